@@ -0,0 +1,263 @@
+// Package backup implements backup and restore of the configuration of the
+// cluster saved in the store, such as the peers, volumes, option profiles,
+// snapshot schedules and cluster options
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/peer"
+	"github.com/gluster/glusterd2/snapshot"
+	"github.com/gluster/glusterd2/store"
+	"github.com/gluster/glusterd2/store/schema"
+	"github.com/gluster/glusterd2/volume"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/coreos/etcd/clientv3"
+	"github.com/coreos/etcd/mvcc/mvccpb"
+	"github.com/pborman/uuid"
+)
+
+// Version is the version of the backup format
+const Version = 1
+
+// maxTxnOps is the most operations put in a single transaction of the store,
+// which is the default limit of etcd servers
+const maxTxnOps = 128
+
+// runtimePrefixes are the keys in the store which hold runtime state rather
+// than configuration, relative to the gluster prefix. They aren't backed up,
+// as they don't apply to a restored cluster.
+var runtimePrefixes = []string{
+	"alive/",
+	"idempotency/",
+	"leader",
+	"locks/",
+	"transaction/",
+	"txnlog/",
+}
+
+// Backup is a portable copy of the configuration of a cluster. Keys are
+// relative to the gluster prefix of the store.
+type Backup struct {
+	Version int               `json:"version"`
+	Created time.Time         `json:"created"`
+	Entries map[string]string `json:"entries"`
+}
+
+// RestoreReport lists the keys of a backup by the outcome of their restore.
+// Conflicts are the keys which exist in the store with a different value,
+// which are only overwritten with force.
+type RestoreReport struct {
+	Restored  []string `json:"restored"`
+	Unchanged []string `json:"unchanged"`
+	Conflicts []string `json:"conflicts"`
+}
+
+func isRuntimeKey(key string) bool {
+	for _, p := range runtimePrefixes {
+		if strings.HasPrefix(key, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// New returns a backup of the configuration of the cluster in the store
+func New() (*Backup, error) {
+	resp, err := store.Store.Get(context.TODO(), store.GlusterPrefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Backup{
+		Version: Version,
+		Created: time.Now().UTC(),
+		Entries: make(map[string]string),
+	}
+	for _, kv := range resp.Kvs {
+		key := strings.TrimPrefix(string(kv.Key), store.GlusterPrefix)
+		if isRuntimeKey(key) {
+			continue
+		}
+		b.Entries[key] = string(kv.Value)
+	}
+	return b, nil
+}
+
+// Validate checks that the backup can be restored by this version of
+// GlusterD. Volumes, peers and snapshot schedules are checked to be readable,
+// and to be saved under their own name and ID.
+func (b *Backup) Validate() error {
+	if b.Version != Version {
+		return errors.ErrBackupVersion
+	}
+
+	for key, value := range b.Entries {
+		if key == "" || isRuntimeKey(key) {
+			return fmt.Errorf("invalid key %s in backup", key)
+		}
+
+		switch {
+		case strings.HasPrefix(key, "volumes/"):
+			var v volume.Volinfo
+			if err := schema.Unmarshal(volume.SchemaKind, []byte(value), &v); err != nil {
+				return fmt.Errorf("invalid volume %s in backup: %s", key, err.Error())
+			}
+			if v.Name != strings.TrimPrefix(key, "volumes/") {
+				return fmt.Errorf("volume %s is saved as %s in backup", v.Name, key)
+			}
+
+		case strings.HasPrefix(key, "peers/"):
+			var p peer.Peer
+			if err := schema.Unmarshal(peer.SchemaKind, []byte(value), &p); err != nil {
+				return fmt.Errorf("invalid peer %s in backup: %s", key, err.Error())
+			}
+			if !uuid.Equal(p.ID, uuid.Parse(strings.TrimPrefix(key, "peers/"))) {
+				return fmt.Errorf("peer %s is saved as %s in backup", p.ID, key)
+			}
+
+		case strings.HasPrefix(key, "snapshot-schedules/"):
+			var sc snapshot.Schedule
+			if err := json.Unmarshal([]byte(value), &sc); err != nil {
+				return fmt.Errorf("invalid snapshot schedule %s in backup: %s", key, err.Error())
+			}
+			if sc.Volume != strings.TrimPrefix(key, "snapshot-schedules/") {
+				return fmt.Errorf("snapshot schedule of %s is saved as %s in backup", sc.Volume, key)
+			}
+		}
+	}
+	return nil
+}
+
+// Restore saves the entries of the backup into the store. Entries which exist
+// in the store with a different value are reported as conflicts, and nothing
+// is restored if there are any, unless force is set. With dryRun set, the
+// report is returned without restoring anything.
+//
+// The entries are restored together, only if the keys haven't changed since
+// they were compared. ErrBackupConflict is returned if they have, and nothing
+// is restored.
+func (b *Backup) Restore(force, dryRun bool) (*RestoreReport, error) {
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+
+	resp, err := store.Store.Get(context.TODO(), store.GlusterPrefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+	current := make(map[string]*mvccpb.KeyValue, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		current[strings.TrimPrefix(string(kv.Key), store.GlusterPrefix)] = kv
+	}
+
+	report := &RestoreReport{
+		Restored:  []string{},
+		Unchanged: []string{},
+		Conflicts: []string{},
+	}
+	var keys []string
+	for key, value := range b.Entries {
+		cur, ok := current[key]
+		switch {
+		case !ok:
+			keys = append(keys, key)
+			report.Restored = append(report.Restored, key)
+		case string(cur.Value) == value:
+			report.Unchanged = append(report.Unchanged, key)
+		default:
+			report.Conflicts = append(report.Conflicts, key)
+			if force {
+				keys = append(keys, key)
+				report.Restored = append(report.Restored, key)
+			}
+		}
+	}
+	sort.Strings(report.Restored)
+	sort.Strings(report.Unchanged)
+	sort.Strings(report.Conflicts)
+
+	if dryRun || (len(report.Conflicts) != 0 && !force) {
+		return report, nil
+	}
+
+	if err := b.apply(keys, current); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// batches splits keys into batches of at most n keys
+func batches(keys []string, n int) [][]string {
+	var bs [][]string
+	for len(keys) > n {
+		bs = append(bs, keys[:n])
+		keys = keys[n:]
+	}
+	if len(keys) != 0 {
+		bs = append(bs, keys)
+	}
+	return bs
+}
+
+// apply saves the entries of the backup at keys into the store. A backup can
+// hold more keys than fit in a single transaction of the store, so they are
+// saved in batches. Every batch is only saved if its keys are still as in
+// current, and the batches already saved are rolled back if a later one
+// fails.
+func (b *Backup) apply(keys []string, current map[string]*mvccpb.KeyValue) error {
+	var saved []string
+	for _, batch := range batches(keys, maxTxnOps) {
+		cmps := make([]clientv3.Cmp, 0, len(batch))
+		ops := make([]clientv3.Op, 0, len(batch))
+		for _, key := range batch {
+			k := store.GlusterPrefix + key
+			if kv, ok := current[key]; ok {
+				cmps = append(cmps, clientv3.Compare(clientv3.ModRevision(k), "=", kv.ModRevision))
+			} else {
+				cmps = append(cmps, clientv3.Compare(clientv3.Version(k), "=", 0))
+			}
+			ops = append(ops, clientv3.OpPut(k, b.Entries[key]))
+		}
+
+		txn, err := store.Store.Txn(context.TODO()).If(cmps...).Then(ops...).Commit()
+		if err == nil && !txn.Succeeded {
+			err = errors.ErrBackupConflict
+		}
+		if err != nil {
+			if rerr := rollback(saved, current); rerr != nil {
+				log.WithError(rerr).Error("failed to roll back partially restored backup")
+			}
+			return err
+		}
+		saved = append(saved, batch...)
+	}
+	return nil
+}
+
+// rollback puts the keys back as they were in current, deleting those which
+// didn't exist
+func rollback(keys []string, current map[string]*mvccpb.KeyValue) error {
+	for _, batch := range batches(keys, maxTxnOps) {
+		ops := make([]clientv3.Op, 0, len(batch))
+		for _, key := range batch {
+			k := store.GlusterPrefix + key
+			if kv, ok := current[key]; ok {
+				ops = append(ops, clientv3.OpPut(k, string(kv.Value)))
+			} else {
+				ops = append(ops, clientv3.OpDelete(k))
+			}
+		}
+		if _, err := store.Store.Txn(context.TODO()).Then(ops...).Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package backup
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/snapshot"
+	"github.com/gluster/glusterd2/store/schema"
+	"github.com/gluster/glusterd2/tests"
+	"github.com/gluster/glusterd2/volume"
+
+	"github.com/pborman/uuid"
+)
+
+func TestValidate(t *testing.T) {
+	data, err := schema.Marshal(volume.SchemaKind, &volume.Volinfo{ID: uuid.NewRandom(), Name: "vol1"})
+	tests.Assert(t, err == nil)
+
+	b := &Backup{Version: Version, Entries: map[string]string{
+		"volumes/vol1":       string(data),
+		"cluster/op-version": "1",
+	}}
+	tests.Assert(t, b.Validate() == nil)
+
+	b.Version = Version + 1
+	tests.Assert(t, b.Validate() == errors.ErrBackupVersion)
+	b.Version = Version
+
+	b.Entries["volumes/vol2"] = string(data)
+	tests.Assert(t, b.Validate() != nil)
+	delete(b.Entries, "volumes/vol2")
+
+	b.Entries["locks/vol1"] = ""
+	tests.Assert(t, b.Validate() != nil)
+	delete(b.Entries, "locks/vol1")
+
+	data, err = json.Marshal(&snapshot.Schedule{Volume: "vol1", Schedule: "0 * * * *", Retain: 2})
+	tests.Assert(t, err == nil)
+	b.Entries["snapshot-schedules/vol1"] = string(data)
+	tests.Assert(t, b.Validate() == nil)
+
+	b.Entries["snapshot-schedules/vol2"] = string(data)
+	tests.Assert(t, b.Validate() != nil)
+}
+
+func TestBatches(t *testing.T) {
+	keys := []string{"a", "b", "c", "d", "e"}
+
+	bs := batches(keys, 2)
+	tests.Assert(t, len(bs) == 3)
+	tests.Assert(t, len(bs[0]) == 2 && len(bs[2]) == 1 && bs[2][0] == "e")
+
+	bs = batches(keys, 5)
+	tests.Assert(t, len(bs) == 1 && len(bs[0]) == 5)
+
+	tests.Assert(t, len(batches(nil, 2)) == 0)
+}
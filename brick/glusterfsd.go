@@ -19,6 +19,8 @@ import (
 
 const (
 	glusterfsdBin = "glusterfsd"
+	// mockBin is run in place of glusterfsd for mocked bricks
+	mockBin = "sleep"
 )
 
 // Glusterfsd type represents information about the brick daemon
@@ -31,6 +33,7 @@ type Glusterfsd struct {
 
 	// For internal use
 	brickinfo Brickinfo
+	mock      bool
 }
 
 // Name returns human-friendly name of the brick process. This is used for logging.
@@ -46,6 +49,11 @@ func (b *Glusterfsd) Path() string {
 // Args returns arguments to be passed to brick process during spawn.
 func (b *Glusterfsd) Args() string {
 
+	if b.mock {
+		b.args = mockBrickDuration
+		return b.args
+	}
+
 	brickPathWithoutSlashes := strings.Trim(strings.Replace(b.brickinfo.Path, "/", "-", -1), "-")
 
 	logFile := path.Join(config.GetString("logdir"), "glusterfs", "bricks", fmt.Sprintf("%s.log", brickPathWithoutSlashes))
@@ -117,14 +125,24 @@ func (b *Glusterfsd) PidFile() string {
 
 // NewGlusterfsd returns a new instance of Glusterfsd type which implements the Daemon interface
 func NewGlusterfsd(binfo Brickinfo) (*Glusterfsd, error) {
-	path, e := exec.LookPath(glusterfsdBin)
+	bin := glusterfsdBin
+	if Mocked() {
+		bin = mockBin
+	}
+	path, e := exec.LookPath(bin)
 	if e != nil {
 		return nil, e
 	}
-	brickObject := &Glusterfsd{binarypath: path, brickinfo: binfo}
+	brickObject := &Glusterfsd{binarypath: path, brickinfo: binfo, mock: Mocked()}
 	return brickObject, nil
 }
 
+// Foreground returns true for mocked bricks, whose processes don't daemonize
+// and write their pid file like glusterfsd does
+func (b *Glusterfsd) Foreground() bool {
+	return b.mock
+}
+
 // ID returns the unique identifier of the brick. The brick path is unique
 // on a node.
 func (b *Glusterfsd) ID() string {
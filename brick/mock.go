@@ -0,0 +1,18 @@
+package brick
+
+import (
+	config "github.com/spf13/viper"
+)
+
+// mockBrickDuration is the time in seconds the stand-in processes of mocked
+// bricks run for, long enough to be running until they are stopped
+const mockBrickDuration = "2147483647"
+
+// Mocked returns true if bricks are mocked, as set by the mock-bricks option.
+// The paths of mocked bricks are not checked for their filesystem and xattr
+// support, and their processes are stand-ins which serve nothing. This allows
+// the transactions and the REST API of GlusterD to be tested in containers
+// without root privileges.
+func Mocked() bool {
+	return config.GetBool("mock-bricks")
+}
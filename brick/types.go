@@ -20,5 +20,12 @@ type Brickstatus struct {
 	Online bool
 	Pid    int
 	Port   int
+	// HealthError is the failure found on the filesystem of the brick, if
+	// it is unhealthy. Unhealthy bricks are reported offline.
+	HealthError string
+	// DiskUsage is the percentage of the filesystem of the brick which is
+	// used, and DiskUsageLevel the watermark it has crossed
+	DiskUsage      int
+	DiskUsageLevel string
 	// TODO: Add other fields like filesystem type, statvfs output etc.
 }
@@ -0,0 +1,141 @@
+// Package brickhealth monitors the filesystems of the bricks on this node, and
+// takes bricks whose filesystem has failed offline
+package brickhealth
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/gluster/glusterd2/brick"
+	"github.com/gluster/glusterd2/diskusage"
+	"github.com/gluster/glusterd2/utils/xattr"
+
+	"github.com/pborman/uuid"
+)
+
+const (
+	volumeIDXattr = "trusted.glusterfs.volume-id"
+
+	// healthCheckFile is written to and read back from to detect I/O
+	// errors. It is kept apart from the health_check file of the posix
+	// xlator, so that the checks of the brick process are not disturbed.
+	healthCheckFile = ".glusterfs/gd2_health_check"
+)
+
+// Health is the health of the filesystem of a brick
+type Health struct {
+	Healthy bool
+	// Reason is the failure found on the filesystem of an unhealthy brick
+	Reason string
+	// Since is the time the brick became healthy or unhealthy
+	Since time.Time
+}
+
+var (
+	healths = make(map[string]*Health)
+	mutex   sync.RWMutex
+)
+
+// Get returns the health of the brick with the given path on this node.
+// Bricks which haven't been checked yet are healthy.
+func Get(brickPath string) Health {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	if h, ok := healths[brickPath]; ok {
+		return *h
+	}
+	return Health{Healthy: true}
+}
+
+// set records the result of a check of the brick, and returns true if its
+// health changed
+func set(brickPath string, reason string) bool {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	healthy := reason == ""
+	h, ok := healths[brickPath]
+	if ok && h.Healthy == healthy {
+		h.Reason = reason
+		return false
+	}
+
+	healths[brickPath] = &Health{Healthy: healthy, Reason: reason, Since: time.Now()}
+	// A brick seen for the first time is only a change if it is unhealthy
+	return ok || !healthy
+}
+
+// forget drops the health of the bricks which are not in the given set
+func forget(bricks map[string]bool) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	for p := range healths {
+		if !bricks[p] {
+			delete(healths, p)
+		}
+	}
+}
+
+// check checks the filesystem of the brick, and returns the reason the brick
+// is unhealthy, or an empty string if it is healthy
+func check(b *brick.Brickinfo) string {
+	if _, err := os.Stat(b.Path); err != nil {
+		return fmt.Sprintf("brick path is not accessible: %s", err.Error())
+	}
+
+	// The volume-id is set on the brick path when the volume is created.
+	// If it is missing, the filesystem of the brick is not mounted
+	// anymore, and the brick path is on the parent filesystem.
+	id, err := xattr.GetUUID(b.Path, volumeIDXattr)
+	if err != nil || !uuid.Equal(id, b.VolumeID) {
+		return "volume-id of brick path does not match, the filesystem of the brick may not be mounted"
+	}
+
+	st, err := diskusage.Stat(b.Path)
+	if err != nil {
+		return fmt.Sprintf("failed to stat filesystem of brick: %s", err.Error())
+	}
+	if st.ReadOnly {
+		return "filesystem of brick is mounted read-only"
+	}
+
+	if err := checkIO(path.Join(b.Path, healthCheckFile)); err != nil {
+		return fmt.Sprintf("I/O error on filesystem of brick: %s", err.Error())
+	}
+	return ""
+}
+
+// checkIO writes the current time to the file and reads it back. The check is
+// skipped if the directory of the file doesn't exist, which is the case for
+// bricks which have never been started.
+func checkIO(file string) error {
+	f, err := os.OpenFile(file, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	data := []byte(time.Now().UTC().Format(time.RFC3339Nano))
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+
+	buf := make([]byte, len(data))
+	if _, err := f.ReadAt(buf, 0); err != nil {
+		return err
+	}
+	if string(buf) != string(data) {
+		return fmt.Errorf("data read back from %s does not match the data written", healthCheckFile)
+	}
+	return nil
+}
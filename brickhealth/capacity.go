@@ -0,0 +1,107 @@
+package brickhealth
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gluster/glusterd2/brick"
+	"github.com/gluster/glusterd2/diskusage"
+	"github.com/gluster/glusterd2/events"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// EventBrickResized is the name of the event emitted when the filesystem of a
+// brick changes size
+const EventBrickResized = "brick.resized"
+
+var (
+	capacities    = make(map[string]*diskusage.Capacity)
+	capacityMutex sync.Mutex
+)
+
+// forgetCapacities drops the capacities of the bricks which are not in the
+// given set
+func forgetCapacities(bricks map[string]bool) {
+	capacityMutex.Lock()
+	defer capacityMutex.Unlock()
+
+	for p := range capacities {
+		if !bricks[p] {
+			delete(capacities, p)
+		}
+	}
+}
+
+// checkCapacity records the size of the filesystem of the brick in the store
+// when it is resized, or when its usage changes by at least a percentage
+// point, and emits an event when the brick is resized
+func checkCapacity(b *brick.Brickinfo) {
+	total, used, err := diskusage.Size(b.Path)
+	if err != nil {
+		return
+	}
+
+	capacityMutex.Lock()
+	defer capacityMutex.Unlock()
+
+	logger := log.WithFields(log.Fields{
+		"volume": b.VolumeName,
+		"brick":  b.Path,
+	})
+
+	prev, ok := capacities[b.Path]
+	if !ok {
+		// The record saved before GlusterD was restarted tells if the
+		// brick was resized meanwhile
+		prev, err = diskusage.GetCapacity(b.VolumeName, b.NodeID, b.Path)
+		if err != nil {
+			logger.WithError(err).Warn("failed to get recorded capacity of brick")
+			return
+		}
+	}
+
+	c := &diskusage.Capacity{
+		Volume:  b.VolumeName,
+		NodeID:  b.NodeID,
+		Path:    b.Path,
+		Total:   total,
+		Used:    used,
+		Updated: time.Now(),
+	}
+	resized := false
+	if prev != nil {
+		c.PreviousTotal = prev.PreviousTotal
+		c.Resized = prev.Resized
+		if prev.Total != total {
+			resized = true
+			c.PreviousTotal = prev.Total
+			c.Resized = &c.Updated
+		} else if prev.UsedPercent() == c.UsedPercent() {
+			capacities[b.Path] = prev
+			return
+		}
+	}
+
+	if err := diskusage.SaveCapacity(c); err != nil {
+		logger.WithError(err).Warn("failed to record capacity of brick")
+		return
+	}
+	capacities[b.Path] = c
+
+	if !resized {
+		return
+	}
+	logger.WithFields(log.Fields{
+		"previous-total": c.PreviousTotal,
+		"total":          c.Total,
+	}).Info("filesystem of brick was resized")
+
+	events.Broadcast(events.New(EventBrickResized, map[string]string{
+		"volume.name":         b.VolumeName,
+		"brick.path":          b.Path,
+		"disk.total":          strconv.FormatUint(c.Total, 10),
+		"disk.previous.total": strconv.FormatUint(c.PreviousTotal, 10),
+	}))
+}
@@ -0,0 +1,211 @@
+package brickhealth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gluster/glusterd2/brick"
+	"github.com/gluster/glusterd2/daemon"
+	"github.com/gluster/glusterd2/diskusage"
+	"github.com/gluster/glusterd2/events"
+	"github.com/gluster/glusterd2/gdctx"
+	"github.com/gluster/glusterd2/pmap"
+	"github.com/gluster/glusterd2/volume"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/pborman/uuid"
+	config "github.com/spf13/viper"
+)
+
+// Names of the events emitted when the health of a brick changes
+const (
+	EventBrickUnhealthy = "brick.unhealthy"
+	EventBrickHealthy   = "brick.healthy"
+)
+
+// Monitor periodically checks the filesystems of the bricks of the started
+// volumes on this node, and their disk usage against the watermarks of the
+// cluster. Unhealthy bricks are reported offline in the volume status, and
+// their brick process is killed if brick-health-kill is set, so that clients
+// fail over to the other replicas.
+// It provides an implementation of the github.com/thejerf/suture.Service
+// interface.
+type Monitor struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// pending holds the paths of the bricks whose check hasn't returned
+	// yet
+	mu      sync.Mutex
+	pending map[string]bool
+}
+
+// NewMonitor returns a new brick health Monitor
+func NewMonitor() *Monitor {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Monitor{ctx: ctx, cancel: cancel, pending: make(map[string]bool)}
+}
+
+// Serve begins monitoring the health of the bricks
+func (m *Monitor) Serve() {
+	interval := time.Duration(config.GetInt("brick-health-interval")) * time.Second
+	// The filesystems of mocked bricks are not checked, as they don't have
+	// the volume-id xattr set
+	if interval <= 0 || brick.Mocked() {
+		<-m.ctx.Done()
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Info("started brick health monitor")
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		m.checkAll(interval)
+	}
+}
+
+// Stop stops monitoring the health of the bricks
+func (m *Monitor) Stop() {
+	m.cancel()
+	log.Info("stopped brick health monitor")
+}
+
+func (m *Monitor) checkAll(timeout time.Duration) {
+	volumes, err := volume.GetVolumes()
+	if err != nil {
+		log.WithError(err).Warn("brick health monitor failed to get volumes")
+		return
+	}
+
+	// The disk usage of the bricks is only checked if the watermarks can
+	// be read
+	usage, err := diskusage.GetConfig()
+	if err != nil {
+		log.WithError(err).Warn("brick health monitor failed to get disk usage configuration")
+	}
+
+	bricks := make(map[string]bool)
+	for _, v := range volumes {
+		if v.Status != volume.VolStarted {
+			continue
+		}
+		for _, b := range v.Bricks {
+			if !uuid.Equal(b.NodeID, gdctx.MyUUID) {
+				continue
+			}
+			bricks[b.Path] = true
+			m.checkBrick(b, timeout)
+			if !Get(b.Path).Healthy {
+				continue
+			}
+			if usage != nil {
+				checkUsage(&b, usage)
+			}
+			checkCapacity(&b)
+		}
+	}
+	forget(bricks)
+	forgetLevels(bricks)
+	forgetCapacities(bricks)
+}
+
+// checkBrick checks the brick, and acts on the changes of its health. A check
+// which doesn't finish in time, as happens with hung filesystems, marks the
+// brick unhealthy. The brick is not checked again until the check hung on it
+// returns, so that hung checks don't pile up.
+func (m *Monitor) checkBrick(b brick.Brickinfo, timeout time.Duration) {
+	if !m.startCheck(b.Path) {
+		return
+	}
+
+	result := make(chan string, 1)
+	go func() {
+		defer m.endCheck(b.Path)
+		result <- check(&b)
+	}()
+
+	var reason string
+	select {
+	case reason = <-result:
+	case <-time.After(timeout):
+		reason = "health check of brick timed out"
+	case <-m.ctx.Done():
+		return
+	}
+
+	if !set(b.Path, reason) {
+		return
+	}
+
+	logger := log.WithFields(log.Fields{
+		"volume": b.VolumeName,
+		"brick":  b.Path,
+	})
+	data := map[string]string{
+		"volume.name": b.VolumeName,
+		"brick.path":  b.Path,
+	}
+
+	if reason == "" {
+		logger.Info("brick is healthy again")
+		events.Broadcast(events.New(EventBrickHealthy, data))
+		return
+	}
+
+	logger.WithField("reason", reason).Error("brick is unhealthy, marking it offline")
+	data["reason"] = reason
+	events.Broadcast(events.New(EventBrickUnhealthy, data))
+
+	if config.GetBool("brick-health-kill") {
+		killBrick(b)
+	}
+}
+
+// killBrick kills the brick process, so that clients stop sending requests to
+// the brick and fail over to its replicas. The brick isn't restarted once its
+// filesystem recovers; the volume has to be started with force for that.
+func killBrick(b brick.Brickinfo) {
+	logger := log.WithFields(log.Fields{
+		"volume": b.VolumeName,
+		"brick":  b.Path,
+	})
+
+	d, err := brick.NewGlusterfsd(b)
+	if err != nil {
+		logger.WithError(err).Error("failed to kill unhealthy brick")
+		return
+	}
+	if err := daemon.Stop(d, true); err != nil {
+		logger.WithError(err).Warn("failed to kill unhealthy brick, it may not be running")
+	} else {
+		logger.Warn("killed unhealthy brick")
+	}
+	// The brick process can't sign out of the portmap registry when it
+	// is killed
+	pmap.RemoveBrick(b.Path)
+}
+
+// startCheck marks a check of the brick at path pending, and returns false if
+// one already is
+func (m *Monitor) startCheck(path string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.pending[path] {
+		return false
+	}
+	m.pending[path] = true
+	return true
+}
+
+func (m *Monitor) endCheck(path string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.pending, path)
+}
@@ -0,0 +1,157 @@
+package brickhealth
+
+import (
+	"context"
+	"time"
+
+	"github.com/gluster/glusterd2/brick"
+	"github.com/gluster/glusterd2/events"
+	"github.com/gluster/glusterd2/gdctx"
+	"github.com/gluster/glusterd2/utils"
+	"github.com/gluster/glusterd2/volume"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/pborman/uuid"
+	config "github.com/spf13/viper"
+)
+
+// EventBrickUnmounted is the name of the event emitted when the filesystem of
+// a brick is unmounted
+const EventBrickUnmounted = "brick.unmounted"
+
+// MountWatcher watches the mount table for the filesystems of the bricks of
+// the started volumes on this node being unmounted, as happens when their
+// device is unplugged. The bricks are marked unhealthy and their brick
+// process is killed right away, as the brick process would otherwise write to
+// the brick path on the parent filesystem.
+// It provides an implementation of the github.com/thejerf/suture.Service
+// interface.
+type MountWatcher struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	// mounts are the filesystems of the healthy bricks, by brick path
+	mounts map[string]utils.MountInfo
+}
+
+// NewMountWatcher returns a new MountWatcher
+func NewMountWatcher() *MountWatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &MountWatcher{ctx: ctx, cancel: cancel, mounts: make(map[string]utils.MountInfo)}
+}
+
+// Serve begins watching the mount table
+func (w *MountWatcher) Serve() {
+	interval := time.Duration(config.GetInt("brick-mount-watch-interval")) * time.Second
+	// Mocked bricks are not on filesystems of their own
+	if interval <= 0 || brick.Mocked() {
+		<-w.ctx.Done()
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Info("started brick mount watcher")
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		w.checkAll()
+	}
+}
+
+// Stop stops watching the mount table
+func (w *MountWatcher) Stop() {
+	w.cancel()
+	log.Info("stopped brick mount watcher")
+}
+
+// mounted returns true if the filesystem is still mounted. A filesystem over
+// which another one has been mounted is still mounted.
+func mounted(m utils.MountInfo, table []utils.MountInfo) bool {
+	for _, t := range table {
+		if t.MountPoint == m.MountPoint && t.Device == m.Device {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *MountWatcher) checkAll() {
+	volumes, err := volume.GetVolumes()
+	if err != nil {
+		log.WithError(err).Warn("brick mount watcher failed to get volumes")
+		return
+	}
+	table, err := utils.GetMounts()
+	if err != nil {
+		log.WithError(err).Warn("brick mount watcher failed to read the mount table")
+		return
+	}
+
+	bricks := make(map[string]bool)
+	for _, v := range volumes {
+		if v.Status != volume.VolStarted {
+			continue
+		}
+		for _, b := range v.Bricks {
+			if !uuid.Equal(b.NodeID, gdctx.MyUUID) {
+				continue
+			}
+			bricks[b.Path] = true
+			w.checkBrick(b, table)
+		}
+	}
+
+	for p := range w.mounts {
+		if !bricks[p] {
+			delete(w.mounts, p)
+		}
+	}
+}
+
+// checkBrick records the filesystem of a healthy brick the first time it is
+// seen, and takes the brick offline once that filesystem is unmounted. The
+// filesystem is recorded again once the health monitor finds the brick
+// healthy, after it is mounted back and the volume is started with force.
+func (w *MountWatcher) checkBrick(b brick.Brickinfo, table []utils.MountInfo) {
+	m, ok := w.mounts[b.Path]
+	if !ok {
+		if !Get(b.Path).Healthy {
+			return
+		}
+		mi, err := utils.GetMountInfo(b.Path)
+		// Bricks on the root filesystem can't be unmounted
+		if err != nil || mi.MountPoint == "/" {
+			return
+		}
+		w.mounts[b.Path] = *mi
+		return
+	}
+
+	if mounted(m, table) {
+		return
+	}
+	delete(w.mounts, b.Path)
+
+	reason := "filesystem of brick was unmounted"
+	logger := log.WithFields(log.Fields{
+		"volume":      b.VolumeName,
+		"brick":       b.Path,
+		"mount-point": m.MountPoint,
+		"device":      m.Device,
+	})
+	logger.Error("filesystem of brick was unmounted, taking brick offline")
+
+	set(b.Path, reason)
+	events.Broadcast(events.New(EventBrickUnmounted, map[string]string{
+		"volume.name":       b.VolumeName,
+		"brick.path":        b.Path,
+		"brick.mount-point": m.MountPoint,
+		"brick.device":      m.Device,
+		"reason":            reason,
+	}))
+	killBrick(b)
+}
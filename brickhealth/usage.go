@@ -0,0 +1,92 @@
+package brickhealth
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/gluster/glusterd2/brick"
+	"github.com/gluster/glusterd2/diskusage"
+	"github.com/gluster/glusterd2/events"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// Names of the events emitted when the disk usage level of a brick changes
+const (
+	EventDiskUsageNormal   = "brick.disk-usage.normal"
+	EventDiskUsageWarning  = "brick.disk-usage.warning"
+	EventDiskUsageCritical = "brick.disk-usage.critical"
+	EventDiskUsageFull     = "brick.disk-usage.full"
+)
+
+var usageEvents = map[string]string{
+	diskusage.LevelNormal:   EventDiskUsageNormal,
+	diskusage.LevelWarning:  EventDiskUsageWarning,
+	diskusage.LevelCritical: EventDiskUsageCritical,
+	diskusage.LevelFull:     EventDiskUsageFull,
+}
+
+var (
+	levels     = make(map[string]string)
+	levelMutex sync.Mutex
+)
+
+// setLevel records the disk usage level of the brick, and returns true if it
+// changed. A brick seen for the first time is only a change if its usage
+// isn't normal.
+func setLevel(brickPath, level string) bool {
+	levelMutex.Lock()
+	defer levelMutex.Unlock()
+
+	prev, ok := levels[brickPath]
+	levels[brickPath] = level
+	if !ok {
+		return level != diskusage.LevelNormal
+	}
+	return prev != level
+}
+
+// forgetLevels drops the disk usage levels of the bricks which are not in the
+// given set
+func forgetLevels(bricks map[string]bool) {
+	levelMutex.Lock()
+	defer levelMutex.Unlock()
+
+	for p := range levels {
+		if !bricks[p] {
+			delete(levels, p)
+		}
+	}
+}
+
+// checkUsage checks the disk usage of the brick against the watermarks, and
+// emits an event when its level changes
+func checkUsage(b *brick.Brickinfo, c *diskusage.Config) {
+	used, err := diskusage.Used(b.Path)
+	if err != nil {
+		return
+	}
+
+	level := c.Level(used)
+	if !setLevel(b.Path, level) {
+		return
+	}
+
+	logger := log.WithFields(log.Fields{
+		"volume": b.VolumeName,
+		"brick":  b.Path,
+		"used":   used,
+	})
+	if level == diskusage.LevelNormal {
+		logger.Info("disk usage of brick is back to normal")
+	} else {
+		logger.WithField("level", level).Warn("disk usage of brick crossed a watermark")
+	}
+
+	events.Broadcast(events.New(usageEvents[level], map[string]string{
+		"volume.name":      b.VolumeName,
+		"brick.path":       b.Path,
+		"disk.used":        strconv.Itoa(used),
+		"disk.usage.level": level,
+	}))
+}
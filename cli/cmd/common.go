@@ -1,16 +1,49 @@
 package cmd
 
 import (
-	"github.com/spf13/cobra"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
 	"os"
+	"strings"
 
+	"github.com/gluster/glusterd2/pkg/api"
 	"github.com/gluster/glusterd2/pkg/restclient"
+
+	"github.com/spf13/cobra"
+)
+
+// Exit codes returned by the CLI
+const (
+	exitSuccess = iota
+	// exitFailure is returned when a request fails for any other reason
+	exitFailure
+	// exitUsage is returned on invalid usage of a command
+	exitUsage
+	// exitNotFound is returned when the requested resource doesn't exist
+	exitNotFound
+	// exitConflict is returned when another operation is in progress
+	exitConflict
+	// exitConnection is returned when GlusterD could not be reached
+	exitConnection
+	// exitQuorumNotMet is returned when the request failed as server quorum
+	// is not met
+	exitQuorumNotMet
 )
 
 var client *restclient.Client
 
-func initRESTClient(hostname string) {
-	client = restclient.New(hostname, "", "")
+// unixScheme is the prefix of hosts which are the path of the local REST
+// socket of GlusterD, eg. unix:///var/run/glusterd2/glusterd2.socket
+const unixScheme = "unix://"
+
+func initRESTClient(hostname string, username string, password string) {
+	if strings.HasPrefix(hostname, unixScheme) {
+		client = restclient.NewUnix(strings.TrimPrefix(hostname, unixScheme), username, password)
+		return
+	}
+	client = restclient.New(hostname, username, password)
 }
 
 func failure(msg string, err int) {
@@ -20,10 +53,48 @@ func failure(msg string, err int) {
 	}
 }
 
+// exitCode maps an error returned by the REST client to the exit code of
+// the CLI
+func exitCode(err error) int {
+	switch e := err.(type) {
+	case *restclient.UnexpectedStatusError:
+		switch e.Code() {
+		case api.ErrCodeQuorumNotMet:
+			return exitQuorumNotMet
+		case api.ErrCodeLockTimeout:
+			return exitConflict
+		}
+		switch e.StatusCode() {
+		case http.StatusNotFound:
+			return exitNotFound
+		case http.StatusConflict:
+			return exitConflict
+		}
+	case *url.Error:
+		return exitConnection
+	}
+	return exitFailure
+}
+
+// handleError prints the error along with the message and exits with the
+// exit code matching the error
+func handleError(msg string, err error) {
+	failure(fmt.Sprintf("%s: %s", msg, err.Error()), exitCode(err))
+}
+
+// printJSON prints the given value as indented JSON on stdout
+func printJSON(v interface{}) {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		failure(fmt.Sprintf("failed to marshal output: %s", err.Error()), exitFailure)
+	}
+	fmt.Println(string(out))
+}
+
 func validateNArgs(cmd *cobra.Command, min int, max int) {
 	nargs := len(cmd.Flags().Args())
 	if nargs < min || (max != 0 && nargs > max) {
 		cmd.Usage()
-		os.Exit(1)
+		os.Exit(exitUsage)
 	}
 }
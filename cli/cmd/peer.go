@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 
 	log "github.com/Sirupsen/logrus"
@@ -56,7 +57,11 @@ var peerProbeCmd = &cobra.Command{
 		peer, err := client.PeerProbe(hostname)
 		if err != nil {
 			log.WithField("host", hostname).Println("peer probe failed")
-			failure(fmt.Sprintf("Peer probe failed with %s", err.Error()), 1)
+			handleError("Peer probe failed", err)
+		}
+		if flagJSONOutput {
+			printJSON(peer)
+			return
 		}
 		fmt.Println("Peer probe success\n")
 		table := tablewriter.NewWriter(os.Stdout)
@@ -75,7 +80,7 @@ var peerDetachCmd = &cobra.Command{
 		err := client.PeerDetach(hostname)
 		if err != nil {
 			log.WithField("host", hostname).Println("peer detach failed")
-			failure(fmt.Sprintf("Peer detach failed with %s", err.Error()), 1)
+			handleError("Peer detach failed", err)
 		}
 		fmt.Println("Peer detach success")
 	},
@@ -86,12 +91,17 @@ func peerStatusHandler(cmd *cobra.Command) {
 	peers, err := client.Peers()
 	if err != nil {
 		log.Println("peer status failed")
-		failure(fmt.Sprintf("Error getting Peers list %s", err.Error()), 1)
+		handleError("Error getting Peers list", err)
+	}
+	if flagJSONOutput {
+		printJSON(peers)
+		return
 	}
 	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"ID", "Name", "Addresses"})
+	table.SetHeader([]string{"ID", "Name", "Addresses", "Online", "Maintenance"})
 	for _, peer := range peers {
-		table.Append([]string{peer.ID.String(), peer.Name, strings.Join(peer.Addresses, ",")})
+		table.Append([]string{peer.ID.String(), peer.Name, strings.Join(peer.Addresses, ","),
+			strconv.FormatBool(peer.Online), strconv.FormatBool(peer.Maintenance)})
 	}
 	table.Render()
 }
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+)
+
+const (
+	defaultHost    = "http://localhost:24007"
+	defaultProfile = "default"
+
+	// profilesEnv can be used to point to a different profiles file
+	profilesEnv = "GLUSTERCLI_PROFILES"
+)
+
+// profile represents a named set of connection details for a GlusterD
+type profile struct {
+	Host     string `json:"host"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+func profilesFile() string {
+	if f := os.Getenv(profilesEnv); f != "" {
+		return f
+	}
+	return path.Join(os.Getenv("HOME"), ".config", "gluster", "profiles.json")
+}
+
+// loadProfile returns the connection profile with the given name from the
+// profiles file. The default profile connects to the local GlusterD when it
+// isn't present in the file.
+func loadProfile(name string) (*profile, error) {
+	profiles := make(map[string]*profile)
+
+	data, err := ioutil.ReadFile(profilesFile())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &profiles); err != nil {
+			return nil, err
+		}
+	}
+
+	p, ok := profiles[name]
+	if !ok {
+		if name != defaultProfile {
+			return nil, os.ErrNotExist
+		}
+		p = &profile{Host: defaultHost}
+	}
+	return p, nil
+}
@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"fmt"
+
 	"github.com/spf13/cobra"
 )
 
@@ -9,7 +11,16 @@ var RootCmd = &cobra.Command{
 	Use:   "gluster",
 	Short: "Gluster Console Manager (command line utility)",
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		initRESTClient(flagHostname)
+		p, err := loadProfile(flagProfile)
+		if err != nil {
+			failure(fmt.Sprintf("failed to load profile %s: %s", flagProfile, err.Error()), exitUsage)
+		}
+		// Host given on the command line takes precedence over the
+		// host in the profile
+		if cmd.Flags().Changed("host") {
+			p.Host = flagHostname
+		}
+		initRESTClient(p.Host, p.Username, p.Password)
 	},
 }
 
@@ -17,13 +28,15 @@ var (
 	flagXMLOutput  bool
 	flagJSONOutput bool
 	flagHostname   string
+	flagProfile    string
 )
 
 func init() {
 	// Global flags, applicable for all sub commands
 	RootCmd.PersistentFlags().BoolVarP(&flagXMLOutput, "xml", "", false, "XML Output")
 	RootCmd.PersistentFlags().BoolVarP(&flagJSONOutput, "json", "", false, "JSON Output")
-	RootCmd.PersistentFlags().StringVarP(&flagHostname, "host", "", "http://localhost:24007", "Host")
+	RootCmd.PersistentFlags().StringVarP(&flagHostname, "host", "", defaultHost, "Host, or unix:// followed by the path of the local GlusterD socket")
+	RootCmd.PersistentFlags().StringVarP(&flagProfile, "profile", "", defaultProfile, "Connection profile to use from "+profilesFile())
 }
 
 // Execute function parses flags and executes command
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/gluster/glusterd2/pkg/api"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+const (
+	helpStatusCmd = "Show status of the cluster"
+)
+
+func init() {
+	RootCmd.AddCommand(statusCmd)
+}
+
+// clusterStatus represents the status of the cluster as shown by the status
+// command
+type clusterStatus struct {
+	OpVersion api.OpVersion `json:"op-version"`
+	Peers     []api.Peer    `json:"peers"`
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: helpStatusCmd,
+	Run: func(cmd *cobra.Command, args []string) {
+		validateNArgs(cmd, 0, 0)
+		var status clusterStatus
+		var err error
+
+		if status.OpVersion, err = client.OpVersion(); err != nil {
+			log.Println("cluster op-version failed")
+			handleError("Error getting cluster op-version", err)
+		}
+		if status.Peers, err = client.Peers(); err != nil {
+			log.Println("peer status failed")
+			handleError("Error getting Peers list", err)
+		}
+
+		if flagJSONOutput {
+			printJSON(status)
+			return
+		}
+		fmt.Println("Cluster op-version:", status.OpVersion.OpVersion)
+		fmt.Println("Maximum supported op-version:", status.OpVersion.MaxOpVersion)
+		fmt.Println()
+
+		online := 0
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"ID", "Name", "Online", "Maintenance"})
+		for _, peer := range status.Peers {
+			if peer.Online {
+				online++
+			}
+			table.Append([]string{peer.ID.String(), peer.Name,
+				strconv.FormatBool(peer.Online), strconv.FormatBool(peer.Maintenance)})
+		}
+		table.Render()
+		fmt.Printf("%d of %d peers online\n", online, len(status.Peers))
+	},
+}
@@ -2,8 +2,16 @@ package cmd
 
 import (
 	"fmt"
-	"github.com/spf13/cobra"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/gluster/glusterd2/pkg/api"
+	"github.com/gluster/glusterd2/validation"
+
 	log "github.com/Sirupsen/logrus"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
 )
 
 const (
@@ -72,13 +80,36 @@ var volumeCmd = &cobra.Command{
 }
 
 var volumeCreateCmd = &cobra.Command{
-	Use:   "create",
+	Use:   "create [flags] <VOLNAME> <BRICK>...",
 	Short: helpVolumeCreateCmd,
 	Run: func(cmd *cobra.Command, args []string) {
 		validateNArgs(cmd, 2, 0)
+		if flagCreateCmdStripeCount != 0 || flagCreateCmdDisperseCount != 0 ||
+			flagCreateCmdDisperseDataCount != 0 || flagCreateCmdRedundancyCount != 0 {
+			failure("only distribute and replicate volumes are supported", exitUsage)
+		}
 		volname := cmd.Flags().Args()[0]
-		bricks := cmd.Flags().Args()[1:]
-		fmt.Println("CREATE:", volname, bricks)
+		if err := validation.CheckVolumeName(volname); err != nil {
+			failure(fmt.Sprintf("invalid volume name: %s", err.Error()), exitUsage)
+		}
+		req := api.VolCreateReq{
+			Name:      volname,
+			Transport: flagCreateCmdTransport,
+			Replica:   flagCreateCmdReplicaCount,
+			Bricks:    cmd.Flags().Args()[1:],
+			Force:     flagCreateCmdForce,
+		}
+		vol, err := client.VolumeCreate(req)
+		if err != nil {
+			log.WithField("volume", volname).Println("volume create failed")
+			handleError("volume create failed", err)
+		}
+		if flagJSONOutput {
+			printJSON(vol)
+			return
+		}
+		fmt.Printf("Volume %s created successfully\n", volname)
+		fmt.Println("Volume ID: ", vol.ID)
 	},
 }
 
@@ -91,7 +122,7 @@ var volumeStartCmd = &cobra.Command{
 		err := client.VolumeStart(volname)
 		if err != nil {
 			log.WithField("volume", volname).Println("volume start failed")
-			failure(fmt.Sprintf("volume start failed with: %s", err.Error()), 1)
+			handleError("volume start failed", err)
 		}
 		fmt.Printf("Volume %s started successfully\n", volname)
 	},
@@ -106,74 +137,190 @@ var volumeStopCmd = &cobra.Command{
 		err := client.VolumeStop(volname)
 		if err != nil {
 			log.WithField("volume", volname).Println("volume stop failed")
-			failure(fmt.Sprintf("volume stop failed with: %s", err.Error()), 1)
+			handleError("volume stop failed", err)
 		}
 		fmt.Printf("Volume %s stopped successfully\n", volname)
 	},
 }
 
 var volumeDeleteCmd = &cobra.Command{
-	Use:   "delete",
+	Use:   "delete <VOLNAME>",
 	Short: helpVolumeDeleteCmd,
 	Run: func(cmd *cobra.Command, args []string) {
 		validateNArgs(cmd, 1, 1)
 		volname := cmd.Flags().Args()[0]
-		fmt.Println("DELETE:", volname)
+		err := client.VolumeDelete(volname)
+		if err != nil {
+			log.WithField("volume", volname).Println("volume delete failed")
+			handleError("volume delete failed", err)
+		}
+		fmt.Printf("Volume %s deleted successfully\n", volname)
 	},
 }
 
 var volumeGetCmd = &cobra.Command{
-	Use:   "get",
+	Use:   "get <VOLNAME> [<OPTION>]",
 	Short: helpVolumeGetCmd,
 	Run: func(cmd *cobra.Command, args []string) {
 		validateNArgs(cmd, 1, 2)
 		volname := cmd.Flags().Args()[0]
-		fmt.Println("GET:", volname)
+		vol, err := client.Volume(volname)
+		if err != nil {
+			log.WithField("volume", volname).Println("volume get failed")
+			handleError("volume get failed", err)
+		}
+
+		options := vol.Options
+		if len(cmd.Flags().Args()) > 1 {
+			name := cmd.Flags().Args()[1]
+			value, ok := vol.Options[name]
+			if !ok {
+				failure(fmt.Sprintf("option %s is not set on volume %s", name, volname), exitNotFound)
+			}
+			options = map[string]string{}
+			options[name] = value
+		}
+
+		if flagJSONOutput {
+			printJSON(options)
+			return
+		}
+		var names []string
+		for k := range options {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Option", "Value"})
+		for _, k := range names {
+			table.Append([]string{k, options[k]})
+		}
+		table.Render()
 	},
 }
 
 var volumeSetCmd = &cobra.Command{
-	Use:   "set",
+	Use:   "set <VOLNAME> <OPTION> <VALUE>",
 	Short: helpVolumeSetCmd,
 	Run: func(cmd *cobra.Command, args []string) {
 		validateNArgs(cmd, 3, 3)
 		volname := cmd.Flags().Args()[0]
-		fmt.Println("SET:", volname)
+		options := map[string]string{cmd.Flags().Args()[1]: cmd.Flags().Args()[2]}
+		if err := client.VolumeSet(volname, options); err != nil {
+			log.WithField("volume", volname).Println("volume set failed")
+			handleError("volume set failed", err)
+		}
+		fmt.Printf("Options set successfully for %s volume\n", volname)
 	},
 }
 
 var volumeResetCmd = &cobra.Command{
-	Use:   "reset",
+	Use:   "reset <VOLNAME> [<OPTION>]",
 	Short: helpVolumeResetCmd,
 	Run: func(cmd *cobra.Command, args []string) {
 		validateNArgs(cmd, 1, 2)
-		volname := cmd.Flags().Args()[0]
-		fmt.Println("RESET:", volname)
+		failure("volume reset is not supported by GlusterD yet", exitFailure)
 	},
 }
 
+// volumeNames returns the volume given on the command line, or all volumes
+// if none was given
+func volumeNames(cmd *cobra.Command) []string {
+	if len(cmd.Flags().Args()) > 0 {
+		return cmd.Flags().Args()[:1]
+	}
+
+	vols, err := client.Volumes()
+	if err != nil {
+		log.Println("volume list failed")
+		handleError("Error getting volumes list", err)
+	}
+	var names []string
+	for name := range vols {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func volStateString(s api.VolState) string {
+	switch s {
+	case 0:
+		return "Created"
+	case 1:
+		return "Started"
+	case 2:
+		return "Stopped"
+	}
+	return "Unknown"
+}
+
 var volumeInfoCmd = &cobra.Command{
-	Use:   "info",
+	Use:   "info [<VOLNAME>]",
 	Short: helpVolumeInfoCmd,
 	Run: func(cmd *cobra.Command, args []string) {
 		validateNArgs(cmd, 0, 1)
-		volname := "all"
-		if len(cmd.Flags().Args()) > 0 {
-			volname = cmd.Flags().Args()[0]
+		var vols []api.Volinfo
+		for _, volname := range volumeNames(cmd) {
+			vol, err := client.Volume(volname)
+			if err != nil {
+				log.WithField("volume", volname).Println("volume info failed")
+				handleError("volume info failed", err)
+			}
+			vols = append(vols, vol)
+		}
+
+		if flagJSONOutput {
+			printJSON(vols)
+			return
+		}
+		for _, vol := range vols {
+			fmt.Println()
+			fmt.Println("Volume Name:", vol.Name)
+			fmt.Println("Volume ID:", vol.ID)
+			fmt.Println("State:", volStateString(vol.Status))
+			fmt.Println("Transport-type:", vol.Transport)
+			fmt.Println("Replica Count:", vol.ReplicaCount)
+			fmt.Println("Number of Bricks:", len(vol.Bricks))
+			for i, b := range vol.Bricks {
+				fmt.Printf("Brick%d: %s:%s\n", i+1, b.Hostname, b.Path)
+			}
 		}
-		fmt.Println("INFO:", volname)
 	},
 }
 
 var volumeStatusCmd = &cobra.Command{
-	Use:   "status",
+	Use:   "status [<VOLNAME>]",
 	Short: helpVolumeStatusCmd,
 	Run: func(cmd *cobra.Command, args []string) {
 		validateNArgs(cmd, 0, 1)
-		volname := "all"
-		if len(cmd.Flags().Args()) > 0 {
-			volname = cmd.Flags().Args()[0]
+		statuses := make(map[string]api.VolStatus)
+		names := volumeNames(cmd)
+		for _, volname := range names {
+			status, err := client.VolumeStatus(volname)
+			if err != nil {
+				log.WithField("volume", volname).Println("volume status failed")
+				handleError("volume status failed", err)
+			}
+			statuses[volname] = status
+		}
+
+		if flagJSONOutput {
+			printJSON(statuses)
+			return
+		}
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Volume", "Brick", "Online", "Port", "Pid", "Health"})
+		for _, volname := range names {
+			for _, b := range statuses[volname].Brickstatuses {
+				health := "healthy"
+				if b.HealthError != "" {
+					health = b.HealthError
+				}
+				table.Append([]string{volname, b.BInfo.Hostname + ":" + b.BInfo.Path,
+					strconv.FormatBool(b.Online), strconv.Itoa(b.Port), strconv.Itoa(b.Pid), health})
+			}
 		}
-		fmt.Println("STATUS:", volname)
+		table.Render()
 	},
 }
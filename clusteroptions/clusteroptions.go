@@ -0,0 +1,228 @@
+// Package clusteroptions implements the options which apply to the cluster as
+// a whole, as opposed to the options of a volume.
+//
+// The options are registered by the subsystems which use them. Only the
+// values which have been set are saved in the store, and the other options
+// have their default value. The subsystems are notified on every peer when
+// the value of their options change.
+package clusteroptions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/store"
+
+	"github.com/coreos/etcd/clientv3"
+)
+
+const optionsKey = store.GlusterPrefix + "cluster/options"
+
+// Option is an option of the cluster
+type Option struct {
+	Name         string
+	DefaultValue string
+	Description  string
+	// Validate checks if the value is valid for the option. get returns the
+	// value other options will have once the update is applied.
+	Validate func(value string, get func(name string) string) error
+	// OnChange is called on every peer with the value of the option when
+	// GlusterD starts, and whenever the value changes
+	OnChange func(value string)
+}
+
+var (
+	options     = make(map[string]*Option)
+	optionsLock sync.RWMutex
+)
+
+// Register registers an option of the cluster. It is meant to be called
+// from the init function of the packages using the option.
+func Register(opt *Option) {
+	optionsLock.Lock()
+	defer optionsLock.Unlock()
+
+	if _, ok := options[opt.Name]; ok {
+		panic(fmt.Sprintf("cluster option %s registered twice", opt.Name))
+	}
+	options[opt.Name] = opt
+}
+
+// lookup returns the registered option with the given name
+func lookup(name string) (*Option, bool) {
+	optionsLock.RLock()
+	defer optionsLock.RUnlock()
+
+	opt, ok := options[name]
+	return opt, ok
+}
+
+// registered returns all the registered options, sorted by name
+func registered() []*Option {
+	optionsLock.RLock()
+	defer optionsLock.RUnlock()
+
+	opts := make([]*Option, 0, len(options))
+	for _, opt := range options {
+		opts = append(opts, opt)
+	}
+	sort.Sort(byName(opts))
+	return opts
+}
+
+type byName []*Option
+
+func (s byName) Len() int           { return len(s) }
+func (s byName) Less(i, j int) bool { return s[i].Name < s[j].Name }
+func (s byName) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// Value is the value of an option of the cluster
+type Value struct {
+	Name         string `json:"name"`
+	Value        string `json:"value"`
+	DefaultValue string `json:"default-value"`
+	Modified     bool   `json:"modified"`
+	Description  string `json:"description"`
+}
+
+// getSaved returns the values of the options which have been set, with the
+// revision of the key they are saved in. The revision is 0 if no option
+// has been set.
+func getSaved() (map[string]string, int64, error) {
+	resp, err := store.Store.Get(context.TODO(), optionsKey)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	saved := make(map[string]string)
+	if resp.Count == 0 {
+		return saved, 0, nil
+	}
+	if err := json.Unmarshal(resp.Kvs[0].Value, &saved); err != nil {
+		return nil, 0, err
+	}
+	return saved, resp.Kvs[0].ModRevision, nil
+}
+
+// effective returns the value of the option given the saved values
+func effective(opt *Option, saved map[string]string) string {
+	if v, ok := saved[opt.Name]; ok {
+		return v
+	}
+	return opt.DefaultValue
+}
+
+// GetAll returns the values of all the options of the cluster
+func GetAll() ([]Value, error) {
+	saved, _, err := getSaved()
+	if err != nil {
+		return nil, err
+	}
+
+	var values []Value
+	for _, opt := range registered() {
+		_, modified := saved[opt.Name]
+		values = append(values, Value{
+			Name:         opt.Name,
+			Value:        effective(opt, saved),
+			DefaultValue: opt.DefaultValue,
+			Modified:     modified,
+			Description:  opt.Description,
+		})
+	}
+	return values, nil
+}
+
+// Get returns the value of an option of the cluster
+func Get(name string) (string, error) {
+	opt, ok := lookup(name)
+	if !ok {
+		return "", errors.ErrClusterOptNotFound
+	}
+
+	saved, _, err := getSaved()
+	if err != nil {
+		return "", err
+	}
+	return effective(opt, saved), nil
+}
+
+// merge applies the given values to the saved values, and validates them.
+// Options set to an empty value are reset to their default.
+func merge(saved, values map[string]string) error {
+	for name, value := range values {
+		opt, ok := lookup(name)
+		if !ok {
+			return fmt.Errorf("%s: %s", errors.ErrClusterOptNotFound.Error(), name)
+		}
+		if value == "" || value == opt.DefaultValue {
+			delete(saved, name)
+		} else {
+			saved[name] = value
+		}
+	}
+
+	get := func(name string) string {
+		if opt, ok := lookup(name); ok {
+			return effective(opt, saved)
+		}
+		return ""
+	}
+	for name := range values {
+		opt, _ := lookup(name)
+		if opt.Validate == nil {
+			continue
+		}
+		if err := opt.Validate(get(name), get); err != nil {
+			return fmt.Errorf("invalid value for %s: %s", name, err.Error())
+		}
+	}
+	return nil
+}
+
+// Check returns an error if any of the given options is unknown or would not
+// be valid once set
+func Check(values map[string]string) error {
+	saved, _, err := getSaved()
+	if err != nil {
+		return err
+	}
+	return merge(saved, values)
+}
+
+// Set validates and saves the values of the given options. Options set to
+// an empty value are reset to their default. Nothing is saved if any of the
+// values is not valid.
+func Set(values map[string]string) error {
+	saved, rev, err := getSaved()
+	if err != nil {
+		return err
+	}
+	if err := merge(saved, values); err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(saved)
+	if err != nil {
+		return err
+	}
+
+	// The options are only saved if they haven't been changed since they
+	// were read, so that concurrent updates aren't lost
+	txn, err := store.Store.Txn(context.TODO()).If(
+		clientv3.Compare(clientv3.ModRevision(optionsKey), "=", rev),
+	).Then(
+		clientv3.OpPut(optionsKey, string(b)),
+	).Commit()
+	if err != nil {
+		return err
+	}
+	if !txn.Succeeded {
+		return errors.ErrClusterOptConflict
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package clusteroptions
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/gluster/glusterd2/tests"
+)
+
+func TestMerge(t *testing.T) {
+	Register(&Option{
+		Name:         "test-low",
+		DefaultValue: "1",
+	})
+	Register(&Option{
+		Name:         "test-high",
+		DefaultValue: "10",
+		Validate: func(value string, get func(string) string) error {
+			high, _ := strconv.Atoi(value)
+			low, _ := strconv.Atoi(get("test-low"))
+			if low > high {
+				return fmt.Errorf("below test-low")
+			}
+			return nil
+		},
+	})
+
+	saved := map[string]string{}
+	tests.Assert(t, merge(saved, map[string]string{"test-high": "5"}) == nil)
+	tests.Assert(t, saved["test-high"] == "5")
+
+	// Setting an option to its default or to nothing resets it
+	tests.Assert(t, merge(saved, map[string]string{"test-high": "10"}) == nil)
+	_, ok := saved["test-high"]
+	tests.Assert(t, !ok)
+	saved["test-low"] = "2"
+	tests.Assert(t, merge(saved, map[string]string{"test-low": ""}) == nil)
+	_, ok = saved["test-low"]
+	tests.Assert(t, !ok)
+
+	// Options are validated with the values of the others after the update
+	tests.Assert(t, merge(saved, map[string]string{"test-low": "20", "test-high": "15"}) != nil)
+	tests.Assert(t, merge(map[string]string{}, map[string]string{"test-low": "5", "test-high": "8"}) == nil)
+
+	tests.Assert(t, merge(saved, map[string]string{"test-unknown": "1"}) != nil)
+}
@@ -0,0 +1,119 @@
+package clusteroptions
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/gluster/glusterd2/store"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/coreos/etcd/clientv3"
+)
+
+// retryInterval is the interval at which the watch is started again when it
+// fails
+const retryInterval = 5 * time.Second
+
+// Watcher notifies the subsystems of this peer when the values of their
+// cluster options change.
+// It provides an implementation of the github.com/thejerf/suture.Service
+// interface.
+type Watcher struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	// applied are the values the subsystems were last notified with
+	applied map[string]string
+}
+
+// NewWatcher returns a new Watcher
+func NewWatcher() *Watcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Watcher{ctx: ctx, cancel: cancel, applied: make(map[string]string)}
+}
+
+// Serve notifies the subsystems of the current values of their options, and
+// begins watching for changes
+func (w *Watcher) Serve() {
+	log.Info("started cluster options watcher")
+	for {
+		rev, err := w.sync()
+		if err != nil {
+			log.WithError(err).Warn("failed to get cluster options")
+		} else {
+			w.watch(rev)
+		}
+
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-time.After(retryInterval):
+		}
+	}
+}
+
+// Stop stops watching the cluster options
+func (w *Watcher) Stop() {
+	w.cancel()
+	log.Info("stopped cluster options watcher")
+}
+
+// sync notifies the subsystems of the saved values, and returns the revision
+// of the store they were read at
+func (w *Watcher) sync() (int64, error) {
+	resp, err := store.Store.Get(w.ctx, optionsKey)
+	if err != nil {
+		return 0, err
+	}
+
+	saved := make(map[string]string)
+	if resp.Count != 0 {
+		if err := json.Unmarshal(resp.Kvs[0].Value, &saved); err != nil {
+			return 0, err
+		}
+	}
+	w.apply(saved)
+	return resp.Header.Revision, nil
+}
+
+// watch applies the changes to the options made after the given revision,
+// until the watch fails or the watcher is stopped
+func (w *Watcher) watch(rev int64) {
+	wch := store.Store.Watch(w.ctx, optionsKey, clientv3.WithRev(rev+1))
+	for wresp := range wch {
+		if err := wresp.Err(); err != nil {
+			log.WithError(err).Warn("cluster options watch failed")
+			return
+		}
+		for _, ev := range wresp.Events {
+			saved := make(map[string]string)
+			if ev.Type != clientv3.EventTypeDelete {
+				if err := json.Unmarshal(ev.Kv.Value, &saved); err != nil {
+					log.WithError(err).Error("failed to parse cluster options")
+					continue
+				}
+			}
+			w.apply(saved)
+		}
+	}
+}
+
+// apply calls the OnChange function of the options whose value differs from
+// the one last applied
+func (w *Watcher) apply(saved map[string]string) {
+	for _, opt := range registered() {
+		value := effective(opt, saved)
+		if old, ok := w.applied[opt.Name]; ok && old == value {
+			continue
+		}
+		w.applied[opt.Name] = value
+
+		log.WithFields(log.Fields{
+			"option": opt.Name,
+			"value":  value,
+		}).Info("cluster option changed")
+		if opt.OnChange != nil {
+			opt.OnChange(value)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package collect
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gluster/glusterd2/gdctx"
+	"github.com/gluster/glusterd2/peer"
+	"github.com/gluster/glusterd2/store"
+
+	"github.com/coreos/etcd/clientv3"
+	"github.com/coreos/etcd/mvcc/mvccpb"
+	"github.com/pborman/uuid"
+	netctx "golang.org/x/net/context"
+)
+
+// Manifest describes the contents of an archive. Peers which failed have
+// their Error set, and can be collected from again by requesting only them.
+type Manifest struct {
+	Created time.Time    `json:"created"`
+	Include []string     `json:"include"`
+	Peers   []PeerResult `json:"peers"`
+}
+
+// Name returns the name of the archive created at the given time, which is
+// also the directory all the files of the archive are under
+func Name(created time.Time) string {
+	return "glusterd2-debug-" + created.UTC().Format("20060102-150405")
+}
+
+// idempotencyKeyPrefix is the prefix of the records of idempotent requests,
+// which hold the responses saved to be replayed
+const idempotencyKeyPrefix = "idempotency/"
+
+// dumpValue returns a value of the store as it is put in the store dump, with
+// its secrets redacted. JSON values are nested in the dump, so that they can
+// be read. Values are redacted before being nested, as the pattern doesn't
+// match the secrets once escaped in the JSON of the dump.
+func dumpValue(key string, value []byte) interface{} {
+	value = Redact(value)
+
+	var v interface{}
+	if err := json.Unmarshal(value, &v); err != nil {
+		return string(value)
+	}
+
+	// The saved responses of idempotency records are base64 encoded,
+	// which hides their secrets from the pattern
+	if rec, ok := v.(map[string]interface{}); ok && strings.HasPrefix(key, idempotencyKeyPrefix) {
+		if body, ok := rec["body"].(string); ok {
+			if data, err := base64.StdEncoding.DecodeString(body); err == nil {
+				rec["body"] = string(Redact(data))
+			} else {
+				rec["body"] = redacted
+			}
+		}
+	}
+	return v
+}
+
+// dumpEntries returns the dump of the given keys of the store with their
+// values
+func dumpEntries(kvs []*mvccpb.KeyValue) []byte {
+	entries := make(map[string]interface{})
+	for _, kv := range kvs {
+		key := strings.TrimPrefix(string(kv.Key), store.GlusterPrefix)
+		entries[key] = dumpValue(key, kv.Value)
+	}
+	return marshal(entries)
+}
+
+// storeDump returns all the keys in the store with their values, with the
+// secrets redacted
+func storeDump() ([]byte, error) {
+	resp, err := store.Store.Get(context.TODO(), store.GlusterPrefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+	return dumpEntries(resp.Kvs), nil
+}
+
+// peerDir returns the directory the files of the peer are put under
+func peerDir(p *peer.Peer) string {
+	return fmt.Sprintf("%s-%s", p.Name, p.ID.String()[:8])
+}
+
+// Collect writes a gzipped tar archive of the information collected from the
+// given peers to w. The peers are collected from one at a time. Peers which
+// fail are reported in the manifest of the archive, and don't fail the
+// collection.
+func Collect(ctx netctx.Context, w io.Writer, created time.Time, peers []peer.Peer, opts *Options) error {
+	if err := begin(); err != nil {
+		return err
+	}
+	defer end()
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	dir := Name(created)
+	manifest := &Manifest{
+		Created: created,
+		Include: opts.Include,
+		Peers:   []PeerResult{},
+	}
+
+	if opts.includes(Store) {
+		data, err := storeDump()
+		if err != nil {
+			manifest.Peers = append(manifest.Peers, PeerResult{Name: Store, Error: err.Error()})
+		} else if err := writeFile(tw, path.Join(dir, "store.json"), data, created); err != nil {
+			return err
+		}
+	}
+
+	for i := range peers {
+		p := &peers[i]
+		pdir := path.Join(dir, peerDir(p))
+
+		var result *PeerResult
+		if uuid.Equal(p.ID, gdctx.MyUUID) {
+			result = collectLocal(tw, pdir, opts)
+		} else {
+			result = collectRemote(ctx, tw, pdir, p, opts)
+		}
+		manifest.Peers = append(manifest.Peers, *result)
+	}
+
+	if err := writeFile(tw, path.Join(dir, "manifest.json"), marshal(manifest), time.Now()); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
@@ -0,0 +1,302 @@
+// Package collect gathers the logs, statedumps, volfiles and a dump of the
+// store from the peers of the cluster into a single archive, to be attached to
+// bug reports. Secrets are redacted, and the size of the archive is limited.
+package collect
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gluster/glusterd2/brick"
+	"github.com/gluster/glusterd2/daemon"
+	"github.com/gluster/glusterd2/gdctx"
+	"github.com/gluster/glusterd2/volume"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/pborman/uuid"
+	config "github.com/spf13/viper"
+	"golang.org/x/sys/unix"
+)
+
+// The kinds of information which can be collected
+const (
+	Logs       = "logs"
+	Statedumps = "statedumps"
+	Volfiles   = "volfiles"
+	Store      = "store"
+)
+
+// Kinds are all the kinds of information which can be collected
+var Kinds = []string{Logs, Statedumps, Volfiles, Store}
+
+const (
+	// DefaultMaxFileSize is the default limit of the size of each file.
+	// Only the end of larger files is collected.
+	DefaultMaxFileSize = 16 << 20
+	// DefaultMaxPeerSize is the default limit of the size of the files
+	// collected from each peer
+	DefaultMaxPeerSize = 256 << 20
+
+	// statedumpWait is the time the bricks are given to write their
+	// statedumps
+	statedumpWait = 3 * time.Second
+)
+
+var (
+	// statedumpDir is the directory the glusterfs processes write their
+	// statedumps to
+	statedumpDir = "/var/run/gluster"
+
+	// ErrInProgress is returned when information is already being
+	// collected on the peer
+	ErrInProgress = errors.New("debug information is already being collected")
+
+	collecting int32
+)
+
+// Options are the options of a collection from a peer
+type Options struct {
+	// Include lists the kinds of information to collect
+	Include []string `json:"include"`
+	// TakeStatedumps makes the bricks on the peer take statedumps before
+	// they are collected. Otherwise the existing statedumps are collected.
+	TakeStatedumps bool  `json:"take-statedumps"`
+	MaxFileSize    int64 `json:"max-file-size"`
+	MaxPeerSize    int64 `json:"max-peer-size"`
+}
+
+func (o *Options) includes(kind string) bool {
+	for _, k := range o.Include {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// PeerResult reports what was collected from a peer. Files which were left out
+// because of the size limit are listed in Skipped, and files of which only the
+// end was collected in Truncated.
+type PeerResult struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Files     int      `json:"files"`
+	Bytes     int64    `json:"bytes"`
+	Skipped   []string `json:"skipped,omitempty"`
+	Truncated []string `json:"truncated,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// begin marks the start of a collection on this peer. Only one collection is
+// run at a time, so that the peer isn't slowed down by several of them.
+func begin() error {
+	if !atomic.CompareAndSwapInt32(&collecting, 0, 1) {
+		return ErrInProgress
+	}
+	return nil
+}
+
+func end() {
+	atomic.StoreInt32(&collecting, 0)
+}
+
+// collector writes the files of this peer into a tar archive
+type collector struct {
+	tw       *tar.Writer
+	opts     *Options
+	throttle *throttle
+	result   *PeerResult
+	// err is the error the archive failed to be written with. Nothing more
+	// is collected once it is set.
+	err error
+}
+
+// addFile adds the file at the given path to the archive with the given name.
+// Files beyond the size limit of the peer are skipped.
+func (c *collector) addFile(name, p string) {
+	if c.err != nil {
+		return
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil || !stat.Mode().IsRegular() {
+		return
+	}
+
+	var offset int64
+	size := stat.Size()
+	truncated := size > c.opts.MaxFileSize
+	if truncated {
+		offset = size - c.opts.MaxFileSize
+		size = c.opts.MaxFileSize
+	}
+	if c.result.Bytes+size > c.opts.MaxPeerSize {
+		c.result.Skipped = append(c.result.Skipped, name)
+		return
+	}
+	if truncated {
+		c.result.Truncated = append(c.result.Truncated, name)
+	}
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, c.throttle.reader(io.NewSectionReader(f, offset, size))); err != nil {
+		log.WithError(err).WithField("file", p).Debug("failed to read file to collect")
+		return
+	}
+	c.addData(name, Redact(buf.Bytes()), stat.ModTime())
+}
+
+// addData adds the data to the archive as a file with the given name
+func (c *collector) addData(name string, data []byte, modTime time.Time) {
+	if c.err != nil {
+		return
+	}
+	c.err = writeFile(c.tw, name, data, modTime)
+	if c.err == nil {
+		c.result.Files++
+		c.result.Bytes += int64(len(data))
+	}
+}
+
+// writeFile writes the data to the tar archive as a file with the given name
+func writeFile(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// addDir adds the files under the directory which match the filter, newest
+// first, so that the latest files are kept within the size limit
+func (c *collector) addDir(name, dir string, filter func(os.FileInfo) bool) {
+	var files []dirFile
+	filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil || !info.Mode().IsRegular() {
+			return nil
+		}
+		if filter != nil && !filter(info) {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return nil
+		}
+		files = append(files, dirFile{rel, info})
+		return nil
+	})
+
+	sort.Sort(byModTime(files))
+	for _, f := range files {
+		c.addFile(path.Join(name, filepath.ToSlash(f.rel)), filepath.Join(dir, f.rel))
+	}
+}
+
+// dirFile is a file found under a directory added to the archive
+type dirFile struct {
+	rel  string
+	info os.FileInfo
+}
+
+// byModTime sorts files from the newest to the oldest
+type byModTime []dirFile
+
+func (s byModTime) Len() int           { return len(s) }
+func (s byModTime) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byModTime) Less(i, j int) bool { return s[i].info.ModTime().After(s[j].info.ModTime()) }
+
+// takeStatedumps signals the bricks running on this peer to take statedumps
+func takeStatedumps() {
+	vols, err := volume.GetVolumes()
+	if err != nil {
+		log.WithError(err).Warn("failed to get volumes to take statedumps of")
+		return
+	}
+	for _, v := range vols {
+		for _, b := range v.Bricks {
+			if !uuid.Equal(b.NodeID, gdctx.MyUUID) {
+				continue
+			}
+			gfsd, err := brick.NewGlusterfsd(b)
+			if err != nil {
+				continue
+			}
+			pid, err := daemon.ReadPidFromFile(gfsd.PidFile())
+			if err != nil {
+				continue
+			}
+			if err := unix.Kill(pid, unix.SIGUSR1); err != nil {
+				log.WithError(err).WithField("brick", b.Path).Warn("failed to take statedump of brick")
+			}
+		}
+	}
+	time.Sleep(statedumpWait)
+}
+
+// collectLocal writes the logs, statedumps and volfiles of this peer into the
+// tar archive. The names of the files are relative to dir.
+func collectLocal(tw *tar.Writer, dir string, opts *Options) *PeerResult {
+	c := &collector{
+		tw:       tw,
+		opts:     opts,
+		throttle: newThrottle(config.GetInt("debug-collect-rate")),
+		result: &PeerResult{
+			ID:   gdctx.MyUUID.String(),
+			Name: gdctx.HostName,
+		},
+	}
+
+	if opts.includes(Statedumps) {
+		since := time.Now()
+		if opts.TakeStatedumps {
+			takeStatedumps()
+		}
+		c.addDir(path.Join(dir, Statedumps), statedumpDir, func(info os.FileInfo) bool {
+			if !strings.Contains(info.Name(), ".dump.") {
+				return false
+			}
+			return !opts.TakeStatedumps || info.ModTime().After(since)
+		})
+	}
+	if opts.includes(Logs) {
+		c.addDir(path.Join(dir, Logs), config.GetString("logdir"), nil)
+	}
+	if opts.includes(Volfiles) {
+		c.addDir(path.Join(dir, Volfiles), path.Join(config.GetString("localstatedir"), "vols"), func(info os.FileInfo) bool {
+			return strings.HasSuffix(info.Name(), ".vol")
+		})
+	}
+
+	if c.err != nil {
+		c.result.Error = c.err.Error()
+	}
+	return c.result
+}
+
+// marshal returns the value as indented JSON, for the files of the archive
+// meant to be read by people
+func marshal(v interface{}) []byte {
+	data, _ := json.MarshalIndent(v, "", "  ")
+	return append(data, '\n')
+}
@@ -0,0 +1,19 @@
+package collect
+
+import (
+	"regexp"
+)
+
+// redacted replaces the secrets in the collected files
+const redacted = "<redacted>"
+
+// secretPattern matches the values of passwords, tokens and other secrets, as
+// found in volfiles (option password <value>), JSON (Password":"<value>"),
+// key=value pairs and Authorization headers. The first group is the part
+// before the value, which is kept.
+var secretPattern = regexp.MustCompile(`(?i)((?:password|passwd|secret|token|username|authorization)["']?\s*[=:\s]\s*["']?(?:(?:basic|bearer)\s+)?)[^\s"',}]+`)
+
+// Redact returns the data with the values of secrets replaced
+func Redact(data []byte) []byte {
+	return secretPattern.ReplaceAll(data, []byte("${1}"+redacted))
+}
@@ -0,0 +1,53 @@
+package collect
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/gluster/glusterd2/store"
+	"github.com/gluster/glusterd2/tests"
+
+	"github.com/coreos/etcd/mvcc/mvccpb"
+)
+
+func TestRedact(t *testing.T) {
+	cases := map[string]string{
+		"    option password 5e0bdd2d-0b9f-4d26":       "    option password <redacted>",
+		"    option username admin\n":                  "    option username <redacted>\n",
+		`{"Username":"u1","Password":"p1","Name":"v"}`: `{"Username":"<redacted>","Password":"<redacted>","Name":"v"}`,
+		"trusted-password=secret123 other=1":           "trusted-password=<redacted> other=1",
+		"Authorization: Basic dXNlcjpwYXNz":            "Authorization: Basic <redacted>",
+		"volume gv0 started":                           "volume gv0 started",
+	}
+	for in, out := range cases {
+		tests.Assert(t, string(Redact([]byte(in))) == out)
+	}
+}
+
+func TestRedactStoreDump(t *testing.T) {
+	body := base64.StdEncoding.EncodeToString([]byte(`{"Auth":{"Username":"u-123","Password":"p-456"}}`))
+	kvs := []*mvccpb.KeyValue{
+		{
+			Key:   []byte(store.GlusterPrefix + "volumes/gv0"),
+			Value: []byte(`{"Name":"gv0","Auth":{"Username":"u-123","Password":"p-456"}}`),
+		},
+		{
+			Key:   []byte(store.GlusterPrefix + "idempotency/f00d"),
+			Value: []byte(`{"fingerprint":"abc","done":true,"status":201,"body":"` + body + `"}`),
+		},
+		{
+			Key:   []byte(store.GlusterPrefix + "options/cluster"),
+			Value: []byte("token=t-789"),
+		},
+	}
+
+	dump := string(dumpEntries(kvs))
+	for _, secret := range []string{"u-123", "p-456", "t-789"} {
+		tests.Assert(t, !strings.Contains(dump, secret))
+	}
+	// Values are nested in the dump, and only the secrets are redacted
+	tests.Assert(t, strings.Contains(dump, `"Name": "gv0"`))
+	tests.Assert(t, strings.Contains(dump, `"status": 201`))
+	tests.Assert(t, strings.Contains(dump, redacted))
+}
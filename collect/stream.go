@@ -0,0 +1,149 @@
+package collect
+
+import (
+	"archive/tar"
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"path"
+	"time"
+
+	"github.com/gluster/glusterd2/peer"
+	"github.com/gluster/glusterd2/servers/peerrpc"
+	"github.com/gluster/glusterd2/utils"
+
+	netctx "golang.org/x/net/context"
+)
+
+const (
+	// streamName is the name of the peer RPC stream the peers send their
+	// archives with
+	streamName = "debug-collect"
+	// resultFile is the file the result of the collection is sent as, at
+	// the end of the archive of a peer
+	resultFile = "result.json"
+
+	chunkSize = 64 << 10
+)
+
+func init() {
+	peerrpc.RegisterStreamHandler(streamName, streamHandler)
+}
+
+// sendWriter is an io.Writer sending the data over a peer RPC stream
+type sendWriter func([]byte) error
+
+func (s sendWriter) Write(p []byte) (int, error) {
+	// The data is copied, as the buffered writer reuses its buffer
+	if err := s(append([]byte(nil), p...)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// streamHandler sends the archive of the files of this peer to the peer
+// collecting them, as a tar stream
+func streamHandler(ctx netctx.Context, args []byte, send func([]byte) error) error {
+	var opts Options
+	if err := json.Unmarshal(args, &opts); err != nil {
+		return err
+	}
+
+	if err := begin(); err != nil {
+		return err
+	}
+	defer end()
+
+	w := bufio.NewWriterSize(sendWriter(send), chunkSize)
+	tw := tar.NewWriter(w)
+
+	result := collectLocal(tw, "", &opts)
+	if result.Error != "" {
+		return errors.New(result.Error)
+	}
+	if err := writeFile(tw, resultFile, marshal(result), time.Now()); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// streamReader is an io.Reader reading the data of a peer RPC stream
+type streamReader struct {
+	s   *peerrpc.Stream
+	buf []byte
+}
+
+func (r *streamReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		data, err := r.s.Recv()
+		if err != nil {
+			return 0, err
+		}
+		r.buf = data
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// zeros is an io.Reader of zero bytes
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
+
+// collectRemote copies the archive of the files of a remote peer into the tar
+// archive, with the names of the files under dir
+func collectRemote(ctx netctx.Context, tw *tar.Writer, dir string, p *peer.Peer, opts *Options) *PeerResult {
+	result := &PeerResult{ID: p.ID.String(), Name: p.Name}
+	fail := func(err error) *PeerResult {
+		result.Error = err.Error()
+		return result
+	}
+
+	address, err := utils.FormRemotePeerAddress(p.Addresses[0])
+	if err != nil {
+		return fail(err)
+	}
+	s, err := peerrpc.OpenStream(ctx, address, streamName, opts)
+	if err != nil {
+		return fail(err)
+	}
+	defer s.Close()
+
+	tr := tar.NewReader(&streamReader{s: s})
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return fail(err)
+		}
+
+		if hdr.Name == resultFile {
+			if err := json.NewDecoder(tr).Decode(result); err != nil {
+				return fail(err)
+			}
+			continue
+		}
+
+		hdr.Name = path.Join(dir, hdr.Name)
+		if err := tw.WriteHeader(hdr); err != nil {
+			return fail(err)
+		}
+		if n, err := io.Copy(tw, tr); err != nil {
+			// The file is padded, for the archive to remain readable
+			io.CopyN(tw, zeros{}, hdr.Size-n)
+			return fail(err)
+		}
+	}
+	return result
+}
@@ -0,0 +1,48 @@
+package collect
+
+import (
+	"io"
+	"time"
+)
+
+// throttle limits the rate files are read at while collecting them, so that
+// collecting doesn't starve the bricks of disk bandwidth
+type throttle struct {
+	// rate is the limit in bytes per second. There is no limit if it is 0.
+	rate  int
+	start time.Time
+	n     int64
+}
+
+func newThrottle(rate int) *throttle {
+	return &throttle{rate: rate, start: time.Now()}
+}
+
+// wait accounts for n bytes read, and sleeps until reading them is within the
+// rate limit
+func (t *throttle) wait(n int) {
+	if t.rate <= 0 {
+		return
+	}
+	t.n += int64(n)
+	due := t.start.Add(time.Duration(float64(t.n) / float64(t.rate) * float64(time.Second)))
+	if d := due.Sub(time.Now()); d > 0 {
+		time.Sleep(d)
+	}
+}
+
+type throttledReader struct {
+	r io.Reader
+	t *throttle
+}
+
+func (r *throttledReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.t.wait(n)
+	return n, err
+}
+
+// reader returns a reader which reads from r within the rate limit
+func (t *throttle) reader(r io.Reader) io.Reader {
+	return &throttledReader{r, t}
+}
@@ -0,0 +1,67 @@
+package clustercommands
+
+import (
+	"net/http"
+
+	"github.com/gluster/glusterd2/backup"
+	"github.com/gluster/glusterd2/errors"
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/utils"
+	"github.com/gluster/glusterd2/validation"
+)
+
+func getBackupHandler(w http.ResponseWriter, r *http.Request) {
+	b, err := backup.New()
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	restutils.SendHTTPResponse(w, http.StatusOK, b)
+}
+
+// restoreHandler restores the configuration of a cluster from a backup. The
+// keys which would be restored, and those which conflict with the current
+// configuration, are reported. Nothing is restored if there are conflicts,
+// unless force is set.
+//
+// Only the configuration in the store is restored. The peers have to be
+// started with their original IDs to rejoin the cluster, and volumes have to
+// be started again to regenerate the brick volfiles on the peers.
+func restoreHandler(w http.ResponseWriter, r *http.Request) {
+	_, logger := restutils.GetReqIDandLogger(r)
+
+	var b backup.Backup
+	if err := utils.GetJSONFromRequest(r, &b); err != nil {
+		restutils.SendHTTPError(w, http.StatusUnprocessableEntity, errors.ErrJSONParsingFailed.Error())
+		return
+	}
+	var errs validation.Errors
+	errs.Check("entries", b.Validate())
+	if errs != nil {
+		restutils.SendValidationErrors(w, errs)
+		return
+	}
+
+	force := r.URL.Query().Get("force") == "true"
+	dryRun := r.URL.Query().Get("dry-run") == "true"
+
+	report, err := b.Restore(force, dryRun)
+	if err == errors.ErrBackupConflict {
+		restutils.SendHTTPError(w, http.StatusConflict, err.Error())
+		return
+	} else if err != nil {
+		logger.WithError(err).Error("failed to restore backup")
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if len(report.Conflicts) != 0 && !force {
+		restutils.SendHTTPResponse(w, http.StatusConflict, report)
+		return
+	}
+
+	if !dryRun {
+		logger.WithField("keys", len(report.Restored)).Info("restored backup")
+	}
+	restutils.SendHTTPResponse(w, http.StatusOK, report)
+}
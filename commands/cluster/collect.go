@@ -0,0 +1,108 @@
+package clustercommands
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gluster/glusterd2/collect"
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/peer"
+	"github.com/gluster/glusterd2/pkg/api"
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/utils"
+	"github.com/gluster/glusterd2/validation"
+)
+
+// collectPeers returns the peers with the given IDs or names, or all the peers
+// if none are given
+func collectPeers(names []string) ([]peer.Peer, error) {
+	peers, err := peer.GetPeers()
+	if err != nil || len(names) == 0 {
+		return peers, err
+	}
+
+	var selected []peer.Peer
+	for _, name := range names {
+		found := false
+		for _, p := range peers {
+			if p.ID.String() == name || p.Name == name {
+				selected = append(selected, p)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown peer %s", name)
+		}
+	}
+	return selected, nil
+}
+
+// debugCollectHandler streams an archive of the logs, statedumps, volfiles
+// and a dump of the store of the peers, as a gzipped tar. Peers which can't be
+// collected from are reported in the manifest.json of the archive, and can be
+// collected from again by listing them in peers.
+func debugCollectHandler(w http.ResponseWriter, r *http.Request) {
+	_, logger := restutils.GetReqIDandLogger(r)
+
+	var req api.DebugCollectReq
+	if err := utils.GetJSONFromRequest(r, &req); err != nil {
+		restutils.SendHTTPError(w, http.StatusUnprocessableEntity, errors.ErrJSONParsingFailed.Error())
+		return
+	}
+
+	opts := &collect.Options{
+		Include:        req.Include,
+		TakeStatedumps: req.TakeStatedumps,
+		MaxFileSize:    req.MaxFileSize,
+		MaxPeerSize:    req.MaxPeerSize,
+	}
+	if len(opts.Include) == 0 {
+		opts.Include = collect.Kinds
+	}
+	var errs validation.Errors
+	for i, kind := range opts.Include {
+		errs.OneOf(fmt.Sprintf("include[%d]", i), kind, collect.Kinds...)
+	}
+	if opts.MaxFileSize < 0 {
+		errs.Add("max-file-size", "must not be negative")
+	}
+	if opts.MaxPeerSize < 0 {
+		errs.Add("max-peer-size", "must not be negative")
+	}
+	if errs != nil {
+		restutils.SendValidationErrors(w, errs)
+		return
+	}
+	if opts.MaxFileSize == 0 {
+		opts.MaxFileSize = collect.DefaultMaxFileSize
+	}
+	if opts.MaxPeerSize == 0 {
+		opts.MaxPeerSize = collect.DefaultMaxPeerSize
+	}
+
+	peers, err := collectPeers(req.Peers)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	created := time.Now()
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.tar.gz", collect.Name(created)))
+
+	// Nothing has been written when the collection fails to start, so the
+	// error can still be sent. Later errors can only be logged, as the
+	// archive is streamed.
+	err = collect.Collect(r.Context(), w, created, peers, opts)
+	if err == collect.ErrInProgress {
+		w.Header().Del("Content-Disposition")
+		restutils.SendHTTPError(w, http.StatusConflict, err.Error())
+		return
+	} else if err != nil {
+		logger.WithError(err).Error("failed to collect debug information")
+		return
+	}
+	logger.WithField("peers", len(peers)).Info("collected debug information")
+}
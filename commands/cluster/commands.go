@@ -0,0 +1,100 @@
+// Package clustercommands implements the cluster wide management commands
+package clustercommands
+
+import (
+	"github.com/gluster/glusterd2/servers/rest/route"
+)
+
+// Command is a holding struct used to implement the GlusterD Command interface
+type Command struct {
+}
+
+// Routes returns command routes. Required for the Command interface.
+func (c *Command) Routes() route.Routes {
+	return route.Routes{
+		route.Route{
+			Name:        "GetQuorum",
+			Method:      "GET",
+			Pattern:     "/cluster/quorum",
+			Version:     1,
+			HandlerFunc: getQuorumHandler},
+		route.Route{
+			Name:        "SetQuorum",
+			Method:      "POST",
+			Pattern:     "/cluster/quorum",
+			Version:     1,
+			HandlerFunc: setQuorumHandler},
+		route.Route{
+			Name:        "GetOpVersion",
+			Method:      "GET",
+			Pattern:     "/cluster/op-version",
+			Version:     1,
+			HandlerFunc: getOpVersionHandler},
+		route.Route{
+			Name:        "SetOpVersion",
+			Method:      "POST",
+			Pattern:     "/cluster/op-version",
+			Version:     1,
+			HandlerFunc: setOpVersionHandler},
+		route.Route{
+			Name:        "GetLeader",
+			Method:      "GET",
+			Pattern:     "/cluster/leader",
+			Version:     1,
+			HandlerFunc: getLeaderHandler},
+		route.Route{
+			Name:        "GetGCReport",
+			Method:      "GET",
+			Pattern:     "/cluster/gc",
+			Version:     1,
+			HandlerFunc: getGCReportHandler},
+		route.Route{
+			Name:        "GetDiskUsageConfig",
+			Method:      "GET",
+			Pattern:     "/cluster/disk-usage",
+			Version:     1,
+			HandlerFunc: getDiskUsageHandler},
+		route.Route{
+			Name:        "SetDiskUsageConfig",
+			Method:      "POST",
+			Pattern:     "/cluster/disk-usage",
+			Version:     1,
+			HandlerFunc: setDiskUsageHandler},
+		route.Route{
+			Name:        "GetClusterOptions",
+			Method:      "GET",
+			Pattern:     "/cluster/options",
+			Version:     1,
+			HandlerFunc: getClusterOptionsHandler},
+		route.Route{
+			Name:        "SetClusterOptions",
+			Method:      "POST",
+			Pattern:     "/cluster/options",
+			Version:     1,
+			HandlerFunc: setClusterOptionsHandler},
+		route.Route{
+			Name:        "Backup",
+			Method:      "GET",
+			Pattern:     "/backup",
+			Version:     1,
+			HandlerFunc: getBackupHandler},
+		route.Route{
+			Name:        "Restore",
+			Method:      "POST",
+			Pattern:     "/restore",
+			Version:     1,
+			HandlerFunc: restoreHandler},
+		route.Route{
+			Name:        "DebugCollect",
+			Method:      "POST",
+			Pattern:     "/debug/collect",
+			Version:     1,
+			Produces:    []string{"application/gzip"},
+			HandlerFunc: debugCollectHandler},
+	}
+}
+
+// RegisterStepFuncs implements a required function for the Command interface
+func (c *Command) RegisterStepFuncs() {
+	registerGCStepFuncs()
+}
@@ -0,0 +1,55 @@
+package clustercommands
+
+import (
+	"net/http"
+
+	"github.com/gluster/glusterd2/diskusage"
+	"github.com/gluster/glusterd2/errors"
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/utils"
+	"github.com/gluster/glusterd2/validation"
+)
+
+func getDiskUsageHandler(w http.ResponseWriter, r *http.Request) {
+	c, err := diskusage.GetConfig()
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	restutils.SendHTTPResponse(w, http.StatusOK, c)
+}
+
+// setDiskUsageHandler updates the disk usage watermarks and reserve of the
+// cluster. The brick health monitor on every node picks up the new
+// watermarks. The reserve is set on the bricks when their volfiles are
+// generated again, which happens when the volume is started or its options
+// are changed.
+func setDiskUsageHandler(w http.ResponseWriter, r *http.Request) {
+	_, logger := restutils.GetReqIDandLogger(r)
+
+	var req diskusage.Config
+	if err := utils.GetJSONFromRequest(r, &req); err != nil {
+		restutils.SendHTTPError(w, http.StatusUnprocessableEntity, errors.ErrJSONParsingFailed.Error())
+		return
+	}
+
+	var errs validation.Errors
+	errs.Range("warning", req.Warning, 0, 100)
+	errs.Range("critical", req.Critical, 0, 100)
+	errs.Range("reserve", req.Reserve, 0, 100)
+	if req.Warning > req.Critical {
+		errs.Add("warning", "must not be above the critical watermark")
+	}
+	if errs != nil {
+		restutils.SendValidationErrors(w, errs)
+		return
+	}
+
+	if err := diskusage.SetConfig(&req); err != nil {
+		logger.WithError(err).Error("failed to save disk usage configuration")
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	restutils.SendHTTPResponse(w, http.StatusOK, &req)
+}
@@ -0,0 +1,83 @@
+package clustercommands
+
+import (
+	"net/http"
+
+	"github.com/gluster/glusterd2/gc"
+	"github.com/gluster/glusterd2/gdctx"
+	"github.com/gluster/glusterd2/peer"
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/transaction"
+)
+
+const gcTxnKey = "orphans"
+
+// GCReportResp is the response sent for an orphaned data report request
+type GCReportResp struct {
+	Orphans []*gc.Orphan `json:"orphans"`
+}
+
+func findOrphans(c transaction.TxnCtx) error {
+	orphans, err := gc.FindLocal()
+	if err != nil {
+		return err
+	}
+	return c.SetNodeResult(gdctx.MyUUID, gcTxnKey, orphans)
+}
+
+func registerGCStepFuncs() {
+	transaction.RegisterStepFunc(findOrphans, "gc.FindOrphans")
+}
+
+// getGCReportHandler reports the orphaned data found on all the peers and in
+// the store, without cleaning it up. Orphaned data is cleaned up by the
+// reconciler once it has been orphaned for long enough.
+func getGCReportHandler(w http.ResponseWriter, r *http.Request) {
+	reqID, logger := restutils.GetReqIDandLogger(r)
+
+	nodes, err := peer.GetPeerIDs()
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	txn := transaction.NewTxn(reqID)
+	defer txn.Cleanup()
+	txn.Nodes = nodes
+	txn.Steps = []*transaction.Step{
+		{
+			DoFunc: "gc.FindOrphans",
+			Nodes:  txn.Nodes,
+		},
+	}
+
+	rtxn, err := txn.Do()
+	if err != nil {
+		logger.WithError(err).Error("failed to look for orphaned data")
+		if transaction.IsTimeout(err) {
+			restutils.SendHTTPError(w, http.StatusGatewayTimeout, err.Error())
+		} else {
+			restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	resp := GCReportResp{Orphans: []*gc.Orphan{}}
+	for _, n := range txn.Nodes {
+		var orphans []*gc.Orphan
+		if err := rtxn.GetNodeResult(n, gcTxnKey, &orphans); err != nil {
+			restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		resp.Orphans = append(resp.Orphans, orphans...)
+	}
+
+	orphans, err := gc.FindStore()
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	resp.Orphans = append(resp.Orphans, orphans...)
+
+	restutils.SendHTTPResponse(w, http.StatusOK, &resp)
+}
@@ -0,0 +1,24 @@
+package clustercommands
+
+import (
+	"net/http"
+
+	"github.com/gluster/glusterd2/leader"
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+
+	"github.com/pborman/uuid"
+)
+
+// LeaderResp is the response sent for a cluster leader request
+type LeaderResp struct {
+	Leader uuid.UUID `json:"leader"`
+}
+
+func getLeaderHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := leader.Leader()
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	restutils.SendHTTPResponse(w, http.StatusOK, &LeaderResp{Leader: id})
+}
@@ -0,0 +1,59 @@
+package clustercommands
+
+import (
+	"net/http"
+
+	"github.com/gluster/glusterd2/clusteroptions"
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/pkg/api"
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/utils"
+	"github.com/gluster/glusterd2/validation"
+)
+
+func getClusterOptionsHandler(w http.ResponseWriter, r *http.Request) {
+	values, err := clusteroptions.GetAll()
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	restutils.SendHTTPResponse(w, http.StatusOK, values)
+}
+
+// setClusterOptionsHandler updates the options of the cluster. Options set to
+// an empty value are reset to their default. The subsystems using the options
+// are notified of the change on every node.
+func setClusterOptionsHandler(w http.ResponseWriter, r *http.Request) {
+	_, logger := restutils.GetReqIDandLogger(r)
+
+	var req api.ClusterOptionReq
+	if err := utils.GetJSONFromRequest(r, &req); err != nil {
+		restutils.SendHTTPError(w, http.StatusUnprocessableEntity, errors.ErrJSONParsingFailed.Error())
+		return
+	}
+
+	var errs validation.Errors
+	if errs.RequireList("options", len(req.Options)) {
+		errs.Check("options", clusteroptions.Check(req.Options))
+	}
+	if errs != nil {
+		restutils.SendValidationErrors(w, errs)
+		return
+	}
+
+	if err := clusteroptions.Set(req.Options); err == errors.ErrClusterOptConflict {
+		restutils.SendHTTPError(w, http.StatusConflict, err.Error())
+		return
+	} else if err != nil {
+		logger.WithError(err).Error("failed to save cluster options")
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	values, err := clusteroptions.GetAll()
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	restutils.SendHTTPResponse(w, http.StatusOK, values)
+}
@@ -0,0 +1,75 @@
+package clustercommands
+
+import (
+	"net/http"
+
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/opversion"
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/utils"
+	"github.com/gluster/glusterd2/validation"
+)
+
+// OpVersionResp is the response sent for op-version requests
+type OpVersionResp struct {
+	OpVersion    int `json:"op-version"`
+	MaxOpVersion int `json:"max-op-version"`
+}
+
+// OpVersionReq represents a request to bump the cluster op-version
+type OpVersionReq struct {
+	OpVersion int `json:"op-version"`
+}
+
+func sendOpVersion(w http.ResponseWriter) {
+	var resp OpVersionResp
+	var err error
+
+	if resp.OpVersion, err = opversion.Get(); err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if resp.MaxOpVersion, err = opversion.ClusterMax(); err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	restutils.SendHTTPResponse(w, http.StatusOK, resp)
+}
+
+func getOpVersionHandler(w http.ResponseWriter, r *http.Request) {
+	sendOpVersion(w)
+}
+
+func setOpVersionHandler(w http.ResponseWriter, r *http.Request) {
+	_, logger := restutils.GetReqIDandLogger(r)
+
+	var req OpVersionReq
+	if err := utils.GetJSONFromRequest(r, &req); err != nil {
+		restutils.SendHTTPError(w, http.StatusUnprocessableEntity, errors.ErrJSONParsingFailed.Error())
+		return
+	}
+
+	var errs validation.Errors
+	if req.OpVersion <= 0 {
+		errs.Add("op-version", "must be a positive number")
+	}
+	if errs != nil {
+		restutils.SendValidationErrors(w, errs)
+		return
+	}
+
+	if err := opversion.Set(req.OpVersion); err != nil {
+		logger.WithError(err).WithField(
+			"op-version", req.OpVersion).Error("failed to set cluster op-version")
+		switch err {
+		case errors.ErrOpVersionDowngrade, errors.ErrOpVersionNotSupported:
+			restutils.SendHTTPError(w, http.StatusBadRequest, err.Error())
+		default:
+			restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	sendOpVersion(w)
+}
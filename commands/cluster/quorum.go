@@ -0,0 +1,53 @@
+package clustercommands
+
+import (
+	"net/http"
+
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/quorum"
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/utils"
+	"github.com/gluster/glusterd2/validation"
+)
+
+func getQuorumHandler(w http.ResponseWriter, r *http.Request) {
+	s, err := quorum.GetStatus()
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	restutils.SendHTTPResponse(w, http.StatusOK, s)
+}
+
+// setQuorumHandler updates the server quorum configuration of the cluster.
+// The quorum monitor on every node picks up the change and starts or stops
+// the local bricks accordingly.
+func setQuorumHandler(w http.ResponseWriter, r *http.Request) {
+	_, logger := restutils.GetReqIDandLogger(r)
+
+	var req quorum.Config
+	if err := utils.GetJSONFromRequest(r, &req); err != nil {
+		restutils.SendHTTPError(w, http.StatusUnprocessableEntity, errors.ErrJSONParsingFailed.Error())
+		return
+	}
+
+	var errs validation.Errors
+	errs.Check("ratio", req.Validate())
+	if errs != nil {
+		restutils.SendValidationErrors(w, errs)
+		return
+	}
+
+	if err := quorum.SetConfig(&req); err != nil {
+		logger.WithError(err).Error("failed to save quorum configuration")
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s, err := quorum.GetStatus()
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	restutils.SendHTTPResponse(w, http.StatusOK, s)
+}
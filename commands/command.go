@@ -2,7 +2,10 @@
 package commands
 
 import (
+	"github.com/gluster/glusterd2/commands/cluster"
+	"github.com/gluster/glusterd2/commands/health"
 	"github.com/gluster/glusterd2/commands/peers"
+	"github.com/gluster/glusterd2/commands/snapshot"
 	"github.com/gluster/glusterd2/commands/version"
 	"github.com/gluster/glusterd2/commands/volumes"
 	"github.com/gluster/glusterd2/servers/rest/route"
@@ -19,6 +22,9 @@ type Command interface {
 // Commands is a list of commands available
 var Commands = []Command{
 	&versioncommands.Command{},
+	&healthcommands.Command{},
 	&volumecommands.Command{},
 	&peercommands.Command{},
+	&clustercommands.Command{},
+	&snapshotcommands.Command{},
 }
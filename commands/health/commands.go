@@ -0,0 +1,45 @@
+// Package healthcommands implements the health check end points
+package healthcommands
+
+import (
+	"github.com/gluster/glusterd2/servers/rest/route"
+)
+
+// Command is a holding struct used to implement the GlusterD Command interface
+type Command struct {
+}
+
+// Routes returns command routes. Required for the Command interface.
+func (c *Command) Routes() route.Routes {
+	return route.Routes{
+		route.Route{
+			Name:        "Ping",
+			Method:      "GET",
+			Pattern:     "/ping",
+			HandlerFunc: pingHandler,
+		},
+		route.Route{
+			Name:        "Ready",
+			Method:      "GET",
+			Pattern:     "/ready",
+			HandlerFunc: readyHandler,
+		},
+		route.Route{
+			Name:        "Health",
+			Method:      "GET",
+			Pattern:     "/health",
+			HandlerFunc: healthHandler,
+		},
+		route.Route{
+			Name:        "Metrics",
+			Method:      "GET",
+			Pattern:     "/metrics",
+			HandlerFunc: metricsHandler,
+		},
+	}
+}
+
+// RegisterStepFuncs implements a required function for the Command interface
+func (c *Command) RegisterStepFuncs() {
+	return
+}
@@ -0,0 +1,88 @@
+package healthcommands
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gluster/glusterd2/gdctx"
+	"github.com/gluster/glusterd2/quorum"
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/store"
+)
+
+const storePingTimeout = 2 * time.Second
+
+// StoreHealth represents the connectivity of this node to the store
+type StoreHealth struct {
+	Reachable bool   `json:"reachable"`
+	Error     string `json:"error,omitempty"`
+}
+
+// HealthResponse represents the structure of the response object for the
+// /health end point
+type HealthResponse struct {
+	Healthy   bool            `json:"healthy"`
+	Store     StoreHealth     `json:"store"`
+	Quorum    *quorum.Status  `json:"quorum,omitempty"`
+	Listeners map[string]bool `json:"listeners"`
+}
+
+// MetricsResponse represents the structure of the response object for the
+// /metrics end point
+type MetricsResponse struct {
+	Caches []store.CacheStats `json:"caches"`
+}
+
+func storeHealth() StoreHealth {
+	if store.Store == nil {
+		return StoreHealth{Error: "store not initialized"}
+	}
+	if err := store.Store.Ping(storePingTimeout); err != nil {
+		return StoreHealth{Error: err.Error()}
+	}
+	return StoreHealth{Reachable: true}
+}
+
+// pingHandler only reports that the REST server is able to serve requests
+func pingHandler(w http.ResponseWriter, r *http.Request) {
+	restutils.SendHTTPResponse(w, http.StatusOK, nil)
+}
+
+// readyHandler reports if glusterd2 is ready to serve management requests,
+// which requires the store to be reachable
+func readyHandler(w http.ResponseWriter, r *http.Request) {
+	s := storeHealth()
+	if !s.Reachable {
+		restutils.SendHTTPResponse(w, http.StatusServiceUnavailable, s)
+		return
+	}
+	restutils.SendHTTPResponse(w, http.StatusOK, s)
+}
+
+func healthHandler(w http.ResponseWriter, r *http.Request) {
+	resp := HealthResponse{
+		Store:     storeHealth(),
+		Listeners: gdctx.ListenerStates(),
+	}
+	if resp.Store.Reachable {
+		resp.Quorum, _ = quorum.GetStatus()
+	}
+
+	// A cluster with server quorum disabled is healthy whatever the
+	// number of peers online, and is reported with the disabled state
+	resp.Healthy = resp.Store.Reachable && resp.Quorum != nil && resp.Quorum.State != quorum.StateLost
+	for _, up := range resp.Listeners {
+		resp.Healthy = resp.Healthy && up
+	}
+
+	if !resp.Healthy {
+		restutils.SendHTTPResponse(w, http.StatusServiceUnavailable, resp)
+		return
+	}
+	restutils.SendHTTPResponse(w, http.StatusOK, resp)
+}
+
+// metricsHandler reports the hit rates of the in-memory store caches
+func metricsHandler(w http.ResponseWriter, r *http.Request) {
+	restutils.SendHTTPResponse(w, http.StatusOK, MetricsResponse{store.AllCacheStats()})
+}
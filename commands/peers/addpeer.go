@@ -9,6 +9,7 @@ import (
 	restutils "github.com/gluster/glusterd2/servers/rest/utils"
 	"github.com/gluster/glusterd2/store"
 	"github.com/gluster/glusterd2/utils"
+	"github.com/gluster/glusterd2/validation"
 
 	log "github.com/Sirupsen/logrus"
 )
@@ -24,8 +25,19 @@ func addPeerHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var errs validation.Errors
 	if len(req.Addresses) < 1 {
-		restutils.SendHTTPError(w, http.StatusBadRequest, errors.ErrNoHostnamesPresent.Error())
+		errs.Add("addresses", errors.ErrNoHostnamesPresent.Error())
+	}
+	for i, addr := range req.Addresses {
+		f := fmt.Sprintf("addresses[%d]", i)
+		if errs.RequireString(f, addr) {
+			_, err := utils.FormRemotePeerAddress(addr)
+			errs.Check(f, err)
+		}
+	}
+	if errs != nil {
+		restutils.SendValidationErrors(w, errs)
 		return
 	}
 	log.WithField("addresses", req.Addresses).Debug("received request to add new peer with given addresses")
@@ -54,7 +66,7 @@ func addPeerHandler(w http.ResponseWriter, r *http.Request) {
 	defer client.conn.Close()
 	logger := log.WithField("peer", remotePeerAddress)
 
-	newconfig := &StoreConfig{store.Store.Endpoints()}
+	newconfig := &StoreConfig{store.Store.Endpoints(), store.Store.ClusterID()}
 	logger.WithField("endpoints", newconfig.Endpoints).Debug("asking new peer to join cluster with given endpoints")
 
 	// Ask the peer to join the cluster
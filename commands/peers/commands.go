@@ -3,6 +3,7 @@ package peercommands
 
 import (
 	"github.com/gluster/glusterd2/servers/rest/route"
+	"github.com/gluster/glusterd2/transaction"
 )
 
 // Command is a holding struct used to implement the GlusterD Command interface
@@ -54,10 +55,25 @@ func (c *Command) Routes() route.Routes {
 			Version:     1,
 			HandlerFunc: addPeerHandler,
 		},
+		route.Route{
+			Name:        "EditPeer",
+			Method:      "PATCH",
+			Pattern:     "/peers/{peerid}/metadata",
+			Version:     1,
+			HandlerFunc: editPeerHandler,
+		},
+		route.Route{
+			Name:        "PeerRename",
+			Method:      "POST",
+			Pattern:     "/peers/{peerid}/rename",
+			Version:     1,
+			HandlerFunc: peerRenameHandler,
+		},
 	}
 }
 
 // RegisterStepFuncs implements a required function for the Command interface
 func (c *Command) RegisterStepFuncs() {
-	return
+	transaction.RegisterStepFunc(storePeerMetaData, "peer-edit.Store")
+	registerPeerRenameStepFuncs()
 }
@@ -5,6 +5,7 @@ import (
 
 	"github.com/gluster/glusterd2/gdctx"
 	"github.com/gluster/glusterd2/peer"
+	"github.com/gluster/glusterd2/servers/peerrpc"
 	restutils "github.com/gluster/glusterd2/servers/rest/utils"
 	"github.com/gluster/glusterd2/store"
 	"github.com/gluster/glusterd2/utils"
@@ -84,6 +85,9 @@ func deletePeerHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	defer client.conn.Close()
 
+	// The pooled connection used for transactions is no longer needed
+	defer peerrpc.CloseConn(remotePeerAddress)
+
 	// TODO: Need to do a better job of handling failures here. If this fails the
 	// peer being removed still thinks it's a part of the cluster, and could
 	// potentially still send commands to the cluster
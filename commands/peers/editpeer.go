@@ -0,0 +1,135 @@
+package peercommands
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/gdctx"
+	"github.com/gluster/glusterd2/peer"
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/transaction"
+	"github.com/gluster/glusterd2/utils"
+	"github.com/gluster/glusterd2/validation"
+
+	"github.com/gorilla/mux"
+	"github.com/pborman/uuid"
+)
+
+type peerEditReq struct {
+	MetaData map[string]string `json:"metadata"`
+}
+
+// storePeerMetaData applies the requested metadata changes to the peer. The
+// peer is updated with a compare-and-swap on its latest version, so that
+// edits of different keys and changes of the peer made by other operations
+// are not lost.
+func storePeerMetaData(c transaction.TxnCtx) error {
+	var id string
+	if err := c.Get("peerid", &id); err != nil {
+		return err
+	}
+	var req peerEditReq
+	if err := c.Get("req", &req); err != nil {
+		return err
+	}
+
+	p, err := peer.UpdatePeer(id, func(p *peer.Peer) error {
+		if p.MetaData == nil {
+			p.MetaData = make(map[string]string)
+		}
+		for k, v := range req.MetaData {
+			// An empty value removes the key
+			if v == "" {
+				delete(p.MetaData, k)
+			} else {
+				p.MetaData[k] = v
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	c.Logger().WithField("metadata", p.MetaData).Debug("updated peer metadata")
+	return nil
+}
+
+func editPeerHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["peerid"]
+	if id == "" {
+		restutils.SendHTTPError(w, http.StatusBadRequest, "peerid not present in request")
+		return
+	}
+	reqID, logger := restutils.GetReqIDandLogger(r)
+
+	var req peerEditReq
+	if err := utils.GetJSONFromRequest(r, &req); err != nil {
+		restutils.SendHTTPError(w, http.StatusUnprocessableEntity, errors.ErrJSONParsingFailed.Error())
+		return
+	}
+
+	var errs validation.Errors
+	for k := range req.MetaData {
+		if strings.TrimSpace(k) == "" {
+			errs.Add("metadata", errors.ErrInvalidPeerMetaDataKey.Error())
+			break
+		}
+	}
+	if errs != nil {
+		restutils.SendValidationErrors(w, errs)
+		return
+	}
+
+	p, err := peer.GetPeerF(id)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	logger = logger.WithField("peerid", p.ID.String())
+
+	lock, unlock, err := transaction.CreateLockSteps(p.ID.String())
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	txn := transaction.NewTxn(reqID)
+	defer txn.Cleanup()
+	txn.Nodes = []uuid.UUID{gdctx.MyUUID}
+	txn.Steps = []*transaction.Step{
+		lock,
+		{
+			DoFunc: "peer-edit.Store",
+			Nodes:  txn.Nodes,
+		},
+		unlock,
+	}
+	if err := txn.Ctx.Set("peerid", p.ID.String()); err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if err := txn.Ctx.Set("req", &req); err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if _, err := txn.Do(); err != nil {
+		logger.WithError(err).Error("failed to update peer metadata")
+		if err == transaction.ErrLockTimeout {
+			restutils.SendHTTPError(w, http.StatusConflict, err.Error())
+		} else if transaction.IsTimeout(err) {
+			restutils.SendHTTPError(w, http.StatusGatewayTimeout, err.Error())
+		} else {
+			restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	p, err = peer.GetPeerF(id)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	restutils.SendHTTPResponse(w, http.StatusOK, p)
+}
@@ -11,6 +11,8 @@ const (
 	ErrHaveVolumes
 	ErrStoreReconfigFailed
 	ErrUnknownPeer
+	ErrAlreadyMember
+	ErrStoreReadFailed
 	ErrMax
 )
 
@@ -22,6 +24,8 @@ func init() {
 	errorStrings[ErrHaveVolumes] = "peer has existing volumes"
 	errorStrings[ErrStoreReconfigFailed] = "store reconfigure failed on peer"
 	errorStrings[ErrUnknownPeer] = "request received from unknown peer"
+	errorStrings[ErrAlreadyMember] = "peer is already a part of the cluster"
+	errorStrings[ErrStoreReadFailed] = "could not read the store of the peer"
 }
 
 func (e Error) String() string {
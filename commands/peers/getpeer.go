@@ -5,6 +5,7 @@ import (
 
 	"github.com/gluster/glusterd2/peer"
 	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/store"
 
 	"github.com/gorilla/mux"
 )
@@ -18,9 +19,10 @@ func getPeerHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if peer, err := peer.GetPeerF(id); err != nil {
+	if peer, err := peer.GetPeerCached(id); err != nil {
 		restutils.SendHTTPError(w, http.StatusNotFound, err.Error())
 	} else {
+		peer.Online = store.Store.IsNodeAlive(peer.ID)
 		restutils.SendHTTPResponse(w, http.StatusOK, peer)
 	}
 }
@@ -2,15 +2,49 @@ package peercommands
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/gluster/glusterd2/peer"
 	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/store"
 )
 
+// metaDataQueryPrefix is the prefix of the query parameters filtering peers
+// by their metadata
+const metaDataQueryPrefix = "metadata."
+
+// getPeersHandler returns the list of peers in the cluster. Query parameters
+// prefixed with "metadata." filter the peers by metadata, and only peers
+// having all the given key/value pairs are returned. For example,
+// GET /v1/peers?metadata.zone=z1
 func getPeersHandler(w http.ResponseWriter, r *http.Request) {
-	if peers, err := peer.GetPeersF(); err != nil {
+	peers, err := peer.GetPeersCached()
+	if err != nil {
 		restutils.SendHTTPError(w, http.StatusNotFound, err.Error())
-	} else {
+		return
+	}
+
+	for i := range peers {
+		peers[i].Online = store.Store.IsNodeAlive(peers[i].ID)
+	}
+
+	query := r.URL.Query()
+	filter := make(map[string]string)
+	for k := range query {
+		if strings.HasPrefix(k, metaDataQueryPrefix) {
+			filter[strings.TrimPrefix(k, metaDataQueryPrefix)] = query.Get(k)
+		}
+	}
+	if len(filter) == 0 {
 		restutils.SendHTTPResponse(w, http.StatusOK, peers)
+		return
+	}
+
+	filtered := make([]peer.Peer, 0, len(peers))
+	for _, p := range peers {
+		if p.MatchesMetaData(filter) {
+			filtered = append(filtered, p)
+		}
 	}
+	restutils.SendHTTPResponse(w, http.StatusOK, filtered)
 }
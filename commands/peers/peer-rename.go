@@ -0,0 +1,474 @@
+package peercommands
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"sort"
+
+	"github.com/gluster/glusterd2/brick"
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/events"
+	"github.com/gluster/glusterd2/gdctx"
+	"github.com/gluster/glusterd2/peer"
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/servers/sunrpc"
+	"github.com/gluster/glusterd2/transaction"
+	"github.com/gluster/glusterd2/utils"
+	"github.com/gluster/glusterd2/validation"
+	"github.com/gluster/glusterd2/volgen"
+	"github.com/gluster/glusterd2/volume"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gorilla/mux"
+	"github.com/pborman/uuid"
+)
+
+// EventPeerRenamed is the name of the event emitted when the hostname of a
+// peer is changed
+const EventPeerRenamed = "peer.renamed"
+
+// PeerRenameReq represents a request to change the hostname of a peer
+type PeerRenameReq struct {
+	Hostname string `json:"hostname"`
+}
+
+// renamedAddresses returns the addresses of the peer with the new hostname as
+// its primary address, on the port of the old primary address. The old
+// primary address is dropped, as the old hostname may no longer resolve.
+func renamedAddresses(p *peer.Peer, hostname string) []string {
+	addrs := []string{hostname}
+	if len(p.Addresses) == 0 {
+		return addrs
+	}
+	if _, port, err := net.SplitHostPort(p.Addresses[0]); err == nil {
+		addrs[0] = net.JoinHostPort(hostname, port)
+	}
+	for _, a := range p.Addresses[1:] {
+		if !utils.IsPeerAddressSame(a, addrs[0]) {
+			addrs = append(addrs, a)
+		}
+	}
+	return addrs
+}
+
+// renameBricks returns the given volumes with bricks on the peer, as they are
+// and with the bricks on the peer renamed to the new hostname. Every such
+// volume must be among the locked ones, otherwise ErrPeerConflict is
+// returned.
+func renameBricks(vols []volume.Volinfo, id uuid.UUID, hostname string, locked []string) ([]volume.Volinfo, []volume.Volinfo, error) {
+	var oldVolinfos, volinfos []volume.Volinfo
+	for _, v := range vols {
+		renamed := v
+		renamed.Bricks = make([]brick.Brickinfo, len(v.Bricks))
+		copy(renamed.Bricks, v.Bricks)
+		onPeer := false
+		for i := range renamed.Bricks {
+			if uuid.Equal(renamed.Bricks[i].NodeID, id) {
+				renamed.Bricks[i].Hostname = hostname
+				onPeer = true
+			}
+		}
+		if !onPeer {
+			continue
+		}
+		// Bricks were added on the peer since the volumes to lock were
+		// chosen
+		if !utils.StringInSlice(v.Name, locked) {
+			return nil, nil, errors.ErrPeerConflict
+		}
+		oldVolinfos = append(oldVolinfos, v)
+		volinfos = append(volinfos, renamed)
+	}
+	return oldVolinfos, volinfos, nil
+}
+
+// volumesOnPeer returns the sorted names of the volumes with bricks on the
+// peer
+func volumesOnPeer(id uuid.UUID) ([]string, error) {
+	vols, err := volume.GetVolumes()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, v := range vols {
+		for _, b := range v.Bricks {
+			if uuid.Equal(b.NodeID, id) {
+				names = append(names, v.Name)
+				break
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// renamePrepare reads the peer and the volumes with bricks on it once they
+// are locked, and saves them in the context as they are and renamed
+func renamePrepare(c transaction.TxnCtx) error {
+	var id, hostname string
+	if err := c.Get("peerid", &id); err != nil {
+		return err
+	}
+	if err := c.Get("hostname", &hostname); err != nil {
+		return err
+	}
+	var locked []string
+	if err := c.Get("volnames", &locked); err != nil {
+		return err
+	}
+
+	p, err := peer.GetPeer(id)
+	if err != nil {
+		return err
+	}
+	vols, err := volume.GetVolumes()
+	if err != nil {
+		return err
+	}
+	oldVolinfos, volinfos, err := renameBricks(vols, p.ID, hostname, locked)
+	if err != nil {
+		return err
+	}
+
+	oldPeer := *p
+	p.Name = hostname
+	p.Addresses = renamedAddresses(p, hostname)
+
+	if err := c.Set("oldpeer", &oldPeer); err != nil {
+		return err
+	}
+	if err := c.Set("peer", p); err != nil {
+		return err
+	}
+	if err := c.Set("oldvolinfos", oldVolinfos); err != nil {
+		return err
+	}
+	return c.Set("volinfos", volinfos)
+}
+
+// renameStoreVolumes saves the peer and the volumes with the new hostname of
+// the peer, along with the client volfiles of the volumes
+func renameStoreVolumes(c transaction.TxnCtx) error {
+	var p peer.Peer
+	if err := c.Get("peer", &p); err != nil {
+		return err
+	}
+	var volinfos []volume.Volinfo
+	if err := c.Get("volinfos", &volinfos); err != nil {
+		return err
+	}
+
+	for i := range volinfos {
+		v := &volinfos[i]
+		if err := volgen.GenerateClientVolfile(v); err != nil {
+			c.Logger().WithError(err).WithField("volume", v.Name).Error("failed to generate client volfile")
+			return err
+		}
+		if err := volume.AddOrUpdateVolumeFunc(v); err != nil {
+			c.Logger().WithError(err).WithField("volume", v.Name).Error("failed to save volume")
+			return err
+		}
+	}
+	return renamePeer(&p)
+}
+
+// renamePeer saves the name and addresses of the given peer, keeping any
+// concurrent changes to the other fields of the peer
+func renamePeer(p *peer.Peer) error {
+	_, err := peer.UpdatePeer(p.ID.String(), func(cur *peer.Peer) error {
+		cur.Name = p.Name
+		cur.Addresses = p.Addresses
+		return nil
+	})
+	return err
+}
+
+// undoRenameStoreVolumes saves back the peer and the volumes as they were
+// before the rename
+func undoRenameStoreVolumes(c transaction.TxnCtx) error {
+	var p peer.Peer
+	if err := c.Get("oldpeer", &p); err != nil {
+		return err
+	}
+	var volinfos []volume.Volinfo
+	if err := c.Get("oldvolinfos", &volinfos); err != nil {
+		return err
+	}
+
+	for i := range volinfos {
+		v := &volinfos[i]
+		if err := volgen.GenerateClientVolfile(v); err != nil {
+			return err
+		}
+		if err := volume.AddOrUpdateVolumeFunc(v); err != nil {
+			return err
+		}
+	}
+	return renamePeer(&p)
+}
+
+// moveBrickRuntimeFiles moves the pidfile and socket file of a running brick
+// to their paths for the new hostname of the brick, which they are named
+// after. The brick process keeps using the socket once it is moved.
+func moveBrickRuntimeFiles(old, renamed brick.Brickinfo) error {
+	oldd, err := brick.NewGlusterfsd(old)
+	if err != nil {
+		return err
+	}
+	newd, err := brick.NewGlusterfsd(renamed)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range [][2]string{
+		{oldd.PidFile(), newd.PidFile()},
+		{oldd.SocketFile(), newd.SocketFile()},
+	} {
+		if err := os.Rename(f[0], f[1]); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// renameGenerateVolfiles regenerates the volfiles of the local bricks of the
+// renamed volumes, and moves the runtime files of the bricks
+func renameGenerateVolfiles(c transaction.TxnCtx) error {
+	var volinfos, oldVolinfos []volume.Volinfo
+	if err := c.Get("volinfos", &volinfos); err != nil {
+		return err
+	}
+	if err := c.Get("oldvolinfos", &oldVolinfos); err != nil {
+		return err
+	}
+
+	for i := range volinfos {
+		v := &volinfos[i]
+		for j := range v.Bricks {
+			b := &v.Bricks[j]
+			if !uuid.Equal(b.NodeID, gdctx.MyUUID) {
+				continue
+			}
+			if err := moveBrickRuntimeFiles(oldVolinfos[i].Bricks[j], *b); err != nil {
+				c.Logger().WithError(err).WithField("brick", b.Path).Error("failed to move runtime files of brick")
+				return err
+			}
+			if err := volgen.GenerateBrickVolfile(v, b); err != nil {
+				c.Logger().WithError(err).WithField("brick", b.Path).Error("failed to generate brick volfile")
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// undoRenameGenerateVolfiles moves the runtime files of the local bricks back
+// to their paths for the old hostname
+func undoRenameGenerateVolfiles(c transaction.TxnCtx) error {
+	var volinfos, oldVolinfos []volume.Volinfo
+	if err := c.Get("volinfos", &volinfos); err != nil {
+		return err
+	}
+	if err := c.Get("oldvolinfos", &oldVolinfos); err != nil {
+		return err
+	}
+
+	for i := range volinfos {
+		for j, b := range volinfos[i].Bricks {
+			if !uuid.Equal(b.NodeID, gdctx.MyUUID) {
+				continue
+			}
+			if err := moveBrickRuntimeFiles(b, oldVolinfos[i].Bricks[j]); err != nil {
+				c.Logger().WithError(err).WithField("brick", b.Path).Error("failed to move back runtime files of brick")
+			}
+		}
+	}
+	return nil
+}
+
+// renameNotify notifies the clients connected to this peer to fetch the new
+// volfiles, if any of the renamed volumes is started
+func renameNotify(c transaction.TxnCtx) error {
+	var volinfos []volume.Volinfo
+	if err := c.Get("volinfos", &volinfos); err != nil {
+		return err
+	}
+
+	for _, v := range volinfos {
+		if v.Status == volume.VolStarted {
+			sunrpc.FetchSpecNotify(c)
+			break
+		}
+	}
+	return nil
+}
+
+func registerPeerRenameStepFuncs() {
+	var sfs = []struct {
+		name string
+		sf   transaction.StepFunc
+	}{
+		{"peer-rename.Prepare", renamePrepare},
+		{"peer-rename.Store", renameStoreVolumes},
+		{"peer-rename.UndoStore", undoRenameStoreVolumes},
+		{"peer-rename.GenerateVolfiles", renameGenerateVolfiles},
+		{"peer-rename.UndoGenerateVolfiles", undoRenameGenerateVolfiles},
+		{"peer-rename.Notify", renameNotify},
+	}
+	for _, sf := range sfs {
+		transaction.RegisterStepFunc(sf.sf, sf.name)
+	}
+}
+
+// peerRenameHandler changes the hostname of a peer. The bricks of the peer
+// are referred to by the new hostname, and the volfiles of their volumes are
+// regenerated across the cluster in a single transaction. Clients are
+// notified to fetch the new volfiles.
+func peerRenameHandler(w http.ResponseWriter, r *http.Request) {
+	reqID, logger := restutils.GetReqIDandLogger(r)
+	id := mux.Vars(r)["peerid"]
+	logger = logger.WithField("peerid", id)
+
+	var req PeerRenameReq
+	if err := utils.GetJSONFromRequest(r, &req); err != nil {
+		restutils.SendHTTPError(w, http.StatusUnprocessableEntity, errors.ErrJSONParsingFailed.Error())
+		return
+	}
+
+	var errs validation.Errors
+	errs.PeerName("hostname", req.Hostname)
+	if errs != nil {
+		restutils.SendValidationErrors(w, errs)
+		return
+	}
+
+	p, err := peer.GetPeerF(id)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusNotFound, errors.ErrPeerNotFound.Error())
+		return
+	}
+	if p.Name == req.Hostname {
+		restutils.SendHTTPResponse(w, http.StatusOK, p)
+		return
+	}
+
+	peers, err := peer.GetPeers()
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for _, other := range peers {
+		if uuid.Equal(other.ID, p.ID) {
+			continue
+		}
+		conflict := validation.SameName(other.Name, req.Hostname)
+		for _, a := range other.Addresses {
+			conflict = conflict || utils.IsPeerAddressSame(a, req.Hostname)
+		}
+		if conflict {
+			restutils.SendHTTPError(w, http.StatusConflict, errors.ErrPeerNameConflict.Error())
+			return
+		}
+	}
+
+	// The volumes to lock are chosen before locking them, and are read
+	// again once locked. Volumes are locked before the peer, in the order
+	// of their names.
+	volnames, err := volumesOnPeer(p.ID)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var locks, unlocks []*transaction.Step
+	for _, key := range append(volnames, p.ID.String()) {
+		lock, unlock, err := transaction.CreateLockSteps(key)
+		if err != nil {
+			restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		locks = append(locks, lock)
+		unlocks = append([]*transaction.Step{unlock}, unlocks...)
+	}
+
+	// Every peer regenerates the volfiles of its own bricks of the renamed
+	// volumes, and notifies its clients
+	allNodes, err := peer.GetPeerIDs()
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	txn := transaction.NewTxn(reqID)
+	defer txn.Cleanup()
+	txn.Nodes = allNodes
+	txn.Steps = append(txn.Steps, locks...)
+	txn.Steps = append(txn.Steps,
+		&transaction.Step{
+			DoFunc: "peer-rename.Prepare",
+			Nodes:  []uuid.UUID{gdctx.MyUUID},
+		},
+		&transaction.Step{
+			DoFunc:   "peer-rename.Store",
+			UndoFunc: "peer-rename.UndoStore",
+			Nodes:    []uuid.UUID{gdctx.MyUUID},
+		},
+		&transaction.Step{
+			DoFunc:   "peer-rename.GenerateVolfiles",
+			UndoFunc: "peer-rename.UndoGenerateVolfiles",
+			Nodes:    allNodes,
+		},
+		&transaction.Step{
+			DoFunc: "peer-rename.Notify",
+			Nodes:  allNodes,
+		},
+	)
+	txn.Steps = append(txn.Steps, unlocks...)
+
+	txn.Ctx.Set("peerid", p.ID.String())
+	txn.Ctx.Set("hostname", req.Hostname)
+	txn.Ctx.Set("volnames", volnames)
+
+	if _, err := txn.Do(); err != nil {
+		logger.WithError(err).Error("failed to rename peer")
+		if err == transaction.ErrLockTimeout || err == errors.ErrPeerConflict {
+			restutils.SendHTTPError(w, http.StatusConflict, err.Error())
+		} else if transaction.IsTimeout(err) {
+			restutils.SendHTTPError(w, http.StatusGatewayTimeout, err.Error())
+		} else {
+			restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	var oldPeer, renamed peer.Peer
+	if err := txn.Ctx.Get("oldpeer", &oldPeer); err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if err := txn.Ctx.Get("peer", &renamed); err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var volinfos []volume.Volinfo
+	if err := txn.Ctx.Get("volinfos", &volinfos); err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	oldName := oldPeer.Name
+	p = &renamed
+
+	logger.WithFields(log.Fields{
+		"old-hostname": oldName,
+		"hostname":     p.Name,
+		"volumes":      len(volinfos),
+	}).Info("peer renamed")
+	events.Broadcast(events.New(EventPeerRenamed, map[string]string{
+		"peer.id":           p.ID.String(),
+		"peer.old.hostname": oldName,
+		"peer.hostname":     p.Name,
+		"volfile.server":    p.Addresses[0],
+	}))
+
+	restutils.SendHTTPResponse(w, http.StatusOK, p)
+}
@@ -0,0 +1,74 @@
+package peercommands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gluster/glusterd2/brick"
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/peer"
+	"github.com/gluster/glusterd2/tests"
+	"github.com/gluster/glusterd2/volume"
+
+	"github.com/pborman/uuid"
+	config "github.com/spf13/viper"
+)
+
+func TestRenamedAddresses(t *testing.T) {
+	config.Set("defaultpeerport", "24008")
+
+	for _, tc := range []struct {
+		addrs    []string
+		expected []string
+	}{
+		{nil, []string{"new"}},
+		// The new hostname takes the port of the old primary address
+		{[]string{"old:24008", "10.0.0.1:24008"}, []string{"new:24008", "10.0.0.1:24008"}},
+		{[]string{"old:24010"}, []string{"new:24010"}},
+		{[]string{"old", "10.0.0.1"}, []string{"new", "10.0.0.1"}},
+		// Other addresses of the new hostname are not repeated
+		{[]string{"old:24008", "new:24008"}, []string{"new:24008"}},
+		{[]string{"old:24008", "new"}, []string{"new:24008"}},
+	} {
+		p := &peer.Peer{Name: "old", Addresses: tc.addrs}
+		addrs := renamedAddresses(p, "new")
+		tests.Assert(t, strings.Join(addrs, ",") == strings.Join(tc.expected, ","))
+	}
+}
+
+func TestRenameBricks(t *testing.T) {
+	id, other := uuid.NewRandom(), uuid.NewRandom()
+	vols := []volume.Volinfo{
+		{Name: "vol1", Bricks: []brick.Brickinfo{
+			{NodeID: id, Hostname: "old", Path: "/b1"},
+			{NodeID: other, Hostname: "other", Path: "/b1"},
+			{NodeID: id, Hostname: "old", Path: "/b2"},
+		}},
+		{Name: "vol2", Bricks: []brick.Brickinfo{
+			{NodeID: other, Hostname: "other", Path: "/b2"},
+		}},
+		{Name: "vol3", Bricks: []brick.Brickinfo{
+			{NodeID: id, Hostname: "old", Path: "/b3"},
+		}},
+	}
+
+	oldVolinfos, volinfos, err := renameBricks(vols, id, "new", []string{"vol1", "vol3"})
+	tests.Assert(t, err == nil)
+
+	// Only the volumes with bricks on the peer are renamed, and only the
+	// bricks on the peer are changed
+	tests.Assert(t, len(volinfos) == 2 && len(oldVolinfos) == 2)
+	tests.Assert(t, volinfos[0].Name == "vol1" && volinfos[1].Name == "vol3")
+	b := volinfos[0].Bricks
+	tests.Assert(t, b[0].Hostname == "new" && b[1].Hostname == "other" && b[2].Hostname == "new")
+	tests.Assert(t, volinfos[1].Bricks[0].Hostname == "new")
+
+	// The volumes are kept as they were, for the rename to be undone
+	tests.Assert(t, oldVolinfos[0].Bricks[0].Hostname == "old" && oldVolinfos[1].Bricks[0].Hostname == "old")
+	tests.Assert(t, vols[0].Bricks[0].Hostname == "old")
+
+	// Volumes given bricks on the peer after the locks were chosen are
+	// not renamed without their lock
+	_, _, err = renameBricks(vols, id, "new", []string{"vol1"})
+	tests.Assert(t, err == errors.ErrPeerConflict)
+}
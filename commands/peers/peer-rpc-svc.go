@@ -32,16 +32,35 @@ func (p *PeerService) Join(ctx context.Context, req *JoinReq) (*JoinRsp, error)
 
 	// Handling a Join request happens as follows,
 	// 	- TODO: Ensure no ongoing operations (transactions/other peer requests) are happening
-	// 	- TODO: Check is peer is part of another cluster
+	// 	- Check if the peer is part of another cluster
 	// 	- Check if the peer has volumes
 	//	- Reconfigure the store with received configuration
 	// 	- Return your ID
 
 	// TODO: Ensure no other operations are happening
 
-	// TODO: Check if we are part of another cluster
+	if req.Config.GetClusterID() == store.Store.ClusterID() {
+		logger.Info("rejecting join, we are already a part of the cluster")
+		return &JoinRsp{"", int32(ErrAlreadyMember)}, nil
+	}
+
+	// A peer which has other peers is a part of another cluster, and must
+	// leave it first
+	peers, err := peer.GetPeers()
+	if err != nil {
+		logger.WithError(err).Error("rejecting join, failed to get peers")
+		return &JoinRsp{"", int32(ErrStoreReadFailed)}, nil
+	}
+	if len(peers) > 1 {
+		logger.WithField("cluster-id", store.Store.ClusterID()).Info("rejecting join, we are part of another cluster")
+		return &JoinRsp{"", int32(ErrAnotherCluster)}, nil
+	}
 
-	volumes, _ := volume.GetVolumes()
+	volumes, err := volume.GetVolumes()
+	if err != nil {
+		logger.WithError(err).Error("rejecting join, failed to get volumes")
+		return &JoinRsp{"", int32(ErrStoreReadFailed)}, nil
+	}
 	if len(volumes) != 0 {
 		logger.Info("rejecting join, we already have volumes")
 		return &JoinRsp{"", int32(ErrAnotherCluster)}, nil
@@ -90,7 +109,7 @@ func (p *PeerService) Leave(ctx context.Context, req *LeaveReq) (*LeaveRsp, erro
 	logger.Debug("all checks passed, leaving cluster")
 
 	logger.Debug("reconfiguring store with defaults")
-	if err := ReconfigureStore(&StoreConfig{store.NewConfig().Endpoints}); err != nil {
+	if err := ReconfigureStore(&StoreConfig{store.NewConfig().Endpoints, ""}); err != nil {
 		logger.WithError(err).Warn("failed to reconfigure store with defaults")
 		// XXX: We should probably keep retrying here?
 	}
@@ -98,7 +117,9 @@ func (p *PeerService) Leave(ctx context.Context, req *LeaveReq) (*LeaveRsp, erro
 }
 
 // ReconfigureStore reconfigures the store with the given store config, if no
-// store config is given uses the default
+// store config is given uses the default. The cluster ID of the store is
+// replaced with the given one, and a new cluster ID is generated if it is
+// empty.
 func ReconfigureStore(c *StoreConfig) error {
 
 	// Destroy the current store first
@@ -109,6 +130,7 @@ func ReconfigureStore(c *StoreConfig) error {
 	// Restart the store with received configuration
 	cfg := store.GetConfig()
 	cfg.Endpoints = c.Endpoints
+	cfg.ClusterID = c.ClusterID
 
 	if err := store.Init(cfg); err != nil {
 		log.WithError(err).WithField("endpoints", cfg.Endpoints).Error("failed to restart store with new endpoints")
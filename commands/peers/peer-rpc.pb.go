@@ -38,6 +38,7 @@ const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
 type StoreConfig struct {
 	Endpoints []string `protobuf:"bytes,1,rep,name=Endpoints" json:"Endpoints,omitempty"`
+	ClusterID string   `protobuf:"bytes,2,opt,name=ClusterID" json:"ClusterID,omitempty"`
 }
 
 func (m *StoreConfig) Reset()                    { *m = StoreConfig{} }
@@ -52,6 +53,13 @@ func (m *StoreConfig) GetEndpoints() []string {
 	return nil
 }
 
+func (m *StoreConfig) GetClusterID() string {
+	if m != nil {
+		return m.ClusterID
+	}
+	return ""
+}
+
 type JoinReq struct {
 	PeerID string       `protobuf:"bytes,1,opt,name=PeerID" json:"PeerID,omitempty"`
 	Config *StoreConfig `protobuf:"bytes,2,opt,name=Config" json:"Config,omitempty"`
@@ -248,21 +256,21 @@ var _PeerService_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("commands/peers/peer-rpc.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 247 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x92, 0x4d, 0xce, 0xcf, 0xcd,
+	// 251 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe3, 0x92, 0x4d, 0xce, 0xcf, 0xcd,
 	0x4d, 0xcc, 0x4b, 0x29, 0xd6, 0x2f, 0x48, 0x4d, 0x2d, 0x82, 0x90, 0xba, 0x45, 0x05, 0xc9, 0x7a,
-	0x05, 0x45, 0xf9, 0x25, 0xf9, 0x42, 0x3c, 0x20, 0x3e, 0x4c, 0x89, 0x92, 0x36, 0x17, 0x77, 0x70,
+	0x05, 0x45, 0xf9, 0x25, 0xf9, 0x42, 0x3c, 0x20, 0x3e, 0x4c, 0x89, 0x92, 0x27, 0x17, 0x77, 0x70,
 	0x49, 0x7e, 0x51, 0xaa, 0x73, 0x7e, 0x5e, 0x5a, 0x66, 0xba, 0x90, 0x0c, 0x17, 0xa7, 0x6b, 0x5e,
-	0x4a, 0x41, 0x7e, 0x66, 0x5e, 0x49, 0xb1, 0x04, 0xa3, 0x02, 0xb3, 0x06, 0x67, 0x10, 0x42, 0x40,
-	0x29, 0x84, 0x8b, 0xdd, 0x2b, 0x3f, 0x33, 0x2f, 0x28, 0xb5, 0x50, 0x48, 0x8c, 0x8b, 0x2d, 0x20,
-	0x35, 0xb5, 0xc8, 0xd3, 0x45, 0x82, 0x51, 0x81, 0x51, 0x83, 0x33, 0x08, 0xca, 0x13, 0x32, 0xe4,
-	0x62, 0x83, 0x18, 0x25, 0xc1, 0xa4, 0xc0, 0xa8, 0xc1, 0x6d, 0x24, 0xa9, 0x87, 0x6c, 0x9d, 0x1e,
-	0x92, 0x5d, 0x41, 0x50, 0x85, 0x4a, 0xc6, 0x50, 0x53, 0x8b, 0x0b, 0x70, 0x9a, 0x2a, 0xc0, 0xc5,
-	0xec, 0x5a, 0x54, 0x04, 0x36, 0x92, 0x35, 0x08, 0xc4, 0x54, 0x52, 0xe2, 0xe2, 0xf0, 0x49, 0x4d,
-	0x2c, 0x4b, 0xc5, 0xe3, 0x16, 0x25, 0x19, 0x98, 0x9a, 0xe2, 0x02, 0x98, 0x09, 0x8c, 0x70, 0x13,
-	0x8c, 0x1a, 0x18, 0xb9, 0xb8, 0x41, 0x0a, 0x83, 0x53, 0x8b, 0xca, 0x32, 0x93, 0x53, 0x85, 0xcc,
-	0xb8, 0x58, 0x40, 0xce, 0x10, 0x12, 0x45, 0x75, 0x31, 0xd4, 0xc3, 0x52, 0xd8, 0x84, 0x8b, 0x0b,
-	0x94, 0x18, 0x84, 0x2c, 0xb9, 0x58, 0xc1, 0xb6, 0x08, 0x89, 0xa1, 0xaa, 0x80, 0x39, 0x4f, 0x0a,
-	0xab, 0x38, 0x48, 0x6b, 0x12, 0x1b, 0x38, 0x46, 0x8c, 0x01, 0x01, 0x00, 0x00, 0xff, 0xff, 0x88,
-	0x1d, 0x73, 0x8f, 0xb2, 0x01, 0x00, 0x00,
+	0x4a, 0x41, 0x7e, 0x66, 0x5e, 0x49, 0xb1, 0x04, 0xa3, 0x02, 0xb3, 0x06, 0x67, 0x10, 0x42, 0x00,
+	0x24, 0xeb, 0x9c, 0x53, 0x5a, 0x5c, 0x92, 0x5a, 0xe4, 0xe9, 0x22, 0xc1, 0xa4, 0xc0, 0x08, 0x92,
+	0x85, 0x0b, 0x28, 0x85, 0x70, 0xb1, 0x7b, 0x01, 0xd5, 0x05, 0xa5, 0x16, 0x0a, 0x89, 0x71, 0xb1,
+	0x05, 0xa4, 0x82, 0x55, 0x31, 0x82, 0x55, 0x41, 0x79, 0x42, 0x86, 0x5c, 0x6c, 0x10, 0x8b, 0xc0,
+	0xba, 0xb9, 0x8d, 0x24, 0xf5, 0x90, 0x1d, 0xa3, 0x87, 0xe4, 0x92, 0x20, 0xa8, 0x42, 0x25, 0x63,
+	0xa8, 0xa9, 0xc5, 0x05, 0x38, 0x4d, 0x15, 0xe0, 0x62, 0x76, 0x2d, 0x2a, 0x02, 0x1b, 0xc9, 0x1a,
+	0x04, 0x62, 0x2a, 0x29, 0x71, 0x71, 0xf8, 0xa4, 0x26, 0x96, 0xa5, 0xe2, 0x71, 0x8b, 0x92, 0x0c,
+	0x4c, 0x0d, 0xd0, 0x64, 0xa8, 0x09, 0x8c, 0x70, 0x13, 0x8c, 0x1a, 0x18, 0xb9, 0xb8, 0x41, 0x0a,
+	0x83, 0x53, 0x8b, 0xca, 0x32, 0x93, 0x53, 0x85, 0xcc, 0xb8, 0x58, 0x40, 0xce, 0x10, 0x12, 0x45,
+	0x75, 0x31, 0xd4, 0xc3, 0x52, 0xd8, 0x84, 0x8b, 0x0b, 0x94, 0x18, 0x84, 0x2c, 0xb9, 0x58, 0xc1,
+	0xb6, 0x08, 0x89, 0xa1, 0xaa, 0x80, 0x39, 0x4f, 0x0a, 0xab, 0x38, 0x48, 0x6b, 0x12, 0x1b, 0x38,
+	0xbe, 0x8c, 0x01, 0x6b, 0xeb, 0x8e, 0xf2, 0xd0, 0x01, 0x00, 0x00,
 }
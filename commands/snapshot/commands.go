@@ -0,0 +1,108 @@
+// Package snapshotcommands implements the commands to manage snapshots of
+// volumes, and to clone volumes from them
+package snapshotcommands
+
+import (
+	"github.com/gluster/glusterd2/servers/rest/route"
+	"github.com/gluster/glusterd2/transaction"
+)
+
+// Command is a holding struct used to implement the GlusterD Command interface
+type Command struct {
+}
+
+// Routes returns command routes. Required for the Command interface.
+func (c *Command) Routes() route.Routes {
+	return route.Routes{
+		route.Route{
+			Name:        "SnapshotCreate",
+			Method:      "POST",
+			Pattern:     "/snapshots",
+			Version:     1,
+			HandlerFunc: snapshotCreateHandler},
+		route.Route{
+			Name:        "SnapshotList",
+			Method:      "GET",
+			Pattern:     "/snapshots",
+			Version:     1,
+			HandlerFunc: snapshotListHandler},
+		route.Route{
+			Name:        "SnapshotInfo",
+			Method:      "GET",
+			Pattern:     "/snapshots/{snapname}",
+			Version:     1,
+			HandlerFunc: snapshotInfoHandler},
+		route.Route{
+			Name:        "SnapshotDelete",
+			Method:      "DELETE",
+			Pattern:     "/snapshots/{snapname}",
+			Version:     1,
+			HandlerFunc: snapshotDeleteHandler},
+		route.Route{
+			Name:        "SnapshotClone",
+			Method:      "POST",
+			Pattern:     "/snapshots/{snapname}/clone",
+			Version:     1,
+			HandlerFunc: snapshotCloneHandler},
+		route.Route{
+			Name:        "ProvisionSnapshot",
+			Method:      "POST",
+			Pattern:     "/provisioner/volumes/{volname}/snapshots",
+			Version:     1,
+			HandlerFunc: snapshotProvisionHandler},
+		route.Route{
+			Name:        "ProvisionSnapshotDelete",
+			Method:      "DELETE",
+			Pattern:     "/provisioner/snapshots/{snapname}",
+			Version:     1,
+			HandlerFunc: snapshotDeprovisionHandler},
+		route.Route{
+			Name:        "SnapshotScheduleList",
+			Method:      "GET",
+			Pattern:     "/snapshot-schedules",
+			Version:     1,
+			HandlerFunc: snapshotScheduleListHandler},
+		route.Route{
+			Name:        "SnapshotScheduleGet",
+			Method:      "GET",
+			Pattern:     "/snapshot-schedules/{volname}",
+			Version:     1,
+			HandlerFunc: snapshotScheduleGetHandler},
+		route.Route{
+			Name:        "SnapshotScheduleSet",
+			Method:      "POST",
+			Pattern:     "/snapshot-schedules/{volname}",
+			Version:     1,
+			HandlerFunc: snapshotScheduleSetHandler},
+		route.Route{
+			Name:        "SnapshotScheduleDelete",
+			Method:      "DELETE",
+			Pattern:     "/snapshot-schedules/{volname}",
+			Version:     1,
+			HandlerFunc: snapshotScheduleDeleteHandler},
+	}
+}
+
+// RegisterStepFuncs registers transaction step functions with
+// the Transaction framework. Required for the Command interface.
+func (c *Command) RegisterStepFuncs() {
+	var sfs = []struct {
+		name string
+		sf   transaction.StepFunc
+	}{
+		{"snap-create.Stage", validateSnapshotCreate},
+		{"snap-create.Commit", createSnapshotLVs},
+		{"snap-create.Undo", undoSnapshotLVs},
+		{"snap-create.Store", storeSnapshot},
+		{"snap-delete.Commit", removeSnapshotLVs},
+		{"snap-delete.Store", deleteSnapshot},
+		{"snap-clone.Commit", createCloneBricks},
+		{"snap-clone.Undo", removeCloneBricks},
+		{"snap-clone.Store", storeClone},
+		{"snap-clone.Remove", removeCloneBricks},
+		{"snap-clone.Unstore", unstoreClone},
+	}
+	for _, sf := range sfs {
+		transaction.RegisterStepFunc(sf.sf, sf.name)
+	}
+}
@@ -0,0 +1,165 @@
+package snapshotcommands
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/gluster/glusterd2/events"
+	"github.com/gluster/glusterd2/snapshot"
+	"github.com/gluster/glusterd2/validation"
+	"github.com/gluster/glusterd2/volume"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/pborman/uuid"
+)
+
+// Names of the events emitted by the snapshot scheduler
+const (
+	EventScheduledSnapCreated = "snapshot.schedule.created"
+	EventScheduledSnapFailed  = "snapshot.schedule.failed"
+	EventScheduledSnapPruned  = "snapshot.schedule.pruned"
+)
+
+const (
+	// schedulerInterval is the interval at which the schedules are
+	// checked. Schedules have a resolution of a minute.
+	schedulerInterval = time.Minute
+
+	// scheduledSnapTimeFormat is the format of the time in the names of
+	// scheduled snapshots
+	scheduledSnapTimeFormat = "20060102-150405"
+)
+
+// RunScheduler takes the snapshots of volumes with a snapshot schedule, and
+// prunes their old scheduled snapshots, every minute until ctx is done. It is
+// run as a leader job, so that only one peer takes the scheduled snapshots.
+func RunScheduler(ctx context.Context) {
+	ticker := time.NewTicker(schedulerInterval)
+	defer ticker.Stop()
+
+	for {
+		runSchedules(time.Now().UTC())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func runSchedules(now time.Time) {
+	schedules, err := snapshot.GetSchedules()
+	if err != nil {
+		log.WithError(err).Error("failed to get snapshot schedules")
+		return
+	}
+
+	for _, sched := range schedules {
+		cron, err := snapshot.ParseCron(sched.Schedule)
+		if err != nil {
+			log.WithError(err).WithField("volume", sched.Volume).Error("invalid snapshot schedule")
+			continue
+		}
+		next := cron.Next(sched.LastRun)
+		if next.IsZero() || next.After(now) {
+			continue
+		}
+		runSchedule(sched, now)
+	}
+}
+
+// scheduledSnapName returns the name of the snapshot of the volume taken by
+// the scheduler at the given time
+func scheduledSnapName(volname string, t time.Time) string {
+	suffix := "_" + t.Format(scheduledSnapTimeFormat)
+	if max := validation.MaxNameLength - len(suffix); len(volname) > max {
+		volname = volname[:max]
+	}
+	return volname + suffix
+}
+
+// runSchedule takes a snapshot of the volume of the schedule, and removes the
+// oldest scheduled snapshots of the volume beyond the retention count
+func runSchedule(sched *snapshot.Schedule, now time.Time) {
+	logger := log.WithField("volume", sched.Volume)
+
+	// The schedule is marked as run even if the snapshot fails, so a
+	// failing snapshot is retried at the next scheduled time, and not
+	// every minute
+	sched.LastRun = now
+	if err := snapshot.SaveSchedule(sched); err != nil {
+		logger.WithError(err).Error("failed to update snapshot schedule")
+		return
+	}
+
+	vol, err := volume.GetVolume(sched.Volume)
+	if err != nil {
+		logger.WithError(err).Error("failed to get volume of snapshot schedule")
+		scheduleFailed(sched, "", err)
+		return
+	}
+
+	snap := &snapshot.Snapinfo{
+		ID:        uuid.NewRandom(),
+		Name:      scheduledSnapName(vol.Name, now),
+		CreatedAt: now,
+		Scheduled: true,
+		Volinfo:   *vol,
+	}
+	if err := createSnapshot(uuid.NewRandom().String(), snap); err != nil {
+		logger.WithError(err).WithField("snapshot", snap.Name).Error("scheduled snapshot failed")
+		scheduleFailed(sched, snap.Name, err)
+		return
+	}
+	logger.WithField("snapshot", snap.Name).Info("scheduled snapshot created")
+	events.Broadcast(events.New(EventScheduledSnapCreated, map[string]string{
+		"volume.name":   sched.Volume,
+		"snapshot.name": snap.Name,
+	}))
+
+	pruneScheduledSnapshots(sched)
+}
+
+func scheduleFailed(sched *snapshot.Schedule, snapname string, err error) {
+	events.Broadcast(events.New(EventScheduledSnapFailed, map[string]string{
+		"volume.name":   sched.Volume,
+		"snapshot.name": snapname,
+		"error":         err.Error(),
+	}))
+}
+
+// pruneScheduledSnapshots removes the oldest scheduled snapshots of the
+// volume of the schedule, keeping the newest sched.Retain ones. Snapshots
+// taken by users are never pruned.
+func pruneScheduledSnapshots(sched *snapshot.Schedule) {
+	logger := log.WithField("volume", sched.Volume)
+
+	snaps, err := snapshot.GetSnapshots(sched.Volume)
+	if err != nil {
+		logger.WithError(err).Error("failed to get snapshots to prune")
+		return
+	}
+
+	var scheduled []*snapshot.Snapinfo
+	for _, s := range snaps {
+		if s.Scheduled {
+			scheduled = append(scheduled, s)
+		}
+	}
+
+	// Snapshots are sorted oldest first
+	for i := 0; i < len(scheduled)-sched.Retain; i++ {
+		s := scheduled[i]
+		if err := deleteSnapshotTxn(uuid.NewRandom().String(), s); err != nil {
+			logger.WithError(err).WithField("snapshot", s.Name).Error("failed to prune scheduled snapshot")
+			continue
+		}
+		logger.WithField("snapshot", s.Name).Info("pruned scheduled snapshot")
+		events.Broadcast(events.New(EventScheduledSnapPruned, map[string]string{
+			"volume.name":   sched.Volume,
+			"snapshot.name": s.Name,
+			"retain":        strconv.Itoa(sched.Retain),
+		}))
+	}
+}
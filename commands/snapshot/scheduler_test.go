@@ -0,0 +1,20 @@
+package snapshotcommands
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gluster/glusterd2/tests"
+	"github.com/gluster/glusterd2/validation"
+)
+
+func TestScheduledSnapName(t *testing.T) {
+	at := time.Date(2017, 10, 20, 18, 30, 0, 0, time.UTC)
+
+	tests.Assert(t, scheduledSnapName("gv0", at) == "gv0_20171020-183000")
+
+	name := scheduledSnapName(strings.Repeat("v", validation.MaxNameLength), at)
+	tests.Assert(t, len(name) == validation.MaxNameLength)
+	tests.Assert(t, strings.HasSuffix(name, "_20171020-183000"))
+}
@@ -0,0 +1,275 @@
+package snapshotcommands
+
+import (
+	"net/http"
+	"os"
+	"path"
+
+	"github.com/gluster/glusterd2/brick"
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/gdctx"
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/snapshot"
+	"github.com/gluster/glusterd2/transaction"
+	"github.com/gluster/glusterd2/utils"
+	"github.com/gluster/glusterd2/utils/xattr"
+	"github.com/gluster/glusterd2/validation"
+	"github.com/gluster/glusterd2/volume"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gorilla/mux"
+	"github.com/pborman/uuid"
+)
+
+// volumeIDXattr is the xattr holding the ID of the volume on the root of its
+// bricks
+const volumeIDXattr = "trusted.glusterfs.volume-id"
+
+// SnapCloneReq represents a request to create a volume from a snapshot
+type SnapCloneReq struct {
+	Name string `json:"name"`
+}
+
+// createCloneBricks creates a writable thin snapshot of the snapshot of every
+// local brick, mounts it, and marks the brick on it as a brick of the new
+// volume
+func createCloneBricks(c transaction.TxnCtx) error {
+	var snapname string
+	if err := c.Get("snapname", &snapname); err != nil {
+		return err
+	}
+	var vol volume.Volinfo
+	if err := c.Get("volinfo", &vol); err != nil {
+		return err
+	}
+	var clone snapshot.Clone
+	if err := c.Get("clone", &clone); err != nil {
+		return err
+	}
+
+	snap, err := snapshot.GetSnapshot(snapname)
+	if err != nil {
+		return err
+	}
+
+	for i, b := range vol.Bricks {
+		if !uuid.Equal(b.NodeID, gdctx.MyUUID) {
+			continue
+		}
+		sb := snap.Bricks[i]
+		cb := clone.Bricks[i]
+
+		c.Logger().WithFields(log.Fields{
+			"volume":   vol.Name,
+			"snapshot": snapname,
+			"brick":    b.Path,
+			"lv":       cb.VG + "/" + cb.LV,
+		}).Info("cloning brick from snapshot")
+
+		if err := snapshot.CreateCloneLV(sb.VG, sb.LV, cb.LV); err != nil {
+			return err
+		}
+		if err := snapshot.Mount(&snapshot.LV{VG: cb.VG, Name: cb.LV}, cb.MountPoint, cb.FSType); err != nil {
+			return err
+		}
+		if err := xattr.SetUUID(b.Path, volumeIDXattr, vol.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeCloneBricks unmounts and removes the logical volumes of the local
+// bricks of a cloned volume
+func removeCloneBricks(c transaction.TxnCtx) error {
+	var clone snapshot.Clone
+	if err := c.Get("clone", &clone); err != nil {
+		return err
+	}
+
+	for _, cb := range clone.Bricks {
+		if !uuid.Equal(cb.NodeID, gdctx.MyUUID) {
+			continue
+		}
+
+		c.Logger().WithFields(log.Fields{
+			"volume": clone.Volume,
+			"lv":     cb.VG + "/" + cb.LV,
+		}).Info("removing brick of cloned volume")
+
+		if err := snapshot.Unmount(cb.MountPoint); err != nil {
+			return err
+		}
+		if err := snapshot.RemoveLV(cb.VG, cb.LV); err != nil {
+			return err
+		}
+		if err := os.Remove(cb.MountPoint); err != nil && !os.IsNotExist(err) {
+			c.Logger().WithError(err).WithField("mount-point", cb.MountPoint).Warn("failed to remove mount point of cloned brick")
+		}
+	}
+	return nil
+}
+
+func storeClone(c transaction.TxnCtx) error {
+	var clone snapshot.Clone
+	if err := c.Get("clone", &clone); err != nil {
+		return err
+	}
+	return snapshot.SaveClone(&clone)
+}
+
+func unstoreClone(c transaction.TxnCtx) error {
+	var clone snapshot.Clone
+	if err := c.Get("clone", &clone); err != nil {
+		return err
+	}
+	return snapshot.DeleteClone(clone.Volume)
+}
+
+// newClone returns the volume cloned from the snapshot, with the given name,
+// and the record of the logical volumes of its bricks
+func newClone(snap *snapshot.Snapinfo, name string) (*volume.Volinfo, *snapshot.Clone) {
+	vol := snap.Volinfo
+	vol.ID = uuid.NewRandom()
+	vol.Name = name
+	vol.Status = volume.VolStopped
+	vol.Checksum = 0
+	vol.Version = 0
+	vol.Capacity = 0
+	vol.Auth = volume.VolAuth{
+		Username: uuid.NewRandom().String(),
+		Password: uuid.NewRandom().String(),
+	}
+	vol.Options = make(map[string]string, len(snap.Volinfo.Options))
+	for k, v := range snap.Volinfo.Options {
+		vol.Options[k] = v
+	}
+
+	clone := &snapshot.Clone{
+		Volume:   name,
+		Snapshot: snap.Name,
+	}
+	vol.Bricks = make([]brick.Brickinfo, len(snap.Volinfo.Bricks))
+	for i, b := range snap.Volinfo.Bricks {
+		sb := snap.Bricks[i]
+		cb := snapshot.CloneBrick{
+			NodeID:     b.NodeID,
+			VG:         sb.VG,
+			LV:         snapshot.CloneLVName(vol.ID, i),
+			FSType:     sb.FSType,
+			MountPoint: snapshot.CloneMountPoint(name, i),
+		}
+		clone.Bricks = append(clone.Bricks, cb)
+
+		b.Path = path.Join(cb.MountPoint, sb.Dir)
+		b.VolumeName = vol.Name
+		b.VolumeID = vol.ID
+		vol.Bricks[i] = b
+	}
+	return &vol, clone
+}
+
+// snapshotCloneHandler creates a new volume from a snapshot. The bricks of the
+// volume are writable snapshots of the snapshot of the bricks, so the volume
+// is independent of the snapshot and of the volume it was taken of. The new
+// volume is left stopped.
+func snapshotCloneHandler(w http.ResponseWriter, r *http.Request) {
+	snapname := mux.Vars(r)["snapname"]
+	reqID, logger := restutils.GetReqIDandLogger(r)
+
+	var req SnapCloneReq
+	if err := utils.GetJSONFromRequest(r, &req); err != nil {
+		restutils.SendHTTPError(w, http.StatusUnprocessableEntity, errors.ErrJSONParsingFailed.Error())
+		return
+	}
+
+	var errs validation.Errors
+	errs.Name("name", req.Name)
+	if errs != nil {
+		restutils.SendValidationErrors(w, errs)
+		return
+	}
+
+	snap, err := snapshot.GetSnapshot(snapname)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusNotFound, errors.ErrSnapNotFound.Error())
+		return
+	}
+	if volume.ExistsFunc(req.Name) {
+		restutils.SendHTTPError(w, http.StatusConflict, errors.ErrVolExists.Error())
+		return
+	}
+
+	vol, clone := newClone(snap, req.Name)
+	if err := cloneSnapshot(reqID, snap, vol, clone); err != nil {
+		logger.WithError(err).WithField("snapshot", snapname).Error("snapshot clone transaction failed")
+		sendSnapTxnError(w, err)
+		return
+	}
+
+	logger.WithFields(log.Fields{
+		"snapshot": snapname,
+		"volume":   req.Name,
+	}).Info("volume cloned from snapshot")
+
+	created, err := volume.GetVolume(req.Name)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	restutils.SendHTTPResponse(w, http.StatusCreated, created)
+}
+
+// cloneSnapshot creates the bricks of the cloned volume on all the nodes, and
+// saves the volume
+func cloneSnapshot(reqID string, snap *snapshot.Snapinfo, vol *volume.Volinfo, clone *snapshot.Clone) error {
+	lock, unlock, err := transaction.CreateLockSteps(vol.Name)
+	if err != nil {
+		return err
+	}
+	// The snapshot must not be removed while it is cloned
+	snapLock, snapUnlock, err := transaction.CreateLockSteps(snap.Volinfo.Name)
+	if err != nil {
+		return err
+	}
+
+	txn := transaction.NewTxn(reqID)
+	defer txn.Cleanup()
+	txn.Nodes = vol.Nodes()
+	txn.Steps = []*transaction.Step{
+		lock,
+		snapLock,
+		{
+			DoFunc:   "snap-clone.Commit",
+			UndoFunc: "snap-clone.Undo",
+			Nodes:    txn.Nodes,
+		},
+		{
+			DoFunc: "vol-create.Commit",
+			Nodes:  txn.Nodes,
+		},
+		{
+			DoFunc:   "snap-clone.Store",
+			UndoFunc: "snap-clone.Unstore",
+			Nodes:    []uuid.UUID{gdctx.MyUUID},
+		},
+		{
+			DoFunc: "vol-create.Store",
+			Nodes:  []uuid.UUID{gdctx.MyUUID},
+		},
+		snapUnlock,
+		unlock,
+	}
+	if err := txn.Ctx.Set("snapname", snap.Name); err != nil {
+		return err
+	}
+	if err := txn.Ctx.Set("volinfo", vol); err != nil {
+		return err
+	}
+	if err := txn.Ctx.Set("clone", clone); err != nil {
+		return err
+	}
+
+	_, err = txn.Do()
+	return err
+}
@@ -0,0 +1,281 @@
+package snapshotcommands
+
+import (
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/gdctx"
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/snapshot"
+	"github.com/gluster/glusterd2/transaction"
+	"github.com/gluster/glusterd2/utils"
+	"github.com/gluster/glusterd2/validation"
+	"github.com/gluster/glusterd2/volume"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/pborman/uuid"
+)
+
+const snapBricksTxnKey = "snapbricks"
+
+// SnapCreateReq represents a request to take a snapshot of a volume
+type SnapCreateReq struct {
+	Volume      string `json:"volume"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+func validateSnapshotCreate(c transaction.TxnCtx) error {
+	var snap snapshot.Snapinfo
+	if err := c.Get("snapinfo", &snap); err != nil {
+		return err
+	}
+
+	for _, b := range snap.Volinfo.Bricks {
+		if !uuid.Equal(b.NodeID, gdctx.MyUUID) {
+			continue
+		}
+		if _, _, err := snapshot.BrickLV(b.Path); err != nil {
+			c.Logger().WithError(err).WithField("brick", b.Path).Error("cannot take a snapshot of brick")
+			return err
+		}
+	}
+	return nil
+}
+
+// createSnapshotLVs takes a thin snapshot of the logical volume of every local
+// brick of the volume, and returns the snapshot bricks by brick path
+func createSnapshotLVs(c transaction.TxnCtx) error {
+	var snap snapshot.Snapinfo
+	if err := c.Get("snapinfo", &snap); err != nil {
+		return err
+	}
+
+	bricks := make(map[string]snapshot.SnapBrick)
+	for i, b := range snap.Volinfo.Bricks {
+		if !uuid.Equal(b.NodeID, gdctx.MyUUID) {
+			continue
+		}
+
+		lv, m, err := snapshot.BrickLV(b.Path)
+		if err != nil {
+			return err
+		}
+		brickPath, err := filepath.EvalSymlinks(b.Path)
+		if err != nil {
+			return err
+		}
+		dir, err := filepath.Rel(m.MountPoint, brickPath)
+		if err != nil {
+			return err
+		}
+
+		name := snapshot.SnapLVName(snap.ID, i)
+		c.Logger().WithFields(log.Fields{
+			"snapshot": snap.Name,
+			"brick":    b.Path,
+			"lv":       lv.VG + "/" + name,
+		}).Info("taking snapshot of brick")
+
+		if err := snapshot.CreateSnapshotLV(lv, name); err != nil {
+			return err
+		}
+		bricks[b.Path] = snapshot.SnapBrick{
+			NodeID: gdctx.MyUUID,
+			Path:   b.Path,
+			VG:     lv.VG,
+			LV:     name,
+			FSType: m.FSType,
+			Dir:    dir,
+		}
+	}
+
+	return c.SetNodeResult(gdctx.MyUUID, snapBricksTxnKey, bricks)
+}
+
+// undoSnapshotLVs removes the snapshots of the local bricks taken by a failed
+// snapshot create
+func undoSnapshotLVs(c transaction.TxnCtx) error {
+	var snap snapshot.Snapinfo
+	if err := c.Get("snapinfo", &snap); err != nil {
+		return err
+	}
+
+	for i, b := range snap.Volinfo.Bricks {
+		if !uuid.Equal(b.NodeID, gdctx.MyUUID) {
+			continue
+		}
+		lv, _, err := snapshot.BrickLV(b.Path)
+		if err != nil {
+			return err
+		}
+		if err := snapshot.RemoveLV(lv.VG, snapshot.SnapLVName(snap.ID, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// storeSnapshot saves the snapshot with the bricks taken on every node, in the
+// order of the bricks of the volume
+func storeSnapshot(c transaction.TxnCtx) error {
+	var snap snapshot.Snapinfo
+	if err := c.Get("snapinfo", &snap); err != nil {
+		return err
+	}
+
+	results := make(map[string]map[string]snapshot.SnapBrick)
+	for _, b := range snap.Volinfo.Bricks {
+		node := b.NodeID.String()
+		if _, ok := results[node]; !ok {
+			var bricks map[string]snapshot.SnapBrick
+			if err := c.GetNodeResult(b.NodeID, snapBricksTxnKey, &bricks); err != nil {
+				return err
+			}
+			results[node] = bricks
+		}
+		snap.Bricks = append(snap.Bricks, results[node][b.Path])
+	}
+
+	return snapshot.AddOrUpdateSnapshot(&snap)
+}
+
+// snapshotCreateHandler takes a snapshot of all the bricks of a volume. The
+// bricks of a started volume are barriered while the snapshots are taken, so
+// that the snapshot is crash consistent.
+func snapshotCreateHandler(w http.ResponseWriter, r *http.Request) {
+	reqID, logger := restutils.GetReqIDandLogger(r)
+
+	var req SnapCreateReq
+	if err := utils.GetJSONFromRequest(r, &req); err != nil {
+		restutils.SendHTTPError(w, http.StatusUnprocessableEntity, errors.ErrJSONParsingFailed.Error())
+		return
+	}
+
+	var errs validation.Errors
+	errs.RequireString("volume", req.Volume)
+	errs.Name("name", req.Name)
+	if errs != nil {
+		restutils.SendValidationErrors(w, errs)
+		return
+	}
+
+	vol, err := volume.GetVolume(req.Volume)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusNotFound, errors.ErrVolNotFound.Error())
+		return
+	}
+	if snapshot.Exists(req.Name) {
+		restutils.SendHTTPError(w, http.StatusConflict, errors.ErrSnapExists.Error())
+		return
+	}
+
+	snap := &snapshot.Snapinfo{
+		ID:          uuid.NewRandom(),
+		Name:        req.Name,
+		Description: req.Description,
+		CreatedAt:   time.Now().UTC(),
+		Volinfo:     *vol,
+	}
+
+	if err := createSnapshot(reqID, snap); err != nil {
+		logger.WithError(err).WithField("snapshot", req.Name).Error("snapshot create transaction failed")
+		sendSnapTxnError(w, err)
+		return
+	}
+
+	logger.WithFields(log.Fields{
+		"volume":   req.Volume,
+		"snapshot": req.Name,
+	}).Info("snapshot created")
+
+	created, err := snapshot.GetSnapshot(req.Name)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	restutils.SendHTTPResponse(w, http.StatusCreated, created)
+}
+
+// createSnapshot takes the snapshot of the bricks of the volume of snap, and
+// saves it
+func createSnapshot(reqID string, snap *snapshot.Snapinfo) error {
+	vol := &snap.Volinfo
+	lock, unlock, err := transaction.CreateLockSteps(vol.Name)
+	if err != nil {
+		return err
+	}
+
+	txn := transaction.NewTxn(reqID)
+	defer txn.Cleanup()
+	txn.Nodes = vol.Nodes()
+
+	commit := &transaction.Step{
+		DoFunc:   "snap-create.Commit",
+		UndoFunc: "snap-create.Undo",
+		Nodes:    txn.Nodes,
+	}
+	txn.Steps = []*transaction.Step{
+		lock,
+		{
+			DoFunc: "snap-create.Stage",
+			Nodes:  txn.Nodes,
+		},
+	}
+	if vol.Status == volume.VolStarted {
+		// The barrier is released by running the undo of the barrier
+		// step once the snapshots are taken
+		txn.Steps = append(txn.Steps,
+			&transaction.Step{
+				DoFunc:   "vol-barrier.Commit",
+				UndoFunc: "vol-barrier.Undo",
+				Nodes:    txn.Nodes,
+			},
+			commit,
+			&transaction.Step{
+				DoFunc: "vol-barrier.Undo",
+				Nodes:  txn.Nodes,
+			})
+	} else {
+		txn.Steps = append(txn.Steps, commit)
+	}
+	txn.Steps = append(txn.Steps,
+		&transaction.Step{
+			DoFunc: "snap-create.Store",
+			Nodes:  []uuid.UUID{gdctx.MyUUID},
+		},
+		unlock)
+
+	if err := txn.Ctx.Set("snapinfo", snap); err != nil {
+		return err
+	}
+	if err := txn.Ctx.Set("volname", vol.Name); err != nil {
+		return err
+	}
+	if err := txn.Ctx.Set("enable", true); err != nil {
+		return err
+	}
+
+	_, err = txn.Do()
+	return err
+}
+
+// snapTxnErrStatus returns the HTTP status sent for an error of a snapshot
+// transaction
+func snapTxnErrStatus(err error) int {
+	switch {
+	case err == errors.ErrBrickNotThinLV:
+		return http.StatusBadRequest
+	case err == transaction.ErrLockTimeout:
+		return http.StatusConflict
+	case transaction.IsTimeout(err):
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
+}
+
+func sendSnapTxnError(w http.ResponseWriter, err error) {
+	restutils.SendHTTPError(w, snapTxnErrStatus(err), err.Error())
+}
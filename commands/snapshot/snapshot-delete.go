@@ -0,0 +1,128 @@
+package snapshotcommands
+
+import (
+	"net/http"
+
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/gdctx"
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/snapshot"
+	"github.com/gluster/glusterd2/transaction"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gorilla/mux"
+	"github.com/pborman/uuid"
+)
+
+// removeSnapshotLVs removes the logical volumes of the local bricks of a
+// snapshot
+func removeSnapshotLVs(c transaction.TxnCtx) error {
+	var snapname string
+	if err := c.Get("snapname", &snapname); err != nil {
+		return err
+	}
+
+	snap, err := snapshot.GetSnapshot(snapname)
+	if err != nil {
+		return err
+	}
+
+	for _, b := range snap.Bricks {
+		if !uuid.Equal(b.NodeID, gdctx.MyUUID) {
+			continue
+		}
+
+		c.Logger().WithFields(log.Fields{
+			"snapshot": snapname,
+			"lv":       b.VG + "/" + b.LV,
+		}).Info("removing snapshot of brick")
+
+		if err := snapshot.RemoveLV(b.VG, b.LV); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func deleteSnapshot(c transaction.TxnCtx) error {
+	var snapname string
+	if err := c.Get("snapname", &snapname); err != nil {
+		return err
+	}
+	return snapshot.DeleteSnapshot(snapname)
+}
+
+// snapshotDeleteHandler removes the snapshots of all the bricks of the
+// snapshot. Volumes cloned from the snapshot are independent of it, and are
+// not affected.
+func snapshotDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	snapname := mux.Vars(r)["snapname"]
+	reqID, logger := restutils.GetReqIDandLogger(r)
+
+	snap, err := snapshot.GetSnapshot(snapname)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusNotFound, errors.ErrSnapNotFound.Error())
+		return
+	}
+
+	if err := deleteSnapshotTxn(reqID, snap); err != nil {
+		logger.WithError(err).WithField("snapshot", snapname).Error("snapshot delete transaction failed")
+		sendSnapTxnError(w, err)
+		return
+	}
+
+	logger.WithField("snapshot", snapname).Info("snapshot deleted")
+	restutils.SendHTTPResponse(w, http.StatusOK, nil)
+}
+
+// deleteSnapshotTxn removes the snapshot on all the nodes with its bricks,
+// and from the store
+func deleteSnapshotTxn(reqID string, snap *snapshot.Snapinfo) error {
+	// Snapshots of a volume are taken and removed one at a time
+	lock, unlock, err := transaction.CreateLockSteps(snap.Volinfo.Name)
+	if err != nil {
+		return err
+	}
+
+	txn := transaction.NewTxn(reqID)
+	defer txn.Cleanup()
+	txn.Nodes = snap.Volinfo.Nodes()
+	txn.Steps = []*transaction.Step{
+		lock,
+		{
+			DoFunc: "snap-delete.Commit",
+			Nodes:  txn.Nodes,
+		},
+		{
+			DoFunc: "snap-delete.Store",
+			Nodes:  []uuid.UUID{gdctx.MyUUID},
+		},
+		unlock,
+	}
+	if err := txn.Ctx.Set("snapname", snap.Name); err != nil {
+		return err
+	}
+
+	_, err = txn.Do()
+	return err
+}
+
+// snapshotListHandler returns the snapshots of all volumes, or of the volume
+// given in the volume query parameter, oldest first
+func snapshotListHandler(w http.ResponseWriter, r *http.Request) {
+	snaps, err := snapshot.GetSnapshots(r.URL.Query().Get("volume"))
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	restutils.SendHTTPResponse(w, http.StatusOK, snaps)
+}
+
+func snapshotInfoHandler(w http.ResponseWriter, r *http.Request) {
+	snap, err := snapshot.GetSnapshot(mux.Vars(r)["snapname"])
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusNotFound, errors.ErrSnapNotFound.Error())
+		return
+	}
+	restutils.SendHTTPResponse(w, http.StatusOK, snap)
+}
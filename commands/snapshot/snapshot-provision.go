@@ -0,0 +1,122 @@
+package snapshotcommands
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gluster/glusterd2/errors"
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/snapshot"
+	"github.com/gluster/glusterd2/utils"
+	"github.com/gluster/glusterd2/validation"
+	"github.com/gluster/glusterd2/volume"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gorilla/mux"
+	"github.com/pborman/uuid"
+)
+
+// ProvisionSnapReq represents a request to take a snapshot of a provisioned
+// volume
+type ProvisionSnapReq struct {
+	Name string `json:"name"`
+}
+
+// snapshotProvisionHandler takes a snapshot of a provisioned volume. Requests
+// are idempotent, a snapshot of the volume which was already taken with the
+// same name is returned as is.
+func snapshotProvisionHandler(w http.ResponseWriter, r *http.Request) {
+	volname := mux.Vars(r)["volname"]
+	reqID, logger := restutils.GetReqIDandLogger(r)
+
+	var req ProvisionSnapReq
+	if err := utils.GetJSONFromRequest(r, &req); err != nil {
+		restutils.SendHTTPError(w, http.StatusUnprocessableEntity, errors.ErrJSONParsingFailed.Error())
+		return
+	}
+
+	var errs validation.Errors
+	errs.Name("name", req.Name)
+	if errs != nil {
+		restutils.SendValidationErrors(w, errs)
+		return
+	}
+
+	vol, err := volume.GetVolume(volname)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusNotFound, errors.ErrVolNotFound.Error())
+		return
+	}
+	if vol.Capacity == 0 {
+		restutils.SendHTTPError(w, http.StatusBadRequest, errors.ErrVolNotProvisioned.Error())
+		return
+	}
+
+	if existing, err := snapshot.GetSnapshot(req.Name); err == nil {
+		// A retried request gets the same response as the request
+		// which took the snapshot
+		if existing.Volinfo.Name == volname {
+			restutils.SendHTTPResponse(w, http.StatusCreated, existing)
+			return
+		}
+		restutils.SendHTTPError(w, http.StatusConflict, errors.ErrSnapExists.Error())
+		return
+	} else if err != errors.ErrSnapNotFound {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	snap := &snapshot.Snapinfo{
+		ID:        uuid.NewRandom(),
+		Name:      req.Name,
+		CreatedAt: time.Now().UTC(),
+		Volinfo:   *vol,
+	}
+	if err := createSnapshot(reqID, snap); err != nil {
+		logger.WithError(err).WithField("snapshot", req.Name).Error("snapshot create transaction failed")
+		sendSnapTxnError(w, err)
+		return
+	}
+
+	logger.WithFields(log.Fields{
+		"volume":   volname,
+		"snapshot": req.Name,
+	}).Info("snapshot of provisioned volume created")
+
+	created, err := snapshot.GetSnapshot(req.Name)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	restutils.SendHTTPResponse(w, http.StatusCreated, created)
+}
+
+// snapshotDeprovisionHandler deletes a snapshot of a provisioned volume.
+// Deleting a snapshot which doesn't exist succeeds, so that requests can be
+// retried.
+func snapshotDeprovisionHandler(w http.ResponseWriter, r *http.Request) {
+	snapname := mux.Vars(r)["snapname"]
+	reqID, logger := restutils.GetReqIDandLogger(r)
+
+	snap, err := snapshot.GetSnapshot(snapname)
+	if err == errors.ErrSnapNotFound {
+		restutils.SendHTTPResponse(w, http.StatusOK, nil)
+		return
+	} else if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if snap.Volinfo.Capacity == 0 {
+		restutils.SendHTTPError(w, http.StatusBadRequest, errors.ErrVolNotProvisioned.Error())
+		return
+	}
+
+	if err := deleteSnapshotTxn(reqID, snap); err != nil {
+		logger.WithError(err).WithField("snapshot", snapname).Error("snapshot delete transaction failed")
+		sendSnapTxnError(w, err)
+		return
+	}
+
+	logger.WithField("snapshot", snapname).Info("snapshot of provisioned volume deleted")
+	restutils.SendHTTPResponse(w, http.StatusOK, nil)
+}
@@ -0,0 +1,127 @@
+package snapshotcommands
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gluster/glusterd2/errors"
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/snapshot"
+	"github.com/gluster/glusterd2/utils"
+	"github.com/gluster/glusterd2/validation"
+	"github.com/gluster/glusterd2/volume"
+
+	"github.com/gorilla/mux"
+)
+
+// SnapScheduleReq represents a request to set the snapshot schedule of a
+// volume
+type SnapScheduleReq struct {
+	Schedule string `json:"schedule"`
+	Retain   int    `json:"retain"`
+}
+
+// snapshotScheduleSetHandler sets the snapshot schedule of a volume,
+// replacing any existing schedule
+func snapshotScheduleSetHandler(w http.ResponseWriter, r *http.Request) {
+	volname := mux.Vars(r)["volname"]
+	_, logger := restutils.GetReqIDandLogger(r)
+
+	var req SnapScheduleReq
+	if err := utils.GetJSONFromRequest(r, &req); err != nil {
+		restutils.SendHTTPError(w, http.StatusUnprocessableEntity, errors.ErrJSONParsingFailed.Error())
+		return
+	}
+
+	var errs validation.Errors
+	if errs.RequireString("schedule", req.Schedule) {
+		if _, err := snapshot.ParseCron(req.Schedule); err != nil {
+			errs.Add("schedule", "%s", err.Error())
+		}
+	}
+	if req.Retain < 1 {
+		errs.Add("retain", "must be at least 1")
+	}
+	if errs != nil {
+		restutils.SendValidationErrors(w, errs)
+		return
+	}
+
+	if !volume.ExistsFunc(volname) {
+		restutils.SendHTTPError(w, http.StatusNotFound, errors.ErrVolNotFound.Error())
+		return
+	}
+
+	sched, err := snapshot.GetSchedule(volname)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if sched == nil {
+		// The first snapshot is taken at the first scheduled time after
+		// the schedule is set
+		now := time.Now().UTC()
+		sched = &snapshot.Schedule{
+			Volume:    volname,
+			CreatedAt: now,
+			LastRun:   now,
+		}
+	}
+	sched.Schedule = req.Schedule
+	sched.Retain = req.Retain
+
+	if err := snapshot.SaveSchedule(sched); err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	logger.WithField("volume", volname).WithField("schedule", req.Schedule).Info("snapshot schedule set")
+	restutils.SendHTTPResponse(w, http.StatusOK, sched)
+}
+
+func snapshotScheduleGetHandler(w http.ResponseWriter, r *http.Request) {
+	sched, err := snapshot.GetSchedule(mux.Vars(r)["volname"])
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if sched == nil {
+		restutils.SendHTTPError(w, http.StatusNotFound, errors.ErrSnapScheduleNotFound.Error())
+		return
+	}
+	restutils.SendHTTPResponse(w, http.StatusOK, sched)
+}
+
+func snapshotScheduleListHandler(w http.ResponseWriter, r *http.Request) {
+	schedules, err := snapshot.GetSchedules()
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	restutils.SendHTTPResponse(w, http.StatusOK, schedules)
+}
+
+// snapshotScheduleDeleteHandler removes the snapshot schedule of a volume.
+// The snapshots already taken by the scheduler are kept.
+func snapshotScheduleDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	volname := mux.Vars(r)["volname"]
+	_, logger := restutils.GetReqIDandLogger(r)
+
+	sched, err := snapshot.GetSchedule(volname)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if sched == nil {
+		restutils.SendHTTPError(w, http.StatusNotFound, errors.ErrSnapScheduleNotFound.Error())
+		return
+	}
+
+	if err := snapshot.DeleteSchedule(volname); err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	logger.WithField("volume", volname).Info("snapshot schedule deleted")
+	restutils.SendHTTPResponse(w, http.StatusOK, nil)
+}
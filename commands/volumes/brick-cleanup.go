@@ -0,0 +1,103 @@
+package volumecommands
+
+import (
+	"net/http"
+
+	"github.com/gluster/glusterd2/brick"
+	gderrors "github.com/gluster/glusterd2/errors"
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/transaction"
+	"github.com/gluster/glusterd2/utils"
+	"github.com/gluster/glusterd2/validation"
+	"github.com/gluster/glusterd2/volume"
+)
+
+// BrickCleanupReq represents a request to remove stale gluster xattrs and
+// internal directories from brick paths which aren't part of any volume
+type BrickCleanupReq struct {
+	Bricks []string `json:"bricks"`
+}
+
+func cleanupBricks(c transaction.TxnCtx) error {
+
+	var bricks []brick.Brickinfo
+	if err := c.Get("bricks", &bricks); err != nil {
+		return err
+	}
+
+	if err := volume.CleanupBricks(bricks); err != nil {
+		c.Logger().WithError(err).Error("failed to cleanup bricks")
+		return err
+	}
+
+	return nil
+}
+
+func registerBrickCleanupStepFuncs() {
+	transaction.RegisterStepFunc(cleanupBricks, "bricks-cleanup.Cleanup")
+}
+
+func brickCleanupHandler(w http.ResponseWriter, r *http.Request) {
+
+	reqID, logger := restutils.GetReqIDandLogger(r)
+
+	var req BrickCleanupReq
+	if err := utils.GetJSONFromRequest(r, &req); err != nil {
+		restutils.SendHTTPError(w, http.StatusUnprocessableEntity, gderrors.ErrJSONParsingFailed.Error())
+		return
+	}
+
+	var errs validation.Errors
+	errs.Bricks("bricks", req.Bricks)
+	if errs != nil {
+		restutils.SendValidationErrors(w, errs)
+		return
+	}
+
+	bricks, err := volume.NewBrickEntriesFunc(req.Bricks, "", nil)
+	if err != nil {
+		logger.WithError(err).Error("failed to create brick entries")
+		restutils.SendHTTPError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := volume.CheckBricksNotInUse(bricks); err != nil {
+		if err == gderrors.ErrBrickPathAlreadyInUse || err == gderrors.ErrBrickPathOverlaps {
+			restutils.SendHTTPError(w, http.StatusBadRequest, err.Error())
+		} else {
+			logger.WithError(err).Error("failed to check if bricks are in use")
+			restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	nodes, err := nodesFromBricks(req.Bricks)
+	if err != nil {
+		logger.WithError(err).Error("could not prepare node list")
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	txn := transaction.NewTxn(reqID)
+	defer txn.Cleanup()
+	txn.Nodes = nodes
+	txn.Steps = []*transaction.Step{
+		{
+			DoFunc: "bricks-cleanup.Cleanup",
+			Nodes:  txn.Nodes,
+		},
+	}
+
+	if err := txn.Ctx.Set("bricks", bricks); err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if _, err := txn.Do(); err != nil {
+		logger.WithError(err).Error("failed to cleanup bricks")
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	restutils.SendHTTPResponse(w, http.StatusOK, nil)
+}
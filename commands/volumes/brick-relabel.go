@@ -0,0 +1,127 @@
+package volumecommands
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gluster/glusterd2/brick"
+	gderrors "github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/gdctx"
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/transaction"
+	"github.com/gluster/glusterd2/utils"
+	"github.com/gluster/glusterd2/validation"
+	"github.com/gluster/glusterd2/volume"
+
+	"github.com/pborman/uuid"
+)
+
+// BrickRelabelReq represents a request to set the SELinux context of bricks
+// to the one brick processes need to access them. Only bricks of existing
+// volumes can be relabeled.
+type BrickRelabelReq struct {
+	Bricks []string `json:"bricks"`
+}
+
+// relabelBricks relabels the bricks on this peer. The bricks are looked up
+// again, so that only the paths of bricks of existing volumes hosted on this
+// peer are ever relabeled.
+func relabelBricks(c transaction.TxnCtx) error {
+
+	var bricks []brick.Brickinfo
+	if err := c.Get("bricks", &bricks); err != nil {
+		return err
+	}
+
+	for _, b := range bricks {
+		if !uuid.Equal(b.NodeID, gdctx.MyUUID) {
+			continue
+		}
+		if _, err := volume.GetBrick(gdctx.MyUUID, b.Path); err != nil {
+			c.Logger().WithError(err).WithField("brick", b.Path).Error("refusing to relabel brick")
+			return err
+		}
+		if err := utils.RelabelBrick(b.Path); err != nil {
+			c.Logger().WithError(err).WithField("brick", b.Path).Error("failed to relabel brick")
+			return err
+		}
+	}
+
+	return nil
+}
+
+func registerBrickRelabelStepFuncs() {
+	transaction.RegisterStepFunc(relabelBricks, "bricks-relabel.Relabel")
+}
+
+func brickRelabelHandler(w http.ResponseWriter, r *http.Request) {
+
+	reqID, logger := restutils.GetReqIDandLogger(r)
+
+	var req BrickRelabelReq
+	if err := utils.GetJSONFromRequest(r, &req); err != nil {
+		restutils.SendHTTPError(w, http.StatusUnprocessableEntity, gderrors.ErrJSONParsingFailed.Error())
+		return
+	}
+
+	var errs validation.Errors
+	errs.Bricks("bricks", req.Bricks)
+	if errs != nil {
+		restutils.SendValidationErrors(w, errs)
+		return
+	}
+
+	bricks, err := volume.NewBrickEntriesFunc(req.Bricks, "", nil)
+	if err != nil {
+		logger.WithError(err).Error("failed to create brick entries")
+		restutils.SendHTTPError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	for i, b := range bricks {
+		vb, err := volume.GetBrick(b.NodeID, b.Path)
+		if err == gderrors.ErrBrickNotFound {
+			restutils.SendHTTPError(w, http.StatusNotFound, fmt.Sprintf("%s: %s", req.Bricks[i], err.Error()))
+			return
+		} else if err != nil {
+			logger.WithError(err).Error("failed to look up bricks")
+			restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		bricks[i] = *vb
+	}
+
+	nodes, err := nodesFromBricks(req.Bricks)
+	if err != nil {
+		logger.WithError(err).Error("could not prepare node list")
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	txn := transaction.NewTxn(reqID)
+	defer txn.Cleanup()
+	txn.Nodes = nodes
+	txn.Steps = []*transaction.Step{
+		{
+			DoFunc: "bricks-relabel.Relabel",
+			Nodes:  txn.Nodes,
+		},
+	}
+
+	if err := txn.Ctx.Set("bricks", bricks); err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if _, err := txn.Do(); err != nil {
+		logger.WithError(err).Error("failed to relabel bricks")
+		if transaction.IsTimeout(err) {
+			restutils.SendHTTPError(w, http.StatusGatewayTimeout, err.Error())
+		} else {
+			restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	restutils.SendHTTPResponse(w, http.StatusOK, nil)
+}
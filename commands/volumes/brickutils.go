@@ -1,15 +1,20 @@
 package volumecommands
 
 import (
+	"fmt"
 	"os/exec"
 	"syscall"
 	"time"
 
 	"github.com/gluster/glusterd2/brick"
 	"github.com/gluster/glusterd2/daemon"
+	"github.com/gluster/glusterd2/errors"
 	"github.com/gluster/glusterd2/peer"
+	"github.com/gluster/glusterd2/quorum"
+	"github.com/gluster/glusterd2/servers/sunrpc"
 	"github.com/gluster/glusterd2/utils"
 
+	log "github.com/Sirupsen/logrus"
 	"github.com/pborman/uuid"
 )
 
@@ -40,6 +45,14 @@ func errorContainsErrno(err error, errno syscall.Errno) bool {
 
 func startBrick(b brick.Brickinfo) error {
 
+	if !quorum.IsMet() {
+		return errors.ErrQuorumNotMet
+	}
+
+	if peer.InMaintenance(b.NodeID.String()) {
+		return errors.ErrPeerInMaintenance
+	}
+
 	brickDaemon, err := brick.NewGlusterfsd(b)
 	if err != nil {
 		return err
@@ -78,6 +91,129 @@ func stopBrick(b brick.Brickinfo) error {
 	return nil
 }
 
+// brickOp sends a brick op RPC to the brick process with the given input
+// and returns the output sent by the brick
+func brickOp(b brick.Brickinfo, op int, input map[string]string) (map[string]string, error) {
+	brickDaemon, err := brick.NewGlusterfsd(b)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := daemon.GetRPCClient(brickDaemon)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &brick.GfBrickOpReq{
+		Name: b.Path,
+		Op:   op,
+	}
+	if input != nil {
+		if req.Input, err = sunrpc.DictSerialize(input); err != nil {
+			return nil, err
+		}
+	}
+
+	var rsp brick.GfBrickOpRsp
+	if err := client.Call("BrickOp", req, &rsp); err != nil {
+		return nil, err
+	}
+	if rsp.OpRet != 0 {
+		return nil, fmt.Errorf("brick op %d failed: %s", op, rsp.OpErrstr)
+	}
+
+	if len(rsp.Output) == 0 {
+		return nil, nil
+	}
+	return sunrpc.DictUnserialize(rsp.Output)
+}
+
+// barrierBrick enables or disables the barrier on the brick. When the
+// barrier is enabled, the brick holds back write fops until it is disabled
+// or the barrier times out.
+func barrierBrick(b brick.Brickinfo, enable bool) error {
+	value := "disable"
+	if enable {
+		value = "enable"
+	}
+	_, err := brickOp(b, brick.OpBrickBarrier, map[string]string{"barrier": value})
+	return err
+}
+
+// BrickStopResult is the result of stopping a single brick
+type BrickStopResult struct {
+	Brick  string    `json:"brick"`
+	NodeID uuid.UUID `json:"node-id"`
+	Pid    int       `json:"pid,omitempty"`
+	// Killed is set when the brick did not exit within the stop timeout
+	// and had to be killed
+	Killed bool   `json:"killed,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// gracefulStopBrick asks the brick to stop accepting writes and terminate
+// itself. If the brick process is still running after the timeout, it is
+// killed.
+func gracefulStopBrick(b brick.Brickinfo, timeout time.Duration) BrickStopResult {
+
+	result := BrickStopResult{
+		Brick:  b.Hostname + ":" + b.Path,
+		NodeID: b.NodeID,
+	}
+
+	brickDaemon, err := brick.NewGlusterfsd(b)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	pid, err := daemon.ReadPidFromFile(brickDaemon.PidFile())
+	if err != nil {
+		// Nothing to stop
+		return result
+	}
+	if _, err := daemon.GetProcess(pid); err != nil {
+		return result
+	}
+	result.Pid = pid
+
+	if err := barrierBrick(b, true); err != nil {
+		log.WithError(err).WithField("brick", result.Brick).Warn(
+			"failed to enable barrier before stopping brick")
+	}
+
+	if _, err := brickOp(b, brick.OpBrickTerminate, nil); err != nil {
+		log.WithError(err).WithField("brick", result.Brick).Warn(
+			"failed to send terminate RPC, sending SIGTERM")
+		daemon.Stop(brickDaemon, false)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if _, err := daemon.GetProcess(pid); err != nil {
+			return result
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	log.WithFields(log.Fields{
+		"brick": result.Brick,
+		"pid":   pid,
+	}).Warn("brick did not stop within timeout, killing it")
+
+	process, err := daemon.GetProcess(pid)
+	if err != nil {
+		return result
+	}
+	if err := process.Kill(); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Killed = true
+
+	return result
+}
+
 func nodesFromBricks(bricks []string) ([]uuid.UUID, error) {
 
 	var nodes []uuid.UUID
@@ -2,6 +2,7 @@
 package volumecommands
 
 import (
+	"github.com/gluster/glusterd2/hooks"
 	"github.com/gluster/glusterd2/servers/rest/route"
 )
 
@@ -24,6 +25,20 @@ func (c *Command) Routes() route.Routes {
 			Pattern:     "/volumes/{volname}/expand",
 			Version:     1,
 			HandlerFunc: volumeExpandHandler},
+		route.Route{
+			Name:        "VolumeImport",
+			Method:      "POST",
+			Pattern:     "/volumes/import",
+			Version:     1,
+			HandlerFunc: volumeImportHandler},
+		// The option table is registered before VolumeInfo, so that
+		// "options" isn't taken to be a volume name
+		route.Route{
+			Name:        "VolumeOptionTable",
+			Method:      "GET",
+			Pattern:     "/volumes/options",
+			Version:     1,
+			HandlerFunc: volumeOptionTableHandler},
 		// TODO: Implmement volume reset as
 		// DELETE /volumes/{volname}/options
 		route.Route{
@@ -32,6 +47,48 @@ func (c *Command) Routes() route.Routes {
 			Pattern:     "/volumes/{volname}/options",
 			Version:     1,
 			HandlerFunc: volumeOptionsHandler},
+		route.Route{
+			Name:        "VolumeProfile",
+			Method:      "POST",
+			Pattern:     "/volumes/{volname}/profile",
+			Version:     1,
+			HandlerFunc: volumeProfileHandler},
+		route.Route{
+			Name:        "VolumeAccessGet",
+			Method:      "GET",
+			Pattern:     "/volumes/{volname}/access",
+			Version:     1,
+			HandlerFunc: volumeAccessGetHandler},
+		route.Route{
+			Name:        "VolumeAccessSet",
+			Method:      "PUT",
+			Pattern:     "/volumes/{volname}/access",
+			Version:     1,
+			HandlerFunc: volumeAccessSetHandler},
+		route.Route{
+			Name:        "ProfileList",
+			Method:      "GET",
+			Pattern:     "/profiles",
+			Version:     1,
+			HandlerFunc: profileListHandler},
+		route.Route{
+			Name:        "ProfileGet",
+			Method:      "GET",
+			Pattern:     "/profiles/{profile}",
+			Version:     1,
+			HandlerFunc: profileGetHandler},
+		route.Route{
+			Name:        "ProfileSet",
+			Method:      "PUT",
+			Pattern:     "/profiles/{profile}",
+			Version:     1,
+			HandlerFunc: profileSetHandler},
+		route.Route{
+			Name:        "ProfileDelete",
+			Method:      "DELETE",
+			Pattern:     "/profiles/{profile}",
+			Version:     1,
+			HandlerFunc: profileDeleteHandler},
 		route.Route{
 			Name:        "VolumeDelete",
 			Method:      "DELETE",
@@ -50,12 +107,54 @@ func (c *Command) Routes() route.Routes {
 			Pattern:     "/volumes/{volname}/status",
 			Version:     1,
 			HandlerFunc: volumeStatusHandler},
+		route.Route{
+			Name:        "VolumeSize",
+			Method:      "GET",
+			Pattern:     "/volumes/{volname}/size",
+			Version:     1,
+			HandlerFunc: volumeSizeHandler},
+		route.Route{
+			Name:        "VolumeBalance",
+			Method:      "GET",
+			Pattern:     "/volumes/{volname}/balance",
+			Version:     1,
+			HandlerFunc: volumeBalanceHandler},
+		route.Route{
+			Name:        "VolumeCheck",
+			Method:      "GET",
+			Pattern:     "/volumes/{volname}/check",
+			Version:     1,
+			HandlerFunc: volumeCheckHandler},
+		route.Route{
+			Name:        "VolumeResync",
+			Method:      "POST",
+			Pattern:     "/volumes/{volname}/resync",
+			Version:     1,
+			HandlerFunc: volumeResyncHandler},
+		route.Route{
+			Name:        "VolumeClients",
+			Method:      "GET",
+			Pattern:     "/volumes/{volname}/clients",
+			Version:     1,
+			HandlerFunc: volumeClientsHandler},
+		route.Route{
+			Name:        "VolumeMount",
+			Method:      "GET",
+			Pattern:     "/volumes/{volname}/mount",
+			Version:     1,
+			HandlerFunc: volumeMountHandler},
 		route.Route{
 			Name:        "VolumeList",
 			Method:      "GET",
 			Pattern:     "/volumes",
 			Version:     1,
 			HandlerFunc: volumeListHandler},
+		route.Route{
+			Name:        "VolumeListV2",
+			Method:      "GET",
+			Pattern:     "/volumes",
+			Version:     2,
+			HandlerFunc: volumeListV2Handler},
 		route.Route{
 			Name:        "VolumeStart",
 			Method:      "POST",
@@ -68,6 +167,90 @@ func (c *Command) Routes() route.Routes {
 			Pattern:     "/volumes/{volname}/stop",
 			Version:     1,
 			HandlerFunc: volumeStopHandler},
+		route.Route{
+			Name:        "VolumeStopV2",
+			Method:      "POST",
+			Pattern:     "/volumes/{volname}/stop",
+			Version:     2,
+			HandlerFunc: volumeStopV2Handler},
+		route.Route{
+			Name:        "VolumeStartBatch",
+			Method:      "POST",
+			Pattern:     "/volumes/start",
+			Version:     1,
+			HandlerFunc: volumeStartBatchHandler},
+		route.Route{
+			Name:        "VolumeStopBatch",
+			Method:      "POST",
+			Pattern:     "/volumes/stop",
+			Version:     1,
+			HandlerFunc: volumeStopBatchHandler},
+		route.Route{
+			Name:        "VolumeStopAll",
+			Method:      "POST",
+			Pattern:     "/volumes/stop-all",
+			Version:     1,
+			HandlerFunc: volumeStopAllHandler},
+		route.Route{
+			Name:        "VolumeBarrier",
+			Method:      "POST",
+			Pattern:     "/volumes/{volname}/barrier",
+			Version:     1,
+			HandlerFunc: volumeBarrierHandler},
+		route.Route{
+			Name:        "VolumeSplitBrain",
+			Method:      "POST",
+			Pattern:     "/volumes/{volname}/heal/split-brain",
+			Version:     1,
+			HandlerFunc: volumeSplitBrainHandler},
+		route.Route{
+			Name:        "ProvisionVolume",
+			Method:      "POST",
+			Pattern:     "/provisioner/volumes",
+			Version:     1,
+			HandlerFunc: provisionHandler},
+		route.Route{
+			Name:        "ProvisionExpand",
+			Method:      "POST",
+			Pattern:     "/provisioner/volumes/{volname}/expand",
+			Version:     1,
+			HandlerFunc: provisionExpandHandler},
+		route.Route{
+			Name:        "ProvisionDelete",
+			Method:      "DELETE",
+			Pattern:     "/provisioner/volumes/{volname}",
+			Version:     1,
+			HandlerFunc: provisionDeleteHandler},
+		route.Route{
+			Name:        "ProvisionCapacity",
+			Method:      "GET",
+			Pattern:     "/provisioner/capacity",
+			Version:     1,
+			HandlerFunc: provisionCapacityHandler},
+		route.Route{
+			Name:        "Apply",
+			Method:      "POST",
+			Pattern:     "/apply",
+			Version:     1,
+			HandlerFunc: applyHandler},
+		route.Route{
+			Name:        "PeerMaintenance",
+			Method:      "POST",
+			Pattern:     "/peers/{peerid}/maintenance",
+			Version:     1,
+			HandlerFunc: peerMaintenanceHandler},
+		route.Route{
+			Name:        "BrickCleanup",
+			Method:      "POST",
+			Pattern:     "/bricks/cleanup",
+			Version:     1,
+			HandlerFunc: brickCleanupHandler},
+		route.Route{
+			Name:        "BrickRelabel",
+			Method:      "POST",
+			Pattern:     "/bricks/relabel",
+			Version:     1,
+			HandlerFunc: brickRelabelHandler},
 	}
 }
 
@@ -78,6 +261,17 @@ func (c *Command) RegisterStepFuncs() {
 	registerVolStartStepFuncs()
 	registerVolStopStepFuncs()
 	registerVolStatusStepFuncs()
+	registerVolSizeStepFuncs()
 	registerVolExpandStepFuncs()
 	registerVolOptionStepFuncs()
+	registerVolDryRunStepFuncs()
+	registerBrickCleanupStepFuncs()
+	registerBrickRelabelStepFuncs()
+	registerVolClientsStepFuncs()
+	registerVolBarrierStepFuncs()
+	registerVolHealStepFuncs()
+	registerVolProvisionStepFuncs()
+	registerPeerMaintenanceStepFuncs()
+	registerVolCheckStepFuncs()
+	hooks.RegisterStepFuncs()
 }
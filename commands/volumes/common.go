@@ -1,10 +1,14 @@
 package volumecommands
 
 import (
+	"encoding/json"
+	"net/http"
 	"os"
 	"strings"
 
 	"github.com/gluster/glusterd2/gdctx"
+	"github.com/gluster/glusterd2/hooks"
+	"github.com/gluster/glusterd2/pkg/api"
 	"github.com/gluster/glusterd2/servers/sunrpc"
 	"github.com/gluster/glusterd2/transaction"
 	"github.com/gluster/glusterd2/utils"
@@ -12,6 +16,7 @@ import (
 	"github.com/gluster/glusterd2/volume"
 	"github.com/gluster/glusterd2/xlator"
 
+	log "github.com/Sirupsen/logrus"
 	"github.com/pborman/uuid"
 )
 
@@ -129,3 +134,46 @@ func storeVolume(c transaction.TxnCtx) error {
 
 	return nil
 }
+
+// VolumeResp is the response sent for operations which return a volume. The
+// post hooks of the operation which failed are included, along with any
+// warnings about the bricks of the volume.
+type VolumeResp struct {
+	*volume.Volinfo
+	HookFailures []hooks.Result `json:"hook-failures,omitempty"`
+	Warnings     []string       `json:"warnings,omitempty"`
+}
+
+// hookEnv returns the environment given to the hooks of operations on the
+// given volume
+func hookEnv(v *volume.Volinfo) map[string]string {
+	env := make(map[string]string)
+	env["volname"] = v.Name
+	env["volume-id"] = v.ID.String()
+	return env
+}
+
+// setHookFailuresHeader reports the hooks which failed in a response header,
+// for responses whose body can't carry them. It must be called before the
+// response is sent.
+func setHookFailuresHeader(w http.ResponseWriter, failed []hooks.Result) {
+	if len(failed) == 0 {
+		return
+	}
+	b, err := json.Marshal(failed)
+	if err != nil {
+		return
+	}
+	w.Header().Set(api.HookFailuresHeader, string(b))
+}
+
+// logHookFailures logs the hooks which failed during an operation
+func logHookFailures(logger log.FieldLogger, failed []hooks.Result) {
+	for _, f := range failed {
+		logger.WithFields(log.Fields{
+			"hook":  f.Hook,
+			"node":  f.NodeID.String(),
+			"error": f.Error,
+		}).Warn("hook failed")
+	}
+}
@@ -0,0 +1,303 @@
+package volumecommands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gluster/glusterd2/brick"
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/gdctx"
+	"github.com/gluster/glusterd2/peer"
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/transaction"
+	"github.com/gluster/glusterd2/utils"
+	"github.com/gluster/glusterd2/volume"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gorilla/mux"
+	"github.com/pborman/uuid"
+)
+
+// PeerMaintenanceReq represents a request to place a peer into, or take it
+// out of, maintenance mode
+type PeerMaintenanceReq struct {
+	Enable bool `json:"enable"`
+	// Force skips the check for pending heals before stopping bricks
+	Force bool `json:"force,omitempty"`
+}
+
+// pendingHeals returns the number of entries pending heal on the brick, as
+// recorded in the index xlator's xattrop directory
+func pendingHeals(b brick.Brickinfo) (int, error) {
+	dir := path.Join(b.Path, ".glusterfs", "indices", "xattrop")
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, e := range entries {
+		// The base file which all index entries link to
+		if strings.HasPrefix(e.Name(), "xattrop-") {
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
+
+// replicaSetsWithPeer returns the replica sets of the volume which have a
+// brick on the given peer
+func replicaSetsWithPeer(v *volume.Volinfo, id uuid.UUID) [][]brick.Brickinfo {
+	var sets [][]brick.Brickinfo
+	if v.ReplicaCount <= 1 {
+		return sets
+	}
+
+	for i := 0; i+v.ReplicaCount <= len(v.Bricks); i += v.ReplicaCount {
+		set := v.Bricks[i : i+v.ReplicaCount]
+		for _, b := range set {
+			if uuid.Equal(b.NodeID, id) {
+				sets = append(sets, set)
+				break
+			}
+		}
+	}
+	return sets
+}
+
+// checkPendingHeals fails if any local brick in a replica set shared with
+// the peer going into maintenance has entries pending heal
+func checkPendingHeals(c transaction.TxnCtx) error {
+	var peerID uuid.UUID
+	if err := c.Get("peerid", &peerID); err != nil {
+		return err
+	}
+
+	volumes, err := volume.GetVolumes()
+	if err != nil {
+		return err
+	}
+
+	for i := range volumes {
+		if volumes[i].Status != volume.VolStarted {
+			continue
+		}
+		for _, set := range replicaSetsWithPeer(&volumes[i], peerID) {
+			for _, b := range set {
+				if !uuid.Equal(b.NodeID, gdctx.MyUUID) {
+					continue
+				}
+				count, err := pendingHeals(b)
+				if err != nil {
+					c.Logger().WithError(err).WithField(
+						"brick", b.Path).Warn("failed to get pending heal count")
+					continue
+				}
+				if count > 0 {
+					return fmt.Errorf("%s: %d entries on brick %s:%s",
+						errors.ErrPendingHeals.Error(), count, b.Hostname, b.Path)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// forEachLocalStartedBrick runs fn for every brick on this node which
+// belongs to a started volume
+func forEachLocalStartedBrick(fn func(b brick.Brickinfo) error) error {
+	volumes, err := volume.GetVolumes()
+	if err != nil {
+		return err
+	}
+
+	for _, v := range volumes {
+		if v.Status != volume.VolStarted {
+			continue
+		}
+		for _, b := range v.Bricks {
+			if !uuid.Equal(b.NodeID, gdctx.MyUUID) {
+				continue
+			}
+			if err := fn(b); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// startedBrickCount returns the number of bricks on the peer which belong to
+// started volumes
+func startedBrickCount(id uuid.UUID) (int, error) {
+	volumes, err := volume.GetVolumes()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, v := range volumes {
+		if v.Status != volume.VolStarted {
+			continue
+		}
+		for _, b := range v.Bricks {
+			if uuid.Equal(b.NodeID, id) {
+				count++
+			}
+		}
+	}
+	return count, nil
+}
+
+func maintenanceStopBricks(c transaction.TxnCtx) error {
+	return forEachLocalStartedBrick(func(b brick.Brickinfo) error {
+		result := gracefulStopBrick(b, defaultBrickStopTimeout)
+		c.Logger().WithFields(log.Fields{
+			"brick":  result.Brick,
+			"killed": result.Killed,
+		}).Info("stopped brick for maintenance")
+		if result.Error != "" {
+			return fmt.Errorf("failed to stop brick %s: %s", result.Brick, result.Error)
+		}
+		return nil
+	})
+}
+
+func maintenanceStartBricks(c transaction.TxnCtx) error {
+	return forEachLocalStartedBrick(func(b brick.Brickinfo) error {
+		err := startBrick(b)
+		if err == errors.ErrProcessAlreadyRunning {
+			return nil
+		}
+		return err
+	})
+}
+
+func registerPeerMaintenanceStepFuncs() {
+	var sfs = []struct {
+		name string
+		sf   transaction.StepFunc
+	}{
+		{"peer-maintenance.CheckHeals", checkPendingHeals},
+		{"peer-maintenance.StopBricks", maintenanceStopBricks},
+		{"peer-maintenance.StartBricks", maintenanceStartBricks},
+	}
+	for _, sf := range sfs {
+		transaction.RegisterStepFunc(sf.sf, sf.name)
+	}
+}
+
+// setPeerMaintenance sets the maintenance mode of the peer in the store,
+// keeping any concurrent changes of the peer
+func setPeerMaintenance(id string, enable bool) (*peer.Peer, error) {
+	return peer.UpdatePeer(id, func(p *peer.Peer) error {
+		p.Maintenance = enable
+		return nil
+	})
+}
+
+// peerMaintenanceHandler places a peer into maintenance mode, or takes it
+// out of it. When entering maintenance, the bricks on the peer are stopped
+// once there are no pending heals on the other bricks of their replica
+// sets. When leaving maintenance, the bricks are started again.
+func peerMaintenanceHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["peerid"]
+	reqID, logger := restutils.GetReqIDandLogger(r)
+
+	var req PeerMaintenanceReq
+	if err := utils.GetJSONFromRequest(r, &req); err != nil {
+		restutils.SendHTTPError(w, http.StatusUnprocessableEntity, errors.ErrJSONParsingFailed.Error())
+		return
+	}
+
+	p, err := peer.GetPeerF(id)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	logger = logger.WithField("peerid", p.ID.String())
+
+	if p.Maintenance == req.Enable {
+		restutils.SendHTTPResponse(w, http.StatusOK, p)
+		return
+	}
+
+	lock, unlock, err := transaction.CreateLockSteps(p.ID.String())
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	txn := transaction.NewTxn(reqID)
+	defer txn.Cleanup()
+	txn.Nodes = []uuid.UUID{p.ID}
+
+	if req.Enable {
+		txn.Steps = []*transaction.Step{lock}
+		if !req.Force {
+			// Every peer checks its own bricks for pending heals
+			if txn.Nodes, err = peer.GetPeerIDs(); err != nil {
+				restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			txn.Steps = append(txn.Steps, &transaction.Step{
+				DoFunc: "peer-maintenance.CheckHeals",
+				Nodes:  txn.Nodes,
+			})
+		}
+		bricks, err := startedBrickCount(p.ID)
+		if err != nil {
+			restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		txn.Steps = append(txn.Steps, &transaction.Step{
+			DoFunc:  "peer-maintenance.StopBricks",
+			Nodes:   []uuid.UUID{p.ID},
+			Timeout: stopStepTimeout(bricks, defaultBrickStopTimeout),
+		}, unlock)
+	} else {
+		txn.Steps = []*transaction.Step{
+			lock,
+			{
+				DoFunc: "peer-maintenance.StartBricks",
+				Nodes:  txn.Nodes,
+			},
+			unlock,
+		}
+	}
+
+	// The peer is marked to be in maintenance before its bricks are
+	// stopped, so that nothing restarts them, and taken out of maintenance
+	// before its bricks are started again.
+	if p, err = setPeerMaintenance(p.ID.String(), req.Enable); err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	txn.Ctx.Set("peerid", p.ID)
+
+	if _, err := txn.Do(); err != nil {
+		logger.WithError(err).Error("failed to change peer maintenance mode")
+
+		if _, err := setPeerMaintenance(p.ID.String(), !req.Enable); err != nil {
+			logger.WithError(err).Error("failed to restore peer maintenance mode")
+		}
+
+		if err == transaction.ErrLockTimeout {
+			restutils.SendHTTPError(w, http.StatusConflict, err.Error())
+		} else if transaction.IsTimeout(err) {
+			restutils.SendHTTPError(w, http.StatusGatewayTimeout, err.Error())
+		} else {
+			restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	logger.WithField("maintenance", req.Enable).Info("peer maintenance mode changed")
+	restutils.SendHTTPResponse(w, http.StatusOK, p)
+}
@@ -0,0 +1,158 @@
+package volumecommands
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/hooks"
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/transaction"
+	"github.com/gluster/glusterd2/utils"
+	"github.com/gluster/glusterd2/validation"
+	"github.com/gluster/glusterd2/volume"
+
+	"github.com/gorilla/mux"
+)
+
+// VolAccessReq represents a request to replace the access control list of a
+// volume
+type VolAccessReq struct {
+	AllowAddrs  []string `json:"allow-addrs"`
+	RejectAddrs []string `json:"reject-addrs"`
+	AllowUsers  []string `json:"allow-users"`
+}
+
+// VolAccessResp is the response sent for a volume access request
+type VolAccessResp struct {
+	AllowAddrs   []string       `json:"allow-addrs"`
+	RejectAddrs  []string       `json:"reject-addrs"`
+	AllowUsers   []string       `json:"allow-users"`
+	HookFailures []hooks.Result `json:"hook-failures,omitempty"`
+}
+
+func newVolAccessResp(a *volume.VolAccess, failed []hooks.Result) *VolAccessResp {
+	return &VolAccessResp{
+		AllowAddrs:   a.AllowAddrs,
+		RejectAddrs:  a.RejectAddrs,
+		AllowUsers:   a.AllowUsers,
+		HookFailures: failed,
+	}
+}
+
+// validateAccessAddr checks that the address is an IP address, a CIDR range,
+// or a hostname or address pattern which can have * wildcards, as accepted by
+// the auth.addr options of the bricks
+func validateAccessAddr(addr string) error {
+	if addr == "" {
+		return fmt.Errorf("empty address")
+	}
+	if net.ParseIP(addr) != nil {
+		return nil
+	}
+	if strings.Contains(addr, "/") {
+		if _, _, err := net.ParseCIDR(addr); err != nil {
+			return fmt.Errorf("invalid CIDR range %s", addr)
+		}
+		return nil
+	}
+	for _, c := range addr {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '.', c == '-', c == ':', c == '*':
+		default:
+			return fmt.Errorf("invalid address %s", addr)
+		}
+	}
+	return nil
+}
+
+// validateAccessUser checks that the user name can be given in the comma
+// separated auth.ssl-allow option
+func validateAccessUser(user string) error {
+	if strings.TrimSpace(user) == "" || strings.Contains(user, ",") {
+		return fmt.Errorf("invalid user %q", user)
+	}
+	return nil
+}
+
+func validateVolAccessReq(req *VolAccessReq) validation.Errors {
+	var errs validation.Errors
+	for i, addr := range req.AllowAddrs {
+		errs.Check(fmt.Sprintf("allow-addrs[%d]", i), validateAccessAddr(addr))
+	}
+	for i, addr := range req.RejectAddrs {
+		errs.Check(fmt.Sprintf("reject-addrs[%d]", i), validateAccessAddr(addr))
+	}
+	for i, user := range req.AllowUsers {
+		errs.Check(fmt.Sprintf("allow-users[%d]", i), validateAccessUser(user))
+	}
+	return errs
+}
+
+func volumeAccessGetHandler(w http.ResponseWriter, r *http.Request) {
+	volname := mux.Vars(r)["volname"]
+
+	volinfo, err := volume.GetVolume(volname)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusNotFound, errors.ErrVolNotFound.Error())
+		return
+	}
+	restutils.SendHTTPResponse(w, http.StatusOK, newVolAccessResp(&volinfo.Access, nil))
+}
+
+// volumeAccessSetHandler replaces the access control list of a volume. The
+// brick volfiles are regenerated, and the running bricks are notified to
+// fetch them, so that the change takes effect without clients remounting.
+func volumeAccessSetHandler(w http.ResponseWriter, r *http.Request) {
+	volname := mux.Vars(r)["volname"]
+	reqID, logger := restutils.GetReqIDandLogger(r)
+
+	volinfo, err := volume.GetVolume(volname)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusNotFound, errors.ErrVolNotFound.Error())
+		return
+	}
+
+	var req VolAccessReq
+	if err := utils.GetJSONFromRequest(r, &req); err != nil {
+		restutils.SendHTTPError(w, http.StatusUnprocessableEntity, errors.ErrJSONParsingFailed.Error())
+		return
+	}
+
+	if errs := validateVolAccessReq(&req); errs != nil {
+		restutils.SendValidationErrors(w, errs)
+		return
+	}
+
+	volinfo.Access = volume.VolAccess{
+		AllowAddrs:  req.AllowAddrs,
+		RejectAddrs: req.RejectAddrs,
+		AllowUsers:  req.AllowUsers,
+	}
+
+	env := hookEnv(volinfo)
+	env["option.auth.allow"] = strings.Join(req.AllowAddrs, ",")
+	env["option.auth.reject"] = strings.Join(req.RejectAddrs, ",")
+	env["option.auth.ssl-allow"] = strings.Join(req.AllowUsers, ",")
+
+	failed, err := commitVolumeChange(reqID, volinfo, env)
+	if err != nil {
+		logger.WithError(err).WithField("volume", volname).Error("volume access transaction failed")
+		if err == transaction.ErrLockTimeout {
+			restutils.SendHTTPError(w, http.StatusConflict, err.Error())
+		} else if transaction.IsTimeout(err) {
+			restutils.SendHTTPError(w, http.StatusGatewayTimeout, err.Error())
+		} else {
+			restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	logHookFailures(logger, failed)
+
+	logger.WithField("volume", volname).Info("volume access list changed")
+	restutils.SendHTTPResponse(w, http.StatusOK, newVolAccessResp(&volinfo.Access, failed))
+}
@@ -0,0 +1,270 @@
+package volumecommands
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gluster/glusterd2/errors"
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/utils"
+	"github.com/gluster/glusterd2/validation"
+	"github.com/gluster/glusterd2/volume"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// Operations of an apply plan
+const (
+	ApplyCreate     = "create"
+	ApplyExpand     = "expand"
+	ApplySetOptions = "set-options"
+)
+
+// ApplySpec is the declarative spec of the volumes of the cluster. Volumes
+// in the cluster which are not in the spec are left alone.
+type ApplySpec struct {
+	Volumes []VolumeSpec `json:"volumes"`
+}
+
+// VolumeSpec is the desired state of a volume. Volumes which don't exist are
+// provisioned with the given size and replica count. Existing volumes are
+// expanded to the given size and get the given options set.
+type VolumeSpec struct {
+	Name string `json:"name"`
+	// Size is the size of the volume in bytes. It is required for new
+	// volumes, and can only be changed for provisioned volumes.
+	Size uint64 `json:"size,omitempty"`
+	// Replica is the replica count of the volume, 1 for distribute
+	// volumes. It can't be changed once the volume is created.
+	Replica int               `json:"replica,omitempty"`
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// ApplyAction is an operation of the plan to bring the cluster to the state
+// of a spec
+type ApplyAction struct {
+	Op      string            `json:"op"`
+	Volume  string            `json:"volume"`
+	Size    uint64            `json:"size,omitempty"`
+	Options map[string]string `json:"options,omitempty"`
+	// Done is set once the operation has been executed
+	Done  bool   `json:"done"`
+	Error string `json:"error,omitempty"`
+}
+
+// ApplyResp is the response sent for an apply request. Unmanaged lists the
+// volumes of the cluster which are not in the spec.
+type ApplyResp struct {
+	DryRun    bool          `json:"dry-run"`
+	Actions   []ApplyAction `json:"actions"`
+	Unmanaged []string      `json:"unmanaged"`
+}
+
+func validateApplySpec(spec *ApplySpec) validation.Errors {
+	var errs validation.Errors
+	seen := make(map[string]bool)
+	for i := range spec.Volumes {
+		v := &spec.Volumes[i]
+		field := fmt.Sprintf("volumes[%d]", i)
+		errs.VolumeName(field+".name", v.Name)
+		// Volume names are unique ignoring case
+		key := strings.ToLower(v.Name)
+		if seen[key] {
+			errs.Add(field+".name", "volume %s is given more than once", v.Name)
+		}
+		seen[key] = true
+		if v.Replica < 0 {
+			errs.Add(field+".replica", "replica count must be at least 1")
+		}
+	}
+	return errs
+}
+
+// planApply diffs the spec against the volumes in the store, and returns the
+// operations needed to bring the volumes to the state of the spec. Changes
+// which can't be made to existing volumes fail the plan.
+func planApply(spec *ApplySpec) (*ApplyResp, error) {
+	for _, v := range spec.Volumes {
+		if err := areOptionNamesValid(v.Options); err != nil {
+			return nil, fmt.Errorf("volume %s: invalid option specified: %s", v.Name, err.Error())
+		}
+	}
+
+	vols, err := volume.GetVolumes()
+	if err != nil {
+		return nil, err
+	}
+	return diffSpec(spec, vols)
+}
+
+// diffSpec returns the operations needed to bring the given volumes to the
+// state of the spec, and the volumes which are not in the spec
+func diffSpec(spec *ApplySpec, vols []volume.Volinfo) (*ApplyResp, error) {
+	resp := &ApplyResp{Actions: []ApplyAction{}, Unmanaged: []string{}}
+
+	byName := make(map[string]*volume.Volinfo, len(vols))
+	for i := range vols {
+		byName[vols[i].Name] = &vols[i]
+	}
+
+	inSpec := make(map[string]bool)
+	for _, v := range spec.Volumes {
+		inSpec[v.Name] = true
+
+		vol, ok := byName[v.Name]
+		if !ok {
+			if v.Size == 0 {
+				return nil, fmt.Errorf("volume %s: size is required to create the volume", v.Name)
+			}
+			for _, existing := range vols {
+				if validation.SameName(existing.Name, v.Name) {
+					return nil, fmt.Errorf("volume %s: %s %s", v.Name, errors.ErrVolNameConflict.Error(), existing.Name)
+				}
+			}
+			// The options are set when the volume is created
+			resp.Actions = append(resp.Actions, ApplyAction{
+				Op:      ApplyCreate,
+				Volume:  v.Name,
+				Size:    v.Size,
+				Options: v.Options,
+			})
+			continue
+		}
+
+		if v.Replica != 0 && v.Replica != vol.ReplicaCount {
+			return nil, fmt.Errorf("volume %s: replica count cannot be changed from %d to %d",
+				v.Name, vol.ReplicaCount, v.Replica)
+		}
+
+		if v.Size != 0 && v.Size != vol.Capacity {
+			if vol.Capacity == 0 {
+				return nil, fmt.Errorf("volume %s: %s", v.Name, errors.ErrVolNotProvisioned.Error())
+			}
+			if v.Size < vol.Capacity {
+				return nil, fmt.Errorf("volume %s: provisioned volumes cannot be shrunk", v.Name)
+			}
+			resp.Actions = append(resp.Actions, ApplyAction{
+				Op:     ApplyExpand,
+				Volume: v.Name,
+				Size:   v.Size,
+			})
+		}
+
+		changed := make(map[string]string)
+		for k, val := range v.Options {
+			if cur, ok := vol.Options[k]; !ok || cur != val {
+				changed[k] = val
+			}
+		}
+		if len(changed) != 0 {
+			resp.Actions = append(resp.Actions, ApplyAction{
+				Op:      ApplySetOptions,
+				Volume:  v.Name,
+				Options: changed,
+			})
+		}
+	}
+
+	for _, vol := range vols {
+		if !inSpec[vol.Name] {
+			resp.Unmanaged = append(resp.Unmanaged, vol.Name)
+		}
+	}
+	sort.Strings(resp.Unmanaged)
+
+	return resp, nil
+}
+
+// applyAction executes an operation of an apply plan
+func applyAction(reqID string, a *ApplyAction, spec *VolumeSpec, logger log.FieldLogger) error {
+	switch a.Op {
+	case ApplyCreate:
+		replica := spec.Replica
+		if replica == 0 {
+			replica = defaultProvisionReplica
+		}
+		_, err := provisionVolume(reqID, &ProvisionReq{
+			Name:    a.Volume,
+			Size:    a.Size,
+			Replica: replica,
+			Options: a.Options,
+		})
+		return err
+
+	case ApplyExpand:
+		vol, err := volume.GetVolume(a.Volume)
+		if err != nil {
+			return err
+		}
+		return expandProvisionedVolume(reqID, vol, a.Size)
+
+	case ApplySetOptions:
+		vol, err := volume.GetVolume(a.Volume)
+		if err != nil {
+			return err
+		}
+		failed, err := setVolumeOptions(reqID, vol, a.Options)
+		logHookFailures(logger, failed)
+		return err
+	}
+	return fmt.Errorf("unknown apply operation %s", a.Op)
+}
+
+// applyHandler brings the volumes of the cluster to the state of the given
+// spec. The volumes are diffed against the spec, and the resulting plan is
+// executed one operation at a time, stopping at the first failure. With
+// dry-run set, only the plan is returned. Applying the same spec again is a
+// no-op, so a failed apply can be retried.
+func applyHandler(w http.ResponseWriter, r *http.Request) {
+	reqID, logger := restutils.GetReqIDandLogger(r)
+
+	var spec ApplySpec
+	if err := utils.GetJSONFromRequest(r, &spec); err != nil {
+		restutils.SendHTTPError(w, http.StatusUnprocessableEntity, errors.ErrJSONParsingFailed.Error())
+		return
+	}
+
+	if errs := validateApplySpec(&spec); errs != nil {
+		restutils.SendValidationErrors(w, errs)
+		return
+	}
+
+	resp, err := planApply(&spec)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp.DryRun = r.URL.Query().Get("dry-run") == "true"
+	if resp.DryRun {
+		restutils.SendHTTPResponse(w, http.StatusOK, resp)
+		return
+	}
+
+	specs := make(map[string]*VolumeSpec, len(spec.Volumes))
+	for i := range spec.Volumes {
+		specs[spec.Volumes[i].Name] = &spec.Volumes[i]
+	}
+
+	for i := range resp.Actions {
+		a := &resp.Actions[i]
+		if err := applyAction(reqID, a, specs[a.Volume], logger); err != nil {
+			logger.WithError(err).WithFields(log.Fields{
+				"op":     a.Op,
+				"volume": a.Volume,
+			}).Error("failed to apply spec")
+			a.Error = err.Error()
+			restutils.SendHTTPResponse(w, provisionErrStatus(err), resp)
+			return
+		}
+		a.Done = true
+		logger.WithFields(log.Fields{
+			"op":     a.Op,
+			"volume": a.Volume,
+		}).Info("applied spec")
+	}
+
+	restutils.SendHTTPResponse(w, http.StatusOK, resp)
+}
@@ -0,0 +1,73 @@
+package volumecommands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gluster/glusterd2/tests"
+	"github.com/gluster/glusterd2/volume"
+)
+
+func TestDiffSpec(t *testing.T) {
+	vols := []volume.Volinfo{
+		{Name: "prov", ReplicaCount: 3, Capacity: 100, Options: map[string]string{"a": "1"}},
+		{Name: "plain", ReplicaCount: 1, Options: map[string]string{}},
+		{Name: "other", ReplicaCount: 1},
+	}
+
+	spec := &ApplySpec{Volumes: []VolumeSpec{
+		{Name: "new", Size: 50, Options: map[string]string{"b": "2"}},
+		// Only the options which differ are set
+		{Name: "prov", Size: 200, Replica: 3, Options: map[string]string{"a": "1", "c": "3"}},
+		{Name: "plain", Options: map[string]string{}},
+	}}
+	resp, err := diffSpec(spec, vols)
+	tests.Assert(t, err == nil)
+	tests.Assert(t, len(resp.Actions) == 3)
+
+	create := resp.Actions[0]
+	tests.Assert(t, create.Op == ApplyCreate && create.Volume == "new" && create.Size == 50)
+	tests.Assert(t, create.Options["b"] == "2")
+
+	expand := resp.Actions[1]
+	tests.Assert(t, expand.Op == ApplyExpand && expand.Volume == "prov" && expand.Size == 200)
+
+	set := resp.Actions[2]
+	tests.Assert(t, set.Op == ApplySetOptions && set.Volume == "prov")
+	tests.Assert(t, len(set.Options) == 1 && set.Options["c"] == "3")
+
+	tests.Assert(t, len(resp.Unmanaged) == 1 && resp.Unmanaged[0] == "other")
+
+	// A spec matching the volumes needs nothing to be done
+	spec = &ApplySpec{Volumes: []VolumeSpec{
+		{Name: "prov", Size: 100, Options: map[string]string{"a": "1"}},
+		{Name: "plain"},
+		{Name: "other"},
+	}}
+	resp, err = diffSpec(spec, vols)
+	tests.Assert(t, err == nil)
+	tests.Assert(t, len(resp.Actions) == 0)
+	tests.Assert(t, len(resp.Unmanaged) == 0)
+}
+
+func TestDiffSpecErrors(t *testing.T) {
+	vols := []volume.Volinfo{
+		{Name: "prov", ReplicaCount: 3, Capacity: 100},
+		{Name: "plain", ReplicaCount: 1},
+	}
+
+	for _, tc := range []struct {
+		spec VolumeSpec
+		err  string
+	}{
+		{VolumeSpec{Name: "new"}, "size is required"},
+		{VolumeSpec{Name: "Prov", Size: 100}, "differs only in case"},
+		{VolumeSpec{Name: "prov", Replica: 2}, "replica count cannot be changed"},
+		{VolumeSpec{Name: "prov", Size: 50}, "cannot be shrunk"},
+		{VolumeSpec{Name: "plain", Size: 50}, "not created by the provisioner"},
+	} {
+		_, err := diffSpec(&ApplySpec{Volumes: []VolumeSpec{tc.spec}}, vols)
+		tests.Assert(t, err != nil)
+		tests.Assert(t, strings.Contains(err.Error(), tc.err))
+	}
+}
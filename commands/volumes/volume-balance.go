@@ -0,0 +1,89 @@
+package volumecommands
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gluster/glusterd2/diskusage"
+	"github.com/gluster/glusterd2/errors"
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/volume"
+
+	"github.com/gorilla/mux"
+)
+
+// VolBalanceResp is the response sent for a volume balance request. Bricks
+// whose capacity hasn't been recorded yet, as happens for bricks of volumes
+// which were just started, are listed as missing and left out of the report.
+type VolBalanceResp struct {
+	diskusage.BalanceReport
+	Missing []string `json:"missing,omitempty"`
+}
+
+// volumeBalanceHandler reports how evenly the data of the volume is spread
+// over its replica sets, from the capacities recorded by the peers of the
+// bricks. The report is advisory; no rebalance is started.
+func volumeBalanceHandler(w http.ResponseWriter, r *http.Request) {
+	volname := mux.Vars(r)["volname"]
+	_, logger := restutils.GetReqIDandLogger(r)
+
+	vol, err := volume.GetVolumeCached(volname)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusNotFound, errors.ErrVolNotFound.Error())
+		return
+	}
+	if vol.Type == volume.Disperse || vol.Type == volume.DistDisperse {
+		restutils.SendHTTPError(w, http.StatusNotImplemented, "balance of disperse volumes is not supported")
+		return
+	}
+
+	threshold := diskusage.SkewThreshold()
+	if t := r.URL.Query().Get("threshold"); t != "" {
+		threshold, err = strconv.Atoi(t)
+		if err != nil || threshold < 1 || threshold > 100 {
+			restutils.SendHTTPError(w, http.StatusBadRequest, "threshold must be a percentage between 1 and 100")
+			return
+		}
+	}
+
+	caps, err := diskusage.GetCapacities(volname)
+	if err != nil {
+		logger.WithError(err).WithField("volume", volname).Error("failed to get brick capacities")
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	recorded := make(map[string]diskusage.Capacity)
+	for _, c := range caps {
+		recorded[c.NodeID.String()+":"+c.Path] = c
+	}
+
+	setSize := vol.ReplicaCount
+	if setSize < 1 {
+		setSize = 1
+	}
+
+	// The bricks of the volume are in order, so that each run of setSize
+	// bricks is a replica set
+	var resp VolBalanceResp
+	var subvols [][]diskusage.Capacity
+	for i := 0; i < len(vol.Bricks); i += setSize {
+		end := i + setSize
+		if end > len(vol.Bricks) {
+			end = len(vol.Bricks)
+		}
+
+		var set []diskusage.Capacity
+		for _, b := range vol.Bricks[i:end] {
+			c, ok := recorded[b.NodeID.String()+":"+b.Path]
+			if !ok {
+				resp.Missing = append(resp.Missing, b.Hostname+":"+b.Path)
+				continue
+			}
+			set = append(set, c)
+		}
+		subvols = append(subvols, set)
+	}
+	resp.BalanceReport = *diskusage.Balance(subvols, threshold)
+
+	restutils.SendHTTPResponse(w, http.StatusOK, resp)
+}
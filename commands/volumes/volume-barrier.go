@@ -0,0 +1,133 @@
+package volumecommands
+
+import (
+	"net/http"
+
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/gdctx"
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/transaction"
+	"github.com/gluster/glusterd2/utils"
+	"github.com/gluster/glusterd2/volume"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gorilla/mux"
+	"github.com/pborman/uuid"
+)
+
+// VolBarrierReq represents a request to enable or disable the barrier on
+// all bricks of a volume
+type VolBarrierReq struct {
+	Enable bool `json:"enable"`
+}
+
+func setBarrier(c transaction.TxnCtx, enable bool) error {
+	var volname string
+	if err := c.Get("volname", &volname); err != nil {
+		return err
+	}
+
+	vol, err := volume.GetVolume(volname)
+	if err != nil {
+		return err
+	}
+
+	for _, b := range vol.Bricks {
+		if !uuid.Equal(b.NodeID, gdctx.MyUUID) {
+			continue
+		}
+
+		c.Logger().WithFields(log.Fields{
+			"volume":  volname,
+			"brick":   b.Hostname + ":" + b.Path,
+			"barrier": enable,
+		}).Info("setting brick barrier")
+
+		if err := barrierBrick(b, enable); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func barrierBricks(c transaction.TxnCtx) error {
+	var enable bool
+	if err := c.Get("enable", &enable); err != nil {
+		return err
+	}
+	return setBarrier(c, enable)
+}
+
+func undoBarrierBricks(c transaction.TxnCtx) error {
+	var enable bool
+	if err := c.Get("enable", &enable); err != nil {
+		return err
+	}
+	return setBarrier(c, !enable)
+}
+
+func registerVolBarrierStepFuncs() {
+	transaction.RegisterStepFunc(barrierBricks, "vol-barrier.Commit")
+	transaction.RegisterStepFunc(undoBarrierBricks, "vol-barrier.Undo")
+}
+
+// volumeBarrierHandler enables or disables the barrier on all bricks of the
+// volume. While the barrier is enabled, bricks hold back write fops, which
+// allows taking crash consistent snapshots of the bricks.
+func volumeBarrierHandler(w http.ResponseWriter, r *http.Request) {
+	volname := mux.Vars(r)["volname"]
+	reqID, logger := restutils.GetReqIDandLogger(r)
+
+	var req VolBarrierReq
+	if err := utils.GetJSONFromRequest(r, &req); err != nil {
+		restutils.SendHTTPError(w, http.StatusUnprocessableEntity, errors.ErrJSONParsingFailed.Error())
+		return
+	}
+
+	vol, err := volume.GetVolume(volname)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusNotFound, errors.ErrVolNotFound.Error())
+		return
+	}
+	if vol.Status != volume.VolStarted {
+		restutils.SendHTTPError(w, http.StatusBadRequest, errors.ErrVolNotStarted.Error())
+		return
+	}
+
+	lock, unlock, err := transaction.CreateLockSteps(volname)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	txn := transaction.NewTxn(reqID)
+	defer txn.Cleanup()
+	txn.Nodes = vol.Nodes()
+	txn.Steps = []*transaction.Step{
+		lock,
+		{
+			DoFunc:   "vol-barrier.Commit",
+			UndoFunc: "vol-barrier.Undo",
+			Nodes:    txn.Nodes,
+		},
+		unlock,
+	}
+	txn.Ctx.Set("volname", volname)
+	txn.Ctx.Set("enable", req.Enable)
+
+	if _, err := txn.Do(); err != nil {
+		logger.WithError(err).WithField(
+			"volume", volname).Error("failed to set barrier on volume")
+		if err == transaction.ErrLockTimeout {
+			restutils.SendHTTPError(w, http.StatusConflict, err.Error())
+		} else if transaction.IsTimeout(err) {
+			restutils.SendHTTPError(w, http.StatusGatewayTimeout, err.Error())
+		} else {
+			restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	restutils.SendHTTPResponse(w, http.StatusOK, nil)
+}
@@ -0,0 +1,265 @@
+package volumecommands
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/hooks"
+	"github.com/gluster/glusterd2/quorum"
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/utils"
+	"github.com/gluster/glusterd2/validation"
+	"github.com/gluster/glusterd2/volume"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// Outcomes of the operation on a volume of a batch
+const (
+	BatchDone    = "done"
+	BatchSkipped = "skipped"
+	BatchFailed  = "failed"
+)
+
+// VolBatchReq represents a request to start or stop several volumes
+type VolBatchReq struct {
+	Volumes []string `json:"volumes"`
+}
+
+// VolBatchResult is the outcome of the operation on a volume of a batch.
+// Volumes already in the requested state are skipped.
+type VolBatchResult struct {
+	Volume       string         `json:"volume"`
+	Status       string         `json:"status"`
+	Error        string         `json:"error,omitempty"`
+	HookFailures []hooks.Result `json:"hook-failures,omitempty"`
+}
+
+// VolBatchResp is the response sent for a batch request. The results are in
+// the order the volumes were started or stopped in.
+type VolBatchResp struct {
+	Results []VolBatchResult `json:"results"`
+}
+
+// batchVolumes returns the volumes with the given names, in the order they
+// should be stopped in
+func batchVolumes(names []string) ([]*volume.Volinfo, validation.Errors, error) {
+	var errs validation.Errors
+	if !errs.RequireList("volumes", len(names)) {
+		return nil, errs, nil
+	}
+
+	var vols []*volume.Volinfo
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if seen[name] {
+			errs.Add("volumes", "volume %s is listed more than once", name)
+			continue
+		}
+		seen[name] = true
+
+		vol, err := volume.GetVolume(name)
+		if err != nil {
+			errs.Add("volumes", "%s: %s", name, errors.ErrVolNotFound.Error())
+			continue
+		}
+		vols = append(vols, vol)
+	}
+	if errs != nil {
+		return nil, errs, nil
+	}
+
+	vols, err := volume.StopOrder(vols)
+	return vols, nil, err
+}
+
+// startVolumes starts the volumes in the reverse of the given stop order.
+// Volumes depending on one which failed to start are not started.
+func startVolumes(reqID string, vols []*volume.Volinfo, logger log.FieldLogger) *VolBatchResp {
+	resp := &VolBatchResp{Results: []VolBatchResult{}}
+	failed := make(map[string]bool)
+
+	for i := len(vols) - 1; i >= 0; i-- {
+		vol := vols[i]
+		result := VolBatchResult{Volume: vol.Name}
+
+		var dependsOn string
+		for _, v := range vols {
+			if failed[v.Name] && utils.StringInSlice(vol.Name, volume.Dependents(v)) {
+				dependsOn = v.Name
+				break
+			}
+		}
+
+		switch {
+		case vol.Status == volume.VolStarted:
+			result.Status = BatchSkipped
+		case dependsOn != "":
+			result.Status = BatchFailed
+			result.Error = fmt.Sprintf("volume %s, which it depends on, failed to start", dependsOn)
+		default:
+			hookFailures, err := startVolume(reqID, vol)
+			logHookFailures(logger, hookFailures)
+			result.HookFailures = hookFailures
+			if err != nil {
+				logger.WithError(err).WithField("volume", vol.Name).Error("failed to start volume")
+				result.Status = BatchFailed
+				result.Error = err.Error()
+			} else {
+				result.Status = BatchDone
+			}
+		}
+
+		if result.Status == BatchFailed {
+			failed[vol.Name] = true
+		}
+		resp.Results = append(resp.Results, result)
+	}
+	return resp
+}
+
+// stopVolumes stops the volumes in the given order. Volumes on which a
+// started volume depends are not stopped.
+func stopVolumes(reqID string, vols []*volume.Volinfo, timeout time.Duration, logger log.FieldLogger) *VolBatchResp {
+	resp := &VolBatchResp{Results: []VolBatchResult{}}
+
+	for _, vol := range vols {
+		result := VolBatchResult{Volume: vol.Name}
+
+		var dependent string
+		for _, name := range volume.Dependents(vol) {
+			if dep, err := volume.GetVolume(name); err == nil && dep.Status == volume.VolStarted {
+				dependent = name
+				break
+			}
+		}
+
+		switch {
+		case vol.Status != volume.VolStarted:
+			result.Status = BatchSkipped
+		case dependent != "":
+			result.Status = BatchFailed
+			result.Error = fmt.Sprintf("volume %s depending on it is started", dependent)
+		default:
+			stopResp, err := stopVolume(reqID, vol, timeout, logger)
+			if err != nil {
+				logger.WithError(err).WithField("volume", vol.Name).Error("failed to stop volume")
+				result.Status = BatchFailed
+				result.Error = err.Error()
+			} else {
+				logHookFailures(logger, stopResp.HookFailures)
+				result.Status = BatchDone
+				result.HookFailures = stopResp.HookFailures
+			}
+		}
+		resp.Results = append(resp.Results, result)
+	}
+	return resp
+}
+
+// volumeStartBatchHandler starts the given volumes one at a time, after the
+// volumes they depend on. The outcome for each volume is reported; a volume
+// failing to start doesn't stop the others from being started.
+func volumeStartBatchHandler(w http.ResponseWriter, r *http.Request) {
+	reqID, logger := restutils.GetReqIDandLogger(r)
+
+	var req VolBatchReq
+	if err := utils.GetJSONFromRequest(r, &req); err != nil {
+		restutils.SendHTTPError(w, http.StatusUnprocessableEntity, errors.ErrJSONParsingFailed.Error())
+		return
+	}
+
+	vols, errs, err := batchVolumes(req.Volumes)
+	if errs != nil {
+		restutils.SendValidationErrors(w, errs)
+		return
+	} else if err != nil {
+		restutils.SendHTTPError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !quorum.IsMet() {
+		restutils.SendHTTPError(w, http.StatusServiceUnavailable, errors.ErrQuorumNotMet.Error())
+		return
+	}
+
+	restutils.SendHTTPResponse(w, http.StatusOK, startVolumes(reqID, vols, logger))
+}
+
+// volumeStopBatchHandler stops the given volumes one at a time, before the
+// volumes they depend on. The outcome for each volume is reported.
+func volumeStopBatchHandler(w http.ResponseWriter, r *http.Request) {
+	reqID, logger := restutils.GetReqIDandLogger(r)
+
+	var req VolBatchReq
+	if err := utils.GetJSONFromRequest(r, &req); err != nil {
+		restutils.SendHTTPError(w, http.StatusUnprocessableEntity, errors.ErrJSONParsingFailed.Error())
+		return
+	}
+
+	timeout, err := stopTimeout(r)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	vols, errs, err := batchVolumes(req.Volumes)
+	if errs != nil {
+		restutils.SendValidationErrors(w, errs)
+		return
+	} else if err != nil {
+		restutils.SendHTTPError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	restutils.SendHTTPResponse(w, http.StatusOK, stopVolumes(reqID, vols, timeout, logger))
+}
+
+// volumeStopAllHandler stops all the started volumes of the cluster, for
+// maintenance of the cluster. Volumes are stopped before the volumes they
+// depend on.
+func volumeStopAllHandler(w http.ResponseWriter, r *http.Request) {
+	reqID, logger := restutils.GetReqIDandLogger(r)
+
+	timeout, err := stopTimeout(r)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	volumes, err := volume.GetVolumes()
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var names []string
+	for _, v := range volumes {
+		if v.Status == volume.VolStarted {
+			names = append(names, v.Name)
+		}
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		restutils.SendHTTPResponse(w, http.StatusOK, &VolBatchResp{Results: []VolBatchResult{}})
+		return
+	}
+
+	// Volumes deleted since they were listed fail the request, which can
+	// be retried
+	vols, errs, err := batchVolumes(names)
+	if errs != nil {
+		restutils.SendValidationErrors(w, errs)
+		return
+	} else if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp := stopVolumes(reqID, vols, timeout, logger)
+	logger.WithField("volumes", len(resp.Results)).Info("stopped all volumes")
+	restutils.SendHTTPResponse(w, http.StatusOK, resp)
+}
@@ -0,0 +1,373 @@
+package volumecommands
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/gdctx"
+	"github.com/gluster/glusterd2/peer"
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/store"
+	"github.com/gluster/glusterd2/transaction"
+	"github.com/gluster/glusterd2/volgen"
+	"github.com/gluster/glusterd2/volume"
+	"github.com/gluster/glusterd2/xlator"
+
+	"github.com/gorilla/mux"
+	"github.com/pborman/uuid"
+)
+
+const volCheckTxnKey = "volcheck"
+
+// PeerVolCheck is the result of the consistency check of a volume on a peer.
+// Checksum is the checksum of the configuration of the volume as seen by the
+// peer. Problems lists the ways the peer has drifted from the configuration
+// in the store.
+type PeerVolCheck struct {
+	PeerID   uuid.UUID `json:"peer-id"`
+	Online   bool      `json:"online"`
+	Checksum string    `json:"checksum,omitempty"`
+	Problems []string  `json:"problems,omitempty"`
+}
+
+// VolCheckResp is the response sent for a volume consistency check. Checksum
+// is the checksum of the configuration of the volume in the store, which all
+// the peers are expected to see. Offline peers are not checked.
+type VolCheckResp struct {
+	Volume     string         `json:"volume"`
+	Checksum   string         `json:"checksum"`
+	Consistent bool           `json:"consistent"`
+	Peers      []PeerVolCheck `json:"peers"`
+}
+
+// volumeConfig is the configuration of a volume which all the peers are
+// expected to see: its volinfo and its client volfile. The parts missing on a
+// peer are nil.
+type volumeConfig struct {
+	volinfo *volume.Volinfo
+	volfile []byte
+}
+
+// checksum returns the checksum of the configuration. The volinfo is encoded
+// as JSON, which sorts the keys of maps, so the order of the options of the
+// volume doesn't change the checksum.
+func (c *volumeConfig) checksum() (string, error) {
+	h := sha256.New()
+	if c.volinfo != nil {
+		volinfo, err := json.Marshal(c.volinfo)
+		if err != nil {
+			return "", err
+		}
+		h.Write(volinfo)
+	}
+	h.Write(c.volfile)
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// configDrift returns the ways the configuration of the volume seen by a peer
+// has drifted from the expected one
+func configDrift(expected, seen *volumeConfig) ([]string, error) {
+	var problems []string
+
+	if seen.volinfo == nil {
+		problems = append(problems, "volume is missing from the volume cache")
+	} else {
+		volinfo, err := json.Marshal(expected.volinfo)
+		if err != nil {
+			return nil, err
+		}
+		seenVolinfo, err := json.Marshal(seen.volinfo)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(seenVolinfo, volinfo) {
+			problems = append(problems, "cached volume information is stale")
+		}
+	}
+
+	if seen.volfile == nil {
+		problems = append(problems, "client volfile is missing")
+	} else if !bytes.Equal(seen.volfile, expected.volfile) {
+		problems = append(problems, "client volfile is stale")
+	}
+	return problems, nil
+}
+
+// expectedConfig returns the configuration of the volume as it should be seen
+// by all the peers
+func expectedConfig(volname string) (*volumeConfig, error) {
+	vol, err := volume.GetVolume(volname)
+	if err != nil {
+		return nil, err
+	}
+	volfile, err := volgen.ClientVolfile(vol)
+	if err != nil {
+		return nil, err
+	}
+	return &volumeConfig{volinfo: vol, volfile: volfile}, nil
+}
+
+// checkVolume compares the configuration of the volume seen by this peer, from
+// its caches and local brick volfiles, with the configuration in the store
+func checkVolume(c transaction.TxnCtx) error {
+	var volname string
+	if err := c.Get("volname", &volname); err != nil {
+		return err
+	}
+
+	expected, err := expectedConfig(volname)
+	if err != nil {
+		return err
+	}
+	vol := expected.volinfo
+
+	var seen volumeConfig
+	if cached, err := volume.GetVolumeCached(volname); err == nil {
+		seen.volinfo = cached
+	}
+	volfile, ok, err := volgen.GetClientVolfile(volname)
+	if err != nil {
+		return err
+	}
+	if ok {
+		// An empty volfile is stale rather than missing
+		seen.volfile = append([]byte{}, volfile...)
+	}
+
+	result := PeerVolCheck{PeerID: gdctx.MyUUID, Online: true}
+	if result.Problems, err = configDrift(expected, &seen); err != nil {
+		return err
+	}
+	if result.Checksum, err = seen.checksum(); err != nil {
+		return err
+	}
+
+	for i := range vol.Bricks {
+		b := &vol.Bricks[i]
+		if !uuid.Equal(b.NodeID, gdctx.MyUUID) {
+			continue
+		}
+		expected, err := volgen.BrickVolfile(vol, b)
+		if err != nil {
+			return err
+		}
+		data, err := ioutil.ReadFile(volgen.BrickVolfilePath(b))
+		if os.IsNotExist(err) {
+			result.Problems = append(result.Problems, fmt.Sprintf("brick volfile of %s is missing", b.Path))
+		} else if err != nil {
+			return err
+		} else if !bytes.Equal(data, expected) {
+			result.Problems = append(result.Problems, fmt.Sprintf("brick volfile of %s is stale", b.Path))
+		}
+	}
+
+	// The options can't be checked if the xlators couldn't be loaded
+	if xlator.AllOptions != nil {
+		for k, v := range vol.Options {
+			if areOptionNamesValid(map[string]string{k: v}) != nil {
+				result.Problems = append(result.Problems, fmt.Sprintf("option %s is not supported by the xlators of this peer", k))
+			}
+		}
+	}
+
+	return c.SetNodeResult(gdctx.MyUUID, volCheckTxnKey, result)
+}
+
+// resyncVolume drops the caches of this peer, so that they are loaded from the
+// store again, and regenerates the volfiles of the local bricks of the volume
+func resyncVolume(c transaction.TxnCtx) error {
+	volume.InvalidateCache()
+	volgen.InvalidateVolfileCache()
+	return generateBrickVolfiles(c)
+}
+
+func registerVolCheckStepFuncs() {
+	var sfs = []struct {
+		name string
+		sf   transaction.StepFunc
+	}{
+		{"vol-check.Check", checkVolume},
+		{"vol-check.StoreVolfile", storeVolume},
+		{"vol-check.Resync", resyncVolume},
+		{"vol-check.Notify", notifyVolfileChange},
+	}
+	for _, sf := range sfs {
+		transaction.RegisterStepFunc(sf.sf, sf.name)
+	}
+}
+
+// onlinePeers splits the peers of the cluster into the online and the offline
+// ones
+func onlinePeers() ([]uuid.UUID, []uuid.UUID, error) {
+	ids, err := peer.GetPeerIDs()
+	if err != nil {
+		return nil, nil, err
+	}
+	var online, offline []uuid.UUID
+	for _, id := range ids {
+		if store.Store.IsNodeAlive(id) {
+			online = append(online, id)
+		} else {
+			offline = append(offline, id)
+		}
+	}
+	return online, offline, nil
+}
+
+// checkVolumeConsistency checks the configuration of the volume on all the
+// online peers
+func checkVolumeConsistency(reqID, volname string) (*VolCheckResp, error) {
+	expected, err := expectedConfig(volname)
+	if err != nil {
+		return nil, err
+	}
+	checksum, err := expected.checksum()
+	if err != nil {
+		return nil, err
+	}
+
+	online, offline, err := onlinePeers()
+	if err != nil {
+		return nil, err
+	}
+
+	// Checking the volume does not modify any state, so no locks are
+	// needed
+	txn := transaction.NewTxn(reqID)
+	defer txn.Cleanup()
+	txn.Nodes = online
+	txn.Steps = []*transaction.Step{
+		{
+			DoFunc: "vol-check.Check",
+			Nodes:  txn.Nodes,
+		},
+	}
+	txn.Ctx.Set("volname", volname)
+
+	rtxn, err := txn.Do()
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &VolCheckResp{
+		Volume:     volname,
+		Checksum:   checksum,
+		Consistent: true,
+		Peers:      []PeerVolCheck{},
+	}
+	for _, node := range txn.Nodes {
+		var result PeerVolCheck
+		if err := rtxn.GetNodeResult(node, volCheckTxnKey, &result); err != nil {
+			return nil, err
+		}
+		if result.Checksum != resp.Checksum || len(result.Problems) != 0 {
+			resp.Consistent = false
+		}
+		resp.Peers = append(resp.Peers, result)
+	}
+	for _, node := range offline {
+		resp.Peers = append(resp.Peers, PeerVolCheck{PeerID: node})
+	}
+	return resp, nil
+}
+
+// volumeCheckHandler reports the peers whose configuration of the volume has
+// drifted from the configuration in the store, such as peers with stale
+// volfiles or without support for some of the options of the volume
+func volumeCheckHandler(w http.ResponseWriter, r *http.Request) {
+	volname := mux.Vars(r)["volname"]
+	reqID, logger := restutils.GetReqIDandLogger(r)
+
+	if !volume.Exists(volname) {
+		restutils.SendHTTPError(w, http.StatusNotFound, errors.ErrVolNotFound.Error())
+		return
+	}
+
+	resp, err := checkVolumeConsistency(reqID, volname)
+	if err != nil {
+		logger.WithError(err).WithField("volume", volname).Error("failed to check volume consistency")
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	restutils.SendHTTPResponse(w, http.StatusOK, resp)
+}
+
+// volumeResyncHandler repairs the configuration of the volume on all the online
+// peers: the client volfile is regenerated from the volinfo in the store, the
+// caches of the peers are reloaded, the brick volfiles are regenerated and
+// clients are notified to fetch the volfiles again. The volume is checked
+// again once resynced.
+func volumeResyncHandler(w http.ResponseWriter, r *http.Request) {
+	volname := mux.Vars(r)["volname"]
+	reqID, logger := restutils.GetReqIDandLogger(r)
+	logger = logger.WithField("volume", volname)
+
+	vol, err := volume.GetVolume(volname)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusNotFound, errors.ErrVolNotFound.Error())
+		return
+	}
+
+	online, _, err := onlinePeers()
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	lock, unlock, err := transaction.CreateLockSteps(volname)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	txn := transaction.NewTxn(reqID)
+	defer txn.Cleanup()
+	txn.Nodes = online
+	txn.Steps = []*transaction.Step{
+		lock,
+		{
+			DoFunc: "vol-check.StoreVolfile",
+			Nodes:  []uuid.UUID{gdctx.MyUUID},
+		},
+		{
+			DoFunc: "vol-check.Resync",
+			Nodes:  txn.Nodes,
+		},
+		{
+			DoFunc: "vol-check.Notify",
+			Nodes:  txn.Nodes,
+		},
+		unlock,
+	}
+	txn.Ctx.Set("volinfo", vol)
+
+	if _, err := txn.Do(); err != nil {
+		logger.WithError(err).Error("failed to resync volume")
+		if err == transaction.ErrLockTimeout {
+			restutils.SendHTTPError(w, http.StatusConflict, err.Error())
+		} else if transaction.IsTimeout(err) {
+			restutils.SendHTTPError(w, http.StatusGatewayTimeout, err.Error())
+		} else {
+			restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	logger.WithField("peers", len(online)).Info("resynced volume")
+
+	resp, err := checkVolumeConsistency(reqID, volname)
+	if err != nil {
+		logger.WithError(err).Error("failed to check volume consistency")
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !resp.Consistent {
+		logger.WithField("peers", len(resp.Peers)).Warn("volume is still inconsistent after resync")
+	}
+	restutils.SendHTTPResponse(w, http.StatusOK, resp)
+}
@@ -0,0 +1,83 @@
+package volumecommands
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gluster/glusterd2/tests"
+	"github.com/gluster/glusterd2/volume"
+)
+
+// testVolinfo returns a volinfo with the given options, added to its options
+// map in the given order
+func testVolinfo(opts ...string) *volume.Volinfo {
+	v := &volume.Volinfo{Name: "vol1", Options: make(map[string]string)}
+	for i := 0; i+1 < len(opts); i += 2 {
+		v.Options[opts[i]] = opts[i+1]
+	}
+	return v
+}
+
+func TestConfigChecksum(t *testing.T) {
+	var opts, reversed []string
+	for i := 0; i < 50; i++ {
+		opts = append(opts, fmt.Sprintf("opt%d", i), fmt.Sprintf("%d", i))
+	}
+	for i := len(opts) - 2; i >= 0; i -= 2 {
+		reversed = append(reversed, opts[i], opts[i+1])
+	}
+
+	// The order of the options doesn't change the checksum
+	c1 := &volumeConfig{volinfo: testVolinfo(opts...), volfile: []byte("volfile")}
+	c2 := &volumeConfig{volinfo: testVolinfo(reversed...), volfile: []byte("volfile")}
+	sum1, err := c1.checksum()
+	tests.Assert(t, err == nil)
+	for i := 0; i < 10; i++ {
+		sum2, err := c2.checksum()
+		tests.Assert(t, err == nil)
+		tests.Assert(t, sum1 == sum2)
+	}
+
+	// Changes of the options or of the volfile do
+	c2.volinfo.Options["opt0"] = "changed"
+	sum2, err := c2.checksum()
+	tests.Assert(t, err == nil && sum1 != sum2)
+
+	c2 = &volumeConfig{volinfo: testVolinfo(opts...), volfile: []byte("changed")}
+	sum2, err = c2.checksum()
+	tests.Assert(t, err == nil && sum1 != sum2)
+}
+
+func TestConfigDrift(t *testing.T) {
+	expected := &volumeConfig{
+		volinfo: testVolinfo("a", "1", "b", "2"),
+		volfile: []byte("volfile"),
+	}
+
+	for _, tc := range []struct {
+		seen     volumeConfig
+		problems []string
+	}{
+		{volumeConfig{testVolinfo("b", "2", "a", "1"), []byte("volfile")}, nil},
+		{volumeConfig{testVolinfo("a", "1", "b", "3"), []byte("volfile")}, []string{"cached volume information is stale"}},
+		{volumeConfig{testVolinfo("a", "1"), []byte("volfile")}, []string{"cached volume information is stale"}},
+		{volumeConfig{testVolinfo("a", "1", "b", "2", "c", "3"), []byte("volfile")}, []string{"cached volume information is stale"}},
+		{volumeConfig{testVolinfo("a", "1", "b", "2"), []byte("changed")}, []string{"client volfile is stale"}},
+		{volumeConfig{testVolinfo("a", "1", "b", "2"), []byte{}}, []string{"client volfile is stale"}},
+		{volumeConfig{nil, []byte("volfile")}, []string{"volume is missing from the volume cache"}},
+		{volumeConfig{testVolinfo("a", "1", "b", "2"), nil}, []string{"client volfile is missing"}},
+		{volumeConfig{testVolinfo("a", "2", "b", "2"), []byte("changed")}, []string{"cached volume information is stale", "client volfile is stale"}},
+	} {
+		problems, err := configDrift(expected, &tc.seen)
+		tests.Assert(t, err == nil)
+		tests.Assert(t, strings.Join(problems, ",") == strings.Join(tc.problems, ","))
+
+		// Peers reporting problems don't have the expected checksum
+		sum, err := expected.checksum()
+		tests.Assert(t, err == nil)
+		seenSum, err := tc.seen.checksum()
+		tests.Assert(t, err == nil)
+		tests.Assert(t, (sum == seenSum) == (len(problems) == 0))
+	}
+}
@@ -0,0 +1,159 @@
+package volumecommands
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gluster/glusterd2/brick"
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/gdctx"
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/transaction"
+	"github.com/gluster/glusterd2/volume"
+
+	"github.com/gorilla/mux"
+	"github.com/pborman/uuid"
+)
+
+const (
+	brickClientsTxnKey string = "brickclients"
+
+	// statusClients is GF_CLI_STATUS_CLIENTS, which asks the brick
+	// process to list the clients connected to it
+	statusClients = 0x000002
+)
+
+// ClientInfo represents a client connected to a brick
+type ClientInfo struct {
+	Hostname     string `json:"hostname"`
+	Name         string `json:"name,omitempty"`
+	BytesRead    uint64 `json:"bytes-read"`
+	BytesWritten uint64 `json:"bytes-written"`
+	OpVersion    int    `json:"op-version"`
+}
+
+// BrickClients represents the list of clients connected to a brick
+type BrickClients struct {
+	Brick   string       `json:"brick"`
+	NodeID  uuid.UUID    `json:"node-id"`
+	Online  bool         `json:"online"`
+	Clients []ClientInfo `json:"clients"`
+}
+
+// getBrickClients queries the brick process for the clients connected to it
+func getBrickClients(vol *volume.Volinfo, b brick.Brickinfo) ([]ClientInfo, error) {
+	output, err := brickOp(b, brick.OpBrickStatus, map[string]string{
+		"cmd":        strconv.Itoa(statusClients),
+		"brick-name": b.Path,
+		"vol-name":   vol.Name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	count, _ := strconv.Atoi(output["clientcount"])
+	clients := make([]ClientInfo, 0, count)
+	for i := 0; i < count; i++ {
+		prefix := fmt.Sprintf("client%d.", i)
+		c := ClientInfo{
+			Hostname: output[prefix+"hostname"],
+			Name:     output[prefix+"name"],
+		}
+		c.BytesRead, _ = strconv.ParseUint(output[prefix+"bytesread"], 10, 64)
+		c.BytesWritten, _ = strconv.ParseUint(output[prefix+"byteswrite"], 10, 64)
+		c.OpVersion, _ = strconv.Atoi(output[prefix+"opversion"])
+		clients = append(clients, c)
+	}
+
+	return clients, nil
+}
+
+func getClients(c transaction.TxnCtx) error {
+	var volname string
+	if err := c.Get("volname", &volname); err != nil {
+		return err
+	}
+
+	vol, err := volume.GetVolume(volname)
+	if err != nil {
+		return err
+	}
+
+	var result []BrickClients
+	for _, b := range vol.Bricks {
+		if !uuid.Equal(b.NodeID, gdctx.MyUUID) {
+			continue
+		}
+
+		bc := BrickClients{
+			Brick:  b.Hostname + ":" + b.Path,
+			NodeID: b.NodeID,
+		}
+		clients, err := getBrickClients(vol, b)
+		if err != nil {
+			c.Logger().WithError(err).WithField(
+				"brick", bc.Brick).Warn("failed to get clients of brick")
+		} else {
+			bc.Online = true
+			bc.Clients = clients
+		}
+		result = append(result, bc)
+	}
+
+	return c.SetNodeResult(gdctx.MyUUID, brickClientsTxnKey, result)
+}
+
+func registerVolClientsStepFuncs() {
+	transaction.RegisterStepFunc(getClients, "vol-clients.Get")
+}
+
+func volumeClientsHandler(w http.ResponseWriter, r *http.Request) {
+	volname := mux.Vars(r)["volname"]
+	reqID, logger := restutils.GetReqIDandLogger(r)
+
+	vol, err := volume.GetVolume(volname)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusNotFound, errors.ErrVolNotFound.Error())
+		return
+	}
+	if vol.Status != volume.VolStarted {
+		restutils.SendHTTPError(w, http.StatusBadRequest, errors.ErrVolNotStarted.Error())
+		return
+	}
+
+	// Listing clients does not modify any state, so no locks are needed.
+	txn := transaction.NewTxn(reqID)
+	defer txn.Cleanup()
+	txn.Nodes = vol.Nodes()
+	txn.Steps = []*transaction.Step{
+		{
+			DoFunc: "vol-clients.Get",
+			Nodes:  txn.Nodes,
+		},
+	}
+	if err := txn.Ctx.Set("volname", volname); err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	rtxn, err := txn.Do()
+	if err != nil {
+		logger.WithError(err).WithField(
+			"volume", volname).Error("failed to get volume clients")
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var result []BrickClients
+	for _, node := range txn.Nodes {
+		var tmp []BrickClients
+		if err := rtxn.GetNodeResult(node, brickClientsTxnKey, &tmp); err != nil {
+			restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		result = append(result, tmp...)
+	}
+
+	restutils.SendHTTPResponse(w, http.StatusOK, result)
+}
@@ -2,18 +2,24 @@ package volumecommands
 
 import (
 	"errors"
-	"fmt"
 	"net/http"
 
+	"github.com/gluster/glusterd2/brick"
 	gderrors "github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/gdctx"
+	"github.com/gluster/glusterd2/hooks"
+	"github.com/gluster/glusterd2/peer"
 	restutils "github.com/gluster/glusterd2/servers/rest/utils"
 	"github.com/gluster/glusterd2/transaction"
 	"github.com/gluster/glusterd2/utils"
+	"github.com/gluster/glusterd2/validation"
 	"github.com/gluster/glusterd2/volume"
 
 	"github.com/pborman/uuid"
 )
 
+const brickWarningsTxnKey string = "brickwarnings"
+
 // VolCreateRequest defines the parameters for creating a volume in the volume-create command
 type VolCreateRequest struct {
 	Name         string            `json:"name"`
@@ -24,6 +30,12 @@ type VolCreateRequest struct {
 	Options      map[string]string `json:"options,omitempty"`
 	// Bricks list is ordered (like in glusterd1) and decides which bricks
 	// form replica sets.
+
+	// PeerMetaData restricts the bricks to the peers having all the given
+	// metadata, and SpreadBy names a metadata key, like zone or rack, whose
+	// value must differ between the peers of the bricks of a replica set.
+	PeerMetaData map[string]string `json:"peer-metadata,omitempty"`
+	SpreadBy     string            `json:"spread-by,omitempty"`
 }
 
 func unmarshalVolCreateRequest(msg *VolCreateRequest, r *http.Request) (int, error) {
@@ -41,6 +53,56 @@ func unmarshalVolCreateRequest(msg *VolCreateRequest, r *http.Request) (int, err
 
 }
 
+func validateVolCreateRequest(req *VolCreateRequest) validation.Errors {
+	var errs validation.Errors
+	errs.VolumeName("name", req.Name)
+	errs.Bricks("bricks", req.Bricks)
+	errs.ReplicaCount("replica", req.ReplicaCount, len(req.Bricks))
+	if req.Transport != "" {
+		errs.OneOf("transport", req.Transport, "tcp", "rdma")
+	}
+	return errs
+}
+
+// validateBrickPeers checks that the bricks are on peers matching the
+// placement constraints of the request
+func validateBrickPeers(req *VolCreateRequest, bricks []brick.Brickinfo, replicaCount int, peers []peer.Peer) validation.Errors {
+	var errs validation.Errors
+
+	byID := make(map[string]*peer.Peer, len(peers))
+	for i := range peers {
+		byID[peers[i].ID.String()] = &peers[i]
+	}
+
+	for _, b := range bricks {
+		p, ok := byID[b.NodeID.String()]
+		if !ok || !p.MatchesMetaData(req.PeerMetaData) {
+			errs.Add("bricks", "brick %s:%s is not on a peer with the requested metadata", b.Hostname, b.Path)
+		}
+	}
+
+	if req.SpreadBy == "" || replicaCount <= 1 {
+		return errs
+	}
+	for i := 0; i+replicaCount <= len(bricks); i += replicaCount {
+		used := make(map[string]bool)
+		for _, b := range bricks[i : i+replicaCount] {
+			var domain string
+			if p, ok := byID[b.NodeID.String()]; ok {
+				domain = p.MetaData[req.SpreadBy]
+			}
+			switch {
+			case domain == "":
+				errs.Add("bricks", "brick %s:%s is on a peer without the %s metadata", b.Hostname, b.Path, req.SpreadBy)
+			case used[domain]:
+				errs.Add("bricks", "brick %s:%s is in %s %s with another brick of its replica set", b.Hostname, b.Path, req.SpreadBy, domain)
+			}
+			used[domain] = true
+		}
+	}
+	return errs
+}
+
 func createVolinfo(req *VolCreateRequest) (*volume.Volinfo, error) {
 
 	var err error
@@ -119,7 +181,8 @@ func validateVolumeCreate(c transaction.TxnCtx) error {
 		return err
 	}
 
-	return nil
+	warnings := volume.BrickFSWarnings(volinfo.Bricks, req.Force)
+	return c.SetNodeResult(gdctx.MyUUID, brickWarningsTxnKey, warnings)
 }
 
 func rollBackVolumeCreate(c transaction.TxnCtx) error {
@@ -159,10 +222,21 @@ func volumeCreateHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if errs := validateVolCreateRequest(req); errs != nil {
+		logger.WithError(errs).Error("invalid volume create request")
+		restutils.SendValidationErrors(w, errs)
+		return
+	}
+
 	if volume.ExistsFunc(req.Name) {
 		restutils.SendHTTPError(w, http.StatusInternalServerError, gderrors.ErrVolExists.Error())
 		return
 	}
+	if existing, ok := volume.ExistingName(req.Name); ok {
+		logger.WithField("existing", existing).Error(gderrors.ErrVolNameConflict.Error())
+		restutils.SendHTTPError(w, http.StatusConflict, gderrors.ErrVolNameConflict.Error())
+		return
+	}
 
 	nodes, err := nodesFromBricks(req.Bricks)
 	if err != nil {
@@ -171,37 +245,82 @@ func volumeCreateHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := areOptionNamesValid(req.Options); err != nil {
-		logger.WithField("option", err.Error()).Error("invalid option specified")
-		restutils.SendHTTPError(w, http.StatusBadRequest, fmt.Sprintf("invalid option specified: %s", err.Error()))
+	if !validateOptions(w, logger, req.Options) {
 		return
 	}
 
-	txn, err := (&transaction.SimpleTxn{
-		Nodes:    nodes,
-		LockKey:  req.Name,
-		Stage:    "vol-create.Stage",
-		Commit:   "vol-create.Commit",
-		Store:    "vol-create.Store",
-		Rollback: "vol-create.Rollback",
-	}).NewTxn(reqID)
+	vol, err := createVolinfo(req)
 	if err != nil {
-		logger.WithError(err).Error("failed to create transaction")
+		logger.WithError(err).Error("failed to create volinfo")
 		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	defer txn.Cleanup()
 
-	err = txn.Ctx.Set("req", req)
+	if len(req.PeerMetaData) != 0 || req.SpreadBy != "" {
+		peers, err := peer.GetPeersF()
+		if err != nil {
+			restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if errs := validateBrickPeers(req, vol.Bricks, vol.ReplicaCount, peers); errs != nil {
+			logger.WithError(errs).Error("bricks do not match the placement constraints")
+			restutils.SendValidationErrors(w, errs)
+			return
+		}
+	}
+
+	if isDryRun(r) {
+		resp, err := dryRunBricks(reqID, nodes, vol.Bricks, req.Force)
+		if err != nil {
+			logger.WithError(err).Error("volume create dry-run failed")
+			restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		resp.Warnings = append(resp.Warnings, replicaSetWarnings(vol.Bricks, vol.ReplicaCount)...)
+		restutils.SendHTTPResponse(w, http.StatusOK, resp)
+		return
+	}
+
+	lock, unlock, err := transaction.CreateLockSteps(req.Name)
 	if err != nil {
-		logger.WithError(err).Error("failed to set request in transaction context")
 		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	vol, err := createVolinfo(req)
+	txn := transaction.NewTxn(reqID)
+	defer txn.Cleanup()
+	txn.Nodes = nodes
+
+	// The hooks are run within the volume lock, around the create steps
+	txn.Steps = []*transaction.Step{
+		lock,
+		hooks.PreStep(nodes),
+		{
+			DoFunc: "vol-create.Stage",
+			Nodes:  nodes,
+		},
+		{
+			DoFunc:   "vol-create.Commit",
+			UndoFunc: "vol-create.Rollback",
+			Nodes:    nodes,
+		},
+		{
+			DoFunc: "vol-create.Store",
+			Nodes:  []uuid.UUID{gdctx.MyUUID},
+		},
+		hooks.PostStep(nodes),
+		unlock,
+	}
+
+	if err := hooks.SetTxnCtx(txn.Ctx, hooks.OpCreate, hookEnv(vol)); err != nil {
+		logger.WithError(err).Error("failed to set hook environment in transaction context")
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	err = txn.Ctx.Set("req", req)
 	if err != nil {
-		logger.WithError(err).Error("failed to create volinfo")
+		logger.WithError(err).Error("failed to set request in transaction context")
 		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -218,6 +337,8 @@ func volumeCreateHandler(w http.ResponseWriter, r *http.Request) {
 		logger.WithError(err).Error("volume create transaction failed")
 		if err == transaction.ErrLockTimeout {
 			restutils.SendHTTPError(w, http.StatusConflict, err.Error())
+		} else if transaction.IsTimeout(err) {
+			restutils.SendHTTPError(w, http.StatusGatewayTimeout, err.Error())
 		} else {
 			restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
 		}
@@ -229,6 +350,21 @@ func volumeCreateHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	failed := hooks.PostFailures(c, nodes)
+	logHookFailures(logger, failed)
+
+	// Warnings about the bricks are only reported, the volume is created
+	// regardless
+	resp := VolumeResp{Volinfo: vol, HookFailures: failed}
+	for _, node := range nodes {
+		var warnings []string
+		if err := c.GetNodeResult(node, brickWarningsTxnKey, &warnings); err != nil {
+			logger.WithError(err).WithField("node", node.String()).Warn("failed to get brick warnings of node")
+			continue
+		}
+		resp.Warnings = append(resp.Warnings, warnings...)
+	}
+
 	c.Logger().WithField("volname", vol.Name).Info("new volume created")
-	restutils.SendHTTPResponse(w, http.StatusCreated, vol)
+	restutils.SendHTTPResponse(w, http.StatusCreated, resp)
 }
@@ -99,3 +99,47 @@ func TestValidateVolumeCreate(t *testing.T) {
 	e = validateVolumeCreate(c)
 	tests.Assert(t, e == errBad)
 }
+
+// TestValidateBrickPeers validates validateBrickPeers()
+func TestValidateBrickPeers(t *testing.T) {
+	n1, n2, n3 := uuid.NewRandom(), uuid.NewRandom(), uuid.NewRandom()
+	peers := []peer.Peer{
+		{ID: n1, MetaData: map[string]string{"zone": "z1", "role": "storage"}},
+		{ID: n2, MetaData: map[string]string{"zone": "z1", "role": "storage"}},
+		{ID: n3, MetaData: map[string]string{"zone": "z2"}},
+	}
+	bricks := []brick.Brickinfo{
+		{NodeID: n1, Path: "/b1"},
+		{NodeID: n3, Path: "/b2"},
+		{NodeID: n1, Path: "/b3"},
+		{NodeID: n2, Path: "/b4"},
+	}
+
+	// No constraints
+	req := &VolCreateRequest{}
+	tests.Assert(t, validateBrickPeers(req, bricks, 2, peers) == nil)
+
+	// Brick on a peer without the metadata
+	req = &VolCreateRequest{PeerMetaData: map[string]string{"role": "storage"}}
+	errs := validateBrickPeers(req, bricks, 2, peers)
+	tests.Assert(t, len(errs) == 1 && errs[0].Field == "bricks")
+
+	// Second replica set is in a single zone
+	req = &VolCreateRequest{SpreadBy: "zone"}
+	errs = validateBrickPeers(req, bricks, 2, peers)
+	tests.Assert(t, len(errs) == 1)
+
+	// Zones only apply within a replica set
+	tests.Assert(t, validateBrickPeers(req, bricks[:2], 2, peers) == nil)
+	tests.Assert(t, validateBrickPeers(req, bricks, 1, peers) == nil)
+
+	// Peers without the key can't be told apart
+	req = &VolCreateRequest{SpreadBy: "rack"}
+	errs = validateBrickPeers(req, bricks[:2], 2, peers)
+	tests.Assert(t, len(errs) == 2)
+
+	// Brick on an unknown peer
+	req = &VolCreateRequest{PeerMetaData: map[string]string{}}
+	unknown := []brick.Brickinfo{{NodeID: uuid.NewRandom(), Path: "/b5"}}
+	tests.Assert(t, len(validateBrickPeers(req, unknown, 1, peers)) == 1)
+}
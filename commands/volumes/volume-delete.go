@@ -3,8 +3,10 @@ package volumecommands
 import (
 	"net/http"
 
+	"github.com/gluster/glusterd2/errors"
 	"github.com/gluster/glusterd2/gdctx"
 	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/snapshot"
 	"github.com/gluster/glusterd2/transaction"
 	"github.com/gluster/glusterd2/volgen"
 	"github.com/gluster/glusterd2/volume"
@@ -53,6 +55,11 @@ func deleteVolume(c transaction.TxnCtx) error {
 		return err
 	}
 
+	// The snapshot schedule of the volume goes with it
+	if err := snapshot.DeleteSchedule(volname); err != nil {
+		return err
+	}
+
 	return volume.DeleteVolume(volname)
 }
 
@@ -85,6 +92,24 @@ func volumeDeleteHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	snaps, err := snapshot.GetSnapshots(volname)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if len(snaps) != 0 {
+		restutils.SendHTTPError(w, http.StatusConflict, errors.ErrVolHasSnapshots.Error())
+		return
+	}
+
+	// The bricks of volumes cloned from snapshots are on logical volumes
+	// of their own, which are removed along with the volume
+	clone, err := snapshot.GetClone(volname)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
 	txn := transaction.NewTxn(reqID)
 	defer txn.Cleanup()
 	lock, unlock, err := transaction.CreateLockSteps(volname)
@@ -99,12 +124,28 @@ func volumeDeleteHandler(w http.ResponseWriter, r *http.Request) {
 			DoFunc: "vol-delete.Commit",
 			Nodes:  txn.Nodes,
 		},
-		{
-			DoFunc: "vol-delete.Store",
+	}
+	if clone != nil {
+		txn.Steps = append(txn.Steps, &transaction.Step{
+			DoFunc: "snap-clone.Remove",
+			Nodes:  txn.Nodes,
+		})
+	}
+	txn.Steps = append(txn.Steps, &transaction.Step{
+		DoFunc: "vol-delete.Store",
+		Nodes:  []uuid.UUID{gdctx.MyUUID},
+	})
+	if clone != nil {
+		txn.Steps = append(txn.Steps, &transaction.Step{
+			DoFunc: "snap-clone.Unstore",
 			Nodes:  []uuid.UUID{gdctx.MyUUID},
-		},
-		unlock,
+		})
+		if err := txn.Ctx.Set("clone", clone); err != nil {
+			restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
 	}
+	txn.Steps = append(txn.Steps, unlock)
 
 	txn.Ctx.Set("volname", volname)
 	if _, err = txn.Do(); err != nil {
@@ -112,6 +153,8 @@ func volumeDeleteHandler(w http.ResponseWriter, r *http.Request) {
 			"volume", volname).Error("failed to delete the volume")
 		if err == transaction.ErrLockTimeout {
 			restutils.SendHTTPError(w, http.StatusConflict, err.Error())
+		} else if transaction.IsTimeout(err) {
+			restutils.SendHTTPError(w, http.StatusGatewayTimeout, err.Error())
 		} else {
 			restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
 		}
@@ -0,0 +1,122 @@
+package volumecommands
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gluster/glusterd2/brick"
+	"github.com/gluster/glusterd2/gdctx"
+	"github.com/gluster/glusterd2/transaction"
+	"github.com/gluster/glusterd2/volume"
+
+	"github.com/pborman/uuid"
+)
+
+const (
+	brickCheckTxnKey string = "brickchecks"
+)
+
+// VolDryRunResp is the response sent for volume create and expand requests
+// made with the dry-run flag set
+type VolDryRunResp struct {
+	Valid    bool                      `json:"valid"`
+	Bricks   []volume.BrickCheckResult `json:"bricks"`
+	Warnings []string                  `json:"warnings,omitempty"`
+}
+
+// isDryRun returns true if the request has the dry-run query parameter set
+func isDryRun(r *http.Request) bool {
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry-run"))
+	return dryRun
+}
+
+func checkBricks(c transaction.TxnCtx) error {
+
+	var bricks []brick.Brickinfo
+	if err := c.Get("bricks", &bricks); err != nil {
+		return err
+	}
+
+	var force bool
+	if err := c.Get("force", &force); err != nil {
+		return err
+	}
+
+	results := volume.CheckBrickEntries(bricks, force)
+
+	return c.SetNodeResult(gdctx.MyUUID, brickCheckTxnKey, results)
+}
+
+func registerVolDryRunStepFuncs() {
+	transaction.RegisterStepFunc(checkBricks, "vol-dryrun.CheckBricks")
+}
+
+// replicaSetWarnings returns a warning for every replica set which has more
+// than one brick on the same peer
+func replicaSetWarnings(bricks []brick.Brickinfo, replicaCount int) []string {
+	var warnings []string
+
+	if replicaCount <= 1 {
+		return warnings
+	}
+
+	for i := 0; i+replicaCount <= len(bricks); i += replicaCount {
+		set := bricks[i : i+replicaCount]
+		for j := range set {
+			for k := j + 1; k < len(set); k++ {
+				if uuid.Equal(set[j].NodeID, set[k].NodeID) {
+					warnings = append(warnings, "bricks "+set[j].Path+" and "+set[k].Path+
+						" of the same replica set are on peer "+set[j].NodeID.String())
+				}
+			}
+		}
+	}
+
+	return warnings
+}
+
+// dryRunBricks validates the given bricks on the nodes they belong to,
+// without modifying anything, and returns the aggregated report
+func dryRunBricks(reqID string, nodes []uuid.UUID, bricks []brick.Brickinfo, force bool) (*VolDryRunResp, error) {
+
+	// Checking bricks does not modify any state on the nodes, so no
+	// locks are needed here.
+	txn := transaction.NewTxn(reqID)
+	defer txn.Cleanup()
+	txn.Nodes = nodes
+	txn.Steps = []*transaction.Step{
+		{
+			DoFunc: "vol-dryrun.CheckBricks",
+			Nodes:  txn.Nodes,
+		},
+	}
+
+	if err := txn.Ctx.Set("bricks", bricks); err != nil {
+		return nil, err
+	}
+	if err := txn.Ctx.Set("force", force); err != nil {
+		return nil, err
+	}
+
+	c, err := txn.Do()
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &VolDryRunResp{Valid: true}
+	for _, node := range txn.Nodes {
+		var results []volume.BrickCheckResult
+		if err := c.GetNodeResult(node, brickCheckTxnKey, &results); err != nil {
+			return nil, err
+		}
+		for _, result := range results {
+			if !result.Valid {
+				resp.Valid = false
+			}
+			resp.Bricks = append(resp.Bricks, result)
+			resp.Warnings = append(resp.Warnings, result.Warnings...)
+		}
+	}
+
+	return resp, nil
+}
@@ -2,13 +2,16 @@ package volumecommands
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/gluster/glusterd2/brick"
 	"github.com/gluster/glusterd2/errors"
 	"github.com/gluster/glusterd2/gdctx"
+	"github.com/gluster/glusterd2/hooks"
 	restutils "github.com/gluster/glusterd2/servers/rest/utils"
 	"github.com/gluster/glusterd2/transaction"
 	"github.com/gluster/glusterd2/utils"
+	"github.com/gluster/glusterd2/validation"
 	"github.com/gluster/glusterd2/volgen"
 	"github.com/gluster/glusterd2/volume"
 
@@ -21,6 +24,7 @@ import (
 type VolExpandReq struct {
 	ReplicaCount int      `json:"replica,omitempty"`
 	Bricks       []string `json:"bricks"`
+	Force        bool     `json:"force,omitempty"`
 	// TODO: Add other fields like disperse count when we support
 	// that volume type
 }
@@ -32,8 +36,13 @@ func checkBricksOnExpand(c transaction.TxnCtx) error {
 		return err
 	}
 
+	var force bool
+	if err := c.Get("force", &force); err != nil {
+		return err
+	}
+
 	// TODO: Fix return values
-	if _, err := volume.ValidateBrickEntriesFunc(newBricks, newBricks[0].VolumeID, true); err != nil {
+	if _, err := volume.ValidateBrickEntriesFunc(newBricks, newBricks[0].VolumeID, force); err != nil {
 		return err
 	}
 
@@ -202,6 +211,17 @@ func volumeExpandHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var errs validation.Errors
+	errs.Bricks("bricks", req.Bricks)
+	if req.ReplicaCount < 0 {
+		errs.Add("replica", "must not be negative")
+	}
+	if errs != nil {
+		logger.WithError(errs).Error("invalid volume expand request")
+		restutils.SendValidationErrors(w, errs)
+		return
+	}
+
 	newBrickCount := len(req.Bricks) + len(volinfo.Bricks)
 
 	var newReplicaCount int
@@ -245,6 +265,7 @@ func volumeExpandHandler(w http.ResponseWriter, r *http.Request) {
 	txn.Nodes = nodes
 	txn.Steps = []*transaction.Step{
 		lock,
+		hooks.PreStep(txn.Nodes),
 		{
 			DoFunc: "vol-expand.CheckBrick",
 			Nodes:  txn.Nodes,
@@ -262,6 +283,7 @@ func volumeExpandHandler(w http.ResponseWriter, r *http.Request) {
 			DoFunc: "vol-expand.NotifyClients",
 			Nodes:  txn.Nodes,
 		},
+		hooks.PostStep(txn.Nodes),
 		unlock,
 	}
 
@@ -272,11 +294,32 @@ func volumeExpandHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if isDryRun(r) {
+		allBricks := make([]brick.Brickinfo, 0, newBrickCount)
+		allBricks = append(allBricks, volinfo.Bricks...)
+		allBricks = append(allBricks, newBricks...)
+
+		resp, err := dryRunBricks(reqID, nodes, newBricks, req.Force)
+		if err != nil {
+			logger.WithError(err).Error("volume expand dry-run failed")
+			restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		resp.Warnings = append(resp.Warnings, replicaSetWarnings(allBricks, newReplicaCount)...)
+		restutils.SendHTTPResponse(w, http.StatusOK, resp)
+		return
+	}
+
 	if err := txn.Ctx.Set("newbricks", newBricks); err != nil {
 		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
+	if err := txn.Ctx.Set("force", req.Force); err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
 	if err := txn.Ctx.Set("newreplicacount", newReplicaCount); err != nil {
 		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -287,10 +330,20 @@ func volumeExpandHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if _, err = txn.Do(); err != nil {
+	env := hookEnv(volinfo)
+	env["bricks"] = strings.Join(req.Bricks, ",")
+	if err := hooks.SetTxnCtx(txn.Ctx, hooks.OpAddBrick, env); err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	rtxn, err := txn.Do()
+	if err != nil {
 		logger.WithError(err).Error("volume expand transaction failed")
 		if err == transaction.ErrLockTimeout {
 			restutils.SendHTTPError(w, http.StatusConflict, err.Error())
+		} else if transaction.IsTimeout(err) {
+			restutils.SendHTTPError(w, http.StatusGatewayTimeout, err.Error())
 		} else {
 			restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
 		}
@@ -303,5 +356,8 @@ func volumeExpandHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	restutils.SendHTTPResponse(w, http.StatusOK, newvolinfo)
+	failed := hooks.PostFailures(rtxn, txn.Nodes)
+	logHookFailures(logger, failed)
+
+	restutils.SendHTTPResponse(w, http.StatusOK, VolumeResp{Volinfo: newvolinfo, HookFailures: failed})
 }
@@ -0,0 +1,224 @@
+package volumecommands
+
+import (
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/gdctx"
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/transaction"
+	"github.com/gluster/glusterd2/utils"
+	"github.com/gluster/glusterd2/validation"
+	"github.com/gluster/glusterd2/volume"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gorilla/mux"
+	"github.com/pborman/uuid"
+)
+
+// glfshealBin is the gfapi based heal utility of glusterfs. It resolves
+// split-brains through the replicate xlator of the volume, which marks the
+// chosen copy as the source and heals the other copies from it, the same way
+// the self-heal daemon does.
+const glfshealBin = "glfsheal"
+
+const splitBrainTxnKey = "splitbrain"
+
+// Split-brain resolution policies
+const (
+	// HealBiggerFile picks the copy with the biggest size as the source
+	HealBiggerFile = "bigger-file"
+	// HealLatestMtime picks the copy with the latest modification time as
+	// the source
+	HealLatestMtime = "latest-mtime"
+	// HealSourceBrick picks the copy on the given brick as the source
+	HealSourceBrick = "source-brick"
+)
+
+// SplitBrainReq represents a request to resolve a split-brain of a file on a
+// replicate volume
+type SplitBrainReq struct {
+	Policy string `json:"policy"`
+	// File is the path of the file from the root of the volume
+	File string `json:"file,omitempty"`
+	// Gfid can be given instead of the file
+	Gfid string `json:"gfid,omitempty"`
+	// SourceBrick is the brick in host:path form used with the source-brick
+	// policy. If no file or gfid is given, all the files in split-brain
+	// are resolved with the copies on the brick.
+	SourceBrick string `json:"source-brick,omitempty"`
+}
+
+// SplitBrainResp is the response sent for a split-brain resolution request
+type SplitBrainResp struct {
+	Output string `json:"output"`
+}
+
+func validateSplitBrainReq(req *SplitBrainReq, v *volume.Volinfo) validation.Errors {
+	var errs validation.Errors
+	if req.File != "" && req.Gfid != "" {
+		errs.Add("gfid", "only one of file and gfid can be given")
+	} else if req.Gfid != "" && uuid.Parse(req.Gfid) == nil {
+		errs.Add("gfid", "invalid gfid %s", req.Gfid)
+	}
+
+	switch req.Policy {
+	case HealBiggerFile, HealLatestMtime:
+		if req.File == "" && req.Gfid == "" {
+			errs.Add("file", "a file or gfid is needed with the %s policy", req.Policy)
+		}
+		if req.SourceBrick != "" {
+			errs.Add("source-brick", "a source brick can only be given with the %s policy", HealSourceBrick)
+		}
+	case HealSourceBrick:
+		if !errs.RequireString("source-brick", req.SourceBrick) {
+			break
+		}
+		found := false
+		for _, b := range v.Bricks {
+			if b.Hostname+":"+b.Path == req.SourceBrick {
+				found = true
+				break
+			}
+		}
+		if !found {
+			errs.Add("source-brick", "brick %s is not a brick of volume %s", req.SourceBrick, v.Name)
+		}
+	default:
+		errs.Add("policy", errors.ErrInvalidHealPolicy.Error())
+	}
+	return errs
+}
+
+// glfshealArgs returns the arguments of glfsheal for the request
+func glfshealArgs(volname string, req *SplitBrainReq) []string {
+	args := []string{volname, req.Policy}
+	if req.Policy == HealSourceBrick {
+		args = append(args, req.SourceBrick)
+	}
+	if req.File != "" {
+		args = append(args, req.File)
+	} else if req.Gfid != "" {
+		args = append(args, "gfid:"+req.Gfid)
+	}
+	return args
+}
+
+func resolveSplitBrain(c transaction.TxnCtx) error {
+	var volname string
+	if err := c.Get("volname", &volname); err != nil {
+		return err
+	}
+	var req SplitBrainReq
+	if err := c.Get("req", &req); err != nil {
+		return err
+	}
+
+	path, err := exec.LookPath(glfshealBin)
+	if err != nil {
+		return err
+	}
+
+	c.Logger().WithFields(log.Fields{
+		"volume": volname,
+		"policy": req.Policy,
+		"file":   req.File,
+		"gfid":   req.Gfid,
+	}).Info("resolving split-brain")
+
+	out, err := exec.Command(path, glfshealArgs(volname, &req)...).CombinedOutput()
+	output := strings.TrimSpace(string(out))
+	if err != nil {
+		if output != "" {
+			return fmt.Errorf("%s: %s", err.Error(), output)
+		}
+		return err
+	}
+
+	return c.SetNodeResult(gdctx.MyUUID, splitBrainTxnKey, output)
+}
+
+func registerVolHealStepFuncs() {
+	transaction.RegisterStepFunc(resolveSplitBrain, "vol-heal.SplitBrain")
+}
+
+// volumeSplitBrainHandler resolves the split-brain of a file, or of all the
+// files in split-brain with the source-brick policy, using the given policy
+// to choose the good copy. The volume is locked while the split-brain is
+// resolved.
+func volumeSplitBrainHandler(w http.ResponseWriter, r *http.Request) {
+	volname := mux.Vars(r)["volname"]
+	reqID, logger := restutils.GetReqIDandLogger(r)
+
+	var req SplitBrainReq
+	if err := utils.GetJSONFromRequest(r, &req); err != nil {
+		restutils.SendHTTPError(w, http.StatusUnprocessableEntity, errors.ErrJSONParsingFailed.Error())
+		return
+	}
+
+	vol, err := volume.GetVolume(volname)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusNotFound, errors.ErrVolNotFound.Error())
+		return
+	}
+	if vol.ReplicaCount <= 1 {
+		restutils.SendHTTPError(w, http.StatusBadRequest, errors.ErrVolNotReplicate.Error())
+		return
+	}
+	if vol.Status != volume.VolStarted {
+		restutils.SendHTTPError(w, http.StatusBadRequest, errors.ErrVolNotStarted.Error())
+		return
+	}
+
+	if errs := validateSplitBrainReq(&req, vol); errs != nil {
+		restutils.SendValidationErrors(w, errs)
+		return
+	}
+
+	lock, unlock, err := transaction.CreateLockSteps(volname)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	txn := transaction.NewTxn(reqID)
+	defer txn.Cleanup()
+	txn.Nodes = []uuid.UUID{gdctx.MyUUID}
+	txn.Steps = []*transaction.Step{
+		lock,
+		{
+			// glfsheal connects to the volume as a client, so it
+			// only needs to be run on one node
+			DoFunc: "vol-heal.SplitBrain",
+			Nodes:  txn.Nodes,
+		},
+		unlock,
+	}
+	txn.Ctx.Set("volname", volname)
+	txn.Ctx.Set("req", &req)
+
+	rtxn, err := txn.Do()
+	if err != nil {
+		logger.WithError(err).WithField(
+			"volume", volname).Error("failed to resolve split-brain")
+		if err == transaction.ErrLockTimeout {
+			restutils.SendHTTPError(w, http.StatusConflict, err.Error())
+		} else if transaction.IsTimeout(err) {
+			restutils.SendHTTPError(w, http.StatusGatewayTimeout, err.Error())
+		} else {
+			restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	var resp SplitBrainResp
+	if err := rtxn.GetNodeResult(gdctx.MyUUID, splitBrainTxnKey, &resp.Output); err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	restutils.SendHTTPResponse(w, http.StatusOK, &resp)
+}
@@ -0,0 +1,271 @@
+package volumecommands
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+
+	"github.com/gluster/glusterd2/brick"
+	gderrors "github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/gdctx"
+	"github.com/gluster/glusterd2/peer"
+	"github.com/gluster/glusterd2/pkg/gd1"
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/transaction"
+	"github.com/gluster/glusterd2/utils"
+	"github.com/gluster/glusterd2/validation"
+	"github.com/gluster/glusterd2/volume"
+
+	"github.com/pborman/uuid"
+)
+
+// VolImportReq represents a request to import volumes from GlusterD1
+type VolImportReq struct {
+	// Dir is the working directory of GlusterD1, /var/lib/glusterd by
+	// default
+	Dir string `json:"dir,omitempty"`
+	// Volumes are the names of the volumes to import. All volumes are
+	// imported if empty.
+	Volumes []string `json:"volumes,omitempty"`
+}
+
+// VolImportSkipped represents a volume which was not imported
+type VolImportSkipped struct {
+	Volume string `json:"volume"`
+	Reason string `json:"reason"`
+}
+
+// PeerImportResult represents a GlusterD1 peer and the GlusterD2 peer it was
+// found as
+type PeerImportResult struct {
+	UUID      string    `json:"uuid"`
+	Hostnames []string  `json:"hostnames"`
+	PeerID    uuid.UUID `json:"peer-id,omitempty"`
+	Found     bool      `json:"found"`
+}
+
+// VolImportResp is the response sent for a volume import request
+type VolImportResp struct {
+	Imported []string           `json:"imported"`
+	Skipped  []VolImportSkipped `json:"skipped,omitempty"`
+	Peers    []PeerImportResult `json:"peers"`
+}
+
+// gd1PeerID returns the ID of the GlusterD2 peer which is the given GlusterD1
+// peer, matching first by UUID and then by the hostnames
+func gd1PeerID(id string, hostnames []string) (uuid.UUID, error) {
+	if u := uuid.Parse(id); u != nil {
+		if p, err := peer.GetPeerF(u.String()); err == nil {
+			return p.ID, nil
+		}
+	}
+	for _, h := range hostnames {
+		if u, err := peer.GetPeerIDByAddrF(h); err == nil {
+			return u, nil
+		}
+	}
+	return nil, gderrors.ErrPeerNotFound
+}
+
+// volinfoFromGD1 returns the volinfo for a GlusterD1 volume. The hosts of all
+// bricks must already be peers of the cluster.
+func volinfoFromGD1(v *gd1.Volume) (*volume.Volinfo, error) {
+	switch {
+	case v.TierEnabled:
+		return nil, errors.New("tiered volumes are not supported")
+	case v.StripeCount > 1:
+		return nil, errors.New("striped volumes are not supported")
+	case v.ArbiterCount > 0:
+		return nil, errors.New("arbiter volumes are not supported")
+	case v.Type != gd1.TypeDistribute && v.Type != gd1.TypeReplicate:
+		return nil, fmt.Errorf("volume type %d is not supported", v.Type)
+	case len(v.Bricks) == 0:
+		return nil, errors.New("volume has no bricks")
+	}
+
+	id := uuid.Parse(v.ID)
+	if id == nil {
+		return nil, fmt.Errorf("invalid volume-id %q", v.ID)
+	}
+
+	vol := &volume.Volinfo{
+		ID:           id,
+		Name:         v.Name,
+		Transport:    v.Transport,
+		ReplicaCount: v.ReplicaCount,
+		Options:      v.Options,
+		Auth:         volume.VolAuth{Username: v.Username, Password: v.Password},
+		// Bricks are started by GlusterD2 when the volume is started
+		Status: volume.VolStopped,
+	}
+	if v.Status == gd1.StatusCreated {
+		vol.Status = volume.VolCreated
+	}
+
+	if vol.ReplicaCount < 1 {
+		vol.ReplicaCount = 1
+	}
+	if len(v.Bricks)%vol.ReplicaCount != 0 {
+		return nil, errors.New("invalid number of bricks")
+	}
+	vol.DistCount = len(v.Bricks) / vol.ReplicaCount
+
+	switch {
+	case vol.ReplicaCount == 1:
+		vol.Type = volume.Distribute
+	case vol.DistCount == 1:
+		vol.Type = volume.Replicate
+	default:
+		vol.Type = volume.DistReplicate
+	}
+
+	for _, b := range v.Bricks {
+		nodeID, err := gd1PeerID(b.UUID, []string{b.Hostname})
+		if err != nil {
+			return nil, fmt.Errorf("host %s of brick %s is not a peer", b.Hostname, b.Path)
+		}
+		vol.Bricks = append(vol.Bricks, brick.Brickinfo{
+			Hostname:   b.Hostname,
+			NodeID:     nodeID,
+			Path:       b.Path,
+			VolumeName: vol.Name,
+			VolumeID:   vol.ID,
+		})
+	}
+
+	return vol, nil
+}
+
+// importVolume generates the volfiles of the imported volume on the nodes
+// with its bricks, and saves the volume in the store. The bricks are used as
+// they are, as they already belong to the volume.
+func importVolume(reqID string, vol *volume.Volinfo) error {
+	txn := transaction.NewTxn(reqID)
+	defer txn.Cleanup()
+
+	lock, unlock, err := transaction.CreateLockSteps(vol.Name)
+	if err != nil {
+		return err
+	}
+
+	txn.Nodes = vol.Nodes()
+	txn.Steps = []*transaction.Step{
+		lock,
+		{
+			DoFunc: "vol-create.Commit",
+			Nodes:  txn.Nodes,
+		},
+		{
+			DoFunc: "vol-create.Store",
+			Nodes:  []uuid.UUID{gdctx.MyUUID},
+		},
+		unlock,
+	}
+	if err := txn.Ctx.Set("volinfo", vol); err != nil {
+		return err
+	}
+
+	_, err = txn.Do()
+	return err
+}
+
+func volumeImportHandler(w http.ResponseWriter, r *http.Request) {
+	reqID, logger := restutils.GetReqIDandLogger(r)
+
+	var req VolImportReq
+	if err := utils.GetJSONFromRequest(r, &req); err != nil {
+		restutils.SendHTTPError(w, http.StatusUnprocessableEntity, gderrors.ErrJSONParsingFailed.Error())
+		return
+	}
+
+	var errs validation.Errors
+	if req.Dir != "" && !filepath.IsAbs(req.Dir) {
+		errs.Add("dir", "must be an absolute path")
+	}
+	for i, name := range req.Volumes {
+		errs.RequireString(fmt.Sprintf("volumes[%d]", i), name)
+	}
+	if errs != nil {
+		restutils.SendValidationErrors(w, errs)
+		return
+	}
+
+	if req.Dir == "" {
+		req.Dir = gd1.DefaultDir
+	}
+
+	gd1Vols, err := gd1.ReadVolumes(req.Dir)
+	if err != nil {
+		logger.WithError(err).WithField("dir", req.Dir).Error("failed to read glusterd1 volumes")
+		restutils.SendHTTPError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	gd1Peers, err := gd1.ReadPeers(req.Dir)
+	if err != nil {
+		logger.WithError(err).WithField("dir", req.Dir).Error("failed to read glusterd1 peers")
+		restutils.SendHTTPError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp := VolImportResp{Imported: []string{}}
+
+	// The GlusterD1 peers need to have been added as GlusterD2 peers for
+	// their bricks to be imported
+	for _, p := range gd1Peers {
+		result := PeerImportResult{UUID: p.UUID, Hostnames: p.Hostnames}
+		if id, err := gd1PeerID(p.UUID, p.Hostnames); err == nil {
+			result.PeerID = id
+			result.Found = true
+		}
+		resp.Peers = append(resp.Peers, result)
+	}
+
+	wanted := make(map[string]bool)
+	for _, name := range req.Volumes {
+		wanted[name] = true
+	}
+
+	filter := len(wanted) > 0
+	for i := range gd1Vols {
+		v := &gd1Vols[i]
+		if filter && !wanted[v.Name] {
+			continue
+		}
+		delete(wanted, v.Name)
+
+		skip := func(reason string) {
+			logger.WithField("volume", v.Name).WithField("reason", reason).Warn("not importing glusterd1 volume")
+			resp.Skipped = append(resp.Skipped, VolImportSkipped{v.Name, reason})
+		}
+
+		if volume.ExistsFunc(v.Name) {
+			skip(gderrors.ErrVolExists.Error())
+			continue
+		}
+		if _, ok := volume.ExistingName(v.Name); ok {
+			skip(gderrors.ErrVolNameConflict.Error())
+			continue
+		}
+
+		vol, err := volinfoFromGD1(v)
+		if err != nil {
+			skip(err.Error())
+			continue
+		}
+
+		if err := importVolume(reqID, vol); err != nil {
+			skip(err.Error())
+			continue
+		}
+
+		logger.WithField("volume", v.Name).Info("imported glusterd1 volume")
+		resp.Imported = append(resp.Imported, v.Name)
+	}
+
+	for name := range wanted {
+		resp.Skipped = append(resp.Skipped, VolImportSkipped{name, gderrors.ErrVolNotFound.Error()})
+	}
+
+	restutils.SendHTTPResponse(w, http.StatusOK, resp)
+}
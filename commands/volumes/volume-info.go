@@ -14,7 +14,7 @@ func volumeInfoHandler(w http.ResponseWriter, r *http.Request) {
 	p := mux.Vars(r)
 	volname := p["volname"]
 
-	vol, e := volume.GetVolume(volname)
+	vol, e := volume.GetVolumeCached(volname)
 	if e != nil {
 		restutils.SendHTTPError(w, http.StatusNotFound, errors.ErrVolNotFound.Error())
 	} else {
@@ -2,11 +2,122 @@ package volumecommands
 
 import (
 	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
 
 	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/validation"
 	"github.com/gluster/glusterd2/volume"
+
+	"github.com/pborman/uuid"
 )
 
+// VolSummary is the summary of a volume returned when listing volumes
+type VolSummary struct {
+	ID         uuid.UUID       `json:"id"`
+	Name       string          `json:"name"`
+	Type       volume.VolType  `json:"type"`
+	Status     volume.VolState `json:"status"`
+	BrickCount int             `json:"brick-count"`
+}
+
+// VolListResp is the response sent for a volume list request. Volumes is a
+// list of VolSummary, or of Volinfo if details were requested.
+type VolListResp struct {
+	Total   int         `json:"total"`
+	Volumes interface{} `json:"volumes"`
+}
+
+// volListQuery is the filtering and pagination query of a volume list request
+type volListQuery struct {
+	state   string
+	volType string
+	// options which the volumes must have set. An empty value matches any
+	// value.
+	options map[string]string
+	limit   int
+	offset  int
+	details bool
+}
+
+func parseVolListQuery(q url.Values) (*volListQuery, validation.Errors) {
+	var errs validation.Errors
+	query := &volListQuery{
+		state:   q.Get("state"),
+		volType: q.Get("type"),
+		options: make(map[string]string),
+	}
+
+	if query.state != "" {
+		errs.OneOf("state", query.state, volume.VolStateNames()...)
+	}
+	if query.volType != "" {
+		errs.OneOf("type", query.volType, volume.VolTypeNames()...)
+	}
+
+	// Options are given as option=<key> or option=<key>=<value>
+	for _, o := range q["option"] {
+		kv := strings.SplitN(o, "=", 2)
+		if kv[0] == "" {
+			errs.Add("option", "option name is required")
+			continue
+		}
+		if len(kv) == 2 {
+			query.options[kv[0]] = kv[1]
+		} else {
+			query.options[kv[0]] = ""
+		}
+	}
+
+	ints := []struct {
+		name string
+		val  *int
+	}{
+		{"limit", &query.limit},
+		{"offset", &query.offset},
+	}
+	for _, i := range ints {
+		v := q.Get(i.name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			errs.Add(i.name, "must be a non-negative integer")
+			continue
+		}
+		*i.val = n
+	}
+
+	if d := q.Get("details"); d != "" {
+		details, err := strconv.ParseBool(d)
+		if err != nil {
+			errs.Add("details", "must be a boolean")
+		}
+		query.details = details
+	}
+
+	return query, errs
+}
+
+func (q *volListQuery) matches(v *volume.Volinfo) bool {
+	if q.state != "" && v.Status.String() != q.state {
+		return false
+	}
+	if q.volType != "" && v.Type.String() != q.volType {
+		return false
+	}
+	for k, want := range q.options {
+		val, ok := v.Options[k]
+		if !ok || (want != "" && val != want) {
+			return false
+		}
+	}
+	return true
+}
+
 func volumeListHandler(w http.ResponseWriter, r *http.Request) {
 
 	volumes, e := volume.GetVolumesList()
@@ -16,3 +127,65 @@ func volumeListHandler(w http.ResponseWriter, r *http.Request) {
 		restutils.SendHTTPResponse(w, http.StatusOK, volumes)
 	}
 }
+
+// volumeListV2Handler returns a filtered page of the volumes list, sorted by
+// volume name. The v1 API returns a map of all volume names to their IDs.
+func volumeListV2Handler(w http.ResponseWriter, r *http.Request) {
+
+	query, errs := parseVolListQuery(r.URL.Query())
+	if errs != nil {
+		restutils.SendValidationErrors(w, errs)
+		return
+	}
+
+	volumes, e := volume.GetVolumesCached()
+	if e != nil {
+		restutils.SendHTTPError(w, http.StatusNotFound, e.Error())
+		return
+	}
+
+	var matched []volume.Volinfo
+	for i := range volumes {
+		if query.matches(&volumes[i]) {
+			matched = append(matched, volumes[i])
+		}
+	}
+
+	// Sort by name for the pages to be stable
+	sort.Sort(volumesByName(matched))
+
+	resp := VolListResp{Total: len(matched)}
+
+	if query.offset < len(matched) {
+		matched = matched[query.offset:]
+	} else {
+		matched = nil
+	}
+	if query.limit > 0 && query.limit < len(matched) {
+		matched = matched[:query.limit]
+	}
+
+	if query.details {
+		resp.Volumes = append([]volume.Volinfo{}, matched...)
+	} else {
+		summaries := make([]VolSummary, 0, len(matched))
+		for _, v := range matched {
+			summaries = append(summaries, VolSummary{
+				ID:         v.ID,
+				Name:       v.Name,
+				Type:       v.Type,
+				Status:     v.Status,
+				BrickCount: len(v.Bricks),
+			})
+		}
+		resp.Volumes = summaries
+	}
+
+	restutils.SendHTTPResponse(w, http.StatusOK, resp)
+}
+
+type volumesByName []volume.Volinfo
+
+func (v volumesByName) Len() int           { return len(v) }
+func (v volumesByName) Swap(i, j int)      { v[i], v[j] = v[j], v[i] }
+func (v volumesByName) Less(i, j int) bool { return v[i].Name < v[j].Name }
@@ -0,0 +1,162 @@
+package volumecommands
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/pkg/api"
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/store"
+	"github.com/gluster/glusterd2/volume"
+
+	"github.com/gorilla/mux"
+	"github.com/pborman/uuid"
+	config "github.com/spf13/viper"
+)
+
+const defaultVolfileServerPort = "24007"
+
+const systemdMountUnit = `[Unit]
+Description=Gluster volume %s
+Wants=network-online.target
+After=network-online.target
+
+[Mount]
+What=%s
+Where=%s
+Type=glusterfs
+Options=%s
+
+[Install]
+WantedBy=remote-fs.target
+`
+
+// volfileServers returns the hosts of the peers with bricks of the volume,
+// which can serve its client volfile. Online peers are listed first.
+func volfileServers(v *volume.Volinfo) []string {
+	var online, offline []string
+	for _, node := range v.Nodes() {
+		var host string
+		for _, b := range v.Bricks {
+			if uuid.Equal(b.NodeID, node) {
+				host = b.Hostname
+				break
+			}
+		}
+		if store.Store.IsNodeAlive(node) {
+			online = append(online, host)
+		} else {
+			offline = append(offline, host)
+		}
+	}
+	return append(online, offline...)
+}
+
+// joinMountOptions returns the mount options as a comma separated list,
+// sorted by name
+func joinMountOptions(opts map[string]string, extra ...string) string {
+	var list []string
+	for k, v := range opts {
+		list = append(list, k+"="+v)
+	}
+	sort.Strings(list)
+	return strings.Join(append(list, extra...), ",")
+}
+
+// systemdEscapePath returns the path escaped as done by systemd-escape --path,
+// to be used as the name of the mount unit of the path
+func systemdEscapePath(p string) string {
+	p = strings.Trim(filepath.Clean(p), "/")
+	if p == "" {
+		return "-"
+	}
+
+	var out []string
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		switch {
+		case c == '/':
+			out = append(out, "-")
+		case c == '.' && i == 0:
+			out = append(out, fmt.Sprintf(`\x%02x`, c))
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9',
+			c == ':', c == '_', c == '.':
+			out = append(out, string(c))
+		default:
+			out = append(out, fmt.Sprintf(`\x%02x`, c))
+		}
+	}
+	return strings.Join(out, "")
+}
+
+func newVolMountInfo(v *volume.Volinfo, mountPoint string) *api.VolMountInfo {
+	servers := volfileServers(v)
+
+	info := &api.VolMountInfo{
+		Volume:         v.Name,
+		VolfileServers: servers,
+		Transport:      v.Transport,
+		Options:        make(map[string]string),
+	}
+	if info.Transport == "" {
+		info.Transport = "tcp"
+	}
+	info.Options["transport"] = info.Transport
+
+	// All the peers serve volfiles on the same port as this one
+	if _, port, err := net.SplitHostPort(config.GetString("clientaddress")); err == nil && port != defaultVolfileServerPort {
+		info.Options["server-port"] = port
+	}
+	if len(servers) > 0 {
+		info.Source = servers[0] + ":/" + v.Name
+	}
+	if len(servers) > 1 {
+		info.Options["backup-volfile-servers"] = strings.Join(servers[1:], ":")
+	}
+
+	where := mountPoint
+	if where == "" {
+		where = "/mnt/" + v.Name
+	}
+	info.Command = fmt.Sprintf("mount -t glusterfs -o %s %s %s", joinMountOptions(info.Options), info.Source, where)
+
+	if mountPoint != "" {
+		// _netdev delays the mount until the network is up
+		opts := joinMountOptions(info.Options, "_netdev")
+		info.MountPoint = mountPoint
+		info.FSTab = fmt.Sprintf("%s %s glusterfs defaults,%s 0 0", info.Source, mountPoint, opts)
+		info.SystemdUnitName = systemdEscapePath(mountPoint) + ".mount"
+		info.SystemdUnit = fmt.Sprintf(systemdMountUnit, v.Name, info.Source, mountPoint, opts)
+	}
+
+	return info
+}
+
+// volumeMountHandler returns the parameters to mount a volume. An fstab entry
+// and a systemd mount unit are generated for the mount point given with the
+// mountpoint query parameter.
+func volumeMountHandler(w http.ResponseWriter, r *http.Request) {
+	volname := mux.Vars(r)["volname"]
+
+	mountPoint := r.URL.Query().Get("mountpoint")
+	if mountPoint != "" {
+		if !filepath.IsAbs(mountPoint) || strings.ContainsAny(mountPoint, " \t\n") {
+			restutils.SendHTTPError(w, http.StatusBadRequest, "mount point must be an absolute path without whitespace")
+			return
+		}
+		mountPoint = filepath.Clean(mountPoint)
+	}
+
+	vol, err := volume.GetVolumeCached(volname)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusNotFound, errors.ErrVolNotFound.Error())
+		return
+	}
+
+	restutils.SendHTTPResponse(w, http.StatusOK, newVolMountInfo(vol, mountPoint))
+}
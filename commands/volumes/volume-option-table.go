@@ -0,0 +1,168 @@
+package volumecommands
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/opversion"
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/utils"
+	"github.com/gluster/glusterd2/version"
+	"github.com/gluster/glusterd2/xlator"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// clusterOpVersion returns the current op-version of the cluster
+var clusterOpVersion = opversion.Get
+
+// optionOpVersionError is returned for an option which needs a higher
+// op-version than the current op-version of the cluster
+type optionOpVersionError struct {
+	option   string
+	required int
+	current  int
+}
+
+func (e optionOpVersionError) Error() string {
+	return fmt.Sprintf("%s: %s requires op-version %d, cluster op-version is %d",
+		errors.ErrOptionOpVersion.Error(), e.option, e.required, e.current)
+}
+
+// optionOpVersions are the op-versions which introduced xlator options, for
+// options which cannot be set at all op-versions of the cluster. Options not
+// listed here can be set since version.MinOpVersion. Options are listed by
+// their first key.
+var optionOpVersions = map[string]int{
+	"posix.ctime":               version.OpVersion41,
+	"shard.shard-deletion-rate": version.OpVersion41,
+}
+
+// OptionInfo describes a volume option which can be set
+type OptionInfo struct {
+	Name string `json:"name"`
+	// Aliases are the other names the xlator accepts for the option
+	Aliases       []string `json:"aliases,omitempty"`
+	Type          string   `json:"type"`
+	DefaultValue  string   `json:"default-value,omitempty"`
+	Description   string   `json:"description,omitempty"`
+	AllowedValues []string `json:"allowed-values,omitempty"`
+	Min           *float64 `json:"min,omitempty"`
+	Max           *float64 `json:"max,omitempty"`
+	OpVersion     int      `json:"op-version"`
+}
+
+// optionOpVersion returns the op-version which introduced the option with
+// the given name, which can be any of the keys of the option
+func optionOpVersion(name string) int {
+	if i := strings.Index(name, "."); i > 0 {
+		xl, key := name[:i], name[i+1:]
+		for _, o := range xlator.AllOptions[xl] {
+			if len(o.Key) == 0 || !utils.StringInSlice(key, o.Key) {
+				continue
+			}
+			if v, ok := optionOpVersions[xl+"."+o.Key[0]]; ok {
+				return v
+			}
+			break
+		}
+	}
+	if v, ok := optionOpVersions[name]; ok {
+		return v
+	}
+	return version.MinOpVersion
+}
+
+// checkOptionsOpVersion returns an error for the first of the options which
+// was introduced after the current op-version of the cluster
+func checkOptionsOpVersion(options map[string]string) error {
+	current, err := clusterOpVersion()
+	if err != nil {
+		return err
+	}
+	for name := range options {
+		if v := optionOpVersion(name); v > current {
+			return optionOpVersionError{option: name, required: v, current: current}
+		}
+	}
+	return nil
+}
+
+// validateOptions checks that the options exist and can be set at the
+// current op-version of the cluster, and sends an error response if not.
+// False is returned if an error was sent.
+func validateOptions(w http.ResponseWriter, logger log.FieldLogger, options map[string]string) bool {
+	if err := areOptionNamesValid(options); err != nil {
+		logger.WithField("option", err.Error()).Error("invalid option specified")
+		restutils.SendHTTPError(w, http.StatusBadRequest, fmt.Sprintf("invalid option specified: %s", err.Error()))
+		return false
+	}
+
+	if err := checkOptionsOpVersion(options); err != nil {
+		logger.WithError(err).Error("option not supported at the cluster op-version")
+		if _, ok := err.(optionOpVersionError); ok {
+			restutils.SendHTTPError(w, http.StatusBadRequest, err.Error())
+		} else {
+			restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		}
+		return false
+	}
+	return true
+}
+
+type optionsByName []OptionInfo
+
+func (o optionsByName) Len() int           { return len(o) }
+func (o optionsByName) Swap(i, j int)      { o[i], o[j] = o[j], o[i] }
+func (o optionsByName) Less(i, j int) bool { return o[i].Name < o[j].Name }
+
+// newOptionInfo returns the description of an option of the given xlator.
+// Options are named <xlator>.<key>, as they are set on volumes.
+func newOptionInfo(xl string, o *xlator.Option) OptionInfo {
+	info := OptionInfo{
+		Name:          xl + "." + o.Key[0],
+		Type:          o.Type.String(),
+		DefaultValue:  o.DefaultValue,
+		Description:   o.Description,
+		AllowedValues: o.Value,
+		OpVersion:     version.MinOpVersion,
+	}
+	for _, k := range o.Key[1:] {
+		info.Aliases = append(info.Aliases, xl+"."+k)
+	}
+	info.OpVersion = optionOpVersion(info.Name)
+
+	// xlators leave both limits as 0 for options which have no range
+	if o.Min != 0 || o.Max != 0 {
+		min, max := o.Min, o.Max
+		switch o.Validate {
+		case xlator.OptionValidateBoth:
+			info.Min, info.Max = &min, &max
+		case xlator.OptionValidateMin:
+			info.Min = &min
+		case xlator.OptionValidateMax:
+			info.Max = &max
+		}
+	}
+	return info
+}
+
+// volumeOptionTableHandler returns all the options which can be set on
+// volumes, for clients to validate options and show help
+func volumeOptionTableHandler(w http.ResponseWriter, r *http.Request) {
+	options := make([]OptionInfo, 0)
+	for xl, xlOptions := range xlator.AllOptions {
+		for i := range xlOptions {
+			if len(xlOptions[i].Key) == 0 {
+				continue
+			}
+			options = append(options, newOptionInfo(xl, &xlOptions[i]))
+		}
+	}
+	sort.Sort(optionsByName(options))
+
+	restutils.SendHTTPResponse(w, http.StatusOK, options)
+}
@@ -0,0 +1,80 @@
+package volumecommands
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gluster/glusterd2/tests"
+	"github.com/gluster/glusterd2/version"
+	"github.com/gluster/glusterd2/xlator"
+
+	log "github.com/Sirupsen/logrus"
+	heketitests "github.com/heketi/tests"
+)
+
+func TestValidateOptionsOpVersion(t *testing.T) {
+	defer heketitests.Patch(&xlator.AllOptions, map[string][]xlator.Option{
+		"posix": {
+			{Key: []string{"ctime", "posix-ctime"}},
+			{Key: []string{"brick-uid"}},
+		},
+	}).Restore()
+
+	tests.Assert(t, optionOpVersion("posix.ctime") == version.OpVersion41)
+	tests.Assert(t, optionOpVersion("posix.posix-ctime") == version.OpVersion41)
+	tests.Assert(t, optionOpVersion("posix.brick-uid") == version.MinOpVersion)
+
+	logger := log.NewEntry(log.StandardLogger())
+
+	// Cluster at the minimum op-version
+	defer heketitests.Patch(&clusterOpVersion, func() (int, error) {
+		return version.MinOpVersion, nil
+	}).Restore()
+
+	w := httptest.NewRecorder()
+	tests.Assert(t, validateOptions(w, logger, map[string]string{"posix.brick-uid": "36"}))
+
+	w = httptest.NewRecorder()
+	tests.Assert(t, !validateOptions(w, logger, map[string]string{"posix.ctime": "on"}))
+	tests.Assert(t, w.Code == http.StatusBadRequest)
+
+	w = httptest.NewRecorder()
+	tests.Assert(t, !validateOptions(w, logger, map[string]string{"posix.posix-ctime": "on"}))
+	tests.Assert(t, w.Code == http.StatusBadRequest)
+
+	// Unknown options are rejected before the op-version is checked
+	w = httptest.NewRecorder()
+	tests.Assert(t, !validateOptions(w, logger, map[string]string{"posix.nonexistent": "on"}))
+	tests.Assert(t, w.Code == http.StatusBadRequest)
+
+	// The option can be set once the cluster op-version is bumped
+	defer heketitests.Patch(&clusterOpVersion, func() (int, error) {
+		return version.OpVersion41, nil
+	}).Restore()
+
+	w = httptest.NewRecorder()
+	tests.Assert(t, validateOptions(w, logger, map[string]string{"posix.ctime": "on"}))
+}
+
+func TestNewOptionInfo(t *testing.T) {
+	defer heketitests.Patch(&xlator.AllOptions, map[string][]xlator.Option{
+		"posix": {
+			{Key: []string{"ctime", "posix-ctime"}},
+		},
+		"shard": {
+			{Key: []string{"shard-block-size"}, Min: 4194304, Max: 4398046511104, Validate: xlator.OptionValidateBoth},
+		},
+	}).Restore()
+
+	info := newOptionInfo("posix", &xlator.AllOptions["posix"][0])
+	tests.Assert(t, info.Name == "posix.ctime")
+	tests.Assert(t, len(info.Aliases) == 1 && info.Aliases[0] == "posix.posix-ctime")
+	tests.Assert(t, info.OpVersion == version.OpVersion41)
+	tests.Assert(t, info.Min == nil && info.Max == nil)
+
+	info = newOptionInfo("shard", &xlator.AllOptions["shard"][0])
+	tests.Assert(t, info.OpVersion == version.MinOpVersion)
+	tests.Assert(t, info.Min != nil && *info.Min == 4194304)
+	tests.Assert(t, info.Max != nil && *info.Max == 4398046511104)
+}
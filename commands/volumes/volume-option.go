@@ -1,15 +1,16 @@
 package volumecommands
 
 import (
-	"fmt"
 	"net/http"
 
 	"github.com/gluster/glusterd2/errors"
 	"github.com/gluster/glusterd2/gdctx"
+	"github.com/gluster/glusterd2/hooks"
 	"github.com/gluster/glusterd2/peer"
 	restutils "github.com/gluster/glusterd2/servers/rest/utils"
 	"github.com/gluster/glusterd2/transaction"
 	"github.com/gluster/glusterd2/utils"
+	"github.com/gluster/glusterd2/validation"
 	"github.com/gluster/glusterd2/volume"
 	"github.com/pborman/uuid"
 
@@ -53,18 +54,61 @@ func volumeOptionsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := areOptionNamesValid(req.Options); err != nil {
-		logger.WithField("option", err.Error()).Error("invalid option specified")
-		restutils.SendHTTPError(w, http.StatusBadRequest, fmt.Sprintf("invalid option specified: %s", err.Error()))
+	var errs validation.Errors
+	errs.RequireList("options", len(req.Options))
+	if errs != nil {
+		restutils.SendValidationErrors(w, errs)
 		return
 	}
 
-	lock, unlock, err := transaction.CreateLockSteps(volinfo.Name)
+	if !validateOptions(w, logger, req.Options) {
+		return
+	}
+
+	failed, err := setVolumeOptions(reqID, volinfo, req.Options)
 	if err != nil {
-		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		logger.WithError(err).Error("volume option transaction failed")
+		if err == transaction.ErrLockTimeout {
+			restutils.SendHTTPError(w, http.StatusConflict, err.Error())
+		} else if transaction.IsTimeout(err) {
+			restutils.SendHTTPError(w, http.StatusGatewayTimeout, err.Error())
+		} else {
+			restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		}
 		return
 	}
 
+	logHookFailures(logger, failed)
+	setHookFailuresHeader(w, failed)
+
+	restutils.SendHTTPResponse(w, http.StatusOK, volinfo.Options)
+}
+
+// setVolumeOptions sets the given options on the volume, and regenerates and
+// notifies the changed volfiles in a single transaction. The post hooks which
+// failed are returned.
+func setVolumeOptions(reqID string, volinfo *volume.Volinfo, options map[string]string) ([]hooks.Result, error) {
+	for k, v := range options {
+		volinfo.Options[k] = v
+	}
+
+	env := hookEnv(volinfo)
+	for k, v := range options {
+		env["option."+k] = v
+	}
+	return commitVolumeChange(reqID, volinfo, env)
+}
+
+// commitVolumeChange saves the changed volinfo, and regenerates and notifies
+// the volfiles of the volume in a single transaction. Running bricks and
+// clients fetch the new volfiles when notified, so the change takes effect
+// without restarting them. The set hooks are run with the given environment.
+func commitVolumeChange(reqID string, volinfo *volume.Volinfo, env map[string]string) ([]hooks.Result, error) {
+	lock, unlock, err := transaction.CreateLockSteps(volinfo.Name)
+	if err != nil {
+		return nil, err
+	}
+
 	txn := transaction.NewTxn(reqID)
 	defer txn.Cleanup()
 
@@ -73,12 +117,12 @@ func volumeOptionsHandler(w http.ResponseWriter, r *http.Request) {
 
 	allNodes, err := peer.GetPeerIDs()
 	if err != nil {
-		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
-		return
+		return nil, err
 	}
 
 	txn.Steps = []*transaction.Step{
 		lock,
+		hooks.PreStep(txn.Nodes),
 		{
 			DoFunc: "vol-option.UpdateVolinfo",
 			Nodes:  []uuid.UUID{gdctx.MyUUID},
@@ -96,28 +140,21 @@ func volumeOptionsHandler(w http.ResponseWriter, r *http.Request) {
 			DoFunc: "vol-option.NotifyVolfileChange",
 			Nodes:  allNodes,
 		},
+		hooks.PostStep(txn.Nodes),
 		unlock,
 	}
 
-	for k, v := range req.Options {
-		volinfo.Options[k] = v
-	}
-
 	if err := txn.Ctx.Set("volinfo", volinfo); err != nil {
-		logger.WithError(err).Error("failed to set volinfo in transaction context")
-		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
-		return
+		return nil, err
 	}
 
-	if _, err := txn.Do(); err != nil {
-		logger.WithError(err).Error("volume option transaction failed")
-		if err == transaction.ErrLockTimeout {
-			restutils.SendHTTPError(w, http.StatusConflict, err.Error())
-		} else {
-			restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
-		}
-		return
+	if err := hooks.SetTxnCtx(txn.Ctx, hooks.OpSet, env); err != nil {
+		return nil, err
 	}
 
-	restutils.SendHTTPResponse(w, http.StatusOK, volinfo.Options)
+	rtxn, err := txn.Do()
+	if err != nil {
+		return nil, err
+	}
+	return hooks.PostFailures(rtxn, txn.Nodes), nil
 }
@@ -0,0 +1,180 @@
+package volumecommands
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gluster/glusterd2/errors"
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/transaction"
+	"github.com/gluster/glusterd2/utils"
+	"github.com/gluster/glusterd2/validation"
+	"github.com/gluster/glusterd2/volume"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gorilla/mux"
+)
+
+// ProfileReq represents a request to create or replace a custom option
+// profile
+type ProfileReq struct {
+	Description string            `json:"description,omitempty"`
+	Options     map[string]string `json:"options"`
+}
+
+// VolProfileReq represents a request to apply an option profile to a volume
+type VolProfileReq struct {
+	Profile string `json:"profile"`
+}
+
+func profileListHandler(w http.ResponseWriter, r *http.Request) {
+	profiles, err := volume.GetProfiles()
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	restutils.SendHTTPResponse(w, http.StatusOK, profiles)
+}
+
+func profileGetHandler(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["profile"]
+
+	p, err := volume.GetProfile(name)
+	if err == errors.ErrProfileNotFound {
+		restutils.SendHTTPError(w, http.StatusNotFound, err.Error())
+		return
+	} else if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	restutils.SendHTTPResponse(w, http.StatusOK, p)
+}
+
+func profileSetHandler(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["profile"]
+	_, logger := restutils.GetReqIDandLogger(r)
+
+	var req ProfileReq
+	if err := utils.GetJSONFromRequest(r, &req); err != nil {
+		restutils.SendHTTPError(w, http.StatusUnprocessableEntity, errors.ErrJSONParsingFailed.Error())
+		return
+	}
+
+	var errs validation.Errors
+	errs.Name("profile", name)
+	errs.RequireList("options", len(req.Options))
+	if errs != nil {
+		restutils.SendValidationErrors(w, errs)
+		return
+	}
+
+	if err := areOptionNamesValid(req.Options); err != nil {
+		logger.WithField("option", err.Error()).Error("invalid option specified")
+		restutils.SendHTTPError(w, http.StatusBadRequest, fmt.Sprintf("invalid option specified: %s", err.Error()))
+		return
+	}
+
+	p := &volume.OptionProfile{
+		Name:        name,
+		Description: req.Description,
+		Options:     req.Options,
+	}
+	if err := volume.AddOrUpdateProfile(p); err == errors.ErrProfileBuiltin {
+		restutils.SendHTTPError(w, http.StatusForbidden, err.Error())
+		return
+	} else if err != nil {
+		logger.WithError(err).WithField("profile", name).Error("failed to save option profile")
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	logger.WithField("profile", name).Info("option profile saved")
+	restutils.SendHTTPResponse(w, http.StatusOK, p)
+}
+
+func profileDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["profile"]
+	_, logger := restutils.GetReqIDandLogger(r)
+
+	switch err := volume.DeleteProfile(name); err {
+	case nil:
+	case errors.ErrProfileBuiltin:
+		restutils.SendHTTPError(w, http.StatusForbidden, err.Error())
+		return
+	case errors.ErrProfileNotFound:
+		restutils.SendHTTPError(w, http.StatusNotFound, err.Error())
+		return
+	default:
+		logger.WithError(err).WithField("profile", name).Error("failed to delete option profile")
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	logger.WithField("profile", name).Info("option profile deleted")
+	restutils.SendHTTPResponse(w, http.StatusOK, nil)
+}
+
+// volumeProfileHandler sets all the options of a profile on a volume, in a
+// single transaction
+func volumeProfileHandler(w http.ResponseWriter, r *http.Request) {
+	volname := mux.Vars(r)["volname"]
+	reqID, logger := restutils.GetReqIDandLogger(r)
+
+	volinfo, err := volume.GetVolume(volname)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusNotFound, errors.ErrVolNotFound.Error())
+		return
+	}
+
+	var req VolProfileReq
+	if err := utils.GetJSONFromRequest(r, &req); err != nil {
+		restutils.SendHTTPError(w, http.StatusUnprocessableEntity, errors.ErrJSONParsingFailed.Error())
+		return
+	}
+
+	var errs validation.Errors
+	errs.RequireString("profile", req.Profile)
+	if errs != nil {
+		restutils.SendValidationErrors(w, errs)
+		return
+	}
+
+	p, err := volume.GetProfile(req.Profile)
+	if err == errors.ErrProfileNotFound {
+		restutils.SendHTTPError(w, http.StatusNotFound, err.Error())
+		return
+	} else if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// Options of custom profiles are validated when they are saved, but
+	// the xlators installed may have changed since
+	if err := areOptionNamesValid(p.Options); err != nil {
+		logger.WithField("option", err.Error()).Error("invalid option in profile")
+		restutils.SendHTTPError(w, http.StatusBadRequest, fmt.Sprintf("invalid option in profile %s: %s", p.Name, err.Error()))
+		return
+	}
+
+	failed, err := setVolumeOptions(reqID, volinfo, p.Options)
+	if err != nil {
+		logger.WithError(err).WithField("profile", p.Name).Error("volume profile transaction failed")
+		if err == transaction.ErrLockTimeout {
+			restutils.SendHTTPError(w, http.StatusConflict, err.Error())
+		} else if transaction.IsTimeout(err) {
+			restutils.SendHTTPError(w, http.StatusGatewayTimeout, err.Error())
+		} else {
+			restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	logHookFailures(logger, failed)
+	setHookFailuresHeader(w, failed)
+
+	logger.WithFields(log.Fields{
+		"volume":  volinfo.Name,
+		"profile": p.Name,
+	}).Info("option profile applied to volume")
+	restutils.SendHTTPResponse(w, http.StatusOK, volinfo.Options)
+}
@@ -0,0 +1,984 @@
+package volumecommands
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/gdctx"
+	"github.com/gluster/glusterd2/peer"
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/snapshot"
+	"github.com/gluster/glusterd2/store"
+	"github.com/gluster/glusterd2/transaction"
+	"github.com/gluster/glusterd2/utils"
+	"github.com/gluster/glusterd2/validation"
+	"github.com/gluster/glusterd2/volgen"
+	"github.com/gluster/glusterd2/volume"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gorilla/mux"
+	"github.com/pborman/uuid"
+	config "github.com/spf13/viper"
+)
+
+const (
+	brickRootTxnKey string = "brickroot"
+
+	// provisionLockKey is locked by the transactions reserving capacity on
+	// the brick roots, so that the same capacity is never promised to two
+	// volumes. It isn't a valid volume name, so it can't clash with the
+	// lock of a volume.
+	provisionLockKey = "provisioner/capacity"
+
+	// provisionFSType is the filesystem the bricks of provisioned volumes
+	// are formatted with
+	provisionFSType = "xfs"
+
+	// defaultProvisionReplica is the replica count of provisioned volumes
+	// if none is requested
+	defaultProvisionReplica = 3
+)
+
+// ProvisionReq represents a request to provision a volume of the given size.
+// The bricks of the volume are placed by GlusterD on the nodes with the most
+// available capacity, and every brick is a thin logical volume of the size of
+// the volume, so the size is enforced. Requests are idempotent, a volume which
+// was already provisioned with the same name, size and replica count is
+// returned as is. Clients can also retry requests with an Idempotency-Key.
+//
+// PeerMetaData restricts the bricks to the peers having all the given
+// metadata, and SpreadBy names a metadata key, like zone or rack, whose value
+// must differ between the peers of the bricks.
+type ProvisionReq struct {
+	Name         string            `json:"name"`
+	Size         uint64            `json:"size"`
+	Replica      int               `json:"replica,omitempty"`
+	Options      map[string]string `json:"options,omitempty"`
+	PeerMetaData map[string]string `json:"peer-metadata,omitempty"`
+	SpreadBy     string            `json:"spread-by,omitempty"`
+}
+
+// ProvisionExpandReq represents a request to grow a provisioned volume
+type ProvisionExpandReq struct {
+	Size uint64 `json:"size"`
+}
+
+// BrickRoot represents the directory bricks are provisioned under on a node,
+// and the capacity available to new bricks in the thin pool of the node
+type BrickRoot struct {
+	NodeID uuid.UUID `json:"node-id"`
+	Path   string    `json:"path"`
+	// Pool is the thin pool, as vg/pool, the logical volumes of the
+	// bricks are created in
+	Pool string `json:"pool"`
+	SizeInfo
+	// Reserved is the capacity promised to provisioned volumes with
+	// bricks on the node
+	Reserved  uint64 `json:"reserved"`
+	Available uint64 `json:"available"`
+}
+
+// ProvisionCapacityResp is the response sent for a provisioner capacity
+// request
+type ProvisionCapacityResp struct {
+	// MaxSize holds the size of the largest volume which can be
+	// provisioned with each replica count, starting with replica 1
+	MaxSize []uint64    `json:"max-size"`
+	Nodes   []BrickRoot `json:"nodes"`
+}
+
+// getBrickRoot returns the brick root of this node, with the size of its thin
+// pool. Nodes without both a brick root and a brick pool configured have no
+// brick root.
+func getBrickRoot(c transaction.TxnCtx) error {
+	root := BrickRoot{NodeID: gdctx.MyUUID}
+
+	dir, pool := config.GetString("brickroot"), config.GetString("brickpool")
+	if dir != "" && pool != "" {
+		lv, err := snapshot.ParseThinPool(pool)
+		if err != nil {
+			return err
+		}
+		// The brick root is created along with the first brick under
+		// it, so it may not exist yet
+		if fi, err := os.Stat(dir); err == nil && !fi.IsDir() {
+			err = fmt.Errorf("%s is not a directory", dir)
+			c.Logger().WithError(err).WithField("brickroot", dir).Error("brick root is not usable")
+			return err
+		}
+		total, used, err := snapshot.ThinPoolUsage(lv)
+		if err != nil {
+			c.Logger().WithError(err).WithField("brickpool", pool).Error("brick pool is not usable")
+			return err
+		}
+		root.Path = dir
+		root.Pool = pool
+		root.SizeInfo = SizeInfo{Total: total, Used: used, Free: total - used}
+	}
+
+	return c.SetNodeResult(gdctx.MyUUID, brickRootTxnKey, &root)
+}
+
+// checkCapacity checks that the brick roots picked for the bricks of a volume
+// still have the capacity needed by the bricks available. It runs with the
+// provisioner lock held, so the capacity reserved by other volumes can't
+// change until the volume is stored.
+func checkCapacity(c transaction.TxnCtx) error {
+	var roots []BrickRoot
+	if err := c.Get("roots", &roots); err != nil {
+		return err
+	}
+	var size uint64
+	if err := c.Get("size", &size); err != nil {
+		return err
+	}
+
+	reserved, err := reservedCapacity()
+	if err != nil {
+		return err
+	}
+	if root := rootWithoutCapacity(roots, reserved, size); root != nil {
+		c.Logger().WithFields(log.Fields{
+			"node":      root.NodeID,
+			"available": root.Available,
+			"needed":    size,
+		}).Error("brick root has not enough capacity left")
+		return errors.ErrNoCapacity
+	}
+	return nil
+}
+
+// rootWithoutCapacity returns the first of the roots which hasn't size bytes
+// available, given the capacity reserved on every node, or nil if all of them
+// have
+func rootWithoutCapacity(roots []BrickRoot, reserved map[string]uint64, size uint64) *BrickRoot {
+	for i := range roots {
+		root := &roots[i]
+		root.setAvailable(reserved[root.NodeID.String()])
+		if root.Available < size {
+			return root
+		}
+	}
+	return nil
+}
+
+// createProvisionedBricks creates a thin logical volume of the size of the
+// volume for every local brick of a provisioned volume, and mounts it at the
+// parent directory of the brick
+func createProvisionedBricks(c transaction.TxnCtx) error {
+	var vol volume.Volinfo
+	if err := c.Get("volinfo", &vol); err != nil {
+		return err
+	}
+	var clone snapshot.Clone
+	if err := c.Get("clone", &clone); err != nil {
+		return err
+	}
+
+	pool, err := snapshot.ParseThinPool(config.GetString("brickpool"))
+	if err != nil {
+		return err
+	}
+
+	for _, cb := range clone.Bricks {
+		if !uuid.Equal(cb.NodeID, gdctx.MyUUID) {
+			continue
+		}
+
+		dir := config.GetString("brickroot")
+		if err := utils.InitDir(dir); err != nil {
+			c.Logger().WithError(err).WithField("brickroot", dir).Error("brick root is not usable")
+			return err
+		}
+
+		lv := &snapshot.LV{VG: cb.VG, Name: cb.LV}
+		c.Logger().WithFields(log.Fields{
+			"volume": vol.Name,
+			"lv":     cb.VG + "/" + cb.LV,
+			"size":   vol.Capacity,
+		}).Info("creating brick of provisioned volume")
+
+		if !snapshot.LVExists(lv.VG, lv.Name) {
+			if err := snapshot.CreateThinLV(pool, lv.Name, vol.Capacity); err != nil {
+				return err
+			}
+			if err := snapshot.MakeFS(lv); err != nil {
+				return err
+			}
+		}
+		if err := snapshot.Mount(lv, cb.MountPoint, cb.FSType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extendProvisionedBricks grows the logical volumes of the local bricks of a
+// provisioned volume, and their filesystems, to the size of the volume. XFS
+// can't be shrunk, so this isn't undone.
+func extendProvisionedBricks(c transaction.TxnCtx) error {
+	var vol volume.Volinfo
+	if err := c.Get("volinfo", &vol); err != nil {
+		return err
+	}
+	var clone snapshot.Clone
+	if err := c.Get("clone", &clone); err != nil {
+		return err
+	}
+
+	for _, cb := range clone.Bricks {
+		if !uuid.Equal(cb.NodeID, gdctx.MyUUID) {
+			continue
+		}
+
+		c.Logger().WithFields(log.Fields{
+			"volume": vol.Name,
+			"lv":     cb.VG + "/" + cb.LV,
+			"size":   vol.Capacity,
+		}).Info("extending brick of provisioned volume")
+
+		if err := snapshot.ExtendLV(cb.VG, cb.LV, vol.Capacity); err != nil {
+			return err
+		}
+		if err := snapshot.GrowFS(cb.MountPoint); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prepareExpand reads the provisioned volume with the capacity and volume
+// locks held, and sets the volinfo with the requested size, along with the
+// additional capacity the bricks need. A volume already grown to the size by
+// another request keeps its capacity.
+func prepareExpand(c transaction.TxnCtx) error {
+	var volname string
+	if err := c.Get("volname", &volname); err != nil {
+		return err
+	}
+	var size uint64
+	if err := c.Get("newsize", &size); err != nil {
+		return err
+	}
+
+	vol, err := volume.GetVolume(volname)
+	if err != nil {
+		return err
+	}
+	if vol.Capacity == 0 {
+		return errors.ErrVolNotProvisioned
+	}
+
+	var extra uint64
+	if size > vol.Capacity {
+		extra = size - vol.Capacity
+		vol.Capacity = size
+	}
+	if err := c.Set("size", extra); err != nil {
+		return err
+	}
+	return c.Set("volinfo", vol)
+}
+
+func markVolumeStarted(c transaction.TxnCtx) error {
+	var volname string
+	if err := c.Get("volname", &volname); err != nil {
+		return err
+	}
+
+	vol, err := volume.GetVolume(volname)
+	if err != nil {
+		return err
+	}
+	vol.Status = volume.VolStarted
+	return volume.AddOrUpdateVolumeFunc(vol)
+}
+
+func unstoreVolume(c transaction.TxnCtx) error {
+	var volinfo volume.Volinfo
+	if err := c.Get("volinfo", &volinfo); err != nil {
+		return err
+	}
+
+	if err := volgen.DeleteClientVolfile(&volinfo); err != nil {
+		c.Logger().WithError(err).WithField("volume", volinfo.Name).Warn("failed to delete client volfile")
+	}
+	return volume.DeleteVolume(volinfo.Name)
+}
+
+func registerVolProvisionStepFuncs() {
+	var sfs = []struct {
+		name string
+		sf   transaction.StepFunc
+	}{
+		{"vol-provision.BrickRoot", getBrickRoot},
+		{"vol-provision.MarkStarted", markVolumeStarted},
+		{"vol-provision.Unstore", unstoreVolume},
+		{"vol-provision.PrepareExpand", prepareExpand},
+		{"vol-provision.CheckCapacity", checkCapacity},
+		{"vol-provision.CreateBricks", createProvisionedBricks},
+		{"vol-provision.ExtendBricks", extendProvisionedBricks},
+		{"vol-provision.Store", storeVolume},
+	}
+	for _, sf := range sfs {
+		transaction.RegisterStepFunc(sf.sf, sf.name)
+	}
+}
+
+// provisionerNodes returns the peers which are online, not in maintenance
+// and have all the metadata in filter, which can have bricks provisioned on
+// them
+func provisionerNodes(filter map[string]string) ([]uuid.UUID, error) {
+	peers, err := peer.GetPeersF()
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []uuid.UUID
+	for _, p := range peers {
+		if p.Maintenance || !store.Store.IsNodeAlive(p.ID) || !p.MatchesMetaData(filter) {
+			continue
+		}
+		nodes = append(nodes, p.ID)
+	}
+	return nodes, nil
+}
+
+// peerDomains returns the value of the metadata key of every peer, by peer ID
+func peerDomains(key string) (map[string]string, error) {
+	peers, err := peer.GetPeersF()
+	if err != nil {
+		return nil, err
+	}
+
+	domains := make(map[string]string, len(peers))
+	for _, p := range peers {
+		domains[p.ID.String()] = p.MetaData[key]
+	}
+	return domains, nil
+}
+
+// reservedCapacity returns the capacity promised to provisioned volumes on
+// each node
+func reservedCapacity() (map[string]uint64, error) {
+	vols, err := volume.GetVolumes()
+	if err != nil {
+		return nil, err
+	}
+	return reservations(vols), nil
+}
+
+// reservations returns the capacity promised to the given provisioned volumes
+// on each node. Every brick of a provisioned volume holds a full copy of it.
+func reservations(vols []volume.Volinfo) map[string]uint64 {
+	reserved := make(map[string]uint64)
+	for _, v := range vols {
+		if v.Capacity == 0 {
+			continue
+		}
+		for _, b := range v.Bricks {
+			reserved[b.NodeID.String()] += v.Capacity
+		}
+	}
+	return reserved
+}
+
+// getBrickRoots returns the brick roots of the given nodes, with the capacity
+// available under them. Nodes without a brick root are left out.
+func getBrickRoots(reqID string, nodes []uuid.UUID) ([]BrickRoot, error) {
+	reserved, err := reservedCapacity()
+	if err != nil {
+		return nil, err
+	}
+
+	txn := transaction.NewTxn(reqID)
+	defer txn.Cleanup()
+	txn.Nodes = nodes
+	txn.Steps = []*transaction.Step{
+		{
+			DoFunc: "vol-provision.BrickRoot",
+			Nodes:  txn.Nodes,
+		},
+	}
+
+	rtxn, err := txn.Do()
+	if err != nil {
+		return nil, err
+	}
+
+	var roots []BrickRoot
+	for _, node := range nodes {
+		var root BrickRoot
+		if err := rtxn.GetNodeResult(node, brickRootTxnKey, &root); err != nil {
+			return nil, err
+		}
+		if root.Path == "" {
+			continue
+		}
+		root.setAvailable(reserved[node.String()])
+		roots = append(roots, root)
+	}
+
+	sort.Sort(rootsByAvailable(roots))
+	return roots, nil
+}
+
+// setAvailable sets the capacity of the root available to new bricks, given
+// the capacity already reserved on it. Blocks of thin logical volumes are only
+// allocated from the pool when they are written to, so the capacity promised
+// to provisioned volumes isn't used up until then, but the pool is never
+// promised more than its size.
+func (r *BrickRoot) setAvailable(reserved uint64) {
+	r.Reserved = reserved
+	r.Available = r.Free
+	if reserved >= r.Total {
+		r.Available = 0
+	} else if r.Total-reserved < r.Available {
+		r.Available = r.Total - reserved
+	}
+}
+
+type rootsByAvailable []BrickRoot
+
+func (r rootsByAvailable) Len() int           { return len(r) }
+func (r rootsByAvailable) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+func (r rootsByAvailable) Less(i, j int) bool { return r[i].Available > r[j].Available }
+
+// peerHost returns the host of the first address of the peer, which bricks
+// on the peer are addressed with
+func peerHost(id uuid.UUID) (string, error) {
+	p, err := peer.GetPeerF(id.String())
+	if err != nil {
+		return "", err
+	}
+	if len(p.Addresses) == 0 {
+		return "", fmt.Errorf("peer %s has no addresses", id)
+	}
+	host, _, err := net.SplitHostPort(p.Addresses[0])
+	if err != nil {
+		return p.Addresses[0], nil
+	}
+	return host, nil
+}
+
+// pickRoots picks replica brick roots with at least size bytes available,
+// preferring the roots with the most available capacity. The roots must be
+// sorted by available capacity. If domains is not nil, no two of the picked
+// roots are on nodes in the same domain. Nil is returned if not enough roots
+// can be picked.
+func pickRoots(roots []BrickRoot, domains map[string]string, size uint64, replica int) []BrickRoot {
+	var picked []BrickRoot
+	used := make(map[string]bool)
+	for _, root := range roots {
+		if len(picked) == replica || root.Available < size {
+			break
+		}
+		if domains != nil {
+			domain := domains[root.NodeID.String()]
+			// Peers without the metadata key can't be told apart
+			if domain == "" || used[domain] {
+				continue
+			}
+			used[domain] = true
+		}
+		picked = append(picked, root)
+	}
+
+	if len(picked) < replica {
+		return nil
+	}
+	return picked
+}
+
+// placeBricks chooses the nodes for the bricks of a new volume, picking the
+// nodes with the most available capacity among those matching the placement
+// constraints of the request, and returns the picked brick roots along with
+// the bricks in host:path form. The capacity of the picked roots is checked
+// again with the provisioner lock held when the volume is created.
+func placeBricks(reqID string, req *ProvisionReq) ([]BrickRoot, []string, error) {
+	nodes, err := provisionerNodes(req.PeerMetaData)
+	if err != nil {
+		return nil, nil, err
+	}
+	roots, err := getBrickRoots(reqID, nodes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var domains map[string]string
+	if req.SpreadBy != "" {
+		if domains, err = peerDomains(req.SpreadBy); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	picked := pickRoots(roots, domains, req.Size, req.Replica)
+	if picked == nil {
+		return nil, nil, errors.ErrNoCapacity
+	}
+
+	var bricks []string
+	for _, root := range picked {
+		host, err := peerHost(root.NodeID)
+		if err != nil {
+			return nil, nil, err
+		}
+		bricks = append(bricks, host+":"+path.Join(root.Path, req.Name, "brick"))
+	}
+	return picked, bricks, nil
+}
+
+// provisionedBricks returns the record of the logical volumes of the bricks of
+// a provisioned volume, whose brick with index i is on roots[i]. Every brick
+// is a directory on a logical volume mounted at its parent directory.
+func provisionedBricks(vol *volume.Volinfo, roots []BrickRoot) (*snapshot.Clone, error) {
+	clone := &snapshot.Clone{Volume: vol.Name}
+	for i, b := range vol.Bricks {
+		pool, err := snapshot.ParseThinPool(roots[i].Pool)
+		if err != nil {
+			return nil, err
+		}
+		clone.Bricks = append(clone.Bricks, snapshot.CloneBrick{
+			NodeID:     b.NodeID,
+			VG:         pool.VG,
+			LV:         snapshot.BrickLVName(vol.ID, i),
+			FSType:     provisionFSType,
+			MountPoint: path.Dir(b.Path),
+		})
+	}
+	return clone, nil
+}
+
+// capacityLockSteps returns the steps locking the capacity of the brick roots
+// and the volume. The capacity is always locked first.
+func capacityLockSteps(volname string) ([]*transaction.Step, []*transaction.Step, error) {
+	capLock, capUnlock, err := transaction.CreateLockSteps(provisionLockKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	lock, unlock, err := transaction.CreateLockSteps(volname)
+	if err != nil {
+		return nil, nil, err
+	}
+	return []*transaction.Step{capLock, lock}, []*transaction.Step{unlock, capUnlock}, nil
+}
+
+// provisionErrStatus returns the HTTP status sent for an error of a
+// provisioner operation
+func provisionErrStatus(err error) int {
+	switch {
+	case err == errors.ErrNoCapacity:
+		return http.StatusServiceUnavailable
+	case err == errors.ErrVolNotProvisioned:
+		return http.StatusBadRequest
+	case err == transaction.ErrLockTimeout:
+		return http.StatusConflict
+	case transaction.IsTimeout(err):
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
+}
+
+func sendProvisionTxnError(w http.ResponseWriter, err error) {
+	restutils.SendHTTPError(w, provisionErrStatus(err), err.Error())
+}
+
+// provisionHandler creates and starts a volume of the requested size in a
+// single transaction
+func provisionHandler(w http.ResponseWriter, r *http.Request) {
+	reqID, logger := restutils.GetReqIDandLogger(r)
+
+	var req ProvisionReq
+	if err := utils.GetJSONFromRequest(r, &req); err != nil {
+		restutils.SendHTTPError(w, http.StatusUnprocessableEntity, errors.ErrJSONParsingFailed.Error())
+		return
+	}
+	if req.Replica == 0 {
+		req.Replica = defaultProvisionReplica
+	}
+
+	var errs validation.Errors
+	errs.VolumeName("name", req.Name)
+	if req.Size == 0 {
+		errs.Add("size", "size must be greater than 0")
+	}
+	if req.Replica < 1 {
+		errs.Add("replica", "replica count must be at least 1")
+	}
+	if errs != nil {
+		restutils.SendValidationErrors(w, errs)
+		return
+	}
+
+	if !validateOptions(w, logger, req.Options) {
+		return
+	}
+
+	if vol, err := volume.GetVolume(req.Name); err == nil {
+		// A retried request gets the same response as the request
+		// which provisioned the volume
+		if vol.Capacity == req.Size && vol.ReplicaCount == req.Replica {
+			restutils.SendHTTPResponse(w, http.StatusCreated, VolumeResp{Volinfo: vol})
+			return
+		}
+		restutils.SendHTTPError(w, http.StatusConflict, errors.ErrVolExists.Error())
+		return
+	}
+	if existing, ok := volume.ExistingName(req.Name); ok {
+		logger.WithField("existing", existing).Error(errors.ErrVolNameConflict.Error())
+		restutils.SendHTTPError(w, http.StatusConflict, errors.ErrVolNameConflict.Error())
+		return
+	}
+
+	vol, err := provisionVolume(reqID, &req)
+	if err != nil {
+		logger.WithError(err).WithField("volume", req.Name).Error("volume provision transaction failed")
+		sendProvisionTxnError(w, err)
+		return
+	}
+
+	logger.WithFields(log.Fields{
+		"volume": vol.Name,
+		"size":   vol.Capacity,
+	}).Info("volume provisioned")
+	restutils.SendHTTPResponse(w, http.StatusCreated, VolumeResp{Volinfo: vol})
+}
+
+// provisionVolume places the bricks of the requested volume, and creates and
+// starts it in a single transaction. The volume as stored is returned.
+func provisionVolume(reqID string, req *ProvisionReq) (*volume.Volinfo, error) {
+	roots, bricks, err := placeBricks(reqID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	createReq := &VolCreateRequest{
+		Name:         req.Name,
+		ReplicaCount: req.Replica,
+		Bricks:       bricks,
+		Options:      req.Options,
+	}
+	vol, err := createVolinfo(createReq)
+	if err != nil {
+		return nil, err
+	}
+	vol.Capacity = req.Size
+
+	clone, err := provisionedBricks(vol, roots)
+	if err != nil {
+		return nil, err
+	}
+
+	locks, unlocks, err := capacityLockSteps(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := vol.Nodes()
+	txn := transaction.NewTxn(reqID)
+	defer txn.Cleanup()
+	txn.Nodes = nodes
+	txn.Steps = append(locks,
+		&transaction.Step{
+			DoFunc: "vol-provision.CheckCapacity",
+			Nodes:  []uuid.UUID{gdctx.MyUUID},
+		},
+		&transaction.Step{
+			DoFunc:   "vol-provision.CreateBricks",
+			UndoFunc: "snap-clone.Undo",
+			Nodes:    nodes,
+		},
+		&transaction.Step{
+			DoFunc: "vol-create.Stage",
+			Nodes:  nodes,
+		},
+		&transaction.Step{
+			DoFunc:   "vol-create.Commit",
+			UndoFunc: "vol-create.Rollback",
+			Nodes:    nodes,
+		},
+		&transaction.Step{
+			DoFunc:   "vol-create.Store",
+			UndoFunc: "vol-provision.Unstore",
+			Nodes:    []uuid.UUID{gdctx.MyUUID},
+		},
+		&transaction.Step{
+			DoFunc:   "snap-clone.Store",
+			UndoFunc: "snap-clone.Unstore",
+			Nodes:    []uuid.UUID{gdctx.MyUUID},
+		},
+		&transaction.Step{
+			DoFunc:   "vol-start.Commit",
+			UndoFunc: "vol-start.Undo",
+			Nodes:    nodes,
+		},
+		&transaction.Step{
+			DoFunc: "vol-provision.MarkStarted",
+			Nodes:  []uuid.UUID{gdctx.MyUUID},
+		})
+	txn.Steps = append(txn.Steps, unlocks...)
+
+	if err := txn.Ctx.Set("req", createReq); err != nil {
+		return nil, err
+	}
+	if err := txn.Ctx.Set("volinfo", vol); err != nil {
+		return nil, err
+	}
+	if err := txn.Ctx.Set("volname", vol.Name); err != nil {
+		return nil, err
+	}
+	if err := txn.Ctx.Set("clone", clone); err != nil {
+		return nil, err
+	}
+	if err := txn.Ctx.Set("roots", roots); err != nil {
+		return nil, err
+	}
+	if err := txn.Ctx.Set("size", req.Size); err != nil {
+		return nil, err
+	}
+
+	if _, err := txn.Do(); err != nil {
+		return nil, err
+	}
+
+	return volume.GetVolume(req.Name)
+}
+
+// provisionExpandHandler grows a provisioned volume, if all the nodes with
+// its bricks have the additional capacity available
+func provisionExpandHandler(w http.ResponseWriter, r *http.Request) {
+	volname := mux.Vars(r)["volname"]
+	reqID, logger := restutils.GetReqIDandLogger(r)
+
+	var req ProvisionExpandReq
+	if err := utils.GetJSONFromRequest(r, &req); err != nil {
+		restutils.SendHTTPError(w, http.StatusUnprocessableEntity, errors.ErrJSONParsingFailed.Error())
+		return
+	}
+
+	vol, err := volume.GetVolume(volname)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusNotFound, errors.ErrVolNotFound.Error())
+		return
+	}
+	if vol.Capacity == 0 {
+		restutils.SendHTTPError(w, http.StatusBadRequest, errors.ErrVolNotProvisioned.Error())
+		return
+	}
+	if req.Size < vol.Capacity {
+		restutils.SendHTTPError(w, http.StatusBadRequest, "provisioned volumes cannot be shrunk")
+		return
+	}
+	if req.Size == vol.Capacity {
+		restutils.SendHTTPResponse(w, http.StatusOK, VolumeResp{Volinfo: vol})
+		return
+	}
+
+	if err := expandProvisionedVolume(reqID, vol, req.Size); err != nil {
+		logger.WithError(err).WithField("volume", volname).Error("volume expand transaction failed")
+		sendProvisionTxnError(w, err)
+		return
+	}
+
+	if vol, err = volume.GetVolume(volname); err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	restutils.SendHTTPResponse(w, http.StatusOK, VolumeResp{Volinfo: vol})
+}
+
+// expandProvisionedVolume grows the provisioned volume and the logical volumes
+// of its bricks to the given size, if all the nodes with its bricks have the
+// additional capacity available. The volume is read again once it is locked,
+// so the capacity it is grown from may have changed from that of vol.
+func expandProvisionedVolume(reqID string, vol *volume.Volinfo, size uint64) error {
+	clone, err := snapshot.GetClone(vol.Name)
+	if err != nil {
+		return err
+	}
+	if clone == nil {
+		return errors.ErrVolNotProvisioned
+	}
+
+	roots, err := getBrickRoots(reqID, vol.Nodes())
+	if err != nil {
+		return err
+	}
+	if len(roots) != len(vol.Nodes()) {
+		return errors.ErrNoCapacity
+	}
+	for _, root := range roots {
+		if root.Available < size-vol.Capacity {
+			return errors.ErrNoCapacity
+		}
+	}
+
+	locks, unlocks, err := capacityLockSteps(vol.Name)
+	if err != nil {
+		return err
+	}
+
+	txn := transaction.NewTxn(reqID)
+	defer txn.Cleanup()
+	txn.Nodes = vol.Nodes()
+	txn.Steps = append(locks,
+		&transaction.Step{
+			DoFunc: "vol-provision.PrepareExpand",
+			Nodes:  []uuid.UUID{gdctx.MyUUID},
+		},
+		&transaction.Step{
+			DoFunc: "vol-provision.CheckCapacity",
+			Nodes:  []uuid.UUID{gdctx.MyUUID},
+		},
+		&transaction.Step{
+			DoFunc: "vol-provision.ExtendBricks",
+			Nodes:  txn.Nodes,
+		},
+		&transaction.Step{
+			DoFunc: "vol-provision.Store",
+			Nodes:  []uuid.UUID{gdctx.MyUUID},
+		})
+	txn.Steps = append(txn.Steps, unlocks...)
+
+	// The capacity already reserved for the volume is counted as
+	// reserved on the roots, so only the additional capacity is checked
+	if err := txn.Ctx.Set("roots", roots); err != nil {
+		return err
+	}
+	if err := txn.Ctx.Set("clone", clone); err != nil {
+		return err
+	}
+	if err := txn.Ctx.Set("volname", vol.Name); err != nil {
+		return err
+	}
+	if err := txn.Ctx.Set("newsize", size); err != nil {
+		return err
+	}
+
+	_, err = txn.Do()
+	return err
+}
+
+// provisionDeleteHandler stops and deletes a provisioned volume, and removes
+// the logical volumes of its bricks. Deleting a volume which doesn't exist
+// succeeds, so that requests can be retried.
+func provisionDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	volname := mux.Vars(r)["volname"]
+	reqID, logger := restutils.GetReqIDandLogger(r)
+
+	vol, err := volume.GetVolume(volname)
+	if err != nil {
+		restutils.SendHTTPResponse(w, http.StatusOK, nil)
+		return
+	}
+	if vol.Capacity == 0 {
+		restutils.SendHTTPError(w, http.StatusBadRequest, errors.ErrVolNotProvisioned.Error())
+		return
+	}
+
+	snaps, err := snapshot.GetSnapshots(volname)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if len(snaps) != 0 {
+		restutils.SendHTTPError(w, http.StatusConflict, errors.ErrVolHasSnapshots.Error())
+		return
+	}
+
+	clone, err := snapshot.GetClone(volname)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	lock, unlock, err := transaction.CreateLockSteps(volname)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	txn := transaction.NewTxn(reqID)
+	defer txn.Cleanup()
+	txn.Nodes = vol.Nodes()
+	txn.Steps = []*transaction.Step{lock}
+	if vol.Status == volume.VolStarted {
+		txn.Steps = append(txn.Steps, &transaction.Step{
+			DoFunc: "vol-stop.Commit",
+			Nodes:  txn.Nodes,
+		})
+	}
+	txn.Steps = append(txn.Steps, &transaction.Step{
+		DoFunc: "vol-delete.Commit",
+		Nodes:  txn.Nodes,
+	})
+	if clone != nil {
+		txn.Steps = append(txn.Steps, &transaction.Step{
+			DoFunc: "snap-clone.Remove",
+			Nodes:  txn.Nodes,
+		})
+	}
+	txn.Steps = append(txn.Steps, &transaction.Step{
+		DoFunc: "vol-delete.Store",
+		Nodes:  []uuid.UUID{gdctx.MyUUID},
+	})
+	if clone != nil {
+		txn.Steps = append(txn.Steps, &transaction.Step{
+			DoFunc: "snap-clone.Unstore",
+			Nodes:  []uuid.UUID{gdctx.MyUUID},
+		})
+		if err := txn.Ctx.Set("clone", clone); err != nil {
+			restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	txn.Steps = append(txn.Steps, unlock)
+
+	if err := txn.Ctx.Set("volname", volname); err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if _, err := txn.Do(); err != nil {
+		logger.WithError(err).WithField("volume", volname).Error("volume deprovision transaction failed")
+		sendProvisionTxnError(w, err)
+		return
+	}
+
+	logger.WithField("volume", volname).Info("provisioned volume deleted")
+	restutils.SendHTTPResponse(w, http.StatusOK, nil)
+}
+
+// provisionCapacityHandler returns the capacity available to provision
+// volumes with
+func provisionCapacityHandler(w http.ResponseWriter, r *http.Request) {
+	reqID, _ := restutils.GetReqIDandLogger(r)
+
+	nodes, err := provisionerNodes(nil)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	roots, err := getBrickRoots(reqID, nodes)
+	if err != nil {
+		sendProvisionTxnError(w, err)
+		return
+	}
+
+	// The roots are sorted by available capacity, so the largest volume
+	// with n replicas fits on the first n roots
+	resp := ProvisionCapacityResp{
+		MaxSize: make([]uint64, 0, len(roots)),
+		Nodes:   roots,
+	}
+	for _, root := range roots {
+		resp.MaxSize = append(resp.MaxSize, root.Available)
+	}
+
+	restutils.SendHTTPResponse(w, http.StatusOK, &resp)
+}
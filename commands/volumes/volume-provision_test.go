@@ -0,0 +1,136 @@
+package volumecommands
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gluster/glusterd2/brick"
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/snapshot"
+	"github.com/gluster/glusterd2/tests"
+	"github.com/gluster/glusterd2/transaction"
+	"github.com/gluster/glusterd2/volume"
+
+	"github.com/pborman/uuid"
+)
+
+func TestPickRoots(t *testing.T) {
+	n1, n2, n3, n4 := uuid.NewRandom(), uuid.NewRandom(), uuid.NewRandom(), uuid.NewRandom()
+	// Sorted by available capacity
+	roots := []BrickRoot{
+		{NodeID: n1, Available: 400},
+		{NodeID: n2, Available: 300},
+		{NodeID: n3, Available: 200},
+		{NodeID: n4, Available: 100},
+	}
+
+	picked := pickRoots(roots, nil, 150, 3)
+	tests.Assert(t, len(picked) == 3)
+	tests.Assert(t, uuid.Equal(picked[0].NodeID, n1))
+	tests.Assert(t, uuid.Equal(picked[2].NodeID, n3))
+
+	// Not enough roots with the capacity
+	tests.Assert(t, pickRoots(roots, nil, 250, 3) == nil)
+
+	// Bricks are spread over the domains, skipping larger roots in a
+	// domain already used
+	domains := map[string]string{
+		n1.String(): "z1",
+		n2.String(): "z1",
+		n3.String(): "z2",
+		n4.String(): "z3",
+	}
+	picked = pickRoots(roots, domains, 100, 3)
+	tests.Assert(t, len(picked) == 3)
+	tests.Assert(t, uuid.Equal(picked[0].NodeID, n1))
+	tests.Assert(t, uuid.Equal(picked[1].NodeID, n3))
+	tests.Assert(t, uuid.Equal(picked[2].NodeID, n4))
+
+	// Not enough domains
+	tests.Assert(t, pickRoots(roots, domains, 100, 4) == nil)
+
+	// Peers without the metadata key are not used
+	delete(domains, n4.String())
+	tests.Assert(t, pickRoots(roots, domains, 100, 3) == nil)
+}
+
+func TestSetAvailable(t *testing.T) {
+	root := BrickRoot{SizeInfo: SizeInfo{Total: 1000, Used: 100, Free: 900}}
+
+	root.setAvailable(0)
+	tests.Assert(t, root.Available == 900)
+
+	// The pool is never promised more than its size, even if the
+	// promised capacity isn't used yet
+	root.setAvailable(400)
+	tests.Assert(t, root.Reserved == 400 && root.Available == 600)
+
+	root.setAvailable(1000)
+	tests.Assert(t, root.Available == 0)
+	root.setAvailable(2000)
+	tests.Assert(t, root.Available == 0)
+}
+
+func TestReservations(t *testing.T) {
+	n1, n2 := uuid.NewRandom(), uuid.NewRandom()
+	vols := []volume.Volinfo{
+		{Name: "pv1", Capacity: 100, Bricks: []brick.Brickinfo{{NodeID: n1}, {NodeID: n2}}},
+		{Name: "pv2", Capacity: 50, Bricks: []brick.Brickinfo{{NodeID: n1}}},
+		// Volumes which weren't provisioned reserve nothing
+		{Name: "vol", Bricks: []brick.Brickinfo{{NodeID: n2}}},
+	}
+
+	reserved := reservations(vols)
+	tests.Assert(t, reserved[n1.String()] == 150)
+	tests.Assert(t, reserved[n2.String()] == 100)
+
+	roots := []BrickRoot{
+		{NodeID: n1, SizeInfo: SizeInfo{Total: 1000, Free: 1000}},
+		{NodeID: n2, SizeInfo: SizeInfo{Total: 300, Free: 300}},
+	}
+	tests.Assert(t, rootWithoutCapacity(roots, reserved, 200) == nil)
+
+	// Capacity reserved since the roots were picked is taken into
+	// account
+	reserved[n2.String()] += 100
+	root := rootWithoutCapacity(roots, reserved, 200)
+	tests.Assert(t, root != nil && uuid.Equal(root.NodeID, n2))
+	tests.Assert(t, root.Available == 100)
+}
+
+func TestProvisionedBricks(t *testing.T) {
+	n1, n2 := uuid.NewRandom(), uuid.NewRandom()
+	vol := &volume.Volinfo{
+		ID:   uuid.NewRandom(),
+		Name: "pv1",
+		Bricks: []brick.Brickinfo{
+			{NodeID: n1, Path: "/bricks/pv1/brick"},
+			{NodeID: n2, Path: "/srv/bricks/pv1/brick"},
+		},
+	}
+	roots := []BrickRoot{
+		{NodeID: n1, Path: "/bricks", Pool: "vg1/pool"},
+		{NodeID: n2, Path: "/srv/bricks", Pool: "vg2/pool"},
+	}
+
+	clone, err := provisionedBricks(vol, roots)
+	tests.Assert(t, err == nil)
+	tests.Assert(t, clone.Volume == "pv1" && clone.Snapshot == "")
+	tests.Assert(t, len(clone.Bricks) == 2)
+	tests.Assert(t, uuid.Equal(clone.Bricks[1].NodeID, n2))
+	tests.Assert(t, clone.Bricks[1].VG == "vg2")
+	tests.Assert(t, clone.Bricks[1].LV == snapshot.BrickLVName(vol.ID, 1))
+	tests.Assert(t, clone.Bricks[1].MountPoint == "/srv/bricks/pv1")
+	tests.Assert(t, clone.Bricks[0].FSType == "xfs")
+
+	roots[0].Pool = "pool"
+	_, err = provisionedBricks(vol, roots)
+	tests.Assert(t, err != nil)
+}
+
+func TestProvisionErrStatus(t *testing.T) {
+	tests.Assert(t, provisionErrStatus(errors.ErrNoCapacity) == http.StatusServiceUnavailable)
+	tests.Assert(t, provisionErrStatus(errors.ErrVolNotProvisioned) == http.StatusBadRequest)
+	tests.Assert(t, provisionErrStatus(transaction.ErrLockTimeout) == http.StatusConflict)
+	tests.Assert(t, provisionErrStatus(transaction.ErrTxnTimeout) == http.StatusGatewayTimeout)
+}
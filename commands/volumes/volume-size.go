@@ -0,0 +1,195 @@
+package volumecommands
+
+import (
+	"net/http"
+
+	"github.com/gluster/glusterd2/diskusage"
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/gdctx"
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/transaction"
+	"github.com/gluster/glusterd2/volume"
+
+	"github.com/gorilla/mux"
+	"github.com/pborman/uuid"
+)
+
+const brickSizeTxnKey string = "bricksizes"
+
+// SizeInfo represents the capacity of a brick or a volume, in bytes
+type SizeInfo struct {
+	Total uint64 `json:"total"`
+	Used  uint64 `json:"used"`
+	Free  uint64 `json:"free"`
+}
+
+// BrickSize represents the capacity of the filesystem of a brick
+type BrickSize struct {
+	Brick  string    `json:"brick"`
+	NodeID uuid.UUID `json:"node-id"`
+	SizeInfo
+}
+
+// VolSizeResp is the response sent for a volume size request. The size of the
+// volume is the logical capacity available to clients, which accounts for
+// replication and erasure coding.
+type VolSizeResp struct {
+	SizeInfo
+	Bricks []BrickSize `json:"bricks"`
+}
+
+// brickSize returns the capacity of the filesystem the given path is on. Free
+// is the space available to unprivileged users, as seen by clients.
+func brickSize(path string) (SizeInfo, error) {
+	st, err := diskusage.Stat(path)
+	if err != nil {
+		return SizeInfo{}, err
+	}
+	return SizeInfo{Total: st.Total, Used: st.Used, Free: st.Free}, nil
+}
+
+func getBrickSizes(c transaction.TxnCtx) error {
+	var volname string
+	if err := c.Get("volname", &volname); err != nil {
+		return err
+	}
+
+	vol, err := volume.GetVolume(volname)
+	if err != nil {
+		return err
+	}
+
+	var result []BrickSize
+	for _, b := range vol.Bricks {
+		if !uuid.Equal(b.NodeID, gdctx.MyUUID) {
+			continue
+		}
+
+		size, err := brickSize(b.Path)
+		if err != nil {
+			c.Logger().WithError(err).WithField("brick", b.Path).Error("failed to get size of brick")
+			return err
+		}
+		result = append(result, BrickSize{
+			Brick:    b.Hostname + ":" + b.Path,
+			NodeID:   b.NodeID,
+			SizeInfo: size,
+		})
+	}
+
+	return c.SetNodeResult(gdctx.MyUUID, brickSizeTxnKey, result)
+}
+
+func registerVolSizeStepFuncs() {
+	transaction.RegisterStepFunc(getBrickSizes, "vol-size.Get")
+}
+
+// volumeSize returns the logical capacity of the volume from the sizes of its
+// bricks, given in the order of the bricks of the volume. Each replica set
+// can only hold as much as its smallest brick, and the capacity of the
+// volume is that of all its replica sets. A disperse set of n bricks with a
+// redundancy of r holds (n - r)/n of the space of its n bricks, each used up
+// to the size of the smallest brick.
+func volumeSize(vol *volume.Volinfo, bricks []BrickSize) SizeInfo {
+	var size SizeInfo
+
+	setSize, dataBricks := vol.ReplicaCount, 1
+	if vol.Type == volume.Disperse || vol.Type == volume.DistDisperse {
+		setSize = vol.DisperseCount
+		dataBricks = vol.DisperseCount - vol.RedundancyCount
+	}
+	if setSize < 1 {
+		setSize = 1
+	}
+	if dataBricks < 1 {
+		dataBricks = 1
+	}
+
+	for i := 0; i < len(bricks); i += setSize {
+		end := i + setSize
+		if end > len(bricks) {
+			end = len(bricks)
+		}
+
+		set := bricks[i].SizeInfo
+		for _, b := range bricks[i+1 : end] {
+			if b.Total < set.Total {
+				set.Total = b.Total
+			}
+			if b.Free < set.Free {
+				set.Free = b.Free
+			}
+			if b.Used > set.Used {
+				set.Used = b.Used
+			}
+		}
+
+		size.Total += set.Total * uint64(dataBricks)
+		size.Used += set.Used * uint64(dataBricks)
+		size.Free += set.Free * uint64(dataBricks)
+	}
+
+	return size
+}
+
+func volumeSizeHandler(w http.ResponseWriter, r *http.Request) {
+	volname := mux.Vars(r)["volname"]
+	reqID, logger := restutils.GetReqIDandLogger(r)
+
+	vol, err := volume.GetVolumeCached(volname)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusNotFound, errors.ErrVolNotFound.Error())
+		return
+	}
+
+	// Getting the brick sizes does not modify any state, so no locks are
+	// needed.
+	txn := transaction.NewTxn(reqID)
+	defer txn.Cleanup()
+	txn.Nodes = vol.Nodes()
+	txn.Steps = []*transaction.Step{
+		{
+			DoFunc: "vol-size.Get",
+			Nodes:  txn.Nodes,
+		},
+	}
+	if err := txn.Ctx.Set("volname", volname); err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	rtxn, err := txn.Do()
+	if err != nil {
+		logger.WithError(err).WithField(
+			"volume", volname).Error("failed to get volume size")
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	sizes := make(map[string]BrickSize)
+	for _, node := range txn.Nodes {
+		var tmp []BrickSize
+		if err := rtxn.GetNodeResult(node, brickSizeTxnKey, &tmp); err != nil {
+			restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		for _, b := range tmp {
+			sizes[b.Brick] = b
+		}
+	}
+
+	// The bricks need to be in the order of the volume for the replica sets
+	// to be found
+	resp := VolSizeResp{Bricks: make([]BrickSize, 0, len(vol.Bricks))}
+	for _, b := range vol.Bricks {
+		size, ok := sizes[b.Hostname+":"+b.Path]
+		if !ok {
+			restutils.SendHTTPError(w, http.StatusInternalServerError, "size of brick "+b.Hostname+":"+b.Path+" not found")
+			return
+		}
+		resp.Bricks = append(resp.Bricks, size)
+	}
+	resp.SizeInfo = volumeSize(vol, resp.Bricks)
+
+	restutils.SendHTTPResponse(w, http.StatusOK, resp)
+}
@@ -0,0 +1,43 @@
+package volumecommands
+
+import (
+	"testing"
+
+	"github.com/gluster/glusterd2/tests"
+	"github.com/gluster/glusterd2/volume"
+)
+
+func brickSizes(sizes ...SizeInfo) []BrickSize {
+	bricks := make([]BrickSize, len(sizes))
+	for i, s := range sizes {
+		bricks[i].SizeInfo = s
+	}
+	return bricks
+}
+
+func TestVolumeSize(t *testing.T) {
+	small := SizeInfo{Total: 100, Used: 10, Free: 90}
+	large := SizeInfo{Total: 200, Used: 20, Free: 180}
+
+	// Distribute volumes hold all of their bricks
+	vol := &volume.Volinfo{Type: volume.Distribute, ReplicaCount: 1}
+	size := volumeSize(vol, brickSizes(small, large))
+	tests.Assert(t, size == SizeInfo{Total: 300, Used: 30, Free: 270})
+
+	// Replica sets hold as much as their smallest brick, and are as used
+	// as their most used brick
+	vol = &volume.Volinfo{Type: volume.DistReplicate, ReplicaCount: 2}
+	size = volumeSize(vol, brickSizes(small, large, large, large))
+	tests.Assert(t, size == SizeInfo{Total: 300, Used: 40, Free: 270})
+
+	// A disperse set of 6 bricks with a redundancy of 2 holds the data of
+	// 4 of its bricks
+	vol = &volume.Volinfo{Type: volume.Disperse, DisperseCount: 6, RedundancyCount: 2}
+	size = volumeSize(vol, brickSizes(small, large, large, large, large, large))
+	tests.Assert(t, size == SizeInfo{Total: 400, Used: 80, Free: 360})
+
+	vol.Type = volume.DistDisperse
+	size = volumeSize(vol, brickSizes(large, large, large, large, large, large,
+		small, small, small, small, small, small))
+	tests.Assert(t, size == SizeInfo{Total: 1200, Used: 120, Free: 1080})
+}
@@ -5,6 +5,9 @@ import (
 
 	"github.com/gluster/glusterd2/errors"
 	"github.com/gluster/glusterd2/gdctx"
+	"github.com/gluster/glusterd2/hooks"
+	"github.com/gluster/glusterd2/peer"
+	"github.com/gluster/glusterd2/quorum"
 	restutils "github.com/gluster/glusterd2/servers/rest/utils"
 	"github.com/gluster/glusterd2/transaction"
 	"github.com/gluster/glusterd2/volume"
@@ -31,6 +34,12 @@ func startAllBricks(c transaction.TxnCtx) error {
 			continue
 		}
 
+		// Bricks on peers in maintenance are started when the peer
+		// leaves maintenance
+		if peer.InMaintenance(b.NodeID.String()) {
+			continue
+		}
+
 		c.Logger().WithFields(log.Fields{
 			"volume": b.VolumeName,
 			"brick":  b.Hostname + ":" + b.Path,
@@ -88,6 +97,46 @@ func registerVolStartStepFuncs() {
 	transaction.RegisterStepFunc(stopAllBricks, "vol-start.Undo")
 }
 
+// startVolume starts the bricks of the volume on all its nodes, and marks the
+// volume as started. The hooks which failed after the bricks were started are
+// returned.
+func startVolume(reqID string, vol *volume.Volinfo) ([]hooks.Result, error) {
+	// A simple one-step transaction to start the brick processes
+	txn := transaction.NewTxn(reqID)
+	defer txn.Cleanup()
+	lock, unlock, err := transaction.CreateLockSteps(vol.Name)
+	if err != nil {
+		return nil, err
+	}
+	txn.Nodes = vol.Nodes()
+	txn.Steps = []*transaction.Step{
+		lock,
+		hooks.PreStep(txn.Nodes),
+		{
+			DoFunc:   "vol-start.Commit",
+			UndoFunc: "vol-start.Undo",
+			Nodes:    txn.Nodes,
+		},
+		hooks.PostStep(txn.Nodes),
+		unlock,
+	}
+	txn.Ctx.Set("volname", vol.Name)
+	hooks.SetTxnCtx(txn.Ctx, hooks.OpStart, hookEnv(vol))
+
+	rtxn, err := txn.Do()
+	if err != nil {
+		return nil, err
+	}
+
+	failed := hooks.PostFailures(rtxn, txn.Nodes)
+
+	vol.Status = volume.VolStarted
+	if err := volume.AddOrUpdateVolumeFunc(vol); err != nil {
+		return failed, err
+	}
+	return failed, nil
+}
+
 func volumeStartHandler(w http.ResponseWriter, r *http.Request) {
 	p := mux.Vars(r)
 	volname := p["volname"]
@@ -102,28 +151,12 @@ func volumeStartHandler(w http.ResponseWriter, r *http.Request) {
 		restutils.SendHTTPError(w, http.StatusBadRequest, errors.ErrVolAlreadyStarted.Error())
 		return
 	}
-
-	// A simple one-step transaction to start the brick processes
-	txn := transaction.NewTxn(reqID)
-	defer txn.Cleanup()
-	lock, unlock, err := transaction.CreateLockSteps(volname)
-	if err != nil {
-		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+	if !quorum.IsMet() {
+		restutils.SendHTTPError(w, http.StatusServiceUnavailable, errors.ErrQuorumNotMet.Error())
 		return
 	}
-	txn.Nodes = vol.Nodes()
-	txn.Steps = []*transaction.Step{
-		lock,
-		{
-			DoFunc:   "vol-start.Commit",
-			UndoFunc: "vol-start.Undo",
-			Nodes:    txn.Nodes,
-		},
-		unlock,
-	}
-	txn.Ctx.Set("volname", volname)
 
-	_, e = txn.Do()
+	failed, e := startVolume(reqID, vol)
 	if e != nil {
 		logger.WithFields(log.Fields{
 			"error":  e.Error(),
@@ -132,13 +165,7 @@ func volumeStartHandler(w http.ResponseWriter, r *http.Request) {
 		restutils.SendHTTPError(w, http.StatusInternalServerError, e.Error())
 		return
 	}
+	logHookFailures(logger, failed)
 
-	vol.Status = volume.VolStarted
-
-	e = volume.AddOrUpdateVolumeFunc(vol)
-	if e != nil {
-		restutils.SendHTTPError(w, http.StatusInternalServerError, e.Error())
-		return
-	}
-	restutils.SendHTTPResponse(w, http.StatusOK, vol)
+	restutils.SendHTTPResponse(w, http.StatusOK, VolumeResp{Volinfo: vol, HookFailures: failed})
 }
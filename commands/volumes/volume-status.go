@@ -5,7 +5,9 @@ import (
 	"net/http"
 
 	"github.com/gluster/glusterd2/brick"
+	"github.com/gluster/glusterd2/brickhealth"
 	"github.com/gluster/glusterd2/daemon"
+	"github.com/gluster/glusterd2/diskusage"
 	"github.com/gluster/glusterd2/errors"
 	"github.com/gluster/glusterd2/gdctx"
 	"github.com/gluster/glusterd2/pmap"
@@ -19,7 +21,8 @@ import (
 )
 
 const (
-	brickStatusTxnKey string = "brickstatuses"
+	brickStatusTxnKey   string = "brickstatuses"
+	serviceStatusTxnKey string = "servicestatuses"
 )
 
 func checkStatus(ctx transaction.TxnCtx) error {
@@ -42,6 +45,11 @@ func checkStatus(ctx transaction.TxnCtx) error {
 		return err
 	}
 
+	usage, err := diskusage.GetConfig()
+	if err != nil {
+		return err
+	}
+
 	var brickStatuses []*brick.Brickstatus
 
 	for _, binfo := range vol.Bricks {
@@ -69,11 +77,23 @@ func checkStatus(ctx transaction.TxnCtx) error {
 			}
 		}
 
+		// Bricks with a failed filesystem are offline, even if their
+		// process is still running
+		health := brickhealth.Get(binfo.Path)
+		if !health.Healthy {
+			online = false
+		}
+
 		brickStatus := &brick.Brickstatus{
-			BInfo:  binfo,
-			Online: online,
-			Pid:    pid,
-			Port:   port,
+			BInfo:       binfo,
+			Online:      online,
+			Pid:         pid,
+			Port:        port,
+			HealthError: health.Reason,
+		}
+		if used, err := diskusage.Used(binfo.Path); err == nil {
+			brickStatus.DiskUsage = used
+			brickStatus.DiskUsageLevel = usage.Level(used)
 		}
 		brickStatuses = append(brickStatuses, brickStatus)
 	}
@@ -81,6 +101,7 @@ func checkStatus(ctx transaction.TxnCtx) error {
 	// Store the results in transaction context. This will be consumed by
 	// the node that initiated the transaction.
 	ctx.SetNodeResult(gdctx.MyUUID, brickStatusTxnKey, brickStatuses)
+	ctx.SetNodeResult(gdctx.MyUUID, serviceStatusTxnKey, volume.ServiceStatuses(vol))
 
 	return nil
 }
@@ -91,6 +112,7 @@ func registerVolStatusStepFuncs() {
 
 func aggregateVolumeStatus(ctx transaction.TxnCtx, nodes []uuid.UUID) (*volume.VolStatus, error) {
 	var brickStatuses []brick.Brickstatus
+	var serviceStatuses []volume.ServiceStatus
 
 	// Loop over each node on which txn was run.
 	// Fetch brick statuses stored by each node in transaction context.
@@ -101,8 +123,22 @@ func aggregateVolumeStatus(ctx transaction.TxnCtx, nodes []uuid.UUID) (*volume.V
 			return nil, goerrors.New("aggregateVolumeStatus: Could not fetch results from transaction context.")
 		}
 		brickStatuses = append(brickStatuses, tmp...)
+
+		var services []volume.ServiceStatus
+		if err := ctx.GetNodeResult(node, serviceStatusTxnKey, &services); err == nil {
+			serviceStatuses = append(serviceStatuses, services...)
+		}
+	}
+	usage, err := diskusage.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	v := &volume.VolStatus{
+		Brickstatuses: brickStatuses,
+		Services:      serviceStatuses,
+		DiskReserve:   usage.Reserve,
 	}
-	v := &volume.VolStatus{Brickstatuses: brickStatuses}
 	return v, nil
 }
 
@@ -112,7 +148,7 @@ func volumeStatusHandler(w http.ResponseWriter, r *http.Request) {
 	reqID, logger := restutils.GetReqIDandLogger(r)
 
 	// Ensure that the volume exists.
-	vol, err := volume.GetVolume(volname)
+	vol, err := volume.GetVolumeCached(volname)
 	if err != nil {
 		restutils.SendHTTPError(w, http.StatusNotFound, errors.ErrVolNotFound.Error())
 		return
@@ -2,11 +2,13 @@ package volumecommands
 
 import (
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gluster/glusterd2/brick"
-	"github.com/gluster/glusterd2/daemon"
 	"github.com/gluster/glusterd2/errors"
 	"github.com/gluster/glusterd2/gdctx"
+	"github.com/gluster/glusterd2/hooks"
 	restutils "github.com/gluster/glusterd2/servers/rest/utils"
 	"github.com/gluster/glusterd2/transaction"
 	"github.com/gluster/glusterd2/volume"
@@ -16,6 +18,22 @@ import (
 	"github.com/pborman/uuid"
 )
 
+const (
+	brickStopTxnKey string = "brickstopresults"
+
+	// defaultBrickStopTimeout is the time given to bricks to exit
+	// gracefully before they are killed
+	defaultBrickStopTimeout = 30 * time.Second
+)
+
+// VolStopResp is the response sent for a volume stop request to version 2 of
+// the API. Version 1 responds with the volume only.
+type VolStopResp struct {
+	Volume       *volume.Volinfo   `json:"volume"`
+	Bricks       []BrickStopResult `json:"bricks"`
+	HookFailures []hooks.Result    `json:"hook-failures,omitempty"`
+}
+
 func stopBricks(c transaction.TxnCtx) error {
 
 	var volname string
@@ -25,6 +43,11 @@ func stopBricks(c transaction.TxnCtx) error {
 		return err
 	}
 
+	var timeout time.Duration
+	if err := c.Get("timeout", &timeout); err != nil {
+		timeout = defaultBrickStopTimeout
+	}
+
 	vol, err := volume.GetVolume(volname)
 	if err != nil {
 		c.Logger().WithError(err).WithField(
@@ -32,48 +55,178 @@ func stopBricks(c transaction.TxnCtx) error {
 		return err
 	}
 
+	var results []BrickStopResult
 	for _, b := range vol.Bricks {
-		if uuid.Equal(b.NodeID, gdctx.MyUUID) {
-
-			brickDaemon, err := brick.NewGlusterfsd(b)
-			if err != nil {
-				return err
-			}
-
-			brickname := b.Hostname + ":" + b.Path
-			c.Logger().WithFields(log.Fields{
-				"volume": volname, "brick": brickname}).Info("Stopping brick")
-
-			client, err := daemon.GetRPCClient(brickDaemon)
-			if err != nil {
-				c.Logger().WithError(err).WithField(
-					"brick", brickname).Error("failed to connect to brick, sending SIGTERM")
-				daemon.Stop(brickDaemon, false)
-				continue
-			}
-
-			req := &brick.GfBrickOpReq{
-				Name: b.Path,
-				Op:   brick.OpBrickTerminate,
-			}
-			var rsp brick.GfBrickOpRsp
-			err = client.Call("BrickOp", req, &rsp)
-			if err != nil || rsp.OpRet != 0 {
-				c.Logger().WithError(err).WithField(
-					"brick", brickname).Error("failed to send terminate RPC, sending SIGTERM")
-				daemon.Stop(brickDaemon, false)
-			}
+		if !uuid.Equal(b.NodeID, gdctx.MyUUID) {
+			continue
 		}
+
+		c.Logger().WithFields(log.Fields{
+			"volume": volname,
+			"brick":  b.Hostname + ":" + b.Path}).Info("Stopping brick")
+
+		results = append(results, gracefulStopBrick(b, timeout))
+	}
+
+	return c.SetNodeResult(gdctx.MyUUID, brickStopTxnKey, results)
+}
+
+// storeVolStopped marks the volume as stopped in the store. The volume is
+// read again under the volume lock, so that no concurrent change of the
+// volume is overwritten.
+func storeVolStopped(c transaction.TxnCtx) error {
+	var volname string
+	if err := c.Get("volname", &volname); err != nil {
+		return err
+	}
+
+	vol, err := volume.GetVolume(volname)
+	if err != nil {
+		return err
+	}
+	vol.Status = volume.VolStopped
+	if err := volume.AddOrUpdateVolumeFunc(vol); err != nil {
+		c.Logger().WithError(err).WithField(
+			"volume", volname).Error("failed to store volume info")
+		return err
 	}
 
-	return nil
+	return c.Set("volinfo", vol)
 }
 
 func registerVolStopStepFuncs() {
 	transaction.RegisterStepFunc(stopBricks, "vol-stop.Commit")
+	transaction.RegisterStepFunc(storeVolStopped, "vol-stop.Store")
+}
+
+// stopVolume stops the bricks of the volume on all its nodes, giving them the
+// timeout to exit gracefully, and marks the volume as stopped. The volume as
+// stored is returned in the response.
+func stopVolume(reqID string, vol *volume.Volinfo, timeout time.Duration, logger log.FieldLogger) (*VolStopResp, error) {
+	// A simple one-step transaction to stop brick processes
+	txn := transaction.NewTxn(reqID)
+	defer txn.Cleanup()
+	lock, unlock, err := transaction.CreateLockSteps(vol.Name)
+	if err != nil {
+		return nil, err
+	}
+	txn.Nodes = vol.Nodes()
+	txn.Steps = []*transaction.Step{
+		lock,
+		hooks.PreStep(txn.Nodes),
+		{
+			DoFunc:  "vol-stop.Commit",
+			Nodes:   txn.Nodes,
+			Timeout: stopStepTimeout(maxBricksPerNode(vol.Bricks), timeout),
+		},
+		{
+			DoFunc: "vol-stop.Store",
+			Nodes:  []uuid.UUID{gdctx.MyUUID},
+		},
+		hooks.PostStep(txn.Nodes),
+		unlock,
+	}
+	if err := txn.Ctx.Set("volname", vol.Name); err != nil {
+		return nil, err
+	}
+	if err := txn.Ctx.Set("timeout", timeout); err != nil {
+		return nil, err
+	}
+	if err := hooks.SetTxnCtx(txn.Ctx, hooks.OpStop, hookEnv(vol)); err != nil {
+		return nil, err
+	}
+
+	rtxn, err := txn.Do()
+	if err != nil {
+		return nil, err
+	}
+
+	failed := hooks.PostFailures(rtxn, txn.Nodes)
+
+	if err := rtxn.Get("volinfo", &vol); err != nil {
+		return nil, err
+	}
+
+	resp := &VolStopResp{Volume: vol, HookFailures: failed}
+	for _, node := range txn.Nodes {
+		var tmp []BrickStopResult
+		if err := rtxn.GetNodeResult(node, brickStopTxnKey, &tmp); err != nil {
+			logger.WithError(err).WithField(
+				"node", node.String()).Warn("failed to get brick stop results")
+			continue
+		}
+		resp.Bricks = append(resp.Bricks, tmp...)
+	}
+	return resp, nil
+}
+
+// maxBricksPerNode returns the highest number of the given bricks on a single
+// node
+func maxBricksPerNode(bricks []brick.Brickinfo) int {
+	count := make(map[string]int)
+	max := 0
+	for _, b := range bricks {
+		node := b.NodeID.String()
+		count[node]++
+		if count[node] > max {
+			max = count[node]
+		}
+	}
+	return max
+}
+
+// stopStepTimeout returns the time a step stopping the given number of
+// bricks on every node is allowed to run. The bricks of a node are stopped
+// one after another, each given the timeout to exit gracefully before it is
+// killed.
+func stopStepTimeout(bricks int, timeout time.Duration) time.Duration {
+	return time.Duration(bricks)*timeout + transaction.DefaultStepTimeout
+}
+
+// stopTimeout returns the time given to bricks to exit gracefully, in
+// seconds in the timeout query parameter of the request
+func stopTimeout(r *http.Request) (time.Duration, error) {
+	t := r.URL.Query().Get("timeout")
+	if t == "" {
+		return defaultBrickStopTimeout, nil
+	}
+	secs, err := strconv.Atoi(t)
+	if err != nil || secs < 0 {
+		return 0, errors.ErrInvalidStopTimeout
+	}
+	return time.Duration(secs) * time.Second, nil
+}
+
+// stopErrStatus returns the HTTP status a failure to stop a volume is sent
+// with
+func stopErrStatus(err error) int {
+	if err == transaction.ErrLockTimeout {
+		return http.StatusConflict
+	} else if transaction.IsTimeout(err) {
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
 }
 
+// volumeStopHandler stops a volume, and responds with the volume
 func volumeStopHandler(w http.ResponseWriter, r *http.Request) {
+	if resp := handleVolumeStop(w, r); resp != nil {
+		restutils.SendHTTPResponse(w, http.StatusOK, resp.Volume)
+	}
+}
+
+// volumeStopV2Handler stops a volume, and responds with the volume and the
+// result of stopping each of its bricks
+func volumeStopV2Handler(w http.ResponseWriter, r *http.Request) {
+	if resp := handleVolumeStop(w, r); resp != nil {
+		restutils.SendHTTPResponse(w, http.StatusOK, resp)
+	}
+}
+
+// handleVolumeStop stops the volume of the request, and returns the stop
+// response to be sent. Errors are sent by handleVolumeStop itself, and nil is
+// returned.
+func handleVolumeStop(w http.ResponseWriter, r *http.Request) *VolStopResp {
 	p := mux.Vars(r)
 	volname := p["volname"]
 	reqID, logger := restutils.GetReqIDandLogger(r)
@@ -81,49 +234,27 @@ func volumeStopHandler(w http.ResponseWriter, r *http.Request) {
 	vol, e := volume.GetVolume(volname)
 	if e != nil {
 		restutils.SendHTTPError(w, http.StatusNotFound, errors.ErrVolNotFound.Error())
-		return
+		return nil
 	}
 	if vol.Status == volume.VolStopped {
 		restutils.SendHTTPError(w, http.StatusBadRequest, errors.ErrVolAlreadyStopped.Error())
-		return
+		return nil
 	}
 
-	// A simple one-step transaction to stop brick processes
-	txn := transaction.NewTxn(reqID)
-	defer txn.Cleanup()
-	lock, unlock, err := transaction.CreateLockSteps(volname)
+	timeout, err := stopTimeout(r)
 	if err != nil {
-		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
-		return
-	}
-	txn.Nodes = vol.Nodes()
-	txn.Steps = []*transaction.Step{
-		lock,
-		{
-			DoFunc: "vol-stop.Commit",
-			Nodes:  txn.Nodes,
-		},
-		unlock,
+		restutils.SendHTTPError(w, http.StatusBadRequest, err.Error())
+		return nil
 	}
-	txn.Ctx.Set("volname", volname)
 
-	if _, err = txn.Do(); err != nil {
+	resp, err := stopVolume(reqID, vol, timeout, logger)
+	if err != nil {
 		logger.WithError(err).WithField(
 			"volume", volname).Error("failed to stop volume")
-		if err == transaction.ErrLockTimeout {
-			restutils.SendHTTPError(w, http.StatusConflict, err.Error())
-		} else {
-			restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
-		}
-		return
+		restutils.SendHTTPError(w, stopErrStatus(err), err.Error())
+		return nil
 	}
+	logHookFailures(logger, resp.HookFailures)
 
-	vol.Status = volume.VolStopped
-
-	e = volume.AddOrUpdateVolumeFunc(vol)
-	if e != nil {
-		restutils.SendHTTPError(w, http.StatusInternalServerError, e.Error())
-		return
-	}
-	restutils.SendHTTPResponse(w, http.StatusOK, vol)
+	return resp
 }
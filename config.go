@@ -5,6 +5,8 @@ import (
 	"net"
 	"os"
 	"path"
+	"reflect"
+	"strings"
 
 	"github.com/gluster/glusterd2/gdctx"
 	"github.com/gluster/glusterd2/store"
@@ -18,8 +20,13 @@ const (
 	defaultLogLevel      = "debug"
 	defaultClientAddress = ":24007"
 	defaultPeerAddress   = ":24008"
+	defaultRESTSocket    = "glusterd2.socket"
 
 	defaultConfName = "glusterd"
+
+	// envPrefix is the prefix of environment variables which can be used
+	// to set config options, eg. GD2_CLIENTADDRESS or GD2_PEER_TIMEOUT.
+	envPrefix = "GD2"
 )
 
 // Slices,Arrays cannot be constants :(
@@ -28,6 +35,17 @@ var (
 		"/etc/glusterd",
 		".",
 	}
+
+	// reloadableOptions are the config options which take effect when the
+	// configuration is reloaded on SIGHUP. Changes to any other option
+	// require a restart of GlusterD.
+	reloadableOptions = []string{
+		"loglevel",
+		"logfile",
+		"logdir",
+		"logformat",
+		"loglevels",
+	}
 )
 
 // parseFlags sets up the flags and parses them, this needs to be called before any other operation
@@ -39,9 +57,30 @@ func parseFlags() {
 	flag.String("logfile", "-", "Log file name. (default: STDERR)")
 	flag.String("config", "", "Configuration file for GlusterD. By default looks for glusterd.(yaml|toml|json) in /etc/glusterd and current working directory.")
 	flag.String("loglevel", defaultLogLevel, "Severity of messages to be logged.")
+	flag.String("loglevels", "", "Severity of messages to be logged for subsystems, overriding loglevel. Given as subsystem=level pairs separated by commas, eg. txn=debug,store=warning. Subsystems are txn, store, rest and volgen.")
+	flag.String("logformat", "text", "Format of the log messages, text or json.")
+	flag.String("group", "", "Group given access to the runtime directory and local sockets of GlusterD. (default: group of the GlusterD process)")
+	flag.String("brickroot", "", "Directory the provisioner mounts the bricks of volumes under, on this node. Volumes are not provisioned on the node if not set.")
+	flag.String("brickpool", "", "LVM thin pool, as vg/pool, the provisioner creates the logical volumes of bricks in, on this node. Every brick is a thin logical volume of the size of the volume. Volumes are not provisioned on the node if not set.")
+	flag.Int("brick-health-interval", 30, "Interval in seconds at which the filesystems of the bricks on this node are checked for failures. Set to 0 to disable.")
+	flag.Int("brick-mount-watch-interval", 2, "Interval in seconds at which the mount table is checked for the filesystems of the bricks on this node being unmounted. Bricks whose filesystem is unmounted are taken offline. Set to 0 to disable.")
+	flag.Bool("brick-health-kill", false, "Kill the brick processes of bricks whose filesystem has failed, so that clients fail over to the replicas.")
+	flag.Bool("mock-bricks", false, "Mock the bricks of this node, for integration tests in environments without root privileges. Brick paths are not checked for xattr support, and stand-in processes are run in place of glusterfsd.")
+	flag.Int("gc-interval", 600, "Interval in seconds at which orphaned runtime files and store entries are looked for and cleaned up. Set to 0 to disable.")
+	flag.Int("debug-collect-rate", 10<<20, "Rate in bytes per second at which files are read when collecting debug information with /debug/collect. Set to 0 to disable.")
+	flag.Float64("rest-rate-limit", 0, "Requests per second accepted by the REST API from all clients. Set to 0 to disable.")
+	flag.Int("rest-rate-burst", 50, "Requests accepted by the REST API from all clients in a burst over the rate limit.")
+	flag.Int("rest-max-inflight", 0, "Requests served by the REST API at a time for all clients. Set to 0 to disable.")
+	flag.Float64("rest-client-rate-limit", 0, "Requests per second accepted by the REST API from a client, identified by its token or address. Set to 0 to disable.")
+	flag.Int("rest-client-rate-burst", 10, "Requests accepted by the REST API from a client in a burst over the rate limit.")
+	flag.Int("rest-client-max-inflight", 0, "Requests served by the REST API at a time for a client. Set to 0 to disable.")
+	flag.Int("idempotency-key-ttl", 86400, "Time in seconds the responses of REST requests with an Idempotency-Key are kept to be replayed for retries.")
+	flag.String("tracing-jaeger-agent", "", "Address of the Jaeger agent tracing spans are reported to, as host:port. Tracing is disabled if not set.")
+	flag.Float64("tracing-sample-ratio", 0.1, "Ratio of the REST requests which are traced.")
 
 	flag.String("clientaddress", defaultClientAddress, "Address to bind the REST service.")
 	flag.String("peeraddress", defaultPeerAddress, "Address to bind the inter glusterd2 RPC service.")
+	flag.String("restsocket", "", "UNIX socket to serve the REST service on for local clients. (default: rundir/glusterd2.socket)")
 
 	store.InitFlags()
 
@@ -70,6 +109,10 @@ func setDefaults() error {
 		config.SetDefault("rundir", path.Join(wd, "run"))
 	}
 
+	if config.GetString("restsocket") == "" {
+		config.SetDefault("restsocket", path.Join(config.GetString("rundir"), defaultRESTSocket))
+	}
+
 	if config.GetString("logdir") == "" {
 		config.SetDefault("logdir", path.Join(wd, "log"))
 	}
@@ -108,6 +151,12 @@ func initConfig(confFile string) error {
 	// If a config file was given, read in configration from that file.
 	// If the file is not present panic.
 
+	// Config options can also be given as environment variables, which
+	// take precedence over the config file but not over flags.
+	config.SetEnvPrefix(envPrefix)
+	config.SetEnvKeyReplacer(strings.NewReplacer("-", "_", ".", "_"))
+	config.AutomaticEnv()
+
 	if confFile == "" {
 		config.SetConfigName(defaultConfName)
 		for _, p := range defaultConfPaths {
@@ -144,3 +193,43 @@ func initConfig(confFile string) error {
 	dumpConfigToLog()
 	return nil
 }
+
+func isReloadable(key string) bool {
+	for _, k := range reloadableOptions {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// reloadConfig re-reads the configuration file. Changes to options which
+// cannot be reloaded are logged and otherwise ignored until the next restart.
+func reloadConfig() error {
+	if config.ConfigFileUsed() == "" {
+		return nil
+	}
+
+	old := config.AllSettings()
+	if err := config.ReadInConfig(); err != nil {
+		return err
+	}
+
+	for k, v := range config.AllSettings() {
+		if reflect.DeepEqual(old[k], v) {
+			continue
+		}
+		l := log.WithFields(log.Fields{
+			"option": k,
+			"old":    old[k],
+			"new":    v,
+		})
+		if isReloadable(k) {
+			l.Info("config option reloaded")
+		} else {
+			l.Warn("config option changed, restart GlusterD for it to take effect")
+		}
+	}
+
+	return nil
+}
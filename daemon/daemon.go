@@ -44,6 +44,13 @@ type Daemon interface {
 	ID() string
 }
 
+// foreground is implemented by daemons which may run in the foreground,
+// without writing their pid file. Start writes the pid file of such daemons
+// itself, and doesn't wait for them to exit.
+type foreground interface {
+	Foreground() bool
+}
+
 // Start function starts the daemon located at path returned by Path() with
 // args returned by Args() function. If the pidfile to the daemon exists, the
 // contents are read to determine if the daemon is already running. If it
@@ -75,6 +82,15 @@ func Start(d Daemon, wait bool) error {
 		return err
 	}
 
+	if fg, ok := d.(foreground); ok && fg.Foreground() {
+		if err := WritePidToFile(cmd.Process.Pid, d.PidFile()); err != nil {
+			cmd.Process.Kill()
+			cmd.Wait()
+			return err
+		}
+		wait = false
+	}
+
 	if wait == true {
 		// Wait for the child to exit
 		errStatus := cmd.Wait()
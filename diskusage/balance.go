@@ -0,0 +1,134 @@
+package diskusage
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gluster/glusterd2/clusteroptions"
+)
+
+const (
+	// OptSkewThreshold is the name of the cluster option setting the
+	// skew above which rebalancing a volume is advised
+	OptSkewThreshold = "rebalance-skew-threshold"
+
+	defaultSkewThreshold = 10
+)
+
+func init() {
+	clusteroptions.Register(&clusteroptions.Option{
+		Name:         OptSkewThreshold,
+		DefaultValue: strconv.Itoa(defaultSkewThreshold),
+		Description:  "Difference in percentage points between the usage of the most and least used subvolumes of a volume above which rebalancing the volume is advised",
+		Validate: func(value string, _ func(string) string) error {
+			if t, err := strconv.Atoi(value); err != nil || t < 1 || t > 100 {
+				return fmt.Errorf("must be a percentage between 1 and 100")
+			}
+			return nil
+		},
+	})
+}
+
+// SkewThreshold returns the skew above which rebalancing a volume is advised
+func SkewThreshold() int {
+	value, err := clusteroptions.Get(OptSkewThreshold)
+	if err != nil {
+		return defaultSkewThreshold
+	}
+	t, err := strconv.Atoi(value)
+	if err != nil {
+		return defaultSkewThreshold
+	}
+	return t
+}
+
+// SubvolUsage is the usage of a subvolume of a volume, a replica set or a
+// single brick of a distribute volume. A subvolume can only hold as much as
+// its smallest brick.
+type SubvolUsage struct {
+	Bricks      []string `json:"bricks"`
+	Total       uint64   `json:"total"`
+	Used        uint64   `json:"used"`
+	UsedPercent int      `json:"used-percent"`
+}
+
+// BalanceReport tells how evenly the data of a volume is spread over its
+// subvolumes. Skew is the difference in percentage points between the most
+// and the least used subvolumes.
+type BalanceReport struct {
+	Subvols          []SubvolUsage `json:"subvols"`
+	Skew             int           `json:"skew"`
+	Threshold        int           `json:"threshold"`
+	RebalanceAdvised bool          `json:"rebalance-advised"`
+	Hints            []string      `json:"hints,omitempty"`
+}
+
+func brickName(c *Capacity) string {
+	return c.NodeID.String() + ":" + c.Path
+}
+
+// Balance reports the balance of a volume from the capacities of the bricks of
+// its subvolumes. Rebalancing is advised when the skew is above the
+// threshold. Subvolumes whose bricks differ in size are reported, as the
+// space of the larger bricks is not usable, which happens when only some of
+// the bricks of a replica set are grown.
+func Balance(subvols [][]Capacity, threshold int) *BalanceReport {
+	report := &BalanceReport{
+		Subvols:   []SubvolUsage{},
+		Threshold: threshold,
+	}
+
+	minUsed, maxUsed := 100, 0
+	for i, bricks := range subvols {
+		if len(bricks) == 0 {
+			continue
+		}
+
+		usage := SubvolUsage{Total: bricks[0].Total, Used: bricks[0].Used}
+		maxTotal := bricks[0].Total
+		for j := range bricks {
+			b := &bricks[j]
+			usage.Bricks = append(usage.Bricks, brickName(b))
+			if b.Total < usage.Total {
+				usage.Total = b.Total
+			}
+			if b.Total > maxTotal {
+				maxTotal = b.Total
+			}
+			if b.Used > usage.Used {
+				usage.Used = b.Used
+			}
+		}
+		if usage.Total != 0 {
+			usage.UsedPercent = int(usage.Used * 100 / usage.Total)
+		}
+		report.Subvols = append(report.Subvols, usage)
+
+		if usage.UsedPercent < minUsed {
+			minUsed = usage.UsedPercent
+		}
+		if usage.UsedPercent > maxUsed {
+			maxUsed = usage.UsedPercent
+		}
+
+		// Sizes within 1% of each other are considered the same
+		if maxTotal-usage.Total > maxTotal/100 {
+			report.Hints = append(report.Hints, fmt.Sprintf(
+				"bricks of subvolume %d differ in size, only %d of %d bytes of the largest brick are usable; grow the other bricks of the subvolume",
+				i, usage.Total, maxTotal))
+		}
+	}
+
+	if len(report.Subvols) < 2 {
+		return report
+	}
+
+	report.Skew = maxUsed - minUsed
+	if report.Skew > threshold {
+		report.RebalanceAdvised = true
+		report.Hints = append(report.Hints, fmt.Sprintf(
+			"usage of subvolumes differs by %d percentage points, above the threshold of %d; rebalance the volume to spread its data evenly",
+			report.Skew, threshold))
+	}
+	return report
+}
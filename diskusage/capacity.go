@@ -0,0 +1,109 @@
+package diskusage
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/gluster/glusterd2/store"
+
+	"github.com/coreos/etcd/clientv3"
+	"github.com/pborman/uuid"
+)
+
+const capacityPrefix = store.GlusterPrefix + "capacity/"
+
+// Capacity is the size of the filesystem of a brick, as last recorded by the
+// peer the brick is on. Each peer only records the capacity of its own
+// bricks.
+type Capacity struct {
+	Volume  string    `json:"volume"`
+	NodeID  uuid.UUID `json:"node-id"`
+	Path    string    `json:"path"`
+	Total   uint64    `json:"total"`
+	Used    uint64    `json:"used"`
+	Updated time.Time `json:"updated"`
+	// PreviousTotal is the size of the filesystem before it was last
+	// resized, at the time Resized. It is 0 if no resize has been seen.
+	PreviousTotal uint64     `json:"previous-total,omitempty"`
+	Resized       *time.Time `json:"resized,omitempty"`
+}
+
+// UsedPercent returns the percentage of the filesystem which is used
+func (c *Capacity) UsedPercent() int {
+	if c.Total == 0 {
+		return 0
+	}
+	return int(c.Used * 100 / c.Total)
+}
+
+func capacityKey(volname string, nodeID uuid.UUID, path string) string {
+	return capacityPrefix + volname + "/" + nodeID.String() + path
+}
+
+// Size returns the total and used bytes of the filesystem the given path is
+// on. The space reserved for the root user counts as used.
+func Size(path string) (uint64, uint64, error) {
+	st, err := Stat(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	return st.Total, st.Unavailable(), nil
+}
+
+// GetCapacity returns the recorded capacity of a brick, or nil if none has
+// been recorded
+func GetCapacity(volname string, nodeID uuid.UUID, path string) (*Capacity, error) {
+	resp, err := store.Store.Get(context.TODO(), capacityKey(volname, nodeID, path))
+	if err != nil {
+		return nil, err
+	}
+	if resp.Count == 0 {
+		return nil, nil
+	}
+
+	var c Capacity
+	if err := json.Unmarshal(resp.Kvs[0].Value, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// GetCapacities returns the recorded capacities of the bricks of a volume, or
+// of all volumes if volname is empty
+func GetCapacities(volname string) ([]Capacity, error) {
+	prefix := capacityPrefix
+	if volname != "" {
+		prefix += volname + "/"
+	}
+	resp, err := store.Store.Get(context.TODO(), prefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+
+	caps := make([]Capacity, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		var c Capacity
+		if err := json.Unmarshal(kv.Value, &c); err != nil {
+			return nil, err
+		}
+		caps = append(caps, c)
+	}
+	return caps, nil
+}
+
+// SaveCapacity records the capacity of a brick
+func SaveCapacity(c *Capacity) error {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	_, err = store.Store.Put(context.TODO(), capacityKey(c.Volume, c.NodeID, c.Path), string(b))
+	return err
+}
+
+// DeleteCapacity deletes the recorded capacity of a brick
+func DeleteCapacity(c *Capacity) error {
+	_, err := store.Store.Delete(context.TODO(), capacityKey(c.Volume, c.NodeID, c.Path))
+	return err
+}
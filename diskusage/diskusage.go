@@ -0,0 +1,122 @@
+// Package diskusage implements the disk usage watermarks of the bricks of the
+// cluster.
+//
+// Bricks whose filesystem usage crosses the warning or critical watermark are
+// reported, so that operators can add capacity before the bricks fill up. The
+// reserve is the percentage of the filesystem of every brick kept free, which
+// the bricks refuse to write into.
+package diskusage
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/store"
+)
+
+const (
+	configKey = store.GlusterPrefix + "cluster/disk-usage"
+
+	defaultWarning  = 80
+	defaultCritical = 90
+	// defaultReserve is the default of the reserve option of the posix
+	// xlator
+	defaultReserve = 1
+)
+
+// Disk usage levels of a brick
+const (
+	LevelNormal   = "normal"
+	LevelWarning  = "warning"
+	LevelCritical = "critical"
+	// LevelFull is reached when only the reserve is left free
+	LevelFull = "full"
+)
+
+// Config is the disk usage configuration of the cluster. All values are
+// percentages of the size of the filesystem of a brick.
+type Config struct {
+	Warning  int `json:"warning"`
+	Critical int `json:"critical"`
+	Reserve  int `json:"reserve"`
+}
+
+// Validate checks if the watermarks and the reserve are valid percentages, and
+// that the warning watermark isn't above the critical one
+func (c *Config) Validate() error {
+	for _, p := range []int{c.Warning, c.Critical, c.Reserve} {
+		if p < 0 || p > 100 {
+			return errors.ErrInvalidDiskUsageConfig
+		}
+	}
+	if c.Warning > c.Critical {
+		return errors.ErrInvalidDiskUsageConfig
+	}
+	return nil
+}
+
+// Level returns the disk usage level of a brick with the given usage
+func (c *Config) Level(used int) string {
+	switch {
+	case used >= 100-c.Reserve:
+		return LevelFull
+	case used >= c.Critical:
+		return LevelCritical
+	case used >= c.Warning:
+		return LevelWarning
+	}
+	return LevelNormal
+}
+
+// GetConfig returns the disk usage configuration saved in the store, or the
+// default configuration if none has been saved
+func GetConfig() (*Config, error) {
+	resp, err := store.Store.Get(context.TODO(), configKey)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Config{
+		Warning:  defaultWarning,
+		Critical: defaultCritical,
+		Reserve:  defaultReserve,
+	}
+	if resp.Count == 0 {
+		return c, nil
+	}
+
+	if err := json.Unmarshal(resp.Kvs[0].Value, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// SetConfig saves the disk usage configuration into the store
+func SetConfig(c *Config) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	_, err = store.Store.Put(context.TODO(), configKey, string(b))
+	return err
+}
+
+// Used returns the percentage of the filesystem the given path is on which is
+// used. The space reserved for the root user counts as used, as it isn't
+// available to clients.
+func Used(path string) (int, error) {
+	st, err := Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	if st.Total == 0 {
+		return 0, nil
+	}
+	return int(st.Unavailable() * 100 / st.Total), nil
+}
@@ -0,0 +1,59 @@
+package diskusage
+
+import (
+	"testing"
+
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/tests"
+)
+
+func TestValidate(t *testing.T) {
+	for _, c := range []Config{{80, 90, 1}, {0, 0, 0}, {90, 90, 10}, {100, 100, 100}} {
+		tests.Assert(t, c.Validate() == nil)
+	}
+
+	for _, c := range []Config{{91, 90, 1}, {-1, 90, 1}, {80, 101, 1}, {80, 90, -5}} {
+		tests.Assert(t, c.Validate() == errors.ErrInvalidDiskUsageConfig)
+	}
+}
+
+func TestLevel(t *testing.T) {
+	c := Config{Warning: 80, Critical: 90, Reserve: 5}
+	tests.Assert(t, c.Level(0) == LevelNormal)
+	tests.Assert(t, c.Level(79) == LevelNormal)
+	tests.Assert(t, c.Level(80) == LevelWarning)
+	tests.Assert(t, c.Level(90) == LevelCritical)
+	tests.Assert(t, c.Level(94) == LevelCritical)
+	tests.Assert(t, c.Level(95) == LevelFull)
+	tests.Assert(t, c.Level(100) == LevelFull)
+}
+
+func TestBalance(t *testing.T) {
+	// Two replica sets, 50% and 80% used
+	subvols := [][]Capacity{
+		{{Path: "/b1", Total: 100, Used: 50}, {Path: "/b2", Total: 100, Used: 40}},
+		{{Path: "/b3", Total: 100, Used: 80}, {Path: "/b4", Total: 100, Used: 80}},
+	}
+	r := Balance(subvols, 10)
+	tests.Assert(t, len(r.Subvols) == 2)
+	tests.Assert(t, r.Subvols[0].UsedPercent == 50)
+	tests.Assert(t, r.Skew == 30)
+	tests.Assert(t, r.RebalanceAdvised)
+	tests.Assert(t, len(r.Hints) == 1)
+
+	r = Balance(subvols, 30)
+	tests.Assert(t, !r.RebalanceAdvised)
+	tests.Assert(t, len(r.Hints) == 0)
+
+	// Only one brick of the replica set was grown
+	subvols[0][1].Total = 200
+	r = Balance(subvols, 30)
+	tests.Assert(t, r.Subvols[0].Total == 100)
+	tests.Assert(t, !r.RebalanceAdvised)
+	tests.Assert(t, len(r.Hints) == 1)
+
+	// A single subvolume is never skewed
+	r = Balance(subvols[1:], 10)
+	tests.Assert(t, r.Skew == 0)
+	tests.Assert(t, !r.RebalanceAdvised)
+}
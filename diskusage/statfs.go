@@ -0,0 +1,41 @@
+package diskusage
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// FSStat is the size of the filesystem a path is on, in bytes
+type FSStat struct {
+	Total uint64
+	// Used does not include the space reserved for the root user
+	Used uint64
+	// Free is the space available to unprivileged users, as seen by
+	// clients
+	Free uint64
+	// NameMax is the maximum length of file names on the filesystem
+	NameMax  int
+	ReadOnly bool
+}
+
+// Stat returns the size of the filesystem the given path is on
+func Stat(path string) (*FSStat, error) {
+	var s unix.Statfs_t
+	if err := unix.Statfs(path, &s); err != nil {
+		return nil, err
+	}
+
+	bsize := uint64(s.Bsize)
+	st := &FSStat{
+		Total: s.Blocks * bsize,
+		Used:  (s.Blocks - s.Bfree) * bsize,
+		Free:  s.Bavail * bsize,
+	}
+	setPlatformStat(st, &s)
+	return st, nil
+}
+
+// Unavailable returns the space of the filesystem which isn't available to
+// clients, which is the used space and the space reserved for the root user
+func (st *FSStat) Unavailable() uint64 {
+	return st.Total - st.Free
+}
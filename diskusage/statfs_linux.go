@@ -0,0 +1,10 @@
+package diskusage
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+func setPlatformStat(st *FSStat, s *unix.Statfs_t) {
+	st.NameMax = int(s.Namelen)
+	st.ReadOnly = s.Flags&unix.ST_RDONLY != 0
+}
@@ -0,0 +1,13 @@
+// +build !linux
+
+package diskusage
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// setPlatformStat sets NAME_MAX of most filesystems, as the maximum length of
+// file names isn't reported by statfs on all platforms
+func setPlatformStat(st *FSStat, s *unix.Statfs_t) {
+	st.NameMax = 255
+}
@@ -0,0 +1,22 @@
+package diskusage
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/gluster/glusterd2/tests"
+)
+
+func TestStat(t *testing.T) {
+	st, err := Stat(os.TempDir())
+	tests.Assert(t, err == nil)
+	tests.Assert(t, st.Total > 0)
+	tests.Assert(t, st.Used <= st.Total)
+	tests.Assert(t, st.Free <= st.Total)
+	tests.Assert(t, st.Unavailable() == st.Total-st.Free)
+	tests.Assert(t, st.NameMax > 0)
+
+	_, err = Stat(path.Join(os.TempDir(), "diskusage-test-does-not-exist"))
+	tests.Assert(t, err != nil)
+}
@@ -8,6 +8,7 @@ import (
 
 var binDir string
 var functest bool
+var mockBricks bool
 
 func TestMain(m *testing.M) {
 
@@ -17,6 +18,9 @@ func TestMain(m *testing.M) {
 	flag.StringVar(&binDir, "bindir", "../build",
 		"The directory containing glusterd2 binary")
 
+	flag.BoolVar(&mockBricks, "mockbricks", false,
+		"Run glusterd2 with mocked bricks, which needs neither root nor glusterfsd")
+
 	flag.Parse()
 
 	if !functest {
@@ -98,10 +98,15 @@ func spawnGlusterd(configFilePath string, cleanStart bool) (*gdProcess, error) {
 		return nil, err
 	}
 
-	g.Cmd = exec.Command(path.Join(binDir, "glusterd2"),
+	args := []string{
 		"--config", configFilePath,
 		"--logdir", path.Join(g.Workdir, "log"),
-		"--logfile", "glusterd2.log")
+		"--logfile", "glusterd2.log",
+	}
+	if mockBricks {
+		args = append(args, "--mock-bricks")
+	}
+	g.Cmd = exec.Command(path.Join(binDir, "glusterd2"), args...)
 
 	if err := g.Cmd.Start(); err != nil {
 		return nil, err
@@ -0,0 +1,114 @@
+package errors
+
+import (
+	"sync"
+
+	"github.com/gluster/glusterd2/pkg/api"
+)
+
+var (
+	codes = map[error]string{
+		ErrVolCreateFail:           api.ErrCodeVolCreateFail,
+		ErrVolNotFound:             api.ErrCodeVolNotFound,
+		ErrPeerNotFound:            api.ErrCodePeerNotFound,
+		ErrJSONParsingFailed:       api.ErrCodeJSONParsingFailed,
+		ErrEmptyVolName:            api.ErrCodeEmptyVolName,
+		ErrEmptyBrickList:          api.ErrCodeEmptyBrickList,
+		ErrInvalidBrickPath:        api.ErrCodeInvalidBrickPath,
+		ErrVolExists:               api.ErrCodeVolExists,
+		ErrVolNameConflict:         api.ErrCodeVolNameConflict,
+		ErrVolAlreadyStarted:       api.ErrCodeVolAlreadyStarted,
+		ErrVolAlreadyStopped:       api.ErrCodeVolAlreadyStopped,
+		ErrVolNotStarted:           api.ErrCodeVolNotStarted,
+		ErrWrongGraphType:          api.ErrCodeWrongGraphType,
+		ErrDeviceIDNotFound:        api.ErrCodeDeviceIDNotFound,
+		ErrBrickIsMountPoint:       api.ErrCodeBrickIsMountPoint,
+		ErrBrickUnderRootPartition: api.ErrCodeBrickUnderRootPartition,
+		ErrBrickNotDirectory:       api.ErrCodeBrickNotDirectory,
+		ErrBrickPathAlreadyInUse:   api.ErrCodeBrickPathAlreadyInUse,
+		ErrNoHostnamesPresent:      api.ErrCodeNoHostnamesPresent,
+		ErrBrickPathConvertFail:    api.ErrCodeBrickPathConvertFail,
+		ErrBrickNotLocal:           api.ErrCodeBrickNotLocal,
+		ErrBrickPathTooLong:        api.ErrCodeBrickPathTooLong,
+		ErrSubDirPathTooLong:       api.ErrCodeSubDirPathTooLong,
+		ErrNameTooLong:             api.ErrCodeNameTooLong,
+		ErrIPAddressNotFound:       api.ErrCodeIPAddressNotFound,
+		ErrPeerLocalNode:           api.ErrCodePeerLocalNode,
+		ErrProcessNotFound:         api.ErrCodeProcessNotFound,
+		ErrProcessAlreadyRunning:   api.ErrCodeProcessAlreadyRunning,
+		ErrInvalidPeerMetaDataKey:  api.ErrCodeInvalidPeerMetaDataKey,
+		ErrQuorumNotMet:            api.ErrCodeQuorumNotMet,
+		ErrInvalidQuorumRatio:      api.ErrCodeInvalidQuorumRatio,
+		ErrOpVersionDowngrade:      api.ErrCodeOpVersionDowngrade,
+		ErrOpVersionNotSupported:   api.ErrCodeOpVersionNotSupported,
+		ErrOptionOpVersion:         api.ErrCodeOptionOpVersion,
+		ErrPendingHeals:            api.ErrCodePendingHeals,
+		ErrPeerInMaintenance:       api.ErrCodePeerInMaintenance,
+		ErrBitrotNotEnabled:        api.ErrCodeBitrotNotEnabled,
+		ErrBrickFSNotSupported:     api.ErrCodeBrickFSNotSupported,
+		ErrBrickPathOverlaps:       api.ErrCodeBrickPathOverlaps,
+		ErrSELinuxNotEnabled:       api.ErrCodeSELinuxNotEnabled,
+		ErrProfileNotFound:         api.ErrCodeProfileNotFound,
+		ErrProfileBuiltin:          api.ErrCodeProfileBuiltin,
+		ErrVolNotReplicate:         api.ErrCodeVolNotReplicate,
+		ErrInvalidHealPolicy:       api.ErrCodeInvalidHealPolicy,
+		ErrBlockVolNotFound:        api.ErrCodeBlockVolNotFound,
+		ErrBlockVolExists:          api.ErrCodeBlockVolExists,
+		ErrBlockVolShrink:          api.ErrCodeBlockVolShrink,
+		ErrNoCapacity:              api.ErrCodeNoCapacity,
+		ErrVolNotProvisioned:       api.ErrCodeVolNotProvisioned,
+		ErrSnapNotFound:            api.ErrCodeSnapNotFound,
+		ErrSnapExists:              api.ErrCodeSnapExists,
+		ErrBrickNotThinLV:          api.ErrCodeBrickNotThinLV,
+		ErrVolHasSnapshots:         api.ErrCodeVolHasSnapshots,
+		ErrSnapScheduleNotFound:    api.ErrCodeSnapScheduleNotFound,
+		ErrBackupVersion:           api.ErrCodeBackupVersion,
+		ErrBackupConflict:          api.ErrCodeBackupConflict,
+		ErrInvalidDiskUsageConfig:  api.ErrCodeInvalidDiskUsageConfig,
+		ErrClusterOptNotFound:      api.ErrCodeClusterOptNotFound,
+		ErrClusterOptConflict:      api.ErrCodeClusterOptConflict,
+		ErrVolDependencyCycle:      api.ErrCodeVolDependencyCycle,
+		ErrInvalidStopTimeout:      api.ErrCodeInvalidStopTimeout,
+		ErrPeerNameConflict:        api.ErrCodePeerNameConflict,
+		ErrBrickNotFound:           api.ErrCodeBrickNotFound,
+		ErrPeerConflict:            api.ErrCodePeerConflict,
+	}
+	// byMessage maps the messages of the errors to their codes, as the
+	// errors reach the REST handlers as strings
+	byMessage = make(map[string]string)
+	codesLock sync.RWMutex
+)
+
+func init() {
+	for err, code := range codes {
+		byMessage[err.Error()] = code
+	}
+}
+
+// RegisterCode sets the code reported to clients for an error defined outside
+// of this package. It is meant to be called from the init function of the
+// package defining the error.
+func RegisterCode(err error, code string) {
+	codesLock.Lock()
+	defer codesLock.Unlock()
+
+	codes[err] = code
+	byMessage[err.Error()] = code
+}
+
+// Code returns the code of the error, or an empty string if it has none
+func Code(err error) string {
+	codesLock.RLock()
+	defer codesLock.RUnlock()
+
+	return codes[err]
+}
+
+// CodeOf returns the code of the error with the given message, or an empty
+// string if it has none
+func CodeOf(msg string) string {
+	codesLock.RLock()
+	defer codesLock.RUnlock()
+
+	return byMessage[msg]
+}
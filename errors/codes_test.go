@@ -0,0 +1,26 @@
+package errors
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gluster/glusterd2/tests"
+)
+
+func TestCodesUnique(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		tests.Assert(t, code != "" && !seen[code])
+		seen[code] = true
+	}
+}
+
+func TestCodeOf(t *testing.T) {
+	tests.Assert(t, CodeOf(ErrVolNotFound.Error()) == Code(ErrVolNotFound))
+	tests.Assert(t, CodeOf("unknown error") == "")
+
+	err := errors.New("registered error")
+	RegisterCode(err, "registered")
+	tests.Assert(t, Code(err) == "registered")
+	tests.Assert(t, CodeOf(err.Error()) == "registered")
+}
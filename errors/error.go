@@ -14,8 +14,10 @@ var (
 	ErrEmptyBrickList          = errors.New("brick list is empty")
 	ErrInvalidBrickPath        = errors.New("invalid brick path, brick path should be in host:<brick> format")
 	ErrVolExists               = errors.New("volume already exists")
+	ErrVolNameConflict         = errors.New("volume name differs only in case from an existing volume")
 	ErrVolAlreadyStarted       = errors.New("volume already started")
 	ErrVolAlreadyStopped       = errors.New("volume already stopped")
+	ErrVolNotStarted           = errors.New("volume not started")
 	ErrWrongGraphType          = errors.New("graph: incorrect graph type")
 	ErrDeviceIDNotFound        = errors.New("Failed to get device id")
 	ErrBrickIsMountPoint       = errors.New("Brick path is already a mount point")
@@ -27,8 +29,45 @@ var (
 	ErrBrickNotLocal           = errors.New("Brickpath doesn't belong to localhost")
 	ErrBrickPathTooLong        = errors.New("Brickpath too long")
 	ErrSubDirPathTooLong       = errors.New("sub directory path is too long")
+	ErrNameTooLong             = errors.New("name of brick directory is too long for the filesystem")
 	ErrIPAddressNotFound       = errors.New("Failed to find IP address")
 	ErrPeerLocalNode           = errors.New("The peer being added is the local node")
 	ErrProcessNotFound         = errors.New("The process is not running or is inaccessible")
 	ErrProcessAlreadyRunning   = errors.New("Process is already running")
+	ErrInvalidPeerMetaDataKey  = errors.New("peer metadata key cannot be empty")
+	ErrQuorumNotMet            = errors.New("server quorum is not met")
+	ErrInvalidQuorumRatio      = errors.New("quorum ratio must be a percentage or a count of peers")
+	ErrOpVersionDowngrade      = errors.New("cluster op-version cannot be lowered")
+	ErrOpVersionNotSupported   = errors.New("op-version is not supported by all peers")
+	ErrOptionOpVersion         = errors.New("option is not supported at the current cluster op-version")
+	ErrPendingHeals            = errors.New("bricks have pending heals")
+	ErrPeerInMaintenance       = errors.New("peer is in maintenance mode")
+	ErrBitrotNotEnabled        = errors.New("bitrot is not enabled on the volume")
+	ErrBrickFSNotSupported     = errors.New("filesystem of the brick path is not supported")
+	ErrBrickPathOverlaps       = errors.New("brick path overlaps with an existing brick")
+	ErrSELinuxNotEnabled       = errors.New("SELinux is not enabled")
+	ErrProfileNotFound         = errors.New("option profile not found")
+	ErrProfileBuiltin          = errors.New("builtin option profiles cannot be changed")
+	ErrVolNotReplicate         = errors.New("volume is not a replicate volume")
+	ErrInvalidHealPolicy       = errors.New("invalid split-brain resolution policy")
+	ErrBlockVolNotFound        = errors.New("block volume not found")
+	ErrBlockVolExists          = errors.New("block volume already exists")
+	ErrBlockVolShrink          = errors.New("block volumes cannot be shrunk")
+	ErrNoCapacity              = errors.New("not enough capacity available on the brick roots of the peers")
+	ErrVolNotProvisioned       = errors.New("volume was not created by the provisioner")
+	ErrSnapNotFound            = errors.New("snapshot not found")
+	ErrSnapExists              = errors.New("snapshot already exists")
+	ErrBrickNotThinLV          = errors.New("brick is not on a thinly provisioned logical volume")
+	ErrVolHasSnapshots         = errors.New("volume has snapshots")
+	ErrSnapScheduleNotFound    = errors.New("volume has no snapshot schedule")
+	ErrBackupVersion           = errors.New("backup version is not supported")
+	ErrBackupConflict          = errors.New("configuration was changed while restoring backup, retry")
+	ErrInvalidDiskUsageConfig  = errors.New("disk usage watermarks and reserve must be percentages, with the warning watermark not above the critical one")
+	ErrClusterOptNotFound      = errors.New("cluster option not found")
+	ErrClusterOptConflict      = errors.New("cluster options were changed concurrently, retry")
+	ErrVolDependencyCycle      = errors.New("volumes depend on each other in a cycle")
+	ErrInvalidStopTimeout      = errors.New("invalid timeout specified")
+	ErrPeerNameConflict        = errors.New("hostname is already used by another peer")
+	ErrBrickNotFound           = errors.New("brick not found in any volume")
+	ErrPeerConflict            = errors.New("peer was changed concurrently, retry")
 )
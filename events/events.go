@@ -0,0 +1,69 @@
+// Package events implements the eventing subsystem of GlusterD.
+//
+// Events are broadcast to all the handlers registered on the local node.
+// Packages wanting to act on, or forward, events register a Handler during
+// initialization.
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gluster/glusterd2/gdctx"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/pborman/uuid"
+)
+
+// Event represents an event in the cluster
+type Event struct {
+	ID        uuid.UUID         `json:"id"`
+	Name      string            `json:"name"`
+	Data      map[string]string `json:"data,omitempty"`
+	Origin    uuid.UUID         `json:"origin"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+// Handler is a function which is called for every event broadcast
+type Handler func(*Event)
+
+var handlers = struct {
+	sync.RWMutex
+	h []Handler
+}{}
+
+// New returns a new Event with the given name and data, originating from
+// this node
+func New(name string, data map[string]string) *Event {
+	return &Event{
+		ID:        uuid.NewRandom(),
+		Name:      name,
+		Data:      data,
+		Origin:    gdctx.MyUUID,
+		Timestamp: time.Now(),
+	}
+}
+
+// Register adds the handler to the list of handlers called on every event
+func Register(h Handler) {
+	handlers.Lock()
+	defer handlers.Unlock()
+
+	handlers.h = append(handlers.h, h)
+}
+
+// Broadcast sends the event to all registered handlers
+func Broadcast(e *Event) {
+	log.WithFields(log.Fields{
+		"event": e.Name,
+		"id":    e.ID.String(),
+		"data":  e.Data,
+	}).Info("event broadcast")
+
+	handlers.RLock()
+	defer handlers.RUnlock()
+
+	for _, h := range handlers.h {
+		h(e)
+	}
+}
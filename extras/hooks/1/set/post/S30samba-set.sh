@@ -0,0 +1,14 @@
+#!/bin/bash
+# Adds or removes the Samba share of a volume when the user.smb volume option
+# is set, by running the start or stop Samba hook.
+#
+# Installed in <localstatedir>/hooks/1/set/post. Run by GlusterD2 with
+# --volname=<volume> after volume options are set. The options set are in
+# the environment as GD2_OPTION_<NAME>.
+
+HOOKS_DIR=$(dirname "$(dirname "$(dirname "$(readlink -f "$0")")")")
+
+case "$GD2_OPTION_USER_SMB" in
+    enable|on)   exec "$HOOKS_DIR/start/post/S30samba-start.sh" "$@" ;;
+    disable|off) exec "$HOOKS_DIR/stop/post/S30samba-stop.sh" "$@" ;;
+esac
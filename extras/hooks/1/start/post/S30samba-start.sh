@@ -0,0 +1,38 @@
+#!/bin/bash
+# Exports a started volume as a Samba share using the vfs_glusterfs module,
+# and reloads Samba.
+#
+# Installed in <localstatedir>/hooks/1/start/post. Run by GlusterD2 with
+# --volname=<volume> after a volume is started.
+
+SMB_CONF=${SMB_CONF:-/etc/samba/smb.conf}
+
+for arg in "$@"; do
+    case $arg in
+        --volname=*) VOL="${arg#*=}" ;;
+    esac
+done
+
+[ -n "$VOL" ] || exit 1
+[ -f "$SMB_CONF" ] || exit 0
+
+# Nothing to do if the share already exists
+grep -q "^\[gluster-$VOL\]" "$SMB_CONF" && exit 0
+
+cat >> "$SMB_CONF" <<EOT
+
+[gluster-$VOL]
+comment = For samba share of volume $VOL
+vfs objects = glusterfs
+glusterfs:volume = $VOL
+glusterfs:logfile = /var/log/samba/glusterfs-$VOL.%M.log
+glusterfs:loglevel = 7
+path = /
+read only = no
+guest ok = yes
+kernel share modes = no
+EOT
+
+if pidof smbd > /dev/null; then
+    smbcontrol smbd reload-config
+fi
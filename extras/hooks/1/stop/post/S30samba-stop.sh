@@ -0,0 +1,25 @@
+#!/bin/bash
+# Removes the Samba share of a stopped volume, and reloads Samba.
+#
+# Installed in <localstatedir>/hooks/1/stop/post. Run by GlusterD2 with
+# --volname=<volume> after a volume is stopped.
+
+SMB_CONF=${SMB_CONF:-/etc/samba/smb.conf}
+
+for arg in "$@"; do
+    case $arg in
+        --volname=*) VOL="${arg#*=}" ;;
+    esac
+done
+
+[ -n "$VOL" ] || exit 1
+[ -f "$SMB_CONF" ] || exit 0
+
+grep -q "^\[gluster-$VOL\]" "$SMB_CONF" || exit 0
+
+# Delete the share section, up to the next section
+sed -i "/^\[gluster-$VOL\]/,/^\[/{/^\[gluster-$VOL\]/d;/^\[/!d}" "$SMB_CONF"
+
+if pidof smbd > /dev/null; then
+    smbcontrol smbd reload-config
+fi
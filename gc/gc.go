@@ -0,0 +1,291 @@
+// Package gc finds and cleans up the data left behind by deleted volumes,
+// dead daemons and failed transactions, which is no longer referenced by the
+// state of the cluster
+package gc
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/gluster/glusterd2/brick"
+	"github.com/gluster/glusterd2/daemon"
+	"github.com/gluster/glusterd2/diskusage"
+	"github.com/gluster/glusterd2/gdctx"
+	"github.com/gluster/glusterd2/pmap"
+	"github.com/gluster/glusterd2/transaction"
+	"github.com/gluster/glusterd2/volgen"
+	"github.com/gluster/glusterd2/volume"
+
+	"github.com/pborman/uuid"
+	config "github.com/spf13/viper"
+)
+
+// Kinds of orphaned data
+const (
+	// KindVolumeDir is the local directory of a volume which doesn't
+	// exist, left by a deleted or partially created volume
+	KindVolumeDir = "volume-dir"
+	// KindBrickVolfile is the volfile of a brick which is no longer part
+	// of its volume
+	KindBrickVolfile = "brick-volfile"
+	// KindPidfile is the pidfile of a daemon which isn't running
+	KindPidfile = "pidfile"
+	// KindSocket is the socket file of a brick which doesn't exist
+	KindSocket = "socket"
+	// KindPort is the portmap registration of a brick which doesn't exist
+	KindPort = "port"
+	// KindClientVolfile is the client volfile of a volume which doesn't
+	// exist
+	KindClientVolfile = "client-volfile"
+	// KindTxnContext is the context of a transaction which has ended or
+	// was never started
+	KindTxnContext = "txn-context"
+	// KindBrickCapacity is the recorded capacity of a brick which doesn't
+	// exist
+	KindBrickCapacity = "brick-capacity"
+)
+
+// brickSocketRegexp matches the names of the socket files of bricks, which are
+// named after the md5sum of the brick
+var brickSocketRegexp = regexp.MustCompile("^[0-9a-f]{32}\\.socket$")
+
+// Orphan is a piece of data which isn't referenced by the state of the
+// cluster anymore
+type Orphan struct {
+	Kind string `json:"kind"`
+	// Node is the peer the orphan is found on. It isn't set for orphans in
+	// the store.
+	Node uuid.UUID `json:"node,omitempty"`
+	// Name is the path, brick, volume or transaction ID of the orphan,
+	// depending on its kind
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+
+	remove func() error
+}
+
+// ID returns a string which identifies the orphan on the node it is found on
+func (o *Orphan) ID() string {
+	return o.Kind + ":" + o.Name
+}
+
+// Remove cleans up the orphaned data
+func (o *Orphan) Remove() error {
+	return o.remove()
+}
+
+// FindLocal returns the orphaned files and portmap registrations on this node.
+// Nothing is returned if the volumes can't be read from the store, so that
+// data isn't mistaken as orphaned.
+func FindLocal() ([]*Orphan, error) {
+	volumes, err := volume.GetVolumes()
+	if err != nil {
+		return nil, err
+	}
+
+	var orphans []*Orphan
+	add := func(kind, name, reason string, remove func() error) {
+		orphans = append(orphans, &Orphan{
+			Kind:   kind,
+			Node:   gdctx.MyUUID,
+			Name:   name,
+			Reason: reason,
+			remove: remove,
+		})
+	}
+
+	vols := make(map[string]bool, len(volumes))
+	volfiles := make(map[string]bool)
+	bricks := make(map[string]bool)
+	for _, v := range volumes {
+		vols[v.Name] = true
+		for i := range v.Bricks {
+			b := &v.Bricks[i]
+			if !uuid.Equal(b.NodeID, gdctx.MyUUID) {
+				continue
+			}
+			volfiles[volgen.BrickVolfilePath(b)] = true
+			bricks[b.Path] = true
+		}
+	}
+
+	// Socket files are only checked if glusterfsd is installed, as their
+	// names are computed by the brick daemon
+	sockets, _ := brickSockets(volumes)
+
+	// Volume directories and brick volfiles
+	volsDir := path.Join(config.GetString("localstatedir"), "vols")
+	dirs, err := ioutil.ReadDir(volsDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		dir := path.Join(volsDir, d.Name())
+		if !vols[d.Name()] {
+			add(KindVolumeDir, dir, "volume does not exist", func() error {
+				return os.RemoveAll(dir)
+			})
+			continue
+		}
+
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		// Only the volfiles of the bricks of this node are looked at
+		prefix := d.Name() + "." + gdctx.MyUUID.String() + "."
+		for _, f := range files {
+			file := path.Join(dir, f.Name())
+			if f.IsDir() || !strings.HasPrefix(f.Name(), prefix) || !strings.HasSuffix(f.Name(), ".vol") || volfiles[file] {
+				continue
+			}
+			add(KindBrickVolfile, file, "brick is not part of the volume", func() error {
+				return os.Remove(file)
+			})
+		}
+	}
+
+	// Pidfiles and socket files
+	runDir := path.Join(config.GetString("rundir"), "gluster")
+	files, err := ioutil.ReadDir(runDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, f := range files {
+		file := path.Join(runDir, f.Name())
+		remove := func() error {
+			return os.Remove(file)
+		}
+
+		switch {
+		case strings.HasSuffix(f.Name(), ".pid"):
+			pid, err := daemon.ReadPidFromFile(file)
+			if err != nil {
+				continue
+			}
+			if _, err := daemon.GetProcess(pid); err != nil {
+				add(KindPidfile, file, "process is not running", remove)
+			}
+		case sockets != nil && brickSocketRegexp.MatchString(f.Name()):
+			if !sockets[file] {
+				add(KindSocket, file, "brick does not exist", remove)
+			}
+		}
+	}
+
+	// Portmap registrations
+	for _, b := range pmap.RegisteredBricks() {
+		if bricks[b] {
+			continue
+		}
+		brickname := b
+		add(KindPort, brickname, "brick does not exist", func() error {
+			pmap.RemoveBrick(brickname)
+			return nil
+		})
+	}
+
+	return orphans, nil
+}
+
+// brickSockets returns the paths of the socket files of the bricks of this
+// node
+func brickSockets(volumes []volume.Volinfo) (map[string]bool, error) {
+	sockets := make(map[string]bool)
+	for _, v := range volumes {
+		for _, b := range v.Bricks {
+			if !uuid.Equal(b.NodeID, gdctx.MyUUID) {
+				continue
+			}
+			d, err := brick.NewGlusterfsd(b)
+			if err != nil {
+				return nil, err
+			}
+			sockets[d.SocketFile()] = true
+		}
+	}
+	return sockets, nil
+}
+
+// FindStore returns the orphaned entries in the store. Nothing is returned if
+// the volumes can't be read from the store.
+func FindStore() ([]*Orphan, error) {
+	volumes, err := volume.GetVolumesList()
+	if err != nil {
+		return nil, err
+	}
+
+	var orphans []*Orphan
+
+	names, err := volgen.ClientVolfiles()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		if _, ok := volumes[name]; ok {
+			continue
+		}
+		volname := name
+		orphans = append(orphans, &Orphan{
+			Kind:   KindClientVolfile,
+			Name:   volname,
+			Reason: "volume does not exist",
+			remove: func() error {
+				return volgen.DeleteClientVolfile(&volume.Volinfo{Name: volname})
+			},
+		})
+	}
+
+	ids, err := transaction.OrphanedContexts()
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		txnid := id
+		orphans = append(orphans, &Orphan{
+			Kind:   KindTxnContext,
+			Name:   txnid,
+			Reason: "transaction is not in progress",
+			remove: func() error {
+				return transaction.DeleteContext(txnid)
+			},
+		})
+	}
+
+	vols, err := volume.GetVolumes()
+	if err != nil {
+		return nil, err
+	}
+	bricks := make(map[string]bool)
+	for _, v := range vols {
+		for _, b := range v.Bricks {
+			bricks[v.Name+":"+b.NodeID.String()+":"+b.Path] = true
+		}
+	}
+	caps, err := diskusage.GetCapacities("")
+	if err != nil {
+		return nil, err
+	}
+	for i := range caps {
+		c := &caps[i]
+		if bricks[c.Volume+":"+c.NodeID.String()+":"+c.Path] {
+			continue
+		}
+		orphans = append(orphans, &Orphan{
+			Kind:   KindBrickCapacity,
+			Name:   c.Volume + ":" + c.NodeID.String() + ":" + c.Path,
+			Reason: "brick does not exist",
+			remove: func() error {
+				return diskusage.DeleteCapacity(c)
+			},
+		})
+	}
+
+	return orphans, nil
+}
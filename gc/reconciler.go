@@ -0,0 +1,134 @@
+package gc
+
+import (
+	"context"
+	"time"
+
+	"github.com/gluster/glusterd2/leader"
+	"github.com/gluster/glusterd2/transaction"
+
+	log "github.com/Sirupsen/logrus"
+	config "github.com/spf13/viper"
+)
+
+// gracePeriod is the time data must have been found orphaned for before it is
+// cleaned up. Data created by a transaction, such as the directory of a new
+// volume, is orphaned until the transaction ends, so this is at least as long
+// as a transaction can run.
+const gracePeriod = transaction.DefaultTxnTimeout + time.Minute
+
+// pending keeps track of the time orphans were first found at, so that they
+// are only cleaned up once the grace period has passed
+type pending map[string]time.Time
+
+// reconcile removes the orphans which have been found orphaned for longer
+// than the grace period, and forgets about those which are no longer orphaned
+func (p pending) reconcile(orphans []*Orphan) {
+	now := time.Now()
+	found := make(map[string]bool, len(orphans))
+
+	for _, o := range orphans {
+		id := o.ID()
+		found[id] = true
+
+		first, ok := p[id]
+		if !ok {
+			p[id] = now
+			continue
+		}
+		if now.Sub(first) < gracePeriod {
+			continue
+		}
+
+		logger := log.WithFields(log.Fields{
+			"kind":   o.Kind,
+			"name":   o.Name,
+			"reason": o.Reason,
+		})
+		if err := o.Remove(); err != nil {
+			logger.WithError(err).Warn("failed to clean up orphaned data")
+			continue
+		}
+		logger.Info("cleaned up orphaned data")
+		delete(p, id)
+	}
+
+	for id := range p {
+		if !found[id] {
+			delete(p, id)
+		}
+	}
+}
+
+// interval returns the interval at which orphaned data is looked for, or 0 if
+// the reconciler is disabled
+func interval() time.Duration {
+	return time.Duration(config.GetInt("gc-interval")) * time.Second
+}
+
+// Reconciler periodically cleans up the orphaned data on this node. Orphaned
+// entries in the store are cleaned up by the leader of the cluster.
+// Data is only cleaned up once it has been found orphaned for longer than a
+// transaction can run, so that the data of in-flight transactions is left
+// alone.
+// It provides an implementation of the github.com/thejerf/suture.Service
+// interface.
+type Reconciler struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewReconciler returns a new Reconciler, and registers the job which cleans
+// up the store with the leader
+func NewReconciler() *Reconciler {
+	ctx, cancel := context.WithCancel(context.Background())
+	leader.RegisterJob("gc", reconcileStore)
+	return &Reconciler{ctx: ctx, cancel: cancel}
+}
+
+// Serve begins looking for orphaned data on this node
+func (r *Reconciler) Serve() {
+	log.Info("started orphaned data reconciler")
+	run(r.ctx, FindLocal)
+}
+
+// Stop stops the reconciler
+func (r *Reconciler) Stop() {
+	r.cancel()
+	log.Info("stopped orphaned data reconciler")
+}
+
+// reconcileStore is run on the leader, and cleans up the orphaned entries in
+// the store
+func reconcileStore(ctx context.Context) {
+	run(ctx, FindStore)
+}
+
+// run cleans up the orphans returned by find at every interval, until ctx is
+// done
+func run(ctx context.Context, find func() ([]*Orphan, error)) {
+	d := interval()
+	if d <= 0 {
+		<-ctx.Done()
+		return
+	}
+
+	ticker := time.NewTicker(d)
+	defer ticker.Stop()
+
+	p := make(pending)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		orphans, err := find()
+		if err != nil {
+			log.WithError(err).Warn("failed to look for orphaned data")
+			continue
+		}
+		p.reconcile(orphans)
+	}
+}
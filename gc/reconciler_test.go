@@ -0,0 +1,97 @@
+package gc
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gluster/glusterd2/tests"
+)
+
+// orphan returns an orphan which counts the times it is removed, and fails to
+// be removed if err is set
+func orphan(name string, removed *int, err error) *Orphan {
+	return &Orphan{
+		Kind: "file",
+		Name: name,
+		remove: func() error {
+			*removed++
+			return err
+		},
+	}
+}
+
+func TestReconcileGracePeriod(t *testing.T) {
+	var removed int
+	p := make(pending)
+	o := orphan("/var/run/a", &removed, nil)
+
+	// Orphans found for the first time are only remembered
+	p.reconcile([]*Orphan{o})
+	tests.Assert(t, removed == 0)
+	first, ok := p[o.ID()]
+	tests.Assert(t, ok)
+
+	// and are kept until the grace period has passed
+	p.reconcile([]*Orphan{o})
+	tests.Assert(t, removed == 0)
+	tests.Assert(t, p[o.ID()] == first)
+
+	p[o.ID()] = time.Now().Add(-gracePeriod)
+	p.reconcile([]*Orphan{o})
+	tests.Assert(t, removed == 1)
+	_, ok = p[o.ID()]
+	tests.Assert(t, !ok)
+}
+
+func TestReconcileNoLongerOrphaned(t *testing.T) {
+	var removed int
+	p := make(pending)
+	a := orphan("/var/run/a", &removed, nil)
+	b := orphan("/var/run/b", &removed, nil)
+
+	p.reconcile([]*Orphan{a, b})
+	tests.Assert(t, len(p) == 2)
+
+	// Data which is no longer orphaned is forgotten, and starts a new
+	// grace period if it is orphaned again
+	p[a.ID()] = time.Now().Add(-gracePeriod)
+	p.reconcile([]*Orphan{b})
+	tests.Assert(t, len(p) == 1)
+	_, ok := p[a.ID()]
+	tests.Assert(t, !ok)
+
+	p.reconcile([]*Orphan{a, b})
+	tests.Assert(t, removed == 0)
+	tests.Assert(t, time.Since(p[a.ID()]) < gracePeriod)
+}
+
+func TestReconcileRemoveFailed(t *testing.T) {
+	var removed int
+	p := make(pending)
+	o := orphan("/var/run/a", &removed, errors.New("busy"))
+
+	p.reconcile([]*Orphan{o})
+	p[o.ID()] = time.Now().Add(-gracePeriod)
+
+	// Orphans which fail to be removed are retried at the next interval
+	p.reconcile([]*Orphan{o})
+	tests.Assert(t, removed == 1)
+	_, ok := p[o.ID()]
+	tests.Assert(t, ok)
+
+	p.reconcile([]*Orphan{o})
+	tests.Assert(t, removed == 2)
+}
+
+func TestReconcileKinds(t *testing.T) {
+	var removed int
+	p := make(pending)
+	file := orphan("vol1", &removed, nil)
+	vol := orphan("vol1", &removed, nil)
+	vol.Kind = "volume"
+
+	// Orphans of different kinds with the same name are told apart
+	p.reconcile([]*Orphan{file, vol})
+	tests.Assert(t, len(p) == 2)
+}
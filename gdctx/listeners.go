@@ -0,0 +1,51 @@
+package gdctx
+
+import (
+	"sync"
+)
+
+// Names of the listeners whose state is tracked
+const (
+	RESTListener    = "rest"
+	PeerRPCListener = "peerrpc"
+	SunRPCListener  = "sunrpc"
+	// MgmtRPCListener is the listener serving the management gRPC API
+	MgmtRPCListener = "mgmtrpc"
+	// RESTSocketListener is the listener serving the REST API on the
+	// local UNIX socket
+	RESTSocketListener = "rest-socket"
+)
+
+var listeners = struct {
+	sync.RWMutex
+	up map[string]bool
+}{
+	up: make(map[string]bool),
+}
+
+// SetListenerState records whether the named listener is currently up and
+// accepting connections
+func SetListenerState(name string, up bool) {
+	listeners.Lock()
+	defer listeners.Unlock()
+
+	listeners.up[name] = up
+}
+
+// ListenerStates returns the last recorded state of the RESTListener,
+// PeerRPCListener, SunRPCListener and MgmtRPCListener
+func ListenerStates() map[string]bool {
+	listeners.RLock()
+	defer listeners.RUnlock()
+
+	states := map[string]bool{
+		RESTListener:    false,
+		PeerRPCListener: false,
+		SunRPCListener:  false,
+		MgmtRPCListener: false,
+	}
+	for name, up := range listeners.up {
+		states[name] = up
+	}
+	return states
+}
@@ -0,0 +1,236 @@
+// Package hooks runs the hook scripts and plugin hooks associated with
+// volume operations.
+//
+// Hook scripts are looked up in <localstatedir>/hooks/1/<op>/<phase>,
+// similar to GlusterD1. Only executable files whose names begin with 'S'
+// are run, in lexical order of their names, so scripts can be ordered by
+// naming them S10foo, S20bar and so on. Hooks registered in-process by
+// plugins are run before the scripts, in order of registration.
+//
+// A failing pre hook fails the operation, while failing post hooks are only
+// reported as the operation has already been done.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gluster/glusterd2/gdctx"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/pborman/uuid"
+	config "github.com/spf13/viper"
+)
+
+// Volume operations for which hooks are run
+const (
+	OpCreate   = "create"
+	OpStart    = "start"
+	OpStop     = "stop"
+	OpSet      = "set"
+	OpAddBrick = "add-brick"
+)
+
+// Phases of an operation in which hooks are run
+const (
+	Pre  = "pre"
+	Post = "post"
+)
+
+const (
+	// hooksVersion is the version of the hooks directory layout
+	hooksVersion = "1"
+
+	defaultTimeout = 30 * time.Second
+)
+
+// Hook is a hook registered in-process, usually by a plugin. It is given
+// the same environment as the hook scripts.
+type Hook func(op, phase string, env map[string]string) error
+
+type registeredHook struct {
+	name string
+	hook Hook
+}
+
+var envReplacer = strings.NewReplacer("-", "_", ".", "_")
+
+var (
+	registry     = make(map[string][]registeredHook)
+	registryLock sync.RWMutex
+)
+
+// Result is the result of running a single hook on a node
+type Result struct {
+	Hook   string    `json:"hook"`
+	NodeID uuid.UUID `json:"node-id"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// Failed returns true if the hook failed to run successfully
+func (r Result) Failed() bool {
+	return r.Error != ""
+}
+
+func registryKey(op, phase string) string {
+	return op + "/" + phase
+}
+
+// Register registers a hook with the given name to be run in the given phase
+// of the given operation
+func Register(op, phase, name string, h Hook) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	key := registryKey(op, phase)
+	registry[key] = append(registry[key], registeredHook{name, h})
+}
+
+// Dir returns the directory containing the hook scripts for the given phase
+// of the given operation
+func Dir(op, phase string) string {
+	return path.Join(config.GetString("localstatedir"), "hooks", hooksVersion, op, phase)
+}
+
+// timeout returns the time a single hook script is allowed to run, which can
+// be set in seconds with the hook-timeout config option
+func timeout() time.Duration {
+	if t := config.GetInt("hook-timeout"); t > 0 {
+		return time.Duration(t) * time.Second
+	}
+	return defaultTimeout
+}
+
+// scripts returns the paths of the hook scripts to be run for the given phase
+// of the given operation, in the order they are to be run
+func scripts(op, phase string) ([]string, error) {
+	dir := Dir(op, phase)
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var paths []string
+	for _, f := range files {
+		if !f.Mode().IsRegular() || f.Mode().Perm()&0111 == 0 {
+			continue
+		}
+		if !strings.HasPrefix(f.Name(), "S") {
+			continue
+		}
+		paths = append(paths, path.Join(dir, f.Name()))
+	}
+	sort.Strings(paths)
+
+	return paths, nil
+}
+
+// environ returns the environment for the hook scripts. The keys of env are
+// upper-cased, prefixed with GD2_ and have '-' and '.' replaced with '_'.
+func environ(op, phase string, env map[string]string) []string {
+	e := os.Environ()
+	e = append(e, "GD2_OP="+op, "GD2_PHASE="+phase, "GD2_NODE_ID="+gdctx.MyUUID.String())
+	for k, v := range env {
+		k = strings.ToUpper(envReplacer.Replace(k))
+		e = append(e, fmt.Sprintf("GD2_%s=%s", k, v))
+	}
+	return e
+}
+
+func runScript(script string, args, env []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout())
+	defer cancel()
+
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, script, args...)
+	cmd.Env = env
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timed out after %s", timeout())
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(out.String()); msg != "" {
+			return fmt.Errorf("%s: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// Run runs all the hooks for the given phase of the given operation on this
+// node, and returns the result of each hook. A failing hook does not prevent
+// the remaining hooks from being run.
+//
+// The hook scripts are given the volume name as the --volname argument, like
+// the GlusterD1 hook scripts, and env in their environment.
+func Run(op, phase string, env map[string]string) []Result {
+	var results []Result
+
+	registryLock.RLock()
+	registered := registry[registryKey(op, phase)]
+	registryLock.RUnlock()
+
+	for _, h := range registered {
+		r := Result{Hook: h.name, NodeID: gdctx.MyUUID}
+		if err := h.hook(op, phase, env); err != nil {
+			r.Error = err.Error()
+		}
+		results = append(results, r)
+	}
+
+	paths, err := scripts(op, phase)
+	if err != nil {
+		log.WithError(err).WithField("dir", Dir(op, phase)).Error("failed to read hooks directory")
+		results = append(results, Result{Hook: Dir(op, phase), NodeID: gdctx.MyUUID, Error: err.Error()})
+	}
+
+	var args []string
+	if volname, ok := env["volname"]; ok {
+		args = append(args, "--volname="+volname)
+	}
+	e := environ(op, phase, env)
+	for _, p := range paths {
+		r := Result{Hook: path.Base(p), NodeID: gdctx.MyUUID}
+		if err := runScript(p, args, e); err != nil {
+			r.Error = err.Error()
+		}
+		results = append(results, r)
+	}
+
+	for _, r := range results {
+		l := log.WithFields(log.Fields{"op": op, "phase": phase, "hook": r.Hook})
+		if r.Failed() {
+			l.WithField("error", r.Error).Error("hook failed")
+		} else {
+			l.Debug("hook ran successfully")
+		}
+	}
+
+	return results
+}
+
+// Failures returns the failed results among the given results
+func Failures(results []Result) []Result {
+	var failed []Result
+	for _, r := range results {
+		if r.Failed() {
+			failed = append(failed, r)
+		}
+	}
+	return failed
+}
@@ -0,0 +1,98 @@
+package hooks
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/gluster/glusterd2/tests"
+
+	config "github.com/spf13/viper"
+)
+
+// writeScript writes a hook script for the given phase of the operation
+func writeScript(t *testing.T, op, phase, name, body string, mode os.FileMode) {
+	dir := Dir(op, phase)
+	tests.Assert(t, os.MkdirAll(dir, 0755) == nil)
+	tests.Assert(t, ioutil.WriteFile(path.Join(dir, name), []byte("#!/bin/sh\n"+body+"\n"), mode) == nil)
+}
+
+func setupStateDir(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "hooks")
+	tests.Assert(t, err == nil)
+	config.Set("localstatedir", dir)
+	return func() {
+		config.Set("localstatedir", "")
+		os.RemoveAll(dir)
+	}
+}
+
+func TestRunOrder(t *testing.T) {
+	defer setupStateDir(t)()
+
+	const op = "test-order"
+	out := path.Join(config.GetString("localstatedir"), "out")
+	record := `echo "$(basename $0) $1 $GD2_VOLNAME $GD2_PHASE" >> ` + out
+
+	// Scripts are run in lexical order, and only executable scripts
+	// starting with S are run
+	writeScript(t, op, Pre, "S20second", record, 0755)
+	writeScript(t, op, Pre, "S10first", record, 0755)
+	writeScript(t, op, Pre, "K05killed", record, 0755)
+	writeScript(t, op, Pre, "S15notexec", record, 0644)
+	writeScript(t, op, Post, "S01post", record, 0755)
+
+	var calls []string
+	Register(op, Pre, "plugin", func(op, phase string, env map[string]string) error {
+		calls = append(calls, env["volname"])
+		return nil
+	})
+
+	results := Run(op, Pre, map[string]string{"volname": "vol1"})
+	tests.Assert(t, len(results) == 3)
+	tests.Assert(t, results[0].Hook == "plugin")
+	tests.Assert(t, results[1].Hook == "S10first")
+	tests.Assert(t, results[2].Hook == "S20second")
+	tests.Assert(t, len(Failures(results)) == 0)
+
+	// Plugin hooks are run before the scripts
+	tests.Assert(t, len(calls) == 1 && calls[0] == "vol1")
+	b, err := ioutil.ReadFile(out)
+	tests.Assert(t, err == nil)
+	tests.Assert(t, string(b) == "S10first --volname=vol1 vol1 pre\nS20second --volname=vol1 vol1 pre\n")
+}
+
+func TestRunFailures(t *testing.T) {
+	defer setupStateDir(t)()
+	config.Set("hook-timeout", 1)
+	defer config.Set("hook-timeout", 0)
+
+	const op = "test-failures"
+	writeScript(t, op, Post, "S10fail", "echo broken >&2; exit 3", 0755)
+	writeScript(t, op, Post, "S20slow", "exec sleep 10", 0755)
+	writeScript(t, op, Post, "S30ok", "exit 0", 0755)
+	Register(op, Post, "plugin", func(op, phase string, env map[string]string) error {
+		return errors.New("plugin failed")
+	})
+
+	// A failing hook does not prevent the remaining hooks from being run
+	results := Run(op, Post, nil)
+	tests.Assert(t, len(results) == 4)
+
+	failed := Failures(results)
+	tests.Assert(t, len(failed) == 3)
+	tests.Assert(t, failed[0].Hook == "plugin" && failed[0].Error == "plugin failed")
+	tests.Assert(t, failed[1].Hook == "S10fail" && strings.HasSuffix(failed[1].Error, ": broken"))
+	tests.Assert(t, failed[2].Hook == "S20slow" && failed[2].Error == "timed out after 1s")
+	tests.Assert(t, results[3].Hook == "S30ok" && !results[3].Failed())
+}
+
+func TestRunWithoutScripts(t *testing.T) {
+	defer setupStateDir(t)()
+
+	// A missing hooks directory is not an error
+	tests.Assert(t, len(Run("test-none", Pre, nil)) == 0)
+}
@@ -0,0 +1,108 @@
+package hooks
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gluster/glusterd2/gdctx"
+	"github.com/gluster/glusterd2/transaction"
+
+	"github.com/pborman/uuid"
+)
+
+const (
+	txnOpKey          = "hooks.op"
+	txnEnvKey         = "hooks.env"
+	txnPostResultsKey = "hooks.postresults"
+)
+
+func getTxnCtx(c transaction.TxnCtx) (string, map[string]string, error) {
+	var op string
+	if err := c.Get(txnOpKey, &op); err != nil {
+		return "", nil, err
+	}
+	var env map[string]string
+	if err := c.Get(txnEnvKey, &env); err != nil {
+		return "", nil, err
+	}
+	return op, env, nil
+}
+
+// runPreHooks runs the pre hooks of the operation set in the transaction
+// context, and fails if any of the hooks fail
+func runPreHooks(c transaction.TxnCtx) error {
+	op, env, err := getTxnCtx(c)
+	if err != nil {
+		return err
+	}
+
+	failed := Failures(Run(op, Pre, env))
+	if len(failed) == 0 {
+		return nil
+	}
+
+	msgs := make([]string, 0, len(failed))
+	for _, f := range failed {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", f.Hook, f.Error))
+	}
+	return fmt.Errorf("pre hooks failed on node %s: %s", gdctx.MyUUID, strings.Join(msgs, "; "))
+}
+
+// runPostHooks runs the post hooks of the operation set in the transaction
+// context. Failing post hooks do not fail the transaction as the operation
+// has already been done, they are only reported.
+func runPostHooks(c transaction.TxnCtx) error {
+	op, env, err := getTxnCtx(c)
+	if err != nil {
+		return err
+	}
+
+	results := Run(op, Post, env)
+	return c.SetNodeResult(gdctx.MyUUID, txnPostResultsKey, results)
+}
+
+// RegisterStepFuncs registers the transaction step functions used to run hooks
+func RegisterStepFuncs() {
+	transaction.RegisterStepFunc(runPreHooks, "hooks.RunPre")
+	transaction.RegisterStepFunc(runPostHooks, "hooks.RunPost")
+}
+
+// SetTxnCtx sets the operation and the hook environment for the hook steps
+// of a transaction
+func SetTxnCtx(c transaction.TxnCtx, op string, env map[string]string) error {
+	if err := c.Set(txnOpKey, op); err != nil {
+		return err
+	}
+	return c.Set(txnEnvKey, env)
+}
+
+// PreStep returns a transaction step which runs the pre hooks on the given
+// nodes. SetTxnCtx must have been called on the transaction context.
+func PreStep(nodes []uuid.UUID) *transaction.Step {
+	return &transaction.Step{
+		DoFunc: "hooks.RunPre",
+		Nodes:  nodes,
+	}
+}
+
+// PostStep returns a transaction step which runs the post hooks on the given
+// nodes. SetTxnCtx must have been called on the transaction context.
+func PostStep(nodes []uuid.UUID) *transaction.Step {
+	return &transaction.Step{
+		DoFunc: "hooks.RunPost",
+		Nodes:  nodes,
+	}
+}
+
+// PostFailures returns the post hooks which failed on the given nodes
+func PostFailures(c transaction.TxnCtx, nodes []uuid.UUID) []Result {
+	var failed []Result
+	for _, node := range nodes {
+		var results []Result
+		if err := c.GetNodeResult(node, txnPostResultsKey, &results); err != nil {
+			continue
+		}
+		failed = append(failed, Failures(results)...)
+	}
+	return failed
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	config "github.com/spf13/viper"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	lockFileName = "glusterd2.lock"
+	pidFileName  = "glusterd2.pid"
+)
+
+// instance holds the files locked by this instance of GlusterD, for as long
+// as it runs
+type instance struct {
+	lock    *os.File
+	pidFile *os.File
+}
+
+// lockFile opens and locks the file, failing if another process has it locked.
+// The pid of the process holding the lock is returned along with the error,
+// if it could be read.
+func lockFile(file string) (*os.File, error) {
+	f, err := os.OpenFile(file, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := unix.Flock(int(f.Fd()), unix.LOCK_EX|unix.LOCK_NB); err != nil {
+		f.Close()
+		if err != unix.EWOULDBLOCK {
+			return nil, err
+		}
+		owner := "another process"
+		if pid, err := readPid(file); err == nil {
+			owner = fmt.Sprintf("process %d", pid)
+		}
+		return nil, fmt.Errorf("%s is locked by %s", file, owner)
+	}
+	return f, nil
+}
+
+func readPid(file string) (int, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(b)))
+}
+
+func writePid(f *os.File) error {
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	_, err := f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	return err
+}
+
+// lockInstance makes sure that no other instance of GlusterD uses the local
+// state and runtime directories of this instance, so that several instances
+// can safely run on the same host with directories of their own. The pid of
+// this instance is written to the lock file of the local state directory and
+// to the pidfile of the runtime directory.
+func lockInstance() (*instance, error) {
+	lock, err := lockFile(path.Join(config.GetString("localstatedir"), lockFileName))
+	if err != nil {
+		return nil, fmt.Errorf("local state directory is used by another instance of GlusterD: %s", err.Error())
+	}
+	pidFile, err := lockFile(path.Join(config.GetString("rundir"), pidFileName))
+	if err != nil {
+		lock.Close()
+		return nil, fmt.Errorf("runtime directory is used by another instance of GlusterD: %s", err.Error())
+	}
+
+	for _, f := range []*os.File{lock, pidFile} {
+		if err := writePid(f); err != nil {
+			lock.Close()
+			pidFile.Close()
+			return nil, err
+		}
+	}
+	return &instance{lock: lock, pidFile: pidFile}, nil
+}
+
+// unlock removes the pidfile and releases the locks of the instance
+func (i *instance) unlock() {
+	if err := os.Remove(i.pidFile.Name()); err != nil {
+		log.WithError(err).Warn("failed to remove pidfile")
+	}
+	i.pidFile.Close()
+	i.lock.Close()
+}
+
+// checkDirPermissions warns about directories of GlusterD which can be
+// written to by other users, as they hold the state and the sockets of
+// GlusterD and its bricks
+func checkDirPermissions(dirs []string) {
+	for _, d := range dirs {
+		info, err := os.Stat(d)
+		if err != nil {
+			continue
+		}
+		if mode := info.Mode(); mode&0002 != 0 && mode&os.ModeSticky == 0 {
+			log.WithFields(log.Fields{
+				"path": d,
+				"mode": mode.String(),
+			}).Warn("directory is writable by all users")
+		}
+	}
+}
@@ -0,0 +1,196 @@
+// Package leader elects one peer of the cluster as the leader, which runs the
+// cluster wide background jobs that must not run on more than one peer at a
+// time
+package leader
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gluster/glusterd2/events"
+	"github.com/gluster/glusterd2/gdctx"
+	"github.com/gluster/glusterd2/store"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/coreos/etcd/clientv3/concurrency"
+	"github.com/pborman/uuid"
+	config "github.com/spf13/viper"
+)
+
+// Names of the events emitted when the leadership changes
+const (
+	EventLeaderElected = "leader.elected"
+	EventLeaderLost    = "leader.lost"
+)
+
+const (
+	electionPrefix = store.GlusterPrefix + "leader"
+
+	// defaultLeaderTTL is the time in seconds after which the leadership
+	// of a peer which stopped refreshing its session expires, and another
+	// peer takes over, if peer-timeout isn't set
+	defaultLeaderTTL = 10
+
+	// retryInterval is the time waited before campaigning again after a
+	// failure to reach the store
+	retryInterval = 5 * time.Second
+)
+
+// Job is a background job run only on the leader. It is started when the peer
+// becomes the leader, and must return once ctx is done, which happens when the
+// peer loses the leadership or GlusterD stops.
+type Job func(ctx context.Context)
+
+var (
+	jobsMutex sync.Mutex
+	jobs      = make(map[string]Job)
+
+	isLeader bool
+	mutex    sync.RWMutex
+)
+
+// RegisterJob registers a job to be run on the leader. Jobs must be
+// registered before the Elector is started.
+func RegisterJob(name string, job Job) {
+	jobsMutex.Lock()
+	defer jobsMutex.Unlock()
+	jobs[name] = job
+}
+
+// IsLeader returns true if this peer is currently the leader
+func IsLeader() bool {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return isLeader
+}
+
+func setLeader(leader bool) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	isLeader = leader
+}
+
+// electionKey returns the key the election is held on. The election is not
+// done through the store, so the key is namespaced with the cluster ID here,
+// for peers of different clusters sharing a store not to elect each other.
+func electionKey() string {
+	return store.Store.NamespaceKey(electionPrefix)
+}
+
+// Leader returns the ID of the current leader of the cluster
+func Leader() (uuid.UUID, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := concurrency.NewElection(store.Store.Session, electionKey()).Leader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return uuid.Parse(string(resp.Kvs[0].Value)), nil
+}
+
+// Elector campaigns for this peer to become the leader, and runs the
+// registered jobs while it is. If the leader dies or loses its connection to
+// the store, its session expires and another peer takes over.
+// It provides an implementation of the github.com/thejerf/suture.Service
+// interface.
+type Elector struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewElector returns a new Elector
+func NewElector() *Elector {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Elector{ctx: ctx, cancel: cancel}
+}
+
+// Serve begins campaigning for the leadership
+func (e *Elector) Serve() {
+	log.Info("started leader elector")
+	for {
+		if err := e.campaign(); err != nil && e.ctx.Err() == nil {
+			log.WithError(err).Warn("failed to campaign for leadership")
+		}
+
+		select {
+		case <-e.ctx.Done():
+			return
+		case <-time.After(retryInterval):
+		}
+	}
+}
+
+// Stop stops campaigning, and gives up the leadership if this peer is the
+// leader
+func (e *Elector) Stop() {
+	e.cancel()
+	log.Info("stopped leader elector")
+}
+
+// campaign waits for this peer to be elected, and runs the jobs until the
+// leadership is lost
+func (e *Elector) campaign() error {
+	// The leadership expires when the leader would be seen as offline
+	ttl := config.GetInt("peer-timeout")
+	if ttl <= 0 {
+		ttl = defaultLeaderTTL
+	}
+
+	// Every term gets its own session, which is lost if the peer can't
+	// refresh it within the TTL
+	session, err := concurrency.NewSession(store.Store.Client, concurrency.WithTTL(ttl))
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	election := concurrency.NewElection(session, electionKey())
+	if err := election.Campaign(e.ctx, gdctx.MyUUID.String()); err != nil {
+		return err
+	}
+
+	setLeader(true)
+	log.Info("elected as the leader of the cluster")
+	data := map[string]string{"peer.id": gdctx.MyUUID.String()}
+	events.Broadcast(events.New(EventLeaderElected, data))
+
+	ctx, cancel := context.WithCancel(e.ctx)
+	wg := runJobs(ctx)
+
+	select {
+	case <-e.ctx.Done():
+	case <-session.Done():
+		log.Warn("lost the leadership of the cluster")
+	}
+
+	cancel()
+	wg.Wait()
+	setLeader(false)
+	events.Broadcast(events.New(EventLeaderLost, data))
+
+	// Resign so that another peer can take over right away, instead of
+	// once the session expires
+	rctx, rcancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer rcancel()
+	return election.Resign(rctx)
+}
+
+// runJobs starts every registered job in its own goroutine
+func runJobs(ctx context.Context) *sync.WaitGroup {
+	jobsMutex.Lock()
+	defer jobsMutex.Unlock()
+
+	var wg sync.WaitGroup
+	for name, job := range jobs {
+		wg.Add(1)
+		go func(name string, job Job) {
+			defer wg.Done()
+			log.WithField("job", name).Debug("starting leader job")
+			job(ctx)
+			log.WithField("job", name).Debug("leader job stopped")
+		}(name, job)
+	}
+	return &wg
+}
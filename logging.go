@@ -7,6 +7,8 @@ import (
 	"path"
 	"strings"
 
+	"github.com/gluster/glusterd2/logging"
+
 	log "github.com/Sirupsen/logrus"
 )
 
@@ -25,7 +27,7 @@ func setLogOutput(w io.Writer) {
 	stdlog.SetOutput(log.StandardLogger().Writer())
 }
 
-func initLog(logdir string, logFileName string, logLevel string) error {
+func initLog(logdir string, logFileName string, logLevel string, logFormat string, logLevels string) error {
 	// Close the previously opened Log file
 	if logWriter != nil {
 		logWriter.Close()
@@ -38,8 +40,21 @@ func initLog(logdir string, logFileName string, logLevel string) error {
 		log.WithError(err).Debug("Failed to parse log level")
 		return err
 	}
-	log.SetLevel(l)
-	log.SetFormatter(&log.TextFormatter{FullTimestamp: true})
+	levels, err := logging.ParseLevels(logLevels)
+	if err != nil {
+		setLogOutput(os.Stderr)
+		log.WithError(err).Debug("Failed to parse subsystem log levels")
+		return err
+	}
+	formatter, err := logging.NewFormatter(logFormat)
+	if err != nil {
+		setLogOutput(os.Stderr)
+		log.WithError(err).Debug("Failed to set log format")
+		return err
+	}
+	lf := &logging.LevelFormatter{Formatter: formatter, Level: l, Levels: levels}
+	log.SetLevel(lf.MaxLevel())
+	log.SetFormatter(lf)
 
 	if strings.ToLower(logFileName) == "stderr" || logFileName == "-" {
 		setLogOutput(os.Stderr)
@@ -0,0 +1,118 @@
+// Package logging implements the log format and the per-subsystem log levels
+// of GlusterD.
+//
+// The log lines of a subsystem carry the subsystem field, and are logged at
+// the level set for the subsystem with the loglevels option, falling back to
+// the level set with the loglevel option.
+package logging
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// SubsystemField is the log field holding the subsystem of a log line
+const SubsystemField = "subsystem"
+
+// Subsystems whose log level can be set separately
+const (
+	Txn    = "txn"
+	Store  = "store"
+	Rest   = "rest"
+	Volgen = "volgen"
+)
+
+var subsystems = []string{Txn, Store, Rest, Volgen}
+
+// Subsystem returns the logger for the given subsystem
+func Subsystem(name string) *log.Entry {
+	return log.WithField(SubsystemField, name)
+}
+
+// NewFormatter returns the formatter for the given log format, which is
+// either text or json
+func NewFormatter(format string) (log.Formatter, error) {
+	switch strings.ToLower(format) {
+	case "", "text":
+		return &log.TextFormatter{FullTimestamp: true}, nil
+	case "json":
+		return &log.JSONFormatter{}, nil
+	}
+	return nil, fmt.Errorf("unknown log format %s", format)
+}
+
+// ParseLevels parses the log levels of subsystems given as a comma separated
+// list of subsystem=level pairs, eg. "txn=debug,store=warning"
+func ParseLevels(s string) (map[string]log.Level, error) {
+	levels := make(map[string]log.Level)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid subsystem log level %s", pair)
+		}
+		name := strings.TrimSpace(kv[0])
+		if !isSubsystem(name) {
+			return nil, fmt.Errorf("unknown subsystem %s, must be one of %s", name, strings.Join(subsystems, ", "))
+		}
+		l, err := log.ParseLevel(strings.ToLower(strings.TrimSpace(kv[1])))
+		if err != nil {
+			return nil, err
+		}
+		levels[name] = l
+	}
+	return levels, nil
+}
+
+func isSubsystem(name string) bool {
+	for _, s := range subsystems {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+// LevelFormatter formats log entries with Formatter, dropping the entries of
+// subsystems below the level set for the subsystem in Levels, and all other
+// entries below Level.
+//
+// The logger must be set to the level returned by MaxLevel, so that the
+// entries of subsystems logging more than the others reach the formatter.
+type LevelFormatter struct {
+	log.Formatter
+	Level  log.Level
+	Levels map[string]log.Level
+}
+
+// Format implements the logrus.Formatter interface
+func (f *LevelFormatter) Format(e *log.Entry) ([]byte, error) {
+	level := f.Level
+	if s, ok := e.Data[SubsystemField].(string); ok {
+		if l, ok := f.Levels[s]; ok {
+			level = l
+		}
+	}
+	if e.Level > level {
+		// Nothing is written for empty output
+		return nil, nil
+	}
+	return f.Formatter.Format(e)
+}
+
+// MaxLevel returns the most verbose of the levels of the formatter
+func (f *LevelFormatter) MaxLevel() log.Level {
+	max := f.Level
+	for _, l := range f.Levels {
+		if l > max {
+			max = l
+		}
+	}
+	return max
+}
@@ -0,0 +1,52 @@
+package logging
+
+import (
+	"testing"
+
+	"github.com/gluster/glusterd2/tests"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+func TestParseLevels(t *testing.T) {
+	levels, err := ParseLevels(" txn=debug, store=WARNING,")
+	tests.Assert(t, err == nil)
+	tests.Assert(t, len(levels) == 2)
+	tests.Assert(t, levels[Txn] == log.DebugLevel)
+	tests.Assert(t, levels[Store] == log.WarnLevel)
+
+	levels, err = ParseLevels("")
+	tests.Assert(t, err == nil && len(levels) == 0)
+
+	for _, s := range []string{"txn", "foo=debug", "txn=loud"} {
+		_, err = ParseLevels(s)
+		tests.Assert(t, err != nil)
+	}
+}
+
+func TestLevelFormatter(t *testing.T) {
+	f := &LevelFormatter{
+		Formatter: &log.JSONFormatter{},
+		Level:     log.InfoLevel,
+		Levels:    map[string]log.Level{Txn: log.DebugLevel, Store: log.ErrorLevel},
+	}
+	tests.Assert(t, f.MaxLevel() == log.DebugLevel)
+
+	logged := func(subsystem string, level log.Level) bool {
+		e := log.NewEntry(log.StandardLogger())
+		if subsystem != "" {
+			e = e.WithField(SubsystemField, subsystem)
+		}
+		e.Level = level
+		b, err := f.Format(e)
+		tests.Assert(t, err == nil)
+		return len(b) != 0
+	}
+
+	tests.Assert(t, logged(Txn, log.DebugLevel))
+	tests.Assert(t, !logged(Store, log.WarnLevel))
+	tests.Assert(t, logged(Store, log.ErrorLevel))
+	tests.Assert(t, logged(Rest, log.InfoLevel))
+	tests.Assert(t, !logged(Rest, log.DebugLevel))
+	tests.Assert(t, !logged("", log.DebugLevel))
+}
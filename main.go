@@ -1,15 +1,26 @@
 package main
 
 import (
+	"fmt"
 	"os"
 	"os/signal"
 	"path"
-	"strings"
 
+	"github.com/gluster/glusterd2/brickhealth"
+	"github.com/gluster/glusterd2/clusteroptions"
+	snapshotcommands "github.com/gluster/glusterd2/commands/snapshot"
+	"github.com/gluster/glusterd2/gc"
 	"github.com/gluster/glusterd2/gdctx"
+	"github.com/gluster/glusterd2/leader"
 	"github.com/gluster/glusterd2/peer"
+	"github.com/gluster/glusterd2/plugins"
+	"github.com/gluster/glusterd2/privileges"
+	"github.com/gluster/glusterd2/quorum"
 	"github.com/gluster/glusterd2/servers"
+	"github.com/gluster/glusterd2/snapshot"
 	"github.com/gluster/glusterd2/store"
+	"github.com/gluster/glusterd2/tracing"
+	"github.com/gluster/glusterd2/transaction"
 	"github.com/gluster/glusterd2/utils"
 	"github.com/gluster/glusterd2/version"
 	"github.com/gluster/glusterd2/xlator"
@@ -38,8 +49,10 @@ func main() {
 	logLevel, _ := flag.CommandLine.GetString("loglevel")
 	logdir, _ := flag.CommandLine.GetString("logdir")
 	logFileName, _ := flag.CommandLine.GetString("logfile")
+	logFormat, _ := flag.CommandLine.GetString("logformat")
+	logLevels, _ := flag.CommandLine.GetString("loglevels")
 
-	if err := initLog(logdir, logFileName, logLevel); err != nil {
+	if err := initLog(logdir, logFileName, logLevel, logFormat, logLevels); err != nil {
 		log.WithError(err).Fatal("Failed to initialize logging")
 	}
 
@@ -64,6 +77,21 @@ func main() {
 		log.WithError(err).Fatal("Failed to create or access directories")
 	}
 
+	inst, err := lockInstance()
+	if err != nil {
+		log.WithError(err).Fatal("Failed to lock directories of GlusterD")
+	}
+
+	if err := initPrivileges(); err != nil {
+		log.WithError(err).Fatal("Failed to run with the available privileges")
+	}
+
+	// Logging options may have been given in the config file or the
+	// environment as well
+	if err := reinitLog(); err != nil {
+		log.WithError(err).Fatal("Failed to initialize logging")
+	}
+
 	if err := gdctx.SetUUID(); err != nil {
 		log.WithError(err).Fatal("Failed to initialize UUID")
 	}
@@ -75,6 +103,14 @@ func main() {
 		log.WithError(err).Warn("Failed to load xlator options")
 	}
 
+	// Add volfile xlators of plugins before any volfiles are generated
+	plugins.RegisterXlators()
+
+	tracer, err := tracing.Init()
+	if err != nil {
+		log.WithError(err).Fatal("Failed to initialize tracing")
+	}
+
 	// Initialize etcd store (etcd client connection)
 	if err := store.Init(nil); err != nil {
 		log.WithError(err).Fatal("Failed to initialize store (etcd client)")
@@ -84,11 +120,35 @@ func main() {
 		log.WithError(err).Fatal("Could not add self details into etcd")
 	}
 
+	// Bricks of volumes cloned from snapshots are mounted in the runtime
+	// directory, which doesn't survive reboots
+	if err := snapshot.MountClones(); err != nil {
+		log.WithError(err).Error("Failed to mount bricks of cloned volumes")
+	}
+
 	// Start all servers (rest, peerrpc, sunrpc) managed by suture supervisor
 	super := initGD2Supervisor()
 	super.ServeBackground()
 	super.Add(servers.New())
+
+	// Roll back the transactions left in-flight by crashed initiators, now
+	// that all step functions have been registered
+	if err := transaction.Recover(); err != nil {
+		log.WithError(err).Error("Failed to recover in-flight transactions")
+	}
+
+	super.Add(peer.NewLivenessWatcher())
+	super.Add(clusteroptions.NewWatcher())
+	super.Add(quorum.NewMonitor())
+	super.Add(gc.NewReconciler())
+	super.Add(brickhealth.NewMonitor())
+	super.Add(brickhealth.NewMountWatcher())
+	plugins.AddServices(super)
 	addMgmtService(super)
+	leader.RegisterJob("snapshot-scheduler", snapshotcommands.RunScheduler)
+	// The elector is added last, as jobs are registered by the other
+	// services before they are run
+	super.Add(leader.NewElector())
 
 	// Use the main goroutine as signal handling loop
 	sigCh := make(chan os.Signal)
@@ -102,16 +162,20 @@ func main() {
 			log.Info("Received SIGTERM. Stopping GlusterD")
 			super.Stop()
 			store.Close()
+			tracer.Close()
+			inst.unlock()
 			log.Info("Stopped GlusterD")
 			return
 		case unix.SIGHUP:
-			// Logrotate case, when Log rotated, Reopen the log file and
-			// re-initiate the logger instance.
-			if strings.ToLower(logFileName) != "stderr" && strings.ToLower(logFileName) != "stdout" && logFileName != "-" {
-				log.Info("Received SIGHUP, Reloading log file")
-				if err := initLog(logdir, logFileName, logLevel); err != nil {
-					log.WithError(err).Fatal("Could not re-initialize logging")
-				}
+			// Reload the config file and re-initiate the logger
+			// instance. This also reopens the log file when it has
+			// been rotated.
+			log.Info("Received SIGHUP, Reloading configuration")
+			if err := reloadConfig(); err != nil {
+				log.WithError(err).Error("Could not reload configuration")
+			}
+			if err := reinitLog(); err != nil {
+				log.WithError(err).Fatal("Could not re-initialize logging")
 			}
 		default:
 			continue
@@ -126,16 +190,45 @@ func initGD2Supervisor() *suture.Supervisor {
 	return suture.New("gd2-main", suture.Spec{Log: superlogger})
 }
 
+// createDirectories creates the directories of this instance of GlusterD, and
+// checks that they can be written to
 func createDirectories() error {
 	dirs := []string{config.GetString("localstatedir"),
 		config.GetString("rundir"), config.GetString("logdir"),
 		path.Join(config.GetString("rundir"), "gluster"),
 		path.Join(config.GetString("logdir"), "glusterfs/bricks"),
+		path.Dir(config.GetString("restsocket")),
 	}
 	for _, dirpath := range dirs {
 		if err := utils.InitDir(dirpath); err != nil {
-			return err
+			return fmt.Errorf("%s: %s", dirpath, err.Error())
 		}
 	}
+	checkDirPermissions(dirs)
 	return nil
 }
+
+// initPrivileges checks that GlusterD has the privileges it needs, and gives
+// the configured group access to the runtime directory
+func initPrivileges() error {
+	if err := privileges.Check(config.GetString("clientaddress"), config.GetString("peeraddress")); err != nil {
+		return err
+	}
+
+	group := config.GetString("group")
+	if group == "" {
+		return nil
+	}
+	gid, err := privileges.LookupGroup(group)
+	if err != nil {
+		return err
+	}
+	return privileges.SetGroupOwnership(config.GetString("rundir"), gid, 0770)
+}
+
+// reinitLog re-initializes logging with the log options in the current
+// configuration
+func reinitLog() error {
+	return initLog(config.GetString("logdir"), config.GetString("logfile"), config.GetString("loglevel"),
+		config.GetString("logformat"), config.GetString("loglevels"))
+}
@@ -0,0 +1,299 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/store"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/coreos/etcd/clientv3"
+	config "github.com/spf13/viper"
+)
+
+const (
+	// IdempotencyKeyHeader is the header clients set to make retries of a
+	// request safe
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set on responses replayed for a retried
+	// request
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	idempotencyPrefix  = store.GlusterPrefix + "idempotency/"
+	idempotencyTimeout = 10 * time.Second
+
+	// idempotencyInProgressTTL is the TTL in seconds of the lease of the
+	// record of a request being served. The lease is kept alive until the
+	// request is served, so that the record of a request whose peer dies
+	// expires soon and the request can be retried.
+	idempotencyInProgressTTL = 30
+
+	// idempotencyMaxBody is the largest response body saved to be
+	// replayed, well below the size of requests the store accepts
+	idempotencyMaxBody = 256 * 1024
+)
+
+// Error codes returned for requests with an idempotency key
+const (
+	ErrCodeIdempotencyInProgress = "idempotency-key-in-progress"
+	ErrCodeIdempotencyMismatch   = "idempotency-key-mismatch"
+)
+
+// idempotencyRecord is saved in the store for every idempotency key, to
+// replay the response of the request for its retries
+type idempotencyRecord struct {
+	// Fingerprint identifies the request the key was first used for
+	Fingerprint string `json:"fingerprint"`
+	ReqID       string `json:"reqid"`
+	Done        bool   `json:"done"`
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"content-type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// recordingWriter keeps a copy of the response, up to idempotencyMaxBody
+// bytes of its body
+type recordingWriter struct {
+	http.ResponseWriter
+	status    int
+	body      bytes.Buffer
+	truncated bool
+}
+
+func (w *recordingWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	if !w.truncated {
+		if w.body.Len()+len(b) > idempotencyMaxBody {
+			w.truncated = true
+			w.body.Reset()
+		} else {
+			w.body.Write(b)
+		}
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func fingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.String() + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func idempotencyStoreKey(key string) string {
+	h := sha256.Sum256([]byte(key))
+	return idempotencyPrefix + hex.EncodeToString(h[:])
+}
+
+// claimIdempotencyKey saves the record for a new idempotency key, unless the
+// key has been used already, in which case the existing record is returned.
+// The record expires with the lease.
+func claimIdempotencyKey(key string, rec *idempotencyRecord, lease clientv3.LeaseID) (*idempotencyRecord, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), idempotencyTimeout)
+	defer cancel()
+
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return nil, err
+	}
+
+	skey := idempotencyStoreKey(key)
+	resp, err := store.Store.Txn(ctx).If(
+		clientv3.Compare(clientv3.CreateRevision(skey), "=", 0),
+	).Then(
+		clientv3.OpPut(skey, string(b), clientv3.WithLease(lease)),
+	).Else(
+		clientv3.OpGet(skey),
+	).Commit()
+	if err != nil {
+		return nil, err
+	}
+	if resp.Succeeded {
+		return nil, nil
+	}
+
+	kvs := resp.Responses[0].GetResponseRange().Kvs
+	if len(kvs) == 0 {
+		// The record expired right after the comparison; the
+		// request is reported in progress so that it is retried
+		return &idempotencyRecord{Fingerprint: rec.Fingerprint}, nil
+	}
+	var existing idempotencyRecord
+	if err := json.Unmarshal(kvs[0].Value, &existing); err != nil {
+		return nil, err
+	}
+	return &existing, nil
+}
+
+// grantLease grants a lease with the given TTL in seconds
+func grantLease(ttl int64) (clientv3.LeaseID, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), idempotencyTimeout)
+	defer cancel()
+
+	resp, err := store.Store.Client.Grant(ctx, ttl)
+	if err != nil {
+		return 0, err
+	}
+	return resp.ID, nil
+}
+
+// keepLeaseAlive keeps the lease alive until the returned function is called
+func keepLeaseAlive(lease clientv3.LeaseID, logger log.FieldLogger) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := store.Store.Client.KeepAlive(ctx, lease)
+	if err != nil {
+		logger.WithError(err).Warn("failed to keep idempotency key alive")
+		return cancel
+	}
+	go func() {
+		for range ch {
+		}
+	}()
+	return cancel
+}
+
+// revokeLease revokes the lease, deleting the keys attached to it
+func revokeLease(lease clientv3.LeaseID, logger log.FieldLogger) {
+	ctx, cancel := context.WithTimeout(context.Background(), idempotencyTimeout)
+	defer cancel()
+
+	if _, err := store.Store.Client.Revoke(ctx, lease); err != nil {
+		logger.WithError(err).Warn("failed to revoke lease of idempotency key")
+	}
+}
+
+// saveResponse saves the record of a served request, with a lease with the
+// given TTL in seconds, in place of the record of the request in progress
+func saveResponse(key string, rec *idempotencyRecord, ttl int64) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	lease, err := grantLease(ttl)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), idempotencyTimeout)
+	defer cancel()
+	if _, err = store.Store.Put(ctx, idempotencyStoreKey(key), string(b), clientv3.WithLease(lease)); err != nil {
+		store.Store.Client.Revoke(context.TODO(), lease)
+	}
+	return err
+}
+
+// Idempotency is a middleware which makes retries of requests carrying an
+// Idempotency-Key header safe. The response of the first request with a key
+// is saved in the store, and replayed for the requests retried with the same
+// key, on any peer, for idempotency-key-ttl seconds. Requests retried while
+// the first request is being served are rejected with 409 Conflict, and
+// requests reusing a key for a different request are rejected with 422.
+//
+// Responses with server errors are not saved, so that the request is served
+// again when retried. Responses with bodies larger than idempotencyMaxBody
+// are replayed without their body. Routes producing anything but JSON, like
+// streams and archives, must not use this middleware.
+func Idempotency(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" || r.Method == "GET" || r.Method == "HEAD" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		reqID, logger := restutils.GetReqIDandLogger(r)
+		logger = logger.WithField("idempotency-key", key)
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			restutils.SendHTTPError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		lease, err := grantLease(idempotencyInProgressTTL)
+		if err != nil {
+			logger.WithError(err).Error("failed to save idempotency key")
+			restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		rec := &idempotencyRecord{Fingerprint: fingerprint(r, body), ReqID: reqID}
+		existing, err := claimIdempotencyKey(key, rec, lease)
+		if err != nil {
+			revokeLease(lease, logger)
+			logger.WithError(err).Error("failed to save idempotency key")
+			restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		if existing != nil {
+			revokeLease(lease, logger)
+			replayIdempotent(w, rec, existing, logger)
+			return
+		}
+
+		stop := keepLeaseAlive(lease, logger)
+		rw := &recordingWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rw, r)
+		stop()
+
+		// Revoking the lease of the request in progress deletes its
+		// record, unless the response has been saved in its place
+		defer revokeLease(lease, logger)
+
+		if rw.status >= http.StatusInternalServerError {
+			return
+		}
+
+		rec.Done = true
+		rec.Status = rw.status
+		if !rw.truncated {
+			rec.ContentType = rw.Header().Get("Content-Type")
+			rec.Body = rw.body.Bytes()
+		}
+		if err := saveResponse(key, rec, int64(config.GetInt("idempotency-key-ttl"))); err != nil {
+			logger.WithError(err).Warn("failed to save response for idempotency key, retries will be served again")
+		}
+	})
+}
+
+// replayIdempotent responds to a request retried with an idempotency key
+// which has been used before
+func replayIdempotent(w http.ResponseWriter, rec, existing *idempotencyRecord, logger log.FieldLogger) {
+	logger = logger.WithField("original-reqid", existing.ReqID)
+
+	switch {
+	case existing.Fingerprint != rec.Fingerprint:
+		logger.Warn("idempotency key reused for a different request")
+		restutils.SendHTTPErrorWithCode(w, http.StatusUnprocessableEntity, ErrCodeIdempotencyMismatch,
+			"idempotency key has been used for a different request")
+
+	case !existing.Done:
+		logger.Info("request with idempotency key is still in progress")
+		w.Header().Set("Retry-After", "1")
+		restutils.SendHTTPErrorWithCode(w, http.StatusConflict, ErrCodeIdempotencyInProgress,
+			"a request with the same idempotency key is in progress")
+
+	default:
+		logger.Info("replaying response for idempotency key")
+		if existing.ContentType != "" {
+			w.Header().Set("Content-Type", existing.ContentType)
+		}
+		w.Header().Set(IdempotentReplayedHeader, "true")
+		w.WriteHeader(existing.Status)
+		w.Write(existing.Body)
+	}
+}
@@ -0,0 +1,358 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gluster/glusterd2/store"
+	"github.com/gluster/glusterd2/tests"
+
+	"github.com/coreos/etcd/clientv3"
+	pb "github.com/coreos/etcd/etcdserver/etcdserverpb"
+	"github.com/coreos/etcd/mvcc/mvccpb"
+	heketitests "github.com/heketi/tests"
+	config "github.com/spf13/viper"
+)
+
+// memStore is an in-memory store implementing the operations on keys and
+// leases used for the idempotency records
+type memStore struct {
+	clientv3.KV
+	clientv3.Lease
+
+	sync.Mutex
+	rev    int64
+	kvs    map[string]*mvccpb.KeyValue
+	lastID clientv3.LeaseID
+	leases map[clientv3.LeaseID]int64
+	alive  map[clientv3.LeaseID]bool
+	// failPut makes puts outside of transactions fail
+	failPut bool
+}
+
+func patchMemStore() (*memStore, func()) {
+	m := &memStore{
+		kvs:    make(map[string]*mvccpb.KeyValue),
+		leases: make(map[clientv3.LeaseID]int64),
+		alive:  make(map[clientv3.LeaseID]bool),
+	}
+	p := heketitests.Patch(&store.Store, &store.GDStore{
+		Client: &clientv3.Client{Lease: m},
+		KV:     m,
+	})
+	return m, p.Restore
+}
+
+// opLease returns the lease of a put operation, which clientv3.Op doesn't
+// export
+func opLease(op clientv3.Op) clientv3.LeaseID {
+	return clientv3.LeaseID(reflect.ValueOf(op).FieldByName("leaseID").Int())
+}
+
+func (m *memStore) put(op clientv3.Op) {
+	m.rev++
+	key := string(op.KeyBytes())
+	kv, ok := m.kvs[key]
+	if !ok {
+		kv = &mvccpb.KeyValue{Key: []byte(key), CreateRevision: m.rev}
+		m.kvs[key] = kv
+	}
+	kv.Value = op.ValueBytes()
+	kv.ModRevision = m.rev
+	kv.Lease = int64(opLease(op))
+}
+
+// record returns the idempotency record of the key, if saved, along with its
+// lease
+func (m *memStore) record(key string) (*idempotencyRecord, clientv3.LeaseID) {
+	m.Lock()
+	defer m.Unlock()
+
+	kv, ok := m.kvs[idempotencyStoreKey(key)]
+	if !ok {
+		return nil, 0
+	}
+	var rec idempotencyRecord
+	if err := json.Unmarshal(kv.Value, &rec); err != nil {
+		return nil, 0
+	}
+	return &rec, clientv3.LeaseID(kv.Lease)
+}
+
+func (m *memStore) Put(ctx context.Context, key, val string, opts ...clientv3.OpOption) (*clientv3.PutResponse, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.failPut {
+		return nil, errors.New("put failed")
+	}
+	m.put(clientv3.OpPut(key, val, opts...))
+	return &clientv3.PutResponse{Header: &pb.ResponseHeader{Revision: m.rev}}, nil
+}
+
+func (m *memStore) Txn(ctx context.Context) clientv3.Txn {
+	return &memTxn{m: m}
+}
+
+func (m *memStore) Grant(ctx context.Context, ttl int64) (*clientv3.LeaseGrantResponse, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.lastID++
+	m.leases[m.lastID] = ttl
+	return &clientv3.LeaseGrantResponse{ID: m.lastID, TTL: ttl}, nil
+}
+
+func (m *memStore) Revoke(ctx context.Context, id clientv3.LeaseID) (*clientv3.LeaseRevokeResponse, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	delete(m.leases, id)
+	for k, kv := range m.kvs {
+		if clientv3.LeaseID(kv.Lease) == id {
+			delete(m.kvs, k)
+		}
+	}
+	return &clientv3.LeaseRevokeResponse{}, nil
+}
+
+func (m *memStore) KeepAlive(ctx context.Context, id clientv3.LeaseID) (<-chan *clientv3.LeaseKeepAliveResponse, error) {
+	m.Lock()
+	m.alive[id] = true
+	m.Unlock()
+
+	ch := make(chan *clientv3.LeaseKeepAliveResponse)
+	go func() {
+		<-ctx.Done()
+		m.Lock()
+		m.alive[id] = false
+		m.Unlock()
+		close(ch)
+	}()
+	return ch, nil
+}
+
+type memTxn struct {
+	m           *memStore
+	cmps        []clientv3.Cmp
+	thens, elss []clientv3.Op
+}
+
+func (t *memTxn) If(cs ...clientv3.Cmp) clientv3.Txn {
+	t.cmps = append(t.cmps, cs...)
+	return t
+}
+
+func (t *memTxn) Then(ops ...clientv3.Op) clientv3.Txn {
+	t.thens = append(t.thens, ops...)
+	return t
+}
+
+func (t *memTxn) Else(ops ...clientv3.Op) clientv3.Txn {
+	t.elss = append(t.elss, ops...)
+	return t
+}
+
+// Commit supports comparing the create revision of keys, and put and get
+// operations on single keys
+func (t *memTxn) Commit() (*clientv3.TxnResponse, error) {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	succeeded := true
+	for _, c := range t.cmps {
+		var rev int64
+		if kv, ok := t.m.kvs[string(c.Key)]; ok {
+			rev = kv.CreateRevision
+		}
+		want := c.TargetUnion.(*pb.Compare_CreateRevision).CreateRevision
+		succeeded = succeeded && rev == want
+	}
+
+	ops := t.thens
+	if !succeeded {
+		ops = t.elss
+	}
+	resp := &clientv3.TxnResponse{Header: &pb.ResponseHeader{}, Succeeded: succeeded}
+	for _, op := range ops {
+		switch {
+		case op.IsPut():
+			t.m.put(op)
+			resp.Responses = append(resp.Responses, &pb.ResponseOp{
+				Response: &pb.ResponseOp_ResponsePut{ResponsePut: &pb.PutResponse{}},
+			})
+		case op.IsGet():
+			rr := &pb.RangeResponse{}
+			if kv, ok := t.m.kvs[string(op.KeyBytes())]; ok {
+				c := *kv
+				rr.Kvs = append(rr.Kvs, &c)
+				rr.Count = 1
+			}
+			resp.Responses = append(resp.Responses, &pb.ResponseOp{
+				Response: &pb.ResponseOp_ResponseRange{ResponseRange: rr},
+			})
+		default:
+			panic("unsupported operation")
+		}
+	}
+	resp.Header.Revision = t.m.rev
+	return resp, nil
+}
+
+// serveIdempotent serves a request with the idempotency key and body
+func serveIdempotent(h http.Handler, key, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest("POST", "/v1/volumes", strings.NewReader(body))
+	r.Header.Set(IdempotencyKeyHeader, key)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestIdempotencyClaim(t *testing.T) {
+	m, restore := patchMemStore()
+	defer restore()
+	config.Set("idempotency-key-ttl", 3600)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	h := Idempotency(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"name":"vol1"}`))
+	}))
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- serveIdempotent(h, "key1", "req")
+	}()
+	<-started
+
+	// The request in progress is saved with a short lease, kept alive
+	// until the request is served
+	rec, lease := m.record("key1")
+	tests.Assert(t, rec != nil && !rec.Done)
+	tests.Assert(t, m.leases[lease] == idempotencyInProgressTTL)
+	m.Lock()
+	tests.Assert(t, m.alive[lease])
+	m.Unlock()
+
+	// Retries are rejected while the request is in progress
+	w := serveIdempotent(h, "key1", "req")
+	tests.Assert(t, w.Code == http.StatusConflict)
+	tests.Assert(t, strings.Contains(w.Body.String(), ErrCodeIdempotencyInProgress))
+
+	close(release)
+	w = <-done
+	tests.Assert(t, w.Code == http.StatusCreated)
+
+	// The response is saved with a lease of the TTL, and the short lease
+	// is revoked
+	rec, saved := m.record("key1")
+	tests.Assert(t, rec != nil && rec.Done)
+	tests.Assert(t, rec.Status == http.StatusCreated)
+	tests.Assert(t, m.leases[saved] == 3600)
+	_, ok := m.leases[lease]
+	tests.Assert(t, !ok)
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	m, restore := patchMemStore()
+	defer restore()
+	config.Set("idempotency-key-ttl", 3600)
+
+	calls := 0
+	status := http.StatusCreated
+	h := Idempotency(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(`{"name":"vol1"}`))
+	}))
+
+	first := serveIdempotent(h, "key1", "req")
+	tests.Assert(t, first.Header().Get(IdempotentReplayedHeader) == "")
+
+	// Retries get the response of the first request
+	w := serveIdempotent(h, "key1", "req")
+	tests.Assert(t, calls == 1)
+	tests.Assert(t, w.Code == http.StatusCreated)
+	tests.Assert(t, w.Body.String() == first.Body.String())
+	tests.Assert(t, w.Header().Get("Content-Type") == "application/json")
+	tests.Assert(t, w.Header().Get(IdempotentReplayedHeader) == "true")
+
+	// Requests without a key are always served
+	r := httptest.NewRequest("POST", "/v1/volumes", strings.NewReader("req"))
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	tests.Assert(t, calls == 2)
+
+	// Server errors are not saved, so that retries are served again
+	status = http.StatusInternalServerError
+	serveIdempotent(h, "key2", "req")
+	rec, _ := m.record("key2")
+	tests.Assert(t, rec == nil)
+	serveIdempotent(h, "key2", "req")
+	tests.Assert(t, calls == 4)
+
+	// Responses which can't be saved are served again as well
+	status = http.StatusOK
+	m.failPut = true
+	serveIdempotent(h, "key3", "req")
+	rec, _ = m.record("key3")
+	tests.Assert(t, rec == nil)
+	tests.Assert(t, len(m.leases) == 0)
+	m.failPut = false
+}
+
+func TestIdempotencyLargeResponse(t *testing.T) {
+	m, restore := patchMemStore()
+	defer restore()
+	config.Set("idempotency-key-ttl", 3600)
+
+	body := strings.Repeat("x", idempotencyMaxBody+1)
+	h := Idempotency(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body[:idempotencyMaxBody]))
+		w.Write([]byte(body[idempotencyMaxBody:]))
+	}))
+
+	w := serveIdempotent(h, "key1", "req")
+	tests.Assert(t, w.Body.String() == body)
+
+	// The status of large responses is saved without their body
+	rec, _ := m.record("key1")
+	tests.Assert(t, rec != nil && rec.Done)
+	tests.Assert(t, len(rec.Body) == 0 && rec.ContentType == "")
+
+	w = serveIdempotent(h, "key1", "req")
+	tests.Assert(t, w.Code == http.StatusOK)
+	tests.Assert(t, w.Body.Len() == 0)
+}
+
+func TestIdempotencyMismatch(t *testing.T) {
+	_, restore := patchMemStore()
+	defer restore()
+	config.Set("idempotency-key-ttl", 3600)
+
+	calls := 0
+	h := Idempotency(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	serveIdempotent(h, "key1", "req")
+
+	// Keys can't be reused for a different request
+	w := serveIdempotent(h, "key1", "other")
+	tests.Assert(t, w.Code == http.StatusUnprocessableEntity)
+	tests.Assert(t, strings.Contains(w.Body.String(), ErrCodeIdempotencyMismatch))
+	tests.Assert(t, calls == 1)
+}
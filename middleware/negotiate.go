@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+)
+
+// DefaultMediaType is the media type of the responses of routes which don't
+// declare the media types they produce
+const DefaultMediaType = "application/json"
+
+// accepts returns true if the given Accept header allows a response of any
+// of the given media types. An empty header accepts any media type.
+func accepts(accept string, mediaTypes []string) bool {
+	if accept == "" {
+		return true
+	}
+
+	for _, r := range strings.Split(accept, ",") {
+		mediaRange, _, err := mime.ParseMediaType(strings.TrimSpace(r))
+		if err != nil {
+			continue
+		}
+		if mediaRange == "*/*" {
+			return true
+		}
+		for _, t := range mediaTypes {
+			if mediaRange == t || (strings.HasSuffix(mediaRange, "/*") &&
+				strings.HasPrefix(t, strings.TrimSuffix(mediaRange, "*"))) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Negotiate returns a middleware which rejects requests that don't accept a
+// response of any of the given media types, which are the ones produced by
+// the route the middleware is applied to. DefaultMediaType is used if no
+// media type is given.
+func Negotiate(produces ...string) func(http.Handler) http.Handler {
+	if len(produces) == 0 {
+		produces = []string{DefaultMediaType}
+	}
+	msg := "only " + strings.Join(produces, ", ") + " responses are supported"
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !accepts(r.Header.Get("Accept"), produces) {
+				restutils.SendHTTPError(w, http.StatusNotAcceptable, msg)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
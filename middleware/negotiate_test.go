@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gluster/glusterd2/tests"
+)
+
+func TestAccepts(t *testing.T) {
+	json := []string{DefaultMediaType}
+	gzip := []string{"application/gzip"}
+
+	for _, accept := range []string{"", "*/*", "application/json", "application/*", "text/html, application/json;q=0.9"} {
+		tests.Assert(t, accepts(accept, json))
+	}
+	for _, accept := range []string{"text/html", "application/gzip", "text/*"} {
+		tests.Assert(t, !accepts(accept, json))
+	}
+
+	tests.Assert(t, accepts("application/gzip", gzip))
+	tests.Assert(t, accepts("application/*", gzip))
+	tests.Assert(t, !accepts("application/json", gzip))
+}
+
+func TestNegotiate(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	serve := func(h http.Handler, accept string) int {
+		r := httptest.NewRequest("POST", "/v1/debug/collect", nil)
+		r.Header.Set("Accept", accept)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	h := Negotiate()(ok)
+	tests.Assert(t, serve(h, "application/json") == http.StatusOK)
+	tests.Assert(t, serve(h, "application/gzip") == http.StatusNotAcceptable)
+
+	h = Negotiate("application/gzip")(ok)
+	tests.Assert(t, serve(h, "application/gzip") == http.StatusOK)
+	tests.Assert(t, serve(h, "text/plain") == http.StatusNotAcceptable)
+}
@@ -0,0 +1,200 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+
+	log "github.com/Sirupsen/logrus"
+	config "github.com/spf13/viper"
+)
+
+// idleClientTimeout is the time after which the state of a client without
+// requests in flight is dropped
+const idleClientTimeout = 10 * time.Minute
+
+// bucket is a token bucket refilled at rate tokens per second, holding at most
+// burst tokens
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// take takes a token from the bucket, and returns 0 if there was one, or the
+// time until the next token otherwise
+func (b *bucket) take(rate float64, burst int, now time.Time) time.Duration {
+	if b.last.IsZero() {
+		b.tokens = float64(burst)
+	} else {
+		b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.last).Seconds()*rate)
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / rate * float64(time.Second))
+}
+
+type client struct {
+	bucket   bucket
+	inflight int
+}
+
+// limit is a rate and in-flight limit. A zero rate or max disables the
+// respective limit.
+type limit struct {
+	rate  float64
+	burst int
+	max   int
+}
+
+func (l *limit) enabled() bool {
+	return l.rate > 0 || l.max > 0
+}
+
+// admit takes a token from the client and counts the request in flight, if
+// the limit allows it. Otherwise the time after which the request can be
+// retried is returned.
+func (l *limit) admit(c *client, now time.Time) (bool, time.Duration) {
+	if l.max > 0 && c.inflight >= l.max {
+		return false, time.Second
+	}
+	if l.rate > 0 {
+		if wait := c.bucket.take(l.rate, l.burst, now); wait > 0 {
+			return false, wait
+		}
+	}
+	c.inflight++
+	return true, 0
+}
+
+// limiter enforces a global limit on all requests, and a limit on the requests
+// of every client
+type limiter struct {
+	sync.Mutex
+	global    limit
+	perClient limit
+
+	all       client
+	clients   map[string]*client
+	lastSweep time.Time
+}
+
+// admit admits the request of the client if both the global and the client
+// limits allow it
+func (l *limiter) admit(key string, now time.Time) (bool, time.Duration) {
+	l.Lock()
+	defer l.Unlock()
+
+	l.sweep(now)
+
+	if ok, wait := l.global.admit(&l.all, now); !ok {
+		return false, wait
+	}
+
+	if l.perClient.enabled() {
+		c, ok := l.clients[key]
+		if !ok {
+			c = new(client)
+			l.clients[key] = c
+		}
+		if ok, wait := l.perClient.admit(c, now); !ok {
+			l.all.inflight--
+			return false, wait
+		}
+	}
+	return true, 0
+}
+
+// done marks a request of the client admitted earlier as finished
+func (l *limiter) done(key string) {
+	l.Lock()
+	defer l.Unlock()
+
+	l.all.inflight--
+	if c, ok := l.clients[key]; ok {
+		c.inflight--
+	}
+}
+
+// sweep drops the idle clients, so that clients which went away don't pile up
+func (l *limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < idleClientTimeout {
+		return
+	}
+	l.lastSweep = now
+
+	for key, c := range l.clients {
+		if c.inflight == 0 && now.Sub(c.bucket.last) > idleClientTimeout {
+			delete(l.clients, key)
+		}
+	}
+}
+
+// clientKey identifies the client sending the request by its token, if it
+// sends one, or by its address
+func clientKey(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		return "token:" + auth
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "addr:" + host
+}
+
+// RateLimit is a middleware which limits the rate of requests and the number
+// of requests in flight, for all requests and for every client, as configured
+// with the rest-rate-limit, rest-max-inflight, rest-client-rate-limit and
+// rest-client-max-inflight options. Requests over the limits are rejected
+// with 429 Too Many Requests, along with the time to retry after.
+func RateLimit(next http.Handler) http.Handler {
+	l := &limiter{
+		global: limit{
+			rate:  config.GetFloat64("rest-rate-limit"),
+			burst: config.GetInt("rest-rate-burst"),
+			max:   config.GetInt("rest-max-inflight"),
+		},
+		perClient: limit{
+			rate:  config.GetFloat64("rest-client-rate-limit"),
+			burst: config.GetInt("rest-client-rate-burst"),
+			max:   config.GetInt("rest-client-max-inflight"),
+		},
+		clients: make(map[string]*client),
+	}
+	for _, lim := range []*limit{&l.global, &l.perClient} {
+		if lim.burst < 1 {
+			lim.burst = 1
+		}
+	}
+
+	if !l.global.enabled() && !l.perClient.enabled() {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := clientKey(r)
+		ok, wait := l.admit(key, time.Now())
+		if !ok {
+			retry := int(math.Ceil(wait.Seconds()))
+			restLog.WithFields(log.Fields{
+				"reqid":       r.Header.Get("X-Request-ID"),
+				"remote":      r.RemoteAddr,
+				"retry-after": retry,
+			}).Warn("rejecting request over the rate limit")
+			w.Header().Set("Retry-After", strconv.Itoa(retry))
+			restutils.SendHTTPError(w, http.StatusTooManyRequests, "too many requests, retry later")
+			return
+		}
+		defer l.done(key)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gluster/glusterd2/tests"
+)
+
+func TestBucket(t *testing.T) {
+	var b bucket
+	now := time.Now()
+
+	// A new bucket is full
+	tests.Assert(t, b.take(1, 2, now) == 0)
+	tests.Assert(t, b.take(1, 2, now) == 0)
+	tests.Assert(t, b.take(1, 2, now) == time.Second)
+
+	// Half a token is refilled in half a second
+	now = now.Add(500 * time.Millisecond)
+	tests.Assert(t, b.take(1, 2, now) == 500*time.Millisecond)
+
+	// The bucket doesn't fill beyond the burst
+	now = now.Add(time.Minute)
+	tests.Assert(t, b.take(1, 2, now) == 0)
+	tests.Assert(t, b.take(1, 2, now) == 0)
+	tests.Assert(t, b.take(1, 2, now) != 0)
+}
+
+func TestLimiter(t *testing.T) {
+	l := &limiter{
+		global:    limit{max: 3},
+		perClient: limit{max: 2},
+		clients:   make(map[string]*client),
+	}
+	now := time.Now()
+
+	ok, _ := l.admit("a", now)
+	tests.Assert(t, ok)
+	ok, _ = l.admit("a", now)
+	tests.Assert(t, ok)
+
+	// Over the limit of the client, but not the global limit
+	ok, wait := l.admit("a", now)
+	tests.Assert(t, !ok && wait > 0)
+	ok, _ = l.admit("b", now)
+	tests.Assert(t, ok)
+
+	// Over the global limit
+	ok, _ = l.admit("c", now)
+	tests.Assert(t, !ok)
+
+	l.done("a")
+	ok, _ = l.admit("c", now)
+	tests.Assert(t, ok)
+}
@@ -2,13 +2,42 @@ package middleware
 
 import (
 	"net/http"
+	"time"
+
+	"github.com/gluster/glusterd2/logging"
 
 	log "github.com/Sirupsen/logrus"
-	"github.com/gorilla/handlers"
 )
 
-// LogRequest is a middleware which logs HTTP requests in the
-// Apache Common Log Format (CLF)
+var restLog = logging.Subsystem(logging.Rest)
+
+// statusWriter records the status code of the response
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// LogRequest is a middleware which logs HTTP requests along with their
+// request ID, so that the log line of a request can be correlated with the
+// other log lines logged while serving it. It must come after ReqIDGenerator.
 func LogRequest(next http.Handler) http.Handler {
-	return handlers.LoggingHandler(log.StandardLogger().Writer(), next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r)
+
+		restLog.WithFields(log.Fields{
+			"reqid":    r.Header.Get("X-Request-ID"),
+			"method":   r.Method,
+			"url":      r.URL.String(),
+			"remote":   r.RemoteAddr,
+			"status":   sw.status,
+			"duration": time.Since(start),
+		}).Info("HTTP request")
+	})
 }
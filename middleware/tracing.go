@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gluster/glusterd2/tracing"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+// Tracing is a middleware which starts a tracing span for each incoming HTTP
+// request, continuing the trace of the client if the request carries one. The
+// span is recorded against the request ID, so that the transactions run for
+// the request are traced under it. It must come after ReqIDGenerator.
+func Tracing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var opts []opentracing.StartSpanOption
+		sc, err := opentracing.GlobalTracer().Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(r.Header))
+		if err == nil {
+			opts = append(opts, ext.RPCServerOption(sc))
+		}
+
+		span := opentracing.StartSpan(r.Method+" "+r.URL.Path, opts...)
+		ext.HTTPMethod.Set(span, r.Method)
+		ext.HTTPUrl.Set(span, r.URL.String())
+
+		reqID := r.Header.Get("X-Request-ID")
+		span.SetTag("reqid", reqID)
+		tracing.SetRequestSpan(reqID, span)
+		defer func() {
+			tracing.ClearRequestSpan(reqID)
+			span.Finish()
+		}()
+
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r)
+
+		ext.HTTPStatusCode.Set(span, uint16(sw.status))
+		if sw.status >= http.StatusInternalServerError {
+			ext.Error.Set(span, true)
+		}
+	})
+}
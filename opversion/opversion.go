@@ -0,0 +1,107 @@
+// Package opversion manages the operating version (op-version) of the
+// cluster.
+//
+// Every peer advertises the maximum op-version it supports. The cluster
+// op-version is the op-version all peers in the cluster operate at, and
+// features which require all peers to understand them should be gated on
+// it using IsSupported. The cluster op-version is only ever bumped
+// explicitly, after all peers have been upgraded to support it.
+package opversion
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/peer"
+	"github.com/gluster/glusterd2/store"
+	"github.com/gluster/glusterd2/version"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+const (
+	clusterOpVersionKey = store.GlusterPrefix + "cluster/op-version"
+)
+
+// Get returns the current op-version of the cluster. If the op-version has
+// never been set, the highest op-version supported by all peers is used.
+func Get() (int, error) {
+	resp, err := store.Store.Get(context.TODO(), clusterOpVersionKey)
+	if err != nil {
+		return 0, err
+	}
+
+	if resp.Count == 0 {
+		return ClusterMax()
+	}
+
+	return strconv.Atoi(string(resp.Kvs[0].Value))
+}
+
+// ClusterMax returns the highest op-version supported by all the peers in
+// the cluster
+func ClusterMax() (int, error) {
+	peers, err := peer.GetPeersF()
+	if err != nil {
+		return 0, err
+	}
+
+	max := version.MaxOpVersion
+	for _, p := range peers {
+		v := p.MaxOpVersion
+		if v == 0 {
+			// Peers which don't advertise an op-version predate
+			// op-version support
+			v = version.MinOpVersion
+		}
+		if v < max {
+			max = v
+		}
+	}
+
+	return max, nil
+}
+
+// Set bumps the cluster op-version to the given op-version. The op-version
+// can only be increased, and only upto the op-version supported by all
+// peers.
+func Set(v int) error {
+	current, err := Get()
+	if err != nil {
+		return err
+	}
+	if v < current {
+		return errors.ErrOpVersionDowngrade
+	}
+
+	max, err := ClusterMax()
+	if err != nil {
+		return err
+	}
+	if v > max {
+		return errors.ErrOpVersionNotSupported
+	}
+
+	if _, err := store.Store.Put(context.TODO(), clusterOpVersionKey, strconv.Itoa(v)); err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"old": current,
+		"new": v,
+	}).Info("cluster op-version updated")
+
+	return nil
+}
+
+// IsSupported returns true if the cluster is operating at or above the given
+// op-version
+func IsSupported(v int) bool {
+	current, err := Get()
+	if err != nil {
+		log.WithError(err).Error("failed to get cluster op-version")
+		return false
+	}
+	return current >= v
+}
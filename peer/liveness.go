@@ -0,0 +1,57 @@
+package peer
+
+import (
+	"context"
+
+	"github.com/gluster/glusterd2/events"
+	"github.com/gluster/glusterd2/store"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/pborman/uuid"
+)
+
+// Names of the events emitted when the liveness of a peer changes
+const (
+	EventPeerConnected    = "peer.connected"
+	EventPeerDisconnected = "peer.disconnected"
+)
+
+// LivenessWatcher watches the liveness of peers in the store and emits
+// events when peers come online or go offline.
+// It provides an implementation of the github.com/thejerf/suture.Service
+// interface.
+type LivenessWatcher struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewLivenessWatcher returns a new LivenessWatcher
+func NewLivenessWatcher() *LivenessWatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &LivenessWatcher{ctx, cancel}
+}
+
+// Serve begins watching peer liveness
+func (w *LivenessWatcher) Serve() {
+	log.Info("started peer liveness watcher")
+	store.Store.WatchLiveness(w.ctx, onLivenessChange)
+}
+
+// Stop stops watching peer liveness
+func (w *LivenessWatcher) Stop() {
+	w.cancel()
+	log.Info("stopped peer liveness watcher")
+}
+
+func onLivenessChange(id uuid.UUID, alive bool) {
+	data := map[string]string{"peer.id": id.String()}
+	if p, err := GetPeerF(id.String()); err == nil {
+		data["peer.name"] = p.Name
+	}
+
+	if alive {
+		events.Broadcast(events.New(EventPeerConnected, data))
+	} else {
+		events.Broadcast(events.New(EventPeerDisconnected, data))
+	}
+}
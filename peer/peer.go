@@ -10,6 +10,29 @@ type Peer struct {
 	ID        uuid.UUID `json:"id"`
 	Name      string    `json:"name"`
 	Addresses []string  `json:"addresses"`
+	// MetaData holds arbitrary key/value tags attached to the peer like
+	// zone, rack or role. These can be used to filter peers and to
+	// make placement decisions.
+	MetaData map[string]string `json:"metadata,omitempty"`
+	// MaxOpVersion is the highest op-version supported by the peer
+	MaxOpVersion int `json:"max-op-version"`
+	// Maintenance is set when the peer has been placed into maintenance
+	// mode. Bricks are not started on peers in maintenance.
+	Maintenance bool `json:"maintenance"`
+	// Online is set when the peer is returned to clients and indicates if
+	// the peer is currently sending heartbeats to the store.
+	Online bool `json:"online"`
+}
+
+// MatchesMetaData returns true if all the given key/value pairs are present
+// in the peer metadata
+func (p *Peer) MatchesMetaData(filter map[string]string) bool {
+	for k, v := range filter {
+		if p.MetaData[k] != v {
+			return false
+		}
+	}
+	return true
 }
 
 // ETCDConfig represents the structure which holds the ETCD env variables &
@@ -1,18 +1,43 @@
 package peer
 
 import (
+	"github.com/gluster/glusterd2/errors"
 	"github.com/gluster/glusterd2/gdctx"
+	"github.com/gluster/glusterd2/utils"
+	"github.com/gluster/glusterd2/version"
 
 	config "github.com/spf13/viper"
 )
 
 // AddSelfDetails results in the peer adding its own details into etcd
 func AddSelfDetails() error {
-	p := &Peer{
-		ID:        gdctx.MyUUID,
-		Name:      gdctx.HostName,
-		Addresses: []string{config.GetString("peeraddress")},
+	addr := config.GetString("peeraddress")
+
+	// Retain metadata and maintenance state of this peer across restarts.
+	// The name of the peer is retained as well, as it is only changed by
+	// renaming the peer, which also sets its primary address.
+	_, err := UpdatePeer(gdctx.MyUUID.String(), func(p *Peer) error {
+		p.MaxOpVersion = version.MaxOpVersion
+		if p.Name == "" || len(p.Addresses) == 0 {
+			p.Name = gdctx.HostName
+			p.Addresses = []string{addr}
+			return nil
+		}
+		if p.Name == gdctx.HostName || utils.IsPeerAddressSame(p.Addresses[0], addr) {
+			p.Addresses = []string{addr}
+		} else {
+			p.Addresses = []string{p.Addresses[0], addr}
+		}
+		return nil
+	})
+	if err != errors.ErrPeerNotFound {
+		return err
 	}
 
-	return AddOrUpdatePeer(p)
+	return AddOrUpdatePeer(&Peer{
+		ID:           gdctx.MyUUID,
+		Name:         gdctx.HostName,
+		Addresses:    []string{addr},
+		MaxOpVersion: version.MaxOpVersion,
+	})
 }
@@ -5,10 +5,10 @@ package peer
 
 import (
 	"context"
-	"encoding/json"
 
 	"github.com/gluster/glusterd2/errors"
 	"github.com/gluster/glusterd2/store"
+	"github.com/gluster/glusterd2/store/schema"
 	"github.com/gluster/glusterd2/utils"
 
 	log "github.com/Sirupsen/logrus"
@@ -18,6 +18,13 @@ import (
 
 const (
 	peerPrefix string = store.GlusterPrefix + "peers/"
+
+	// maxUpdateAttempts is the number of times UpdatePeer re-reads and
+	// updates a peer which was changed concurrently before giving up
+	maxUpdateAttempts = 5
+
+	// SchemaKind is the kind of peerinfo objects for schema versioning
+	SchemaKind = "peerinfo"
 )
 
 var (
@@ -31,24 +38,78 @@ var (
 	GetPeerByNameF = GetPeerByName
 	//GetPeerIDByAddrF returns the ID of the peer with the given address
 	GetPeerIDByAddrF = GetPeerIDByAddr
+
+	// cache keeps the peerinfo objects in memory for the read-only REST
+	// endpoints
+	cache = store.NewCache("peers", peerPrefix)
 )
 
 // AddOrUpdatePeer adds/updates given peer in the store
 func AddOrUpdatePeer(p *Peer) error {
-	json, err := json.Marshal(p)
+	data, err := schema.Marshal(SchemaKind, p)
 	if err != nil {
 		return err
 	}
 
 	idStr := p.ID.String()
 
-	if _, err := store.Store.Put(context.TODO(), peerPrefix+idStr, string(json)); err != nil {
+	resp, err := store.Store.Put(context.TODO(), peerPrefix+idStr, string(data))
+	if err != nil {
 		return err
 	}
+	cache.Put(peerPrefix+idStr, data, resp.Header.Revision)
 
 	return nil
 }
 
+// UpdatePeer applies update to the peer with the given ID and saves it. The
+// peer is only saved if it hasn't been changed since it was read, otherwise it
+// is read again and update is retried, so that concurrent updates of the peer
+// by different nodes are not lost. The updated peer is returned.
+func UpdatePeer(id string, update func(*Peer) error) (*Peer, error) {
+	key := peerPrefix + id
+
+	for i := 0; i < maxUpdateAttempts; i++ {
+		resp, err := store.Store.Get(context.TODO(), key)
+		if err != nil {
+			return nil, err
+		}
+		if resp.Count != 1 {
+			return nil, errors.ErrPeerNotFound
+		}
+
+		var p Peer
+		if err := schema.Unmarshal(SchemaKind, resp.Kvs[0].Value, &p); err != nil {
+			return nil, err
+		}
+		if err := update(&p); err != nil {
+			return nil, err
+		}
+		// Online is only set on peers returned to clients
+		p.Online = false
+
+		data, err := schema.Marshal(SchemaKind, &p)
+		if err != nil {
+			return nil, err
+		}
+
+		tresp, err := store.Store.Txn(context.TODO()).If(
+			clientv3.Compare(clientv3.ModRevision(key), "=", resp.Kvs[0].ModRevision),
+		).Then(
+			clientv3.OpPut(key, string(data)),
+		).Commit()
+		if err != nil {
+			return nil, err
+		}
+		if tresp.Succeeded {
+			cache.Put(key, data, tresp.Header.Revision)
+			return &p, nil
+		}
+	}
+
+	return nil, errors.ErrPeerConflict
+}
+
 // GetPeer returns specified peer from the store
 func GetPeer(id string) (*Peer, error) {
 	resp, err := store.Store.Get(context.TODO(), peerPrefix+id)
@@ -63,7 +124,7 @@ func GetPeer(id string) (*Peer, error) {
 	}
 
 	var p Peer
-	if err := json.Unmarshal(resp.Kvs[0].Value, &p); err != nil {
+	if err := schema.Unmarshal(SchemaKind, resp.Kvs[0].Value, &p); err != nil {
 		return nil, err
 	}
 	return &p, nil
@@ -98,7 +159,7 @@ func GetPeers() ([]Peer, error) {
 	for i, kv := range resp.Kvs {
 		var p Peer
 
-		if err := json.Unmarshal(kv.Value, &p); err != nil {
+		if err := schema.Unmarshal(SchemaKind, kv.Value, &p); err != nil {
 			log.WithFields(log.Fields{
 				"peer":  string(kv.Key),
 				"error": err,
@@ -121,7 +182,7 @@ func GetPeerIDs() ([]uuid.UUID, error) {
 	uuids := make([]uuid.UUID, len(resp.Kvs))
 	for i, kv := range resp.Kvs {
 		var p Peer
-		if err := json.Unmarshal(kv.Value, &p); err != nil {
+		if err := schema.Unmarshal(SchemaKind, kv.Value, &p); err != nil {
 			log.WithFields(log.Fields{
 				"peer":  string(kv.Key),
 				"error": err,
@@ -152,8 +213,12 @@ func GetPeerByName(name string) (*Peer, error) {
 
 // DeletePeer deletes given peer from the store
 func DeletePeer(id string) error {
-	_, e := store.Store.Delete(context.TODO(), peerPrefix+id)
-	return e
+	resp, e := store.Store.Delete(context.TODO(), peerPrefix+id)
+	if e != nil {
+		return e
+	}
+	cache.Delete(peerPrefix+id, resp.Header.Revision)
+	return nil
 }
 
 // Exists checks if given peer is present in the store
@@ -210,3 +275,52 @@ func GetPeerIDByAddr(addr string) (uuid.UUID, error) {
 	}
 	return p.ID, nil
 }
+
+// InMaintenance returns true if the given peer is in maintenance mode
+func InMaintenance(id string) bool {
+	p, err := GetPeerF(id)
+	if err != nil {
+		return false
+	}
+	return p.Maintenance
+}
+
+// GetPeerCached returns the specified peer from the peer cache. It may
+// briefly lag behind changes made on other peers, and must only be used where
+// stale data is acceptable.
+func GetPeerCached(id string) (*Peer, error) {
+	data, ok, err := cache.Get(peerPrefix + id)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.ErrPeerNotFound
+	}
+
+	var p Peer
+	if err := schema.Unmarshal(SchemaKind, data, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// GetPeersCached returns all peers from the peer cache. Like GetPeerCached,
+// it must only be used where stale data is acceptable.
+func GetPeersCached() ([]Peer, error) {
+	values, err := cache.List()
+	if err != nil {
+		return nil, err
+	}
+
+	peers := make([]Peer, 0, len(values))
+	for _, data := range values {
+		var p Peer
+		if err := schema.Unmarshal(SchemaKind, data, &p); err != nil {
+			log.WithError(err).Error("Failed to unmarshal peer")
+			continue
+		}
+		peers = append(peers, p)
+	}
+
+	return peers, nil
+}
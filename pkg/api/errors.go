@@ -2,5 +2,87 @@ package api
 
 // HTTPError represents HTTP error returned by glusterd2
 type HTTPError struct {
-	Error string `json:"Error"`
+	Error  string       `json:"Error"`
+	Code   string       `json:"code"`
+	Errors []FieldError `json:"errors,omitempty"`
 }
+
+// FieldError describes why a single field of a request is invalid
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Codes of the errors returned by GlusterD. The codes are stable, unlike the
+// error messages, and can be relied on to tell failures apart. Errors without
+// a more specific code have a code derived from their HTTP status, eg.
+// "not-found".
+const (
+	ErrCodeValidationFailed        = "validation-failed"
+	ErrCodeVolCreateFail           = "volume-create-failed"
+	ErrCodeVolNotFound             = "volume-not-found"
+	ErrCodePeerNotFound            = "peer-not-found"
+	ErrCodeJSONParsingFailed       = "invalid-json"
+	ErrCodeEmptyVolName            = "volume-name-empty"
+	ErrCodeEmptyBrickList          = "brick-list-empty"
+	ErrCodeInvalidBrickPath        = "brick-path-invalid"
+	ErrCodeVolExists               = "volume-exists"
+	ErrCodeVolNameConflict         = "volume-name-conflict"
+	ErrCodeVolAlreadyStarted       = "volume-already-started"
+	ErrCodeVolAlreadyStopped       = "volume-already-stopped"
+	ErrCodeVolNotStarted           = "volume-not-started"
+	ErrCodeWrongGraphType          = "wrong-graph-type"
+	ErrCodeDeviceIDNotFound        = "device-id-not-found"
+	ErrCodeBrickIsMountPoint       = "brick-is-mount-point"
+	ErrCodeBrickUnderRootPartition = "brick-under-root-partition"
+	ErrCodeBrickNotDirectory       = "brick-not-directory"
+	ErrCodeBrickPathAlreadyInUse   = "brick-path-in-use"
+	ErrCodeNoHostnamesPresent      = "no-hostnames"
+	ErrCodeBrickPathConvertFail    = "brick-path-not-absolute"
+	ErrCodeBrickNotLocal           = "brick-not-local"
+	ErrCodeBrickPathTooLong        = "brick-path-too-long"
+	ErrCodeSubDirPathTooLong       = "subdir-path-too-long"
+	ErrCodeNameTooLong             = "brick-name-too-long"
+	ErrCodeIPAddressNotFound       = "ip-address-not-found"
+	ErrCodePeerLocalNode           = "peer-is-local-node"
+	ErrCodeProcessNotFound         = "process-not-found"
+	ErrCodeProcessAlreadyRunning   = "process-already-running"
+	ErrCodeInvalidPeerMetaDataKey  = "peer-metadata-key-invalid"
+	ErrCodeQuorumNotMet            = "quorum-not-met"
+	ErrCodeInvalidQuorumRatio      = "quorum-ratio-invalid"
+	ErrCodeOpVersionDowngrade      = "op-version-downgrade"
+	ErrCodeOpVersionNotSupported   = "op-version-not-supported"
+	ErrCodeOptionOpVersion         = "option-op-version"
+	ErrCodePendingHeals            = "pending-heals"
+	ErrCodePeerInMaintenance       = "peer-in-maintenance"
+	ErrCodeBitrotNotEnabled        = "bitrot-not-enabled"
+	ErrCodeBrickFSNotSupported     = "brick-fs-not-supported"
+	ErrCodeBrickPathOverlaps       = "brick-path-overlaps"
+	ErrCodeSELinuxNotEnabled       = "selinux-not-enabled"
+	ErrCodeProfileNotFound         = "profile-not-found"
+	ErrCodeProfileBuiltin          = "profile-builtin"
+	ErrCodeVolNotReplicate         = "volume-not-replicate"
+	ErrCodeInvalidHealPolicy       = "heal-policy-invalid"
+	ErrCodeBlockVolNotFound        = "block-volume-not-found"
+	ErrCodeBlockVolExists          = "block-volume-exists"
+	ErrCodeBlockVolShrink          = "block-volume-shrink"
+	ErrCodeNoCapacity              = "no-capacity"
+	ErrCodeVolNotProvisioned       = "volume-not-provisioned"
+	ErrCodeSnapNotFound            = "snapshot-not-found"
+	ErrCodeSnapExists              = "snapshot-exists"
+	ErrCodeBrickNotThinLV          = "brick-not-thin-lv"
+	ErrCodeVolHasSnapshots         = "volume-has-snapshots"
+	ErrCodeSnapScheduleNotFound    = "snapshot-schedule-not-found"
+	ErrCodeBackupVersion           = "backup-version-unsupported"
+	ErrCodeBackupConflict          = "backup-conflict"
+	ErrCodeInvalidDiskUsageConfig  = "disk-usage-config-invalid"
+	ErrCodeClusterOptNotFound      = "cluster-option-not-found"
+	ErrCodeClusterOptConflict      = "cluster-option-conflict"
+	ErrCodeVolDependencyCycle      = "volume-dependency-cycle"
+	ErrCodeInvalidStopTimeout      = "stop-timeout-invalid"
+	ErrCodePeerNameConflict        = "peer-name-conflict"
+	ErrCodeBrickNotFound           = "brick-not-found"
+	ErrCodePeerConflict            = "peer-conflict"
+	ErrCodeLockTimeout             = "lock-timeout"
+	ErrCodeTxnTimeout              = "transaction-timeout"
+)
@@ -7,9 +7,197 @@ type VolCreateReq struct {
 	Replica   int      `json:"replica,omitempty"`
 	Bricks    []string `json:"bricks"`
 	Force     bool     `json:"force,omitempty"`
+	// PeerMetaData restricts the bricks to the peers having all the given
+	// metadata, and SpreadBy names a metadata key, like zone or rack, whose
+	// value must differ between the peers of the bricks of a replica set.
+	PeerMetaData map[string]string `json:"peer-metadata,omitempty"`
+	SpreadBy     string            `json:"spread-by,omitempty"`
 }
 
 // PeerAddReq represents a Peer Add Request
 type PeerAddReq struct {
 	Addresses []string `json:"addresses"`
 }
+
+// PeerEditReq represents a request to edit the metadata of a peer.
+// A key with an empty value removes that key from the peer metadata.
+type PeerEditReq struct {
+	MetaData map[string]string `json:"metadata"`
+}
+
+// BrickCleanupReq represents a request to cleanup stale brick paths
+type BrickCleanupReq struct {
+	Bricks []string `json:"bricks"`
+}
+
+// BrickRelabelReq represents a request to set the SELinux context of bricks
+type BrickRelabelReq struct {
+	Bricks []string `json:"bricks"`
+}
+
+// VolBarrierReq represents a request to enable or disable the barrier on a
+// volume
+type VolBarrierReq struct {
+	Enable bool `json:"enable"`
+}
+
+// OpVersionReq represents a request to bump the cluster op-version
+type OpVersionReq struct {
+	OpVersion int `json:"op-version"`
+}
+
+// PeerMaintenanceReq represents a request to place a peer into, or take it
+// out of, maintenance mode
+type PeerMaintenanceReq struct {
+	Enable bool `json:"enable"`
+	Force  bool `json:"force,omitempty"`
+}
+
+// PeerRenameReq represents a request to change the hostname of a peer
+type PeerRenameReq struct {
+	Hostname string `json:"hostname"`
+}
+
+// VolOptionReq represents a request to set volume options
+type VolOptionReq struct {
+	Options map[string]string `json:"options"`
+}
+
+// ClusterOptionReq represents a request to set options of the cluster
+type ClusterOptionReq struct {
+	Options map[string]string `json:"options"`
+}
+
+// VolBatchReq represents a request to start or stop several volumes
+type VolBatchReq struct {
+	Volumes []string `json:"volumes"`
+}
+
+// VolExpandReq represents a request to expand the volume by adding more bricks
+type VolExpandReq struct {
+	ReplicaCount int      `json:"replica,omitempty"`
+	Bricks       []string `json:"bricks"`
+	Force        bool     `json:"force,omitempty"`
+}
+
+// VolImportReq represents a request to import volumes from GlusterD1
+type VolImportReq struct {
+	Dir     string   `json:"dir,omitempty"`
+	Volumes []string `json:"volumes,omitempty"`
+}
+
+// VolListOpts are the filtering and pagination options for listing volumes.
+// Zero values are not used.
+type VolListOpts struct {
+	// State is one of created, started or stopped
+	State string
+	// Type is one of distribute, replicate, disperse, distribute-replicate
+	// or distribute-disperse
+	Type string
+	// Options are the options the volumes must have set. An empty value
+	// matches any value.
+	Options map[string]string
+	Limit   int
+	Offset  int
+}
+
+// ProfileReq represents a request to create or replace a custom option
+// profile
+type ProfileReq struct {
+	Description string            `json:"description,omitempty"`
+	Options     map[string]string `json:"options"`
+}
+
+// VolProfileReq represents a request to apply an option profile to a volume
+type VolProfileReq struct {
+	Profile string `json:"profile"`
+}
+
+// VolAccessReq represents a request to replace the access control list of a
+// volume. Addresses can be IP addresses, CIDR ranges or hostnames with *
+// wildcards. Users are the common names of the TLS certificates of clients.
+type VolAccessReq struct {
+	AllowAddrs  []string `json:"allow-addrs"`
+	RejectAddrs []string `json:"reject-addrs"`
+	AllowUsers  []string `json:"allow-users"`
+}
+
+// SplitBrainReq represents a request to resolve a split-brain on a replicate
+// volume. Policy is one of bigger-file, latest-mtime and source-brick. One of
+// File and Gfid is needed, except with source-brick, which resolves all the
+// files in split-brain if neither is given.
+type SplitBrainReq struct {
+	Policy      string `json:"policy"`
+	File        string `json:"file,omitempty"`
+	Gfid        string `json:"gfid,omitempty"`
+	SourceBrick string `json:"source-brick,omitempty"`
+}
+
+// ProvisionReq represents a request to provision a volume of the given size,
+// with its bricks placed by GlusterD. PeerMetaData restricts the bricks to
+// peers with the given metadata, and the bricks are placed on peers with
+// different values of the SpreadBy metadata key.
+type ProvisionReq struct {
+	Name         string            `json:"name"`
+	Size         uint64            `json:"size"`
+	Replica      int               `json:"replica,omitempty"`
+	Options      map[string]string `json:"options,omitempty"`
+	PeerMetaData map[string]string `json:"peer-metadata,omitempty"`
+	SpreadBy     string            `json:"spread-by,omitempty"`
+}
+
+// ProvisionExpandReq represents a request to grow a provisioned volume
+type ProvisionExpandReq struct {
+	Size uint64 `json:"size"`
+}
+
+// ProvisionSnapReq represents a request to take a snapshot of a provisioned
+// volume
+type ProvisionSnapReq struct {
+	Name string `json:"name"`
+}
+
+// SnapCreateReq represents a request to take a snapshot of a volume
+type SnapCreateReq struct {
+	Volume      string `json:"volume"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// SnapCloneReq represents a request to create a volume from a snapshot
+type SnapCloneReq struct {
+	Name string `json:"name"`
+}
+
+// SnapScheduleReq represents a request to set the snapshot schedule of a
+// volume. Schedule is a cron expression, and Retain is the number of
+// scheduled snapshots kept.
+type SnapScheduleReq struct {
+	Schedule string `json:"schedule"`
+	Retain   int    `json:"retain"`
+}
+
+// DebugCollectReq represents a request to collect debug information from the
+// peers into an archive. All the peers are collected from if Peers is empty,
+// and all the kinds of information if Include is empty.
+type DebugCollectReq struct {
+	Peers          []string `json:"peers,omitempty"`
+	Include        []string `json:"include,omitempty"`
+	TakeStatedumps bool     `json:"take-statedumps,omitempty"`
+	MaxFileSize    int64    `json:"max-file-size,omitempty"`
+	MaxPeerSize    int64    `json:"max-peer-size,omitempty"`
+}
+
+// ApplySpec is the declarative spec of the volumes of the cluster
+type ApplySpec struct {
+	Volumes []VolumeSpec `json:"volumes"`
+}
+
+// VolumeSpec is the desired state of a volume. Volumes which don't exist are
+// provisioned with the given size, in bytes, and replica count.
+type VolumeSpec struct {
+	Name    string            `json:"name"`
+	Size    uint64            `json:"size,omitempty"`
+	Replica int               `json:"replica,omitempty"`
+	Options map[string]string `json:"options,omitempty"`
+}
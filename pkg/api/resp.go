@@ -1,14 +1,25 @@
 package api
 
 import (
+	"time"
+
 	"github.com/pborman/uuid"
 )
 
+// HookFailuresHeader is the response header listing the hooks which failed,
+// for responses whose body can't carry them, eg. the options of a volume set
+// request. Its value is the JSON encoded list of failed hooks.
+const HookFailuresHeader = "X-Gluster-Hook-Failures"
+
 // Peer reperesents a GlusterD
 type Peer struct {
-	ID        uuid.UUID `json:"id"`
-	Name      string    `json:"name"`
-	Addresses []string  `json:"addresses"`
+	ID           uuid.UUID         `json:"id"`
+	Name         string            `json:"name"`
+	Addresses    []string          `json:"addresses"`
+	MetaData     map[string]string `json:"metadata,omitempty"`
+	MaxOpVersion int               `json:"max-op-version"`
+	Maintenance  bool              `json:"maintenance"`
+	Online       bool              `json:"online"`
 }
 
 // VolState is the current status of a volume
@@ -34,19 +45,394 @@ type Brickinfo struct {
 
 // Volinfo repesents a volume
 type Volinfo struct {
-	ID           uuid.UUID
-	Name         string
-	Type         VolType
-	Transport    string
-	DistCount    int
-	ReplicaCount int
-	Options      map[string]string
-	Status       VolState
-	Checksum     uint64
-	Version      uint64
-	Bricks       []Brickinfo
-	Auth         VolAuth // TODO: should not be returned to client
+	ID              uuid.UUID
+	Name            string
+	Type            VolType
+	Transport       string
+	DistCount       int
+	ReplicaCount    int
+	DisperseCount   int
+	RedundancyCount int
+	Options         map[string]string
+	Status          VolState
+	Checksum        uint64
+	Version         uint64
+	Bricks          []Brickinfo
+	Auth            VolAuth // TODO: should not be returned to client
+	Capacity        uint64
+}
+
+// VolSummary is the summary of a volume returned when listing volumes
+type VolSummary struct {
+	ID         uuid.UUID `json:"id"`
+	Name       string    `json:"name"`
+	Type       VolType   `json:"type"`
+	Status     VolState  `json:"status"`
+	BrickCount int       `json:"brick-count"`
 }
 
 // VolList respresents volumes list
 type VolList map[string]string
+
+// VolListPage respresents a page of the volumes list
+type VolListPage struct {
+	Total   int          `json:"total"`
+	Volumes []VolSummary `json:"volumes"`
+}
+
+// VolDetailListPage represents a page of the volumes list with the full
+// information of each volume
+type VolDetailListPage struct {
+	Total   int       `json:"total"`
+	Volumes []Volinfo `json:"volumes"`
+}
+
+// BrickCheckResult represents the result of validating a single brick
+type BrickCheckResult struct {
+	Brick    string    `json:"brick"`
+	NodeID   uuid.UUID `json:"node-id"`
+	Valid    bool      `json:"valid"`
+	Errors   []string  `json:"errors,omitempty"`
+	Warnings []string  `json:"warnings,omitempty"`
+}
+
+// VolDryRunResp represents the report returned for a dry-run of volume
+// create or expand
+type VolDryRunResp struct {
+	Valid    bool               `json:"valid"`
+	Bricks   []BrickCheckResult `json:"bricks"`
+	Warnings []string           `json:"warnings,omitempty"`
+}
+
+// ClientInfo represents a client connected to a brick
+type ClientInfo struct {
+	Hostname     string `json:"hostname"`
+	Name         string `json:"name,omitempty"`
+	BytesRead    uint64 `json:"bytes-read"`
+	BytesWritten uint64 `json:"bytes-written"`
+	OpVersion    int    `json:"op-version"`
+}
+
+// BrickClients represents the list of clients connected to a brick
+type BrickClients struct {
+	Brick   string       `json:"brick"`
+	NodeID  uuid.UUID    `json:"node-id"`
+	Online  bool         `json:"online"`
+	Clients []ClientInfo `json:"clients"`
+}
+
+// OpVersion represents the op-version of the cluster
+type OpVersion struct {
+	OpVersion    int `json:"op-version"`
+	MaxOpVersion int `json:"max-op-version"`
+}
+
+// Brickstatus represents the runtime status of a brick
+type Brickstatus struct {
+	BInfo          Brickinfo
+	Online         bool
+	Pid            int
+	Port           int
+	HealthError    string
+	DiskUsage      int
+	DiskUsageLevel string
+}
+
+// ServiceStatus represents the status of a service, other than the bricks,
+// which serves a volume
+type ServiceStatus struct {
+	Name   string    `json:"name"`
+	NodeID uuid.UUID `json:"node-id"`
+	Online bool      `json:"online"`
+	Pid    int       `json:"pid,omitempty"`
+}
+
+// VolStatus represents collective status of the bricks that make up the volume
+type VolStatus struct {
+	Brickstatuses []Brickstatus
+	Services      []ServiceStatus `json:",omitempty"`
+	DiskReserve   int
+}
+
+// SizeInfo represents the capacity of a brick or a volume, in bytes
+type SizeInfo struct {
+	Total uint64 `json:"total"`
+	Used  uint64 `json:"used"`
+	Free  uint64 `json:"free"`
+}
+
+// BrickSize represents the capacity of the filesystem of a brick
+type BrickSize struct {
+	Brick  string    `json:"brick"`
+	NodeID uuid.UUID `json:"node-id"`
+	SizeInfo
+}
+
+// VolSizeResp represents the logical capacity of a volume, and the capacity
+// of each of its bricks
+type VolSizeResp struct {
+	SizeInfo
+	Bricks []BrickSize `json:"bricks"`
+}
+
+// SubvolUsage represents the usage of a replica set of a volume, or of a
+// single brick of a distribute volume
+type SubvolUsage struct {
+	Bricks      []string `json:"bricks"`
+	Total       uint64   `json:"total"`
+	Used        uint64   `json:"used"`
+	UsedPercent int      `json:"used-percent"`
+}
+
+// VolBalanceResp represents how evenly the data of a volume is spread over
+// its subvolumes, and whether rebalancing the volume is advised
+type VolBalanceResp struct {
+	Subvols          []SubvolUsage `json:"subvols"`
+	Skew             int           `json:"skew"`
+	Threshold        int           `json:"threshold"`
+	RebalanceAdvised bool          `json:"rebalance-advised"`
+	Hints            []string      `json:"hints,omitempty"`
+	Missing          []string      `json:"missing,omitempty"`
+}
+
+// PeerVolCheck represents the consistency of the configuration of a volume
+// on a peer. Offline peers are not checked.
+type PeerVolCheck struct {
+	PeerID   uuid.UUID `json:"peer-id"`
+	Online   bool      `json:"online"`
+	Checksum string    `json:"checksum,omitempty"`
+	Problems []string  `json:"problems,omitempty"`
+}
+
+// VolCheckResp represents whether all the peers see the same configuration of
+// a volume as the one in the store
+type VolCheckResp struct {
+	Volume     string         `json:"volume"`
+	Checksum   string         `json:"checksum"`
+	Consistent bool           `json:"consistent"`
+	Peers      []PeerVolCheck `json:"peers"`
+}
+
+// VolImportSkipped represents a volume which was not imported from GlusterD1
+type VolImportSkipped struct {
+	Volume string `json:"volume"`
+	Reason string `json:"reason"`
+}
+
+// PeerImportResult represents a GlusterD1 peer and the GlusterD2 peer it was
+// found as
+type PeerImportResult struct {
+	UUID      string    `json:"uuid"`
+	Hostnames []string  `json:"hostnames"`
+	PeerID    uuid.UUID `json:"peer-id,omitempty"`
+	Found     bool      `json:"found"`
+}
+
+// VolImportResp is the response of a volume import from GlusterD1
+type VolImportResp struct {
+	Imported []string           `json:"imported"`
+	Skipped  []VolImportSkipped `json:"skipped,omitempty"`
+	Peers    []PeerImportResult `json:"peers"`
+}
+
+// OptionProfile represents a named group of volume options which are set
+// together
+type OptionProfile struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Options     map[string]string `json:"options"`
+	Builtin     bool              `json:"builtin"`
+}
+
+// OptionInfo represents a volume option which can be set, as returned in the
+// option table
+type OptionInfo struct {
+	Name          string   `json:"name"`
+	Aliases       []string `json:"aliases,omitempty"`
+	Type          string   `json:"type"`
+	DefaultValue  string   `json:"default-value,omitempty"`
+	Description   string   `json:"description,omitempty"`
+	AllowedValues []string `json:"allowed-values,omitempty"`
+	Min           *float64 `json:"min,omitempty"`
+	Max           *float64 `json:"max,omitempty"`
+	OpVersion     int      `json:"op-version"`
+}
+
+// VolAccess represents the access control list of a volume
+type VolAccess struct {
+	AllowAddrs  []string `json:"allow-addrs"`
+	RejectAddrs []string `json:"reject-addrs"`
+	AllowUsers  []string `json:"allow-users"`
+}
+
+// SplitBrainResp is the response sent for a split-brain resolution request
+type SplitBrainResp struct {
+	Output string `json:"output"`
+}
+
+// BrickRoot represents the directory bricks are provisioned under on a node,
+// and the capacity available to new bricks in the thin pool of the node
+type BrickRoot struct {
+	NodeID uuid.UUID `json:"node-id"`
+	Path   string    `json:"path"`
+	Pool   string    `json:"pool"`
+	SizeInfo
+	Reserved  uint64 `json:"reserved"`
+	Available uint64 `json:"available"`
+}
+
+// ProvisionCapacityResp represents the capacity available to provision
+// volumes with. MaxSize holds the size of the largest volume which can be
+// provisioned with each replica count, starting with replica 1.
+type ProvisionCapacityResp struct {
+	MaxSize []uint64    `json:"max-size"`
+	Nodes   []BrickRoot `json:"nodes"`
+}
+
+// SnapBrick represents the snapshot of a brick of a volume
+type SnapBrick struct {
+	NodeID uuid.UUID `json:"node-id"`
+	Path   string    `json:"path"`
+	VG     string    `json:"vg"`
+	LV     string    `json:"lv"`
+	FSType string    `json:"fstype"`
+	Dir    string    `json:"dir"`
+}
+
+// Snapshot represents a snapshot of a volume. Volinfo is the volume as it was
+// when the snapshot was taken.
+type Snapshot struct {
+	ID          uuid.UUID   `json:"id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	CreatedAt   time.Time   `json:"created-at"`
+	Scheduled   bool        `json:"scheduled,omitempty"`
+	Volinfo     Volinfo     `json:"volinfo"`
+	Bricks      []SnapBrick `json:"bricks"`
+}
+
+// SnapSchedule represents the snapshot schedule of a volume
+type SnapSchedule struct {
+	Volume    string    `json:"volume"`
+	Schedule  string    `json:"schedule"`
+	Retain    int       `json:"retain"`
+	CreatedAt time.Time `json:"created-at"`
+	LastRun   time.Time `json:"last-run"`
+}
+
+// LeaderResp represents the current leader of the cluster, which runs the
+// cluster wide background jobs
+type LeaderResp struct {
+	Leader uuid.UUID `json:"leader"`
+}
+
+// Orphan represents a piece of data which isn't referenced by the state of the
+// cluster anymore, such as the files of a deleted volume or the pidfile of a
+// dead daemon. Node isn't set for orphaned entries in the store.
+type Orphan struct {
+	Kind   string    `json:"kind"`
+	Node   uuid.UUID `json:"node,omitempty"`
+	Name   string    `json:"name"`
+	Reason string    `json:"reason"`
+}
+
+// GCReportResp is the response sent for an orphaned data report request
+type GCReportResp struct {
+	Orphans []Orphan `json:"orphans"`
+}
+
+// DiskUsageConfig represents the disk usage watermarks of the bricks of the
+// cluster, and the reserve kept free on them, as percentages
+type DiskUsageConfig struct {
+	Warning  int `json:"warning"`
+	Critical int `json:"critical"`
+	Reserve  int `json:"reserve"`
+}
+
+// BrickStopResult is the result of stopping a brick of a volume. Killed is
+// set if the brick didn't exit within the stop timeout.
+type BrickStopResult struct {
+	Brick  string    `json:"brick"`
+	NodeID uuid.UUID `json:"node-id"`
+	Pid    int       `json:"pid,omitempty"`
+	Killed bool      `json:"killed,omitempty"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// VolStopResp is the response sent by version 2 of the API for a volume stop
+// request
+type VolStopResp struct {
+	Volume Volinfo           `json:"volume"`
+	Bricks []BrickStopResult `json:"bricks"`
+}
+
+// VolBatchResult is the outcome of starting or stopping a volume of a batch,
+// one of done, skipped and failed
+type VolBatchResult struct {
+	Volume string `json:"volume"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// VolBatchResp is the response sent for a request to start or stop several
+// volumes
+type VolBatchResp struct {
+	Results []VolBatchResult `json:"results"`
+}
+
+// ClusterOption is the value of an option of the cluster
+type ClusterOption struct {
+	Name         string `json:"name"`
+	Value        string `json:"value"`
+	DefaultValue string `json:"default-value"`
+	Modified     bool   `json:"modified"`
+	Description  string `json:"description"`
+}
+
+// ApplyAction is an operation of the plan to bring the cluster to the state
+// of a spec
+type ApplyAction struct {
+	Op      string            `json:"op"`
+	Volume  string            `json:"volume"`
+	Size    uint64            `json:"size,omitempty"`
+	Options map[string]string `json:"options,omitempty"`
+	Done    bool              `json:"done"`
+	Error   string            `json:"error,omitempty"`
+}
+
+// ApplyResp is the plan to bring the cluster to the state of a spec, and the
+// outcome of its operations. Unmanaged lists the volumes not in the spec.
+type ApplyResp struct {
+	DryRun    bool          `json:"dry-run"`
+	Actions   []ApplyAction `json:"actions"`
+	Unmanaged []string      `json:"unmanaged"`
+}
+
+// Backup is a portable copy of the configuration of a cluster
+type Backup struct {
+	Version int               `json:"version"`
+	Created time.Time         `json:"created"`
+	Entries map[string]string `json:"entries"`
+}
+
+// RestoreReport lists the keys of a backup by the outcome of their restore
+type RestoreReport struct {
+	Restored  []string `json:"restored"`
+	Unchanged []string `json:"unchanged"`
+	Conflicts []string `json:"conflicts"`
+}
+
+// VolMountInfo holds the parameters to mount a volume with the native client.
+// FSTab and SystemdUnit are set only when a mount point is requested.
+type VolMountInfo struct {
+	Volume          string            `json:"volume"`
+	VolfileServers  []string          `json:"volfile-servers"`
+	Transport       string            `json:"transport"`
+	Source          string            `json:"source"`
+	Options         map[string]string `json:"options"`
+	Command         string            `json:"command"`
+	MountPoint      string            `json:"mount-point,omitempty"`
+	FSTab           string            `json:"fstab,omitempty"`
+	SystemdUnit     string            `json:"systemd-unit,omitempty"`
+	SystemdUnitName string            `json:"systemd-unit-name,omitempty"`
+}
@@ -0,0 +1,264 @@
+// Package gd1 reads the volume and peer metadata saved by GlusterD1 in its
+// working directory, usually /var/lib/glusterd.
+package gd1
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// DefaultDir is the default working directory of GlusterD1
+const DefaultDir = "/var/lib/glusterd"
+
+// Volume types of GlusterD1
+const (
+	TypeDistribute       = 0
+	TypeStripe           = 1
+	TypeReplicate        = 2
+	TypeStripeReplicate  = 3
+	TypeDisperse         = 4
+	TypeTier             = 5
+	TypeDistributeStripe = 6
+)
+
+// Volume statuses of GlusterD1
+const (
+	StatusCreated = 0
+	StatusStarted = 1
+	StatusStopped = 2
+)
+
+// Transport types of GlusterD1
+var transports = []string{"tcp", "rdma", "tcp,rdma"}
+
+// Brick is a brick of a GlusterD1 volume
+type Brick struct {
+	UUID     string
+	Hostname string
+	Path     string
+}
+
+// Volume is a volume of GlusterD1
+type Volume struct {
+	Name            string
+	ID              string
+	Type            int
+	Status          int
+	StripeCount     int
+	ReplicaCount    int
+	ArbiterCount    int
+	DisperseCount   int
+	RedundancyCount int
+	Transport       string
+	TierEnabled     bool
+	Username        string
+	Password        string
+	Options         map[string]string
+	Bricks          []Brick
+}
+
+// Peer is a peer of GlusterD1
+type Peer struct {
+	UUID      string
+	State     int
+	Hostnames []string
+}
+
+// readKeyValues reads a GlusterD1 store file of key=value lines. The keys
+// are returned in the order they are present in the file.
+func readKeyValues(file string) (map[string]string, []string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	kv := make(map[string]string)
+	var keys []string
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return nil, nil, fmt.Errorf("%s: invalid line %q", file, line)
+		}
+		if _, ok := kv[parts[0]]; !ok {
+			keys = append(keys, parts[0])
+		}
+		kv[parts[0]] = parts[1]
+	}
+
+	return kv, keys, scanner.Err()
+}
+
+func atoi(kv map[string]string, key string) (int, error) {
+	v, ok := kv[key]
+	if !ok {
+		return 0, nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q for %s", v, key)
+	}
+	return i, nil
+}
+
+// ReadUUID returns the UUID of the GlusterD1 node with the given working
+// directory
+func ReadUUID(dir string) (string, error) {
+	kv, _, err := readKeyValues(path.Join(dir, "glusterd.info"))
+	if err != nil {
+		return "", err
+	}
+	return kv["UUID"], nil
+}
+
+// ReadPeers returns the peers of the GlusterD1 node with the given working
+// directory
+func ReadPeers(dir string) ([]Peer, error) {
+	peersDir := path.Join(dir, "peers")
+	files, err := ioutil.ReadDir(peersDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var peers []Peer
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		kv, keys, err := readKeyValues(path.Join(peersDir, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		p := Peer{UUID: kv["uuid"]}
+		if p.State, err = atoi(kv, "state"); err != nil {
+			return nil, fmt.Errorf("peer %s: %s", f.Name(), err)
+		}
+		for _, k := range keys {
+			if strings.HasPrefix(k, "hostname") {
+				p.Hostnames = append(p.Hostnames, kv[k])
+			}
+		}
+		peers = append(peers, p)
+	}
+
+	return peers, nil
+}
+
+// ReadVolumes returns the volumes of the GlusterD1 node with the given
+// working directory
+func ReadVolumes(dir string) ([]Volume, error) {
+	volsDir := path.Join(dir, "vols")
+	files, err := ioutil.ReadDir(volsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var vols []Volume
+	for _, f := range files {
+		if !f.IsDir() {
+			continue
+		}
+		v, err := ReadVolume(dir, f.Name())
+		if err != nil {
+			return nil, err
+		}
+		vols = append(vols, *v)
+	}
+
+	return vols, nil
+}
+
+// ReadVolume returns the volume with the given name of the GlusterD1 node
+// with the given working directory
+func ReadVolume(dir, name string) (*Volume, error) {
+	volDir := path.Join(dir, "vols", name)
+	kv, keys, err := readKeyValues(path.Join(volDir, "info"))
+	if err != nil {
+		return nil, err
+	}
+
+	v := &Volume{
+		Name:     name,
+		ID:       kv["volume-id"],
+		Username: kv["username"],
+		Password: kv["password"],
+		Options:  make(map[string]string),
+	}
+
+	ints := []struct {
+		key string
+		val *int
+	}{
+		{"type", &v.Type},
+		{"status", &v.Status},
+		{"stripe_count", &v.StripeCount},
+		{"replica_count", &v.ReplicaCount},
+		{"arbiter_count", &v.ArbiterCount},
+		{"disperse_count", &v.DisperseCount},
+		{"redundancy_count", &v.RedundancyCount},
+	}
+	for _, i := range ints {
+		if *i.val, err = atoi(kv, i.key); err != nil {
+			return nil, fmt.Errorf("volume %s: %s", name, err)
+		}
+	}
+
+	transport, err := atoi(kv, "transport-type")
+	if err != nil || transport < 0 || transport >= len(transports) {
+		return nil, fmt.Errorf("volume %s: invalid transport-type %q", name, kv["transport-type"])
+	}
+	v.Transport = transports[transport]
+	v.TierEnabled = kv["tier-enabled"] == "1"
+
+	// Bricks are listed as brick-<index>, naming the brick files
+	brickFiles := make(map[int]string)
+	for _, k := range keys {
+		switch {
+		case strings.HasPrefix(k, "brick-"):
+			i, err := strconv.Atoi(strings.TrimPrefix(k, "brick-"))
+			if err != nil {
+				return nil, fmt.Errorf("volume %s: invalid brick key %s", name, k)
+			}
+			brickFiles[i] = kv[k]
+		case strings.Contains(k, "."):
+			// Only volume options have a '.' in their keys
+			v.Options[k] = kv[k]
+		}
+	}
+
+	for i := 0; i < len(brickFiles); i++ {
+		file, ok := brickFiles[i]
+		if !ok {
+			return nil, fmt.Errorf("volume %s: brick-%d not found", name, i)
+		}
+		bkv, _, err := readKeyValues(path.Join(volDir, "bricks", file))
+		if err != nil {
+			return nil, fmt.Errorf("volume %s: %s", name, err)
+		}
+		v.Bricks = append(v.Bricks, Brick{
+			UUID:     bkv["uuid"],
+			Hostname: bkv["hostname"],
+			Path:     bkv["path"],
+		})
+	}
+
+	return v, nil
+}
@@ -0,0 +1,75 @@
+package gd1
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/gluster/glusterd2/tests"
+)
+
+const testInfo = `type=2
+count=2
+status=1
+sub_count=2
+stripe_count=1
+replica_count=2
+arbiter_count=0
+disperse_count=0
+redundancy_count=0
+version=3
+transport-type=0
+volume-id=5a0e6b1d-7c1c-4d2a-9b6e-6d2f0a3d6c11
+username=user
+password=pass
+op-version=31000
+parent_volname=N/A
+performance.readdir-ahead=on
+nfs.disable=on
+brick-0=server1:-bricks-b1
+brick-1=server2:-bricks-b1
+`
+
+func writeFile(t *testing.T, file, content string) {
+	tests.Assert(t, os.MkdirAll(path.Dir(file), 0755) == nil)
+	tests.Assert(t, ioutil.WriteFile(file, []byte(content), 0644) == nil)
+}
+
+func TestReadVolumes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gd1")
+	tests.Assert(t, err == nil)
+	defer os.RemoveAll(dir)
+
+	volDir := path.Join(dir, "vols", "gv0")
+	writeFile(t, path.Join(volDir, "info"), testInfo)
+	writeFile(t, path.Join(volDir, "bricks", "server1:-bricks-b1"), "uuid=u1\nhostname=server1\npath=/bricks/b1\n")
+	writeFile(t, path.Join(volDir, "bricks", "server2:-bricks-b1"), "uuid=u2\nhostname=server2\npath=/bricks/b1\n")
+
+	vols, err := ReadVolumes(dir)
+	tests.Assert(t, err == nil)
+	tests.Assert(t, len(vols) == 1)
+
+	v := vols[0]
+	tests.Assert(t, v.Name == "gv0")
+	tests.Assert(t, v.ID == "5a0e6b1d-7c1c-4d2a-9b6e-6d2f0a3d6c11")
+	tests.Assert(t, v.Type == TypeReplicate && v.Status == StatusStarted)
+	tests.Assert(t, v.ReplicaCount == 2 && v.Transport == "tcp")
+	tests.Assert(t, len(v.Options) == 2 && v.Options["nfs.disable"] == "on")
+	tests.Assert(t, len(v.Bricks) == 2)
+	tests.Assert(t, v.Bricks[1] == Brick{"u2", "server2", "/bricks/b1"})
+}
+
+func TestReadPeers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gd1")
+	tests.Assert(t, err == nil)
+	defer os.RemoveAll(dir)
+
+	writeFile(t, path.Join(dir, "peers", "u2"), "uuid=u2\nstate=3\nhostname1=server2\nhostname2=10.0.0.2\n")
+
+	peers, err := ReadPeers(dir)
+	tests.Assert(t, err == nil)
+	tests.Assert(t, len(peers) == 1)
+	tests.Assert(t, peers[0].UUID == "u2" && peers[0].State == 3)
+	tests.Assert(t, len(peers[0].Hostnames) == 2 && peers[0].Hostnames[1] == "10.0.0.2")
+}
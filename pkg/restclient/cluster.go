@@ -0,0 +1,111 @@
+package restclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gluster/glusterd2/pkg/api"
+)
+
+// OpVersion returns the current and maximum supported op-version of the
+// cluster
+func (c *Client) OpVersion() (api.OpVersion, error) {
+	var v api.OpVersion
+	err := c.get("/v1/cluster/op-version", nil, http.StatusOK, &v)
+	return v, err
+}
+
+// SetOpVersion bumps the op-version of the cluster
+func (c *Client) SetOpVersion(opversion int) (api.OpVersion, error) {
+	var v api.OpVersion
+	err := c.post("/v1/cluster/op-version", api.OpVersionReq{OpVersion: opversion}, http.StatusOK, &v)
+	return v, err
+}
+
+// Leader returns the ID of the peer which is the current leader of the
+// cluster
+func (c *Client) Leader() (api.LeaderResp, error) {
+	var resp api.LeaderResp
+	err := c.get("/v1/cluster/leader", nil, http.StatusOK, &resp)
+	return resp, err
+}
+
+// GCReport returns the orphaned data found on the peers and in the store,
+// which will be cleaned up by the reconciler
+func (c *Client) GCReport() (api.GCReportResp, error) {
+	var resp api.GCReportResp
+	err := c.get("/v1/cluster/gc", nil, http.StatusOK, &resp)
+	return resp, err
+}
+
+// DiskUsageConfig returns the disk usage watermarks and reserve of the bricks
+// of the cluster
+func (c *Client) DiskUsageConfig() (api.DiskUsageConfig, error) {
+	var cfg api.DiskUsageConfig
+	err := c.get("/v1/cluster/disk-usage", nil, http.StatusOK, &cfg)
+	return cfg, err
+}
+
+// SetDiskUsageConfig updates the disk usage watermarks and reserve of the
+// bricks of the cluster
+func (c *Client) SetDiskUsageConfig(cfg api.DiskUsageConfig) (api.DiskUsageConfig, error) {
+	var resp api.DiskUsageConfig
+	err := c.post("/v1/cluster/disk-usage", cfg, http.StatusOK, &resp)
+	return resp, err
+}
+
+// Backup returns a backup of the configuration of the cluster
+func (c *Client) Backup() (api.Backup, error) {
+	var b api.Backup
+	err := c.get("/v1/backup", nil, http.StatusOK, &b)
+	return b, err
+}
+
+// Restore restores the configuration of a cluster from a backup. Keys which
+// conflict with the current configuration are only overwritten with force.
+// With dryRun set, the report is returned without restoring anything.
+func (c *Client) Restore(b api.Backup, force, dryRun bool) (api.RestoreReport, error) {
+	var report api.RestoreReport
+	url := fmt.Sprintf("/v1/restore?force=%t&dry-run=%t", force, dryRun)
+	err := c.post(url, b, http.StatusOK, &report)
+	return report, err
+}
+
+// ClusterOptions returns the values of the options of the cluster
+func (c *Client) ClusterOptions() ([]api.ClusterOption, error) {
+	var opts []api.ClusterOption
+	err := c.get("/v1/cluster/options", nil, http.StatusOK, &opts)
+	return opts, err
+}
+
+// SetClusterOptions sets options of the cluster. Options set to an empty
+// value are reset to their default.
+func (c *Client) SetClusterOptions(options map[string]string) ([]api.ClusterOption, error) {
+	var opts []api.ClusterOption
+	err := c.post("/v1/cluster/options", api.ClusterOptionReq{Options: options}, http.StatusOK, &opts)
+	return opts, err
+}
+
+// DebugCollect collects debug information from the peers of the cluster, and
+// writes the archive, a gzipped tar, to w
+func (c *Client) DebugCollect(req api.DebugCollectReq, w io.Writer) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	resp, err := c.send("POST", c.baseURL+"/v1/debug/collect", "application/gzip", body, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		raw, _ := ioutil.ReadAll(resp.Body)
+		return newUnexpectedStatusError(http.StatusOK, resp.StatusCode, raw)
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
@@ -1,14 +1,23 @@
 package restclient
 
 import (
+	"bytes"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
-	"strings"
+	"time"
 
 	"github.com/gluster/glusterd2/pkg/api"
+
+	"github.com/pborman/uuid"
+)
+
+const (
+	defaultRetryBackoff = 500 * time.Millisecond
 )
 
 // Client represents Glusterd2 REST Client
@@ -16,20 +25,63 @@ type Client struct {
 	baseURL  string
 	username string
 	password string
+
+	httpClient   *http.Client
+	retries      int
+	retryBackoff time.Duration
 }
 
 // New creates new instance of Glusterd REST Client
 func New(baseURL string, username string, password string) *Client {
-	return &Client{baseURL, username, password}
+	return &Client{
+		baseURL:      baseURL,
+		username:     username,
+		password:     password,
+		httpClient:   &http.Client{},
+		retryBackoff: defaultRetryBackoff,
+	}
 }
 
-func parseHTTPError(jsonData []byte) string {
-	var errstr api.HTTPError
-	err := json.Unmarshal(jsonData, &errstr)
-	if err != nil {
-		return ""
+// NewUnix creates a new instance of Glusterd REST Client which connects to the
+// UNIX socket GlusterD serves the REST API on for local clients
+func NewUnix(socket string, username string, password string) *Client {
+	c := New("http://localhost", username, password)
+	c.httpClient.Transport = &http.Transport{
+		Dial: func(network, addr string) (net.Conn, error) {
+			return net.Dial("unix", socket)
+		},
 	}
-	return errstr.Error
+	return c
+}
+
+// SetTLSConfig sets the TLS configuration used to connect to a GlusterD
+// serving the REST API over HTTPS
+func (c *Client) SetTLSConfig(config *tls.Config) {
+	c.httpClient.Transport = &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: config,
+	}
+}
+
+// SetTimeout sets the time limit for requests made by the client. A timeout
+// of zero means no timeout.
+func (c *Client) SetTimeout(timeout time.Duration) {
+	c.httpClient.Timeout = timeout
+}
+
+// SetRetries sets the number of times a request is retried when GlusterD
+// can't be reached or is unavailable. Requests which aren't idempotent are
+// sent with an idempotency key, so that retrying them is safe. The wait
+// between attempts starts at backoff and doubles after every attempt.
+func (c *Client) SetRetries(retries int, backoff time.Duration) {
+	c.retries = retries
+	c.retryBackoff = backoff
+}
+
+func parseHTTPError(jsonData []byte) api.HTTPError {
+	var errstr api.HTTPError
+	json.Unmarshal(jsonData, &errstr)
+	return errstr
 }
 
 func (c *Client) post(url string, data interface{}, expectStatusCode int, output interface{}) error {
@@ -40,6 +92,10 @@ func (c *Client) put(url string, data interface{}, expectStatusCode int, output
 	return c.do("PUT", url, data, expectStatusCode, output)
 }
 
+func (c *Client) patch(url string, data interface{}, expectStatusCode int, output interface{}) error {
+	return c.do("PATCH", url, data, expectStatusCode, output)
+}
+
 func (c *Client) get(url string, data interface{}, expectStatusCode int, output interface{}) error {
 	return c.do("GET", url, data, expectStatusCode, output)
 }
@@ -48,36 +104,108 @@ func (c *Client) del(url string, data interface{}, expectStatusCode int, output
 	return c.do("DELETE", url, data, expectStatusCode, output)
 }
 
+// isIdempotent returns true for the HTTP methods which can be safely
+// retried
+func isIdempotent(method string) bool {
+	switch method {
+	case "GET", "PUT", "DELETE":
+		return true
+	}
+	return false
+}
+
+// shouldRetry returns true if a request which failed with the given response
+// or error may succeed when retried
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// send sends a request with a JSON body, which accepts responses of the given
+// media type
+func (c *Client) send(method string, url string, accept string, reqBody []byte, idempotencyKey string) (*http.Response, error) {
+	var body io.Reader
+	if reqBody != nil {
+		body = bytes.NewReader(reqBody)
+	}
+
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	req.Header.Set("Content-Type", "application/json")
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	return c.httpClient.Do(req)
+}
+
 func (c *Client) do(method string, url string, data interface{}, expectStatusCode int, output interface{}) error {
+	// Requests which aren't idempotent are made safe to retry with an
+	// idempotency key, so that GlusterD serves them only once
+	var idempotencyKey string
+	if c.retries > 0 && !isIdempotent(method) {
+		idempotencyKey = uuid.NewRandom().String()
+	}
+	return c.doWithKey(method, url, idempotencyKey, data, expectStatusCode, output)
+}
+
+// postWithKey sends a POST request with the given idempotency key, so that
+// the request can be retried safely by the caller, even by another client. A
+// random key is used if none is given and the client retries requests.
+func (c *Client) postWithKey(url string, idempotencyKey string, data interface{}, expectStatusCode int, output interface{}) error {
+	if idempotencyKey == "" {
+		return c.post(url, data, expectStatusCode, output)
+	}
+	return c.doWithKey("POST", url, idempotencyKey, data, expectStatusCode, output)
+}
+
+func (c *Client) doWithKey(method string, url string, idempotencyKey string, data interface{}, expectStatusCode int, output interface{}) error {
 	url = fmt.Sprintf("%s%s", c.baseURL, url)
 
-	var body io.Reader
+	var reqBody []byte
 	if data != nil {
-		reqBody, marshalErr := json.Marshal(data)
-		if marshalErr != nil {
+		var marshalErr error
+		if reqBody, marshalErr = json.Marshal(data); marshalErr != nil {
 			return marshalErr
 		}
-		body = strings.NewReader(string(reqBody))
 	}
 
-	req, err := http.NewRequest(method, url, body)
+	var resp *http.Response
+	var err error
+	backoff := c.retryBackoff
+	for attempt := 0; ; attempt++ {
+		resp, err = c.send(method, url, "application/json", reqBody, idempotencyKey)
+		if attempt >= c.retries || !shouldRetry(resp, err) {
+			break
+		}
+		if err == nil {
+			resp.Body.Close()
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
 	if err != nil {
 		return err
 	}
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Content-Type", "application/json")
-	resp, err1 := http.DefaultClient.Do(req)
-	if err1 != nil {
-		return err1
-	}
 
 	defer resp.Body.Close()
-	outputRaw, err2 := ioutil.ReadAll(resp.Body)
-	if err2 != nil {
-		return err2
+	outputRaw, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
 	}
 	if resp.StatusCode != expectStatusCode {
-		return &UnexpectedStatusError{"Unexpected Status", expectStatusCode, resp.StatusCode, parseHTTPError(outputRaw)}
+		return newUnexpectedStatusError(expectStatusCode, resp.StatusCode, outputRaw)
 	}
 
 	if output != nil {
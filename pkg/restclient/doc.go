@@ -0,0 +1,28 @@
+/*
+Package restclient implements a Go client for the GlusterD 2.0 REST API.
+
+The request and response types used by the client are defined in the
+github.com/gluster/glusterd2/pkg/api package, which mirrors the types used by
+the server.
+
+	client := restclient.New("https://gd2.example.com:24007", "user", "secret")
+	client.SetTLSConfig(&tls.Config{RootCAs: pool})
+	client.SetRetries(3, time.Second)
+
+	vol, err := client.VolumeCreate(api.VolCreateReq{
+		Name:   "gv1",
+		Bricks: []string{"node1:/bricks/b1", "node2:/bricks/b2"},
+	})
+
+Requests which fail with an unexpected HTTP status return an
+*UnexpectedStatusError, whose StatusCode method returns the status returned by
+GlusterD. Its Code method returns the code of the error, which is stable
+across releases unlike the error message:
+
+	if restclient.ErrorCode(err) == api.ErrCodeQuorumNotMet {
+		// retry later
+	}
+
+Only idempotent requests (GET, PUT and DELETE) are retried.
+*/
+package restclient
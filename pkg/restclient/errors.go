@@ -2,6 +2,8 @@ package restclient
 
 import (
 	"fmt"
+
+	"github.com/gluster/glusterd2/pkg/api"
 )
 
 // UnexpectedStatusError is custom error when expected
@@ -11,8 +13,48 @@ type UnexpectedStatusError struct {
 	expected int
 	actual   int
 	resp     string
+	code     string
+	fields   []api.FieldError
+}
+
+func newUnexpectedStatusError(expected, actual int, body []byte) *UnexpectedStatusError {
+	httpErr := parseHTTPError(body)
+	return &UnexpectedStatusError{
+		msg:      "Unexpected Status",
+		expected: expected,
+		actual:   actual,
+		resp:     httpErr.Error,
+		code:     httpErr.Code,
+		fields:   httpErr.Errors,
+	}
 }
 
 func (e *UnexpectedStatusError) Error() string {
 	return fmt.Sprintf("%s (expected=%d actual=%d)", e.resp, e.expected, e.actual)
 }
+
+// StatusCode returns the HTTP status code returned by the server
+func (e *UnexpectedStatusError) StatusCode() int {
+	return e.actual
+}
+
+// Code returns the code of the error returned by the server, one of the
+// api.ErrCode constants or a code derived from the HTTP status
+func (e *UnexpectedStatusError) Code() string {
+	return e.code
+}
+
+// FieldErrors returns the invalid fields of the request, for requests which
+// failed validation
+func (e *UnexpectedStatusError) FieldErrors() []api.FieldError {
+	return e.fields
+}
+
+// ErrorCode returns the code of an error returned by the client, or an empty
+// string if the error wasn't returned by the server
+func ErrorCode(err error) string {
+	if e, ok := err.(*UnexpectedStatusError); ok {
+		return e.code
+	}
+	return ""
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 
 	"github.com/gluster/glusterd2/pkg/api"
 )
@@ -63,3 +64,50 @@ func (c *Client) Peers() ([]api.Peer, error) {
 	err := c.get("/v1/peers", nil, http.StatusOK, &peers)
 	return peers, err
 }
+
+// PeersWithMetaData gets the list of Gluster Peers having all the given
+// metadata
+func (c *Client) PeersWithMetaData(filter map[string]string) ([]api.Peer, error) {
+	query := url.Values{}
+	for k, v := range filter {
+		query.Set("metadata."+k, v)
+	}
+
+	var peers []api.Peer
+	err := c.get("/v1/peers?"+query.Encode(), nil, http.StatusOK, &peers)
+	return peers, err
+}
+
+// PeerEditMetaData adds, updates or removes metadata of a peer. Keys with
+// empty values are removed from the peer metadata.
+func (c *Client) PeerEditMetaData(peerid string, metadata map[string]string) (api.Peer, error) {
+	req := api.PeerEditReq{
+		MetaData: metadata,
+	}
+
+	var resp api.Peer
+	url := fmt.Sprintf("/v1/peers/%s/metadata", peerid)
+	err := c.patch(url, req, http.StatusOK, &resp)
+	return resp, err
+}
+
+// PeerMaintenance places a peer into, or takes it out of, maintenance mode
+func (c *Client) PeerMaintenance(peerid string, enable bool, force bool) (api.Peer, error) {
+	req := api.PeerMaintenanceReq{
+		Enable: enable,
+		Force:  force,
+	}
+
+	var resp api.Peer
+	url := fmt.Sprintf("/v1/peers/%s/maintenance", peerid)
+	err := c.post(url, req, http.StatusOK, &resp)
+	return resp, err
+}
+
+// PeerRename changes the hostname of a peer, and of the bricks on it
+func (c *Client) PeerRename(peerid string, hostname string) (api.Peer, error) {
+	var resp api.Peer
+	url := fmt.Sprintf("/v1/peers/%s/rename", peerid)
+	err := c.post(url, api.PeerRenameReq{Hostname: hostname}, http.StatusOK, &resp)
+	return resp, err
+}
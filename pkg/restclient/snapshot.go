@@ -0,0 +1,81 @@
+package restclient
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gluster/glusterd2/pkg/api"
+)
+
+// SnapshotCreate takes a snapshot of a Gluster Volume. All the bricks of the
+// volume must be on thinly provisioned logical volumes.
+func (c *Client) SnapshotCreate(req api.SnapCreateReq) (api.Snapshot, error) {
+	var snap api.Snapshot
+	err := c.post("/v1/snapshots", req, http.StatusCreated, &snap)
+	return snap, err
+}
+
+// Snapshots returns the snapshots of a Gluster Volume, or of all volumes if
+// volname is empty
+func (c *Client) Snapshots(volname string) ([]api.Snapshot, error) {
+	var snaps []api.Snapshot
+	path := "/v1/snapshots"
+	if volname != "" {
+		path += "?" + url.Values{"volume": {volname}}.Encode()
+	}
+	err := c.get(path, nil, http.StatusOK, &snaps)
+	return snaps, err
+}
+
+// Snapshot returns information about a snapshot
+func (c *Client) Snapshot(snapname string) (api.Snapshot, error) {
+	var snap api.Snapshot
+	url := fmt.Sprintf("/v1/snapshots/%s", snapname)
+	err := c.get(url, nil, http.StatusOK, &snap)
+	return snap, err
+}
+
+// SnapshotDelete deletes a snapshot
+func (c *Client) SnapshotDelete(snapname string) error {
+	url := fmt.Sprintf("/v1/snapshots/%s", snapname)
+	return c.del(url, nil, http.StatusOK, nil)
+}
+
+// SnapshotClone creates a new Gluster Volume from a snapshot. The new volume
+// is not started.
+func (c *Client) SnapshotClone(snapname string, volname string) (api.Volinfo, error) {
+	var vol api.Volinfo
+	url := fmt.Sprintf("/v1/snapshots/%s/clone", snapname)
+	err := c.post(url, api.SnapCloneReq{Name: volname}, http.StatusCreated, &vol)
+	return vol, err
+}
+
+// SnapshotScheduleSet sets the snapshot schedule of a Gluster Volume
+func (c *Client) SnapshotScheduleSet(volname string, req api.SnapScheduleReq) (api.SnapSchedule, error) {
+	var sched api.SnapSchedule
+	url := fmt.Sprintf("/v1/snapshot-schedules/%s", volname)
+	err := c.post(url, req, http.StatusOK, &sched)
+	return sched, err
+}
+
+// SnapshotSchedule returns the snapshot schedule of a Gluster Volume
+func (c *Client) SnapshotSchedule(volname string) (api.SnapSchedule, error) {
+	var sched api.SnapSchedule
+	url := fmt.Sprintf("/v1/snapshot-schedules/%s", volname)
+	err := c.get(url, nil, http.StatusOK, &sched)
+	return sched, err
+}
+
+// SnapshotSchedules returns the snapshot schedules of all volumes
+func (c *Client) SnapshotSchedules() ([]api.SnapSchedule, error) {
+	var schedules []api.SnapSchedule
+	err := c.get("/v1/snapshot-schedules", nil, http.StatusOK, &schedules)
+	return schedules, err
+}
+
+// SnapshotScheduleDelete removes the snapshot schedule of a Gluster Volume
+func (c *Client) SnapshotScheduleDelete(volname string) error {
+	url := fmt.Sprintf("/v1/snapshot-schedules/%s", volname)
+	return c.del(url, nil, http.StatusOK, nil)
+}
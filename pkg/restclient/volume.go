@@ -3,6 +3,8 @@ package restclient
 import (
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 
 	"github.com/gluster/glusterd2/pkg/api"
 )
@@ -14,6 +16,22 @@ func (c *Client) VolumeCreate(req api.VolCreateReq) (api.Volinfo, error) {
 	return vol, err
 }
 
+// VolumeCreateDryRun validates a volume create request without creating the
+// volume
+func (c *Client) VolumeCreateDryRun(req api.VolCreateReq) (api.VolDryRunResp, error) {
+	var resp api.VolDryRunResp
+	err := c.post("/v1/volumes?dry-run=true", req, http.StatusOK, &resp)
+	return resp, err
+}
+
+// VolumeExpand adds bricks to a Gluster Volume
+func (c *Client) VolumeExpand(volname string, req api.VolExpandReq) (api.Volinfo, error) {
+	var vol api.Volinfo
+	url := fmt.Sprintf("/v1/volumes/%s/expand", volname)
+	err := c.post(url, req, http.StatusOK, &vol)
+	return vol, err
+}
+
 // Volumes returns list of all volumes
 func (c *Client) Volumes() (api.VolList, error) {
 	var vols api.VolList
@@ -21,6 +39,52 @@ func (c *Client) Volumes() (api.VolList, error) {
 	return vols, err
 }
 
+func volListQuery(opts api.VolListOpts, details bool) string {
+	q := url.Values{}
+	if opts.State != "" {
+		q.Set("state", opts.State)
+	}
+	if opts.Type != "" {
+		q.Set("type", opts.Type)
+	}
+	for k, v := range opts.Options {
+		if v == "" {
+			q.Add("option", k)
+		} else {
+			q.Add("option", k+"="+v)
+		}
+	}
+	if opts.Limit > 0 {
+		q.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Offset > 0 {
+		q.Set("offset", strconv.Itoa(opts.Offset))
+	}
+	if details {
+		q.Set("details", "true")
+	}
+	if len(q) == 0 {
+		return ""
+	}
+	return "?" + q.Encode()
+}
+
+// VolumesWithOpts returns the summaries of the volumes matching the given
+// filtering and pagination options
+func (c *Client) VolumesWithOpts(opts api.VolListOpts) (api.VolListPage, error) {
+	var vols api.VolListPage
+	err := c.get("/v2/volumes"+volListQuery(opts, false), nil, http.StatusOK, &vols)
+	return vols, err
+}
+
+// VolumesDetail returns the full information of the volumes matching the
+// given filtering and pagination options
+func (c *Client) VolumesDetail(opts api.VolListOpts) (api.VolDetailListPage, error) {
+	var vols api.VolDetailListPage
+	err := c.get("/v2/volumes"+volListQuery(opts, true), nil, http.StatusOK, &vols)
+	return vols, err
+}
+
 // VolumeStart starts a Gluster Volume
 func (c *Client) VolumeStart(volname string) error {
 	url := fmt.Sprintf("/v1/volumes/%s/start", volname)
@@ -33,8 +97,277 @@ func (c *Client) VolumeStop(volname string) error {
 	return c.post(url, nil, http.StatusOK, nil)
 }
 
+// VolumeStopWithResults stops a Gluster Volume, and returns the result of
+// stopping each of its bricks
+func (c *Client) VolumeStopWithResults(volname string) (api.VolStopResp, error) {
+	var resp api.VolStopResp
+	url := fmt.Sprintf("/v2/volumes/%s/stop", volname)
+	err := c.post(url, nil, http.StatusOK, &resp)
+	return resp, err
+}
+
+// VolumesStart starts the given volumes, after the volumes they depend on
+func (c *Client) VolumesStart(volnames []string) (api.VolBatchResp, error) {
+	var resp api.VolBatchResp
+	err := c.post("/v1/volumes/start", api.VolBatchReq{Volumes: volnames}, http.StatusOK, &resp)
+	return resp, err
+}
+
+// VolumesStop stops the given volumes, before the volumes they depend on
+func (c *Client) VolumesStop(volnames []string) (api.VolBatchResp, error) {
+	var resp api.VolBatchResp
+	err := c.post("/v1/volumes/stop", api.VolBatchReq{Volumes: volnames}, http.StatusOK, &resp)
+	return resp, err
+}
+
+// VolumesStopAll stops all the started volumes of the cluster
+func (c *Client) VolumesStopAll() (api.VolBatchResp, error) {
+	var resp api.VolBatchResp
+	err := c.post("/v1/volumes/stop-all", nil, http.StatusOK, &resp)
+	return resp, err
+}
+
 // VolumeDelete deletes a Gluster Volume
 func (c *Client) VolumeDelete(volname string) error {
 	url := fmt.Sprintf("/v1/volumes/%s", volname)
 	return c.del(url, nil, http.StatusOK, nil)
 }
+
+// BrickCleanup removes stale gluster xattrs and internal directories from
+// brick paths which aren't part of any volume
+func (c *Client) BrickCleanup(req api.BrickCleanupReq) error {
+	return c.post("/v1/bricks/cleanup", req, http.StatusOK, nil)
+}
+
+// BrickRelabel sets the SELinux context of the bricks to the one brick
+// processes need to access them
+func (c *Client) BrickRelabel(req api.BrickRelabelReq) error {
+	return c.post("/v1/bricks/relabel", req, http.StatusOK, nil)
+}
+
+// VolumeClients returns the clients connected to each brick of the volume
+func (c *Client) VolumeClients(volname string) ([]api.BrickClients, error) {
+	var clients []api.BrickClients
+	url := fmt.Sprintf("/v1/volumes/%s/clients", volname)
+	err := c.get(url, nil, http.StatusOK, &clients)
+	return clients, err
+}
+
+// VolumeBarrier enables or disables the barrier on all bricks of a volume
+func (c *Client) VolumeBarrier(volname string, enable bool) error {
+	url := fmt.Sprintf("/v1/volumes/%s/barrier", volname)
+	return c.post(url, api.VolBarrierReq{Enable: enable}, http.StatusOK, nil)
+}
+
+// Volume returns information about a Gluster Volume
+func (c *Client) Volume(volname string) (api.Volinfo, error) {
+	var vol api.Volinfo
+	url := fmt.Sprintf("/v1/volumes/%s", volname)
+	err := c.get(url, nil, http.StatusOK, &vol)
+	return vol, err
+}
+
+// VolumeStatus returns the status of the bricks of a Gluster Volume
+func (c *Client) VolumeStatus(volname string) (api.VolStatus, error) {
+	var status api.VolStatus
+	url := fmt.Sprintf("/v1/volumes/%s/status", volname)
+	err := c.get(url, nil, http.StatusOK, &status)
+	return status, err
+}
+
+// VolumeSize returns the total, used and free logical capacity of a Gluster
+// Volume
+func (c *Client) VolumeSize(volname string) (api.VolSizeResp, error) {
+	var size api.VolSizeResp
+	url := fmt.Sprintf("/v1/volumes/%s/size", volname)
+	err := c.get(url, nil, http.StatusOK, &size)
+	return size, err
+}
+
+// VolumeBalance reports how evenly the data of a Gluster Volume is spread
+// over its bricks. If threshold is 0, the rebalance-skew-threshold cluster
+// option is used.
+func (c *Client) VolumeBalance(volname string, threshold int) (api.VolBalanceResp, error) {
+	var report api.VolBalanceResp
+	url := fmt.Sprintf("/v1/volumes/%s/balance", volname)
+	if threshold != 0 {
+		url += fmt.Sprintf("?threshold=%d", threshold)
+	}
+	err := c.get(url, nil, http.StatusOK, &report)
+	return report, err
+}
+
+// VolumeCheck reports whether all the peers see the same configuration of a
+// Gluster Volume as the one in the store
+func (c *Client) VolumeCheck(volname string) (api.VolCheckResp, error) {
+	var check api.VolCheckResp
+	url := fmt.Sprintf("/v1/volumes/%s/check", volname)
+	err := c.get(url, nil, http.StatusOK, &check)
+	return check, err
+}
+
+// VolumeResync regenerates the volfiles of a Gluster Volume and reloads its
+// configuration on all the online peers. The volume is checked again once
+// resynced.
+func (c *Client) VolumeResync(volname string) (api.VolCheckResp, error) {
+	var check api.VolCheckResp
+	url := fmt.Sprintf("/v1/volumes/%s/resync", volname)
+	err := c.post(url, nil, http.StatusOK, &check)
+	return check, err
+}
+
+// VolumeMount returns the parameters to mount a Gluster Volume. If a mount
+// point is given, an fstab entry and a systemd mount unit for it are returned
+// as well.
+func (c *Client) VolumeMount(volname string, mountPoint string) (api.VolMountInfo, error) {
+	var info api.VolMountInfo
+	path := fmt.Sprintf("/v1/volumes/%s/mount", volname)
+	if mountPoint != "" {
+		path += "?" + url.Values{"mountpoint": {mountPoint}}.Encode()
+	}
+	err := c.get(path, nil, http.StatusOK, &info)
+	return info, err
+}
+
+// VolumeSet sets options of a Gluster Volume
+func (c *Client) VolumeSet(volname string, options map[string]string) error {
+	url := fmt.Sprintf("/v1/volumes/%s/options", volname)
+	return c.post(url, api.VolOptionReq{Options: options}, http.StatusOK, nil)
+}
+
+// VolumeOptionTable returns all the options which can be set on volumes
+func (c *Client) VolumeOptionTable() ([]api.OptionInfo, error) {
+	var options []api.OptionInfo
+	err := c.get("/v1/volumes/options", nil, http.StatusOK, &options)
+	return options, err
+}
+
+// VolumeImport imports volumes from the GlusterD1 working directory of the
+// node the client is connected to
+func (c *Client) VolumeImport(req api.VolImportReq) (api.VolImportResp, error) {
+	var resp api.VolImportResp
+	err := c.post("/v1/volumes/import", req, http.StatusOK, &resp)
+	return resp, err
+}
+
+// Profiles returns the builtin and custom option profiles
+func (c *Client) Profiles() ([]api.OptionProfile, error) {
+	var profiles []api.OptionProfile
+	err := c.get("/v1/profiles", nil, http.StatusOK, &profiles)
+	return profiles, err
+}
+
+// Profile returns the option profile with the given name
+func (c *Client) Profile(name string) (api.OptionProfile, error) {
+	var profile api.OptionProfile
+	url := fmt.Sprintf("/v1/profiles/%s", name)
+	err := c.get(url, nil, http.StatusOK, &profile)
+	return profile, err
+}
+
+// ProfileSet creates or replaces a custom option profile
+func (c *Client) ProfileSet(name string, req api.ProfileReq) error {
+	url := fmt.Sprintf("/v1/profiles/%s", name)
+	return c.put(url, req, http.StatusOK, nil)
+}
+
+// ProfileDelete deletes a custom option profile
+func (c *Client) ProfileDelete(name string) error {
+	url := fmt.Sprintf("/v1/profiles/%s", name)
+	return c.del(url, nil, http.StatusOK, nil)
+}
+
+// VolumeProfile sets all the options of an option profile on a volume
+func (c *Client) VolumeProfile(volname string, profile string) error {
+	url := fmt.Sprintf("/v1/volumes/%s/profile", volname)
+	return c.post(url, api.VolProfileReq{Profile: profile}, http.StatusOK, nil)
+}
+
+// VolumeAccess returns the access control list of a volume
+func (c *Client) VolumeAccess(volname string) (api.VolAccess, error) {
+	var access api.VolAccess
+	url := fmt.Sprintf("/v1/volumes/%s/access", volname)
+	err := c.get(url, nil, http.StatusOK, &access)
+	return access, err
+}
+
+// VolumeSetAccess replaces the access control list of a volume
+func (c *Client) VolumeSetAccess(volname string, req api.VolAccessReq) (api.VolAccess, error) {
+	var access api.VolAccess
+	url := fmt.Sprintf("/v1/volumes/%s/access", volname)
+	err := c.put(url, req, http.StatusOK, &access)
+	return access, err
+}
+
+// VolumeSplitBrain resolves a split-brain on a replicate volume
+func (c *Client) VolumeSplitBrain(volname string, req api.SplitBrainReq) (api.SplitBrainResp, error) {
+	var resp api.SplitBrainResp
+	url := fmt.Sprintf("/v1/volumes/%s/heal/split-brain", volname)
+	err := c.post(url, req, http.StatusOK, &resp)
+	return resp, err
+}
+
+// VolumeProvision creates and starts a volume of the given size, with its
+// bricks placed by GlusterD. Provisioning a volume again with the same
+// request returns the existing volume. If idempotencyKey is not empty,
+// retries of the request with the same key are served only once.
+func (c *Client) VolumeProvision(req api.ProvisionReq, idempotencyKey string) (api.Volinfo, error) {
+	var vol api.Volinfo
+	err := c.postWithKey("/v1/provisioner/volumes", idempotencyKey, req, http.StatusCreated, &vol)
+	return vol, err
+}
+
+// VolumeProvisionExpand grows a provisioned volume to the given size. If
+// idempotencyKey is not empty, retries of the request with the same key are
+// served only once.
+func (c *Client) VolumeProvisionExpand(volname string, size uint64, idempotencyKey string) (api.Volinfo, error) {
+	var vol api.Volinfo
+	url := fmt.Sprintf("/v1/provisioner/volumes/%s/expand", volname)
+	err := c.postWithKey(url, idempotencyKey, api.ProvisionExpandReq{Size: size}, http.StatusOK, &vol)
+	return vol, err
+}
+
+// ProvisionSnapshot takes a snapshot of a provisioned volume. Taking the
+// snapshot again with the same name returns the existing snapshot. If
+// idempotencyKey is not empty, retries of the request with the same key are
+// served only once.
+func (c *Client) ProvisionSnapshot(volname string, snapname string, idempotencyKey string) (api.Snapshot, error) {
+	var snap api.Snapshot
+	url := fmt.Sprintf("/v1/provisioner/volumes/%s/snapshots", volname)
+	err := c.postWithKey(url, idempotencyKey, api.ProvisionSnapReq{Name: snapname}, http.StatusCreated, &snap)
+	return snap, err
+}
+
+// ProvisionSnapshotDelete deletes a snapshot of a provisioned volume.
+// Deleting a snapshot which doesn't exist succeeds.
+func (c *Client) ProvisionSnapshotDelete(snapname string) error {
+	url := fmt.Sprintf("/v1/provisioner/snapshots/%s", snapname)
+	return c.del(url, nil, http.StatusOK, nil)
+}
+
+// VolumeProvisionDelete stops and deletes a provisioned volume along with its
+// bricks
+func (c *Client) VolumeProvisionDelete(volname string) error {
+	url := fmt.Sprintf("/v1/provisioner/volumes/%s", volname)
+	return c.del(url, nil, http.StatusOK, nil)
+}
+
+// ProvisionCapacity returns the capacity available to provision volumes with
+func (c *Client) ProvisionCapacity() (api.ProvisionCapacityResp, error) {
+	var resp api.ProvisionCapacityResp
+	err := c.get("/v1/provisioner/capacity", nil, http.StatusOK, &resp)
+	return resp, err
+}
+
+// Apply brings the volumes of the cluster to the state of the given spec, and
+// returns the operations executed. With dryRun set, only the plan is
+// returned.
+func (c *Client) Apply(spec api.ApplySpec, dryRun bool) (api.ApplyResp, error) {
+	var resp api.ApplyResp
+	url := "/v1/apply"
+	if dryRun {
+		url += "?dry-run=true"
+	}
+	err := c.post(url, spec, http.StatusOK, &resp)
+	return resp, err
+}
@@ -0,0 +1,76 @@
+package bitrot
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path"
+
+	config "github.com/spf13/viper"
+)
+
+const (
+	glusterfsBin = "glusterfs"
+
+	bitdName  = "bitd"
+	scrubName = "scrub"
+)
+
+// bitrotDaemon represents either the bitd daemon, which signs objects on the
+// bricks of this node, or the scrubber daemon, which verifies signatures.
+type bitrotDaemon struct {
+	name       string
+	binarypath string
+}
+
+func newBitrotDaemon(name string) (*bitrotDaemon, error) {
+	path, err := exec.LookPath(glusterfsBin)
+	if err != nil {
+		return nil, err
+	}
+	return &bitrotDaemon{name: name, binarypath: path}, nil
+}
+
+// Name returns human-friendly name of the daemon. This is used for logging.
+func (d *bitrotDaemon) Name() string {
+	return d.name
+}
+
+// Path returns absolute path to the binary of the daemon
+func (d *bitrotDaemon) Path() string {
+	return d.binarypath
+}
+
+// Args returns arguments to be passed to the daemon during spawn.
+func (d *bitrotDaemon) Args() string {
+	logFile := path.Join(config.GetString("logdir"), "glusterfs", d.name+".log")
+
+	var buffer bytes.Buffer
+	buffer.WriteString(fmt.Sprintf(" -f %s", d.VolfilePath()))
+	buffer.WriteString(fmt.Sprintf(" -p %s", d.PidFile()))
+	buffer.WriteString(fmt.Sprintf(" -S %s", d.SocketFile()))
+	buffer.WriteString(fmt.Sprintf(" -l %s", logFile))
+	buffer.WriteString(" --global-timer-wheel")
+	return buffer.String()
+}
+
+// SocketFile returns path to the socket file used for IPC.
+func (d *bitrotDaemon) SocketFile() string {
+	return path.Join(config.GetString("rundir"), "gluster", d.name+".socket")
+}
+
+// PidFile returns path to the pid file of the daemon
+func (d *bitrotDaemon) PidFile() string {
+	return path.Join(config.GetString("rundir"), "gluster", d.name+".pid")
+}
+
+// VolfilePath returns path to the volfile the daemon is started with
+func (d *bitrotDaemon) VolfilePath() string {
+	return path.Join(config.GetString("localstatedir"), d.name, d.name+"-server.vol")
+}
+
+// ID returns the unique identifier of the daemon. There is at most one
+// instance of each daemon on a node.
+func (d *bitrotDaemon) ID() string {
+	return d.name
+}
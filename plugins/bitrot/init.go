@@ -0,0 +1,87 @@
+// Package bitrot implements management of the bitrot detection feature of
+// gluster volumes
+package bitrot
+
+import (
+	"github.com/gluster/glusterd2/servers/rest/route"
+	"github.com/gluster/glusterd2/transaction"
+	"github.com/gluster/glusterd2/volgen"
+
+	"github.com/prashanthpai/sunrpc"
+	"github.com/thejerf/suture"
+)
+
+// Plugin is a structure which implements GlusterdPlugin interface
+type Plugin struct {
+}
+
+// Name returns name of plugin
+func (p *Plugin) Name() string {
+	return "bitrot"
+}
+
+// SunRPCProgram returns sunrpc program to register with Glusterd
+func (p *Plugin) SunRPCProgram() sunrpc.Program {
+	return nil
+}
+
+// RestRoutes returns list of REST API routes to register with Glusterd
+func (p *Plugin) RestRoutes() route.Routes {
+	return route.Routes{
+		route.Route{
+			Name:        "BitrotEnable",
+			Method:      "POST",
+			Pattern:     "/volumes/{volname}/bitrot/enable",
+			Version:     1,
+			HandlerFunc: bitrotEnableHandler},
+		route.Route{
+			Name:        "BitrotDisable",
+			Method:      "POST",
+			Pattern:     "/volumes/{volname}/bitrot/disable",
+			Version:     1,
+			HandlerFunc: bitrotDisableHandler},
+		route.Route{
+			Name:        "BitrotScrubOptions",
+			Method:      "POST",
+			Pattern:     "/volumes/{volname}/bitrot/scrub-options",
+			Version:     1,
+			HandlerFunc: scrubOptionsHandler},
+		route.Route{
+			Name:        "BitrotScrubStatus",
+			Method:      "GET",
+			Pattern:     "/volumes/{volname}/bitrot/scrub-status",
+			Version:     1,
+			HandlerFunc: scrubStatusHandler},
+	}
+}
+
+// RegisterStepFuncs registers transaction step functions with
+// Glusterd Transaction framework
+func (p *Plugin) RegisterStepFuncs() {
+	transaction.RegisterStepFunc(storeVolume, "bitrot.StoreVolume")
+	transaction.RegisterStepFunc(reconcileStep, "bitrot.Reconcile")
+	transaction.RegisterStepFunc(scrubStatus, "bitrot.ScrubStatus")
+}
+
+// BrickXlators returns the xlators to be added to the brick graph. The
+// bitrot-stub xlator is always part of the brick graph, and signs the files
+// of the brick only while bitrot detection is enabled on the volume.
+func (p *Plugin) BrickXlators() []volgen.Xlator {
+	return []volgen.Xlator{
+		{
+			Name:    "bitrot-stub",
+			Type:    "features/bitrot-stub",
+			Options: stubOptions,
+		},
+	}
+}
+
+// ClientXlators returns the xlators to be added to the client graph
+func (p *Plugin) ClientXlators() []volgen.Xlator {
+	return nil
+}
+
+// Services returns the long running services of the plugin
+func (p *Plugin) Services() []suture.Service {
+	return []suture.Service{newManager()}
+}
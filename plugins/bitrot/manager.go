@@ -0,0 +1,143 @@
+package bitrot
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/gluster/glusterd2/brick"
+	"github.com/gluster/glusterd2/daemon"
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/gdctx"
+	"github.com/gluster/glusterd2/utils"
+	"github.com/gluster/glusterd2/volume"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/pborman/uuid"
+)
+
+// reconcileInterval is the interval at which the bitrot daemons are
+// reconciled with the state of the volumes. This picks up volumes being
+// started or stopped after bitrot was enabled on them.
+const reconcileInterval = 30 * time.Second
+
+// reconcileMutex serializes reconciles triggered by the manager and by
+// transactions
+var reconcileMutex sync.Mutex
+
+// manager keeps the bitd and scrubber daemons of this node running as long
+// as there are started volumes with bitrot enabled which have bricks on this
+// node.
+// It provides an implementation of the github.com/thejerf/suture.Service
+// interface.
+type manager struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func newManager() *manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &manager{ctx, cancel}
+}
+
+// Serve begins managing the bitrot daemons
+func (m *manager) Serve() {
+	ticker := time.NewTicker(reconcileInterval)
+	defer ticker.Stop()
+
+	log.Info("started bitrot daemon manager")
+	for {
+		if err := reconcile(); err != nil {
+			log.WithError(err).Warn("failed to reconcile bitrot daemons")
+		}
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop stops managing the bitrot daemons. The daemons are left running.
+func (m *manager) Stop() {
+	m.cancel()
+	log.Info("stopped bitrot daemon manager")
+}
+
+// localBricks returns the bricks of the volume on this node
+func localBricks(v *volume.Volinfo) []brick.Brickinfo {
+	var bricks []brick.Brickinfo
+	for _, b := range v.Bricks {
+		if uuid.Equal(b.NodeID, gdctx.MyUUID) {
+			bricks = append(bricks, b)
+		}
+	}
+	return bricks
+}
+
+// writeVolfile writes the volfile if its contents have changed, and returns
+// true if it did so
+func writeVolfile(file string, content string) (bool, error) {
+	if old, err := ioutil.ReadFile(file); err == nil && bytes.Equal(old, []byte(content)) {
+		return false, nil
+	}
+	if err := utils.InitDir(path.Dir(file)); err != nil {
+		return false, err
+	}
+	return true, ioutil.WriteFile(file, []byte(content), 0600)
+}
+
+// reconcile starts, restarts or stops the bitd and scrubber daemons of this
+// node based on the volumes which have bitrot enabled
+func reconcile() error {
+	reconcileMutex.Lock()
+	defer reconcileMutex.Unlock()
+
+	vols, err := volume.GetVolumes()
+	if err != nil {
+		return err
+	}
+
+	var enabled []*volume.Volinfo
+	bricks := make(map[string][]brick.Brickinfo)
+	for i := range vols {
+		v := &vols[i]
+		if v.Status != volume.VolStarted || !isEnabled(v) {
+			continue
+		}
+		if local := localBricks(v); len(local) > 0 {
+			enabled = append(enabled, v)
+			bricks[v.Name] = local
+		}
+	}
+
+	for _, name := range []string{bitdName, scrubName} {
+		d, err := newBitrotDaemon(name)
+		if err != nil {
+			return err
+		}
+
+		if len(enabled) == 0 {
+			// Stop fails when the daemon isn't running
+			daemon.Stop(d, false)
+			continue
+		}
+
+		changed, err := writeVolfile(d.VolfilePath(), generateVolfile(name, enabled, bricks))
+		if err != nil {
+			return err
+		}
+		if changed {
+			// Restart the daemon for it to pick up the new volfile
+			daemon.Stop(d, false)
+		}
+		if err := daemon.Start(d, false); err != nil && err != errors.ErrProcessAlreadyRunning {
+			return err
+		}
+	}
+
+	return nil
+}
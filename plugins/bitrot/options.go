@@ -0,0 +1,55 @@
+package bitrot
+
+import (
+	"github.com/gluster/glusterd2/volume"
+)
+
+// Volume options used by the bitrot feature
+const (
+	optBitrot        = "features.bitrot"
+	optScrubFreq     = "features.scrub-freq"
+	optScrubThrottle = "features.scrub-throttle"
+)
+
+const (
+	defaultScrubFreq     = "biweekly"
+	defaultScrubThrottle = "lazy"
+)
+
+var (
+	scrubFrequencies = []string{"hourly", "daily", "weekly", "biweekly", "monthly"}
+	scrubThrottles   = []string{"lazy", "normal", "aggressive"}
+)
+
+// isEnabled returns true if bitrot detection is enabled on the volume
+func isEnabled(v *volume.Volinfo) bool {
+	return v.Options[optBitrot] == "on"
+}
+
+func option(v *volume.Volinfo, name string, def string) string {
+	if value, ok := v.Options[name]; ok {
+		return value
+	}
+	return def
+}
+
+func scrubFreq(v *volume.Volinfo) string {
+	return option(v, optScrubFreq, defaultScrubFreq)
+}
+
+func scrubThrottle(v *volume.Volinfo) string {
+	return option(v, optScrubThrottle, defaultScrubThrottle)
+}
+
+// stubOptions returns the options of the bitrot-stub xlator of the bricks of
+// the volume. The brick path is filled in by volgen for every brick.
+func stubOptions(v *volume.Volinfo) map[string]string {
+	bitrot := "disable"
+	if isEnabled(v) {
+		bitrot = "enable"
+	}
+	return map[string]string{
+		"export": "<brick-path>",
+		"bitrot": bitrot,
+	}
+}
@@ -0,0 +1,271 @@
+package bitrot
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/gdctx"
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/transaction"
+	"github.com/gluster/glusterd2/utils"
+	"github.com/gluster/glusterd2/validation"
+	"github.com/gluster/glusterd2/volume"
+
+	"github.com/gorilla/mux"
+	"github.com/pborman/uuid"
+)
+
+const scrubStatusTxnKey = "scrubstatus"
+
+// quarantineDir is the directory on a brick in which the scrubber records
+// the objects it found to be corrupted, named by their gfid. The misspelling
+// matches the directory used by the bit-rot xlator.
+const quarantineDir = ".glusterfs/quanrantine"
+
+// ScrubOptionsReq represents a request to change the scrubber options of a
+// volume
+type ScrubOptionsReq struct {
+	Frequency string `json:"frequency,omitempty"`
+	Throttle  string `json:"throttle,omitempty"`
+}
+
+// BrickScrubStatus represents the objects found to be corrupted on a brick
+type BrickScrubStatus struct {
+	Brick            string    `json:"brick"`
+	NodeID           uuid.UUID `json:"node-id"`
+	CorruptedObjects []string  `json:"corrupted-objects"`
+}
+
+// ScrubStatus represents the bitrot scrub status of a volume
+type ScrubStatus struct {
+	Volume    string             `json:"volume"`
+	Frequency string             `json:"frequency"`
+	Throttle  string             `json:"throttle"`
+	Bricks    []BrickScrubStatus `json:"bricks"`
+}
+
+func storeVolume(c transaction.TxnCtx) error {
+	var volinfo volume.Volinfo
+	if err := c.Get("volinfo", &volinfo); err != nil {
+		return err
+	}
+	return volume.AddOrUpdateVolume(&volinfo)
+}
+
+func reconcileStep(c transaction.TxnCtx) error {
+	if err := reconcile(); err != nil {
+		c.Logger().WithError(err).Error("failed to reconcile bitrot daemons")
+		return err
+	}
+	return nil
+}
+
+func scrubStatus(c transaction.TxnCtx) error {
+	var volname string
+	if err := c.Get("volname", &volname); err != nil {
+		return err
+	}
+
+	v, err := volume.GetVolume(volname)
+	if err != nil {
+		return err
+	}
+
+	var result []BrickScrubStatus
+	for _, b := range localBricks(v) {
+		s := BrickScrubStatus{
+			Brick:            b.Hostname + ":" + b.Path,
+			NodeID:           b.NodeID,
+			CorruptedObjects: []string{},
+		}
+		entries, err := ioutil.ReadDir(path.Join(b.Path, quarantineDir))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		for _, e := range entries {
+			s.CorruptedObjects = append(s.CorruptedObjects, e.Name())
+		}
+		result = append(result, s)
+	}
+
+	return c.SetNodeResult(gdctx.MyUUID, scrubStatusTxnKey, result)
+}
+
+// setVolumeOptions stores the given options of the volume and reconciles the
+// bitrot daemons on all nodes of the volume
+func setVolumeOptions(w http.ResponseWriter, r *http.Request, volinfo *volume.Volinfo, options map[string]string) {
+	reqID, logger := restutils.GetReqIDandLogger(r)
+
+	for k, v := range options {
+		volinfo.Options[k] = v
+	}
+
+	lock, unlock, err := transaction.CreateLockSteps(volinfo.Name)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	txn := transaction.NewTxn(reqID)
+	defer txn.Cleanup()
+
+	txn.Nodes = volinfo.Nodes()
+	txn.Steps = []*transaction.Step{
+		lock,
+		{
+			DoFunc: "bitrot.StoreVolume",
+			Nodes:  []uuid.UUID{gdctx.MyUUID},
+		},
+		// The bitrot-stub xlator of the bricks signs files only while
+		// bitrot detection is enabled, so the brick volfiles are
+		// regenerated and the running bricks notified to fetch them
+		{
+			DoFunc: "vol-option.RegenerateVolfiles",
+			Nodes:  txn.Nodes,
+		},
+		{
+			DoFunc: "vol-option.NotifyVolfileChange",
+			Nodes:  txn.Nodes,
+		},
+		{
+			DoFunc: "bitrot.Reconcile",
+			Nodes:  txn.Nodes,
+		},
+		unlock,
+	}
+
+	if err := txn.Ctx.Set("volinfo", volinfo); err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if _, err := txn.Do(); err != nil {
+		logger.WithError(err).WithField("volume", volinfo.Name).Error("failed to set bitrot options")
+		if err == transaction.ErrLockTimeout {
+			restutils.SendHTTPError(w, http.StatusConflict, err.Error())
+		} else if transaction.IsTimeout(err) {
+			restutils.SendHTTPError(w, http.StatusGatewayTimeout, err.Error())
+		} else {
+			restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	restutils.SendHTTPResponse(w, http.StatusOK, volinfo.Options)
+}
+
+func bitrotEnableHandler(w http.ResponseWriter, r *http.Request) {
+	volinfo, err := volume.GetVolume(mux.Vars(r)["volname"])
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusNotFound, errors.ErrVolNotFound.Error())
+		return
+	}
+	setVolumeOptions(w, r, volinfo, map[string]string{optBitrot: "on"})
+}
+
+func bitrotDisableHandler(w http.ResponseWriter, r *http.Request) {
+	volinfo, err := volume.GetVolume(mux.Vars(r)["volname"])
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusNotFound, errors.ErrVolNotFound.Error())
+		return
+	}
+	setVolumeOptions(w, r, volinfo, map[string]string{optBitrot: "off"})
+}
+
+func scrubOptionsHandler(w http.ResponseWriter, r *http.Request) {
+	volinfo, err := volume.GetVolume(mux.Vars(r)["volname"])
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusNotFound, errors.ErrVolNotFound.Error())
+		return
+	}
+
+	var req ScrubOptionsReq
+	if err := utils.GetJSONFromRequest(r, &req); err != nil {
+		restutils.SendHTTPError(w, http.StatusUnprocessableEntity, errors.ErrJSONParsingFailed.Error())
+		return
+	}
+
+	var errs validation.Errors
+	options := make(map[string]string)
+	if req.Frequency != "" {
+		errs.OneOf("frequency", req.Frequency, scrubFrequencies...)
+		options[optScrubFreq] = req.Frequency
+	}
+	if req.Throttle != "" {
+		errs.OneOf("throttle", req.Throttle, scrubThrottles...)
+		options[optScrubThrottle] = req.Throttle
+	}
+	if len(options) == 0 {
+		errs.Add("frequency", "either frequency or throttle is required")
+	}
+	if errs != nil {
+		restutils.SendValidationErrors(w, errs)
+		return
+	}
+
+	if !isEnabled(volinfo) {
+		restutils.SendHTTPError(w, http.StatusBadRequest, errors.ErrBitrotNotEnabled.Error())
+		return
+	}
+
+	setVolumeOptions(w, r, volinfo, options)
+}
+
+func scrubStatusHandler(w http.ResponseWriter, r *http.Request) {
+	reqID, logger := restutils.GetReqIDandLogger(r)
+
+	volinfo, err := volume.GetVolume(mux.Vars(r)["volname"])
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusNotFound, errors.ErrVolNotFound.Error())
+		return
+	}
+
+	if !isEnabled(volinfo) {
+		restutils.SendHTTPError(w, http.StatusBadRequest, errors.ErrBitrotNotEnabled.Error())
+		return
+	}
+
+	// Reading the scrub status does not modify any state, so no locks
+	// are needed.
+	txn := transaction.NewTxn(reqID)
+	defer txn.Cleanup()
+
+	txn.Nodes = volinfo.Nodes()
+	txn.Steps = []*transaction.Step{
+		{
+			DoFunc: "bitrot.ScrubStatus",
+			Nodes:  txn.Nodes,
+		},
+	}
+	if err := txn.Ctx.Set("volname", volinfo.Name); err != nil {
+		logger.WithError(err).Error("failed to set volume name in transaction context")
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	rtxn, err := txn.Do()
+	if err != nil {
+		logger.WithError(err).WithField("volume", volinfo.Name).Error("failed to get scrub status")
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	status := ScrubStatus{
+		Volume:    volinfo.Name,
+		Frequency: scrubFreq(volinfo),
+		Throttle:  scrubThrottle(volinfo),
+	}
+	for _, node := range txn.Nodes {
+		var tmp []BrickScrubStatus
+		if err := rtxn.GetNodeResult(node, scrubStatusTxnKey, &tmp); err != nil {
+			restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		status.Bricks = append(status.Bricks, tmp...)
+	}
+
+	restutils.SendHTTPResponse(w, http.StatusOK, status)
+}
@@ -0,0 +1,73 @@
+package bitrot
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/gluster/glusterd2/brick"
+	"github.com/gluster/glusterd2/volume"
+)
+
+const clientTemplate = `
+volume <volume-name>-client-<child-index>
+    type protocol/client
+    option password <trusted-password>
+    option username <trusted-username>
+    option transport.address-family inet
+    option transport-type tcp
+    option remote-subvolume <brick-path>
+    option remote-host <remote-host>
+    option ping-timeout 42
+end-volume
+`
+
+const bitrotTemplate = `
+volume <volume-name>-bit-rot-0
+    type features/bit-rot
+    option scrubber <scrubber>
+    option scrub-freq <scrub-freq>
+    option scrub-throttle <scrub-throttle>
+    option expiry-time 120
+    subvolumes <subvolumes>
+end-volume
+`
+
+// generateVolfile returns the volfile for the bitd or scrubber daemon,
+// covering the given local bricks of each volume
+func generateVolfile(name string, vols []*volume.Volinfo, bricks map[string][]brick.Brickinfo) string {
+	scrubber := "off"
+	if name == scrubName {
+		scrubber = "on"
+	}
+
+	volfile := new(bytes.Buffer)
+	var tops []string
+	for _, v := range vols {
+		var subvols []string
+		for i, b := range bricks[v.Name] {
+			replacer := strings.NewReplacer(
+				"<volume-name>", v.Name,
+				"<child-index>", fmt.Sprint(i),
+				"<trusted-username>", v.Auth.Username,
+				"<trusted-password>", v.Auth.Password,
+				"<brick-path>", b.Path,
+				"<remote-host>", "127.0.0.1")
+			volfile.WriteString(replacer.Replace(clientTemplate))
+			subvols = append(subvols, fmt.Sprintf("%s-client-%d", v.Name, i))
+		}
+
+		replacer := strings.NewReplacer(
+			"<volume-name>", v.Name,
+			"<scrubber>", scrubber,
+			"<scrub-freq>", scrubFreq(v),
+			"<scrub-throttle>", scrubThrottle(v),
+			"<subvolumes>", strings.Join(subvols, " "))
+		volfile.WriteString(replacer.Replace(bitrotTemplate))
+		tops = append(tops, v.Name+"-bit-rot-0")
+	}
+
+	fmt.Fprintf(volfile, "\nvolume %s\n    type debug/io-stats\n    subvolumes %s\nend-volume\n",
+		name, strings.Join(tops, " "))
+	return volfile.String()
+}
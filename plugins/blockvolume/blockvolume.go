@@ -0,0 +1,175 @@
+package blockvolume
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/store"
+
+	"github.com/coreos/etcd/clientv3"
+)
+
+const (
+	blockVolumePrefix = store.GlusterPrefix + "blockvolumes/"
+
+	// glusterBlockBin is the CLI of gluster-block. It creates the file
+	// backing a block volume on the hosting volume, and configures the
+	// tcmu-runner iSCSI targets on the given hosts through the
+	// gluster-blockd daemons running on them.
+	glusterBlockBin = "gluster-block"
+)
+
+// BlockVolume is a block device backed by a file on a hosting gluster volume,
+// exported as an iSCSI target from a set of nodes
+type BlockVolume struct {
+	Name          string `json:"name"`
+	HostingVolume string `json:"hosting-volume"`
+	// Size is the size of the block device, in bytes
+	Size uint64 `json:"size"`
+	// HaCount is the number of hosts the target is exported from
+	HaCount int      `json:"ha-count"`
+	Hosts   []string `json:"hosts"`
+	IQN     string   `json:"iqn,omitempty"`
+	Portals []string `json:"portals,omitempty"`
+}
+
+func blockVolumeKey(hostvol, name string) string {
+	return blockVolumePrefix + hostvol + "/" + name
+}
+
+// GetBlockVolume returns the block volume with the given name on the hosting
+// volume
+func GetBlockVolume(hostvol, name string) (*BlockVolume, error) {
+	resp, err := store.Store.Get(context.TODO(), blockVolumeKey(hostvol, name))
+	if err != nil {
+		return nil, err
+	}
+	if resp.Count != 1 {
+		return nil, errors.ErrBlockVolNotFound
+	}
+
+	var bv BlockVolume
+	if err := json.Unmarshal(resp.Kvs[0].Value, &bv); err != nil {
+		return nil, err
+	}
+	return &bv, nil
+}
+
+// GetBlockVolumes returns the block volumes on the hosting volume, or on all
+// volumes if hostvol is empty, sorted by hosting volume and name
+func GetBlockVolumes(hostvol string) ([]*BlockVolume, error) {
+	prefix := blockVolumePrefix
+	if hostvol != "" {
+		prefix += hostvol + "/"
+	}
+	resp, err := store.Store.Get(context.TODO(), prefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+
+	bvs := make([]*BlockVolume, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		var bv BlockVolume
+		if err := json.Unmarshal(kv.Value, &bv); err != nil {
+			return nil, err
+		}
+		bvs = append(bvs, &bv)
+	}
+
+	sort.Sort(blockVolumesByName(bvs))
+	return bvs, nil
+}
+
+// AddOrUpdateBlockVolume saves the block volume in the store
+func AddOrUpdateBlockVolume(bv *BlockVolume) error {
+	data, err := json.Marshal(bv)
+	if err != nil {
+		return err
+	}
+	_, err = store.Store.Put(context.TODO(), blockVolumeKey(bv.HostingVolume, bv.Name), string(data))
+	return err
+}
+
+// DeleteBlockVolume deletes the block volume from the store
+func DeleteBlockVolume(hostvol, name string) error {
+	_, err := store.Store.Delete(context.TODO(), blockVolumeKey(hostvol, name))
+	return err
+}
+
+type blockVolumesByName []*BlockVolume
+
+func (b blockVolumesByName) Len() int      { return len(b) }
+func (b blockVolumesByName) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
+func (b blockVolumesByName) Less(i, j int) bool {
+	if b[i].HostingVolume != b[j].HostingVolume {
+		return b[i].HostingVolume < b[j].HostingVolume
+	}
+	return b[i].Name < b[j].Name
+}
+
+// glusterBlockResult is the JSON output of the gluster-block CLI
+type glusterBlockResult struct {
+	IQN     string   `json:"IQN"`
+	Portals []string `json:"PORTAL(S)"`
+	Result  string   `json:"RESULT"`
+	ErrCode int      `json:"errCode"`
+	ErrMsg  string   `json:"errMsg"`
+}
+
+// glusterBlock runs the gluster-block CLI with JSON output and returns its
+// result
+func glusterBlock(args ...string) (*glusterBlockResult, error) {
+	path, err := exec.LookPath(glusterBlockBin)
+	if err != nil {
+		return nil, err
+	}
+
+	args = append(args, "--json")
+	out, cmdErr := exec.Command(path, args...).Output()
+
+	var result glusterBlockResult
+	if err := json.Unmarshal(out, &result); err != nil {
+		if cmdErr != nil {
+			return nil, fmt.Errorf("%s %s failed: %s", glusterBlockBin, args[0], cmdErr.Error())
+		}
+		return nil, err
+	}
+	if cmdErr != nil || result.ErrCode != 0 || result.Result == "FAIL" {
+		return nil, fmt.Errorf("%s %s failed: %s", glusterBlockBin, args[0], result.ErrMsg)
+	}
+	return &result, nil
+}
+
+// create creates the block volume, and sets its IQN and portals
+func create(bv *BlockVolume) error {
+	result, err := glusterBlock("create", bv.HostingVolume+"/"+bv.Name,
+		"ha", strconv.Itoa(bv.HaCount),
+		strings.Join(bv.Hosts, ","),
+		strconv.FormatUint(bv.Size, 10))
+	if err != nil {
+		return err
+	}
+	bv.IQN = result.IQN
+	bv.Portals = result.Portals
+	return nil
+}
+
+// remove deletes the block volume, its backing file and its targets
+func remove(bv *BlockVolume) error {
+	_, err := glusterBlock("delete", bv.HostingVolume+"/"+bv.Name)
+	return err
+}
+
+// resize changes the size of the block volume. Block volumes can only be
+// grown.
+func resize(bv *BlockVolume, size uint64) error {
+	_, err := glusterBlock("modify", bv.HostingVolume+"/"+bv.Name,
+		"size", strconv.FormatUint(size, 10))
+	return err
+}
@@ -0,0 +1,87 @@
+// Package blockvolume implements management of gluster-block block volumes,
+// which are files on a hosting gluster volume exported as iSCSI targets
+package blockvolume
+
+import (
+	"github.com/gluster/glusterd2/servers/rest/route"
+	"github.com/gluster/glusterd2/transaction"
+	"github.com/gluster/glusterd2/volgen"
+
+	"github.com/prashanthpai/sunrpc"
+	"github.com/thejerf/suture"
+)
+
+// Plugin is a structure which implements GlusterdPlugin interface
+type Plugin struct {
+}
+
+// Name returns name of plugin
+func (p *Plugin) Name() string {
+	return "blockvolume"
+}
+
+// SunRPCProgram returns sunrpc program to register with Glusterd
+func (p *Plugin) SunRPCProgram() sunrpc.Program {
+	return nil
+}
+
+// RestRoutes returns list of REST API routes to register with Glusterd
+func (p *Plugin) RestRoutes() route.Routes {
+	return route.Routes{
+		route.Route{
+			Name:        "BlockVolumeCreate",
+			Method:      "POST",
+			Pattern:     "/blockvolumes",
+			Version:     1,
+			HandlerFunc: createHandler},
+		route.Route{
+			Name:        "BlockVolumeList",
+			Method:      "GET",
+			Pattern:     "/blockvolumes",
+			Version:     1,
+			HandlerFunc: listHandler},
+		route.Route{
+			Name:        "BlockVolumeInfo",
+			Method:      "GET",
+			Pattern:     "/blockvolumes/{volname}/{blockname}",
+			Version:     1,
+			HandlerFunc: getHandler},
+		route.Route{
+			Name:        "BlockVolumeDelete",
+			Method:      "DELETE",
+			Pattern:     "/blockvolumes/{volname}/{blockname}",
+			Version:     1,
+			HandlerFunc: deleteHandler},
+		route.Route{
+			Name:        "BlockVolumeResize",
+			Method:      "POST",
+			Pattern:     "/blockvolumes/{volname}/{blockname}/resize",
+			Version:     1,
+			HandlerFunc: resizeHandler},
+	}
+}
+
+// RegisterStepFuncs registers transaction step functions with
+// Glusterd Transaction framework
+func (p *Plugin) RegisterStepFuncs() {
+	transaction.RegisterStepFunc(createStep, "blockvolume.Create")
+	transaction.RegisterStepFunc(deleteStep, "blockvolume.Delete")
+	transaction.RegisterStepFunc(resizeStep, "blockvolume.Resize")
+	transaction.RegisterStepFunc(storeStep, "blockvolume.Store")
+	transaction.RegisterStepFunc(unstoreStep, "blockvolume.Unstore")
+}
+
+// BrickXlators returns the xlators to be added to the brick graph
+func (p *Plugin) BrickXlators() []volgen.Xlator {
+	return nil
+}
+
+// ClientXlators returns the xlators to be added to the client graph
+func (p *Plugin) ClientXlators() []volgen.Xlator {
+	return nil
+}
+
+// Services returns the long running services of the plugin
+func (p *Plugin) Services() []suture.Service {
+	return nil
+}
@@ -0,0 +1,359 @@
+package blockvolume
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/gdctx"
+	"github.com/gluster/glusterd2/peer"
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/transaction"
+	"github.com/gluster/glusterd2/utils"
+	"github.com/gluster/glusterd2/validation"
+	"github.com/gluster/glusterd2/volume"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/gorilla/mux"
+	"github.com/pborman/uuid"
+)
+
+// CreateReq represents a request to create a block volume
+type CreateReq struct {
+	Name          string `json:"name"`
+	HostingVolume string `json:"hosting-volume"`
+	Size          uint64 `json:"size"`
+	// Hosts are the addresses of the peers the iSCSI target is exported
+	// from. They default to the peers with bricks of the hosting volume.
+	Hosts []string `json:"hosts,omitempty"`
+	// HaCount defaults to the number of hosts
+	HaCount int `json:"ha-count,omitempty"`
+}
+
+// ResizeReq represents a request to change the size of a block volume
+type ResizeReq struct {
+	Size uint64 `json:"size"`
+}
+
+func createStep(c transaction.TxnCtx) error {
+	var bv BlockVolume
+	if err := c.Get("blockvolume", &bv); err != nil {
+		return err
+	}
+
+	c.Logger().WithFields(log.Fields{
+		"blockvolume":    bv.Name,
+		"hosting-volume": bv.HostingVolume,
+		"hosts":          bv.Hosts,
+	}).Info("creating block volume")
+
+	if err := create(&bv); err != nil {
+		return err
+	}
+	// The IQN and portals are only known once the block volume is created
+	return c.Set("blockvolume", &bv)
+}
+
+func deleteStep(c transaction.TxnCtx) error {
+	var bv BlockVolume
+	if err := c.Get("blockvolume", &bv); err != nil {
+		return err
+	}
+
+	c.Logger().WithFields(log.Fields{
+		"blockvolume":    bv.Name,
+		"hosting-volume": bv.HostingVolume,
+	}).Info("deleting block volume")
+
+	return remove(&bv)
+}
+
+func resizeStep(c transaction.TxnCtx) error {
+	var bv BlockVolume
+	if err := c.Get("blockvolume", &bv); err != nil {
+		return err
+	}
+	var size uint64
+	if err := c.Get("size", &size); err != nil {
+		return err
+	}
+
+	if err := resize(&bv, size); err != nil {
+		return err
+	}
+	bv.Size = size
+	return c.Set("blockvolume", &bv)
+}
+
+func storeStep(c transaction.TxnCtx) error {
+	var bv BlockVolume
+	if err := c.Get("blockvolume", &bv); err != nil {
+		return err
+	}
+	return AddOrUpdateBlockVolume(&bv)
+}
+
+func unstoreStep(c transaction.TxnCtx) error {
+	var bv BlockVolume
+	if err := c.Get("blockvolume", &bv); err != nil {
+		return err
+	}
+	return DeleteBlockVolume(bv.HostingVolume, bv.Name)
+}
+
+// volumeHosts returns the address of each peer with bricks of the volume
+func volumeHosts(v *volume.Volinfo) ([]string, error) {
+	var hosts []string
+	for _, id := range v.Nodes() {
+		p, err := peer.GetPeerF(id.String())
+		if err != nil {
+			return nil, err
+		}
+		if len(p.Addresses) == 0 {
+			return nil, fmt.Errorf("peer %s has no addresses", p.ID)
+		}
+		hosts = append(hosts, p.Addresses[0])
+	}
+	return hosts, nil
+}
+
+// runBlockVolumeTxn runs the steps of a block volume operation with the
+// hosting volume locked. The gluster-block CLI is run on this node only, as it
+// configures the targets on the other hosts itself. The block volume as
+// stored is returned.
+func runBlockVolumeTxn(r *http.Request, bv *BlockVolume, steps []*transaction.Step, extra map[string]interface{}) (*BlockVolume, error) {
+	reqID, _ := restutils.GetReqIDandLogger(r)
+
+	lock, unlock, err := transaction.CreateLockSteps(bv.HostingVolume)
+	if err != nil {
+		return nil, err
+	}
+
+	txn := transaction.NewTxn(reqID)
+	defer txn.Cleanup()
+
+	txn.Nodes = []uuid.UUID{gdctx.MyUUID}
+	txn.Steps = append([]*transaction.Step{lock}, steps...)
+	txn.Steps = append(txn.Steps, unlock)
+
+	if err := txn.Ctx.Set("blockvolume", bv); err != nil {
+		return nil, err
+	}
+	for k, v := range extra {
+		if err := txn.Ctx.Set(k, v); err != nil {
+			return nil, err
+		}
+	}
+
+	rtxn, err := txn.Do()
+	if err != nil {
+		return nil, err
+	}
+
+	var result BlockVolume
+	if err := rtxn.Get("blockvolume", &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func sendTxnError(w http.ResponseWriter, err error) {
+	if err == transaction.ErrLockTimeout {
+		restutils.SendHTTPError(w, http.StatusConflict, err.Error())
+	} else if transaction.IsTimeout(err) {
+		restutils.SendHTTPError(w, http.StatusGatewayTimeout, err.Error())
+	} else {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+func createHandler(w http.ResponseWriter, r *http.Request) {
+	_, logger := restutils.GetReqIDandLogger(r)
+
+	var req CreateReq
+	if err := utils.GetJSONFromRequest(r, &req); err != nil {
+		restutils.SendHTTPError(w, http.StatusUnprocessableEntity, errors.ErrJSONParsingFailed.Error())
+		return
+	}
+
+	var errs validation.Errors
+	errs.Name("name", req.Name)
+	errs.RequireString("hosting-volume", req.HostingVolume)
+	if req.Size == 0 {
+		errs.Add("size", "size must be greater than 0")
+	}
+	if req.HaCount < 0 {
+		errs.Add("ha-count", "ha-count cannot be negative")
+	}
+	if errs != nil {
+		restutils.SendValidationErrors(w, errs)
+		return
+	}
+
+	hostvol, err := volume.GetVolume(req.HostingVolume)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusNotFound, errors.ErrVolNotFound.Error())
+		return
+	}
+	if hostvol.Status != volume.VolStarted {
+		restutils.SendHTTPError(w, http.StatusBadRequest, errors.ErrVolNotStarted.Error())
+		return
+	}
+
+	if _, err := GetBlockVolume(req.HostingVolume, req.Name); err == nil {
+		restutils.SendHTTPError(w, http.StatusConflict, errors.ErrBlockVolExists.Error())
+		return
+	}
+
+	hosts := req.Hosts
+	if len(hosts) == 0 {
+		if hosts, err = volumeHosts(hostvol); err != nil {
+			restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	for _, h := range hosts {
+		if _, err := peer.GetPeerByAddr(h); err != nil {
+			restutils.SendHTTPError(w, http.StatusBadRequest, fmt.Sprintf("%s: %s", errors.ErrPeerNotFound.Error(), h))
+			return
+		}
+	}
+
+	haCount := req.HaCount
+	if haCount == 0 {
+		haCount = len(hosts)
+	}
+	if haCount > len(hosts) {
+		restutils.SendHTTPError(w, http.StatusBadRequest,
+			fmt.Sprintf("ha-count %d is more than the %d hosts given", haCount, len(hosts)))
+		return
+	}
+
+	bv := &BlockVolume{
+		Name:          req.Name,
+		HostingVolume: req.HostingVolume,
+		Size:          req.Size,
+		HaCount:       haCount,
+		Hosts:         hosts,
+	}
+
+	bv, err = runBlockVolumeTxn(r, bv, []*transaction.Step{
+		{
+			DoFunc:   "blockvolume.Create",
+			UndoFunc: "blockvolume.Delete",
+			Nodes:    []uuid.UUID{gdctx.MyUUID},
+		},
+		{
+			DoFunc: "blockvolume.Store",
+			Nodes:  []uuid.UUID{gdctx.MyUUID},
+		},
+	}, nil)
+	if err != nil {
+		logger.WithError(err).WithField("blockvolume", req.Name).Error("block volume create transaction failed")
+		sendTxnError(w, err)
+		return
+	}
+
+	restutils.SendHTTPResponse(w, http.StatusCreated, bv)
+}
+
+func listHandler(w http.ResponseWriter, r *http.Request) {
+	bvs, err := GetBlockVolumes(r.URL.Query().Get("hosting-volume"))
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	restutils.SendHTTPResponse(w, http.StatusOK, bvs)
+}
+
+func getHandler(w http.ResponseWriter, r *http.Request) {
+	p := mux.Vars(r)
+	bv, err := GetBlockVolume(p["volname"], p["blockname"])
+	if err == errors.ErrBlockVolNotFound {
+		restutils.SendHTTPError(w, http.StatusNotFound, err.Error())
+		return
+	} else if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	restutils.SendHTTPResponse(w, http.StatusOK, bv)
+}
+
+func deleteHandler(w http.ResponseWriter, r *http.Request) {
+	p := mux.Vars(r)
+	_, logger := restutils.GetReqIDandLogger(r)
+
+	bv, err := GetBlockVolume(p["volname"], p["blockname"])
+	if err == errors.ErrBlockVolNotFound {
+		restutils.SendHTTPError(w, http.StatusNotFound, err.Error())
+		return
+	} else if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	_, err = runBlockVolumeTxn(r, bv, []*transaction.Step{
+		{
+			DoFunc: "blockvolume.Delete",
+			Nodes:  []uuid.UUID{gdctx.MyUUID},
+		},
+		{
+			DoFunc: "blockvolume.Unstore",
+			Nodes:  []uuid.UUID{gdctx.MyUUID},
+		},
+	}, nil)
+	if err != nil {
+		logger.WithError(err).WithField("blockvolume", bv.Name).Error("block volume delete transaction failed")
+		sendTxnError(w, err)
+		return
+	}
+
+	restutils.SendHTTPResponse(w, http.StatusOK, nil)
+}
+
+func resizeHandler(w http.ResponseWriter, r *http.Request) {
+	p := mux.Vars(r)
+	_, logger := restutils.GetReqIDandLogger(r)
+
+	var req ResizeReq
+	if err := utils.GetJSONFromRequest(r, &req); err != nil {
+		restutils.SendHTTPError(w, http.StatusUnprocessableEntity, errors.ErrJSONParsingFailed.Error())
+		return
+	}
+
+	bv, err := GetBlockVolume(p["volname"], p["blockname"])
+	if err == errors.ErrBlockVolNotFound {
+		restutils.SendHTTPError(w, http.StatusNotFound, err.Error())
+		return
+	} else if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if req.Size < bv.Size {
+		restutils.SendHTTPError(w, http.StatusBadRequest, errors.ErrBlockVolShrink.Error())
+		return
+	}
+	if req.Size == bv.Size {
+		restutils.SendHTTPResponse(w, http.StatusOK, bv)
+		return
+	}
+
+	bv, err = runBlockVolumeTxn(r, bv, []*transaction.Step{
+		{
+			DoFunc: "blockvolume.Resize",
+			Nodes:  []uuid.UUID{gdctx.MyUUID},
+		},
+		{
+			DoFunc: "blockvolume.Store",
+			Nodes:  []uuid.UUID{gdctx.MyUUID},
+		},
+	}, map[string]interface{}{"size": req.Size})
+	if err != nil {
+		logger.WithError(err).WithField("blockvolume", p["blockname"]).Error("block volume resize transaction failed")
+		sendTxnError(w, err)
+		return
+	}
+
+	restutils.SendHTTPResponse(w, http.StatusOK, bv)
+}
@@ -2,15 +2,43 @@ package plugins
 
 import (
 	"github.com/gluster/glusterd2/servers/rest/route"
+	"github.com/gluster/glusterd2/volgen"
+
 	"github.com/prashanthpai/sunrpc"
+	"github.com/thejerf/suture"
 )
 
 // GlusterdPlugin is an interface that every Glusterd plugin will
-// implement to add sunrpc program, REST routes and Transaction step
-// functions
+// implement to add sunrpc program, REST routes, Transaction step
+// functions, volfile xlators and services
 type GlusterdPlugin interface {
 	Name() string
 	SunRPCProgram() sunrpc.Program
 	RestRoutes() route.Routes
 	RegisterStepFuncs()
+	// BrickXlators returns the xlators to be added to the brick graph
+	BrickXlators() []volgen.Xlator
+	// ClientXlators returns the xlators to be added to the client graph
+	ClientXlators() []volgen.Xlator
+	// Services returns long running services of the plugin, such as a
+	// manager for the daemons of a feature. They are added to the
+	// GlusterD supervisor.
+	Services() []suture.Service
+}
+
+// RegisterXlators registers the volfile xlators of all plugins with volgen
+func RegisterXlators() {
+	for _, p := range PluginsList {
+		volgen.RegisterBrickXlators(p.BrickXlators()...)
+		volgen.RegisterClientXlators(p.ClientXlators()...)
+	}
+}
+
+// AddServices adds the services of all plugins to the supervisor
+func AddServices(super *suture.Supervisor) {
+	for _, p := range PluginsList {
+		for _, s := range p.Services() {
+			super.Add(s)
+		}
+	}
 }
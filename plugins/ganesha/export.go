@@ -0,0 +1,217 @@
+package ganesha
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/gluster/glusterd2/daemon"
+	"github.com/gluster/glusterd2/gdctx"
+	"github.com/gluster/glusterd2/utils"
+	"github.com/gluster/glusterd2/volume"
+
+	config "github.com/spf13/viper"
+)
+
+// Volume options used to record the NFS export of a volume
+const (
+	optEnable   = "ganesha.enable"
+	optExportID = "ganesha.export-id"
+)
+
+const (
+	defaultConfDir = "/etc/ganesha"
+	ganeshaPidFile = "/var/run/ganesha.pid"
+	ganeshaService = "nfs-ganesha"
+
+	// firstExportID is the export ID given to the first exported volume.
+	// Export ID 1 is commonly used by the default export of ganesha.
+	firstExportID = 2
+)
+
+const exportTemplate = `EXPORT {
+    Export_Id = <export-id>;
+    Path = "/<volume-name>";
+    Pseudo = "/<volume-name>";
+    Access_Type = RW;
+    Squash = No_root_squash;
+    Disable_ACL = true;
+    Protocols = "3", "4";
+    Transports = "UDP", "TCP";
+    SecType = "sys";
+    FSAL {
+        Name = GLUSTER;
+        Hostname = "localhost";
+        Volume = "<volume-name>";
+    }
+}
+`
+
+func isExported(v *volume.Volinfo) bool {
+	return v.Options[optEnable] == "on"
+}
+
+// confDir returns the configuration directory of ganesha, which can be
+// overridden with the ganesha-confdir config option
+func confDir() string {
+	if dir := config.GetString("ganesha-confdir"); dir != "" {
+		return dir
+	}
+	return defaultConfDir
+}
+
+func exportFile(volname string) string {
+	return path.Join(confDir(), "exports", "export."+volname+".conf")
+}
+
+func includeLine(volname string) string {
+	return fmt.Sprintf("%%include \"%s\"", exportFile(volname))
+}
+
+// nextExportID returns an export ID not used by any exported volume
+func nextExportID() (int, error) {
+	vols, err := volume.GetVolumes()
+	if err != nil {
+		return 0, err
+	}
+	return unusedExportID(vols), nil
+}
+
+// unusedExportID returns an export ID higher than the export IDs of all the
+// given volumes
+func unusedExportID(vols []volume.Volinfo) int {
+	id := firstExportID
+	for _, v := range vols {
+		if n, err := strconv.Atoi(v.Options[optExportID]); err == nil && n >= id {
+			id = n + 1
+		}
+	}
+	return id
+}
+
+// exportConfig returns the ganesha export config of the volume
+func exportConfig(v *volume.Volinfo) string {
+	replacer := strings.NewReplacer(
+		"<export-id>", v.Options[optExportID],
+		"<volume-name>", v.Name)
+	return replacer.Replace(exportTemplate)
+}
+
+// updateIncludes adds or removes the include of the export file of the
+// volume in ganesha.conf
+func updateIncludes(volname string, include bool) error {
+	file := path.Join(confDir(), "ganesha.conf")
+	line := includeLine(volname)
+
+	content, err := ioutil.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	var out bytes.Buffer
+	found := false
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) == line {
+			found = true
+			if !include {
+				continue
+			}
+		}
+		out.WriteString(scanner.Text() + "\n")
+	}
+	if include && !found {
+		out.WriteString(line + "\n")
+	}
+	return ioutil.WriteFile(file, out.Bytes(), 0644)
+}
+
+// isRunning returns the pid of ganesha if it is running on this node
+func isRunning() (int, bool) {
+	pid, err := daemon.ReadPidFromFile(ganeshaPidFile)
+	if err != nil {
+		return 0, false
+	}
+	if _, err := daemon.GetProcess(pid); err != nil {
+		return pid, false
+	}
+	return pid, true
+}
+
+func systemctl(action string) error {
+	if out, err := exec.Command("systemctl", action, ganeshaService).CombinedOutput(); err != nil {
+		return fmt.Errorf("systemctl %s %s failed: %s", action, ganeshaService, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// dbusExportMgr invokes a method of the export manager of a running ganesha
+func dbusExportMgr(method string, args ...string) error {
+	cmdArgs := append([]string{"--system", "--print-reply", "--dest=org.ganesha.nfsd",
+		"/org/ganesha/nfsd/ExportMgr", "org.ganesha.nfsd.exportmgr." + method}, args...)
+	if out, err := exec.Command("dbus-send", cmdArgs...).CombinedOutput(); err != nil {
+		return fmt.Errorf("%s of ganesha export failed: %s", method, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// export writes the export config of the volume and exports it from the
+// ganesha on this node, starting ganesha if it isn't running
+func export(v *volume.Volinfo) error {
+	file := exportFile(v.Name)
+	if err := utils.InitDir(path.Dir(file)); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(file, []byte(exportConfig(v)), 0644); err != nil {
+		return err
+	}
+	if err := updateIncludes(v.Name, true); err != nil {
+		return err
+	}
+
+	// A newly started ganesha reads all exports from its config
+	if _, running := isRunning(); !running {
+		return systemctl("start")
+	}
+	return dbusExportMgr("AddExport", "string:"+file,
+		fmt.Sprintf("string:EXPORT(Export_Id=%s)", v.Options[optExportID]))
+}
+
+// unexport removes the export of the volume from the ganesha on this node
+// and deletes its export config
+func unexport(v *volume.Volinfo) error {
+	if _, running := isRunning(); running {
+		if err := dbusExportMgr("RemoveExport", "uint16:"+v.Options[optExportID]); err != nil {
+			return err
+		}
+	}
+
+	if err := updateIncludes(v.Name, false); err != nil {
+		return err
+	}
+	if err := os.Remove(exportFile(v.Name)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// nfsStatus reports the status of ganesha on this node for volumes which
+// are exported
+func nfsStatus(v *volume.Volinfo) (volume.ServiceStatus, bool) {
+	if !isExported(v) {
+		return volume.ServiceStatus{}, false
+	}
+	pid, running := isRunning()
+	return volume.ServiceStatus{
+		Name:   ganeshaService,
+		NodeID: gdctx.MyUUID,
+		Online: running,
+		Pid:    pid,
+	}, true
+}
@@ -0,0 +1,71 @@
+package ganesha
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/gluster/glusterd2/tests"
+	"github.com/gluster/glusterd2/volume"
+
+	config "github.com/spf13/viper"
+)
+
+func TestExportConfig(t *testing.T) {
+	v := &volume.Volinfo{
+		Name:    "vol1",
+		Options: map[string]string{optEnable: "on", optExportID: "7"},
+	}
+
+	conf := exportConfig(v)
+	tests.Assert(t, strings.Contains(conf, "Export_Id = 7;"))
+	tests.Assert(t, strings.Contains(conf, `Path = "/vol1";`))
+	tests.Assert(t, strings.Contains(conf, `Pseudo = "/vol1";`))
+	tests.Assert(t, strings.Contains(conf, `Volume = "vol1";`))
+	tests.Assert(t, !strings.Contains(conf, "<"))
+}
+
+func TestUnusedExportID(t *testing.T) {
+	tests.Assert(t, unusedExportID(nil) == firstExportID)
+
+	vols := []volume.Volinfo{
+		{Name: "a", Options: map[string]string{optExportID: "5"}},
+		{Name: "b", Options: map[string]string{optExportID: "3"}},
+		{Name: "c", Options: map[string]string{}},
+		{Name: "d", Options: map[string]string{optExportID: "bad"}},
+	}
+	tests.Assert(t, unusedExportID(vols) == 6)
+}
+
+func TestUpdateIncludes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ganesha")
+	tests.Assert(t, err == nil)
+	defer os.RemoveAll(dir)
+	config.Set("ganesha-confdir", dir)
+	defer config.Set("ganesha-confdir", "")
+
+	file := path.Join(dir, "ganesha.conf")
+	tests.Assert(t, ioutil.WriteFile(file, []byte("NFS_CORE_PARAM {}\n"), 0644) == nil)
+
+	read := func() string {
+		b, err := ioutil.ReadFile(file)
+		tests.Assert(t, err == nil)
+		return string(b)
+	}
+
+	// Includes are added once, keeping the existing config
+	tests.Assert(t, updateIncludes("vol1", true) == nil)
+	tests.Assert(t, updateIncludes("vol1", true) == nil)
+	tests.Assert(t, updateIncludes("vol2", true) == nil)
+	tests.Assert(t, read() == "NFS_CORE_PARAM {}\n"+includeLine("vol1")+"\n"+includeLine("vol2")+"\n")
+
+	tests.Assert(t, updateIncludes("vol1", false) == nil)
+	tests.Assert(t, read() == "NFS_CORE_PARAM {}\n"+includeLine("vol2")+"\n")
+
+	// A missing ganesha.conf is created
+	tests.Assert(t, os.Remove(file) == nil)
+	tests.Assert(t, updateIncludes("vol3", true) == nil)
+	tests.Assert(t, read() == includeLine("vol3")+"\n")
+}
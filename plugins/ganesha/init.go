@@ -0,0 +1,72 @@
+// Package ganesha implements management of NFS-Ganesha exports of gluster
+// volumes
+package ganesha
+
+import (
+	"github.com/gluster/glusterd2/servers/rest/route"
+	"github.com/gluster/glusterd2/transaction"
+	"github.com/gluster/glusterd2/volgen"
+	"github.com/gluster/glusterd2/volume"
+
+	"github.com/prashanthpai/sunrpc"
+	"github.com/thejerf/suture"
+)
+
+func init() {
+	volume.RegisterServiceStatusFunc(nfsStatus)
+}
+
+// Plugin is a structure which implements GlusterdPlugin interface
+type Plugin struct {
+}
+
+// Name returns name of plugin
+func (p *Plugin) Name() string {
+	return "ganesha"
+}
+
+// SunRPCProgram returns sunrpc program to register with Glusterd
+func (p *Plugin) SunRPCProgram() sunrpc.Program {
+	return nil
+}
+
+// RestRoutes returns list of REST API routes to register with Glusterd
+func (p *Plugin) RestRoutes() route.Routes {
+	return route.Routes{
+		route.Route{
+			Name:        "NFSExport",
+			Method:      "POST",
+			Pattern:     "/volumes/{volname}/nfs/export",
+			Version:     1,
+			HandlerFunc: exportHandler},
+		route.Route{
+			Name:        "NFSUnexport",
+			Method:      "POST",
+			Pattern:     "/volumes/{volname}/nfs/unexport",
+			Version:     1,
+			HandlerFunc: unexportHandler},
+	}
+}
+
+// RegisterStepFuncs registers transaction step functions with
+// Glusterd Transaction framework
+func (p *Plugin) RegisterStepFuncs() {
+	transaction.RegisterStepFunc(storeVolume, "ganesha.StoreVolume")
+	transaction.RegisterStepFunc(exportStep, "ganesha.Export")
+	transaction.RegisterStepFunc(unexportStep, "ganesha.Unexport")
+}
+
+// BrickXlators returns the xlators to be added to the brick graph
+func (p *Plugin) BrickXlators() []volgen.Xlator {
+	return nil
+}
+
+// ClientXlators returns the xlators to be added to the client graph
+func (p *Plugin) ClientXlators() []volgen.Xlator {
+	return nil
+}
+
+// Services returns the long running services of the plugin
+func (p *Plugin) Services() []suture.Service {
+	return nil
+}
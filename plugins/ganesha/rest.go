@@ -0,0 +1,155 @@
+package ganesha
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/gdctx"
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
+	"github.com/gluster/glusterd2/transaction"
+	"github.com/gluster/glusterd2/volume"
+
+	"github.com/gorilla/mux"
+	"github.com/pborman/uuid"
+)
+
+func storeVolume(c transaction.TxnCtx) error {
+	var volinfo volume.Volinfo
+	if err := c.Get("newvolinfo", &volinfo); err != nil {
+		return err
+	}
+	return volume.AddOrUpdateVolume(&volinfo)
+}
+
+func exportStep(c transaction.TxnCtx) error {
+	var volinfo volume.Volinfo
+	if err := c.Get("volinfo", &volinfo); err != nil {
+		return err
+	}
+	if err := export(&volinfo); err != nil {
+		c.Logger().WithError(err).WithField("volume", volinfo.Name).Error("failed to export volume")
+		return err
+	}
+	return nil
+}
+
+func unexportStep(c transaction.TxnCtx) error {
+	var volinfo volume.Volinfo
+	if err := c.Get("volinfo", &volinfo); err != nil {
+		return err
+	}
+	if err := unexport(&volinfo); err != nil {
+		c.Logger().WithError(err).WithField("volume", volinfo.Name).Error("failed to unexport volume")
+		return err
+	}
+	return nil
+}
+
+// runExportTxn runs the steps exporting or unexporting the volume on all its
+// nodes. The export steps work on volinfo, while newvolinfo is the volinfo
+// to be stored once the volume is exported or unexported.
+func runExportTxn(w http.ResponseWriter, r *http.Request, volinfo *volume.Volinfo, newvolinfo *volume.Volinfo, steps []*transaction.Step) {
+	reqID, logger := restutils.GetReqIDandLogger(r)
+
+	lock, unlock, err := transaction.CreateLockSteps(volinfo.Name)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	txn := transaction.NewTxn(reqID)
+	defer txn.Cleanup()
+
+	txn.Nodes = volinfo.Nodes()
+	txn.Steps = append([]*transaction.Step{lock}, steps...)
+	txn.Steps = append(txn.Steps, &transaction.Step{
+		DoFunc: "ganesha.StoreVolume",
+		Nodes:  []uuid.UUID{gdctx.MyUUID},
+	}, unlock)
+
+	if err := txn.Ctx.Set("volinfo", volinfo); err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if err := txn.Ctx.Set("newvolinfo", newvolinfo); err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if _, err := txn.Do(); err != nil {
+		logger.WithError(err).WithField("volume", volinfo.Name).Error("NFS export transaction failed")
+		if err == transaction.ErrLockTimeout {
+			restutils.SendHTTPError(w, http.StatusConflict, err.Error())
+		} else if transaction.IsTimeout(err) {
+			restutils.SendHTTPError(w, http.StatusGatewayTimeout, err.Error())
+		} else {
+			restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	restutils.SendHTTPResponse(w, http.StatusOK, newvolinfo)
+}
+
+func exportHandler(w http.ResponseWriter, r *http.Request) {
+	volinfo, err := volume.GetVolume(mux.Vars(r)["volname"])
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusNotFound, errors.ErrVolNotFound.Error())
+		return
+	}
+
+	if volinfo.Status != volume.VolStarted {
+		restutils.SendHTTPError(w, http.StatusBadRequest, errors.ErrVolNotStarted.Error())
+		return
+	}
+
+	if isExported(volinfo) {
+		restutils.SendHTTPResponse(w, http.StatusOK, volinfo)
+		return
+	}
+
+	id, err := nextExportID()
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	volinfo.Options[optEnable] = "on"
+	volinfo.Options[optExportID] = strconv.Itoa(id)
+
+	runExportTxn(w, r, volinfo, volinfo, []*transaction.Step{
+		{
+			DoFunc:   "ganesha.Export",
+			UndoFunc: "ganesha.Unexport",
+			Nodes:    volinfo.Nodes(),
+		},
+	})
+}
+
+func unexportHandler(w http.ResponseWriter, r *http.Request) {
+	volinfo, err := volume.GetVolume(mux.Vars(r)["volname"])
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusNotFound, errors.ErrVolNotFound.Error())
+		return
+	}
+
+	if !isExported(volinfo) {
+		restutils.SendHTTPResponse(w, http.StatusOK, volinfo)
+		return
+	}
+
+	newvolinfo, err := volume.GetVolume(volinfo.Name)
+	if err != nil {
+		restutils.SendHTTPError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	delete(newvolinfo.Options, optEnable)
+	delete(newvolinfo.Options, optExportID)
+
+	runExportTxn(w, r, volinfo, newvolinfo, []*transaction.Step{
+		{
+			DoFunc: "ganesha.Unexport",
+			Nodes:  volinfo.Nodes(),
+		},
+	})
+}
@@ -2,7 +2,10 @@ package hello
 
 import (
 	"github.com/gluster/glusterd2/servers/rest/route"
+	"github.com/gluster/glusterd2/volgen"
+
 	"github.com/prashanthpai/sunrpc"
+	"github.com/thejerf/suture"
 )
 
 // Plugin is a structure which implements GlusterdPlugin interface
@@ -42,3 +45,18 @@ func (p *Plugin) RestRoutes() route.Routes {
 func (p *Plugin) RegisterStepFuncs() {
 	return
 }
+
+// BrickXlators returns the xlators to be added to the brick graph
+func (p *Plugin) BrickXlators() []volgen.Xlator {
+	return nil
+}
+
+// ClientXlators returns the xlators to be added to the client graph
+func (p *Plugin) ClientXlators() []volgen.Xlator {
+	return nil
+}
+
+// Services returns the long running services of the plugin
+func (p *Plugin) Services() []suture.Service {
+	return nil
+}
@@ -3,10 +3,16 @@
 package plugins
 
 import (
+	"github.com/gluster/glusterd2/plugins/bitrot"
+	"github.com/gluster/glusterd2/plugins/blockvolume"
+	"github.com/gluster/glusterd2/plugins/ganesha"
 	"github.com/gluster/glusterd2/plugins/hello"
 )
 
 // PluginsList is a list of plugins which implements GlusterdPlugin interface
 var PluginsList = []GlusterdPlugin{
 	&hello.Plugin{},
+	&bitrot.Plugin{},
+	&ganesha.Plugin{},
+	&blockvolume.Plugin{},
 }
@@ -0,0 +1,87 @@
+package pmap
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gluster/glusterd2/clusteroptions"
+)
+
+// Names of the cluster options setting the range of ports of the bricks
+const (
+	OptBasePort = "base-port"
+	OptMaxPort  = "max-port"
+)
+
+func init() {
+	clusteroptions.Register(&clusteroptions.Option{
+		Name:         OptBasePort,
+		DefaultValue: strconv.Itoa(gfIanaPrivPortsStart),
+		Description:  "Lowest port assigned to brick processes",
+		Validate:     validatePortRange,
+		OnChange:     func(string) { applyPortRange() },
+	})
+	clusteroptions.Register(&clusteroptions.Option{
+		Name:         OptMaxPort,
+		DefaultValue: strconv.Itoa(gfPortMax),
+		Description:  "Highest port assigned to brick processes",
+		Validate:     validatePortRange,
+		OnChange:     func(string) { applyPortRange() },
+	})
+}
+
+// parsePort returns the port given as a string, or an error if it isn't a
+// valid unprivileged port
+func parsePort(s string) (int, error) {
+	port, err := strconv.Atoi(s)
+	if err != nil || port < 1024 || port > gfPortMax {
+		return 0, fmt.Errorf("port must be a number between 1024 and %d", gfPortMax)
+	}
+	return port, nil
+}
+
+func validatePortRange(_ string, get func(string) string) error {
+	base, err := parsePort(get(OptBasePort))
+	if err != nil {
+		return err
+	}
+	max, err := parsePort(get(OptMaxPort))
+	if err != nil {
+		return err
+	}
+	if base > max {
+		return fmt.Errorf("%s must not be above %s", OptBasePort, OptMaxPort)
+	}
+	return nil
+}
+
+// applyPortRange sets the range ports are allocated from to the one set by
+// the cluster options. Ports already allocated are kept.
+func applyPortRange() {
+	baseValue, err := clusteroptions.Get(OptBasePort)
+	if err != nil {
+		return
+	}
+	maxValue, err := clusteroptions.Get(OptMaxPort)
+	if err != nil {
+		return
+	}
+	base, err1 := parsePort(baseValue)
+	max, err2 := parsePort(maxValue)
+	if err1 != nil || err2 != nil || base > max {
+		return
+	}
+	setPortRange(base, max)
+}
+
+// setPortRange sets the range of ports allocated to brick processes
+func setPortRange(base, max int) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	registry.BasePort = base
+	registry.MaxPort = max
+	if base < registry.LowestPort {
+		registry.LowestPort = base
+	}
+}
@@ -32,8 +32,12 @@ type portStatus struct {
 var registry = struct {
 	sync.RWMutex
 	BasePort  int
+	MaxPort   int
 	LastAlloc int
-	Ports     [gfPortMax + 1]portStatus
+	// LowestPort is the lowest base port the registry has had, below which
+	// no port has been allocated
+	LowestPort int
+	Ports      [gfPortMax + 1]portStatus
 }{}
 
 // NOTE: Export the functions defined here only when other parts of glusterd2
@@ -53,7 +57,7 @@ func registrySearchByXprt(xprt interface{}, ptype PortType) int {
 	defer registry.RUnlock()
 
 	var port int
-	for p := registry.LastAlloc; p >= registry.BasePort; p-- {
+	for p := registry.LastAlloc; p >= registry.LowestPort; p-- {
 		if registry.Ports[p].Xprt == nil {
 			continue
 		}
@@ -82,7 +86,7 @@ func RegistrySearch(brickname string, ptype PortType) int {
 	registry.RLock()
 	defer registry.RUnlock()
 
-	for p := registry.LastAlloc; p >= registry.BasePort; p-- {
+	for p := registry.LastAlloc; p >= registry.LowestPort; p-- {
 
 		if len(registry.Ports[p].Bricknames) == 0 || registry.Ports[p].Type != ptype {
 			continue
@@ -101,7 +105,7 @@ func registryAlloc(recheckForeign bool) int {
 	defer registry.Unlock()
 
 	var port int
-	for p := registry.BasePort; p <= gfPortMax; p++ {
+	for p := registry.BasePort; p <= registry.MaxPort; p++ {
 		if registry.Ports[p].Type == GfPmapPortFree ||
 			(recheckForeign && registry.Ports[p].Type == GfPmapPortForeign) {
 
@@ -227,9 +231,11 @@ func initRegistry() {
 	registry.Lock()
 	defer registry.Unlock()
 
-	// TODO: When a config option by the name 'base-port'
-	// becomes available, use that.
+	// The range is changed with the base-port and max-port cluster
+	// options
 	registry.BasePort = gfIanaPrivPortsStart
+	registry.MaxPort = gfPortMax
+	registry.LowestPort = gfIanaPrivPortsStart
 
 	for i := registry.BasePort; i <= gfPortMax; i++ {
 		if isPortFree(i) {
@@ -243,3 +249,25 @@ func initRegistry() {
 func init() {
 	registryInit.Do(initRegistry)
 }
+
+// RegisteredBricks returns the names of the bricks which have signed in and
+// have a port registered in the portmap registry
+func RegisteredBricks() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	var bricks []string
+	for p := registry.LowestPort; p <= registry.LastAlloc; p++ {
+		if registry.Ports[p].Type != GfPmapPortBrickserver {
+			continue
+		}
+		bricks = append(bricks, registry.Ports[p].Bricknames...)
+	}
+	return bricks
+}
+
+// RemoveBrick removes the port registration of the brick from the portmap
+// registry
+func RemoveBrick(brickname string) {
+	registryRemove(0, brickname, GfPmapPortBrickserver, nil)
+}
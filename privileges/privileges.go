@@ -0,0 +1,167 @@
+// Package privileges checks the privileges GlusterD runs with, so that it can
+// be run as a non-root user with only the capabilities it needs
+package privileges
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// Capability is a Linux capability, as numbered in linux/capability.h
+type Capability uint
+
+// Capabilities used by GlusterD
+const (
+	// CapNetBindService is needed to bind to ports below 1024
+	CapNetBindService Capability = 10
+	// CapSysAdmin is needed to set the trusted.* xattrs on bricks
+	CapSysAdmin Capability = 21
+)
+
+var capNames = map[Capability]string{
+	CapNetBindService: "CAP_NET_BIND_SERVICE",
+	CapSysAdmin:       "CAP_SYS_ADMIN",
+}
+
+func (c Capability) String() string {
+	if name, ok := capNames[c]; ok {
+		return name
+	}
+	return "CAP_" + strconv.Itoa(int(c))
+}
+
+// statusFile is the file the effective capabilities of the process are read
+// from
+const statusFile = "/proc/self/status"
+
+// privilegedPortMax is the highest port which can only be bound to with
+// CapNetBindService
+const privilegedPortMax = 1023
+
+// parseCapEff returns the effective capability set from the contents of the
+// status file of a process
+func parseCapEff(status []byte) (uint64, error) {
+	s := bufio.NewScanner(bytes.NewReader(status))
+	for s.Scan() {
+		line := s.Text()
+		if !strings.HasPrefix(line, "CapEff:") {
+			continue
+		}
+		return strconv.ParseUint(strings.TrimSpace(strings.TrimPrefix(line, "CapEff:")), 16, 64)
+	}
+	if err := s.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("effective capabilities not found in %s", statusFile)
+}
+
+// HasCapability returns true if the process has the capability in its
+// effective set
+func HasCapability(c Capability) (bool, error) {
+	status, err := ioutil.ReadFile(statusFile)
+	if err != nil {
+		return false, err
+	}
+	caps, err := parseCapEff(status)
+	if err != nil {
+		return false, err
+	}
+	return caps&(1<<uint(c)) != 0, nil
+}
+
+// isPrivilegedAddress returns true if the port of the address can only be
+// bound to with CapNetBindService. Port 0 picks any free port, which is never
+// privileged.
+func isPrivilegedAddress(address string) bool {
+	_, p, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	port, err := strconv.Atoi(p)
+	if err != nil {
+		return false
+	}
+	return port > 0 && port <= privilegedPortMax
+}
+
+// Check checks that the process has the capabilities needed to listen on the
+// given addresses, and returns an error naming the missing capability if it
+// does not.
+//
+// Capabilities which are only needed for some operations are not required.
+// Their absence is logged, and those operations fail when they are attempted.
+func Check(addresses ...string) error {
+	logger := log.WithFields(log.Fields{
+		"uid":  os.Geteuid(),
+		"gid":  os.Getegid(),
+		"user": currentUser(),
+	})
+
+	for _, address := range addresses {
+		if !isPrivilegedAddress(address) {
+			continue
+		}
+		ok, err := HasCapability(CapNetBindService)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%s is needed to listen on %s, either grant it or use a port above %d",
+				CapNetBindService, address, privilegedPortMax)
+		}
+	}
+
+	ok, err := HasCapability(CapSysAdmin)
+	if err != nil {
+		// Not being able to check capabilities is not fatal, the
+		// operations needing them will fail with clear enough errors
+		logger.WithError(err).Warn("failed to check capabilities")
+		return nil
+	}
+	if !ok {
+		logger.WithField("capability", CapSysAdmin.String()).Error(
+			"missing capability, bricks cannot be created or expanded as their xattrs cannot be set")
+	} else if os.Geteuid() != 0 {
+		logger.Info("running as a non-root user")
+	}
+
+	return nil
+}
+
+func currentUser() string {
+	u, err := user.Current()
+	if err != nil {
+		return strconv.Itoa(os.Geteuid())
+	}
+	return u.Username
+}
+
+// LookupGroup returns the ID of the group with the given name or ID
+func LookupGroup(name string) (int, error) {
+	g, err := user.LookupGroup(name)
+	if err != nil {
+		if g, err = user.LookupGroupId(name); err != nil {
+			return -1, fmt.Errorf("group %s not found", name)
+		}
+	}
+	return strconv.Atoi(g.Gid)
+}
+
+// SetGroupOwnership gives the group ownership of the path, and sets its
+// permissions to the given mode. The group can then access the path without
+// GlusterD running as that group.
+func SetGroupOwnership(path string, gid int, mode os.FileMode) error {
+	if err := os.Lchown(path, -1, gid); err != nil {
+		return err
+	}
+	return os.Chmod(path, mode)
+}
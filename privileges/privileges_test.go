@@ -0,0 +1,27 @@
+package privileges
+
+import (
+	"testing"
+
+	"github.com/gluster/glusterd2/tests"
+)
+
+func TestParseCapEff(t *testing.T) {
+	status := []byte("Name:\tglusterd2\nCapInh:\t0000000000000000\nCapPrm:\t0000003fffffffff\nCapEff:\t0000000000200400\n")
+	caps, err := parseCapEff(status)
+	tests.Assert(t, err == nil)
+	tests.Assert(t, caps&(1<<uint(CapSysAdmin)) != 0)
+	tests.Assert(t, caps&(1<<uint(CapNetBindService)) != 0)
+	tests.Assert(t, caps&1 == 0)
+
+	_, err = parseCapEff([]byte("Name:\tglusterd2\n"))
+	tests.Assert(t, err != nil)
+}
+
+func TestIsPrivilegedAddress(t *testing.T) {
+	tests.Assert(t, isPrivilegedAddress(":80"))
+	tests.Assert(t, isPrivilegedAddress("127.0.0.1:1023"))
+	tests.Assert(t, !isPrivilegedAddress(":24007"))
+	tests.Assert(t, !isPrivilegedAddress(":0"))
+	tests.Assert(t, !isPrivilegedAddress("localhost"))
+}
@@ -0,0 +1,197 @@
+package quorum
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gluster/glusterd2/brick"
+	"github.com/gluster/glusterd2/daemon"
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/events"
+	"github.com/gluster/glusterd2/gdctx"
+	"github.com/gluster/glusterd2/peer"
+	"github.com/gluster/glusterd2/store"
+	"github.com/gluster/glusterd2/volume"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/pborman/uuid"
+)
+
+// Names of the events emitted when the server quorum state changes
+const (
+	EventQuorumLost     = "quorum.lost"
+	EventQuorumRegained = "quorum.regained"
+)
+
+// checkInterval is the interval at which quorum is checked, in addition to
+// the checks done when peer liveness changes. This also picks up changes in
+// the quorum configuration.
+const checkInterval = 10 * time.Second
+
+// ratioChanged triggers a check when the server-quorum-ratio option changes
+var ratioChanged = make(chan struct{}, 1)
+
+var (
+	// startBrick and stopBrick start and stop the brick processes, and
+	// are replaced in tests
+	startBrick = func(d *brick.Glusterfsd) error { return daemon.Start(d, true) }
+	stopBrick  = func(d *brick.Glusterfsd) error { return daemon.Stop(d, true) }
+
+	// getVolumes returns the volumes whose bricks are stopped and started
+	getVolumes = volume.GetVolumes
+)
+
+// Monitor keeps track of the server quorum state of the cluster. Bricks on
+// this node are stopped when quorum is lost, and started again when quorum
+// is regained.
+// It provides an implementation of the github.com/thejerf/suture.Service
+// interface.
+type Monitor struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	met    bool
+	// stopped holds the IDs of the bricks stopped by the monitor when
+	// quorum was lost. Only these bricks are started when quorum is
+	// regained, bricks which were taken offline otherwise stay offline.
+	stopped map[string]bool
+}
+
+// NewMonitor returns a new quorum Monitor
+func NewMonitor() *Monitor {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Monitor{ctx: ctx, cancel: cancel, met: true, stopped: make(map[string]bool)}
+}
+
+// Serve begins monitoring the server quorum
+func (m *Monitor) Serve() {
+	trigger := make(chan struct{}, 1)
+	go store.Store.WatchLiveness(m.ctx, func(uuid.UUID, bool) {
+		select {
+		case trigger <- struct{}{}:
+		default:
+		}
+	})
+
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	log.Info("started server quorum monitor")
+	for {
+		m.check()
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-trigger:
+		case <-ratioChanged:
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop stops monitoring the server quorum
+func (m *Monitor) Stop() {
+	m.cancel()
+	log.Info("stopped server quorum monitor")
+}
+
+func (m *Monitor) check() {
+	s, err := GetStatus()
+	if err != nil {
+		// A peer which can't reach the store can't tell if the other
+		// peers are online, and is treated as being on the losing side
+		log.WithError(err).Warn("failed to get server quorum status, treating server quorum as lost")
+		s = &Status{State: StateLost}
+	}
+
+	if s.Met == m.met {
+		return
+	}
+	m.met = s.Met
+
+	data := map[string]string{
+		"quorum.ratio":    s.Ratio,
+		"quorum.required": strconv.Itoa(s.Required),
+		"quorum.online":   strconv.Itoa(s.OnlinePeers),
+		"quorum.total":    strconv.Itoa(s.TotalPeers),
+	}
+
+	if s.Met {
+		events.Broadcast(events.New(EventQuorumRegained, data))
+		if peer.InMaintenance(gdctx.MyUUID.String()) {
+			// The bricks are started when the peer leaves maintenance
+			m.stopped = make(map[string]bool)
+			return
+		}
+		m.startStoppedBricks()
+	} else {
+		events.Broadcast(events.New(EventQuorumLost, data))
+		m.stopBricks()
+	}
+}
+
+// stopBricks stops the running bricks of this node, and remembers them to be
+// started again once quorum is regained
+func (m *Monitor) stopBricks() {
+	forEachLocalBrick(func(d *brick.Glusterfsd) error {
+		err := stopBrick(d)
+		switch {
+		case err == nil:
+			m.stopped[d.ID()] = true
+		case os.IsNotExist(err) || err == errors.ErrProcessNotFound:
+			// The brick isn't running
+			err = nil
+		}
+		return err
+	})
+}
+
+// startStoppedBricks starts the bricks stopped when quorum was lost which
+// still belong to started volumes
+func (m *Monitor) startStoppedBricks() {
+	forEachLocalBrick(func(d *brick.Glusterfsd) error {
+		if !m.stopped[d.ID()] {
+			return nil
+		}
+		err := startBrick(d)
+		if err == errors.ErrProcessAlreadyRunning {
+			return nil
+		}
+		return err
+	})
+	m.stopped = make(map[string]bool)
+}
+
+// forEachLocalBrick runs fn on the bricks of this node which belong to
+// started volumes
+func forEachLocalBrick(fn func(*brick.Glusterfsd) error) {
+	volumes, err := getVolumes()
+	if err != nil {
+		log.WithError(err).Error("failed to get volumes")
+		return
+	}
+
+	for _, v := range volumes {
+		if v.Status != volume.VolStarted {
+			continue
+		}
+		for _, b := range v.Bricks {
+			if !uuid.Equal(b.NodeID, gdctx.MyUUID) {
+				continue
+			}
+
+			d, err := brick.NewGlusterfsd(b)
+			if err != nil {
+				log.WithError(err).WithField("brick", b.Path).Error("failed to create brick daemon")
+				continue
+			}
+			if err := fn(d); err != nil {
+				log.WithError(err).WithFields(log.Fields{
+					"volume": v.Name,
+					"brick":  b.Hostname + ":" + b.Path,
+				}).Error("failed to change brick state on quorum change")
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package quorum
+
+import (
+	"os"
+	"testing"
+
+	"github.com/gluster/glusterd2/brick"
+	"github.com/gluster/glusterd2/gdctx"
+	"github.com/gluster/glusterd2/tests"
+	"github.com/gluster/glusterd2/volume"
+
+	heketitests "github.com/heketi/tests"
+	"github.com/pborman/uuid"
+	config "github.com/spf13/viper"
+)
+
+func TestMonitorRestartsStoppedBricks(t *testing.T) {
+	config.Set("mock-bricks", true)
+	defer config.Set("mock-bricks", false)
+	defer heketitests.Patch(&gdctx.MyUUID, uuid.NewRandom()).Restore()
+
+	vol := volume.Volinfo{
+		Name:   "vol",
+		Status: volume.VolStarted,
+		Bricks: []brick.Brickinfo{
+			{NodeID: gdctx.MyUUID, Path: "/b1"},
+			{NodeID: gdctx.MyUUID, Path: "/b2"},
+			{NodeID: uuid.NewRandom(), Path: "/b3"},
+		},
+	}
+	defer heketitests.Patch(&getVolumes, func() ([]volume.Volinfo, error) {
+		return []volume.Volinfo{vol}, nil
+	}).Restore()
+
+	// /b2 was taken offline before quorum was lost
+	running := map[string]bool{"/b1": true}
+	var started []string
+	defer heketitests.Patch(&stopBrick, func(d *brick.Glusterfsd) error {
+		if !running[d.ID()] {
+			return os.ErrNotExist
+		}
+		running[d.ID()] = false
+		return nil
+	}).Restore()
+	defer heketitests.Patch(&startBrick, func(d *brick.Glusterfsd) error {
+		started = append(started, d.ID())
+		running[d.ID()] = true
+		return nil
+	}).Restore()
+
+	m := NewMonitor()
+	m.stopBricks()
+	tests.Assert(t, len(m.stopped) == 1 && m.stopped["/b1"])
+	tests.Assert(t, !running["/b1"])
+
+	m.startStoppedBricks()
+	tests.Assert(t, len(started) == 1 && started[0] == "/b1")
+	tests.Assert(t, len(m.stopped) == 0)
+
+	// Nothing is started again without quorum having been lost
+	m.startStoppedBricks()
+	tests.Assert(t, len(started) == 1)
+}
@@ -0,0 +1,203 @@
+// Package quorum implements server side quorum for GlusterD.
+//
+// When server quorum is enabled, brick processes are only allowed to run when
+// enough peers in the cluster are online. The number of peers required is
+// configured as a percentage of the total peers (for eg. "51%") or as a fixed
+// count (for eg. "2").
+package quorum
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/gluster/glusterd2/clusteroptions"
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/peer"
+	"github.com/gluster/glusterd2/store"
+)
+
+// OptRatio is the name of the cluster option setting the quorum ratio
+const OptRatio = "server-quorum-ratio"
+
+const (
+	quorumKey = store.GlusterPrefix + "cluster/quorum"
+
+	// defaultRatio requires more than half of the peers to be online
+	defaultRatio = "51%"
+)
+
+// Config is the server quorum configuration of the cluster
+type Config struct {
+	Enabled bool   `json:"enabled"`
+	Ratio   string `json:"ratio"`
+}
+
+// States of server quorum reported in Status
+const (
+	StateDisabled = "disabled"
+	StateMet      = "met"
+	StateLost     = "lost"
+)
+
+// Status represents the current server quorum state of the cluster. Met is
+// true if bricks are allowed to run, which is always the case when server
+// quorum is disabled; State tells the cases apart.
+type Status struct {
+	Config
+	State       string `json:"state"`
+	Met         bool   `json:"met"`
+	Required    int    `json:"required"`
+	TotalPeers  int    `json:"total-peers"`
+	OnlinePeers int    `json:"online-peers"`
+}
+
+func init() {
+	clusteroptions.Register(&clusteroptions.Option{
+		Name:         OptRatio,
+		DefaultValue: defaultRatio,
+		Description:  "Peers required to be online for server quorum to be met, as a percentage of the peers or a count",
+		Validate: func(value string, _ func(string) string) error {
+			c := Config{Ratio: value}
+			return c.Validate()
+		},
+		OnChange: func(string) {
+			select {
+			case ratioChanged <- struct{}{}:
+			default:
+			}
+		},
+	})
+}
+
+// Validate checks if the quorum ratio is a valid percentage or count
+func (c *Config) Validate() error {
+	if c.Ratio == "" {
+		return nil
+	}
+
+	if strings.HasSuffix(c.Ratio, "%") {
+		p, err := strconv.Atoi(strings.TrimSuffix(c.Ratio, "%"))
+		if err != nil || p <= 0 || p > 100 {
+			return errors.ErrInvalidQuorumRatio
+		}
+		return nil
+	}
+
+	if n, err := strconv.Atoi(c.Ratio); err != nil || n <= 0 {
+		return errors.ErrInvalidQuorumRatio
+	}
+	return nil
+}
+
+// required returns the number of peers which need to be online for quorum
+// to be met in a cluster of total peers
+func (c *Config) required(total int) int {
+	ratio := c.Ratio
+	if ratio == "" {
+		ratio = defaultRatio
+	}
+
+	if strings.HasSuffix(ratio, "%") {
+		p, _ := strconv.Atoi(strings.TrimSuffix(ratio, "%"))
+		// Round up, so that 51% of 3 peers requires 2 peers
+		return (total*p + 99) / 100
+	}
+
+	n, _ := strconv.Atoi(ratio)
+	return n
+}
+
+// state returns the server quorum state with online peers out of total
+func (c *Config) state(online int, total int) string {
+	switch {
+	case !c.Enabled:
+		return StateDisabled
+	case online >= c.required(total):
+		return StateMet
+	}
+	return StateLost
+}
+
+// GetConfig returns the quorum configuration saved in the store. Server
+// quorum is disabled if no configuration has been saved. The ratio is the
+// server-quorum-ratio cluster option, unless a ratio was saved along with the
+// configuration before the option existed.
+func GetConfig() (*Config, error) {
+	resp, err := store.Store.Get(context.TODO(), quorumKey)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Config{}
+	if resp.Count != 0 {
+		if err := json.Unmarshal(resp.Kvs[0].Value, c); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.Ratio == "" {
+		if c.Ratio, err = clusteroptions.Get(OptRatio); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// SetConfig saves the quorum configuration into the store. The ratio, if
+// given, is saved as the server-quorum-ratio cluster option.
+func SetConfig(c *Config) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	if c.Ratio != "" {
+		if err := clusteroptions.Set(map[string]string{OptRatio: c.Ratio}); err != nil {
+			return err
+		}
+	}
+
+	b, err := json.Marshal(&Config{Enabled: c.Enabled})
+	if err != nil {
+		return err
+	}
+
+	_, err = store.Store.Put(context.TODO(), quorumKey, string(b))
+	return err
+}
+
+// GetStatus returns the current quorum status of the cluster
+func GetStatus() (*Status, error) {
+	c, err := GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	peers, err := peer.GetPeersF()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Status{Config: *c, TotalPeers: len(peers)}
+	for _, p := range peers {
+		if store.Store.IsNodeAlive(p.ID) {
+			s.OnlinePeers++
+		}
+	}
+	s.Required = c.required(s.TotalPeers)
+	s.State = c.state(s.OnlinePeers, s.TotalPeers)
+	s.Met = s.State != StateLost
+
+	return s, nil
+}
+
+// IsMet returns true if server quorum is disabled or if enough peers are
+// online
+func IsMet() bool {
+	s, err := GetStatus()
+	if err != nil {
+		return false
+	}
+	return s.Met
+}
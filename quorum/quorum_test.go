@@ -0,0 +1,46 @@
+package quorum
+
+import (
+	"testing"
+
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/tests"
+)
+
+func TestValidate(t *testing.T) {
+	for _, r := range []string{"", "51%", "100%", "1", "3"} {
+		c := Config{Enabled: true, Ratio: r}
+		tests.Assert(t, c.Validate() == nil)
+	}
+
+	for _, r := range []string{"0%", "101%", "-1", "0", "abc", "%"} {
+		c := Config{Enabled: true, Ratio: r}
+		tests.Assert(t, c.Validate() == errors.ErrInvalidQuorumRatio)
+	}
+}
+
+func TestRequired(t *testing.T) {
+	c := Config{Enabled: true, Ratio: "51%"}
+	tests.Assert(t, c.required(1) == 1)
+	tests.Assert(t, c.required(2) == 2)
+	tests.Assert(t, c.required(3) == 2)
+	tests.Assert(t, c.required(4) == 3)
+
+	c.Ratio = "100%"
+	tests.Assert(t, c.required(3) == 3)
+
+	c.Ratio = "2"
+	tests.Assert(t, c.required(5) == 2)
+
+	c.Ratio = ""
+	tests.Assert(t, c.required(3) == 2)
+}
+
+func TestState(t *testing.T) {
+	c := Config{Enabled: false, Ratio: "51%"}
+	tests.Assert(t, c.state(0, 3) == StateDisabled)
+
+	c.Enabled = true
+	tests.Assert(t, c.state(2, 3) == StateMet)
+	tests.Assert(t, c.state(1, 3) == StateLost)
+}
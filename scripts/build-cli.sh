@@ -16,12 +16,12 @@ REPO_PATH="github.com/gluster/glusterd2"
 GIT_SHA=`git rev-parse --short HEAD || echo "undefined"`
 LDFLAGS="-X ${REPO_PATH}/version.GlusterdVersion=$VERSION -X ${REPO_PATH}/version.GitSHA=$GIT_SHA"
 LDFLAGS+=" -B 0x$(head -c20 /dev/urandom | od -An -tx1 | tr -d ' \n')"
-BIN=glustercli
+BIN=glustercli$(go env GOEXE)
 
 echo "Building $BIN $VERSION"
 
 cd cli
-go build -ldflags "${LDFLAGS}" -o ../$OUTDIR/$BIN -tags "$GOBUILD_TAGS" || exit 1
+CGO_ENABLED=0 go build -ldflags "${LDFLAGS}" -o ../$OUTDIR/$BIN -tags "$GOBUILD_TAGS" || exit 1
 cd ..
 
 echo "Built $BIN $VERSION at $OUTDIR/$BIN"
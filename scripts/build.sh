@@ -27,6 +27,6 @@ fi
 
 echo "Building $BIN $VERSION"
 
-go build -ldflags "${LDFLAGS}" -o $OUTDIR/$BIN -tags "$GOBUILD_TAGS" || exit 1
+CGO_ENABLED=${CGO_ENABLED:-0} go build -ldflags "${LDFLAGS}" -o $OUTDIR/$BIN -tags "$GOBUILD_TAGS" || exit 1
 
 echo "Built $BIN $VERSION at $OUTDIR/$BIN"
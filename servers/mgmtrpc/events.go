@@ -0,0 +1,87 @@
+package mgmtrpc
+
+import (
+	"sync"
+
+	"github.com/gluster/glusterd2/events"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// eventBacklog is the number of events buffered for a client watching events.
+// Events are dropped for clients which fall further behind.
+const eventBacklog = 64
+
+var watchers = struct {
+	sync.Mutex
+	chans map[chan *events.Event]bool
+}{
+	chans: make(map[chan *events.Event]bool),
+}
+
+func init() {
+	events.Register(publish)
+}
+
+// publish sends the event to all the clients watching events
+func publish(e *events.Event) {
+	watchers.Lock()
+	defer watchers.Unlock()
+
+	for ch := range watchers.chans {
+		select {
+		case ch <- e:
+		default:
+			log.WithField("event", e.Name).Warn("dropped event for slow gRPC event watcher")
+		}
+	}
+}
+
+func watch() chan *events.Event {
+	watchers.Lock()
+	defer watchers.Unlock()
+
+	ch := make(chan *events.Event, eventBacklog)
+	watchers.chans[ch] = true
+	return ch
+}
+
+func unwatch(ch chan *events.Event) {
+	watchers.Lock()
+	defer watchers.Unlock()
+
+	delete(watchers.chans, ch)
+}
+
+// WatchEvents streams the events broadcast on this peer, until the client
+// cancels the call
+func (s *service) WatchEvents(in *EventsReq, stream MgmtService_WatchEventsServer) error {
+	names := make(map[string]bool)
+	for _, n := range in.Names {
+		names[n] = true
+	}
+
+	ch := watch()
+	defer unwatch(ch)
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case e := <-ch:
+			if len(names) != 0 && !names[e.Name] {
+				continue
+			}
+			err := stream.Send(&Event{
+				ID:        e.ID.String(),
+				Name:      e.Name,
+				Data:      e.Data,
+				Origin:    e.Origin.String(),
+				Timestamp: e.Timestamp.UnixNano(),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,55 @@
+// Package mgmtrpc implements the management gRPC API of GlusterD, for clients
+// preferring typed RPCs to the REST API.
+//
+// The API is served on the client address along with the REST API. Calls are
+// served by the handlers of the REST API, so both APIs share the same
+// validation, transactions and errors. Operations without an RPC of their own
+// can be called with the Call RPC, which takes any REST API request.
+package mgmtrpc
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/gluster/glusterd2/gdctx"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/soheilhy/cmux"
+	"google.golang.org/grpc"
+)
+
+// Server is the management gRPC server
+// It provides an implementation of github.com/thejerf/suture.Service interface
+type Server struct {
+	server   *grpc.Server
+	listener net.Listener
+}
+
+// NewMuxed returns a Server listening on a CMux multiplexed connection, which
+// serves the calls with the given REST API handler
+func NewMuxed(m cmux.CMux, h http.Handler) *Server {
+	s := &Server{
+		server:   grpc.NewServer(),
+		listener: m.Match(cmux.HTTP2HeaderField("content-type", "application/grpc")),
+	}
+	RegisterMgmtServiceServer(s.server, &service{handler: h})
+
+	return s
+}
+
+// Serve begins serving management gRPC calls
+func (s *Server) Serve() {
+	log.WithField("ip:port", s.listener.Addr().String()).Info("started management gRPC server")
+	gdctx.SetListenerState(gdctx.MgmtRPCListener, true)
+	defer gdctx.SetListenerState(gdctx.MgmtRPCListener, false)
+
+	if err := s.server.Serve(s.listener); err != nil {
+		log.WithError(err).Error("management gRPC server failed")
+	}
+}
+
+// Stop stops the management gRPC server
+func (s *Server) Stop() {
+	s.server.GracefulStop()
+	log.Info("stopped management gRPC server")
+}
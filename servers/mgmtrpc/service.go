@@ -0,0 +1,342 @@
+package mgmtrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gluster/glusterd2/pkg/api"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// forwardedHeaders are the headers of REST requests which can be given as
+// metadata of calls
+var forwardedHeaders = []string{
+	"X-Request-ID",
+	"Idempotency-Key",
+	"Authorization",
+}
+
+// grpcCodes maps the status codes of REST responses to gRPC codes
+var grpcCodes = map[int]codes.Code{
+	http.StatusBadRequest:          codes.InvalidArgument,
+	http.StatusUnauthorized:        codes.Unauthenticated,
+	http.StatusForbidden:           codes.PermissionDenied,
+	http.StatusNotFound:            codes.NotFound,
+	http.StatusMethodNotAllowed:    codes.Unimplemented,
+	http.StatusConflict:            codes.Aborted,
+	http.StatusPreconditionFailed:  codes.FailedPrecondition,
+	http.StatusUnprocessableEntity: codes.InvalidArgument,
+	http.StatusTooManyRequests:     codes.ResourceExhausted,
+	http.StatusNotImplemented:      codes.Unimplemented,
+	http.StatusServiceUnavailable:  codes.Unavailable,
+	http.StatusGatewayTimeout:      codes.DeadlineExceeded,
+}
+
+// responseBuffer is an http.ResponseWriter keeping the response in memory
+type responseBuffer struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *responseBuffer) Header() http.Header {
+	return b.header
+}
+
+func (b *responseBuffer) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+func (b *responseBuffer) Write(p []byte) (int, error) {
+	b.WriteHeader(http.StatusOK)
+	return b.body.Write(p)
+}
+
+// service implements MgmtServiceServer with the handler of the REST API
+type service struct {
+	handler http.Handler
+}
+
+// call serves a REST API request with the given method, path and JSON body
+func (s *service) call(ctx context.Context, method, path string, body []byte) (*responseBuffer, error) {
+	if !strings.HasPrefix(path, "/") {
+		return nil, grpc.Errorf(codes.InvalidArgument, "invalid path %s", path)
+	}
+	req, err := http.NewRequest(method, path, bytes.NewReader(body))
+	if err != nil {
+		return nil, grpc.Errorf(codes.InvalidArgument, err.Error())
+	}
+	// Requests are cancelled along with their calls
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+
+	if md, ok := metadata.FromContext(ctx); ok {
+		for _, h := range forwardedHeaders {
+			if v := md[strings.ToLower(h)]; len(v) != 0 {
+				req.Header.Set(h, v[0])
+			}
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		req.RemoteAddr = p.Addr.String()
+	}
+
+	b := &responseBuffer{header: make(http.Header)}
+	s.handler.ServeHTTP(b, req)
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	return b, nil
+}
+
+// do serves a REST API request, and decodes the JSON response into out.
+// Responses with an error status are returned as gRPC errors.
+func (s *service) do(ctx context.Context, method, path string, in interface{}, out interface{}) error {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return grpc.Errorf(codes.Internal, err.Error())
+		}
+	}
+
+	b, err := s.call(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+
+	if b.status < 200 || b.status > 299 {
+		var apiErr api.HTTPError
+		json.Unmarshal(b.body.Bytes(), &apiErr)
+		if apiErr.Error == "" {
+			apiErr.Error = http.StatusText(b.status)
+		}
+		code, ok := grpcCodes[b.status]
+		if !ok {
+			code = codes.Internal
+		}
+		return grpc.Errorf(code, apiErr.Error)
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(b.body.Bytes(), out); err != nil {
+			return grpc.Errorf(codes.Internal, err.Error())
+		}
+	}
+	return nil
+}
+
+func toVolume(v *api.Volinfo) *Volume {
+	vol := &Volume{
+		ID:           v.ID.String(),
+		Name:         v.Name,
+		Type:         uint32(v.Type),
+		Status:       uint32(v.Status),
+		ReplicaCount: int32(v.ReplicaCount),
+		Options:      v.Options,
+		Capacity:     v.Capacity,
+	}
+	for _, b := range v.Bricks {
+		vol.Bricks = append(vol.Bricks, &Brick{
+			NodeID:   b.NodeID.String(),
+			Hostname: b.Hostname,
+			Path:     b.Path,
+		})
+	}
+	return vol
+}
+
+func toPeer(p *api.Peer) *Peer {
+	return &Peer{
+		ID:          p.ID.String(),
+		Name:        p.Name,
+		Addresses:   p.Addresses,
+		Online:      p.Online,
+		Maintenance: p.Maintenance,
+	}
+}
+
+func toSnapshot(s *api.Snapshot) *Snapshot {
+	snap := &Snapshot{
+		ID:          s.ID.String(),
+		Name:        s.Name,
+		Description: s.Description,
+		CreatedAt:   s.CreatedAt.UnixNano(),
+		Scheduled:   s.Scheduled,
+		Volume:      toVolume(&s.Volinfo),
+	}
+	for _, b := range s.Bricks {
+		snap.Bricks = append(snap.Bricks, &SnapBrick{
+			NodeID: b.NodeID.String(),
+			Path:   b.Path,
+			VG:     b.VG,
+			LV:     b.LV,
+		})
+	}
+	return snap
+}
+
+// Call serves any request of the REST API. Responses with an error status are
+// returned as they are, along with the status.
+func (s *service) Call(ctx context.Context, in *CallReq) (*CallResp, error) {
+	b, err := s.call(ctx, strings.ToUpper(in.Method), in.Path, in.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &CallResp{Status: int32(b.status), Body: b.body.Bytes()}, nil
+}
+
+func (s *service) VolumeCreate(ctx context.Context, in *VolumeCreateReq) (*Volume, error) {
+	req := api.VolCreateReq{
+		Name:    in.Name,
+		Replica: int(in.Replica),
+		Bricks:  in.Bricks,
+		Force:   in.Force,
+	}
+	var v api.Volinfo
+	if err := s.do(ctx, "POST", "/v1/volumes", req, &v); err != nil {
+		return nil, err
+	}
+	return toVolume(&v), nil
+}
+
+func (s *service) VolumeGet(ctx context.Context, in *VolumeReq) (*Volume, error) {
+	var v api.Volinfo
+	if err := s.do(ctx, "GET", fmt.Sprintf("/v1/volumes/%s", in.Name), nil, &v); err != nil {
+		return nil, err
+	}
+	return toVolume(&v), nil
+}
+
+func (s *service) VolumeList(ctx context.Context, in *Empty) (*VolumeListResp, error) {
+	var vols api.VolDetailListPage
+	if err := s.do(ctx, "GET", "/v2/volumes?details=true", nil, &vols); err != nil {
+		return nil, err
+	}
+	resp := new(VolumeListResp)
+	for i := range vols.Volumes {
+		resp.Volumes = append(resp.Volumes, toVolume(&vols.Volumes[i]))
+	}
+	return resp, nil
+}
+
+func (s *service) VolumeStart(ctx context.Context, in *VolumeReq) (*Empty, error) {
+	if err := s.do(ctx, "POST", fmt.Sprintf("/v1/volumes/%s/start", in.Name), nil, nil); err != nil {
+		return nil, err
+	}
+	return new(Empty), nil
+}
+
+func (s *service) VolumeStop(ctx context.Context, in *VolumeReq) (*Empty, error) {
+	if err := s.do(ctx, "POST", fmt.Sprintf("/v1/volumes/%s/stop", in.Name), nil, nil); err != nil {
+		return nil, err
+	}
+	return new(Empty), nil
+}
+
+func (s *service) VolumeDelete(ctx context.Context, in *VolumeReq) (*Empty, error) {
+	if err := s.do(ctx, "DELETE", fmt.Sprintf("/v1/volumes/%s", in.Name), nil, nil); err != nil {
+		return nil, err
+	}
+	return new(Empty), nil
+}
+
+func (s *service) VolumeSetOptions(ctx context.Context, in *VolumeOptionsReq) (*Empty, error) {
+	req := api.VolOptionReq{Options: in.Options}
+	if err := s.do(ctx, "POST", fmt.Sprintf("/v1/volumes/%s/options", in.Name), req, nil); err != nil {
+		return nil, err
+	}
+	return new(Empty), nil
+}
+
+func (s *service) PeerAdd(ctx context.Context, in *PeerAddReq) (*Peer, error) {
+	var p api.Peer
+	if err := s.do(ctx, "POST", "/v1/peers", api.PeerAddReq{Addresses: in.Addresses}, &p); err != nil {
+		return nil, err
+	}
+	return toPeer(&p), nil
+}
+
+func (s *service) PeerList(ctx context.Context, in *Empty) (*PeerListResp, error) {
+	var peers []api.Peer
+	if err := s.do(ctx, "GET", "/v1/peers", nil, &peers); err != nil {
+		return nil, err
+	}
+	resp := new(PeerListResp)
+	for i := range peers {
+		resp.Peers = append(resp.Peers, toPeer(&peers[i]))
+	}
+	return resp, nil
+}
+
+func (s *service) PeerDelete(ctx context.Context, in *PeerReq) (*Empty, error) {
+	if err := s.do(ctx, "DELETE", fmt.Sprintf("/v1/peers/%s", in.ID), nil, nil); err != nil {
+		return nil, err
+	}
+	return new(Empty), nil
+}
+
+func (s *service) SnapshotCreate(ctx context.Context, in *SnapshotCreateReq) (*Snapshot, error) {
+	req := api.SnapCreateReq{
+		Volume:      in.Volume,
+		Name:        in.Name,
+		Description: in.Description,
+	}
+	var snap api.Snapshot
+	if err := s.do(ctx, "POST", "/v1/snapshots", req, &snap); err != nil {
+		return nil, err
+	}
+	return toSnapshot(&snap), nil
+}
+
+func (s *service) SnapshotGet(ctx context.Context, in *SnapshotReq) (*Snapshot, error) {
+	var snap api.Snapshot
+	if err := s.do(ctx, "GET", fmt.Sprintf("/v1/snapshots/%s", in.Name), nil, &snap); err != nil {
+		return nil, err
+	}
+	return toSnapshot(&snap), nil
+}
+
+func (s *service) SnapshotList(ctx context.Context, in *SnapshotListReq) (*SnapshotListResp, error) {
+	path := "/v1/snapshots"
+	if in.Volume != "" {
+		path += "?volume=" + url.QueryEscape(in.Volume)
+	}
+	var snaps []api.Snapshot
+	if err := s.do(ctx, "GET", path, nil, &snaps); err != nil {
+		return nil, err
+	}
+	resp := new(SnapshotListResp)
+	for i := range snaps {
+		resp.Snapshots = append(resp.Snapshots, toSnapshot(&snaps[i]))
+	}
+	return resp, nil
+}
+
+func (s *service) SnapshotDelete(ctx context.Context, in *SnapshotReq) (*Empty, error) {
+	if err := s.do(ctx, "DELETE", fmt.Sprintf("/v1/snapshots/%s", in.Name), nil, nil); err != nil {
+		return nil, err
+	}
+	return new(Empty), nil
+}
+
+func (s *service) SnapshotClone(ctx context.Context, in *SnapshotCloneReq) (*Volume, error) {
+	req := api.SnapCloneReq{Name: in.Volume}
+	var v api.Volinfo
+	if err := s.do(ctx, "POST", fmt.Sprintf("/v1/snapshots/%s/clone", in.Name), req, &v); err != nil {
+		return nil, err
+	}
+	return toVolume(&v), nil
+}
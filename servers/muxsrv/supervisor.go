@@ -1,6 +1,7 @@
 package muxsrv
 
 import (
+	"github.com/gluster/glusterd2/servers/mgmtrpc"
 	"github.com/gluster/glusterd2/servers/rest"
 	"github.com/gluster/glusterd2/servers/sunrpc"
 
@@ -8,8 +9,9 @@ import (
 	"github.com/thejerf/suture"
 )
 
-// New returns a new supervisor managing the mux listener and the multiplexed services
-func New() *suture.Supervisor {
+// New returns a new supervisor managing the mux listener and the multiplexed
+// services, along with the multiplexed REST server
+func New() (*suture.Supervisor, *rest.GDRest) {
 	logger := func(msg string) {
 		log.WithField("supervisor", "gd2-muxserver").Println(msg)
 	}
@@ -17,9 +19,12 @@ func New() *suture.Supervisor {
 
 	m := newMuxSrv()
 
-	s.Add(rest.NewMuxed(m.m))
+	r := rest.NewMuxed(m.m)
+	s.Add(r)
+	// The management gRPC API is served by the handlers of the REST API
+	s.Add(mgmtrpc.NewMuxed(m.m, r.Handler()))
 	s.Add(sunrpc.NewMuxed(m.m))
 	s.Add(m)
 
-	return s
+	return s, r
 }
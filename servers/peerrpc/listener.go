@@ -4,6 +4,8 @@ package peerrpc
 import (
 	"net"
 
+	"github.com/gluster/glusterd2/gdctx"
+
 	log "github.com/Sirupsen/logrus"
 	config "github.com/spf13/viper"
 	"google.golang.org/grpc"
@@ -46,6 +48,9 @@ func (s *Server) Serve() {
 		}
 	}
 
+	gdctx.SetListenerState(gdctx.PeerRPCListener, true)
+	defer gdctx.SetListenerState(gdctx.PeerRPCListener, false)
+
 	s.server.Serve(l)
 	return
 }
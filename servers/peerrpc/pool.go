@@ -0,0 +1,47 @@
+package peerrpc
+
+import (
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
+	"google.golang.org/grpc"
+)
+
+var (
+	conns     = make(map[string]*grpc.ClientConn)
+	connsLock sync.Mutex
+)
+
+// Conn returns a client connection to the peer RPC server at the given
+// address. Connections are pooled and shared between callers, and must not be
+// closed by them. A broken connection is reconnected to automatically, with
+// backoff, by gRPC.
+func Conn(address string) (*grpc.ClientConn, error) {
+	connsLock.Lock()
+	defer connsLock.Unlock()
+
+	if conn, ok := conns[address]; ok {
+		return conn, nil
+	}
+
+	conn, err := grpc.Dial(address, grpc.WithInsecure())
+	if err != nil {
+		return nil, err
+	}
+	log.WithField("remote", address).Debug("connected to peer rpc server")
+
+	conns[address] = conn
+	return conn, nil
+}
+
+// CloseConn closes the pooled connection to the given address, if any. It
+// should be called when a peer is removed from the cluster.
+func CloseConn(address string) {
+	connsLock.Lock()
+	defer connsLock.Unlock()
+
+	if conn, ok := conns[address]; ok {
+		conn.Close()
+		delete(conns, address)
+	}
+}
@@ -0,0 +1,112 @@
+package peerrpc
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+
+	log "github.com/Sirupsen/logrus"
+	netctx "golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// StreamHandler handles a stream request from a peer. The handler sends data
+// to the peer with send, until it returns. The request arguments are passed
+// JSON encoded, as given to OpenStream.
+type StreamHandler func(ctx netctx.Context, args []byte, send func([]byte) error) error
+
+var (
+	streamHandlers     = make(map[string]StreamHandler)
+	streamHandlersLock sync.RWMutex
+)
+
+// RegisterStreamHandler registers a stream handler with the given name, which
+// peers can open streams to with OpenStream. This can be used to tail logs,
+// transfer statedumps or report progress of long running operations.
+func RegisterStreamHandler(name string, h StreamHandler) {
+	streamHandlersLock.Lock()
+	defer streamHandlersLock.Unlock()
+
+	streamHandlers[name] = h
+}
+
+type streamSvc int
+
+func init() {
+	Register(new(streamSvc))
+}
+
+// Stream runs the requested stream handler, sending its data to the peer
+func (s *streamSvc) Stream(req *StreamReq, stream StreamService_StreamServer) error {
+	streamHandlersLock.RLock()
+	h, ok := streamHandlers[req.Name]
+	streamHandlersLock.RUnlock()
+
+	if !ok {
+		return grpc.Errorf(codes.NotFound, "stream handler %s not found", req.Name)
+	}
+
+	send := func(data []byte) error {
+		return stream.Send(&StreamMsg{Data: data})
+	}
+
+	if err := h(stream.Context(), req.Args, send); err != nil {
+		log.WithError(err).WithField("stream", req.Name).Debug("stream handler failed")
+		return err
+	}
+	return nil
+}
+
+// RegisterService registers streamSvc with the given grpc.Server
+func (s *streamSvc) RegisterService(server *grpc.Server) {
+	RegisterStreamServiceServer(server, s)
+}
+
+// Stream is a stream of data from a peer
+type Stream struct {
+	client StreamService_StreamClient
+	cancel netctx.CancelFunc
+}
+
+// OpenStream opens a stream to the handler with the given name on the peer at
+// the given address. The arguments are JSON encoded and passed to the
+// handler. The stream is closed when ctx is done, or with Close.
+func OpenStream(ctx netctx.Context, address, name string, args interface{}) (*Stream, error) {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := Conn(address)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := netctx.WithCancel(ctx)
+	client, err := NewStreamServiceClient(conn).Stream(ctx, &StreamReq{Name: name, Args: data})
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	return &Stream{client, cancel}, nil
+}
+
+// Recv returns the next data sent by the peer. io.EOF is returned once the
+// handler on the peer has returned successfully.
+func (s *Stream) Recv() ([]byte, error) {
+	msg, err := s.client.Recv()
+	if err != nil {
+		if err != io.EOF {
+			s.cancel()
+		}
+		return nil, err
+	}
+	return msg.Data, nil
+}
+
+// Close closes the stream, stopping the handler on the peer
+func (s *Stream) Close() {
+	s.cancel()
+}
@@ -5,28 +5,37 @@ import (
 	"net"
 	"net/http"
 
+	"github.com/gluster/glusterd2/gdctx"
+	"github.com/gluster/glusterd2/logging"
 	"github.com/gluster/glusterd2/middleware"
 
-	log "github.com/Sirupsen/logrus"
 	"github.com/gorilla/mux"
 	"github.com/justinas/alice"
 	"github.com/soheilhy/cmux"
 )
 
+var restLog = logging.Subsystem(logging.Rest)
+
 // GDRest is the GlusterD Rest server
 type GDRest struct {
 	Routes   *mux.Router
 	listener net.Listener
+	// name is the name the state of the listener is tracked with
+	name string
+	// handler is Routes with all the middleware applied
+	handler http.Handler
 }
 
 // New returns a GDRest object which can listen on the configured address
 func New(l net.Listener) *GDRest {
 	rest := &GDRest{
-		mux.NewRouter(),
-		l,
+		Routes:   mux.NewRouter(),
+		listener: l,
+		name:     gdctx.RESTListener,
 	}
 
 	rest.registerRoutes()
+	rest.handler = alice.New(middleware.ReqIDGenerator, middleware.LogRequest, middleware.RateLimit, middleware.Tracing).Then(rest.Routes)
 
 	return rest
 }
@@ -36,20 +45,27 @@ func NewMuxed(m cmux.CMux) *GDRest {
 	return New(m.Match(cmux.HTTP1Fast()))
 }
 
+// Handler returns the handler serving the REST API, with all the middleware
+// applied
+func (r *GDRest) Handler() http.Handler {
+	return r.handler
+}
+
 // Serve begins serving client HTTP requests served by REST server
 func (r *GDRest) Serve() {
-	chain := alice.New(middleware.LogRequest, middleware.ReqIDGenerator).Then(r.Routes)
-	log.WithField("ip:port", r.listener.Addr().String()).Info("Started GlusterD ReST server")
-	if err := http.Serve(r.listener, chain); err != nil {
+	restLog.WithField("ip:port", r.listener.Addr().String()).Info("Started GlusterD ReST server")
+	gdctx.SetListenerState(r.name, true)
+	defer gdctx.SetListenerState(r.name, false)
+	if err := http.Serve(r.listener, r.handler); err != nil {
 		//TODO: Correctly handle valid errors. We could also be having errors when stopping
-		log.WithError(err).Error("GlusterD ReST server failed")
+		restLog.WithError(err).Error("GlusterD ReST server failed")
 	}
 	return
 }
 
 // Stop stops the GlusterD Rest server
 func (r *GDRest) Stop() {
-	log.Debug("stopping the GlusterD ReST server")
+	restLog.Debug("stopping the GlusterD ReST server")
 	// TODO: Graceful shutdown here
-	log.Info("stopped GlusterD ReST server")
+	restLog.Info("stopped GlusterD ReST server")
 }
@@ -8,11 +8,15 @@ import (
 // Route models a route to be set on the GlusterD Rest server
 // This route style comes from the tutorial on
 // http://thenewstack.io/make-a-restful-json-api-go/
+// Routes with a Version of 0 are not prefixed with a version in the URL.
+// Produces lists the media types of the responses of the route, routes which
+// don't set it respond with JSON.
 type Route struct {
 	Name        string
 	Method      string
 	Pattern     string
 	Version     int
+	Produces    []string
 	HandlerFunc http.HandlerFunc
 }
 
@@ -2,38 +2,94 @@ package rest
 
 import (
 	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
 
 	"github.com/gluster/glusterd2/commands"
+	"github.com/gluster/glusterd2/middleware"
 	"github.com/gluster/glusterd2/plugins"
 	"github.com/gluster/glusterd2/servers/rest/route"
+	restutils "github.com/gluster/glusterd2/servers/rest/utils"
 
 	log "github.com/Sirupsen/logrus"
+	"github.com/justinas/alice"
 )
 
+// errCodeUnsupportedVersion is the error code returned for requests to a
+// version of the API which isn't served
+const errCodeUnsupportedVersion = "unsupported-api-version"
+
+var versionPrefix = regexp.MustCompile(`^/v([0-9]+)/`)
+
+// apiVersions is the set of API versions which have routes registered
+var apiVersions = make(map[int]bool)
+
+// notFoundHandler responds to requests which don't match any route. Requests
+// to an unknown API version are reported as such, so that clients can tell
+// them apart from requests for resources that don't exist.
+func notFoundHandler(w http.ResponseWriter, r *http.Request) {
+	if m := versionPrefix.FindStringSubmatch(r.URL.Path); m != nil {
+		if v, _ := strconv.Atoi(m[1]); !apiVersions[v] {
+			restutils.SendHTTPErrorWithCode(w, http.StatusNotFound, errCodeUnsupportedVersion,
+				fmt.Sprintf("API version v%s is not supported", m[1]))
+			return
+		}
+	}
+	restutils.SendHTTPError(w, http.StatusNotFound, "no such API endpoint")
+}
+
+// producesJSON returns true if the route responds with JSON
+func producesJSON(r route.Route) bool {
+	if len(r.Produces) == 0 {
+		return true
+	}
+	for _, p := range r.Produces {
+		if p == middleware.DefaultMediaType {
+			return true
+		}
+	}
+	return false
+}
+
 // setRoutes adds the given routes to the GlusterD Rest server
 func (r *GDRest) setRoutes(routes route.Routes) {
 	for _, route := range routes {
 		var urlPattern string
-		if route.Name == "GetVersion" {
+		if route.Version == 0 {
 			urlPattern = route.Pattern
 		} else {
 			urlPattern = fmt.Sprintf("/v%d%s", route.Version, route.Pattern)
+			apiVersions[route.Version] = true
 		}
-		log.WithFields(log.Fields{
+		restLog.WithFields(log.Fields{
 			"name":   route.Name,
 			"path":   urlPattern,
 			"method": route.Method,
 		}).Debug("Registering new route")
 
+		// Content negotiation depends on the route, and is done before
+		// the idempotency key of the request is claimed, so that a
+		// rejected request can be retried with the same key. Only JSON
+		// responses are saved to be replayed, archives and streams are
+		// too large for the store.
+		chain := alice.New(middleware.Negotiate(route.Produces...))
+		if producesJSON(route) {
+			chain = chain.Append(middleware.Idempotency)
+		}
+		handler := chain.Then(route.HandlerFunc)
+
 		r.Routes.
 			Methods(route.Method).
 			Path(urlPattern).
 			Name(route.Name).
-			Handler(route.HandlerFunc)
+			Handler(handler)
 	}
 }
 
 func (r *GDRest) registerRoutes() {
+	r.Routes.NotFoundHandler = http.HandlerFunc(notFoundHandler)
+
 	for _, c := range commands.Commands {
 		r.setRoutes(c.Routes())
 		//XXX: This doesn't feel like the right place to be register step
@@ -46,7 +102,7 @@ func (r *GDRest) registerRoutes() {
 		restRoutes := p.RestRoutes()
 		if restRoutes != nil {
 			r.setRoutes(restRoutes)
-			log.WithField("plugin", p.Name()).Debug("loaded REST routes from plugin")
+			restLog.WithField("plugin", p.Name()).Debug("loaded REST routes from plugin")
 		}
 		p.RegisterStepFuncs()
 	}
@@ -0,0 +1,79 @@
+package rest
+
+import (
+	"net"
+	"os"
+
+	"github.com/gluster/glusterd2/gdctx"
+	"github.com/gluster/glusterd2/privileges"
+
+	config "github.com/spf13/viper"
+)
+
+// socketMode is the mode of the REST socket file. Only the user GlusterD runs
+// as and the configured group can connect to it.
+const socketMode = 0660
+
+// UnixServer serves the REST API on a UNIX domain socket, so that local
+// clients can reach GlusterD without going over the network. Access to the
+// API is controlled by the permissions of the socket file.
+type UnixServer struct {
+	*GDRest
+	path string
+}
+
+// NewUnix returns a UnixServer listening on a socket at the given path, serving
+// the routes of the given REST server with its middleware. A socket left
+// behind at the path by a previous GlusterD is replaced.
+func NewUnix(path string, r *GDRest) (*UnixServer, error) {
+	if fi, err := os.Lstat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	gid := -1
+	if group := config.GetString("group"); group != "" {
+		if gid, err = privileges.LookupGroup(group); err != nil {
+			l.Close()
+			return nil, err
+		}
+	}
+	if err := os.Chmod(path, socketMode); err != nil {
+		l.Close()
+		return nil, err
+	}
+	if gid != -1 {
+		if err := os.Lchown(path, -1, gid); err != nil {
+			l.Close()
+			return nil, err
+		}
+	}
+
+	rest := &GDRest{
+		Routes:   r.Routes,
+		listener: l,
+		name:     gdctx.RESTSocketListener,
+		handler:  r.handler,
+	}
+	return &UnixServer{rest, path}, nil
+}
+
+// Stop stops the server and removes the socket. Closing the listener may
+// already have removed the socket, which is not an error.
+func (u *UnixServer) Stop() {
+	restLog.WithField("socket", u.path).Debug("stopping the GlusterD ReST socket server")
+	if err := u.listener.Close(); err != nil {
+		restLog.WithError(err).WithField("socket", u.path).Error("failed to close ReST socket")
+	}
+	if err := os.Remove(u.path); err != nil && !os.IsNotExist(err) {
+		restLog.WithError(err).WithField("socket", u.path).Error("failed to remove ReST socket")
+		return
+	}
+	restLog.WithField("socket", u.path).Info("stopped GlusterD ReST socket server")
+}
@@ -4,6 +4,12 @@ package utils
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
+
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/logging"
+	"github.com/gluster/glusterd2/pkg/api"
+	"github.com/gluster/glusterd2/validation"
 
 	log "github.com/Sirupsen/logrus"
 )
@@ -11,6 +17,24 @@ import (
 // APIError is the placeholder for error string to report back to the client
 type APIError struct {
 	Error string
+	// Code is a machine-readable code for the error
+	Code string `json:"code"`
+	// Errors lists the invalid fields of a request
+	Errors validation.Errors `json:"errors,omitempty"`
+}
+
+// ErrCodeValidationFailed is the error code returned when a request has
+// invalid fields
+const ErrCodeValidationFailed = api.ErrCodeValidationFailed
+
+// ErrorCode returns the machine-readable error code for an HTTP status code,
+// eg. "not-found" for 404
+func ErrorCode(statusCode int) string {
+	text := http.StatusText(statusCode)
+	if text == "" {
+		return "unknown"
+	}
+	return strings.Replace(strings.ToLower(text), " ", "-", -1)
 }
 
 // SendHTTPResponse to send response back to the client
@@ -25,22 +49,49 @@ func SendHTTPResponse(w http.ResponseWriter, statusCode int, rsp interface{}) {
 	w.WriteHeader(statusCode)
 	if rsp != nil {
 		if e := json.NewEncoder(w).Encode(rsp); e != nil {
-			log.WithField("error", e).Error("Failed to send the response -", rsp)
+			logging.Subsystem(logging.Rest).WithField("error", e).Error("Failed to send the response -", rsp)
 		}
 	}
 	return
 }
 
-// SendHTTPError is to report error back to the client
+// SendHTTPError is to report error back to the client. The error code is
+// that of the error with the given message, or is derived from the status
+// code for errors without a code.
 func SendHTTPError(rw http.ResponseWriter, statusCode int, errMsg string) {
-	bytes, _ := json.Marshal(APIError{Error: errMsg})
+	code := errors.CodeOf(errMsg)
+	if code == "" {
+		code = ErrorCode(statusCode)
+	}
+	sendHTTPError(rw, statusCode, APIError{Error: errMsg, Code: code})
+}
+
+// SendHTTPErrorWithCode reports an error with a specific error code back to
+// the client
+func SendHTTPErrorWithCode(rw http.ResponseWriter, statusCode int, code string, errMsg string) {
+	sendHTTPError(rw, statusCode, APIError{Error: errMsg, Code: code})
+}
+
+// SendValidationErrors reports the invalid fields of a request back to the
+// client
+func SendValidationErrors(rw http.ResponseWriter, errs validation.Errors) {
+	sendHTTPError(rw, http.StatusBadRequest, APIError{
+		Error:  "invalid request: " + errs.Error(),
+		Code:   ErrCodeValidationFailed,
+		Errors: errs,
+	})
+}
+
+func sendHTTPError(rw http.ResponseWriter, statusCode int, apiErr APIError) {
+	bytes, _ := json.Marshal(apiErr)
+	rw.Header().Set("Content-Type", "application/json; charset=UTF-8")
 	rw.WriteHeader(statusCode)
 	rw.Write(bytes)
 }
 
-// GetReqIDandLogger returns a request ID and a request-scoped logger having
-// the request ID as a logging field.
+// GetReqIDandLogger returns a request ID and a request-scoped logger of the
+// rest subsystem having the request ID as a logging field.
 func GetReqIDandLogger(r *http.Request) (string, *log.Entry) {
 	reqID := r.Header.Get("X-Request-ID")
-	return reqID, log.WithField("reqid", reqID)
+	return reqID, logging.Subsystem(logging.Rest).WithField("reqid", reqID)
 }
@@ -4,8 +4,10 @@ package servers
 import (
 	"github.com/gluster/glusterd2/servers/muxsrv"
 	"github.com/gluster/glusterd2/servers/peerrpc"
+	"github.com/gluster/glusterd2/servers/rest"
 
 	log "github.com/Sirupsen/logrus"
+	config "github.com/spf13/viper"
 	"github.com/thejerf/suture"
 )
 
@@ -17,7 +19,19 @@ func New() *suture.Supervisor {
 
 	s := suture.New("gd2-servers", suture.Spec{Log: logger})
 	s.Add(peerrpc.New()) // grpc
-	s.Add(muxsrv.New())  // sunrpc + http
+	mux, r := muxsrv.New()
+	s.Add(mux) // sunrpc + http
+
+	// The REST API is also served on a local socket, by the handler of the
+	// multiplexed REST server. GlusterD can run without it, so failing to
+	// create it is not fatal.
+	if sock := config.GetString("restsocket"); sock != "" {
+		if u, err := rest.NewUnix(sock, r); err != nil {
+			log.WithError(err).WithField("socket", sock).Error("failed to create ReST socket")
+		} else {
+			s.Add(u)
+		}
+	}
 
 	return s
 }
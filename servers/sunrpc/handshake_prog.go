@@ -1,14 +1,13 @@
 package sunrpc
 
 import (
-	"context"
 	"fmt"
 	"io/ioutil"
 	"path"
 	"strings"
 
-	"github.com/gluster/glusterd2/store"
 	"github.com/gluster/glusterd2/utils"
+	"github.com/gluster/glusterd2/volgen"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/prashanthpai/sunrpc"
@@ -23,8 +22,6 @@ const (
 	gfHndskGetSpec = 2 // GF_HNDSK_GETSPEC
 )
 
-var volfilePrefix = store.GlusterPrefix + "volfiles/"
-
 // GfHandshake is a type for GlusterFS Handshake RPC program
 type GfHandshake genericProgram
 
@@ -105,18 +102,17 @@ func (p *GfHandshake) ServerGetspec(args *GfGetspecReq, reply *GfGetspecRsp) err
 		log.Info(fileContents)
 	} else {
 		// client volfile
-		resp, err := store.Store.Get(context.TODO(), volfilePrefix+args.Key)
+		var found bool
+		fileContents, found, err = volgen.GetClientVolfile(args.Key)
 		if err != nil {
 			log.WithError(err).Error("ServerGetspec(): failed to retrive client volfile from store")
 			goto Out
 		}
 
-		if resp.Count != 1 {
+		if !found {
 			log.WithField("volume", args.Key).Error("ServerGetspec(): client volfile not found in store")
 			goto Out
 		}
-
-		fileContents = resp.Kvs[0].Value
 	}
 
 	reply.Spec = string(fileContents)
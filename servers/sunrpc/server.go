@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"sync"
 
+	"github.com/gluster/glusterd2/gdctx"
 	"github.com/gluster/glusterd2/plugins"
 	"github.com/gluster/glusterd2/pmap"
 
@@ -110,6 +111,9 @@ func (s *SunRPC) Serve() {
 	}()
 
 	log.WithField("ip:port", s.listener.Addr().String()).Info("started GlusterD SunRPC server")
+	gdctx.SetListenerState(gdctx.SunRPCListener, true)
+	defer gdctx.SetListenerState(gdctx.SunRPCListener, false)
+
 	for {
 		select {
 		case <-s.stop:
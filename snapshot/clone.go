@@ -0,0 +1,127 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+
+	"github.com/gluster/glusterd2/gdctx"
+	"github.com/gluster/glusterd2/store"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/coreos/etcd/clientv3"
+	"github.com/pborman/uuid"
+	config "github.com/spf13/viper"
+)
+
+const clonePrefix = store.GlusterPrefix + "snapshot-clones/"
+
+// Clone records the logical volumes GlusterD created for the bricks of a
+// volume, so that they can be mounted again when GlusterD restarts and removed
+// along with the volume. The bricks of volumes cloned from a snapshot, and of
+// volumes created by the provisioner, are on logical volumes of their own.
+// Snapshot is empty for provisioned volumes.
+type Clone struct {
+	Volume   string       `json:"volume"`
+	Snapshot string       `json:"snapshot"`
+	Bricks   []CloneBrick `json:"bricks"`
+}
+
+// CloneBrick is the logical volume of a brick of a cloned volume
+type CloneBrick struct {
+	NodeID     uuid.UUID `json:"node-id"`
+	VG         string    `json:"vg"`
+	LV         string    `json:"lv"`
+	FSType     string    `json:"fstype"`
+	MountPoint string    `json:"mount-point"`
+}
+
+func cloneKey(volname string) string {
+	return clonePrefix + volname
+}
+
+// CloneLVName returns the name of the logical volume holding the brick with
+// the given index of a cloned volume
+func CloneLVName(volID uuid.UUID, index int) string {
+	return lvName("clone", volID, index)
+}
+
+// BrickLVName returns the name of the logical volume holding the brick with
+// the given index of a provisioned volume
+func BrickLVName(volID uuid.UUID, index int) string {
+	return lvName("brick", volID, index)
+}
+
+// CloneMountPoint returns where the brick with the given index of a cloned
+// volume is mounted
+func CloneMountPoint(volname string, index int) string {
+	return path.Join(config.GetString("rundir"), "snaps", volname, fmt.Sprintf("brick%d", index))
+}
+
+// GetClone returns the clone record of the volume, or nil if the volume
+// wasn't cloned from a snapshot
+func GetClone(volname string) (*Clone, error) {
+	resp, err := store.Store.Get(context.TODO(), cloneKey(volname))
+	if err != nil {
+		return nil, err
+	}
+	if resp.Count != 1 {
+		return nil, nil
+	}
+
+	var c Clone
+	if err := json.Unmarshal(resp.Kvs[0].Value, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// SaveClone saves the clone record in the store
+func SaveClone(c *Clone) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	_, err = store.Store.Put(context.TODO(), cloneKey(c.Volume), string(data))
+	return err
+}
+
+// DeleteClone deletes the clone record of the volume from the store
+func DeleteClone(volname string) error {
+	_, err := store.Store.Delete(context.TODO(), cloneKey(volname))
+	return err
+}
+
+// MountClones mounts the bricks of the cloned and provisioned volumes on this
+// node, whose mounts are not in fstab and so don't survive a reboot
+func MountClones() error {
+	resp, err := store.Store.Get(context.TODO(), clonePrefix, clientv3.WithPrefix())
+	if err != nil {
+		return err
+	}
+
+	for _, kv := range resp.Kvs {
+		var c Clone
+		if err := json.Unmarshal(kv.Value, &c); err != nil {
+			return err
+		}
+		for _, b := range c.Bricks {
+			if !uuid.Equal(b.NodeID, gdctx.MyUUID) {
+				continue
+			}
+			logger := log.WithFields(log.Fields{
+				"volume":      c.Volume,
+				"mount-point": b.MountPoint,
+			})
+			if err := ActivateLV(b.VG, b.LV); err != nil {
+				logger.WithError(err).Error("failed to activate logical volume of cloned brick")
+				continue
+			}
+			if err := Mount(&LV{VG: b.VG, Name: b.LV}, b.MountPoint, b.FSType); err != nil {
+				logger.WithError(err).Error("failed to mount cloned brick")
+			}
+		}
+	}
+	return nil
+}
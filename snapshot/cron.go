@@ -0,0 +1,151 @@
+package snapshot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronField is the set of values a field of a cron expression matches
+type cronField uint64
+
+// cronFieldRanges are the ranges of the minute, hour, day of month, month and
+// day of week fields of cron expressions
+var cronFieldRanges = [5]struct{ min, max int }{
+	{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7},
+}
+
+// CronSchedule is a parsed cron expression with the minute, hour, day of
+// month, month and day of week fields. Every field is a comma separated list
+// of values, ranges and "*", which can have a "/step".
+type CronSchedule struct {
+	minute, hour, dom, month, dow cronField
+	// domAny and dowAny are set if the day fields are "*". As in cron,
+	// a day matches if either of the day fields match, unless one of them
+	// is "*".
+	domAny, dowAny bool
+}
+
+func (f cronField) has(v int) bool {
+	return f&(1<<uint(v)) != 0
+}
+
+// parseCronField parses a field of a cron expression with values in the
+// range [min, max]
+func parseCronField(s string, min, max int) (cronField, error) {
+	var f cronField
+	for _, part := range strings.Split(s, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+			step = n
+			part = part[:i]
+		}
+
+		lo, hi := min, max
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if lo, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, fmt.Errorf("invalid value %q", part)
+			}
+			hi = lo
+			if len(bounds) == 2 {
+				if hi, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, fmt.Errorf("invalid range %q", part)
+				}
+			} else if step != 1 {
+				// "n/step" starts at n and runs to the end
+				hi = max
+			}
+			if lo < min || hi > max || lo > hi {
+				return 0, fmt.Errorf("%q is out of the range %d-%d", part, min, max)
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			f |= 1 << uint(v)
+		}
+	}
+	return f, nil
+}
+
+// ParseCron parses a cron expression made of the minute, hour, day of month,
+// month and day of week fields, like "0 */6 * * *"
+func ParseCron(expr string) (*CronSchedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression must have 5 fields, has %d", len(fields))
+	}
+
+	var parsed [5]cronField
+	for i, field := range fields {
+		f, err := parseCronField(field, cronFieldRanges[i].min, cronFieldRanges[i].max)
+		if err != nil {
+			return nil, err
+		}
+		parsed[i] = f
+	}
+
+	s := &CronSchedule{
+		minute: parsed[0],
+		hour:   parsed[1],
+		dom:    parsed[2],
+		month:  parsed[3],
+		dow:    parsed[4],
+		domAny: fields[2] == "*",
+		dowAny: fields[4] == "*",
+	}
+	// Sunday is both 0 and 7
+	if s.dow.has(7) {
+		s.dow |= 1
+	}
+	return s, nil
+}
+
+func (s *CronSchedule) matchesDay(t time.Time) bool {
+	dom := s.dom.has(t.Day())
+	dow := s.dow.has(int(t.Weekday()))
+	switch {
+	case s.domAny && s.dowAny:
+		return true
+	case s.domAny:
+		return dow
+	case s.dowAny:
+		return dom
+	}
+	return dom || dow
+}
+
+// Next returns the first time matching the schedule after t, in the location
+// of t. The zero time is returned if nothing matches within five years, as
+// happens with schedules like "0 0 31 2 *".
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	end := t.AddDate(5, 0, 0)
+
+	for t.Before(end) {
+		if !s.month.has(int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.hour.has(t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.minute.has(t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
@@ -0,0 +1,50 @@
+package snapshot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gluster/glusterd2/tests"
+)
+
+func TestParseCron(t *testing.T) {
+	for _, expr := range []string{"* * * * *", "0 */6 * * *", "15,45 8-18 * * 1-5", "0 0 1 */3 *", "30 2 * * 7"} {
+		_, err := ParseCron(expr)
+		tests.Assert(t, err == nil)
+	}
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "5-1 * * * *", "*/0 * * * *", "a * * * *"} {
+		_, err := ParseCron(expr)
+		tests.Assert(t, err != nil)
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	at := func(s string) time.Time {
+		v, err := time.Parse("2006-01-02 15:04", s)
+		tests.Assert(t, err == nil)
+		return v
+	}
+	cases := []struct {
+		expr, from, next string
+	}{
+		{"* * * * *", "2017-10-20 10:00", "2017-10-20 10:01"},
+		{"0 */6 * * *", "2017-10-20 10:00", "2017-10-20 12:00"},
+		{"0 */6 * * *", "2017-10-20 23:30", "2017-10-21 00:00"},
+		// Fridays at 18:30
+		{"30 18 * * 5", "2017-10-20 18:30", "2017-10-27 18:30"},
+		// Sunday as 7
+		{"0 0 * * 7", "2017-10-20 10:00", "2017-10-22 00:00"},
+		{"0 0 1 */3 *", "2017-10-20 10:00", "2018-01-01 00:00"},
+		// Either day field matches when both are set
+		{"0 0 1 * 0", "2017-10-20 10:00", "2017-10-22 00:00"},
+	}
+	for _, c := range cases {
+		s, err := ParseCron(c.expr)
+		tests.Assert(t, err == nil)
+		tests.Assert(t, s.Next(at(c.from)).Equal(at(c.next)))
+	}
+
+	s, err := ParseCron("0 0 31 2 *")
+	tests.Assert(t, err == nil)
+	tests.Assert(t, s.Next(at("2017-10-20 10:00")).IsZero())
+}
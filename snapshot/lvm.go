@@ -0,0 +1,235 @@
+package snapshot
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/utils"
+
+	"golang.org/x/sys/unix"
+)
+
+// runLVM runs an LVM command and returns its output. It is a variable so that
+// tests can stub it.
+var runLVM = func(cmd string, args ...string) ([]byte, error) {
+	out, err := exec.Command(cmd, args...).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("%s %s failed: %s", cmd, strings.Join(args, " "), strings.TrimSpace(string(out)))
+	}
+	return out, nil
+}
+
+// LV is a logical volume
+type LV struct {
+	VG   string
+	Name string
+	// Pool is the thin pool the volume is allocated from, if it is
+	// thinly provisioned
+	Pool string
+}
+
+// Path returns the device path of the logical volume
+func (lv *LV) Path() string {
+	return "/dev/" + lv.VG + "/" + lv.Name
+}
+
+// parseLV parses the output of lvs for a single logical volume, with the
+// vg_name, lv_name and pool_lv fields separated by colons
+func parseLV(out []byte) (*LV, error) {
+	fields := strings.Split(strings.TrimSpace(string(out)), ":")
+	if len(fields) != 3 || fields[0] == "" || fields[1] == "" {
+		return nil, fmt.Errorf("unexpected output of lvs: %q", strings.TrimSpace(string(out)))
+	}
+	return &LV{VG: fields[0], Name: fields[1], Pool: fields[2]}, nil
+}
+
+// getLV returns the logical volume of the given device
+func getLV(device string) (*LV, error) {
+	out, err := runLVM("lvs", "--noheadings", "--separator", ":", "-o", "vg_name,lv_name,pool_lv", device)
+	if err != nil {
+		return nil, err
+	}
+	return parseLV(out)
+}
+
+// BrickLV returns the thin logical volume the brick is on, along with the
+// mount entry of its filesystem
+func BrickLV(brickPath string) (*LV, *utils.MountInfo, error) {
+	m, err := utils.GetMountInfo(brickPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	lv, err := getLV(m.Device)
+	if err != nil {
+		return nil, nil, errors.ErrBrickNotThinLV
+	}
+	if lv.Pool == "" {
+		return nil, nil, errors.ErrBrickNotThinLV
+	}
+	return lv, m, nil
+}
+
+// CreateSnapshotLV creates a thin snapshot of the logical volume. The
+// snapshot is not activated.
+func CreateSnapshotLV(lv *LV, name string) error {
+	_, err := runLVM("lvcreate", "--snapshot", "--name", name, lv.VG+"/"+lv.Name)
+	return err
+}
+
+// CreateCloneLV creates a writable thin snapshot of a snapshot, and activates
+// it. The clone shares blocks with the snapshot in the thin pool, but is
+// otherwise independent of it.
+func CreateCloneLV(vg, snapLV, name string) error {
+	_, err := runLVM("lvcreate", "--snapshot", "--setactivationskip", "n", "--activate", "y",
+		"--name", name, vg+"/"+snapLV)
+	return err
+}
+
+// ActivateLV activates the logical volume, if it isn't active
+func ActivateLV(vg, name string) error {
+	_, err := runLVM("lvchange", "--activate", "y", vg+"/"+name)
+	return err
+}
+
+// LVExists returns true if the logical volume exists
+func LVExists(vg, name string) bool {
+	_, err := runLVM("lvs", vg+"/"+name)
+	return err == nil
+}
+
+// RemoveLV removes the logical volume. Removing a logical volume which doesn't
+// exist succeeds.
+func RemoveLV(vg, name string) error {
+	if !LVExists(vg, name) {
+		return nil
+	}
+	_, err := runLVM("lvremove", "--force", vg+"/"+name)
+	return err
+}
+
+// mountOptions returns the options the snapshot of a filesystem of the given
+// type is mounted with. XFS refuses to mount filesystems with the UUID of a
+// mounted filesystem, which snapshots share with their origin.
+func mountOptions(fstype string) string {
+	if fstype == "xfs" {
+		return "nouuid"
+	}
+	return ""
+}
+
+// Mount mounts the logical volume at the mount point, creating the mount
+// point if needed. Mounting a volume which is already mounted there succeeds.
+func Mount(lv *LV, mountPoint, fstype string) error {
+	if m, err := utils.GetMountInfo(mountPoint); err == nil && m.MountPoint == mountPoint {
+		return nil
+	}
+	if err := utils.InitDir(mountPoint); err != nil {
+		return err
+	}
+
+	args := []string{"-t", fstype}
+	if opts := mountOptions(fstype); opts != "" {
+		args = append(args, "-o", opts)
+	}
+	args = append(args, lv.Path(), mountPoint)
+	_, err := runLVM("mount", args...)
+	return err
+}
+
+// Unmount unmounts the filesystem at the mount point, if it is mounted
+func Unmount(mountPoint string) error {
+	m, err := utils.GetMountInfo(mountPoint)
+	if err != nil || m.MountPoint != filepath.Clean(mountPoint) {
+		return nil
+	}
+	return unix.Unmount(mountPoint, 0)
+}
+
+// ParseThinPool parses a thin pool given as vg/pool
+func ParseThinPool(s string) (*LV, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("thin pool %q is not of the form vg/pool", s)
+	}
+	return &LV{VG: parts[0], Name: parts[1]}, nil
+}
+
+// parseSizeAndUsage parses the output of lvs for a single logical volume, with
+// the lv_size in bytes and data_percent fields separated by colons. The usage
+// is empty for volumes which aren't thin pools or thin volumes.
+func parseSizeAndUsage(out []byte) (uint64, float64, error) {
+	fields := strings.Split(strings.TrimSpace(string(out)), ":")
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("unexpected output of lvs: %q", strings.TrimSpace(string(out)))
+	}
+	size, err := strconv.ParseUint(strings.TrimSpace(fields[0]), 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	var percent float64
+	if p := strings.TrimSpace(fields[1]); p != "" {
+		if percent, err = strconv.ParseFloat(p, 64); err != nil {
+			return 0, 0, err
+		}
+	}
+	return size, percent, nil
+}
+
+func lvSizeAndUsage(vg, name string) (uint64, float64, error) {
+	out, err := runLVM("lvs", "--noheadings", "--units", "b", "--nosuffix", "--separator", ":",
+		"-o", "lv_size,data_percent", vg+"/"+name)
+	if err != nil {
+		return 0, 0, err
+	}
+	return parseSizeAndUsage(out)
+}
+
+// ThinPoolUsage returns the size of the thin pool, and how much of it is used
+// by the data of its thin volumes, in bytes
+func ThinPoolUsage(pool *LV) (uint64, uint64, error) {
+	size, percent, err := lvSizeAndUsage(pool.VG, pool.Name)
+	if err != nil {
+		return 0, 0, err
+	}
+	return size, uint64(float64(size) * percent / 100), nil
+}
+
+// CreateThinLV creates a thin logical volume of the given size in bytes in the
+// thin pool. Blocks are only allocated from the pool when they are written to.
+func CreateThinLV(pool *LV, name string, size uint64) error {
+	_, err := runLVM("lvcreate", "--thin", "--virtualsize", fmt.Sprintf("%dB", size),
+		"--name", name, pool.VG+"/"+pool.Name)
+	return err
+}
+
+// ExtendLV grows the logical volume to the given size in bytes. Volumes which
+// are already as large are left as is.
+func ExtendLV(vg, name string, size uint64) error {
+	current, _, err := lvSizeAndUsage(vg, name)
+	if err != nil {
+		return err
+	}
+	if current >= size {
+		return nil
+	}
+	_, err = runLVM("lvextend", "--size", fmt.Sprintf("%dB", size), vg+"/"+name)
+	return err
+}
+
+// MakeFS creates an XFS filesystem on the logical volume, with the inode size
+// recommended for bricks so that the gluster xattrs fit in the inodes
+func MakeFS(lv *LV) error {
+	_, err := runLVM("mkfs.xfs", "-i", "size=512", lv.Path())
+	return err
+}
+
+// GrowFS grows the XFS filesystem mounted at the mount point to the size of
+// its device
+func GrowFS(mountPoint string) error {
+	_, err := runLVM("xfs_growfs", mountPoint)
+	return err
+}
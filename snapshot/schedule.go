@@ -0,0 +1,81 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/gluster/glusterd2/store"
+
+	"github.com/coreos/etcd/clientv3"
+)
+
+const schedulePrefix = store.GlusterPrefix + "snapshot-schedules/"
+
+// Schedule is the snapshot policy of a volume. Snapshots of the volume are
+// taken at the times matching the cron expression, and only the newest Retain
+// scheduled snapshots are kept.
+type Schedule struct {
+	Volume    string    `json:"volume"`
+	Schedule  string    `json:"schedule"`
+	Retain    int       `json:"retain"`
+	CreatedAt time.Time `json:"created-at"`
+	// LastRun is when the scheduler last took a snapshot of the volume
+	LastRun time.Time `json:"last-run"`
+}
+
+func scheduleKey(volname string) string {
+	return schedulePrefix + volname
+}
+
+// GetSchedule returns the snapshot schedule of the volume, or nil if the
+// volume has none
+func GetSchedule(volname string) (*Schedule, error) {
+	resp, err := store.Store.Get(context.TODO(), scheduleKey(volname))
+	if err != nil {
+		return nil, err
+	}
+	if resp.Count != 1 {
+		return nil, nil
+	}
+
+	var s Schedule
+	if err := json.Unmarshal(resp.Kvs[0].Value, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// GetSchedules returns the snapshot schedules of all volumes
+func GetSchedules() ([]*Schedule, error) {
+	resp, err := store.Store.Get(context.TODO(), schedulePrefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+
+	schedules := make([]*Schedule, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		var s Schedule
+		if err := json.Unmarshal(kv.Value, &s); err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, &s)
+	}
+	return schedules, nil
+}
+
+// SaveSchedule saves the snapshot schedule in the store
+func SaveSchedule(s *Schedule) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	_, err = store.Store.Put(context.TODO(), scheduleKey(s.Volume), string(data))
+	return err
+}
+
+// DeleteSchedule deletes the snapshot schedule of the volume from the store
+func DeleteSchedule(volname string) error {
+	_, err := store.Store.Delete(context.TODO(), scheduleKey(volname))
+	return err
+}
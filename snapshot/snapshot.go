@@ -0,0 +1,150 @@
+// Package snapshot manages snapshots of volumes. A snapshot of a volume is
+// made of a thin LVM snapshot of the logical volume of each of its bricks, so
+// snapshots can only be taken of volumes with all their bricks on thinly
+// provisioned logical volumes.
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/store"
+	"github.com/gluster/glusterd2/store/schema"
+	"github.com/gluster/glusterd2/volume"
+
+	"github.com/coreos/etcd/clientv3"
+	"github.com/pborman/uuid"
+)
+
+const (
+	snapshotPrefix = store.GlusterPrefix + "snapshots/"
+
+	// SchemaKind is the kind of snapinfo objects for schema versioning.
+	// Snapshots embed the volinfo of their volume, so a change to the
+	// volinfo schema needs a migration of snapinfo objects as well.
+	SchemaKind = "snapinfo"
+)
+
+// Snapinfo represents a snapshot of a volume
+type Snapinfo struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created-at"`
+	// Scheduled is set for snapshots taken by the snapshot scheduler,
+	// which are pruned as newer ones are taken
+	Scheduled bool `json:"scheduled,omitempty"`
+	// Volinfo is the volume as it was when the snapshot was taken
+	Volinfo volume.Volinfo `json:"volinfo"`
+	Bricks  []SnapBrick    `json:"bricks"`
+}
+
+// SnapBrick is the snapshot of a single brick of the volume
+type SnapBrick struct {
+	NodeID uuid.UUID `json:"node-id"`
+	// Path is the path of the brick of the volume
+	Path string `json:"path"`
+	// VG and LV name the logical volume of the snapshot
+	VG string `json:"vg"`
+	LV string `json:"lv"`
+	// FSType is the filesystem of the brick
+	FSType string `json:"fstype"`
+	// Dir is the path of the brick relative to the mount point of its
+	// filesystem
+	Dir string `json:"dir"`
+}
+
+func snapshotKey(name string) string {
+	return snapshotPrefix + name
+}
+
+// lvName returns the name of the logical volume holding the snapshot of the
+// brick with the given index. LVM names can't have dashes in a row, so they
+// are dropped from the ID.
+func lvName(prefix string, id uuid.UUID, index int) string {
+	return fmt.Sprintf("%s_%s_%d", prefix, strings.Replace(id.String(), "-", "", -1), index)
+}
+
+// SnapLVName returns the name of the logical volume holding the snapshot of
+// the brick with the given index
+func SnapLVName(snapID uuid.UUID, index int) string {
+	return lvName("snap", snapID, index)
+}
+
+// GetSnapshot returns the snapshot with the given name
+func GetSnapshot(name string) (*Snapinfo, error) {
+	resp, err := store.Store.Get(context.TODO(), snapshotKey(name))
+	if err != nil {
+		return nil, err
+	}
+	if resp.Count != 1 {
+		return nil, errors.ErrSnapNotFound
+	}
+
+	var s Snapinfo
+	if err := schema.Unmarshal(SchemaKind, resp.Kvs[0].Value, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// GetSnapshots returns the snapshots of the volume, or of all volumes if
+// volname is empty, oldest first
+func GetSnapshots(volname string) ([]*Snapinfo, error) {
+	resp, err := store.Store.Get(context.TODO(), snapshotPrefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+
+	snaps := make([]*Snapinfo, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		var s Snapinfo
+		if err := schema.Unmarshal(SchemaKind, kv.Value, &s); err != nil {
+			return nil, err
+		}
+		if volname != "" && s.Volinfo.Name != volname {
+			continue
+		}
+		snaps = append(snaps, &s)
+	}
+
+	sort.Sort(snapsByAge(snaps))
+	return snaps, nil
+}
+
+// Exists returns true if a snapshot with the given name exists
+func Exists(name string) bool {
+	_, err := GetSnapshot(name)
+	return err == nil
+}
+
+// AddOrUpdateSnapshot saves the snapshot in the store
+func AddOrUpdateSnapshot(s *Snapinfo) error {
+	data, err := schema.Marshal(SchemaKind, s)
+	if err != nil {
+		return err
+	}
+	_, err = store.Store.Put(context.TODO(), snapshotKey(s.Name), string(data))
+	return err
+}
+
+// DeleteSnapshot deletes the snapshot from the store
+func DeleteSnapshot(name string) error {
+	_, err := store.Store.Delete(context.TODO(), snapshotKey(name))
+	return err
+}
+
+type snapsByAge []*Snapinfo
+
+func (s snapsByAge) Len() int      { return len(s) }
+func (s snapsByAge) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s snapsByAge) Less(i, j int) bool {
+	if !s[i].CreatedAt.Equal(s[j].CreatedAt) {
+		return s[i].CreatedAt.Before(s[j].CreatedAt)
+	}
+	return s[i].Name < s[j].Name
+}
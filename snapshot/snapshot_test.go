@@ -0,0 +1,111 @@
+package snapshot
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gluster/glusterd2/tests"
+
+	heketitests "github.com/heketi/tests"
+	"github.com/pborman/uuid"
+)
+
+func TestParseLV(t *testing.T) {
+	lv, err := parseLV([]byte("  vg_bricks:brick1:pool0\n"))
+	tests.Assert(t, err == nil)
+	tests.Assert(t, lv.VG == "vg_bricks" && lv.Name == "brick1" && lv.Pool == "pool0")
+	tests.Assert(t, lv.Path() == "/dev/vg_bricks/brick1")
+
+	// Thick volumes have no pool
+	lv, err = parseLV([]byte("  vg_bricks:brick1:\n"))
+	tests.Assert(t, err == nil)
+	tests.Assert(t, lv.Pool == "")
+
+	_, err = parseLV([]byte(""))
+	tests.Assert(t, err != nil)
+}
+
+func TestLVNames(t *testing.T) {
+	id := uuid.Parse("6d3c6c04-1d1c-4e43-8a4d-2f5e5c1a7b9e")
+	tests.Assert(t, SnapLVName(id, 2) == "snap_6d3c6c041d1c4e438a4d2f5e5c1a7b9e_2")
+	tests.Assert(t, CloneLVName(id, 0) == "clone_6d3c6c041d1c4e438a4d2f5e5c1a7b9e_0")
+	tests.Assert(t, BrickLVName(id, 1) == "brick_6d3c6c041d1c4e438a4d2f5e5c1a7b9e_1")
+}
+
+func TestRemoveLV(t *testing.T) {
+	var calls []string
+	exists := false
+	defer heketitests.Patch(&runLVM, func(cmd string, args ...string) ([]byte, error) {
+		calls = append(calls, cmd)
+		if cmd == "lvs" && !exists {
+			return nil, fmt.Errorf("volume not found")
+		}
+		return nil, nil
+	}).Restore()
+
+	// Removing a volume which doesn't exist succeeds
+	tests.Assert(t, RemoveLV("vg", "snap_1") == nil)
+	tests.Assert(t, len(calls) == 1)
+
+	exists = true
+	calls = nil
+	tests.Assert(t, RemoveLV("vg", "snap_1") == nil)
+	tests.Assert(t, len(calls) == 2 && calls[1] == "lvremove")
+}
+
+func TestParseThinPool(t *testing.T) {
+	pool, err := ParseThinPool("vg_bricks/pool0")
+	tests.Assert(t, err == nil)
+	tests.Assert(t, pool.VG == "vg_bricks" && pool.Name == "pool0")
+
+	for _, s := range []string{"", "pool0", "vg/", "/pool0", "vg/pool/x"} {
+		_, err := ParseThinPool(s)
+		tests.Assert(t, err != nil)
+	}
+}
+
+func TestParseSizeAndUsage(t *testing.T) {
+	size, percent, err := parseSizeAndUsage([]byte("  107374182400:12.50\n"))
+	tests.Assert(t, err == nil)
+	tests.Assert(t, size == 107374182400 && percent == 12.5)
+
+	// Volumes which aren't thin have no usage
+	size, percent, err = parseSizeAndUsage([]byte("  1073741824:\n"))
+	tests.Assert(t, err == nil)
+	tests.Assert(t, size == 1073741824 && percent == 0)
+
+	_, _, err = parseSizeAndUsage([]byte("  1073741824\n"))
+	tests.Assert(t, err != nil)
+	_, _, err = parseSizeAndUsage([]byte("  1G:10\n"))
+	tests.Assert(t, err != nil)
+}
+
+func TestThinPoolUsage(t *testing.T) {
+	defer heketitests.Patch(&runLVM, func(cmd string, args ...string) ([]byte, error) {
+		return []byte("  1000:25.00\n"), nil
+	}).Restore()
+
+	size, used, err := ThinPoolUsage(&LV{VG: "vg", Name: "pool"})
+	tests.Assert(t, err == nil)
+	tests.Assert(t, size == 1000 && used == 250)
+}
+
+func TestExtendLV(t *testing.T) {
+	var calls []string
+	defer heketitests.Patch(&runLVM, func(cmd string, args ...string) ([]byte, error) {
+		calls = append(calls, cmd)
+		if cmd == "lvs" {
+			return []byte("  1000:5.00\n"), nil
+		}
+		return nil, nil
+	}).Restore()
+
+	// Volumes which are already as large are left as is, so that
+	// retries succeed
+	tests.Assert(t, ExtendLV("vg", "brick_1", 1000) == nil)
+	tests.Assert(t, len(calls) == 1)
+
+	calls = nil
+	tests.Assert(t, ExtendLV("vg", "brick_1", 2000) == nil)
+	tests.Assert(t, len(calls) == 2 && calls[1] == "lvextend")
+}
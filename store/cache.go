@@ -0,0 +1,334 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/coreos/etcd/clientv3"
+)
+
+const cacheSyncTimeout = 10 * time.Second
+
+// ErrStoreNotInited is returned when the store is used before it has been
+// initialized
+var ErrStoreNotInited = errors.New("store has not been initialized")
+
+type cacheEntry struct {
+	value   []byte
+	rev     int64
+	deleted bool
+}
+
+// Cache is an in-memory cache of the keys under a prefix in the store. The
+// cache is loaded on first use and kept up to date with a watch on the
+// prefix. Reads are served from the store if the cache cannot be synced.
+//
+// As the watch is asynchronous, a cache may briefly lag behind changes made
+// by other peers. Changes made by this peer should be recorded with Put and
+// Delete to be seen immediately.
+type Cache struct {
+	// hits and misses are accessed atomically and must be 64-bit aligned
+	hits    uint64
+	misses  uint64
+	resyncs uint64
+
+	name   string
+	prefix string
+
+	lock    sync.RWMutex
+	store   *GDStore // the store the cache is synced with
+	entries map[string]*cacheEntry
+	// watchRev is the latest revision seen on the watch. Deleted entries
+	// are kept until the watch has passed them, so that older changes
+	// seen on the watch don't bring the keys back.
+	watchRev int64
+	synced   bool
+	cancel   context.CancelFunc
+}
+
+// CacheStats are the statistics of a cache
+type CacheStats struct {
+	Name    string  `json:"name"`
+	Hits    uint64  `json:"hits"`
+	Misses  uint64  `json:"misses"`
+	HitRate float64 `json:"hit-rate"`
+	Resyncs uint64  `json:"resyncs"`
+	Entries int     `json:"entries"`
+	Synced  bool    `json:"synced"`
+}
+
+var (
+	caches     []*Cache
+	cachesLock sync.Mutex
+)
+
+// NewCache returns a new cache with the given name of the keys under the
+// given prefix
+func NewCache(name, prefix string) *Cache {
+	c := &Cache{name: name, prefix: prefix}
+
+	cachesLock.Lock()
+	caches = append(caches, c)
+	cachesLock.Unlock()
+
+	return c
+}
+
+// AllCacheStats returns the statistics of all caches
+func AllCacheStats() []CacheStats {
+	cachesLock.Lock()
+	defer cachesLock.Unlock()
+
+	stats := make([]CacheStats, 0, len(caches))
+	for _, c := range caches {
+		stats = append(stats, c.Stats())
+	}
+	return stats
+}
+
+// Stats returns the statistics of the cache
+func (c *Cache) Stats() CacheStats {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	entries := 0
+	for _, e := range c.entries {
+		if !e.deleted {
+			entries++
+		}
+	}
+
+	stats := CacheStats{
+		Name:    c.name,
+		Hits:    atomic.LoadUint64(&c.hits),
+		Misses:  atomic.LoadUint64(&c.misses),
+		Resyncs: atomic.LoadUint64(&c.resyncs),
+		Entries: entries,
+		Synced:  c.synced && c.store == Store,
+	}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
+
+// ensureSynced syncs the cache with the current store if required, and
+// returns true if the cache is synced
+func (c *Cache) ensureSynced() bool {
+	c.lock.RLock()
+	synced := c.synced && c.store == Store
+	c.lock.RUnlock()
+
+	if synced {
+		return true
+	}
+
+	if err := c.sync(); err != nil {
+		storeLog.WithError(err).WithField("cache", c.name).Warn("failed to sync cache with store")
+		return false
+	}
+	return true
+}
+
+// sync loads all the keys under the prefix and starts watching for changes
+func (c *Cache) sync() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.synced && c.store == Store {
+		return nil
+	}
+	if c.cancel != nil {
+		c.cancel()
+		c.cancel = nil
+	}
+	c.synced = false
+
+	s := Store
+	if s == nil {
+		return ErrStoreNotInited
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cacheSyncTimeout)
+	resp, err := s.Get(ctx, c.prefix, clientv3.WithPrefix())
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	c.entries = make(map[string]*cacheEntry, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		c.entries[string(kv.Key)] = &cacheEntry{value: kv.Value, rev: kv.ModRevision}
+	}
+	c.watchRev = resp.Header.Revision
+
+	ctx, c.cancel = context.WithCancel(context.Background())
+	wch := s.Watch(ctx, c.prefix, clientv3.WithPrefix(), clientv3.WithRev(resp.Header.Revision+1))
+	go c.watch(s, wch)
+
+	c.store = s
+	c.synced = true
+	atomic.AddUint64(&c.resyncs, 1)
+
+	return nil
+}
+
+// watch applies the changes seen on the watch to the cache. If the watch
+// fails the cache is marked as not synced, to be synced again on next use.
+func (c *Cache) watch(s *GDStore, wch clientv3.WatchChan) {
+	for wresp := range wch {
+		if err := wresp.Err(); err != nil {
+			storeLog.WithError(err).WithField("cache", c.name).Debug("cache watch failed")
+			break
+		}
+
+		c.lock.Lock()
+		if c.store != s {
+			c.lock.Unlock()
+			return
+		}
+		c.applyEvents(wresp.Events, wresp.Header.Revision)
+		c.lock.Unlock()
+	}
+
+	c.lock.Lock()
+	if c.store == s {
+		c.synced = false
+	}
+	c.lock.Unlock()
+}
+
+// applyEvents applies the changes seen on the watch up to the given revision,
+// and prunes the deleted entries the watch has passed. Must be called with the
+// lock held.
+func (c *Cache) applyEvents(events []*clientv3.Event, rev int64) {
+	for _, ev := range events {
+		c.apply(string(ev.Kv.Key), ev.Kv.Value, ev.Kv.ModRevision, ev.Type == clientv3.EventTypeDelete)
+	}
+	if rev <= c.watchRev {
+		return
+	}
+	c.watchRev = rev
+
+	for key, e := range c.entries {
+		if e.deleted && e.rev <= c.watchRev {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// apply applies a change to a key, unless a later change has been applied
+// already. Must be called with the lock held.
+func (c *Cache) apply(key string, value []byte, rev int64, deleted bool) {
+	if e, ok := c.entries[key]; ok && e.rev >= rev {
+		return
+	}
+	c.entries[key] = &cacheEntry{value, rev, deleted}
+}
+
+func (c *Cache) record(key string, value []byte, rev int64, deleted bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.synced && c.store == Store {
+		c.apply(key, value, rev, deleted)
+	}
+}
+
+// Put records a put of the key done by this peer at the given revision
+func (c *Cache) Put(key string, value []byte, rev int64) {
+	c.record(key, value, rev, false)
+}
+
+// Delete records a delete of the key done by this peer at the given revision
+func (c *Cache) Delete(key string, rev int64) {
+	c.record(key, nil, rev, true)
+}
+
+// Invalidate drops the contents of the cache, which is synced with the store
+// again on next use. It repairs a cache which has drifted from the store.
+func (c *Cache) Invalidate() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.cancel != nil {
+		c.cancel()
+		c.cancel = nil
+	}
+	c.synced = false
+}
+
+// Get returns the value of the given key, and if the key was found. Only the
+// keys found in the cache are counted as hits.
+func (c *Cache) Get(key string) ([]byte, bool, error) {
+	if c.ensureSynced() {
+		c.lock.RLock()
+		e, ok := c.entries[key]
+		c.lock.RUnlock()
+
+		if ok && !e.deleted {
+			atomic.AddUint64(&c.hits, 1)
+			return e.value, true, nil
+		}
+		atomic.AddUint64(&c.misses, 1)
+		return nil, false, nil
+	}
+
+	atomic.AddUint64(&c.misses, 1)
+	if Store == nil {
+		return nil, false, ErrStoreNotInited
+	}
+
+	resp, err := Store.Get(context.TODO(), key)
+	if err != nil {
+		return nil, false, err
+	}
+	if resp.Count != 1 {
+		return nil, false, nil
+	}
+	return resp.Kvs[0].Value, true, nil
+}
+
+// List returns the values of all the keys under the prefix of the cache,
+// sorted by key. Lists served by the store are counted as misses.
+func (c *Cache) List() ([][]byte, error) {
+	if c.ensureSynced() {
+		atomic.AddUint64(&c.hits, 1)
+
+		c.lock.RLock()
+		defer c.lock.RUnlock()
+
+		keys := make([]string, 0, len(c.entries))
+		for k, e := range c.entries {
+			if !e.deleted {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		values := make([][]byte, 0, len(keys))
+		for _, k := range keys {
+			values = append(values, c.entries[k].value)
+		}
+		return values, nil
+	}
+
+	atomic.AddUint64(&c.misses, 1)
+	if Store == nil {
+		return nil, ErrStoreNotInited
+	}
+
+	resp, err := Store.Get(context.TODO(), c.prefix, clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByKey, clientv3.SortAscend))
+	if err != nil {
+		return nil, err
+	}
+	values := make([][]byte, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		values = append(values, kv.Value)
+	}
+	return values, nil
+}
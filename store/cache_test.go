@@ -0,0 +1,104 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/gluster/glusterd2/tests"
+
+	"github.com/coreos/etcd/clientv3"
+	"github.com/coreos/etcd/mvcc/mvccpb"
+	heketitests "github.com/heketi/tests"
+)
+
+// syncedCache returns a cache which is synced with the given store, without
+// loading anything from it
+func syncedCache(s *GDStore, entries map[string]*cacheEntry, rev int64) *Cache {
+	return &Cache{
+		name:     "test",
+		prefix:   "test/",
+		store:    s,
+		entries:  entries,
+		watchRev: rev,
+		synced:   true,
+	}
+}
+
+func event(key string, value string, rev int64, deleted bool) *clientv3.Event {
+	ev := &clientv3.Event{
+		Type: clientv3.EventTypePut,
+		Kv:   &mvccpb.KeyValue{Key: []byte(key), Value: []byte(value), ModRevision: rev},
+	}
+	if deleted {
+		ev.Type = clientv3.EventTypeDelete
+	}
+	return ev
+}
+
+func TestCacheHits(t *testing.T) {
+	s := new(GDStore)
+	defer heketitests.Patch(&Store, s).Restore()
+
+	c := syncedCache(s, map[string]*cacheEntry{
+		"test/a": {value: []byte("a"), rev: 1},
+		"test/b": {rev: 2, deleted: true},
+	}, 2)
+
+	value, ok, err := c.Get("test/a")
+	tests.Assert(t, err == nil && ok && string(value) == "a")
+
+	// Keys not found in the cache are misses
+	_, ok, err = c.Get("test/b")
+	tests.Assert(t, err == nil && !ok)
+	_, ok, err = c.Get("test/c")
+	tests.Assert(t, err == nil && !ok)
+
+	values, err := c.List()
+	tests.Assert(t, err == nil && len(values) == 1)
+
+	stats := c.Stats()
+	tests.Assert(t, stats.Hits == 2)
+	tests.Assert(t, stats.Misses == 2)
+	tests.Assert(t, stats.HitRate == 0.5)
+	tests.Assert(t, stats.Entries == 1)
+	tests.Assert(t, stats.Synced)
+}
+
+func TestCacheApplyEvents(t *testing.T) {
+	s := new(GDStore)
+	defer heketitests.Patch(&Store, s).Restore()
+
+	c := syncedCache(s, map[string]*cacheEntry{
+		"test/a": {value: []byte("a"), rev: 1},
+	}, 1)
+
+	c.applyEvents([]*clientv3.Event{event("test/b", "b", 2, false)}, 2)
+	tests.Assert(t, string(c.entries["test/b"].value) == "b")
+
+	// A delete done by this peer is kept as a tombstone until the watch
+	// has passed it, so that older changes don't bring the key back
+	c.Delete("test/a", 4)
+	c.applyEvents([]*clientv3.Event{event("test/a", "a2", 3, false)}, 3)
+	tests.Assert(t, c.entries["test/a"].deleted)
+	_, ok, _ := c.Get("test/a")
+	tests.Assert(t, !ok)
+
+	c.applyEvents([]*clientv3.Event{event("test/a", "", 4, true)}, 4)
+	_, found := c.entries["test/a"]
+	tests.Assert(t, !found)
+	tests.Assert(t, c.watchRev == 4)
+
+	// Puts are not pruned
+	tests.Assert(t, len(c.entries) == 1)
+}
+
+func TestCacheRecordNotSynced(t *testing.T) {
+	s := new(GDStore)
+	defer heketitests.Patch(&Store, s).Restore()
+
+	c := syncedCache(s, map[string]*cacheEntry{}, 1)
+	c.Invalidate()
+
+	c.Put("test/a", []byte("a"), 2)
+	_, found := c.entries["test/a"]
+	tests.Assert(t, !found)
+}
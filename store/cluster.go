@@ -0,0 +1,150 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/coreos/etcd/clientv3"
+	"github.com/coreos/etcd/clientv3/namespace"
+	"github.com/pborman/uuid"
+)
+
+const (
+	// clusterIDKey is the key holding the ID of the cluster which owns the
+	// store. It is the only key stored outside the cluster namespace.
+	clusterIDKey = "gluster-cluster-id"
+
+	clusterIDTimeout = 10 * time.Second
+)
+
+// ErrClusterIDMismatch is returned when the store belongs to a cluster other
+// than the one this peer is a part of
+var ErrClusterIDMismatch = errors.New("store belongs to another cluster")
+
+// ClusterID returns the ID of the cluster which owns the store
+func (s *GDStore) ClusterID() string {
+	return s.conf.ClusterID
+}
+
+// NamespaceKey returns the key in the cluster namespace for the given key.
+// This needs to be used only for keys accessed without using GDStore, for
+// eg. keys of concurrency mutexes.
+func (s *GDStore) NamespaceKey(key string) string {
+	return s.conf.ClusterID + "/" + key
+}
+
+// initClusterID validates that the store belongs to the cluster of this peer,
+// and namespaces all further keys with the cluster ID.
+//
+// If the store doesn't have a cluster ID yet, the cluster ID of this peer is
+// set on it, or a new cluster ID is generated if this peer doesn't have one.
+// If this peer doesn't have a cluster ID, it takes the one of the store.
+func (s *GDStore) initClusterID() error {
+	ctx, cancel := context.WithTimeout(context.Background(), clusterIDTimeout)
+	defer cancel()
+
+	resp, err := s.Client.Get(ctx, clusterIDKey)
+	if err != nil {
+		return err
+	}
+
+	var storeID string
+	if resp.Count == 1 {
+		storeID = string(resp.Kvs[0].Value)
+	}
+
+	switch {
+	case storeID == "":
+		if s.conf.ClusterID == "" {
+			s.conf.ClusterID = uuid.NewRandom().String()
+			storeLog.WithField("cluster-id", s.conf.ClusterID).Info("generated new cluster ID")
+		}
+		// Only set the cluster ID if it hasn't been set by another peer
+		// in the meantime
+		tresp, err := s.Client.Txn(ctx).If(
+			clientv3.Compare(clientv3.CreateRevision(clusterIDKey), "=", 0),
+		).Then(
+			clientv3.OpPut(clusterIDKey, s.conf.ClusterID),
+		).Commit()
+		if err != nil {
+			return err
+		}
+		if !tresp.Succeeded {
+			return ErrClusterIDMismatch
+		}
+		if err := s.moveUnprefixedKeys(ctx); err != nil {
+			storeLog.WithError(err).Error("failed to move existing keys into the cluster namespace")
+			return err
+		}
+
+	case s.conf.ClusterID == "":
+		s.conf.ClusterID = storeID
+
+	case s.conf.ClusterID == storeID:
+		// Finish moving the keys if this peer was interrupted while
+		// moving them. There is nothing left to move otherwise.
+		if err := s.moveUnprefixedKeys(ctx); err != nil {
+			storeLog.WithError(err).Error("failed to move existing keys into the cluster namespace")
+			return err
+		}
+
+	case s.conf.ClusterID != storeID:
+		storeLog.WithFields(log.Fields{
+			"cluster-id":       s.conf.ClusterID,
+			"store-cluster-id": storeID,
+		}).Error("store belongs to another cluster, refusing to use it")
+		return ErrClusterIDMismatch
+	}
+
+	if err := s.conf.Save(); err != nil {
+		return err
+	}
+
+	prefix := s.NamespaceKey("")
+	s.KV = &tracedKV{namespace.NewKV(s.Client.KV, prefix)}
+	s.Watcher = namespace.NewWatcher(s.Client.Watcher, prefix)
+
+	return nil
+}
+
+// moveUnprefixedKeys moves keys stored before the store was namespaced by
+// the cluster ID into the cluster namespace. Keys attached to leases are not
+// moved, as they are recreated by their owners. A key which already exists in
+// the namespace was written after the store was namespaced, and is kept over
+// the unprefixed key.
+func (s *GDStore) moveUnprefixedKeys(ctx context.Context) error {
+	resp, err := s.Client.Get(ctx, GlusterPrefix, clientv3.WithPrefix())
+	if err != nil {
+		return err
+	}
+
+	moved := 0
+	for _, kv := range resp.Kvs {
+		if kv.Lease != 0 {
+			continue
+		}
+		key := string(kv.Key)
+		nsKey := s.NamespaceKey(key)
+		tresp, err := s.Client.Txn(ctx).If(
+			clientv3.Compare(clientv3.CreateRevision(nsKey), "=", 0),
+		).Then(
+			clientv3.OpPut(nsKey, string(kv.Value)),
+			clientv3.OpDelete(key),
+		).Else(
+			clientv3.OpDelete(key),
+		).Commit()
+		if err != nil {
+			return err
+		}
+		if tresp.Succeeded {
+			moved++
+		}
+	}
+
+	if moved > 0 {
+		storeLog.WithField("keys", moved).Info("moved existing keys into the cluster namespace")
+	}
+	return nil
+}
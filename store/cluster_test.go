@@ -0,0 +1,219 @@
+package store
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"testing"
+
+	"github.com/gluster/glusterd2/tests"
+
+	"github.com/coreos/etcd/clientv3"
+	pb "github.com/coreos/etcd/etcdserver/etcdserverpb"
+	"github.com/coreos/etcd/mvcc/mvccpb"
+)
+
+// memKV is an in-memory clientv3.KV, implementing the range gets and the
+// transactions used to set up the cluster namespace
+type memKV struct {
+	clientv3.KV
+	rev  int64
+	kvs  map[string]*mvccpb.KeyValue
+	txns int
+}
+
+func newMemKV() *memKV {
+	return &memKV{rev: 1, kvs: make(map[string]*mvccpb.KeyValue)}
+}
+
+func (m *memKV) put(key, value string, lease int64) {
+	m.rev++
+	kv, ok := m.kvs[key]
+	if !ok {
+		kv = &mvccpb.KeyValue{Key: []byte(key), CreateRevision: m.rev}
+		m.kvs[key] = kv
+	}
+	kv.Value = []byte(value)
+	kv.ModRevision = m.rev
+	kv.Lease = lease
+}
+
+func (m *memKV) value(key string) (string, bool) {
+	kv, ok := m.kvs[key]
+	if !ok {
+		return "", false
+	}
+	return string(kv.Value), true
+}
+
+func (m *memKV) Get(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	op := clientv3.OpGet(key, opts...)
+	begin, end := string(op.KeyBytes()), string(op.RangeBytes())
+
+	var keys []string
+	for k := range m.kvs {
+		if k == begin || (end != "" && k >= begin && k < end) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	resp := &clientv3.GetResponse{Header: &pb.ResponseHeader{Revision: m.rev}, Count: int64(len(keys))}
+	for _, k := range keys {
+		kv := *m.kvs[k]
+		resp.Kvs = append(resp.Kvs, &kv)
+	}
+	return resp, nil
+}
+
+func (m *memKV) Txn(ctx context.Context) clientv3.Txn {
+	return &memTxn{kv: m}
+}
+
+type memTxn struct {
+	kv          *memKV
+	cmps        []clientv3.Cmp
+	thens, elss []clientv3.Op
+}
+
+func (t *memTxn) If(cs ...clientv3.Cmp) clientv3.Txn {
+	t.cmps = append(t.cmps, cs...)
+	return t
+}
+
+func (t *memTxn) Then(ops ...clientv3.Op) clientv3.Txn {
+	t.thens = append(t.thens, ops...)
+	return t
+}
+
+func (t *memTxn) Else(ops ...clientv3.Op) clientv3.Txn {
+	t.elss = append(t.elss, ops...)
+	return t
+}
+
+// Commit supports comparing the create revision of keys, and put and delete
+// operations on single keys
+func (t *memTxn) Commit() (*clientv3.TxnResponse, error) {
+	t.kv.txns++
+
+	succeeded := true
+	for _, c := range t.cmps {
+		var rev int64
+		if kv, ok := t.kv.kvs[string(c.Key)]; ok {
+			rev = kv.CreateRevision
+		}
+		if c.Target != pb.Compare_CREATE || c.Result != pb.Compare_EQUAL {
+			panic("unsupported comparison")
+		}
+		want := c.TargetUnion.(*pb.Compare_CreateRevision).CreateRevision
+		succeeded = succeeded && rev == want
+	}
+
+	ops := t.thens
+	if !succeeded {
+		ops = t.elss
+	}
+	for _, op := range ops {
+		switch {
+		case op.IsPut():
+			t.kv.put(string(op.KeyBytes()), string(op.ValueBytes()), 0)
+		case op.IsDelete():
+			t.kv.rev++
+			delete(t.kv.kvs, string(op.KeyBytes()))
+		default:
+			panic("unsupported operation")
+		}
+	}
+
+	return &clientv3.TxnResponse{Header: &pb.ResponseHeader{Revision: t.kv.rev}, Succeeded: succeeded}, nil
+}
+
+// memStore returns a store on the given KV, with its config saved in dir
+func memStore(kv *memKV, dir string, clusterID string) *GDStore {
+	return &GDStore{
+		conf: Config{
+			ConfFile:  path.Join(dir, storeConfFile),
+			ClusterID: clusterID,
+		},
+		Client: &clientv3.Client{KV: kv},
+	}
+}
+
+func TestMoveUnprefixedKeys(t *testing.T) {
+	kv := newMemKV()
+	s := memStore(kv, "", "cluster")
+
+	kv.put(GlusterPrefix+"volumes/vol1", "vol1", 0)
+	kv.put(GlusterPrefix+"peers/p1", "p1", 0)
+	kv.put(GlusterPrefix+"liveness/p1", "alive", 7)
+	kv.put("other/key", "other", 0)
+	// Written after the store was namespaced, and newer than the
+	// unprefixed key
+	kv.put(GlusterPrefix+"peers/p2", "old", 0)
+	kv.put(s.NamespaceKey(GlusterPrefix+"peers/p2"), "new", 0)
+
+	tests.Assert(t, s.moveUnprefixedKeys(context.Background()) == nil)
+
+	v, ok := kv.value(s.NamespaceKey(GlusterPrefix + "volumes/vol1"))
+	tests.Assert(t, ok && v == "vol1")
+	v, ok = kv.value(s.NamespaceKey(GlusterPrefix + "peers/p1"))
+	tests.Assert(t, ok && v == "p1")
+	_, ok = kv.value(GlusterPrefix + "volumes/vol1")
+	tests.Assert(t, !ok)
+
+	// Keys existing in both places keep the namespaced value
+	v, ok = kv.value(s.NamespaceKey(GlusterPrefix + "peers/p2"))
+	tests.Assert(t, ok && v == "new")
+	_, ok = kv.value(GlusterPrefix + "peers/p2")
+	tests.Assert(t, !ok)
+
+	// Keys attached to leases and keys of others are left alone
+	v, ok = kv.value(GlusterPrefix + "liveness/p1")
+	tests.Assert(t, ok && v == "alive")
+	_, ok = kv.value(s.NamespaceKey(GlusterPrefix + "liveness/p1"))
+	tests.Assert(t, !ok)
+	_, ok = kv.value("other/key")
+	tests.Assert(t, ok)
+
+	// Moving again does nothing but look at the leased key
+	txns := kv.txns
+	tests.Assert(t, s.moveUnprefixedKeys(context.Background()) == nil)
+	tests.Assert(t, kv.txns == txns)
+}
+
+func TestInitClusterID(t *testing.T) {
+	dir, err := ioutil.TempDir("", "store")
+	tests.Assert(t, err == nil)
+	defer os.RemoveAll(dir)
+
+	kv := newMemKV()
+	kv.put(GlusterPrefix+"volumes/vol1", "vol1", 0)
+
+	// The first peer sets its cluster ID and moves the existing keys
+	s := memStore(kv, dir, "cluster1")
+	tests.Assert(t, s.initClusterID() == nil)
+	v, ok := kv.value(clusterIDKey)
+	tests.Assert(t, ok && v == "cluster1")
+	_, ok = kv.value(s.NamespaceKey(GlusterPrefix + "volumes/vol1"))
+	tests.Assert(t, ok)
+
+	// A peer without a cluster ID takes the one of the store
+	s = memStore(kv, dir, "")
+	tests.Assert(t, s.initClusterID() == nil)
+	tests.Assert(t, s.ClusterID() == "cluster1")
+
+	// Keys left behind by an interrupted move are moved on restart
+	kv.put(GlusterPrefix+"peers/p1", "p1", 0)
+	s = memStore(kv, dir, "cluster1")
+	tests.Assert(t, s.initClusterID() == nil)
+	_, ok = kv.value(GlusterPrefix + "peers/p1")
+	tests.Assert(t, !ok)
+	_, ok = kv.value(s.NamespaceKey(GlusterPrefix + "peers/p1"))
+	tests.Assert(t, ok)
+
+	// A peer of another cluster can't use the store
+	s = memStore(kv, dir, "cluster2")
+	tests.Assert(t, s.initClusterID() == ErrClusterIDMismatch)
+}
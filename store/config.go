@@ -8,7 +8,6 @@ import (
 
 	"github.com/gluster/glusterd2/pkg/elasticetcd"
 
-	log "github.com/Sirupsen/logrus"
 	"github.com/pelletier/go-toml"
 	flag "github.com/spf13/pflag"
 	config "github.com/spf13/viper"
@@ -20,8 +19,10 @@ const (
 	etcdCURLsOpt     = "etcdcurls"
 	etcdPURLsOpt     = "etcdpurls"
 	etcdLogFileOpt   = "etcdlogfile"
+	peerTimeoutOpt   = "peer-timeout"
 
 	defaultEtcdLogFile = "etcd.log"
+	defaultPeerTimeout = 10
 
 	storeConfFile = "store.toml"
 )
@@ -34,6 +35,7 @@ func InitFlags() {
 	flag.StringSlice(etcdEndpointsOpt, nil, fmt.Sprintf("ETCD endpoints of a remote etcd cluster for the store to connect to. (Defaults to: %s)", elasticetcd.DefaultEndpoint))
 	flag.StringSlice(etcdCURLsOpt, nil, fmt.Sprintf("URLs which etcd server will use for peer to peer communication. (Defaults to: %s)", elasticetcd.DefaultCURL))
 	flag.StringSlice(etcdPURLsOpt, nil, fmt.Sprintf("URLs which etcd server will use to receive etcd client requests. (Defaults to: %s)", elasticetcd.DefaultPURL))
+	flag.Int(peerTimeoutOpt, defaultPeerTimeout, "Time in seconds after which a peer which has stopped sending heartbeats is considered offline.")
 }
 
 // Config is the GD2 store configuration
@@ -45,6 +47,9 @@ type Config struct {
 
 	Dir      string
 	ConfFile string
+
+	// ClusterID is the ID of the cluster the store belongs to
+	ClusterID string
 }
 
 // NewConfig returns a new store Config with defaults
@@ -56,6 +61,7 @@ func NewConfig() *Config {
 		false,
 		path.Join(config.GetString("localstatedir"), "store"),
 		path.Join(config.GetString("localstatedir"), storeConfFile),
+		"",
 	}
 }
 
@@ -87,7 +93,7 @@ func (c *Config) Save() error {
 func GetConfig() *Config {
 	conf, err := readConfigFile()
 	if err != nil {
-		log.WithError(err).Warn("could not read store config file, continuing with defaults")
+		storeLog.WithError(err).Warn("could not read store config file, continuing with defaults")
 		conf = NewConfig()
 	}
 
@@ -115,9 +121,9 @@ func GetConfig() *Config {
 	}
 
 	if saveconf {
-		log.Debug("saving updated store config")
+		storeLog.Debug("saving updated store config")
 		if err := conf.Save(); err != nil {
-			log.WithError(err).Warn("failed to save updated store config")
+			storeLog.WithError(err).Warn("failed to save updated store config")
 		}
 	}
 
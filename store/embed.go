@@ -14,11 +14,11 @@ import (
 func newEmbedStore(sconf *Config) (*GDStore, error) {
 	econf, err := getElasticConfig(sconf)
 	if err != nil {
-		log.WithError(err).Error("failed to create embedded store config")
+		storeLog.WithError(err).Error("failed to create embedded store config")
 		return nil, err
 	}
 
-	log.WithFields(log.Fields{
+	storeLog.WithFields(log.Fields{
 		"name":      econf.Name,
 		"datadir":   econf.Dir,
 		"logdir":    econf.LogDir,
@@ -29,17 +29,17 @@ func newEmbedStore(sconf *Config) (*GDStore, error) {
 
 	ee, err := elasticetcd.New(econf)
 	if err != nil {
-		log.WithError(err).Error("failed to start embedded store")
+		storeLog.WithError(err).Error("failed to start embedded store")
 		return nil, err
 	}
 
-	return &GDStore{*sconf, ee.Client(), ee.Session(), ee}, nil
+	return &GDStore{conf: *sconf, Client: ee.Client(), Session: ee.Session(), ee: ee}, nil
 }
 
 func (s *GDStore) closeEmbedStore() {
-	log.Debug("stopping embedded store")
+	storeLog.Debug("stopping embedded store")
 	s.ee.Stop()
-	log.Debug("stopped embedded store")
+	storeLog.Debug("stopped embedded store")
 }
 
 func getElasticConfig(sconf *Config) (*elasticetcd.Config, error) {
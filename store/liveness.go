@@ -2,12 +2,15 @@ package store
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/gluster/glusterd2/gdctx"
 
 	"github.com/coreos/etcd/clientv3"
+	"github.com/coreos/etcd/mvcc/mvccpb"
 	"github.com/pborman/uuid"
+	config "github.com/spf13/viper"
 )
 
 const (
@@ -35,7 +38,7 @@ func (s *GDStore) IsNodeAlive(nodeID interface{}) bool {
 	defer cancel()
 
 	key := livenessKeyPrefix + keySuffix
-	resp, err := s.Client.Get(ctx, key)
+	resp, err := s.Get(ctx, key)
 	if err != nil {
 		return false
 	}
@@ -43,10 +46,138 @@ func (s *GDStore) IsNodeAlive(nodeID interface{}) bool {
 	return resp.Count == 1
 }
 
+// publishLiveness publishes the liveness of this instance into the store.
+// The liveness key is attached to a lease with a TTL of peer-timeout seconds,
+// which is kept alive as long as this instance can reach the store. If the
+// heartbeats stop, the key expires and the peer is seen as offline.
 func (s *GDStore) publishLiveness() error {
-	// publish liveness of this instance into the store
+	ttl := config.GetInt(peerTimeoutOpt)
+	if ttl <= 0 {
+		ttl = defaultPeerTimeout
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := s.keepLivenessAlive(ctx, int64(ttl)); err != nil {
+		cancel()
+		return err
+	}
+	s.stopLiveness = cancel
+
+	return nil
+}
+
+// keepLivenessAlive attaches the liveness key to a lease and keeps the lease
+// alive until the context is cancelled. If the keepalives stop, for eg. after
+// losing connectivity with the store, the same lease is kept alive again once
+// the store is reachable. A new lease is only granted, and the liveness key
+// published again, if the old lease has expired in the meantime.
+func (s *GDStore) keepLivenessAlive(ctx context.Context, ttl int64) error {
+	lease, err := s.grantLiveness(ctx, ttl)
+	if err != nil {
+		return err
+	}
+
+	kch, err := s.Client.KeepAlive(ctx, lease)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		for {
+			for range kch {
+			}
+			if ctx.Err() != nil {
+				return
+			}
+
+			storeLog.WithField("lease", lease).Warn("liveness keepalives stopped, resuming them")
+			for ctx.Err() == nil {
+				ch, err := s.resumeLiveness(ctx, &lease, ttl)
+				if err == nil {
+					kch = ch
+					break
+				}
+				time.Sleep(time.Second)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// grantLiveness grants a new lease with the given TTL and attaches the
+// liveness key to it
+func (s *GDStore) grantLiveness(ctx context.Context, ttl int64) (clientv3.LeaseID, error) {
+	resp, err := s.Client.Grant(ctx, ttl)
+	if err != nil {
+		return 0, err
+	}
+
 	key := livenessKeyPrefix + gdctx.MyUUID.String()
-	_, err := s.Put(context.TODO(), key, "", clientv3.WithLease(s.Session.Lease()))
+	if _, err := s.Put(ctx, key, "", clientv3.WithLease(resp.ID)); err != nil {
+		s.Client.Revoke(ctx, resp.ID)
+		return 0, err
+	}
+	return resp.ID, nil
+}
+
+// resumeLiveness resumes the keepalives of the liveness lease. If the lease
+// has expired, the liveness key went with it, and a new lease is granted.
+func (s *GDStore) resumeLiveness(ctx context.Context, lease *clientv3.LeaseID, ttl int64) (<-chan *clientv3.LeaseKeepAliveResponse, error) {
+	resp, err := s.Client.TimeToLive(ctx, *lease)
+	if err != nil {
+		return nil, err
+	}
+	if resp.TTL <= 0 {
+		storeLog.WithField("lease", *lease).Warn("liveness lease expired, publishing liveness again")
+		id, err := s.grantLiveness(ctx, ttl)
+		if err != nil {
+			return nil, err
+		}
+		*lease = id
+	}
+	return s.Client.KeepAlive(ctx, *lease)
+}
+
+// WatchLiveness calls the handler with the ID of a node whenever the node
+// comes online or goes offline, until the context is cancelled. Updates of
+// the liveness key of a node which is already online are not reported.
+func (s *GDStore) WatchLiveness(ctx context.Context, handler func(nodeID uuid.UUID, alive bool)) {
+	resp, err := s.Get(ctx, livenessKeyPrefix, clientv3.WithPrefix(), clientv3.WithKeysOnly())
+	if err != nil {
+		storeLog.WithError(err).Error("failed to get peer liveness")
+		return
+	}
+
+	alive := make(map[string]bool)
+	for _, kv := range resp.Kvs {
+		alive[strings.TrimPrefix(string(kv.Key), livenessKeyPrefix)] = true
+	}
+
+	wch := s.Watch(ctx, livenessKeyPrefix, clientv3.WithPrefix(), clientv3.WithRev(resp.Header.Revision+1))
+	for wresp := range wch {
+		for _, ev := range wresp.Events {
+			suffix := strings.TrimPrefix(string(ev.Kv.Key), livenessKeyPrefix)
+			id := uuid.Parse(suffix)
+			if id == nil {
+				continue
+			}
+			online := ev.Type == mvccpb.PUT
+			if alive[suffix] == online {
+				continue
+			}
+			alive[suffix] = online
+			handler(id, online)
+		}
+	}
+}
+
+// Ping checks if the store can be reached and is able to serve requests
+// within the given timeout
+func (s *GDStore) Ping(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
 
+	_, err := s.Get(ctx, livenessKeyPrefix, clientv3.WithPrefix(), clientv3.WithCountOnly())
 	return err
 }
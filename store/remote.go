@@ -3,7 +3,6 @@ package store
 import (
 	"time"
 
-	log "github.com/Sirupsen/logrus"
 	"github.com/coreos/etcd/clientv3"
 	"github.com/coreos/etcd/clientv3/concurrency"
 )
@@ -17,10 +16,10 @@ func newRemoteStore(conf *Config) (*GDStore, error) {
 		RejectOldCluster: true,
 	})
 	if e != nil {
-		log.WithError(e).Error("failed to create etcd client")
+		storeLog.WithError(e).Error("failed to create etcd client")
 		return nil, e
 	}
-	log.Debug("etcd client connection created")
+	storeLog.Debug("etcd client connection created")
 
 	// Create a new session (lease kept alive for the lifetime of a client)
 	// This is currently used for:
@@ -28,22 +27,22 @@ func newRemoteStore(conf *Config) (*GDStore, error) {
 	// * representing liveness of the client
 	s, e := concurrency.NewSession(c, concurrency.WithTTL(sessionTTL))
 	if e != nil {
-		log.WithError(e).Error("failed to create an etcd session")
+		storeLog.WithError(e).Error("failed to create an etcd session")
 		return nil, e
 	}
 
-	return &GDStore{*conf, c, s, nil}, nil
+	return &GDStore{conf: *conf, Client: c, Session: s}, nil
 }
 
 func (s *GDStore) closeRemoteStore() {
 	s.Session.Orphan()
 	if e := s.Client.Close(); e != nil {
-		log.WithError(e).Warn("failed to close etcd client connection")
+		storeLog.WithError(e).Warn("failed to close etcd client connection")
 	}
 	// FIXME: We should close the session first and then the client but it
 	// doesn't work because restart of embedded etcd server when using v3
 	// has issues.
 	if e := s.Session.Close(); e != nil {
-		log.WithError(e).Warn("failed to close etcd session")
+		storeLog.WithError(e).Warn("failed to close etcd session")
 	}
 }
@@ -0,0 +1,126 @@
+// Package schema implements versioned serialization of the objects saved in
+// the store, like volinfo and peerinfo.
+//
+// Objects are saved wrapped in an envelope recording the schema version of
+// the object. When an object saved with an older schema version is read, the
+// migrations registered for its kind are applied in order to bring it to the
+// current version. Objects saved before versioning was introduced are
+// treated as version 0, and are migrated to version 1 as is.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Migration migrates the JSON encoded object of a kind from one schema
+// version to the next
+type Migration func(json.RawMessage) (json.RawMessage, error)
+
+// envelope is the format in which objects are saved
+type envelope struct {
+	Version *int            `json:"schema-version"`
+	Object  json.RawMessage `json:"object"`
+}
+
+var (
+	// migrations[kind][i] migrates objects of kind from version i+1 to i+2
+	migrations     = make(map[string][]Migration)
+	migrationsLock sync.RWMutex
+)
+
+// ErrVersionTooNew is returned when reading an object saved with a schema
+// version newer than the current version, by a newer GlusterD
+type ErrVersionTooNew struct {
+	Kind    string
+	Version int
+	Current int
+}
+
+func (e *ErrVersionTooNew) Error() string {
+	return fmt.Sprintf("%s saved with schema version %d, newer than supported version %d", e.Kind, e.Version, e.Current)
+}
+
+// RegisterMigration registers a migration of the given kind of objects from
+// version from to from+1. Migrations must be registered in order, and from
+// must be the current version of the kind.
+func RegisterMigration(kind string, from int, m Migration) {
+	migrationsLock.Lock()
+	defer migrationsLock.Unlock()
+
+	if current := len(migrations[kind]) + 1; from != current {
+		panic(fmt.Sprintf("schema: migration of %s from version %d registered, current version is %d", kind, from, current))
+	}
+	migrations[kind] = append(migrations[kind], m)
+}
+
+// Version returns the current schema version of the given kind of objects
+func Version(kind string) int {
+	migrationsLock.RLock()
+	defer migrationsLock.RUnlock()
+
+	return len(migrations[kind]) + 1
+}
+
+// Marshal returns the JSON encoding of v, an object of the given kind,
+// wrapped with the current schema version of the kind
+func Marshal(kind string, v interface{}) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	version := Version(kind)
+	return json.Marshal(envelope{&version, b})
+}
+
+// Unmarshal parses data, an object of the given kind saved with Marshal or
+// saved before versioning, into v. The object is migrated to the current
+// schema version of the kind first if required.
+func Unmarshal(kind string, data []byte, v interface{}) error {
+	var e envelope
+	if err := json.Unmarshal(data, &e); err != nil {
+		return err
+	}
+
+	version := 0
+	object := json.RawMessage(data)
+	if e.Version != nil && e.Object != nil {
+		version = *e.Version
+		object = e.Object
+	}
+
+	object, err := migrate(kind, version, object)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(object, v)
+}
+
+func migrate(kind string, version int, object json.RawMessage) (json.RawMessage, error) {
+	migrationsLock.RLock()
+	defer migrationsLock.RUnlock()
+
+	ms := migrations[kind]
+	current := len(ms) + 1
+
+	if version > current {
+		return nil, &ErrVersionTooNew{kind, version, current}
+	}
+
+	// Unversioned objects are the same as version 1 objects
+	if version == 0 {
+		version = 1
+	}
+
+	var err error
+	for ; version < current; version++ {
+		if object, err = ms[version-1](object); err != nil {
+			return nil, fmt.Errorf("failed to migrate %s from schema version %d: %s", kind, version, err)
+		}
+	}
+
+	return object, nil
+}
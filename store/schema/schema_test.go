@@ -0,0 +1,58 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gluster/glusterd2/tests"
+)
+
+type objV1 struct {
+	Name string
+}
+
+type objV2 struct {
+	Name  string
+	Count int
+}
+
+func TestMarshalUnmarshal(t *testing.T) {
+	b, err := Marshal("test-roundtrip", objV1{"a"})
+	tests.Assert(t, err == nil)
+
+	var o objV1
+	tests.Assert(t, Unmarshal("test-roundtrip", b, &o) == nil)
+	tests.Assert(t, o.Name == "a")
+
+	// Objects saved before versioning
+	tests.Assert(t, Unmarshal("test-roundtrip", []byte(`{"Name":"b"}`), &o) == nil)
+	tests.Assert(t, o.Name == "b")
+}
+
+func TestMigration(t *testing.T) {
+	b, err := Marshal("test-migrate", objV1{"a"})
+	tests.Assert(t, err == nil)
+
+	RegisterMigration("test-migrate", 1, func(m json.RawMessage) (json.RawMessage, error) {
+		var o1 objV1
+		if err := json.Unmarshal(m, &o1); err != nil {
+			return nil, err
+		}
+		return json.Marshal(objV2{o1.Name, 1})
+	})
+	tests.Assert(t, Version("test-migrate") == 2)
+
+	var o objV2
+	tests.Assert(t, Unmarshal("test-migrate", b, &o) == nil)
+	tests.Assert(t, o.Name == "a" && o.Count == 1)
+
+	tests.Assert(t, Unmarshal("test-migrate", []byte(`{"Name":"b"}`), &o) == nil)
+	tests.Assert(t, o.Name == "b" && o.Count == 1)
+}
+
+func TestVersionTooNew(t *testing.T) {
+	var o objV1
+	err := Unmarshal("test-new", []byte(`{"schema-version":5,"object":{"Name":"a"}}`), &o)
+	_, ok := err.(*ErrVersionTooNew)
+	tests.Assert(t, ok)
+}
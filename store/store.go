@@ -2,10 +2,12 @@
 package store
 
 import (
+	"context"
 	"errors"
 	"os"
 	"sync"
 
+	"github.com/gluster/glusterd2/logging"
 	"github.com/gluster/glusterd2/pkg/elasticetcd"
 
 	"github.com/coreos/etcd/clientv3"
@@ -23,6 +25,8 @@ var (
 	Store *GDStore
 	lock  sync.Mutex
 
+	storeLog = logging.Subsystem(logging.Store)
+
 	// ErrStoreInitedAlready is returned when the store is already intialized
 	ErrStoreInitedAlready = errors.New("store has been intialized already")
 )
@@ -34,7 +38,14 @@ type GDStore struct {
 	*clientv3.Client
 	*concurrency.Session
 
+	// KV and Watcher are namespaced with the cluster ID, and are used in
+	// place of the KV and Watcher of the embedded Client
+	clientv3.KV
+	clientv3.Watcher
+
 	ee *elasticetcd.ElasticEtcd
+
+	stopLiveness context.CancelFunc
 }
 
 // Init initializes the GD2 store
@@ -90,6 +101,11 @@ func New(conf *Config) (*GDStore, error) {
 		}
 	}
 
+	if err = store.initClusterID(); err != nil {
+		store.Close()
+		return nil, err
+	}
+
 	if err = store.publishLiveness(); err != nil {
 		return nil, err
 	}
@@ -99,6 +115,9 @@ func New(conf *Config) (*GDStore, error) {
 
 // Close closes the store connections
 func (s *GDStore) Close() {
+	if s.stopLiveness != nil {
+		s.stopLiveness()
+	}
 	if s.ee != nil {
 		s.closeEmbedStore()
 	} else {
@@ -0,0 +1,90 @@
+package store
+
+import (
+	"context"
+
+	"github.com/gluster/glusterd2/tracing"
+
+	"github.com/coreos/etcd/clientv3"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+// tracedKV traces the operations made with a context carrying a span as
+// children of the span. Operations made without one aren't traced, so that
+// background activity doesn't start traces of its own.
+type tracedKV struct {
+	clientv3.KV
+}
+
+// traceOp starts a span for the store operation if ctx carries a span, and
+// returns the function to be called with the result of the operation
+func traceOp(ctx context.Context, op, key string) func(error) {
+	parent := opentracing.SpanFromContext(ctx)
+	if parent == nil {
+		return func(error) {}
+	}
+
+	span := tracing.StartSpan("store "+op, parent)
+	ext.DBType.Set(span, "etcd")
+	span.SetTag("key", key)
+	return func(err error) {
+		if err != nil {
+			tracing.SetError(span, err)
+		}
+		span.Finish()
+	}
+}
+
+func (kv *tracedKV) Put(ctx context.Context, key, val string, opts ...clientv3.OpOption) (*clientv3.PutResponse, error) {
+	done := traceOp(ctx, "put", key)
+	resp, err := kv.KV.Put(ctx, key, val, opts...)
+	done(err)
+	return resp, err
+}
+
+func (kv *tracedKV) Get(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	done := traceOp(ctx, "get", key)
+	resp, err := kv.KV.Get(ctx, key, opts...)
+	done(err)
+	return resp, err
+}
+
+func (kv *tracedKV) Delete(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.DeleteResponse, error) {
+	done := traceOp(ctx, "delete", key)
+	resp, err := kv.KV.Delete(ctx, key, opts...)
+	done(err)
+	return resp, err
+}
+
+func (kv *tracedKV) Txn(ctx context.Context) clientv3.Txn {
+	return &tracedTxn{Txn: kv.KV.Txn(ctx), ctx: ctx}
+}
+
+// tracedTxn traces the commit of a store transaction
+type tracedTxn struct {
+	clientv3.Txn
+	ctx context.Context
+}
+
+func (t *tracedTxn) If(cs ...clientv3.Cmp) clientv3.Txn {
+	t.Txn = t.Txn.If(cs...)
+	return t
+}
+
+func (t *tracedTxn) Then(ops ...clientv3.Op) clientv3.Txn {
+	t.Txn = t.Txn.Then(ops...)
+	return t
+}
+
+func (t *tracedTxn) Else(ops ...clientv3.Op) clientv3.Txn {
+	t.Txn = t.Txn.Else(ops...)
+	return t
+}
+
+func (t *tracedTxn) Commit() (*clientv3.TxnResponse, error) {
+	done := traceOp(t.ctx, "txn", "")
+	resp, err := t.Txn.Commit()
+	done(err)
+	return resp, err
+}
@@ -0,0 +1,127 @@
+// Package tracing implements distributed tracing of GlusterD operations using
+// OpenTracing, with the spans reported to a Jaeger agent.
+//
+// REST requests, the transactions run for them and their steps on every peer
+// are traced as a single trace, so that slow operations spanning multiple
+// nodes can be diagnosed end to end. Tracing is disabled unless the address of
+// a Jaeger agent is given with the tracing-jaeger-agent option.
+package tracing
+
+import (
+	"io"
+	"sync"
+
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	config "github.com/spf13/viper"
+	jaeger "github.com/uber/jaeger-client-go"
+	jaegercfg "github.com/uber/jaeger-client-go/config"
+)
+
+const serviceName = "glusterd2"
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
+
+// Init sets up the global tracer to report spans to the Jaeger agent given by
+// the tracing-jaeger-agent option. The tracer is left as a no-op tracer if the
+// option isn't set. The returned Closer flushes the pending spans.
+func Init() (io.Closer, error) {
+	agent := config.GetString("tracing-jaeger-agent")
+	if agent == "" {
+		return nopCloser{}, nil
+	}
+
+	cfg := jaegercfg.Configuration{
+		Sampler: &jaegercfg.SamplerConfig{
+			Type:  jaeger.SamplerTypeProbabilistic,
+			Param: config.GetFloat64("tracing-sample-ratio"),
+		},
+		Reporter: &jaegercfg.ReporterConfig{
+			LocalAgentHostPort: agent,
+		},
+	}
+	tracer, closer, err := cfg.New(serviceName)
+	if err != nil {
+		return nil, err
+	}
+	opentracing.SetGlobalTracer(tracer)
+
+	return closer, nil
+}
+
+// Carrier is the serialized context of a span, used to continue the trace of
+// the span on other peers
+type Carrier map[string]string
+
+// Inject returns the carrier of the given span
+func Inject(span opentracing.Span) Carrier {
+	if span == nil {
+		return nil
+	}
+	c := make(Carrier)
+	if err := opentracing.GlobalTracer().Inject(span.Context(), opentracing.TextMap, opentracing.TextMapCarrier(c)); err != nil {
+		return nil
+	}
+	return c
+}
+
+// StartSpan starts a span with the given name as a child of parent. A new
+// trace is started if parent is nil.
+func StartSpan(name string, parent opentracing.Span) opentracing.Span {
+	if parent == nil {
+		return opentracing.StartSpan(name)
+	}
+	return opentracing.StartSpan(name, opentracing.ChildOf(parent.Context()))
+}
+
+// StartRemoteSpan starts a span with the given name as a child of the span
+// serialized in the carrier, which was started on another peer
+func StartRemoteSpan(name string, c Carrier) opentracing.Span {
+	if len(c) == 0 {
+		return opentracing.StartSpan(name)
+	}
+	sc, err := opentracing.GlobalTracer().Extract(opentracing.TextMap, opentracing.TextMapCarrier(c))
+	if err != nil {
+		return opentracing.StartSpan(name)
+	}
+	return opentracing.StartSpan(name, ext.RPCServerOption(sc))
+}
+
+// SetError marks the span as failed with the given error
+func SetError(span opentracing.Span, err error) {
+	ext.Error.Set(span, true)
+	span.LogKV("event", "error", "message", err.Error())
+}
+
+// The spans of the REST requests being served, keyed by the request ID. The
+// request ID is the only request scoped state handed down to transactions, so
+// this is how transactions find the span of their request.
+var requests = struct {
+	sync.RWMutex
+	spans map[string]opentracing.Span
+}{spans: make(map[string]opentracing.Span)}
+
+// SetRequestSpan records the span of the request with the given ID
+func SetRequestSpan(reqID string, span opentracing.Span) {
+	requests.Lock()
+	defer requests.Unlock()
+	requests.spans[reqID] = span
+}
+
+// ClearRequestSpan drops the span of the request with the given ID, once the
+// request has been served
+func ClearRequestSpan(reqID string) {
+	requests.Lock()
+	defer requests.Unlock()
+	delete(requests.spans, reqID)
+}
+
+// RequestSpan returns the span of the request with the given ID, or nil if
+// the request isn't being served
+func RequestSpan(reqID string) opentracing.Span {
+	requests.RLock()
+	defer requests.RUnlock()
+	return requests.spans[reqID]
+}
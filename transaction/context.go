@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 
 	"github.com/gluster/glusterd2/store"
+	"github.com/gluster/glusterd2/tracing"
 
 	log "github.com/Sirupsen/logrus"
+	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/pborman/uuid"
 )
 
@@ -36,12 +38,15 @@ type Tctx struct {
 	logFields log.Fields
 
 	prefix string // The prefix under which the data is to be stored
+
+	span  opentracing.Span // The span the store operations of the context are traced under
+	trace tracing.Carrier  // The serialized span, to continue the trace on other peers
 }
 
-// NewCtx returns a new empty TxnCtx with no parent, no associated data and the default logger.
+// NewCtx returns a new empty TxnCtx with no parent, no associated data and the logger of the txn subsystem.
 func NewCtx() *Tctx {
 	return &Tctx{
-		log: log.StandardLogger(),
+		log: txnLog,
 	}
 }
 
@@ -61,6 +66,8 @@ func (c *Tctx) NewCtx() *Tctx {
 		log:       c.log,
 		logFields: c.logFields,
 		prefix:    c.prefix,
+		span:      c.span,
+		trace:     c.trace,
 	}
 }
 
@@ -81,6 +88,21 @@ func (c *Tctx) WithPrefix(prefix string) *Tctx {
 	return n
 }
 
+// setSpan sets the span the context is traced under
+func (c *Tctx) setSpan(span opentracing.Span) {
+	c.span = span
+	c.trace = tracing.Inject(span)
+}
+
+// storeContext returns the context for the store operations of the context,
+// carrying the span of the context if it is traced
+func (c *Tctx) storeContext() context.Context {
+	if c.span == nil {
+		return context.TODO()
+	}
+	return opentracing.ContextWithSpan(context.Background(), c.span)
+}
+
 // Set attaches the given key-value pair to the context.
 // If the key exists, the value will be updated.
 func (c *Tctx) Set(key string, value interface{}) error {
@@ -94,7 +116,7 @@ func (c *Tctx) Set(key string, value interface{}) error {
 	}
 
 	storeKey := c.prefix + "/" + key
-	_, e = store.Store.Put(context.TODO(), storeKey, string(json))
+	_, e = store.Store.Put(c.storeContext(), storeKey, string(json))
 	if e != nil {
 		c.log.WithFields(log.Fields{
 			"error": e,
@@ -116,7 +138,7 @@ func (c *Tctx) SetNodeResult(nodeID uuid.UUID, key string, value interface{}) er
 // Returns error if not found.
 func (c *Tctx) Get(key string, value interface{}) error {
 	storeKey := c.prefix + "/" + key
-	r, e := store.Store.Get(context.TODO(), storeKey)
+	r, e := store.Store.Get(c.storeContext(), storeKey)
 	if e != nil {
 		c.log.WithFields(log.Fields{
 			"error": e,
@@ -145,7 +167,7 @@ func (c *Tctx) GetNodeResult(nodeID uuid.UUID, key string, value interface{}) er
 // Delete deletes the key and attached value
 func (c *Tctx) Delete(key string) error {
 	storeKey := c.prefix + "/" + key
-	_, e := store.Store.Delete(context.TODO(), storeKey)
+	_, e := store.Store.Delete(c.storeContext(), storeKey)
 	if e != nil {
 		c.log.WithFields(log.Fields{
 			"error": e,
@@ -177,6 +199,7 @@ type expContext struct {
 	Parent    *Tctx
 	LogFields log.Fields
 	Prefix    string
+	Trace     tracing.Carrier `json:",omitempty"`
 }
 
 // MarshalJSON implements the json.Marshaler interface
@@ -185,6 +208,7 @@ func (c *Tctx) MarshalJSON() ([]byte, error) {
 		Parent:    c.parent,
 		LogFields: c.logFields,
 		Prefix:    c.prefix,
+		Trace:     c.trace,
 	}
 
 	return json.Marshal(ac)
@@ -201,12 +225,13 @@ func (c *Tctx) UnmarshalJSON(d []byte) error {
 
 	c.parent = ac.Parent
 	if c.parent == nil {
-		c.log = log.StandardLogger().WithFields(ac.LogFields)
+		c.log = txnLog.WithFields(ac.LogFields)
 	} else {
 		c.log = c.parent.log.WithFields(ac.LogFields)
 	}
 	c.logFields = ac.LogFields
 	c.prefix = ac.Prefix
+	c.trace = ac.Trace
 
 	return nil
 }
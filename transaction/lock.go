@@ -3,6 +3,7 @@ package transaction
 import (
 	"context"
 	"errors"
+	"sync"
 	"time"
 
 	"github.com/gluster/glusterd2/gdctx"
@@ -21,6 +22,92 @@ const (
 // and the request timed out
 var ErrLockTimeout = errors.New("could not obtain lock: another conflicting transaction may be in progress")
 
+// locker is a lock in the store, shared by all the nodes
+type locker interface {
+	Lock(ctx context.Context) error
+	Unlock(ctx context.Context) error
+}
+
+// newLocker returns the mutex in the store for the given key. The mutexes of
+// all the transactions of this node are taken with the session of the node,
+// and etcd lets a session take a mutex it already holds.
+var newLocker = func(key string) locker {
+	return concurrency.NewMutex(store.Store.Session, key)
+}
+
+// localLock keeps the transactions of this node from taking the same lock at
+// the same time, which the store can't tell apart. It is held from the lock
+// step of a transaction until its unlock step.
+type localLock struct {
+	held chan struct{}
+
+	sync.Mutex
+	// holder is the prefix of the context of the transaction holding the
+	// lock, and mutex is the mutex it took in the store
+	holder string
+	mutex  locker
+}
+
+// localLocks are the local locks of the keys, by key. The lock and unlock
+// step functions of a key must share its local lock, even if they are
+// registered more than once.
+var localLocks = struct {
+	sync.Mutex
+	m map[string]*localLock
+}{m: make(map[string]*localLock)}
+
+func getLocalLock(key string) *localLock {
+	localLocks.Lock()
+	defer localLocks.Unlock()
+
+	l, ok := localLocks.m[key]
+	if !ok {
+		l = &localLock{held: make(chan struct{}, 1)}
+		localLocks.m[key] = l
+	}
+	return l
+}
+
+// lock takes the local lock and then the mutex of the key in the store
+func (l *localLock) lock(ctx context.Context, c TxnCtx, key string) error {
+	select {
+	case l.held <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	mutex := newLocker(key)
+	if err := mutex.Lock(ctx); err != nil {
+		<-l.held
+		return err
+	}
+
+	l.Lock()
+	l.holder = c.Prefix()
+	l.mutex = mutex
+	l.Unlock()
+	return nil
+}
+
+// unlock releases the mutex in the store and the local lock, if they are
+// held by the transaction of the context. A transaction which failed to take
+// the lock undoes its lock step, and must not release the lock of another
+// transaction.
+func (l *localLock) unlock(c TxnCtx) (bool, error) {
+	l.Lock()
+	if l.mutex == nil || l.holder != c.Prefix() {
+		l.Unlock()
+		return false, nil
+	}
+	mutex := l.mutex
+	l.holder = ""
+	l.mutex = nil
+	l.Unlock()
+
+	defer func() { <-l.held }()
+	return true, mutex.Unlock(context.Background())
+}
+
 // createLockStepFunc returns the registry IDs of StepFuncs which lock/unlock the given key.
 // If existing StepFuncs are not found, new funcs are created and registered.
 func createLockStepFunc(key string) (string, string, error) {
@@ -34,8 +121,8 @@ func createLockStepFunc(key string) (string, string, error) {
 		return lockFuncID, unlockFuncID, nil
 	}
 
-	key = lockPrefix + key
-	locker := concurrency.NewMutex(store.Store.Session, key)
+	key = store.Store.NamespaceKey(lockPrefix + key)
+	l := getLocalLock(key)
 
 	lockFunc := func(c TxnCtx) error {
 
@@ -43,7 +130,7 @@ func createLockStepFunc(key string) (string, string, error) {
 		defer cancel()
 
 		c.Logger().WithField("key", key).Debug("attempting to lock")
-		err := locker.Lock(ctx)
+		err := l.lock(ctx, c, key)
 		switch err {
 		case nil:
 			c.Logger().WithField("key", key).Debug("lock obtained")
@@ -60,8 +147,11 @@ func createLockStepFunc(key string) (string, string, error) {
 	unlockFunc := func(c TxnCtx) error {
 
 		c.Logger().WithField("key", key).Debug("attempting to unlock")
-		err := locker.Unlock(context.Background())
-		if err == nil {
+		held, err := l.unlock(c)
+		switch {
+		case !held:
+			c.Logger().WithField("key", key).Debug("lock not held by transaction")
+		case err == nil:
 			c.Logger().WithField("key", key).Debug("lock unlocked")
 		}
 
@@ -79,8 +169,8 @@ func CreateLockSteps(key string) (*Step, *Step, error) {
 		return nil, nil, err
 	}
 
-	lockStep := &Step{lockFunc, unlockFunc, []uuid.UUID{gdctx.MyUUID}}
-	unlockStep := &Step{unlockFunc, "", []uuid.UUID{gdctx.MyUUID}}
+	lockStep := &Step{DoFunc: lockFunc, UndoFunc: unlockFunc, Nodes: []uuid.UUID{gdctx.MyUUID}}
+	unlockStep := &Step{DoFunc: unlockFunc, Nodes: []uuid.UUID{gdctx.MyUUID}}
 
 	return lockStep, unlockStep, nil
 }
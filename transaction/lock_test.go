@@ -0,0 +1,126 @@
+package transaction
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gluster/glusterd2/tests"
+
+	heketitests "github.com/heketi/tests"
+)
+
+// sessionMutex is a mutex in the store taken with the session of this node,
+// which etcd lets the session take again while it holds it
+type sessionMutex struct {
+	lock *sync.Mutex
+	held *int
+}
+
+func (m sessionMutex) Lock(ctx context.Context) error {
+	m.lock.Lock()
+	*m.held++
+	m.lock.Unlock()
+	return nil
+}
+
+func (m sessionMutex) Unlock(ctx context.Context) error {
+	m.lock.Lock()
+	*m.held--
+	m.lock.Unlock()
+	return nil
+}
+
+// patchLocker makes the locks use session mutexes, and returns the number of
+// them held
+func patchLocker() (*int, func()) {
+	var lock sync.Mutex
+	held := new(int)
+	p := heketitests.Patch(&newLocker, func(string) locker {
+		return sessionMutex{&lock, held}
+	})
+	return held, p.Restore
+}
+
+func TestLockConcurrentProvisions(t *testing.T) {
+	defer patchNode()()
+	defer patchStore(newMemKV())()
+	held, restore := patchLocker()
+	defer restore()
+
+	// Each provision checks the capacity left, and reserves its size
+	// unless it would exceed the capacity
+	const capacity, size = 150, 100
+	var reserved int
+	var lock sync.Mutex
+	RegisterStepFunc(func(TxnCtx) error {
+		lock.Lock()
+		left := capacity - reserved
+		lock.Unlock()
+		if left < size {
+			return nil
+		}
+
+		time.Sleep(20 * time.Millisecond)
+
+		lock.Lock()
+		reserved += size
+		lock.Unlock()
+		return nil
+	}, "test.Reserve")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		lockStep, unlockStep, err := CreateLockSteps("test/capacity")
+		tests.Assert(t, err == nil)
+
+		txn := NewTxn("")
+		txn.Steps = []*Step{
+			lockStep,
+			{DoFunc: "test.Reserve", Nodes: lockStep.Nodes},
+			unlockStep,
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := txn.Do()
+			tests.Assert(t, err == nil)
+		}()
+	}
+	wg.Wait()
+
+	// The transactions of this node don't hold the lock at the same
+	// time, so the capacity is reserved once
+	tests.Assert(t, reserved == size)
+	tests.Assert(t, *held == 0)
+}
+
+func TestLockNotHeld(t *testing.T) {
+	held, restore := patchLocker()
+	defer restore()
+
+	l := getLocalLock("test/not-held")
+	c1 := NewCtx().WithPrefix("txn1")
+	c2 := NewCtx().WithPrefix("txn2")
+
+	tests.Assert(t, l.lock(context.Background(), c1, "key") == nil)
+
+	// A transaction failing to take the lock doesn't release it when
+	// undoing its lock step
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	tests.Assert(t, l.lock(ctx, c2, "key") == context.DeadlineExceeded)
+	ok, err := l.unlock(c2)
+	tests.Assert(t, !ok && err == nil)
+	tests.Assert(t, *held == 1)
+
+	ok, err = l.unlock(c1)
+	tests.Assert(t, ok && err == nil)
+	tests.Assert(t, *held == 0)
+
+	// The lock can be taken again once released
+	tests.Assert(t, l.lock(context.Background(), c2, "key") == nil)
+	ok, err = l.unlock(c2)
+	tests.Assert(t, ok && err == nil)
+}
@@ -4,8 +4,6 @@ package transaction
 
 import (
 	"sync"
-
-	log "github.com/Sirupsen/logrus"
 )
 
 var sfRegistry = struct {
@@ -19,7 +17,7 @@ func registerStepFunc(s StepFunc, name string) {
 	}
 
 	if _, ok := sfRegistry.sfMap[name]; ok {
-		log.WithField("stepname", name).Warning("step with provided name exists in registry and will be overwritten")
+		txnLog.WithField("stepname", name).Warning("step with provided name exists in registry and will be overwritten")
 	}
 
 	sfRegistry.sfMap[name] = s
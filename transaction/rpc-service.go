@@ -4,9 +4,10 @@ import (
 	"encoding/json"
 	"errors"
 
+	"github.com/gluster/glusterd2/gdctx"
 	"github.com/gluster/glusterd2/servers/peerrpc"
+	"github.com/gluster/glusterd2/tracing"
 
-	log "github.com/Sirupsen/logrus"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 )
@@ -23,7 +24,7 @@ func (p *txnSvc) RunStep(rpcCtx context.Context, req *TxnStepReq) (*TxnStepResp,
 
 	err := json.Unmarshal(req.Context, &ctx)
 	if err != nil {
-		log.WithError(err).Error("failed to Unmarshal transaction context")
+		txnLog.WithError(err).Error("failed to Unmarshal transaction context")
 		return nil, err
 	}
 
@@ -38,12 +39,19 @@ func (p *txnSvc) RunStep(rpcCtx context.Context, req *TxnStepReq) (*TxnStepResp,
 
 	logger.Debug("running step")
 
+	// Continue the trace of the step started by the initiator
+	span := tracing.StartRemoteSpan("run-step "+req.StepFunc, ctx.trace)
+	span.SetTag("peer", gdctx.MyUUID.String())
+	ctx.setSpan(span)
+	defer span.Finish()
+
 	resp := new(TxnStepResp)
 
 	// Execute the step function, build and return result
 	err = f(&ctx)
 	if err != nil {
 		logger.WithError(err).Debug("step function failed")
+		tracing.SetError(span, err)
 		resp.Error = err.Error()
 	} else {
 		b, err := json.Marshal(ctx)
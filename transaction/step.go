@@ -1,13 +1,26 @@
 package transaction
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/gluster/glusterd2/gdctx"
+	"github.com/gluster/glusterd2/peer"
+	"github.com/gluster/glusterd2/tracing"
 
+	log "github.com/Sirupsen/logrus"
 	"github.com/pborman/uuid"
 )
 
+// DefaultStepTimeout is the time a step is allowed to run on its nodes, if the
+// step does not set a timeout
+const DefaultStepTimeout = 2 * time.Minute
+
 // StepFunc is the function that is supposed to be run during a transaction step
 type StepFunc func(TxnCtx) error
 
@@ -25,10 +38,22 @@ type StepFunc func(TxnCtx) error
 // DoFunc and UndoFunc are names of StepFuncs registered in the registry
 // DoFunc performs does the action
 // UndoFunc undoes anything done by DoFunc
+// Timeout is the time the step is allowed to run on the nodes. DefaultStepTimeout
+// is used if it is not set.
+//
+// Steps are independent of the order of the nodes and are run on all the
+// nodes concurrently. Sequential must be set for steps which need to run on
+// one node at a time, in the order of Nodes.
 type Step struct {
-	DoFunc   string
-	UndoFunc string
-	Nodes    []uuid.UUID
+	DoFunc     string
+	UndoFunc   string
+	Nodes      []uuid.UUID
+	Timeout    time.Duration
+	Sequential bool
+
+	// local tracks the step functions running on this node, which are
+	// left running when the step times out
+	local sync.WaitGroup
 }
 
 var (
@@ -36,45 +61,225 @@ var (
 	ErrStepFuncNotFound = errors.New("StepFunc was not found")
 )
 
-// do runs the DoFunc on the nodes
-func (s *Step) do(c TxnCtx) error {
-	return runStepFuncOnNodes(s.DoFunc, c, s.Nodes)
+// StepTimeoutError is returned when a step did not finish in time on some of
+// its nodes
+type StepTimeoutError struct {
+	StepFunc string
+	// Nodes are the nodes which did not respond in time
+	Nodes []string
 }
 
-// undo runs the UndoFunc on the nodes
-func (s *Step) undo(c TxnCtx) error {
-	if s.UndoFunc != "" {
-		return runStepFuncOnNodes(s.UndoFunc, c, s.Nodes)
+func (e *StepTimeoutError) Error() string {
+	return fmt.Sprintf("step %s timed out waiting for node(s) %s", e.StepFunc, strings.Join(e.Nodes, ", "))
+}
+
+// StepError is returned when a step running on more than one node fails on
+// some of them. A step running on a single node returns the error of the node
+// as it is.
+type StepError struct {
+	StepFunc string
+	// Errors are the errors of the failed nodes, keyed by the node
+	Errors map[string]error
+}
+
+func (e *StepError) Error() string {
+	nodes := make([]string, 0, len(e.Errors))
+	for node := range e.Errors {
+		nodes = append(nodes, node)
 	}
-	return nil
+	sort.Strings(nodes)
+
+	msgs := make([]string, len(nodes))
+	for i, node := range nodes {
+		msgs[i] = fmt.Sprintf("%s: %s", node, e.Errors[node])
+	}
+	return fmt.Sprintf("step %s failed on %d node(s): %s", e.StepFunc, len(nodes), strings.Join(msgs, "; "))
 }
 
-func runStepFuncOnNodes(name string, c TxnCtx, nodes []uuid.UUID) error {
-	var (
-		i    int
-		node uuid.UUID
-	)
-	done := make(chan error)
-	defer close(done)
+// timeout returns the time the step is allowed to run, limited to the given
+// time left for the transaction
+func (s *Step) timeout(limit time.Duration) time.Duration {
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = DefaultStepTimeout
+	}
+	if limit > 0 && limit < timeout {
+		timeout = limit
+	}
+	return timeout
+}
+
+// do runs the DoFunc on the nodes
+func (s *Step) do(c TxnCtx, limit time.Duration) error {
+	return s.run(s.DoFunc, c, s.timeout(limit))
+}
 
-	for i, node = range nodes {
-		go runStepFuncOnNode(name, c, node, done)
+// undo runs the UndoFunc on the nodes, once the DoFunc has returned on this
+// node
+func (s *Step) undo(c TxnCtx) error {
+	if s.UndoFunc == "" {
+		return nil
 	}
+	// A DoFunc which timed out on this node is still running, and would
+	// race with the UndoFunc
+	s.local.Wait()
+	return s.run(s.UndoFunc, c, s.timeout(0))
+}
 
-	// TODO: Need to properly aggregate results
+func (s *Step) run(name string, c TxnCtx, timeout time.Duration) error {
+	start := time.Now()
+	c, done := traceStep(name, c)
 	var err error
-	for i >= 0 {
-		err = <-done
-		i--
+	if s.Sequential {
+		err = runStepFuncOnNodesSequential(name, c, s.Nodes, timeout, &s.local)
+	} else {
+		err = runStepFuncOnNodes(name, c, s.Nodes, timeout, &s.local)
 	}
+	c.Logger().WithFields(log.Fields{
+		"stepfunc": name,
+		"nodes":    len(s.Nodes),
+		"duration": time.Since(start),
+	}).Debug("step finished")
+	done(err)
 	return err
 }
 
-func runStepFuncOnNode(name string, c TxnCtx, node uuid.UUID, done chan<- error) {
+// traceStep starts a span for running the step function, as a child of the
+// span of the context. The step function on every node is run with the
+// returned context, which is traced under the step span. The returned
+// function finishes the span with the result of the step.
+func traceStep(name string, c TxnCtx) (TxnCtx, func(error)) {
+	tc, ok := c.(*Tctx)
+	if !ok {
+		return c, func(error) {}
+	}
+
+	span := tracing.StartSpan("step "+name, tc.span)
+	sc := tc.NewCtx()
+	sc.setSpan(span)
+	return sc, func(err error) {
+		if err != nil {
+			tracing.SetError(span, err)
+		}
+		span.Finish()
+	}
+}
+
+type stepResult struct {
+	node uuid.UUID
+	err  error
+}
+
+// nodeDesc returns the ID of the node along with its name, if known
+func nodeDesc(node uuid.UUID) string {
+	if p, err := peer.GetPeerF(node.String()); err == nil {
+		return fmt.Sprintf("%s (%s)", node, p.Name)
+	}
+	return node.String()
+}
+
+// stepTimedOut returns the error for the step timing out while waiting for the
+// given nodes
+func stepTimedOut(name string, c TxnCtx, nodes []uuid.UUID, timeout time.Duration) error {
+	terr := &StepTimeoutError{StepFunc: name}
+	for _, node := range nodes {
+		terr.Nodes = append(terr.Nodes, nodeDesc(node))
+	}
+	c.Logger().WithFields(log.Fields{
+		"stepfunc": name,
+		"timeout":  timeout,
+		"nodes":    terr.Nodes,
+	}).Error("step timed out")
+	return terr
+}
+
+// runStepFuncOnNodes runs the step function on all the nodes concurrently, and
+// waits for all nodes to finish or for the timeout. The errors of all failed
+// nodes are aggregated into a StepError.
+func runStepFuncOnNodes(name string, c TxnCtx, nodes []uuid.UUID, timeout time.Duration, local *sync.WaitGroup) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// done is buffered so that nodes responding after the timeout do not
+	// block forever
+	done := make(chan stepResult, len(nodes))
+	for _, node := range nodes {
+		startStepFuncOnNode(ctx, name, c, node, done, local)
+	}
+
+	errs := make(map[string]error)
+	responded := make(map[string]bool)
+	for range nodes {
+		select {
+		case r := <-done:
+			responded[r.node.String()] = true
+			if r.err != nil {
+				errs[nodeDesc(r.node)] = r.err
+			}
+		case <-ctx.Done():
+			var stuck []uuid.UUID
+			for _, node := range nodes {
+				if !responded[node.String()] {
+					stuck = append(stuck, node)
+				}
+			}
+			return stepTimedOut(name, c, stuck, timeout)
+		}
+	}
+
+	switch {
+	case len(errs) == 0:
+		return nil
+	case len(nodes) == 1:
+		for _, err := range errs {
+			return err
+		}
+	}
+	return &StepError{StepFunc: name, Errors: errs}
+}
+
+// runStepFuncOnNodesSequential runs the step function on the nodes one at a
+// time, in the given order, stopping at the first failure
+func runStepFuncOnNodesSequential(name string, c TxnCtx, nodes []uuid.UUID, timeout time.Duration, local *sync.WaitGroup) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	for _, node := range nodes {
+		done := make(chan stepResult, 1)
+		startStepFuncOnNode(ctx, name, c, node, done, local)
+
+		select {
+		case r := <-done:
+			if r.err == nil {
+				continue
+			}
+			if len(nodes) == 1 {
+				return r.err
+			}
+			errs := make(map[string]error)
+			errs[nodeDesc(node)] = r.err
+			return &StepError{StepFunc: name, Errors: errs}
+		case <-ctx.Done():
+			return stepTimedOut(name, c, []uuid.UUID{node}, timeout)
+		}
+	}
+	return nil
+}
+
+// startStepFuncOnNode runs the step function on the node in the background.
+// Step functions running on this node are tracked in local.
+func startStepFuncOnNode(ctx context.Context, name string, c TxnCtx, node uuid.UUID, done chan<- stepResult, local *sync.WaitGroup) {
 	if uuid.Equal(node, gdctx.MyUUID) {
-		done <- runStepFuncLocal(name, c)
+		// Local step functions cannot be interrupted, only given up on
+		local.Add(1)
+		go func() {
+			defer local.Done()
+			done <- stepResult{node, runStepFuncLocal(name, c)}
+		}()
 	} else {
-		done <- runStepFuncRemote(name, c, node)
+		go func() {
+			done <- stepResult{node, runStepFuncRemote(ctx, name, c, node)}
+		}()
 	}
 }
 
@@ -89,8 +294,8 @@ func runStepFuncLocal(name string, c TxnCtx) error {
 	//TODO: Results need to be aggregated
 }
 
-func runStepFuncRemote(step string, c TxnCtx, node uuid.UUID) error {
-	rsp, err := RunStepOn(step, node, c)
+func runStepFuncRemote(ctx context.Context, step string, c TxnCtx, node uuid.UUID) error {
+	rsp, err := RunStepOn(ctx, step, node, c)
 	//TODO: Results need to be aggregated
 	_ = rsp
 	return err
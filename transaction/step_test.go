@@ -0,0 +1,248 @@
+package transaction
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gluster/glusterd2/gdctx"
+	"github.com/gluster/glusterd2/peer"
+	"github.com/gluster/glusterd2/tests"
+
+	heketitests "github.com/heketi/tests"
+	"github.com/pborman/uuid"
+)
+
+// patchNode makes the tests run as a node with a random ID, which is not
+// found in the store
+func patchNode() func() {
+	n := heketitests.Patch(&gdctx.MyUUID, uuid.NewRandom())
+	p := heketitests.Patch(&peer.GetPeerF, func(id string) (*peer.Peer, error) {
+		return nil, errors.New("peer not found")
+	})
+	return func() {
+		p.Restore()
+		n.Restore()
+	}
+}
+
+func TestStepTimeout(t *testing.T) {
+	s := &Step{}
+	tests.Assert(t, s.timeout(0) == DefaultStepTimeout)
+	tests.Assert(t, s.timeout(time.Minute) == time.Minute)
+
+	s.Timeout = 5 * time.Minute
+	tests.Assert(t, s.timeout(0) == 5*time.Minute)
+	tests.Assert(t, s.timeout(10*time.Minute) == 5*time.Minute)
+	tests.Assert(t, s.timeout(time.Minute) == time.Minute)
+}
+
+func TestStepTimedOut(t *testing.T) {
+	defer patchNode()()
+
+	// The stuck step functions are released, and waited for, before the
+	// test ends
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer close(release)
+	RegisterStepFunc(func(TxnCtx) error {
+		defer wg.Done()
+		<-release
+		return nil
+	}, "test.Stuck")
+
+	s := &Step{
+		DoFunc:  "test.Stuck",
+		Nodes:   []uuid.UUID{gdctx.MyUUID},
+		Timeout: 10 * time.Millisecond,
+	}
+	wg.Add(1)
+	err := s.do(NewMockCtx(), 0)
+	tests.Assert(t, IsTimeout(err))
+
+	terr, ok := err.(*StepTimeoutError)
+	tests.Assert(t, ok)
+	tests.Assert(t, terr.StepFunc == "test.Stuck")
+	tests.Assert(t, len(terr.Nodes) == 1 && terr.Nodes[0] == gdctx.MyUUID.String())
+
+	// The time left for the transaction limits the step
+	s.Timeout = time.Hour
+	wg.Add(1)
+	tests.Assert(t, IsTimeout(s.do(NewMockCtx(), 10*time.Millisecond)))
+}
+
+func TestStepError(t *testing.T) {
+	defer patchNode()()
+
+	errFailed := errors.New("failed")
+	RegisterStepFunc(func(TxnCtx) error {
+		return errFailed
+	}, "test.Fail")
+
+	// The error of a single node is returned as it is
+	s := &Step{DoFunc: "test.Fail", Nodes: []uuid.UUID{gdctx.MyUUID}}
+	tests.Assert(t, s.do(NewMockCtx(), 0) == errFailed)
+
+	s.Nodes = append(s.Nodes, gdctx.MyUUID)
+	err := s.do(NewMockCtx(), 0)
+	serr, ok := err.(*StepError)
+	tests.Assert(t, ok)
+	tests.Assert(t, serr.Errors[gdctx.MyUUID.String()] == errFailed)
+	tests.Assert(t, strings.Contains(err.Error(), "test.Fail"))
+
+	s.DoFunc = "test.NotRegistered"
+	s.Nodes = s.Nodes[:1]
+	tests.Assert(t, s.do(NewMockCtx(), 0) == ErrStepFuncNotFound)
+}
+
+func TestTxnUndo(t *testing.T) {
+	defer patchNode()()
+
+	var undone []string
+	for _, name := range []string{"a", "b", "c"} {
+		name := name
+		RegisterStepFunc(func(TxnCtx) error {
+			undone = append(undone, name)
+			return nil
+		}, "test.Undo."+name)
+	}
+
+	nodes := []uuid.UUID{gdctx.MyUUID}
+	txn := &Txn{
+		Ctx: NewMockCtx(),
+		Steps: []*Step{
+			{UndoFunc: "test.Undo.a", Nodes: nodes},
+			// Steps without an UndoFunc are skipped
+			{Nodes: nodes},
+			{UndoFunc: "test.Undo.b", Nodes: nodes},
+			{UndoFunc: "test.Undo.c", Nodes: nodes},
+		},
+	}
+
+	// Steps are undone in reverse order, from the failed step
+	txn.undo(2)
+	tests.Assert(t, strings.Join(undone, ",") == "b,a")
+
+	undone = nil
+	txn.undo(-1)
+	tests.Assert(t, len(undone) == 0)
+}
+
+// concurrencyStep returns a step function which records the highest number of
+// its calls running at the same time, and fails if fail is set
+func concurrencyStep(max *int, calls *int, fail bool) StepFunc {
+	var lock sync.Mutex
+	running := 0
+	return func(TxnCtx) error {
+		lock.Lock()
+		running++
+		*calls++
+		if running > *max {
+			*max = running
+		}
+		lock.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		lock.Lock()
+		running--
+		lock.Unlock()
+		if fail {
+			return errors.New("failed")
+		}
+		return nil
+	}
+}
+
+func TestStepConcurrent(t *testing.T) {
+	defer patchNode()()
+
+	var max, calls int
+	RegisterStepFunc(concurrencyStep(&max, &calls, false), "test.Concurrent")
+
+	s := &Step{
+		DoFunc: "test.Concurrent",
+		Nodes:  []uuid.UUID{gdctx.MyUUID, gdctx.MyUUID, gdctx.MyUUID},
+	}
+	tests.Assert(t, s.do(NewMockCtx(), 0) == nil)
+	tests.Assert(t, calls == 3)
+	tests.Assert(t, max == 3)
+}
+
+func TestStepSequential(t *testing.T) {
+	defer patchNode()()
+
+	var max, calls int
+	RegisterStepFunc(concurrencyStep(&max, &calls, false), "test.Sequential")
+
+	s := &Step{
+		DoFunc:     "test.Sequential",
+		Nodes:      []uuid.UUID{gdctx.MyUUID, gdctx.MyUUID, gdctx.MyUUID},
+		Sequential: true,
+	}
+	tests.Assert(t, s.do(NewMockCtx(), 0) == nil)
+	tests.Assert(t, calls == 3)
+	tests.Assert(t, max == 1)
+
+	// Sequential steps stop at the first failure
+	max, calls = 0, 0
+	RegisterStepFunc(concurrencyStep(&max, &calls, true), "test.SequentialFail")
+	s.DoFunc = "test.SequentialFail"
+	_, ok := s.do(NewMockCtx(), 0).(*StepError)
+	tests.Assert(t, ok)
+	tests.Assert(t, calls == 1)
+}
+
+func TestStepUndoAfterTimeout(t *testing.T) {
+	defer patchNode()()
+
+	release := make(chan struct{})
+	var order []string
+	var lock sync.Mutex
+	record := func(s string) {
+		lock.Lock()
+		order = append(order, s)
+		lock.Unlock()
+	}
+	RegisterStepFunc(func(TxnCtx) error {
+		<-release
+		record("do")
+		return nil
+	}, "test.SlowDo")
+	RegisterStepFunc(func(TxnCtx) error {
+		record("undo")
+		return nil
+	}, "test.SlowUndo")
+
+	s := &Step{
+		DoFunc:   "test.SlowDo",
+		UndoFunc: "test.SlowUndo",
+		Nodes:    []uuid.UUID{gdctx.MyUUID},
+		Timeout:  10 * time.Millisecond,
+	}
+	tests.Assert(t, IsTimeout(s.do(NewMockCtx(), 0)))
+
+	// The undo waits for the timed out DoFunc to return
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+	}()
+	tests.Assert(t, s.undo(NewMockCtx()) == nil)
+	tests.Assert(t, strings.Join(order, ",") == "do,undo")
+}
+
+func TestTraceStep(t *testing.T) {
+	c := NewCtx().WithPrefix("prefix")
+	sc, done := traceStep("test.Trace", c)
+	defer done(nil)
+
+	// The step gets its own span, and the context is left alone
+	tc, ok := sc.(*Tctx)
+	tests.Assert(t, ok)
+	tests.Assert(t, tc.span != nil)
+	tests.Assert(t, c.span == nil)
+	tests.Assert(t, tc.Prefix() == c.Prefix())
+}
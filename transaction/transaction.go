@@ -3,9 +3,15 @@ package transaction
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
+	gderrors "github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/logging"
+	"github.com/gluster/glusterd2/pkg/api"
 	"github.com/gluster/glusterd2/store"
+	"github.com/gluster/glusterd2/tracing"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/coreos/etcd/clientv3"
@@ -14,43 +20,76 @@ import (
 
 const (
 	txnPrefix = store.GlusterPrefix + "transaction/"
+
+	// DefaultTxnTimeout is the time a transaction is allowed to run, if the
+	// transaction does not set a timeout
+	DefaultTxnTimeout = 10 * time.Minute
 )
 
+var txnLog = logging.Subsystem(logging.Txn)
+
+// ErrTxnTimeout is returned when a transaction does not finish in time
+var ErrTxnTimeout = errors.New("transaction timed out")
+
+func init() {
+	gderrors.RegisterCode(ErrTxnTimeout, api.ErrCodeTxnTimeout)
+	gderrors.RegisterCode(ErrLockTimeout, api.ErrCodeLockTimeout)
+}
+
 // Txn is a set of steps
 //
 // Nodes is a union of the all the TxnStep.Nodes
+// Timeout is the time the steps of the transaction are allowed to run,
+// excluding any rollback. DefaultTxnTimeout is used if it is not set.
 type Txn struct {
 	// TODO: Any good reason for this to be not just string ?
-	ID    uuid.UUID
-	Ctx   TxnCtx
-	Steps []*Step
-	Nodes []uuid.UUID
+	ID uuid.UUID
+	// ReqID is the ID of the request the transaction is run for. It is
+	// logged with the log lines of the transaction on all the peers.
+	ReqID   string
+	Ctx     TxnCtx
+	Steps   []*Step
+	Nodes   []uuid.UUID
+	Timeout time.Duration
+
+	startTime time.Time
 }
 
 // NewTxn returns an initialized Txn without any steps
 func NewTxn(id string) *Txn {
-	t := new(Txn)
+	t := &Txn{ReqID: id}
 
 	if t.ID = uuid.Parse(id); t.ID == nil {
 		t.ID = uuid.NewRandom()
-		log.WithField("reqid", t.ID.String()).Warn("Invalid UUID set as request ID. Generated new request ID")
+		if t.ReqID == "" {
+			t.ReqID = t.ID.String()
+		}
+		txnLog.WithFields(log.Fields{
+			"reqid": t.ReqID,
+			"txnid": t.ID.String(),
+		}).Debug("request ID is not a UUID, generated new transaction ID")
 	}
 
 	prefix := txnPrefix + t.ID.String()
-	t.Ctx = NewCtxWithLogFields(log.Fields{
-		"reqid": t.ID.String(),
-	}).WithPrefix(prefix)
+	t.Ctx = NewCtxWithLogFields(t.logFields()).WithPrefix(prefix)
 
 	return t
 }
 
+// logFields returns the log fields identifying the transaction
+func (t *Txn) logFields() log.Fields {
+	fields := log.Fields{"reqid": t.ReqID}
+	if t.ReqID != t.ID.String() {
+		fields["txnid"] = t.ID.String()
+	}
+	return fields
+}
+
 // NewTxnWithLoggingContext creates a Txn with a Context with given logging fields
 func NewTxnWithLoggingContext(f log.Fields, id string) *Txn {
 	t := NewTxn(id)
 	prefix := txnPrefix + t.ID.String()
-	t.Ctx = NewCtxWithLogFields(log.Fields{
-		"reqid": t.ID.String(),
-	}).WithPrefix(prefix).WithLogFields(f)
+	t.Ctx = NewCtxWithLogFields(t.logFields()).WithPrefix(prefix).WithLogFields(f)
 
 	return t
 }
@@ -60,10 +99,24 @@ func (t *Txn) Cleanup() {
 	store.Store.Delete(context.TODO(), t.Ctx.Prefix(), clientv3.WithPrefix())
 }
 
-// Do runs the transaction on the cluster
-func (t *Txn) Do() (TxnCtx, error) {
+// Do runs the transaction on the cluster. The transaction is traced under the
+// span of its request, if any.
+func (t *Txn) Do() (_ TxnCtx, err error) {
 	t.Ctx.Logger().Debug("Starting transaction")
 
+	span := tracing.StartSpan("txn", tracing.RequestSpan(t.ReqID))
+	span.SetTag("reqid", t.ReqID)
+	span.SetTag("steps", len(t.Steps))
+	if c, ok := t.Ctx.(*Tctx); ok {
+		c.setSpan(span)
+	}
+	defer func() {
+		if err != nil {
+			tracing.SetError(span, err)
+		}
+		span.Finish()
+	}()
+
 	// verify that all nodes are online
 	for _, node := range t.Nodes {
 		if !store.Store.IsNodeAlive(node) {
@@ -71,8 +124,36 @@ func (t *Txn) Do() (TxnCtx, error) {
 		}
 	}
 
+	timeout := t.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTxnTimeout
+	}
+	t.startTime = time.Now()
+	deadline := t.startTime.Add(timeout)
+
+	// Save the transaction in the transaction log, so that it can be
+	// rolled back if this node crashes before the transaction ends
+	if e := t.saveLog(0); e != nil {
+		t.Ctx.Logger().WithError(e).Error("Failed to save transaction log")
+		return nil, e
+	}
+	defer t.deleteLog()
+
 	//Do the steps
 	for i, s := range t.Steps {
+		remaining := deadline.Sub(time.Now())
+		if remaining <= 0 {
+			t.Ctx.Logger().WithField("timeout", timeout).Error("Transaction timed out, rolling back changes")
+			t.undo(i - 1)
+			return nil, ErrTxnTimeout
+		}
+
+		if e := t.saveLog(i + 1); e != nil {
+			t.Ctx.Logger().WithError(e).Error("Failed to save transaction log, rolling back changes")
+			t.undo(i - 1)
+			return nil, e
+		}
+
 		//TODO: Renable (correctly) if All/Leader keys are fixed
 		//if s.Nodes[0] == All {
 		//s.Nodes = t.Nodes
@@ -80,7 +161,7 @@ func (t *Txn) Do() (TxnCtx, error) {
 		////s.Nodes[0] = LeaderName
 		//}
 
-		if e := s.do(t.Ctx); e != nil {
+		if e := s.do(t.Ctx, remaining); e != nil {
 			t.Ctx.Logger().WithError(e).Error("Transaction failed, rolling back changes")
 			t.undo(i)
 			return nil, e
@@ -97,3 +178,13 @@ func (t *Txn) undo(n int) {
 		t.Steps[i].undo(t.Ctx)
 	}
 }
+
+// IsTimeout returns true if the error is returned for a transaction or one of
+// its steps not finishing in time
+func IsTimeout(err error) bool {
+	if err == ErrTxnTimeout {
+		return true
+	}
+	_, ok := err.(*StepTimeoutError)
+	return ok
+}
@@ -0,0 +1,198 @@
+package transaction
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/gluster/glusterd2/gdctx"
+	"github.com/gluster/glusterd2/store"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/coreos/etcd/clientv3"
+	"github.com/pborman/uuid"
+)
+
+const txnLogPrefix = store.GlusterPrefix + "txnlog/"
+
+// txnLogEntry is the state of an in-flight transaction, saved in the store so
+// that the transaction can be rolled back if its initiator crashes
+type txnLogEntry struct {
+	ID        uuid.UUID   `json:"id"`
+	Initiator uuid.UUID   `json:"initiator"`
+	Steps     []*Step     `json:"steps"`
+	Nodes     []uuid.UUID `json:"nodes"`
+	Ctx       *Tctx       `json:"ctx"`
+	StartTime time.Time   `json:"start-time"`
+	// Started is the number of steps started. All steps before the last
+	// started step have completed.
+	Started int `json:"started"`
+}
+
+func (t *Txn) logKey() string {
+	return txnLogPrefix + t.ID.String()
+}
+
+// saveLog saves the state of the transaction, with the given number of steps
+// started. Only transactions with a Tctx context can be saved.
+func (t *Txn) saveLog(started int) error {
+	ctx, ok := t.Ctx.(*Tctx)
+	if !ok {
+		return nil
+	}
+
+	entry := txnLogEntry{
+		ID:        t.ID,
+		Initiator: gdctx.MyUUID,
+		Steps:     t.Steps,
+		Nodes:     t.Nodes,
+		Ctx:       ctx,
+		StartTime: t.startTime,
+		Started:   started,
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	_, err = store.Store.Put(context.TODO(), t.logKey(), string(data))
+	return err
+}
+
+// deleteLog deletes the saved state of the transaction, once the transaction
+// has completed or has been rolled back
+func (t *Txn) deleteLog() {
+	if _, err := store.Store.Delete(context.TODO(), t.logKey()); err != nil {
+		t.Ctx.Logger().WithError(err).Warn("failed to delete transaction log")
+	}
+}
+
+// Recover rolls back the transactions which were left in-flight in the store
+// by initiators that crashed. This includes transactions initiated by this
+// node before it restarted, and those of initiators which are no longer
+// alive.
+//
+// Steps which had started are undone in the reverse order, as a failed
+// transaction would have been. Undo functions which are not registered on
+// this node, such as those of the locks of the crashed initiator, are skipped.
+// Such locks are released when the session of the initiator expires.
+//
+// Recover must be called after all step functions have been registered.
+func Recover() error {
+	resp, err := store.Store.Get(context.TODO(), txnLogPrefix, clientv3.WithPrefix())
+	if err != nil {
+		return err
+	}
+
+	for _, kv := range resp.Kvs {
+		var entry txnLogEntry
+		if err := json.Unmarshal(kv.Value, &entry); err != nil {
+			txnLog.WithError(err).WithField("key", string(kv.Key)).Error("failed to unmarshal transaction log")
+			continue
+		}
+
+		if !uuid.Equal(entry.Initiator, gdctx.MyUUID) && store.Store.IsNodeAlive(entry.Initiator) {
+			continue
+		}
+
+		// Claim the transaction by deleting its log, so that it is only
+		// recovered by one node
+		claim, err := store.Store.Txn(context.TODO()).
+			If(clientv3.Compare(clientv3.ModRevision(string(kv.Key)), "=", kv.ModRevision)).
+			Then(clientv3.OpDelete(string(kv.Key))).
+			Commit()
+		if err != nil {
+			txnLog.WithError(err).WithField("txnid", entry.ID.String()).Error("failed to claim transaction for recovery")
+			continue
+		}
+		if !claim.Succeeded {
+			continue
+		}
+
+		recoverTxn(&entry)
+	}
+
+	return nil
+}
+
+// recoverTxn rolls back the started steps of the transaction in the entry
+func recoverTxn(entry *txnLogEntry) {
+	t := &Txn{
+		ID:    entry.ID,
+		Ctx:   entry.Ctx,
+		Steps: entry.Steps,
+		Nodes: entry.Nodes,
+	}
+	logger := t.Ctx.Logger().WithFields(log.Fields{
+		"initiator": entry.Initiator.String(),
+		"started":   entry.StartTime,
+	})
+	logger.Warn("recovering in-flight transaction, rolling back changes")
+
+	last := entry.Started - 1
+	if last >= len(t.Steps) {
+		last = len(t.Steps) - 1
+	}
+	for i := last; i >= 0; i-- {
+		s := t.Steps[i]
+		if s.UndoFunc == "" {
+			continue
+		}
+		if _, ok := GetStepFunc(s.UndoFunc); !ok {
+			logger.WithField("stepfunc", s.UndoFunc).Debug("undo function not registered, skipping")
+			continue
+		}
+		if err := s.undo(t.Ctx); err != nil {
+			logger.WithError(err).WithField("stepfunc", s.UndoFunc).Error("failed to undo step")
+		}
+	}
+
+	t.Cleanup()
+	logger.Info("rolled back in-flight transaction")
+}
+
+// OrphanedContexts returns the IDs of the transactions whose context is left
+// in the store without a transaction log. The context of a transaction is
+// normally deleted by Cleanup once the transaction ends, but is left behind
+// if the initiator crashes before the transaction is started or after it has
+// ended.
+//
+// The context of a transaction is set before its log is first saved, so a
+// transaction which is about to start is returned as well. Callers must make
+// sure the context has been orphaned for longer than a transaction can run
+// before deleting it.
+func OrphanedContexts() ([]string, error) {
+	resp, err := store.Store.Get(context.TODO(), txnPrefix, clientv3.WithPrefix(), clientv3.WithKeysOnly())
+	if err != nil {
+		return nil, err
+	}
+	logs, err := store.Store.Get(context.TODO(), txnLogPrefix, clientv3.WithPrefix(), clientv3.WithKeysOnly())
+	if err != nil {
+		return nil, err
+	}
+
+	logged := make(map[string]bool, len(logs.Kvs))
+	for _, kv := range logs.Kvs {
+		logged[strings.TrimPrefix(string(kv.Key), txnLogPrefix)] = true
+	}
+
+	seen := make(map[string]bool)
+	var ids []string
+	for _, kv := range resp.Kvs {
+		id := strings.SplitN(strings.TrimPrefix(string(kv.Key), txnPrefix), "/", 2)[0]
+		if id == "" || seen[id] || logged[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// DeleteContext deletes the context of the transaction with the given ID from
+// the store
+func DeleteContext(id string) error {
+	_, err := store.Store.Delete(context.TODO(), txnPrefix+id+"/", clientv3.WithPrefix())
+	return err
+}
@@ -0,0 +1,303 @@
+package transaction
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gluster/glusterd2/gdctx"
+	"github.com/gluster/glusterd2/store"
+	"github.com/gluster/glusterd2/tests"
+
+	"github.com/coreos/etcd/clientv3"
+	pb "github.com/coreos/etcd/etcdserver/etcdserverpb"
+	"github.com/coreos/etcd/mvcc/mvccpb"
+	heketitests "github.com/heketi/tests"
+	"github.com/pborman/uuid"
+)
+
+// memKV is an in-memory clientv3.KV, implementing the operations used by the
+// transaction log
+type memKV struct {
+	clientv3.KV
+	sync.Mutex
+	rev int64
+	kvs map[string]*mvccpb.KeyValue
+	// onGet is called after every get of the given key, without the lock
+	// held
+	onGet func(key string)
+}
+
+func newMemKV() *memKV {
+	return &memKV{rev: 1, kvs: make(map[string]*mvccpb.KeyValue)}
+}
+
+// patchStore makes the store use the given KV
+func patchStore(kv *memKV) func() {
+	p := heketitests.Patch(&store.Store, &store.GDStore{KV: kv})
+	return p.Restore
+}
+
+// matching returns the sorted keys in the range of the operation
+func (m *memKV) matching(op clientv3.Op) []string {
+	begin, end := string(op.KeyBytes()), string(op.RangeBytes())
+	var keys []string
+	for k := range m.kvs {
+		if k == begin || (end != "" && k >= begin && k < end) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (m *memKV) put(key, value string) {
+	m.rev++
+	kv, ok := m.kvs[key]
+	if !ok {
+		kv = &mvccpb.KeyValue{Key: []byte(key), CreateRevision: m.rev}
+		m.kvs[key] = kv
+	}
+	kv.Value = []byte(value)
+	kv.ModRevision = m.rev
+}
+
+func (m *memKV) delete(op clientv3.Op) {
+	m.rev++
+	for _, k := range m.matching(op) {
+		delete(m.kvs, k)
+	}
+}
+
+func (m *memKV) Get(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	m.Lock()
+	resp := &clientv3.GetResponse{Header: &pb.ResponseHeader{Revision: m.rev}}
+	for _, k := range m.matching(clientv3.OpGet(key, opts...)) {
+		kv := *m.kvs[k]
+		resp.Kvs = append(resp.Kvs, &kv)
+	}
+	resp.Count = int64(len(resp.Kvs))
+	m.Unlock()
+
+	if m.onGet != nil {
+		m.onGet(key)
+	}
+	return resp, nil
+}
+
+func (m *memKV) Put(ctx context.Context, key, val string, opts ...clientv3.OpOption) (*clientv3.PutResponse, error) {
+	m.Lock()
+	defer m.Unlock()
+	m.put(key, val)
+	return &clientv3.PutResponse{Header: &pb.ResponseHeader{Revision: m.rev}}, nil
+}
+
+func (m *memKV) Delete(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.DeleteResponse, error) {
+	m.Lock()
+	defer m.Unlock()
+	m.delete(clientv3.OpDelete(key, opts...))
+	return &clientv3.DeleteResponse{Header: &pb.ResponseHeader{Revision: m.rev}}, nil
+}
+
+func (m *memKV) Txn(ctx context.Context) clientv3.Txn {
+	return &memTxn{kv: m}
+}
+
+type memTxn struct {
+	kv    *memKV
+	cmps  []clientv3.Cmp
+	thens []clientv3.Op
+}
+
+func (t *memTxn) If(cs ...clientv3.Cmp) clientv3.Txn {
+	t.cmps = append(t.cmps, cs...)
+	return t
+}
+
+func (t *memTxn) Then(ops ...clientv3.Op) clientv3.Txn {
+	t.thens = append(t.thens, ops...)
+	return t
+}
+
+func (t *memTxn) Else(ops ...clientv3.Op) clientv3.Txn {
+	panic("unsupported else")
+}
+
+// Commit supports comparing the mod revision of keys, and delete operations
+func (t *memTxn) Commit() (*clientv3.TxnResponse, error) {
+	t.kv.Lock()
+	defer t.kv.Unlock()
+
+	succeeded := true
+	for _, c := range t.cmps {
+		var rev int64
+		if kv, ok := t.kv.kvs[string(c.Key)]; ok {
+			rev = kv.ModRevision
+		}
+		if c.Target != pb.Compare_MOD || c.Result != pb.Compare_EQUAL {
+			panic("unsupported comparison")
+		}
+		want := c.TargetUnion.(*pb.Compare_ModRevision).ModRevision
+		succeeded = succeeded && rev == want
+	}
+
+	if succeeded {
+		for _, op := range t.thens {
+			if !op.IsDelete() {
+				panic("unsupported operation")
+			}
+			t.kv.delete(op)
+		}
+	}
+	return &clientv3.TxnResponse{Header: &pb.ResponseHeader{Revision: t.kv.rev}, Succeeded: succeeded}, nil
+}
+
+// logTxn saves the log of a transaction of the given initiator, with a value
+// set in its context
+func logTxn(t *testing.T, initiator uuid.UUID, started int, steps ...*Step) *Txn {
+	defer heketitests.Patch(&gdctx.MyUUID, initiator).Restore()
+
+	txn := NewTxn("")
+	txn.Steps = steps
+	tests.Assert(t, txn.Ctx.Set("key", "value") == nil)
+	tests.Assert(t, txn.saveLog(started) == nil)
+	return txn
+}
+
+// recorder returns step functions which record their names when called
+func recorder(names ...string) *[]string {
+	var lock sync.Mutex
+	var called []string
+	for _, name := range names {
+		name := name
+		RegisterStepFunc(func(TxnCtx) error {
+			lock.Lock()
+			called = append(called, name)
+			lock.Unlock()
+			return nil
+		}, name)
+	}
+	return &called
+}
+
+// logged returns whether the log and the context of the transaction are in
+// the store
+func logged(kv *memKV, txn *Txn) (bool, bool) {
+	_, log := kv.kvs[txn.logKey()]
+	_, ctx := kv.kvs[txn.Ctx.Prefix()+"/key"]
+	return log, ctx
+}
+
+func TestRecoverClaim(t *testing.T) {
+	defer patchNode()()
+	kv := newMemKV()
+	defer patchStore(kv)()
+
+	undone := recorder("test.Claim.Undo")
+	step := func() *Step {
+		return &Step{UndoFunc: "test.Claim.Undo", Nodes: []uuid.UUID{gdctx.MyUUID}}
+	}
+
+	alive := uuid.NewRandom()
+	kv.put(store.GlusterPrefix+"alive/"+alive.String(), "")
+
+	mine := logTxn(t, gdctx.MyUUID, 1, step())
+	dead := logTxn(t, uuid.NewRandom(), 1, step())
+	running := logTxn(t, alive, 1, step())
+
+	tests.Assert(t, Recover() == nil)
+
+	// Transactions of this node and of dead nodes are rolled back and
+	// cleaned up
+	tests.Assert(t, len(*undone) == 2)
+	for _, txn := range []*Txn{mine, dead} {
+		log, ctx := logged(kv, txn)
+		tests.Assert(t, !log && !ctx)
+	}
+
+	// Transactions of live nodes are left alone
+	log, ctx := logged(kv, running)
+	tests.Assert(t, log && ctx)
+
+	// A transaction whose log changes after being read is not claimed
+	*undone = nil
+	changed := logTxn(t, gdctx.MyUUID, 1, step())
+	kv.onGet = func(key string) {
+		if key == txnLogPrefix {
+			kv.Lock()
+			kv.put(changed.logKey(), string(kv.kvs[changed.logKey()].Value))
+			kv.Unlock()
+		}
+	}
+	tests.Assert(t, Recover() == nil)
+	tests.Assert(t, len(*undone) == 0)
+	log, ctx = logged(kv, changed)
+	tests.Assert(t, log && ctx)
+}
+
+func TestRecoverUndoOrder(t *testing.T) {
+	defer patchNode()()
+	kv := newMemKV()
+	defer patchStore(kv)()
+
+	undone := recorder("test.Order.a", "test.Order.c", "test.Order.d")
+	nodes := []uuid.UUID{gdctx.MyUUID}
+	steps := []*Step{
+		{UndoFunc: "test.Order.a", Nodes: nodes},
+		// Steps without an UndoFunc, or with an UndoFunc not registered
+		// on this node, are skipped
+		{Nodes: nodes},
+		{UndoFunc: "test.Order.NotRegistered", Nodes: nodes},
+		{UndoFunc: "test.Order.c", Nodes: nodes},
+		// Steps which had not started are not undone
+		{UndoFunc: "test.Order.d", Nodes: nodes},
+	}
+
+	// Started steps are undone in reverse order
+	logTxn(t, gdctx.MyUUID, 4, steps...)
+	tests.Assert(t, Recover() == nil)
+	tests.Assert(t, strings.Join(*undone, ",") == "test.Order.c,test.Order.a")
+
+	// A transaction which had not started any step has nothing to undo
+	*undone = nil
+	logTxn(t, gdctx.MyUUID, 0, steps...)
+	tests.Assert(t, Recover() == nil)
+	tests.Assert(t, len(*undone) == 0)
+}
+
+func TestRecoverRace(t *testing.T) {
+	defer patchNode()()
+	kv := newMemKV()
+	defer patchStore(kv)()
+
+	undone := recorder("test.Race.Undo")
+	txn := logTxn(t, uuid.NewRandom(), 1, &Step{UndoFunc: "test.Race.Undo", Nodes: []uuid.UUID{gdctx.MyUUID}})
+
+	// Both nodes read the transaction log before either claims the
+	// transaction
+	var read sync.WaitGroup
+	read.Add(2)
+	kv.onGet = func(key string) {
+		if key == txnLogPrefix {
+			read.Done()
+			read.Wait()
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tests.Assert(t, Recover() == nil)
+		}()
+	}
+	wg.Wait()
+
+	// Only one of the nodes rolls back the transaction
+	tests.Assert(t, len(*undone) == 1)
+	log, ctx := logged(kv, txn)
+	tests.Assert(t, !log && !ctx)
+}
@@ -0,0 +1,203 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gluster/glusterd2/errors"
+)
+
+const (
+	// recommendedXFSInodeSize is the inode size recommended for XFS bricks,
+	// for the gluster xattrs to fit in the inode
+	recommendedXFSInodeSize = 512
+)
+
+var (
+	mountsFile = "/proc/mounts"
+
+	// unsupportedBrickFS are the filesystems bricks cannot be on, as they
+	// are volatile, lack xattr support or are not local
+	unsupportedBrickFS = []string{
+		"tmpfs", "ramfs", "devtmpfs", "proc", "sysfs", "iso9660", "squashfs",
+		"vfat", "nfs", "nfs4", "cifs", "fuse.glusterfs",
+	}
+
+	// XFSInodeSize returns the inode size of the XFS filesystem mounted at
+	// the given mount point
+	XFSInodeSize = xfsInodeSize
+
+	isizeRegexp = regexp.MustCompile(`isize=(\d+)`)
+)
+
+// MountInfo is an entry in the mount table
+type MountInfo struct {
+	Device     string
+	MountPoint string
+	FSType     string
+	Options    []string
+}
+
+// HasOption returns true if the filesystem is mounted with the given option
+func (m *MountInfo) HasOption(opt string) bool {
+	for _, o := range m.Options {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// unescapeMountField decodes the octal escapes used for spaces, tabs and
+// backslashes in the fields of the mount table
+func unescapeMountField(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var b bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+4 <= len(s) {
+			if c, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// GetMounts returns the entries of the mount table
+func GetMounts() ([]MountInfo, error) {
+	f, err := os.Open(mountsFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var mounts []MountInfo
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 {
+			continue
+		}
+		mounts = append(mounts, MountInfo{
+			Device:     unescapeMountField(fields[0]),
+			MountPoint: unescapeMountField(fields[1]),
+			FSType:     fields[2],
+			Options:    strings.Split(fields[3], ","),
+		})
+	}
+
+	return mounts, scanner.Err()
+}
+
+// GetMountInfo returns the mount table entry of the filesystem the given path
+// is on. The path need not exist yet, in which case the filesystem of its
+// closest existing parent is returned.
+func GetMountInfo(p string) (*MountInfo, error) {
+	existing, _, err := NearestExistingPath(p)
+	if err != nil {
+		return nil, err
+	}
+	if resolved, err := filepath.EvalSymlinks(existing); err == nil {
+		existing = resolved
+	}
+
+	mounts, err := GetMounts()
+	if err != nil {
+		return nil, err
+	}
+
+	// The last mount on the longest matching mount point is the one in
+	// effect
+	var found *MountInfo
+	for i := range mounts {
+		m := &mounts[i]
+		if !IsPathUnder(existing, m.MountPoint) {
+			continue
+		}
+		if found == nil || len(m.MountPoint) >= len(found.MountPoint) {
+			found = m
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("mount point of %s not found", p)
+	}
+	return found, nil
+}
+
+// IsPathUnder returns true if p is dir or is under dir. Both paths must be
+// clean.
+func IsPathUnder(p, dir string) bool {
+	if dir == "/" || p == dir {
+		return true
+	}
+	return strings.HasPrefix(p, dir+"/")
+}
+
+// IsBrickFSSupported returns false if bricks cannot be on the given type of
+// filesystem
+func IsBrickFSSupported(fstype string) bool {
+	for _, fs := range unsupportedBrickFS {
+		if fstype == fs {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckBrickFS checks if the filesystem of the brick path is supported. If
+// force is set, an unsupported filesystem is only warned about.
+//
+// Warnings are returned for settings of the filesystem which are not
+// recommended for bricks.
+func CheckBrickFS(brickPath string, force bool) ([]string, error) {
+	m, err := GetMountInfo(brickPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var warnings []string
+	if !IsBrickFSSupported(m.FSType) {
+		if !force {
+			return nil, errors.ErrBrickFSNotSupported
+		}
+		warnings = append(warnings, fmt.Sprintf("brick %s is on a %s filesystem, which is not supported", brickPath, m.FSType))
+	}
+
+	if !m.HasOption("noatime") {
+		warnings = append(warnings, fmt.Sprintf("filesystem of brick %s is not mounted with noatime", brickPath))
+	}
+
+	if m.FSType == "xfs" {
+		if isize, err := XFSInodeSize(m.MountPoint); err == nil && isize < recommendedXFSInodeSize {
+			warnings = append(warnings, fmt.Sprintf("XFS filesystem of brick %s has an inode size of %d, %d is recommended", brickPath, isize, recommendedXFSInodeSize))
+		}
+	}
+
+	return warnings, nil
+}
+
+func xfsInodeSize(mountPoint string) (int, error) {
+	out, err := exec.Command("xfs_info", mountPoint).Output()
+	if err != nil {
+		return 0, err
+	}
+
+	match := isizeRegexp.FindSubmatch(out)
+	if match == nil {
+		return 0, fmt.Errorf("inode size not found in xfs_info output of %s", mountPoint)
+	}
+	return strconv.Atoi(string(match[1]))
+}
@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/tests"
+
+	heketitests "github.com/heketi/tests"
+)
+
+func TestUnescapeMountField(t *testing.T) {
+	for _, c := range []struct {
+		field    string
+		expected string
+	}{
+		{"/mnt/brick", "/mnt/brick"},
+		{`/mnt/my\040brick`, "/mnt/my brick"},
+		{`/mnt/tab\011brick`, "/mnt/tab\tbrick"},
+		{`/mnt/back\134slash`, `/mnt/back\slash`},
+		{`/mnt/a\040b\040c`, "/mnt/a b c"},
+		// Backslashes not followed by an octal escape are kept
+		{`/mnt/not\08escape`, `/mnt/not\08escape`},
+		{`/mnt/short\04`, `/mnt/short\04`},
+		{`/mnt/trailing\`, `/mnt/trailing\`},
+	} {
+		tests.Assert(t, unescapeMountField(c.field) == c.expected)
+	}
+}
+
+// patchMounts makes the mount table the given entries, and returns the
+// directory the test mount points are under
+func patchMounts(t *testing.T, entries ...string) (string, func()) {
+	dir, err := ioutil.TempDir("", "mounts")
+	tests.Assert(t, err == nil)
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+
+	table := filepath.Join(dir, "mounts")
+	data := strings.Replace(strings.Join(entries, "\n"), "<dir>", dir, -1)
+	tests.Assert(t, ioutil.WriteFile(table, []byte(data+"\n"), 0600) == nil)
+
+	p := heketitests.Patch(&mountsFile, table)
+	return dir, func() {
+		p.Restore()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestGetMounts(t *testing.T) {
+	_, restore := patchMounts(t,
+		"/dev/sda1 / ext4 rw,relatime 0 0",
+		`/dev/mapper/my\040vg-lv <dir>/my\040brick xfs rw,noatime 0 0`,
+		"malformed line",
+	)
+	defer restore()
+
+	mounts, err := GetMounts()
+	tests.Assert(t, err == nil)
+	tests.Assert(t, len(mounts) == 2)
+	tests.Assert(t, mounts[1].Device == "/dev/mapper/my vg-lv")
+	tests.Assert(t, strings.HasSuffix(mounts[1].MountPoint, "/my brick"))
+	tests.Assert(t, mounts[1].FSType == "xfs")
+	tests.Assert(t, mounts[1].HasOption("noatime"))
+	tests.Assert(t, !mounts[0].HasOption("noatime"))
+}
+
+func TestGetMountInfo(t *testing.T) {
+	dir, restore := patchMounts(t,
+		"/dev/sda1 / ext4 rw,relatime 0 0",
+		`/dev/sdb1 <dir>/my\040brick xfs rw,noatime 0 0`,
+		`/dev/sdc1 <dir>/tab\011brick xfs rw 0 0`,
+		// Remounted on the same mount point
+		`/dev/sdc2 <dir>/tab\011brick ext4 rw,noatime 0 0`,
+	)
+	defer restore()
+
+	for _, d := range []string{"my brick", "tab\tbrick", "other"} {
+		tests.Assert(t, os.Mkdir(filepath.Join(dir, d), 0755) == nil)
+	}
+
+	for _, c := range []struct {
+		path   string
+		device string
+	}{
+		{filepath.Join(dir, "my brick"), "/dev/sdb1"},
+		// Paths which don't exist yet are on the filesystem of their
+		// closest existing parent
+		{filepath.Join(dir, "my brick", "b1", "data"), "/dev/sdb1"},
+		{filepath.Join(dir, "tab\tbrick", "b1"), "/dev/sdc2"},
+		{filepath.Join(dir, "other", "b1"), "/dev/sda1"},
+		// Mount points are matched on whole path components
+		{filepath.Join(dir, "my brick2"), "/dev/sda1"},
+	} {
+		m, err := GetMountInfo(c.path)
+		tests.Assert(t, err == nil)
+		tests.Assert(t, m.Device == c.device)
+	}
+}
+
+func TestCheckBrickFS(t *testing.T) {
+	dir, restore := patchMounts(t,
+		"/dev/sda1 / ext4 rw,relatime 0 0",
+		`tmpfs <dir>/tmp\040fs tmpfs rw 0 0`,
+		`/dev/sdb1 <dir>/xfs\040brick xfs rw,noatime 0 0`,
+	)
+	defer restore()
+	defer heketitests.Patch(&XFSInodeSize, func(string) (int, error) {
+		return 256, nil
+	}).Restore()
+
+	for _, d := range []string{"tmp fs", "xfs brick"} {
+		tests.Assert(t, os.Mkdir(filepath.Join(dir, d), 0755) == nil)
+	}
+
+	tmpfs := filepath.Join(dir, "tmp fs", "b1")
+	_, err := CheckBrickFS(tmpfs, false)
+	tests.Assert(t, err == errors.ErrBrickFSNotSupported)
+
+	warnings, err := CheckBrickFS(tmpfs, true)
+	tests.Assert(t, err == nil)
+	tests.Assert(t, len(warnings) == 2)
+
+	warnings, err = CheckBrickFS(filepath.Join(dir, "xfs brick", "b1"), false)
+	tests.Assert(t, err == nil)
+	tests.Assert(t, len(warnings) == 1)
+	tests.Assert(t, strings.Contains(warnings[0], fmt.Sprintf("%d", recommendedXFSInodeSize)))
+}
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/gluster/glusterd2/errors"
+
+	"golang.org/x/sys/unix"
+)
+
+var (
+	// PathMax calls unix.PathMax
+	PathMax = unix.PathMax
+)
+
+// GetDeviceID fetches the device id of the device containing the file/directory
+func GetDeviceID(f os.FileInfo) (int, error) {
+	s := f.Sys()
+	switch s := s.(type) {
+	//TODO : Need to change syscall to unix, using unix.Stat_t fails in one
+	//of the test
+	case *syscall.Stat_t:
+		return int(s.Dev), nil
+	}
+	return -1, errors.ErrDeviceIDNotFound
+}
+
+// checkWritable returns an error if the directory doesn't have write
+// permission
+func checkWritable(path string) error {
+	return unix.Access(path, unix.W_OK)
+}
@@ -0,0 +1,31 @@
+// +build !linux
+
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+
+	"github.com/gluster/glusterd2/errors"
+)
+
+var (
+	// PathMax is the PATH_MAX of Linux, which bricks are limited to
+	PathMax = 4096
+)
+
+// GetDeviceID fetches the device id of the device containing the
+// file/directory. Device ids are available only on Linux.
+func GetDeviceID(f os.FileInfo) (int, error) {
+	return -1, errors.ErrDeviceIDNotFound
+}
+
+// checkWritable returns an error if a file cannot be created in the directory
+func checkWritable(path string) error {
+	f, err := ioutil.TempFile(path, ".write-check")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/utils/xattr"
+)
+
+const (
+	selinuxEnforceFile = "/sys/fs/selinux/enforce"
+	selinuxXattr       = "security.selinux"
+
+	// BrickSELinuxType is the SELinux type bricks need to be labeled with
+	// for the brick processes to be allowed to access them
+	BrickSELinuxType    = "glusterd_brick_t"
+	brickSELinuxContext = "system_u:object_r:" + BrickSELinuxType + ":s0"
+)
+
+// IsSELinuxEnabled returns true if SELinux is enabled, whether it is
+// enforcing its policy or not
+func IsSELinuxEnabled() bool {
+	_, err := os.Stat(selinuxEnforceFile)
+	return err == nil
+}
+
+// IsSELinuxEnforcing returns true if SELinux is enabled and enforcing its
+// policy
+func IsSELinuxEnforcing() bool {
+	b, err := ioutil.ReadFile(selinuxEnforceFile)
+	if err != nil {
+		return false
+	}
+	return string(bytes.TrimSpace(b)) == "1"
+}
+
+// GetSELinuxContext returns the SELinux security context of the path
+func GetSELinuxContext(p string) (string, error) {
+	b, err := xattr.Get(p, selinuxXattr)
+	if err != nil {
+		return "", err
+	}
+	return string(bytes.TrimRight(b, "\x00")), nil
+}
+
+// SetSELinuxContext sets the SELinux security context of the path
+func SetSELinuxContext(p string, context string) error {
+	// The context is stored NUL terminated, as done by setfilecon
+	return xattr.Set(p, selinuxXattr, append([]byte(context), 0))
+}
+
+// selinuxType returns the type field of an SELinux security context of the
+// form user:role:type:level
+func selinuxType(context string) string {
+	fields := strings.SplitN(context, ":", 4)
+	if len(fields) < 3 {
+		return ""
+	}
+	return fields[2]
+}
+
+// SetBrickSELinuxContext labels the brick path with the context of bricks, if
+// SELinux is enforcing
+func SetBrickSELinuxContext(brickPath string) error {
+	if !IsSELinuxEnforcing() {
+		return nil
+	}
+	return SetSELinuxContext(brickPath, brickSELinuxContext)
+}
+
+// CheckBrickSELinuxContext returns a warning if SELinux is enforcing and the
+// brick path is labeled with a type other than BrickSELinuxType. Brick paths
+// which don't exist yet are labeled when they are created, and are not
+// warned about.
+func CheckBrickSELinuxContext(brickPath string) (string, error) {
+	if !IsSELinuxEnforcing() {
+		return "", nil
+	}
+	if _, err := os.Lstat(brickPath); os.IsNotExist(err) {
+		return "", nil
+	}
+
+	context, err := GetSELinuxContext(brickPath)
+	if err != nil {
+		return "", err
+	}
+	if t := selinuxType(context); t != BrickSELinuxType {
+		return fmt.Sprintf("brick %s has the SELinux type %s instead of %s, relabel it for the brick to be accessible",
+			brickPath, t, BrickSELinuxType), nil
+	}
+	return "", nil
+}
+
+// RelabelBrick labels the brick path and everything under it with the
+// context of bricks. Symbolic links are not followed. Bricks can be relabeled
+// as long as SELinux is enabled, even if it is not enforcing. Callers must make
+// sure the path is a brick of a volume on this peer, as anything under it is
+// relabeled.
+func RelabelBrick(brickPath string) error {
+	if !IsSELinuxEnabled() {
+		return errors.ErrSELinuxNotEnabled
+	}
+	return filepath.Walk(brickPath, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return nil
+		}
+		return SetSELinuxContext(p, brickSELinuxContext)
+	})
+}
@@ -1,8 +1,5 @@
 package utils
 
-// #include "limits.h"
-import "C"
-
 import (
 	"net"
 	"os"
@@ -11,12 +8,10 @@ import (
 	"reflect"
 	"runtime"
 	"strings"
-	"syscall"
-
-	"golang.org/x/sys/unix"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/utils/xattr"
 	"github.com/pborman/uuid"
 )
 
@@ -26,19 +21,9 @@ const (
 	gfidXattr     = "trusted.gfid"
 )
 
-var (
-	// PathMax calls unix.PathMax
-	PathMax = unix.PathMax
-	// Removexattr calls unix.Removexattr
-	Removexattr = unix.Removexattr
-	// Setxattr calls unix.Setxattr
-	Setxattr = unix.Setxattr
-	// Getxattr calls unix.Getxattr
-	Getxattr = unix.Getxattr
-)
-
-//PosixPathMax represents C's POSIX_PATH_MAX
-const PosixPathMax = C._POSIX_PATH_MAX
+// PosixPathMax is _POSIX_PATH_MAX, the minimum PATH_MAX required by POSIX. It
+// is the same on all platforms.
+const PosixPathMax = 256
 
 // IsLocalAddress checks whether a given host/IP is local
 // Does lookup only after string matching IP addresses
@@ -125,18 +110,6 @@ func ValidateBrickSubDirLength(brickPath string) error {
 	return nil
 }
 
-//GetDeviceID fetches the device id of the device containing the file/directory
-func GetDeviceID(f os.FileInfo) (int, error) {
-	s := f.Sys()
-	switch s := s.(type) {
-	//TODO : Need to change syscall to unix, using unix.Stat_t fails in one
-	//of the test
-	case *syscall.Stat_t:
-		return int(s.Dev), nil
-	}
-	return -1, errors.ErrDeviceIDNotFound
-}
-
 //ValidateBrickPathStats checks whether the brick directory can be created with
 //certain validations like directory checks, whether directory is part of mount
 //point etc
@@ -227,6 +200,24 @@ func ValidateBrickPathStats(brickPath string, host string, force bool) error {
 			return errors.ErrBrickUnderRootPartition
 		}
 
+		if m, e := GetMountInfo(brickPath); e == nil && !IsBrickFSSupported(m.FSType) {
+			log.WithFields(log.Fields{
+				"host":   host,
+				"brick":  brickPath,
+				"fstype": m.FSType,
+			}).Error(errors.ErrBrickFSNotSupported.Error())
+			return errors.ErrBrickFSNotSupported
+		}
+
+	}
+
+	// Label new brick directories for the brick processes to be able to
+	// access them. Existing directories are left as they are, and are
+	// warned about if they have the wrong label.
+	if created {
+		if err := SetBrickSELinuxContext(brickPath); err != nil {
+			log.WithError(err).WithField("brick", brickPath).Warn("failed to set SELinux context of brick")
+		}
 	}
 
 	// Workaround till https://review.gluster.org/#/c/18003/ gets in
@@ -238,12 +229,110 @@ func ValidateBrickPathStats(brickPath string, host string, force bool) error {
 	return nil
 }
 
+// NearestExistingPath returns the given path if it exists, or its closest
+// ancestor which does, along with its stat
+func NearestExistingPath(p string) (string, os.FileInfo, error) {
+	p = filepath.Clean(p)
+	for {
+		stat, err := os.Lstat(p)
+		if err == nil {
+			return p, stat, nil
+		}
+		if !os.IsNotExist(err) || p == "/" {
+			return "", nil, err
+		}
+		p = path.Dir(p)
+	}
+}
+
+//CheckBrickPathStats does the same validations as ValidateBrickPathStats but
+//does not create the brick directory. If the brick path does not exist yet,
+//the checks are done against the closest existing parent directory.
+func CheckBrickPathStats(brickPath string, force bool) error {
+	existing, brickStat, err := NearestExistingPath(brickPath)
+	if err != nil {
+		return err
+	}
+	if !brickStat.IsDir() {
+		return errors.ErrBrickNotDirectory
+	}
+
+	if force {
+		return nil
+	}
+
+	rootStat, err := os.Lstat("/")
+	if err != nil {
+		return err
+	}
+
+	// A brick directory that doesn't exist yet will be created on the same
+	// device as its closest existing parent
+	parentStat := brickStat
+	if existing == filepath.Clean(brickPath) {
+		parentStat, err = os.Lstat(path.Dir(existing))
+		if err != nil {
+			return err
+		}
+	}
+
+	brickDeviceID, err := GetDeviceID(brickStat)
+	if err != nil {
+		return err
+	}
+	parentDeviceID, err := GetDeviceID(parentStat)
+	if err != nil {
+		return err
+	}
+	rootDeviceID, err := GetDeviceID(rootStat)
+	if err != nil {
+		return err
+	}
+
+	if brickDeviceID != parentDeviceID {
+		return errors.ErrBrickIsMountPoint
+	} else if parentDeviceID == rootDeviceID {
+		return errors.ErrBrickUnderRootPartition
+	}
+
+	return nil
+}
+
+//CheckXattrSupport checks whether the file system on which the brick will
+//reside supports extended attributes and whether the brick path is already in
+//use. Unlike ValidateXattrSupport, the brick is not marked as in use.
+func CheckXattrSupport(brickPath string, force bool) error {
+	p, _, err := NearestExistingPath(brickPath)
+	if err != nil {
+		return err
+	}
+
+	if err := xattr.Set(p, testXattr, []byte("working")); err != nil {
+		return err
+	}
+	if err := xattr.Remove(p, testXattr); err != nil {
+		return err
+	}
+
+	if !force {
+		inUse, err := isBrickPathAlreadyInUse(brickPath)
+		if err != nil {
+			return err
+		}
+		if inUse {
+			return errors.ErrBrickPathAlreadyInUse
+		}
+	}
+
+	return nil
+}
+
 //ValidateXattrSupport checks whether the underlying file system has extended
 //attribute support and it also sets some internal xattrs to mark the brick in
 //use
 func ValidateXattrSupport(brickPath string, host string, volid uuid.UUID, force bool) error {
 	var err error
-	err = Setxattr(brickPath, "trusted.glusterfs.test", []byte("working"), 0)
+	err = xattr.Set(brickPath, testXattr, []byte("working"))
 	if err != nil {
 		log.WithFields(log.Fields{"error": err.Error(),
 			"brickPath": brickPath,
@@ -251,7 +340,7 @@ func ValidateXattrSupport(brickPath string, host string, volid uuid.UUID, force
 			"xattr":     testXattr}).Error("setxattr failed")
 		return err
 	}
-	err = Removexattr(brickPath, "trusted.glusterfs.test")
+	err = xattr.Remove(brickPath, testXattr)
 	if err != nil {
 		log.WithFields(log.Fields{"error": err.Error(),
 			"brickPath": brickPath,
@@ -260,14 +349,21 @@ func ValidateXattrSupport(brickPath string, host string, volid uuid.UUID, force
 		return err
 	}
 	if !force {
-		if isBrickPathAlreadyInUse(brickPath) {
+		inUse, err := isBrickPathAlreadyInUse(brickPath)
+		if err != nil {
+			log.WithFields(log.Fields{"error": err.Error(),
+				"brickPath": brickPath,
+				"host":      host}).Error("failed to check if brick path is in use")
+			return err
+		}
+		if inUse {
 			log.WithFields(log.Fields{
 				"brickPath": brickPath,
 				"host":      host}).Error(errors.ErrBrickPathAlreadyInUse.Error())
 			return errors.ErrBrickPathAlreadyInUse
 		}
 	}
-	err = Setxattr(brickPath, volumeIDXattr, []byte(volid), 0)
+	err = xattr.SetUUID(brickPath, volumeIDXattr, volid)
 	if err != nil {
 		log.WithFields(log.Fields{"error": err.Error(),
 			"brickPath": brickPath,
@@ -279,25 +375,48 @@ func ValidateXattrSupport(brickPath string, host string, volid uuid.UUID, force
 	return nil
 }
 
-func isBrickPathAlreadyInUse(brickPath string) bool {
+// CleanupBrickXattrs removes the internal xattrs set by glusterd and the
+// brick process on the brick path, along with the .glusterfs directory, so
+// that the path can be used again as a brick
+func CleanupBrickXattrs(brickPath string) error {
+	for _, key := range []string{volumeIDXattr, gfidXattr} {
+		err := xattr.Remove(brickPath, key)
+		if err != nil {
+			log.WithFields(log.Fields{"error": err.Error(),
+				"brickPath": brickPath,
+				"xattr":     key}).Error("removexattr failed")
+			return err
+		}
+	}
+
+	if err := os.RemoveAll(filepath.Join(brickPath, ".glusterfs")); err != nil {
+		log.WithError(err).WithField("brickPath", brickPath).Error(
+			"failed to remove internal directory")
+		return err
+	}
+
+	return nil
+}
+
+// isBrickPathAlreadyInUse returns true if the brick path or any of its parent
+// directories is, or has been, a brick. Directories which do not exist yet or
+// are on filesystems without xattr support cannot have been bricks.
+func isBrickPathAlreadyInUse(brickPath string) (bool, error) {
 	keys := []string{gfidXattr, volumeIDXattr}
-	var p string
-	var buf []byte
-	p = brickPath
-	for ; p != "/"; p = path.Dir(p) {
+	for p := filepath.Clean(brickPath); p != "/" && p != "."; p = path.Dir(p) {
 		for _, key := range keys {
-			size, err := Getxattr(p, key, buf)
-			if err != nil {
-				return false
-			} else if size > 0 {
-				return true
-			} else {
-				return false
+			exists, err := xattr.Exists(p, key)
+			if os.IsNotExist(err) || xattr.IsNotSupported(err) {
+				break
+			} else if err != nil {
+				return false, err
+			}
+			if exists {
+				return true, nil
 			}
-
 		}
 	}
-	return false
+	return false, nil
 }
 
 // InitDir creates directory path and checks if files can be created in it.
@@ -311,7 +430,7 @@ func InitDir(path string) error {
 		return err
 	}
 
-	if err := unix.Access(path, unix.W_OK); err != nil {
+	if err := checkWritable(path); err != nil {
 		log.WithError(err).WithField("path", path).Debug(
 			"directory does not have write permission")
 		return err
@@ -9,6 +9,7 @@ import (
 	"golang.org/x/sys/unix"
 
 	"github.com/gluster/glusterd2/tests"
+	"github.com/gluster/glusterd2/utils/xattr"
 
 	"github.com/pborman/uuid"
 
@@ -91,9 +92,9 @@ func TestValidateBrickPathStats(t *testing.T) {
 }
 
 func TestValidateXattrSupport(t *testing.T) {
-	defer heketitests.Patch(&Setxattr, tests.MockSetxattr).Restore()
-	defer heketitests.Patch(&Getxattr, tests.MockGetxattr).Restore()
-	defer heketitests.Patch(&Removexattr, tests.MockRemovexattr).Restore()
+	defer heketitests.Patch(&xattr.Setxattr, tests.MockSetxattr).Restore()
+	defer heketitests.Patch(&xattr.Getxattr, tests.MockGetxattr).Restore()
+	defer heketitests.Patch(&xattr.Removexattr, tests.MockRemovexattr).Restore()
 	tests.Assert(t, ValidateXattrSupport("/tmp/b1", "localhost", uuid.NewRandom(), true) == nil)
 
 	// Some negative tests
@@ -102,19 +103,19 @@ func TestValidateXattrSupport(t *testing.T) {
 	xattrErr = baderror
 
 	// Now check what happens when setxattr fails
-	defer heketitests.Patch(&Setxattr, func(path string, attr string, data []byte, flags int) (err error) {
+	defer heketitests.Patch(&xattr.Setxattr, func(path string, attr string, data []byte, flags int) (err error) {
 		return xattrErr
 	}).Restore()
 	tests.Assert(t, ValidateXattrSupport("/tmp/b1", "localhost", uuid.NewRandom(), true) == baderror)
 
 	// Now check what happens when getxattr fails
-	defer heketitests.Patch(&Getxattr, func(path string, attr string, dest []byte) (sz int, err error) {
+	defer heketitests.Patch(&xattr.Getxattr, func(path string, attr string, dest []byte) (sz int, err error) {
 		return 0, xattrErr
 	}).Restore()
 	tests.Assert(t, ValidateXattrSupport("/tmp/b1", "localhost", uuid.NewRandom(), true) == baderror)
 
 	// Now check what happens when removexattr fails
-	defer heketitests.Patch(&Removexattr, func(path string, attr string) (err error) {
+	defer heketitests.Patch(&xattr.Removexattr, func(path string, attr string) (err error) {
 		return xattrErr
 	}).Restore()
 	tests.Assert(t, ValidateXattrSupport("/tmp/b1", "localhost", uuid.NewRandom(), true) == baderror)
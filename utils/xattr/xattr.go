@@ -0,0 +1,154 @@
+// Package xattr provides helpers to get, set and list the extended attributes
+// of files. Extended attributes are supported only on Linux; on other
+// platforms all the calls fail with an error for which IsNotSupported is true.
+package xattr
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/pborman/uuid"
+)
+
+// IsNotExist returns true if the error is returned for an extended attribute
+// which is not set
+func IsNotExist(err error) bool {
+	return err == errNoAttr
+}
+
+// IsNotSupported returns true if the error is returned for a filesystem which
+// does not support extended attributes
+func IsNotSupported(err error) bool {
+	for _, e := range errNotSupported {
+		if err == e {
+			return true
+		}
+	}
+	return false
+}
+
+// Get returns the value of the extended attribute of the path
+func Get(path, name string) ([]byte, error) {
+	for {
+		size, err := Getxattr(path, name, nil)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return []byte{}, nil
+		}
+
+		buf := make([]byte, size)
+		size, err = Getxattr(path, name, buf)
+		if err == errRange {
+			// The value grew after its size was read
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return buf[:size], nil
+	}
+}
+
+// Set sets the value of the extended attribute of the path
+func Set(path, name string, value []byte) error {
+	return Setxattr(path, name, value, 0)
+}
+
+// Remove removes the extended attribute of the path. It is not an error if the
+// attribute is not set.
+func Remove(path, name string) error {
+	if err := Removexattr(path, name); err != nil && !IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Exists returns true if the extended attribute is set on the path, even if it
+// has an empty value. An error is returned if the filesystem of the path does
+// not support extended attributes, which can be checked with IsNotSupported.
+func Exists(path, name string) (bool, error) {
+	_, err := Getxattr(path, name, nil)
+	switch {
+	case err == nil:
+		return true, nil
+	case IsNotExist(err):
+		return false, nil
+	}
+	return false, err
+}
+
+// List returns the names of the extended attributes of the path
+func List(path string) ([]string, error) {
+	var buf []byte
+	for {
+		size, err := Listxattr(path, nil)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return nil, nil
+		}
+
+		buf = make([]byte, size)
+		size, err = Listxattr(path, buf)
+		if err == errRange {
+			// Attributes were added after the size of the list was read
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		buf = buf[:size]
+		break
+	}
+
+	var names []string
+	for _, name := range bytes.Split(buf, []byte{0}) {
+		if len(name) > 0 {
+			names = append(names, string(name))
+		}
+	}
+	return names, nil
+}
+
+// GetAll returns the values of all the extended attributes of the path, keyed
+// by their names. Attributes removed while they are being read are skipped.
+func GetAll(path string) (map[string][]byte, error) {
+	names, err := List(path)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := make(map[string][]byte)
+	for _, name := range names {
+		value, err := Get(path, name)
+		if IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		attrs[name] = value
+	}
+	return attrs, nil
+}
+
+// GetUUID returns the value of the extended attribute of the path as a UUID.
+// The value must be the 16 raw bytes of the UUID.
+func GetUUID(path, name string) (uuid.UUID, error) {
+	value, err := Get(path, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(value) != 16 {
+		return nil, fmt.Errorf("value of xattr %s of %s is not a UUID", name, path)
+	}
+	return uuid.UUID(value), nil
+}
+
+// SetUUID sets the value of the extended attribute of the path to the raw
+// bytes of the UUID
+func SetUUID(path, name string, id uuid.UUID) error {
+	return Set(path, name, []byte(id))
+}
@@ -0,0 +1,26 @@
+package xattr
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+var (
+	// Getxattr calls unix.Getxattr
+	Getxattr = unix.Getxattr
+	// Setxattr calls unix.Setxattr
+	Setxattr = unix.Setxattr
+	// Removexattr calls unix.Removexattr
+	Removexattr = unix.Removexattr
+	// Listxattr calls unix.Listxattr
+	Listxattr = unix.Listxattr
+)
+
+var (
+	// errNoAttr is returned for attributes which are not set. ENOATTR is the
+	// same as ENODATA on Linux.
+	errNoAttr error = unix.ENODATA
+	// errRange is returned when the buffer is too small for the value
+	errRange error = unix.ERANGE
+
+	errNotSupported = []error{unix.ENOTSUP, unix.EOPNOTSUPP}
+)
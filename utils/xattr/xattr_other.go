@@ -0,0 +1,37 @@
+// +build !linux
+
+package xattr
+
+import (
+	"errors"
+)
+
+// ErrUnsupportedPlatform is returned by all the calls on platforms other than
+// Linux
+var ErrUnsupportedPlatform = errors.New("extended attributes are not supported on this platform")
+
+var (
+	// Getxattr always fails with ErrUnsupportedPlatform
+	Getxattr = func(path string, attr string, dest []byte) (int, error) {
+		return 0, ErrUnsupportedPlatform
+	}
+	// Setxattr always fails with ErrUnsupportedPlatform
+	Setxattr = func(path string, attr string, data []byte, flags int) error {
+		return ErrUnsupportedPlatform
+	}
+	// Removexattr always fails with ErrUnsupportedPlatform
+	Removexattr = func(path string, attr string) error {
+		return ErrUnsupportedPlatform
+	}
+	// Listxattr always fails with ErrUnsupportedPlatform
+	Listxattr = func(path string, dest []byte) (int, error) {
+		return 0, ErrUnsupportedPlatform
+	}
+)
+
+var (
+	errNoAttr = errors.New("attribute not set")
+	errRange  = errors.New("result too large")
+
+	errNotSupported = []error{ErrUnsupportedPlatform}
+)
@@ -0,0 +1,45 @@
+package xattr
+
+import (
+	"testing"
+
+	"github.com/gluster/glusterd2/tests"
+
+	heketitests "github.com/heketi/tests"
+	"golang.org/x/sys/unix"
+)
+
+func TestList(t *testing.T) {
+	list := []byte("trusted.gfid\x00trusted.glusterfs.volume-id\x00")
+	defer heketitests.Patch(&Listxattr, func(path string, dest []byte) (int, error) {
+		if dest == nil {
+			return len(list), nil
+		}
+		return copy(dest, list), nil
+	}).Restore()
+
+	names, err := List("/tmp/b1")
+	tests.Assert(t, err == nil)
+	tests.Assert(t, len(names) == 2)
+	tests.Assert(t, names[0] == "trusted.gfid")
+	tests.Assert(t, names[1] == "trusted.glusterfs.volume-id")
+}
+
+func TestExists(t *testing.T) {
+	var xattrErr error
+	defer heketitests.Patch(&Getxattr, func(path string, attr string, dest []byte) (int, error) {
+		return 0, xattrErr
+	}).Restore()
+
+	// An attribute with an empty value is set
+	exists, err := Exists("/tmp/b1", "trusted.gfid")
+	tests.Assert(t, err == nil && exists)
+
+	xattrErr = unix.ENODATA
+	exists, err = Exists("/tmp/b1", "trusted.gfid")
+	tests.Assert(t, err == nil && !exists)
+
+	xattrErr = unix.ENOTSUP
+	exists, err = Exists("/tmp/b1", "trusted.gfid")
+	tests.Assert(t, IsNotSupported(err) && !exists)
+}
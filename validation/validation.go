@@ -0,0 +1,249 @@
+// Package validation implements validation of requests received by the
+// GlusterD REST API, collecting field-level errors to be reported back to
+// the client.
+package validation
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	// MaxNameLength is the maximum length of volume names
+	MaxNameLength = 64
+	// MaxPeerNameLength is the maximum length of peer names, which is the
+	// maximum length of hostnames
+	MaxPeerNameLength = 253
+	// maxPeerNameLabelLength is the maximum length of each dot separated
+	// label of peer names
+	maxPeerNameLabelLength = 63
+)
+
+var (
+	validName     = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+	validPeerName = regexp.MustCompile(`^[a-zA-Z0-9.-]+$`)
+)
+
+var (
+	// ReservedVolumeNames can't be used as volume names. "all" refers to all
+	// the volumes in commands, and "options" and "import" would be shadowed
+	// by the /volumes/options and /volumes/import REST routes.
+	ReservedVolumeNames = []string{"all", "options", "import"}
+	// ReservedPeerNames can't be used as peer names
+	ReservedPeerNames = []string{"all", "localhost"}
+)
+
+// SameName returns true if the names are the same, ignoring case. Names of
+// volumes and peers must be unique ignoring case.
+func SameName(a, b string) bool {
+	return strings.EqualFold(a, b)
+}
+
+// IsReservedName returns true if the name is one of the reserved names,
+// ignoring case
+func IsReservedName(name string, reserved []string) bool {
+	for _, r := range reserved {
+		if SameName(name, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckVolumeName returns the errors of a volume name, for clients to check
+// names before sending requests
+func CheckVolumeName(name string) error {
+	var e Errors
+	e.VolumeName("name", name)
+	return e.Err()
+}
+
+// CheckPeerName returns the errors of a peer name, for clients to check names
+// before sending requests
+func CheckPeerName(name string) error {
+	var e Errors
+	e.PeerName("name", name)
+	return e.Err()
+}
+
+// FieldError describes why a single field of a request is invalid
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Errors is a list of field-level errors found in a request
+type Errors []FieldError
+
+// Error implements the error interface
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, f := range e {
+		msgs[i] = f.Field + ": " + f.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Err returns the errors as an error, or nil if no errors have been found
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Add records an error for the given field
+func (e *Errors) Add(field string, format string, args ...interface{}) {
+	*e = append(*e, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// RequireString checks that the field is not empty
+func (e *Errors) RequireString(field string, value string) bool {
+	if value == "" {
+		e.Add(field, "is required")
+		return false
+	}
+	return true
+}
+
+// RequireList checks that the field has at least one element
+func (e *Errors) RequireList(field string, length int) bool {
+	if length == 0 {
+		e.Add(field, "must have at least one entry")
+		return false
+	}
+	return true
+}
+
+// Name checks that the field is a valid name for a volume
+func (e *Errors) Name(field string, name string) {
+	if !e.RequireString(field, name) {
+		return
+	}
+	if len(name) > MaxNameLength {
+		e.Add(field, "must not be longer than %d characters", MaxNameLength)
+	}
+	if !validName.MatchString(name) {
+		e.Add(field, "must contain only letters, digits, '-' and '_'")
+	}
+}
+
+// VolumeName checks that the field is a valid name for a volume, which must
+// also start with a letter or a digit and must not be a reserved name
+func (e *Errors) VolumeName(field string, name string) {
+	n := len(*e)
+	e.Name(field, name)
+	if len(*e) != n {
+		return
+	}
+	if name[0] == '-' || name[0] == '_' {
+		e.Add(field, "must start with a letter or a digit")
+	}
+	if IsReservedName(name, ReservedVolumeNames) {
+		e.Add(field, "%q is a reserved name", name)
+	}
+}
+
+// PeerName checks that the field is a valid name for a peer. Peer names are
+// hostnames, made of dot separated labels of letters, digits and '-', which
+// don't start or end with '-'.
+func (e *Errors) PeerName(field string, name string) {
+	if !e.RequireString(field, name) {
+		return
+	}
+	if len(name) > MaxPeerNameLength {
+		e.Add(field, "must not be longer than %d characters", MaxPeerNameLength)
+		return
+	}
+	if !validPeerName.MatchString(name) {
+		e.Add(field, "must contain only letters, digits, '-' and '.'")
+		return
+	}
+	for _, label := range strings.Split(name, ".") {
+		if label == "" || len(label) > maxPeerNameLabelLength ||
+			label[0] == '-' || label[len(label)-1] == '-' {
+			e.Add(field, "%q is not a valid hostname", name)
+			return
+		}
+	}
+	if IsReservedName(name, ReservedPeerNames) {
+		e.Add(field, "%q is a reserved name", name)
+	}
+}
+
+// Unique checks that the name in the field is not the same as any of the
+// existing names, ignoring case
+func (e *Errors) Unique(field string, name string, existing []string) {
+	for _, x := range existing {
+		if SameName(name, x) {
+			e.Add(field, "%q conflicts with existing name %q", name, x)
+			return
+		}
+	}
+}
+
+// Bricks checks that every brick in the field is in the <host>:<path>
+// format with an absolute path, and that no brick is listed twice
+func (e *Errors) Bricks(field string, bricks []string) {
+	if !e.RequireList(field, len(bricks)) {
+		return
+	}
+
+	seen := make(map[string]bool)
+	for i, b := range bricks {
+		f := fmt.Sprintf("%s[%d]", field, i)
+		j := strings.LastIndex(b, ":")
+		if j <= 0 || j == len(b)-1 {
+			e.Add(f, "%q is not in <host>:<path> format", b)
+			continue
+		}
+		host, path := b[:j], filepath.Clean(b[j+1:])
+		if !filepath.IsAbs(path) {
+			e.Add(f, "brick path %q is not absolute", path)
+			continue
+		}
+		if seen[host+":"+path] {
+			e.Add(f, "brick %q is listed more than once", b)
+		}
+		seen[host+":"+path] = true
+	}
+}
+
+// ReplicaCount checks that the replica count in the field is valid for the
+// given number of bricks. A replica count of 0 is treated as 1.
+func (e *Errors) ReplicaCount(field string, replica int, brickCount int) {
+	if replica < 0 {
+		e.Add(field, "must not be negative")
+		return
+	}
+	if replica > 0 && brickCount%replica != 0 {
+		e.Add(field, "number of bricks (%d) is not a multiple of the replica count (%d)", brickCount, replica)
+	}
+}
+
+// OneOf checks that the field has one of the allowed values
+func (e *Errors) OneOf(field string, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	e.Add(field, "must be one of %s", strings.Join(allowed, ", "))
+}
+
+// Range checks that the value of the field is between min and max, inclusive
+func (e *Errors) Range(field string, value, min, max int) {
+	if value < min || value > max {
+		e.Add(field, "must be between %d and %d", min, max)
+	}
+}
+
+// Check records the error returned by a validator for the given field, if
+// any
+func (e *Errors) Check(field string, err error) {
+	if err != nil {
+		e.Add(field, "%s", err.Error())
+	}
+}
@@ -0,0 +1,95 @@
+package validation
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gluster/glusterd2/tests"
+)
+
+func TestName(t *testing.T) {
+	var errs Errors
+	errs.Name("name", "gv0_test-1")
+	tests.Assert(t, errs.Err() == nil)
+
+	for _, name := range []string{"", "gv 0", "gv/0", string(make([]byte, MaxNameLength+1))} {
+		errs = nil
+		errs.Name("name", name)
+		tests.Assert(t, errs.Err() != nil)
+		tests.Assert(t, errs[0].Field == "name")
+	}
+}
+
+func TestVolumeName(t *testing.T) {
+	tests.Assert(t, CheckVolumeName("gv0") == nil)
+	for _, name := range []string{"", "-gv0", "_gv0", "all", "ALL", "Options", "gv 0"} {
+		tests.Assert(t, CheckVolumeName(name) != nil)
+	}
+}
+
+func TestPeerName(t *testing.T) {
+	for _, name := range []string{"node1", "node-1.example.com", "10.0.0.1"} {
+		tests.Assert(t, CheckPeerName(name) == nil)
+	}
+	for _, name := range []string{"", "-node1", "node1-", "node..com", "node_1", "LocalHost"} {
+		tests.Assert(t, CheckPeerName(name) != nil)
+	}
+}
+
+func TestUnique(t *testing.T) {
+	var errs Errors
+	errs.Unique("name", "gv1", []string{"gv0"})
+	tests.Assert(t, errs.Err() == nil)
+
+	errs.Unique("name", "GV0", []string{"gv0"})
+	tests.Assert(t, len(errs) == 1)
+}
+
+func TestBricks(t *testing.T) {
+	var errs Errors
+	errs.Bricks("bricks", []string{"host1:/bricks/b1", "host2:/bricks/b1"})
+	tests.Assert(t, errs.Err() == nil)
+
+	errs = nil
+	errs.Bricks("bricks", nil)
+	tests.Assert(t, len(errs) == 1)
+
+	errs = nil
+	errs.Bricks("bricks", []string{"/bricks/b1", "host1:bricks/b1", "host1:", "host1:/bricks/b1", "host1:/bricks/b1/"})
+	tests.Assert(t, len(errs) == 4)
+	tests.Assert(t, errs[0].Field == "bricks[0]")
+	tests.Assert(t, errs[3].Field == "bricks[4]")
+}
+
+func TestReplicaCount(t *testing.T) {
+	var errs Errors
+	errs.ReplicaCount("replica", 0, 3)
+	errs.ReplicaCount("replica", 3, 6)
+	tests.Assert(t, errs.Err() == nil)
+
+	errs.ReplicaCount("replica", -1, 3)
+	errs.ReplicaCount("replica", 2, 3)
+	tests.Assert(t, len(errs) == 2)
+}
+
+func TestRange(t *testing.T) {
+	var errs Errors
+	errs.Range("warning", 0, 0, 100)
+	errs.Range("warning", 100, 0, 100)
+	tests.Assert(t, errs.Err() == nil)
+
+	errs.Range("warning", -1, 0, 100)
+	errs.Range("critical", 101, 0, 100)
+	tests.Assert(t, len(errs) == 2)
+	tests.Assert(t, errs[1].Field == "critical")
+}
+
+func TestCheck(t *testing.T) {
+	var errs Errors
+	errs.Check("ratio", nil)
+	tests.Assert(t, errs.Err() == nil)
+
+	errs.Check("ratio", fmt.Errorf("invalid ratio"))
+	tests.Assert(t, len(errs) == 1)
+	tests.Assert(t, errs[0].Field == "ratio" && errs[0].Message == "invalid ratio")
+}
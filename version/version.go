@@ -7,12 +7,20 @@ import (
 	flag "github.com/spf13/pflag"
 )
 
-// MaxOpVersion and APIVersion supported
+// MinOpVersion, MaxOpVersion and APIVersion supported
 const (
-	MaxOpVersion = 40000
+	MinOpVersion = 40000
+	MaxOpVersion = OpVersion41
 	APIVersion   = 1
 )
 
+// Op-versions which introduced features that need all the peers of the
+// cluster to support them
+const (
+	// OpVersion41 introduced the xlator options of glusterfs 4.1
+	OpVersion41 = 40100
+)
+
 // GlusterdVersion and GitSHA
 var (
 	GlusterdVersion = "4.0dev"
@@ -4,6 +4,7 @@ var brickVolfileTemplate = `volume <volume-name>-posix
     type storage/posix
     option volume-id <volume-id>
     option directory <brick-path>
+    option reserve <reserve>
 end-volume
 
 volume <volume-name>-trash
@@ -41,15 +42,9 @@ volume <volume-name>-changelog
     subvolumes <volume-name>-changetimerecorder
 end-volume
 
-volume <volume-name>-bitrot-stub
-    type features/bitrot-stub
-    option export <brick-path>
-    subvolumes <volume-name>-changelog
-end-volume
-
 volume <volume-name>-access-control
     type features/access-control
-    subvolumes <volume-name>-bitrot-stub
+    subvolumes <volume-name>-changelog
 end-volume
 
 volume <volume-name>-locks
@@ -122,14 +117,18 @@ volume <volume-name>-quota
     option volume-uuid <volume-name>
     subvolumes <volume-name>-index
 end-volume
+`
 
+// brickVolfileTopTemplate is the part of the brick graph above the xlators
+// registered by features
+var brickVolfileTopTemplate = `
 volume <volume-name>-io-stats
     type debug/io-stats
     option count-fop-hits off
     option latency-measurement off
     option log-level INFO
     option unique-id <brick-path>
-    subvolumes <volume-name>-quota
+    subvolumes <io-stats-subvol>
 end-volume
 
 volume <brick-path>
@@ -139,8 +138,8 @@ end-volume
 
 volume <volume-name>-server
     type protocol/server
-    option auth.addr.<brick-path>.allow *
-    option auth-path <brick-path>
+    option auth.addr.<brick-path>.allow <auth-allow>
+<auth-options>    option auth-path <brick-path>
     option auth.login.<trusted-username>.password <trusted-password>
     option auth.login.<brick-path>.allow <trusted-username>
     option transport.address-family inet
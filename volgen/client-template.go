@@ -40,13 +40,17 @@ volume <volume-name>-io-threads
     type performance/io-threads
     subvolumes <volume-name>-md-cache
 end-volume
+`
 
+// clientVolfileTopTemplate is the part of the client graph above the xlators
+// registered by features
+var clientVolfileTopTemplate = `
 volume <volume-name>
     type debug/io-stats
     option count-fop-hits off
     option latency-measurement off
     option log-level INFO
-    subvolumes <volume-name>-io-threads
+    subvolumes <io-stats-subvol>
 end-volume
 `
 
@@ -11,16 +11,26 @@ import (
 	"strings"
 
 	"github.com/gluster/glusterd2/brick"
+	"github.com/gluster/glusterd2/diskusage"
+	"github.com/gluster/glusterd2/logging"
 	"github.com/gluster/glusterd2/store"
 	"github.com/gluster/glusterd2/utils"
 	"github.com/gluster/glusterd2/volume"
 
+	log "github.com/Sirupsen/logrus"
+	"github.com/coreos/etcd/clientv3"
 	config "github.com/spf13/viper"
 )
 
+var volgenLog = logging.Subsystem(logging.Volgen)
+
 // TODO: differentiate between various types of client volfiles
 var volfilePrefix = store.GlusterPrefix + "volfiles/"
 
+// volfileCache keeps the client volfiles in memory for the volfile fetches
+// by clients
+var volfileCache = store.NewCache("volfiles", volfilePrefix)
+
 // TODO: This is a quick and dirty reference implementation that should
 // be replaced when real volgen with dependency resolution is ready.
 // This is not complete either - works only for dist, rep and dist-rep
@@ -28,6 +38,23 @@ var volfilePrefix = store.GlusterPrefix + "volfiles/"
 
 // GenerateClientVolfile generates the client volfile and stores it in etcd
 func GenerateClientVolfile(vinfo *volume.Volinfo) error {
+	volfile, err := ClientVolfile(vinfo)
+	if err != nil {
+		return err
+	}
+
+	resp, err := store.Store.Put(context.TODO(), volfilePrefix+vinfo.Name, string(volfile))
+	if err != nil {
+		return err
+	}
+	volfileCache.Put(volfilePrefix+vinfo.Name, volfile, resp.Header.Revision)
+	volgenLog.WithField("volume", vinfo.Name).Debug("generated client volfile")
+
+	return nil
+}
+
+// ClientVolfile returns the client volfile of the volume, without storing it
+func ClientVolfile(vinfo *volume.Volinfo) ([]byte, error) {
 
 	volfile := new(bytes.Buffer)
 
@@ -36,7 +63,7 @@ func GenerateClientVolfile(vinfo *volume.Volinfo) error {
 
 		address, err := utils.FormRemotePeerAddress(b.Hostname)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		remoteHost, _, _ := net.SplitHostPort(address)
 
@@ -106,23 +133,52 @@ func GenerateClientVolfile(vinfo *volume.Volinfo) error {
 	replacer := strings.NewReplacer("<volume-name>", vinfo.Name, "<wb-subvol>", wbSubvol)
 	volfile.WriteString(replacer.Replace(clientVolfileBaseTemplate))
 
-	if _, err := store.Store.Put(context.TODO(), volfilePrefix+vinfo.Name, volfile.String()); err != nil {
-		return err
-	}
+	top := writeXlators(volfile, clientXlators, vinfo, vinfo.Name+"-io-threads")
+	replacer = strings.NewReplacer("<volume-name>", vinfo.Name, "<io-stats-subvol>", top)
+	volfile.WriteString(replacer.Replace(clientVolfileTopTemplate))
 
-	return nil
+	return volfile.Bytes(), nil
 }
 
 // DeleteClientVolfile deletes the client volfile (duh!)
 func DeleteClientVolfile(vol *volume.Volinfo) error {
 
-	if _, err := store.Store.Delete(context.TODO(), volfilePrefix+vol.Name); err != nil {
+	resp, err := store.Store.Delete(context.TODO(), volfilePrefix+vol.Name)
+	if err != nil {
 		return err
 	}
+	volfileCache.Delete(volfilePrefix+vol.Name, resp.Header.Revision)
 
 	return nil
 }
 
+// ClientVolfiles returns the names of the volumes with a client volfile in the
+// store
+func ClientVolfiles() ([]string, error) {
+	resp, err := store.Store.Get(context.TODO(), volfilePrefix, clientv3.WithPrefix(), clientv3.WithKeysOnly())
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		names = append(names, strings.TrimPrefix(string(kv.Key), volfilePrefix))
+	}
+	return names, nil
+}
+
+// InvalidateVolfileCache drops the client volfile cache of this peer, which is
+// loaded from the store again on next use
+func InvalidateVolfileCache() {
+	volfileCache.Invalidate()
+}
+
+// GetClientVolfile returns the client volfile of the given volume, and if it
+// was found. The volfile is served from the volfile cache.
+func GetClientVolfile(volname string) ([]byte, bool, error) {
+	return volfileCache.Get(volfilePrefix + volname)
+}
+
 func getBrickVolFilePath(volumeName string, brickNodeID string, brickPath string) string {
 	volumeDir := utils.GetVolumeDir(volumeName)
 	brickPathWithoutSlashes := strings.Trim(strings.Replace(brickPath, "/", "-", -1), "-")
@@ -130,9 +186,19 @@ func getBrickVolFilePath(volumeName string, brickNodeID string, brickPath string
 	return path.Join(volumeDir, volFileName)
 }
 
+// BrickVolfilePath returns the path of the brick volfile of the brick
+func BrickVolfilePath(binfo *brick.Brickinfo) string {
+	return getBrickVolFilePath(binfo.VolumeName, binfo.NodeID.String(), binfo.Path)
+}
+
 // GenerateBrickVolfile generates the brick volfile for a single brick
 func GenerateBrickVolfile(vinfo *volume.Volinfo, binfo *brick.Brickinfo) error {
 
+	volfile, err := BrickVolfile(vinfo, binfo)
+	if err != nil {
+		return err
+	}
+
 	bpath := getBrickVolFilePath(vinfo.Name, binfo.NodeID.String(), binfo.Path)
 	f, err := os.Create(bpath)
 	if err != nil {
@@ -140,20 +206,66 @@ func GenerateBrickVolfile(vinfo *volume.Volinfo, binfo *brick.Brickinfo) error {
 	}
 	defer f.Close()
 
+	if _, err = f.Write(volfile); err != nil {
+		return err
+	}
+	f.Sync()
+	volgenLog.WithFields(log.Fields{
+		"volume": vinfo.Name,
+		"brick":  binfo.Path,
+		"file":   bpath,
+	}).Debug("generated brick volfile")
+
+	return nil
+}
+
+// BrickVolfile returns the brick volfile of a single brick, without writing it
+func BrickVolfile(vinfo *volume.Volinfo, binfo *brick.Brickinfo) ([]byte, error) {
+
+	usage, err := diskusage.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	volfile := new(bytes.Buffer)
+	volfile.WriteString(brickVolfileTemplate)
+	top := writeXlators(volfile, brickXlators, vinfo, "<volume-name>-quota")
+	volfile.WriteString(strings.Replace(brickVolfileTopTemplate, "<io-stats-subvol>", top, -1))
+
 	replacer := strings.NewReplacer(
 		"<volume-name>", vinfo.Name,
 		"<volume-id>", vinfo.ID.String(),
 		"<brick-path>", binfo.Path,
 		"<trusted-username>", vinfo.Auth.Username,
 		"<trusted-password>", vinfo.Auth.Password,
+		"<auth-allow>", authAllow(&vinfo.Access),
+		"<auth-options>", authOptions(&vinfo.Access, binfo.Path),
+		"<reserve>", strconv.Itoa(usage.Reserve),
 		"<local-state-dir>", config.GetString("localstatedir"))
 
-	if _, err = replacer.WriteString(f, brickVolfileTemplate); err != nil {
-		return err
+	return []byte(replacer.Replace(volfile.String())), nil
+}
+
+// authAllow returns the value of the auth.addr allow option of the bricks of a
+// volume with the given access list
+func authAllow(a *volume.VolAccess) string {
+	if len(a.AllowAddrs) == 0 {
+		return "*"
 	}
-	f.Sync()
+	return strings.Join(a.AllowAddrs, ",")
+}
 
-	return nil
+// authOptions returns the lines of the options for the rest of the access
+// list of a brick, which are only set if the lists are not empty
+func authOptions(a *volume.VolAccess, brickPath string) string {
+	var opts bytes.Buffer
+	if len(a.RejectAddrs) != 0 {
+		fmt.Fprintf(&opts, "    option auth.addr.%s.reject %s\n", brickPath, strings.Join(a.RejectAddrs, ","))
+	}
+	if len(a.AllowUsers) != 0 {
+		fmt.Fprintf(&opts, "    option auth.ssl-allow %s\n", strings.Join(a.AllowUsers, ","))
+	}
+	return opts.String()
 }
 
 // DeleteBrickVolfile deletes the brick volfile of a single brick
@@ -0,0 +1,70 @@
+package volgen
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/gluster/glusterd2/volume"
+)
+
+// Xlator is a fragment of the volfile graph contributed by a feature, for
+// example by a plugin. Xlators are stacked linearly, in the order in which
+// they were registered, at a fixed point of the brick or client graph.
+type Xlator struct {
+	// Name is the suffix of the name of the xlator in the graph. The full
+	// name is <volume-name>-<Name>.
+	Name string
+	// Type is the type of the xlator, eg. features/bit-rot-stub
+	Type string
+	// Enabled returns true if the xlator should be part of the graph of
+	// the volume. A nil Enabled always adds the xlator.
+	Enabled func(v *volume.Volinfo) bool
+	// Options returns the options to be set for the xlator for the volume
+	Options func(v *volume.Volinfo) map[string]string
+}
+
+var (
+	brickXlators  []Xlator
+	clientXlators []Xlator
+)
+
+// RegisterBrickXlators adds xlators to the brick graph, below io-stats. This
+// must be called before any volfiles are generated.
+func RegisterBrickXlators(xlators ...Xlator) {
+	brickXlators = append(brickXlators, xlators...)
+}
+
+// RegisterClientXlators adds xlators to the client graph, below the top
+// io-stats xlator. This must be called before any volfiles are generated.
+func RegisterClientXlators(xlators ...Xlator) {
+	clientXlators = append(clientXlators, xlators...)
+}
+
+// writeXlators writes the xlators enabled for the volume as a linear graph
+// on top of subvol, and returns the name of the topmost xlator
+func writeXlators(w *bytes.Buffer, xlators []Xlator, v *volume.Volinfo, subvol string) string {
+	for _, x := range xlators {
+		if x.Enabled != nil && !x.Enabled(v) {
+			continue
+		}
+		name := v.Name + "-" + x.Name
+
+		fmt.Fprintf(w, "\nvolume %s\n    type %s\n", name, x.Type)
+		if x.Options != nil {
+			opts := x.Options(v)
+			var keys []string
+			for k := range opts {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				fmt.Fprintf(w, "    option %s %s\n", k, opts[k])
+			}
+		}
+		fmt.Fprintf(w, "    subvolumes %s\nend-volume\n", subvol)
+
+		subvol = name
+	}
+	return subvol
+}
@@ -0,0 +1,91 @@
+package volume
+
+import (
+	"sync"
+
+	"github.com/gluster/glusterd2/errors"
+)
+
+// DependentsFunc returns the names of the volumes which depend on the given
+// volume, like the secondary volumes of the geo-replication sessions it is the
+// primary of. Dependent volumes are stopped before the volumes they depend on,
+// and started after them.
+//
+// Snapshots are not volumes, so they are neither started nor stopped, and the
+// volumes cloned from them are on bricks of their own, independent of the
+// volume the snapshot was taken of. Neither are dependents of the volume, and
+// nothing registers dependents until geo-replication is supported.
+type DependentsFunc func(v *Volinfo) []string
+
+var (
+	dependentsFuncs []DependentsFunc
+	dependentsLock  sync.RWMutex
+)
+
+// RegisterDependents registers a function returning the volumes depending on
+// a volume
+func RegisterDependents(fn DependentsFunc) {
+	dependentsLock.Lock()
+	defer dependentsLock.Unlock()
+
+	dependentsFuncs = append(dependentsFuncs, fn)
+}
+
+// Dependents returns the names of the volumes depending on the given volume
+func Dependents(v *Volinfo) []string {
+	dependentsLock.RLock()
+	defer dependentsLock.RUnlock()
+
+	var names []string
+	for _, fn := range dependentsFuncs {
+		names = append(names, fn(v)...)
+	}
+	return names
+}
+
+// StopOrder returns the volumes in the order they should be stopped in, with
+// the volumes depending on others before them. The volumes should be started
+// in the reverse order. Volumes which don't depend on each other keep their
+// order.
+func StopOrder(vols []*Volinfo) ([]*Volinfo, error) {
+	byName := make(map[string]*Volinfo, len(vols))
+	for _, v := range vols {
+		byName[v.Name] = v
+	}
+
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := make(map[string]int, len(vols))
+	ordered := make([]*Volinfo, 0, len(vols))
+
+	var visit func(v *Volinfo) error
+	visit = func(v *Volinfo) error {
+		switch state[v.Name] {
+		case visiting:
+			return errors.ErrVolDependencyCycle
+		case visited:
+			return nil
+		}
+		state[v.Name] = visiting
+		for _, name := range Dependents(v) {
+			// Only the order of the given volumes matters
+			if dep, ok := byName[name]; ok {
+				if err := visit(dep); err != nil {
+					return err
+				}
+			}
+		}
+		state[v.Name] = visited
+		ordered = append(ordered, v)
+		return nil
+	}
+
+	for _, v := range vols {
+		if err := visit(v); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
@@ -0,0 +1,143 @@
+package volume
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/gluster/glusterd2/errors"
+	"github.com/gluster/glusterd2/store"
+
+	"github.com/coreos/etcd/clientv3"
+)
+
+const profilePrefix string = store.GlusterPrefix + "profiles/"
+
+// OptionProfile is a named group of volume options which are set together,
+// tuning a volume for a use case
+type OptionProfile struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Options     map[string]string `json:"options"`
+	// Builtin is set for the profiles shipped with GlusterD, which cannot be
+	// changed or deleted
+	Builtin bool `json:"builtin"`
+}
+
+// builtinProfiles are the profiles shipped with GlusterD, matching the option
+// groups of glusterd1
+var builtinProfiles = map[string]*OptionProfile{
+	"virt": {
+		Name:        "virt",
+		Description: "Volumes storing virtual machine images",
+		Options: map[string]string{
+			"cluster/afr.eager-lock":                  "on",
+			"cluster/afr.quorum-type":                 "auto",
+			"cluster/afr.data-self-heal-algorithm":    "full",
+			"cluster/afr.locking-scheme":              "granular",
+			"cluster/afr.shd-max-threads":             "8",
+			"cluster/afr.shd-wait-qlength":            "10000",
+			"performance/io-threads.low-prio-threads": "32",
+			"protocol/client.filter-O_DIRECT":         "enable",
+		},
+		Builtin: true,
+	},
+	"gluster-block": {
+		Name:        "gluster-block",
+		Description: "Volumes hosting gluster-block block devices",
+		Options: map[string]string{
+			"cluster/afr.eager-lock":                  "off",
+			"cluster/afr.quorum-type":                 "auto",
+			"cluster/afr.data-self-heal-algorithm":    "full",
+			"cluster/afr.locking-scheme":              "granular",
+			"cluster/afr.shd-max-threads":             "8",
+			"cluster/afr.shd-wait-qlength":            "10000",
+			"features/shard.shard-block-size":         "64MB",
+			"protocol/client.filter-O_DIRECT":         "disable",
+			"protocol/server.rpc-auth-allow-insecure": "on",
+		},
+		Builtin: true,
+	},
+}
+
+// GetProfile returns the builtin or custom profile with the given name
+func GetProfile(name string) (*OptionProfile, error) {
+	if p, ok := builtinProfiles[name]; ok {
+		return p, nil
+	}
+
+	resp, err := store.Store.Get(context.TODO(), profilePrefix+name)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Count != 1 {
+		return nil, errors.ErrProfileNotFound
+	}
+
+	var p OptionProfile
+	if err := json.Unmarshal(resp.Kvs[0].Value, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// GetProfiles returns the builtin and custom profiles, sorted by name
+func GetProfiles() ([]*OptionProfile, error) {
+	resp, err := store.Store.Get(context.TODO(), profilePrefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make([]*OptionProfile, 0, len(builtinProfiles)+len(resp.Kvs))
+	for _, p := range builtinProfiles {
+		profiles = append(profiles, p)
+	}
+	for _, kv := range resp.Kvs {
+		var p OptionProfile
+		if err := json.Unmarshal(kv.Value, &p); err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, &p)
+	}
+
+	sort.Sort(profilesByName(profiles))
+	return profiles, nil
+}
+
+// AddOrUpdateProfile saves a custom profile in the store. Builtin profiles
+// cannot be replaced.
+func AddOrUpdateProfile(p *OptionProfile) error {
+	if _, ok := builtinProfiles[p.Name]; ok {
+		return errors.ErrProfileBuiltin
+	}
+
+	p.Builtin = false
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	_, err = store.Store.Put(context.TODO(), profilePrefix+p.Name, string(data))
+	return err
+}
+
+// DeleteProfile deletes a custom profile from the store
+func DeleteProfile(name string) error {
+	if _, ok := builtinProfiles[name]; ok {
+		return errors.ErrProfileBuiltin
+	}
+
+	resp, err := store.Store.Delete(context.TODO(), profilePrefix+name)
+	if err != nil {
+		return err
+	}
+	if resp.Deleted == 0 {
+		return errors.ErrProfileNotFound
+	}
+	return nil
+}
+
+type profilesByName []*OptionProfile
+
+func (p profilesByName) Len() int           { return len(p) }
+func (p profilesByName) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+func (p profilesByName) Less(i, j int) bool { return p[i].Name < p[j].Name }
@@ -0,0 +1,39 @@
+package volume
+
+import (
+	"github.com/pborman/uuid"
+)
+
+// ServiceStatus represents the status of a service on a node, other than the
+// bricks, which serves a volume
+type ServiceStatus struct {
+	Name   string    `json:"name"`
+	NodeID uuid.UUID `json:"node-id"`
+	Online bool      `json:"online"`
+	Pid    int       `json:"pid,omitempty"`
+}
+
+// ServiceStatusFunc returns the status of a service serving the volume on
+// this node. It returns false if the service doesn't serve the volume.
+type ServiceStatusFunc func(v *Volinfo) (ServiceStatus, bool)
+
+var serviceStatusFuncs []ServiceStatusFunc
+
+// RegisterServiceStatusFunc registers a function reporting the status of a
+// service in volume status. This must be called during initialization, for
+// example from an init function.
+func RegisterServiceStatusFunc(f ServiceStatusFunc) {
+	serviceStatusFuncs = append(serviceStatusFuncs, f)
+}
+
+// ServiceStatuses returns the status of the services on this node which
+// serve the volume
+func ServiceStatuses(v *Volinfo) []ServiceStatus {
+	var statuses []ServiceStatus
+	for _, f := range serviceStatusFuncs {
+		if s, ok := f(v); ok {
+			statuses = append(statuses, s)
+		}
+	}
+	return statuses
+}
@@ -2,10 +2,11 @@ package volume
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 
 	"github.com/gluster/glusterd2/store"
+	"github.com/gluster/glusterd2/store/schema"
+	"github.com/gluster/glusterd2/validation"
 	"github.com/pborman/uuid"
 
 	log "github.com/Sirupsen/logrus"
@@ -14,6 +15,9 @@ import (
 
 const (
 	volumePrefix string = store.GlusterPrefix + "volumes/"
+
+	// SchemaKind is the kind of volinfo objects for schema versioning
+	SchemaKind = "volinfo"
 )
 
 var (
@@ -21,21 +25,26 @@ var (
 	ExistsFunc = Exists
 	// AddOrUpdateVolumeFunc marshals to volume object and passes to store to add/update
 	AddOrUpdateVolumeFunc = AddOrUpdateVolume
+
+	// cache keeps the volinfo objects in memory for the read-only REST
+	// endpoints
+	cache = store.NewCache("volumes", volumePrefix)
 )
 
 // AddOrUpdateVolume marshals to volume object and passes to store to add/update
 func AddOrUpdateVolume(v *Volinfo) error {
-	json, e := json.Marshal(v)
+	data, e := schema.Marshal(SchemaKind, v)
 	if e != nil {
 		log.WithField("error", e).Error("Failed to marshal the volinfo object")
 		return e
 	}
 
-	_, e = store.Store.Put(context.TODO(), volumePrefix+v.Name, string(json))
+	resp, e := store.Store.Put(context.TODO(), volumePrefix+v.Name, string(data))
 	if e != nil {
 		log.WithError(e).Error("Couldn't add volume to store")
 		return e
 	}
+	cache.Put(volumePrefix+v.Name, data, resp.Header.Revision)
 	return nil
 }
 
@@ -54,7 +63,7 @@ func GetVolume(name string) (*Volinfo, error) {
 		return nil, errors.New("volume not found")
 	}
 
-	if e = json.Unmarshal(resp.Kvs[0].Value, &v); e != nil {
+	if e = schema.Unmarshal(SchemaKind, resp.Kvs[0].Value, &v); e != nil {
 		log.WithError(e).Error("Failed to unmarshal the data into volinfo object")
 		return nil, e
 	}
@@ -63,8 +72,12 @@ func GetVolume(name string) (*Volinfo, error) {
 
 //DeleteVolume passes the volname to store to delete the volume object
 func DeleteVolume(name string) error {
-	_, e := store.Store.Delete(context.TODO(), volumePrefix+name)
-	return e
+	resp, e := store.Store.Delete(context.TODO(), volumePrefix+name)
+	if e != nil {
+		return e
+	}
+	cache.Delete(volumePrefix+name, resp.Header.Revision)
+	return nil
 }
 
 // GetVolumesList returns a map of volume names to their UUIDs
@@ -79,7 +92,7 @@ func GetVolumesList() (map[string]uuid.UUID, error) {
 	for _, kv := range resp.Kvs {
 		var vol Volinfo
 
-		if err := json.Unmarshal(kv.Value, &vol); err != nil {
+		if err := schema.Unmarshal(SchemaKind, kv.Value, &vol); err != nil {
 			log.WithFields(log.Fields{
 				"volume": string(kv.Key),
 				"error":  err,
@@ -106,7 +119,7 @@ func GetVolumes() ([]Volinfo, error) {
 	for i, kv := range resp.Kvs {
 		var vol Volinfo
 
-		if err := json.Unmarshal(kv.Value, &vol); err != nil {
+		if err := schema.Unmarshal(SchemaKind, kv.Value, &vol); err != nil {
 			log.WithFields(log.Fields{
 				"volume": string(kv.Key),
 				"error":  err,
@@ -129,3 +142,66 @@ func Exists(name string) bool {
 
 	return resp.Count == 1
 }
+
+// ExistingName returns the name of the existing volume whose name is the same
+// as the given name ignoring case, if there is one. Volume names must be unique
+// ignoring case.
+func ExistingName(name string) (string, bool) {
+	vols, e := GetVolumesList()
+	if e != nil {
+		return "", false
+	}
+	for v := range vols {
+		if validation.SameName(v, name) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// GetVolumeCached returns the volinfo of the given volume from the volume
+// cache. It may briefly lag behind changes made on other peers, and must only
+// be used where a stale volinfo is acceptable.
+func GetVolumeCached(name string) (*Volinfo, error) {
+	data, ok, e := cache.Get(volumePrefix + name)
+	if e != nil {
+		return nil, e
+	}
+	if !ok {
+		return nil, errors.New("volume not found")
+	}
+
+	var v Volinfo
+	if e = schema.Unmarshal(SchemaKind, data, &v); e != nil {
+		log.WithError(e).Error("Failed to unmarshal the data into volinfo object")
+		return nil, e
+	}
+	return &v, nil
+}
+
+// InvalidateCache drops the volume cache of this peer, which is loaded from
+// the store again on next use
+func InvalidateCache() {
+	cache.Invalidate()
+}
+
+// GetVolumesCached returns the volinfo of all volumes from the volume cache.
+// Like GetVolumeCached, it must only be used where stale data is acceptable.
+func GetVolumesCached() ([]Volinfo, error) {
+	values, e := cache.List()
+	if e != nil {
+		return nil, e
+	}
+
+	volumes := make([]Volinfo, 0, len(values))
+	for _, data := range values {
+		var vol Volinfo
+		if err := schema.Unmarshal(SchemaKind, data, &vol); err != nil {
+			log.WithError(err).Error("Failed to unmarshal volume")
+			continue
+		}
+		volumes = append(volumes, vol)
+	}
+
+	return volumes, nil
+}
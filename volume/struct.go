@@ -54,6 +54,34 @@ const (
 	DistDisperse
 )
 
+var volStateNames = []string{"created", "started", "stopped"}
+
+var volTypeNames = []string{"distribute", "replicate", "disperse", "distribute-replicate", "distribute-disperse"}
+
+func (s VolState) String() string {
+	if int(s) < len(volStateNames) {
+		return volStateNames[s]
+	}
+	return "unknown"
+}
+
+func (t VolType) String() string {
+	if int(t) < len(volTypeNames) {
+		return volTypeNames[t]
+	}
+	return "unknown"
+}
+
+// VolStateNames returns the names of all volume states
+func VolStateNames() []string {
+	return append([]string(nil), volStateNames...)
+}
+
+// VolTypeNames returns the names of all volume types
+func VolTypeNames() []string {
+	return append([]string(nil), volTypeNames...)
+}
+
 // Volinfo repesents a volume
 type Volinfo struct {
 	ID           uuid.UUID
@@ -62,12 +90,21 @@ type Volinfo struct {
 	Transport    string
 	DistCount    int
 	ReplicaCount int
-	Options      map[string]string
-	Status       VolState
-	Checksum     uint64
-	Version      uint64
-	Bricks       []brick.Brickinfo
-	Auth         VolAuth // TODO: should not be returned to client
+	// DisperseCount is the number of bricks in each disperse set, of
+	// which RedundancyCount can be lost without losing data. Both are 0
+	// for volumes which aren't erasure coded.
+	DisperseCount   int
+	RedundancyCount int
+	Options         map[string]string
+	Status          VolState
+	Checksum        uint64
+	Version         uint64
+	Bricks          []brick.Brickinfo
+	Auth            VolAuth // TODO: should not be returned to client
+	Access          VolAccess
+	// Capacity is the size in bytes requested for volumes created by the
+	// provisioner. It is 0 for other volumes.
+	Capacity uint64
 }
 
 // VolAuth represents username and password used by trusted/internal clients
@@ -76,13 +113,45 @@ type VolAuth struct {
 	Password string
 }
 
+// VolAccess is the access control list of a volume, enforced by its bricks.
+// Clients are allowed if they match any of the allowed entries, and none of
+// the rejected ones. An empty list of allowed addresses allows all addresses.
+type VolAccess struct {
+	// AllowAddrs and RejectAddrs are addresses, CIDR ranges or hostnames
+	// of clients, which can have * wildcards
+	AllowAddrs  []string
+	RejectAddrs []string
+	// AllowUsers are the names of users allowed when TLS is used, as given
+	// in the common name of their certificates. An empty list allows all
+	// users with a trusted certificate.
+	AllowUsers []string
+}
+
 // VolStatus represents collective status of the bricks that make up the volume
 type VolStatus struct {
 	Brickstatuses []brick.Brickstatus
+	// Services is the status of the other services serving the volume,
+	// such as NFS servers
+	Services []ServiceStatus `json:",omitempty"`
+	// DiskReserve is the percentage of the filesystem of the bricks kept
+	// free by the bricks
+	DiskReserve int
 	// TODO: Add further fields like memory usage, brick filesystem, fd consumed,
 	// clients connected etc.
 }
 
+// BrickCheckResult is the result of validating a single brick without making
+// any changes to it
+type BrickCheckResult struct {
+	Brick  string    `json:"brick"`
+	NodeID uuid.UUID `json:"node-id"`
+	Valid  bool      `json:"valid"`
+	Errors []string  `json:"errors,omitempty"`
+	// Warnings are about settings of the brick filesystem which are not
+	// recommended, and do not fail the validation
+	Warnings []string `json:"warnings,omitempty"`
+}
+
 // NewBrickEntries creates the brick list
 func NewBrickEntries(bricks []string, volName string, volID uuid.UUID) ([]brick.Brickinfo, error) {
 	var brickInfos []brick.Brickinfo
@@ -150,10 +219,21 @@ func ValidateBrickEntries(bricks []brick.Brickinfo, volID uuid.UUID, force bool)
 		if err != nil {
 			return http.StatusBadRequest, err
 		}
-		err = isBrickPathAvailable(b.Hostname, b.Path)
+		err = validateBrickNameLength(b.Path)
 		if err != nil {
 			return http.StatusBadRequest, err
 		}
+		err = isBrickPathAvailable(b)
+		if err == errors.ErrBrickPathAlreadyInUse || err == errors.ErrBrickPathOverlaps {
+			return http.StatusBadRequest, err
+		} else if err != nil {
+			return http.StatusInternalServerError, err
+		}
+		// The filesystems of mocked bricks are not used, and need not
+		// support xattrs
+		if brick.Mocked() {
+			continue
+		}
 		err = validateBrickPathStatsFunc(b.Path, b.Hostname, force)
 		if err != nil {
 			return http.StatusBadRequest, err
@@ -166,6 +246,86 @@ func ValidateBrickEntries(bricks []brick.Brickinfo, volID uuid.UUID, force bool)
 	return 0, nil
 }
 
+// CheckBrickEntries runs the validations done by ValidateBrickEntries on the
+// bricks local to this node, without creating brick directories or setting
+// any xattrs on them. All failed validations are reported for every brick.
+func CheckBrickEntries(bricks []brick.Brickinfo, force bool) []BrickCheckResult {
+	var results []BrickCheckResult
+
+	for _, b := range bricks {
+		if !uuid.Equal(b.NodeID, gdctx.MyUUID) {
+			continue
+		}
+
+		var errs []string
+		if local, err := utils.IsLocalAddress(b.Hostname); err != nil {
+			errs = append(errs, err.Error())
+		} else if !local {
+			errs = append(errs, errors.ErrBrickNotLocal.Error())
+		}
+
+		checks := []func() error{
+			func() error { return utils.ValidateBrickPathLength(b.Path) },
+			func() error { return utils.ValidateBrickSubDirLength(b.Path) },
+			func() error { return validateBrickNameLength(b.Path) },
+			func() error { return isBrickPathAvailable(b) },
+		}
+		if !brick.Mocked() {
+			checks = append(checks,
+				func() error { return utils.CheckBrickPathStats(b.Path, force) },
+				func() error { return utils.CheckXattrSupport(b.Path, force) },
+			)
+		}
+		for _, check := range checks {
+			if err := check(); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+
+		var warnings []string
+		if !brick.Mocked() {
+			fsWarnings, err := utils.CheckBrickFS(b.Path, force)
+			if err != nil {
+				errs = append(errs, err.Error())
+			}
+			warnings = fsWarnings
+			if w, err := utils.CheckBrickSELinuxContext(b.Path); err != nil {
+				errs = append(errs, err.Error())
+			} else if w != "" {
+				warnings = append(warnings, w)
+			}
+		}
+
+		results = append(results, BrickCheckResult{
+			Brick:    b.Hostname + ":" + b.Path,
+			NodeID:   b.NodeID,
+			Valid:    len(errs) == 0,
+			Errors:   errs,
+			Warnings: warnings,
+		})
+	}
+	return results
+}
+
+// BrickFSWarnings returns the warnings about the filesystems and SELinux
+// contexts of the bricks local to this node. Bricks which cannot be checked
+// are skipped, as they are already validated by ValidateBrickEntries.
+func BrickFSWarnings(bricks []brick.Brickinfo, force bool) []string {
+	var warnings []string
+	for _, b := range bricks {
+		if !uuid.Equal(b.NodeID, gdctx.MyUUID) {
+			continue
+		}
+		if w, err := utils.CheckBrickFS(b.Path, force); err == nil {
+			warnings = append(warnings, w...)
+		}
+		if w, err := utils.CheckBrickSELinuxContext(b.Path); err == nil && w != "" {
+			warnings = append(warnings, w)
+		}
+	}
+	return warnings
+}
+
 func (v *Volinfo) String() string {
 	b, err := json.Marshal(v)
 	if err != nil {
@@ -2,12 +2,16 @@ package volume
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/gluster/glusterd2/brick"
+	"github.com/gluster/glusterd2/diskusage"
 	"github.com/gluster/glusterd2/errors"
 	"github.com/gluster/glusterd2/utils"
 
 	log "github.com/Sirupsen/logrus"
+	"github.com/pborman/uuid"
 )
 
 var (
@@ -34,22 +38,123 @@ func RemoveBrickPaths(bricks []brick.Brickinfo) error {
 	return e
 }
 
-// isBrickPathAvailable validates whether the brick is consumed by other
-// volume
-func isBrickPathAvailable(hostname string, brickPath string) error {
-	volumes, e := getVolumesFunc()
-	if e != nil || volumes == nil {
-		// In case cluster doesn't have any volumes configured yet,
-		// treat this as success
-		log.Debug("Failed to retrieve volumes")
+// validateBrickNameLength validates the length of the directories of the
+// brick path which are yet to be created, against the maximum file name
+// length of the filesystem they will be created on
+func validateBrickNameLength(brickPath string) error {
+	existing, _, err := utils.NearestExistingPath(brickPath)
+	if err != nil {
+		return err
+	}
+	st, err := diskusage.Stat(existing)
+	if err != nil {
+		return err
+	}
+
+	rel, err := filepath.Rel(existing, filepath.Clean(brickPath))
+	if err != nil || rel == "." {
+		return err
+	}
+	for _, name := range strings.Split(rel, string(os.PathSeparator)) {
+		if len(name) > st.NameMax {
+			log.WithFields(log.Fields{
+				"name":    name,
+				"namemax": st.NameMax,
+			}).Error(errors.ErrNameTooLong.Error())
+			return errors.ErrNameTooLong
+		}
+	}
+	return nil
+}
+
+// CheckBricksNotInUse returns an error if any of the bricks is part of an
+// existing volume, or if the volumes can't be read
+func CheckBricksNotInUse(bricks []brick.Brickinfo) error {
+	for _, b := range bricks {
+		if err := isBrickPathAvailable(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CleanupBricks removes the brick xattrs and internal directories from the
+// bricks local to this node. Nothing is removed if any of the bricks is part
+// of an existing volume, or if the volumes can't be read.
+func CleanupBricks(bricks []brick.Brickinfo) error {
+	if err := CheckBricksNotInUse(bricks); err != nil {
+		return err
+	}
+	if brick.Mocked() {
 		return nil
 	}
+
+	for _, b := range bricks {
+		local, err := utils.IsLocalAddress(b.Hostname)
+		if err != nil || local == false {
+			continue
+		}
+		if err := utils.CleanupBrickXattrs(b.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetBrick returns the brick with the given path on the given peer, from the
+// volume it belongs to
+func GetBrick(nodeID uuid.UUID, brickPath string) (*brick.Brickinfo, error) {
+	volumes, err := getVolumesFunc()
+	if err != nil {
+		return nil, err
+	}
+
+	brickPath = filepath.Clean(brickPath)
 	for _, v := range volumes {
-		for _, b := range v.Bricks {
-			if b.Hostname == hostname && b.Path == brickPath {
+		for i := range v.Bricks {
+			b := &v.Bricks[i]
+			if uuid.Equal(b.NodeID, nodeID) && filepath.Clean(b.Path) == brickPath {
+				return b, nil
+			}
+		}
+	}
+	return nil, errors.ErrBrickNotFound
+}
+
+// isBrickPathAvailable validates whether the brick is consumed by other
+// volume. Bricks on the same node which are nested inside the brick path, or
+// which the brick path is nested inside, overlap with the brick and are not
+// allowed either, even if they are bricks of the same volume.
+func isBrickPathAvailable(b brick.Brickinfo) error {
+	// Bricks whose volumes can't be read are not known to be unused
+	volumes, err := getVolumesFunc()
+	if err != nil {
+		log.WithError(err).Error("Failed to retrieve volumes")
+		return err
+	}
+
+	brickPath := filepath.Clean(b.Path)
+	for _, v := range volumes {
+		for _, vb := range v.Bricks {
+			sameNode := vb.Hostname == b.Hostname ||
+				(b.NodeID != nil && uuid.Equal(vb.NodeID, b.NodeID))
+			if !sameNode {
+				continue
+			}
+
+			vbPath := filepath.Clean(vb.Path)
+			if vbPath == brickPath {
 				log.Error("Brick is already used by ", v.Name)
 				return errors.ErrBrickPathAlreadyInUse
 			}
+			if utils.IsPathUnder(vbPath, brickPath) || utils.IsPathUnder(brickPath, vbPath) {
+				log.WithFields(log.Fields{
+					"brick":         b.Path,
+					"volume":        v.Name,
+					"existingBrick": vb.Path,
+				}).Error(errors.ErrBrickPathOverlaps.Error())
+				return errors.ErrBrickPathOverlaps
+			}
 		}
 	}
 	return nil
@@ -3,13 +3,17 @@ package volume
 import (
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 
+	"github.com/gluster/glusterd2/brick"
+	"github.com/gluster/glusterd2/diskusage"
 	"github.com/gluster/glusterd2/errors"
 	"github.com/gluster/glusterd2/peer"
 	"github.com/gluster/glusterd2/tests"
 
 	heketitests "github.com/heketi/tests"
+	"github.com/pborman/uuid"
 )
 
 func find(haystack []string, needle string) bool {
@@ -66,3 +70,125 @@ func TestNewBrickEntry(t *testing.T) {
 	tests.Assert(t, err == errors.ErrBrickPathConvertFail)
 
 }
+
+func TestStopOrder(t *testing.T) {
+	deps := map[string][]string{
+		"origin": {"snap1", "snap2"},
+		"snap1":  {"clone"},
+	}
+	defer heketitests.Patch(&dependentsFuncs, []DependentsFunc{
+		func(v *Volinfo) []string { return deps[v.Name] },
+	}).Restore()
+
+	var vols []*Volinfo
+	for _, name := range []string{"origin", "other", "snap1", "clone", "snap2"} {
+		vols = append(vols, &Volinfo{Name: name})
+	}
+
+	ordered, err := StopOrder(vols)
+	tests.Assert(t, err == nil)
+	var names []string
+	for _, v := range ordered {
+		names = append(names, v.Name)
+	}
+	tests.Assert(t, fmt.Sprint(names) == "[clone snap1 snap2 origin other]")
+
+	deps["clone"] = []string{"origin"}
+	_, err = StopOrder(vols)
+	tests.Assert(t, err == errors.ErrVolDependencyCycle)
+}
+
+func TestValidateBrickNameLength(t *testing.T) {
+	st, err := diskusage.Stat("/tmp")
+	tests.Assert(t, err == nil)
+
+	name := strings.Repeat("a", st.NameMax)
+	tests.Assert(t, validateBrickNameLength("/tmp/"+name+"/brick1") == nil)
+	tests.Assert(t, validateBrickNameLength("/tmp/"+name+"a/brick1") == errors.ErrNameTooLong)
+	tests.Assert(t, validateBrickNameLength("/tmp") == nil)
+}
+
+func TestIsBrickPathAvailable(t *testing.T) {
+	defer heketitests.Patch(&getVolumesFunc, func() ([]Volinfo, error) {
+		return []Volinfo{
+			{Name: "vol1", Bricks: []brick.Brickinfo{
+				{Hostname: "host1", Path: "/bricks/vol1/b1", VolumeName: "vol1"},
+				{Hostname: "host2", Path: "/bricks/vol1/b2", VolumeName: "vol1"},
+			}},
+			{Name: "vol2", Bricks: []brick.Brickinfo{
+				{Hostname: "host1", Path: "/bricks/vol2/b1", VolumeName: "vol2"},
+			}},
+		}, nil
+	}).Restore()
+
+	for _, c := range []struct {
+		brick    brick.Brickinfo
+		expected error
+	}{
+		{brick.Brickinfo{Hostname: "host1", Path: "/bricks/vol1/b1", VolumeName: "vol3"}, errors.ErrBrickPathAlreadyInUse},
+		// Bricks nested inside, or containing, a brick of another volume
+		{brick.Brickinfo{Hostname: "host1", Path: "/bricks/vol2/b1/sub", VolumeName: "vol3"}, errors.ErrBrickPathOverlaps},
+		{brick.Brickinfo{Hostname: "host1", Path: "/bricks/vol2", VolumeName: "vol3"}, errors.ErrBrickPathOverlaps},
+		// Bricks nested inside, or containing, a brick of the same volume
+		{brick.Brickinfo{Hostname: "host1", Path: "/bricks/vol1/b1/sub/", VolumeName: "vol1"}, errors.ErrBrickPathOverlaps},
+		{brick.Brickinfo{Hostname: "host1", Path: "/bricks/vol1", VolumeName: "vol1"}, errors.ErrBrickPathOverlaps},
+		// Bricks on other nodes, and siblings sharing a prefix, don't
+		// overlap
+		{brick.Brickinfo{Hostname: "host2", Path: "/bricks/vol2/b1", VolumeName: "vol3"}, nil},
+		{brick.Brickinfo{Hostname: "host1", Path: "/bricks/vol1/b10", VolumeName: "vol1"}, nil},
+	} {
+		tests.Assert(t, isBrickPathAvailable(c.brick) == c.expected)
+	}
+}
+
+func TestBrickOverlapStoreError(t *testing.T) {
+	storeErr := fmt.Errorf("store unavailable")
+	defer heketitests.Patch(&getVolumesFunc, func() ([]Volinfo, error) {
+		return nil, storeErr
+	}).Restore()
+
+	// Bricks can't be checked for overlaps without the volumes, nested
+	// bricks or not
+	for _, path := range []string{"/bricks/vol1/b1", "/bricks/vol1/b1/sub", "/bricks"} {
+		b := brick.Brickinfo{Hostname: "host1", Path: path, VolumeName: "vol3"}
+		tests.Assert(t, isBrickPathAvailable(b) == storeErr)
+	}
+}
+
+func TestCheckBricksNotInUseStoreError(t *testing.T) {
+	storeErr := fmt.Errorf("store unavailable")
+	defer heketitests.Patch(&getVolumesFunc, func() ([]Volinfo, error) {
+		return nil, storeErr
+	}).Restore()
+
+	// Bricks are not known to be unused when the volumes can't be read,
+	// so they are not cleaned up
+	bricks := []brick.Brickinfo{{Hostname: "host1", Path: "/bricks/b1"}}
+	tests.Assert(t, CheckBricksNotInUse(bricks) == storeErr)
+	tests.Assert(t, CleanupBricks(bricks) == storeErr)
+}
+
+func TestGetBrick(t *testing.T) {
+	node1, node2 := uuid.NewRandom(), uuid.NewRandom()
+	defer heketitests.Patch(&getVolumesFunc, func() ([]Volinfo, error) {
+		return []Volinfo{
+			{Name: "vol1", Bricks: []brick.Brickinfo{
+				{NodeID: node1, Path: "/bricks/vol1/b1", VolumeName: "vol1"},
+				{NodeID: node2, Path: "/bricks/vol1/b2", VolumeName: "vol1"},
+			}},
+		}, nil
+	}).Restore()
+
+	b, err := GetBrick(node2, "/bricks/vol1/b2/")
+	tests.Assert(t, err == nil)
+	tests.Assert(t, b.VolumeName == "vol1" && b.Path == "/bricks/vol1/b2")
+
+	// Paths which are not bricks of the peer, including paths under its
+	// bricks, are not found
+	_, err = GetBrick(node1, "/bricks/vol1/b2")
+	tests.Assert(t, err == errors.ErrBrickNotFound)
+	_, err = GetBrick(node1, "/bricks/vol1/b1/sub")
+	tests.Assert(t, err == errors.ErrBrickNotFound)
+	_, err = GetBrick(node1, "/etc")
+	tests.Assert(t, err == errors.ErrBrickNotFound)
+}
@@ -23,6 +23,32 @@ const (
 	OptionTypeClientAuthAddr
 )
 
+var optionTypeNames = []string{
+	"any",
+	"str",
+	"int",
+	"sizet",
+	"percent",
+	"percent-or-sizet",
+	"bool",
+	"xlator",
+	"path",
+	"time",
+	"double",
+	"internet-address",
+	"internet-address-list",
+	"priority-list",
+	"size-list",
+	"client-auth-addr",
+}
+
+func (t OptionType) String() string {
+	if t < 0 || int(t) >= len(optionTypeNames) {
+		return "unknown"
+	}
+	return optionTypeNames[t]
+}
+
 // OptionValidateType is a type which represents how the value of xlator
 // option should be validated.
 type OptionValidateType int